@@ -1,7 +1,6 @@
 package apis
 
 import (
-	"log"
 	"net/http"
 
 	"github.com/labstack/echo/v5"
@@ -22,12 +21,16 @@ func BindAdminApi(app core.App, rg *echo.Group) {
 	subGroup.POST("/auth-via-email", api.emailAuth, RequireGuestOnly())
 	subGroup.POST("/request-password-reset", api.requestPasswordReset)
 	subGroup.POST("/confirm-password-reset", api.confirmPasswordReset)
+	subGroup.POST("/request-magic-link", api.requestMagicLink)
+	subGroup.POST("/auth-via-magic-link", api.magicLinkAuth, RequireGuestOnly())
 	subGroup.POST("/refresh", api.refresh, RequireAdminAuth())
-	subGroup.GET("", api.list, RequireAdminAuth())
+	subGroup.POST("/logout", api.logout)
+	subGroup.GET("", api.list, RequireSuperAdminAuth())
 	subGroup.POST("", api.create, RequireAdminAuthOnlyIfAny(app))
-	subGroup.GET("/:id", api.view, RequireAdminAuth())
+	subGroup.GET("/:id", api.view, RequireSuperAdminAuth())
 	subGroup.PATCH("/:id", api.update, RequireAdminAuth())
-	subGroup.DELETE("/:id", api.delete, RequireAdminAuth())
+	subGroup.DELETE("/:id", api.delete, RequireSuperAdminAuth())
+	subGroup.PUT("/:id/permissions", api.updatePermissions, RequireSuperAdminAuth())
 }
 
 type adminApi struct {
@@ -47,6 +50,8 @@ func (api *adminApi) authResponse(c echo.Context, admin *models.Admin) error {
 	}
 
 	return api.app.OnAdminAuthRequest().Trigger(event, func(e *core.AdminAuthEvent) error {
+		setAuthCookies(api.app, e.HttpContext, AuthCookieAdmin, e.Token, api.app.Settings().AdminAuthToken.Duration)
+
 		return e.HttpContext.JSON(200, map[string]any{
 			"token": e.Token,
 			"admin": e.Admin,
@@ -63,17 +68,41 @@ func (api *adminApi) refresh(c echo.Context) error {
 	return api.authResponse(c, admin)
 }
 
+// logout clears the admin auth cookies (if any).
+func (api *adminApi) logout(c echo.Context) error {
+	clearAuthCookies(api.app, c, AuthCookieAdmin)
+
+	return c.NoContent(http.StatusNoContent)
+}
+
 func (api *adminApi) emailAuth(c echo.Context) error {
 	form := forms.NewAdminLogin(api.app)
-	if readErr := c.Bind(form); readErr != nil {
+	readErr := c.Bind(form)
+
+	attempt := newAuthAttemptEvent(api.app, c, core.AuthSubjectAdmin, core.AuthMethodEmail, form.Email)
+
+	if readErr != nil {
+		triggerAuthFailure(api.app, attempt, core.AuthFailureInvalidRequest)
 		return rest.NewBadRequestError("An error occurred while reading the submitted data.", readErr)
 	}
 
+	lockoutKey := loginLockoutKey("admin", form.Email)
+	if err := beginLoginAttempt(api.app, c, lockoutKey); err != nil {
+		triggerAuthFailure(api.app, attempt, core.AuthFailureLockedOut)
+		return err
+	}
+
 	admin, submitErr := form.Submit()
 	if submitErr != nil {
+		triggerAuthFailure(api.app, attempt, loginFailureReason(submitErr))
 		return rest.NewBadRequestError("Failed to authenticate.", submitErr)
 	}
 
+	resetLoginFailures(api.app, lockoutKey)
+
+	attempt.Admin = admin
+	triggerAuthSuccess(api.app, attempt)
+
 	return api.authResponse(c, admin)
 }
 
@@ -91,13 +120,65 @@ func (api *adminApi) requestPasswordReset(c echo.Context) error {
 	// (prevents admins enumeration)
 	routine.FireAndForget(func() {
 		if err := form.Submit(); err != nil && api.app.IsDebug() {
-			log.Println(err)
+			api.app.Logger().Println(err)
 		}
 	})
 
 	return c.NoContent(http.StatusNoContent)
 }
 
+func (api *adminApi) requestMagicLink(c echo.Context) error {
+	if !api.app.Settings().AdminMagicLink.Enabled {
+		return rest.NewBadRequestError("The admin magic link login is not enabled.", nil)
+	}
+
+	form := forms.NewAdminMagicLinkRequest(api.app)
+	if err := c.Bind(form); err != nil {
+		return rest.NewBadRequestError("An error occurred while reading the submitted data.", err)
+	}
+
+	if err := form.Validate(); err != nil {
+		return rest.NewBadRequestError("An error occurred while validating the form.", err)
+	}
+
+	// run in background because we don't need to show the result
+	// (prevents admins enumeration)
+	routine.FireAndForget(func() {
+		if err := form.Submit(); err != nil && api.app.IsDebug() {
+			api.app.Logger().Println(err)
+		}
+	})
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (api *adminApi) magicLinkAuth(c echo.Context) error {
+	attempt := newAuthAttemptEvent(api.app, c, core.AuthSubjectAdmin, core.AuthMethodMagicLink, "")
+
+	if !api.app.Settings().AdminMagicLink.Enabled {
+		triggerAuthFailure(api.app, attempt, core.AuthFailureMethodDisabled)
+		return rest.NewBadRequestError("The admin magic link login is not enabled.", nil)
+	}
+
+	form := forms.NewAdminMagicLinkLogin(api.app)
+	if readErr := c.Bind(form); readErr != nil {
+		triggerAuthFailure(api.app, attempt, core.AuthFailureInvalidRequest)
+		return rest.NewBadRequestError("An error occurred while reading the submitted data.", readErr)
+	}
+
+	admin, submitErr := form.Submit()
+	if submitErr != nil {
+		triggerAuthFailure(api.app, attempt, loginFailureReason(submitErr))
+		return rest.NewBadRequestError("Failed to authenticate.", submitErr)
+	}
+
+	attempt.Identity = admin.Email
+	attempt.Admin = admin
+	triggerAuthSuccess(api.app, attempt)
+
+	return api.authResponse(c, admin)
+}
+
 func (api *adminApi) confirmPasswordReset(c echo.Context) error {
 	form := forms.NewAdminPasswordResetConfirm(api.app)
 	if readErr := c.Bind(form); readErr != nil {
@@ -121,6 +202,7 @@ func (api *adminApi) list(c echo.Context) error {
 
 	result, err := search.NewProvider(fieldResolver).
 		Query(api.app.Dao().AdminQuery()).
+		Retry(api.app.Dao().WithRetry).
 		ParseAndExec(c.QueryString(), &admins)
 
 	if err != nil {
@@ -134,7 +216,7 @@ func (api *adminApi) list(c echo.Context) error {
 	}
 
 	return api.app.OnAdminsListRequest().Trigger(event, func(e *core.AdminsListEvent) error {
-		return e.HttpContext.JSON(http.StatusOK, e.Result)
+		return listResponse(e.HttpContext, e.Result)
 	})
 }
 
@@ -196,6 +278,12 @@ func (api *adminApi) update(c echo.Context) error {
 		return rest.NewNotFoundError("", nil)
 	}
 
+	// collection restricted admins can update only their own profile
+	authAdmin, _ := c.Get(ContextAdminKey).(*models.Admin)
+	if authAdmin != nil && !authAdmin.IsSuper() && authAdmin.Id != id {
+		return rest.NewForbiddenError("The request requires super admin permissions.", nil)
+	}
+
 	admin, err := api.app.Dao().FindAdminById(id)
 	if err != nil || admin == nil {
 		return rest.NewNotFoundError("", err)
@@ -229,6 +317,45 @@ func (api *adminApi) update(c echo.Context) error {
 	return handlerErr
 }
 
+func (api *adminApi) updatePermissions(c echo.Context) error {
+	id := c.PathParam("id")
+	if id == "" {
+		return rest.NewNotFoundError("", nil)
+	}
+
+	admin, err := api.app.Dao().FindAdminById(id)
+	if err != nil || admin == nil {
+		return rest.NewNotFoundError("", err)
+	}
+
+	form := forms.NewAdminPermissionsUpsert(api.app, admin)
+
+	// load request
+	if err := c.Bind(form); err != nil {
+		return rest.NewBadRequestError("Failed to read the submitted data due to invalid formatting.", err)
+	}
+
+	event := &core.AdminUpdateEvent{
+		HttpContext: c,
+		Admin:       admin,
+	}
+
+	handlerErr := api.app.OnAdminBeforeUpdateRequest().Trigger(event, func(e *core.AdminUpdateEvent) error {
+		// update the admin permissions
+		if err := form.Submit(); err != nil {
+			return rest.NewBadRequestError("Failed to update admin permissions.", err)
+		}
+
+		return e.HttpContext.JSON(http.StatusOK, e.Admin)
+	})
+
+	if handlerErr == nil {
+		api.app.OnAdminAfterUpdateRequest().Trigger(event)
+	}
+
+	return handlerErr
+}
+
 func (api *adminApi) delete(c echo.Context) error {
 	id := c.PathParam("id")
 	if id == "" {
@@ -0,0 +1,107 @@
+package apis
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/resolvers"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// BindAuthApi registers the common admin and user auth api endpoints.
+func BindAuthApi(app core.App, rg *echo.Group) {
+	api := authApi{app: app}
+
+	subGroup := rg.Group("/auth", ActivityLogger(app))
+	subGroup.GET("/me", api.me, RequireAdminOrUserAuth())
+}
+
+type authApi struct {
+	app core.App
+}
+
+// me returns the currently authenticated admin or user.
+//
+// The user profile is returned only if it satisfies the profiles
+// collection view rule and its relations could be expanded
+// with the "expand" query parameter.
+func (api *authApi) me(c echo.Context) error {
+	admin, _ := c.Get(ContextAdminKey).(*models.Admin)
+	user, _ := c.Get(ContextUserKey).(*models.User)
+
+	if admin == nil && user != nil && user.Profile != nil {
+		profile, err := api.viewProfile(c, user.Profile)
+		if err != nil {
+			return err
+		}
+		user.Profile = profile
+	}
+
+	event := &core.AuthMeEvent{
+		HttpContext: c,
+		Admin:       admin,
+		User:        user,
+		Meta:        map[string]any{},
+	}
+
+	return api.app.OnAuthMeRequest().Trigger(event, func(e *core.AuthMeEvent) error {
+		result := map[string]any{}
+
+		if e.Admin != nil {
+			result["type"] = "admin"
+			result["admin"] = e.Admin
+		} else {
+			result["type"] = "user"
+			result["user"] = e.User
+		}
+
+		if len(e.Meta) > 0 {
+			result["meta"] = e.Meta
+		}
+
+		return e.HttpContext.JSON(http.StatusOK, result)
+	})
+}
+
+// viewProfile refetches the provided user profile with the profiles
+// collection view rule applied (returns nil if the rule is not satisfied).
+func (api *authApi) viewProfile(c echo.Context, profile *models.Record) (*models.Record, error) {
+	collection := profile.Collection()
+	if collection.ViewRule == nil {
+		return nil, nil // admins only
+	}
+
+	recordApi := &recordApi{app: api.app}
+	requestData := recordApi.exportRequestData(c)
+
+	result, err := api.app.Dao().FindRecordById(collection, profile.Id, func(q *dbx.SelectQuery) error {
+		if *collection.ViewRule == "" {
+			return nil
+		}
+
+		resolver := resolvers.NewRecordFieldResolver(api.app.Dao(), collection, requestData)
+		expr, err := search.FilterData(*collection.ViewRule).BuildExpr(resolver)
+		if err != nil {
+			return err
+		}
+		resolver.UpdateQuery(q)
+		q.AndWhere(expr)
+
+		return nil
+	})
+	if err != nil || result == nil {
+		return nil, nil // the view rule is not satisfied
+	}
+
+	localizeRecords(api.app, c, []*models.Record{result})
+
+	expands := requestExpands(c, collection)
+	if err := recordApi.expandRecords(c, []*models.Record{result}, expands, requestData); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,59 @@
+package apis
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// newAuthAttemptEvent creates a new auth attempt event for the current request.
+func newAuthAttemptEvent(app core.App, c echo.Context, subject string, method string, identity string) *core.AuthAttemptEvent {
+	return &core.AuthAttemptEvent{
+		HttpContext: c,
+		Subject:     subject,
+		Method:      method,
+		Identity:    identity,
+		ClientIp:    clientIp(app, c),
+	}
+}
+
+// triggerAuthSuccess triggers the OnAuthSuccess hook for the provided event.
+func triggerAuthSuccess(app core.App, event *core.AuthAttemptEvent) {
+	event.FailureReason = ""
+
+	if err := app.OnAuthSuccess().Trigger(event); err != nil {
+		app.Logger().Println("OnAuthSuccess error:", err)
+	}
+}
+
+// triggerAuthFailure triggers the OnAuthFailure hook for the provided event
+// with the specified failure reason.
+func triggerAuthFailure(app core.App, event *core.AuthAttemptEvent, reason string) {
+	event.FailureReason = reason
+
+	if err := app.OnAuthFailure().Trigger(event); err != nil {
+		app.Logger().Println("OnAuthFailure error:", err)
+	}
+}
+
+// loginFailureReason returns the auth failure reason of a login form submit error.
+//
+// Form validation errors (eg. missing or malformed email) are reported as
+// invalid request and everything else as invalid credentials, so that
+// the event doesn't reveal whether the attempted account exists.
+func loginFailureReason(err error) string {
+	if _, ok := err.(validation.Errors); ok {
+		return core.AuthFailureInvalidRequest
+	}
+
+	return core.AuthFailureInvalidCredentials
+}
+
+// oauth2FailureReason returns the auth failure reason of an OAuth2 login form submit error.
+func oauth2FailureReason(err error) string {
+	if _, ok := err.(validation.Errors); ok {
+		return core.AuthFailureInvalidRequest
+	}
+
+	return core.AuthFailureOAuth2
+}
@@ -0,0 +1,273 @@
+package apis_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestAuthAttemptHooks(t *testing.T) {
+	scenarios := []struct {
+		name             string
+		url              string
+		body             string
+		trustedProxy     bool
+		expectedSuccess  bool
+		expectedSubject  string
+		expectedIdentity string
+		expectedReason   string
+		expectedIp       string
+	}{
+		{
+			"admin missing account",
+			"/api/admins/auth-via-email",
+			`{"email":"missing@example.com","password":"1234567890"}`,
+			false,
+			false,
+			core.AuthSubjectAdmin,
+			"missing@example.com",
+			core.AuthFailureInvalidCredentials,
+			"192.0.2.1",
+		},
+		{
+			"admin wrong password",
+			"/api/admins/auth-via-email",
+			`{"email":"test@example.com","password":"wrong_pass"}`,
+			false,
+			false,
+			core.AuthSubjectAdmin,
+			"test@example.com",
+			core.AuthFailureInvalidCredentials,
+			"192.0.2.1",
+		},
+		{
+			"admin invalid request",
+			"/api/admins/auth-via-email",
+			`{"email":"invalid"}`,
+			false,
+			false,
+			core.AuthSubjectAdmin,
+			"invalid",
+			core.AuthFailureInvalidRequest,
+			"192.0.2.1",
+		},
+		{
+			"admin success",
+			"/api/admins/auth-via-email",
+			`{"email":"test@example.com","password":"1234567890"}`,
+			false,
+			true,
+			core.AuthSubjectAdmin,
+			"test@example.com",
+			"",
+			"192.0.2.1",
+		},
+		{
+			"user wrong password (untrusted forwarded ip)",
+			"/api/users/auth-via-email",
+			`{"email":"test@example.com","password":"wrong_pass"}`,
+			false,
+			false,
+			core.AuthSubjectUser,
+			"test@example.com",
+			core.AuthFailureInvalidCredentials,
+			"192.0.2.1",
+		},
+		{
+			"user success (trusted forwarded ip)",
+			"/api/users/auth-via-email",
+			`{"email":"test@example.com","password":"123456"}`,
+			true,
+			true,
+			core.AuthSubjectUser,
+			"test@example.com",
+			"",
+			"198.51.100.7",
+		},
+		{
+			"user oauth2 invalid provider",
+			"/api/users/auth-via-oauth2",
+			`{"provider":"missing","code":"123","codeVerifier":"123","redirectUrl":"https://example.com"}`,
+			false,
+			false,
+			core.AuthSubjectUser,
+			"",
+			core.AuthFailureInvalidRequest,
+			"192.0.2.1",
+		},
+	}
+
+	for _, s := range scenarios {
+		app, _ := tests.NewTestApp()
+
+		if s.trustedProxy {
+			app.Settings().Https.TrustedProxies = []string{"192.0.2.1"}
+		}
+
+		var successEvents, failureEvents []*core.AuthAttemptEvent
+
+		app.OnAuthSuccess().Add(func(e *core.AuthAttemptEvent) error {
+			successEvents = append(successEvents, e)
+			return nil
+		})
+
+		app.OnAuthFailure().Add(func(e *core.AuthAttemptEvent) error {
+			failureEvents = append(failureEvents, e)
+			return nil
+		})
+
+		e, err := apis.InitApi(app)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, s.url, strings.NewReader(s.body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderXForwardedFor, "203.0.113.5, 198.51.100.7")
+		req.RemoteAddr = "192.0.2.1:1234"
+		e.ServeHTTP(recorder, req)
+
+		app.Cleanup()
+
+		events := failureEvents
+		if s.expectedSuccess {
+			events = successEvents
+			if len(failureEvents) != 0 {
+				t.Errorf("[%s] Expected no failure events, got %d", s.name, len(failureEvents))
+				continue
+			}
+		} else if len(successEvents) != 0 {
+			t.Errorf("[%s] Expected no success events, got %d", s.name, len(successEvents))
+			continue
+		}
+
+		if len(events) != 1 {
+			t.Errorf("[%s] Expected 1 event, got %d", s.name, len(events))
+			continue
+		}
+
+		event := events[0]
+
+		if event.Subject != s.expectedSubject {
+			t.Errorf("[%s] Expected subject %q, got %q", s.name, s.expectedSubject, event.Subject)
+		}
+
+		if event.Identity != s.expectedIdentity {
+			t.Errorf("[%s] Expected identity %q, got %q", s.name, s.expectedIdentity, event.Identity)
+		}
+
+		if event.FailureReason != s.expectedReason {
+			t.Errorf("[%s] Expected failure reason %q, got %q", s.name, s.expectedReason, event.FailureReason)
+		}
+
+		if event.ClientIp != s.expectedIp {
+			t.Errorf("[%s] Expected client ip %q, got %q", s.name, s.expectedIp, event.ClientIp)
+		}
+
+		hasAccount := event.Admin != nil || event.User != nil
+		if hasAccount != s.expectedSuccess {
+			t.Errorf("[%s] Expected the event account to be set only on success, got admin %v and user %v", s.name, event.Admin, event.User)
+		}
+	}
+}
+
+func TestAuthAttemptClientIp(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		remoteAddr     string
+		trustedProxies []string
+		forwardedFor   string
+		realIp         string
+		expectedIp     string
+	}{
+		{
+			"untrusted peer",
+			"192.0.2.1:1234",
+			nil,
+			"203.0.113.5",
+			"203.0.113.6",
+			"192.0.2.1",
+		},
+		{
+			"trusted peer with a single forwarded ip",
+			"192.0.2.1:1234",
+			[]string{"192.0.2.1"},
+			"203.0.113.5",
+			"",
+			"203.0.113.5",
+		},
+		{
+			"trusted peer with client spoofed leftmost ip",
+			"192.0.2.1:1234",
+			[]string{"192.0.2.1"},
+			"1.1.1.1, 203.0.113.5",
+			"",
+			"203.0.113.5",
+		},
+		{
+			"trusted proxies chain",
+			"192.0.2.1:1234",
+			[]string{"192.0.2.0/24"},
+			"1.1.1.1, 203.0.113.5, 192.0.2.3, 192.0.2.2",
+			"",
+			"203.0.113.5",
+		},
+		{
+			"only trusted forwarded hops",
+			"192.0.2.1:1234",
+			[]string{"192.0.2.0/24"},
+			"192.0.2.3, 192.0.2.2",
+			"",
+			"192.0.2.3",
+		},
+		{
+			"trusted peer with empty forwarded header",
+			"192.0.2.1:1234",
+			[]string{"192.0.2.1"},
+			"",
+			"203.0.113.6",
+			"203.0.113.6",
+		},
+	}
+
+	for _, s := range scenarios {
+		app, _ := tests.NewTestApp()
+
+		app.Settings().Https.TrustedProxies = s.trustedProxies
+
+		var clientIp string
+		app.OnAuthFailure().Add(func(e *core.AuthAttemptEvent) error {
+			clientIp = e.ClientIp
+			return nil
+		})
+
+		e, err := apis.InitApi(app)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/api/users/auth-via-email", strings.NewReader(`{"email":"missing@example.com","password":"1234567890"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if s.forwardedFor != "" {
+			req.Header.Set(echo.HeaderXForwardedFor, s.forwardedFor)
+		}
+		if s.realIp != "" {
+			req.Header.Set(echo.HeaderXRealIP, s.realIp)
+		}
+		req.RemoteAddr = s.remoteAddr
+		e.ServeHTTP(httptest.NewRecorder(), req)
+
+		app.Cleanup()
+
+		if clientIp != s.expectedIp {
+			t.Errorf("[%s] Expected client ip %q, got %q", s.name, s.expectedIp, clientIp)
+		}
+	}
+}
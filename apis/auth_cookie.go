@@ -0,0 +1,103 @@
+package apis
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// Cookie auth names (see [core.CookieAuthConfig]).
+const (
+	// AuthCookieUser is the name of the HttpOnly user auth token cookie.
+	AuthCookieUser string = "pb_user_auth"
+
+	// AuthCookieAdmin is the name of the HttpOnly admin auth token cookie.
+	AuthCookieAdmin string = "pb_admin_auth"
+
+	// CsrfCookie is the name of the (js readable) double-submit CSRF token cookie.
+	CsrfCookie string = "pb_csrf"
+
+	// CsrfHeader is the request header that must contain the CsrfCookie value
+	// for all cookie authenticated non-safe (POST, PATCH, etc.) requests.
+	CsrfHeader string = "X-CSRF-Token"
+)
+
+// setAuthCookies sets the auth token cookie with the specified name
+// and a new CSRF token cookie (if the cookie auth is enabled).
+func setAuthCookies(app core.App, c echo.Context, name string, token string, duration int64) {
+	config := app.Settings().CookieAuth
+	if !config.Enabled {
+		return
+	}
+
+	expires := time.Now().Add(time.Duration(duration) * time.Second)
+
+	c.SetCookie(newAuthCookie(config, name, token, expires, true))
+	c.SetCookie(newAuthCookie(config, CsrfCookie, security.RandomString(32), expires, false))
+}
+
+// clearAuthCookies expires the auth token cookie with the specified name
+// and the CSRF token cookie.
+func clearAuthCookies(app core.App, c echo.Context, name string) {
+	config := app.Settings().CookieAuth
+
+	c.SetCookie(newAuthCookie(config, name, "", time.Unix(0, 0), true))
+	c.SetCookie(newAuthCookie(config, CsrfCookie, "", time.Unix(0, 0), false))
+}
+
+func newAuthCookie(config core.CookieAuthConfig, name string, value string, expires time.Time, httpOnly bool) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Domain:   config.Domain,
+		Expires:  expires,
+		Secure:   config.Secure,
+		HttpOnly: httpOnly,
+		SameSite: config.HttpSameSite(),
+	}
+
+	if value == "" {
+		cookie.MaxAge = -1
+	}
+
+	return cookie
+}
+
+// cookieAuthToken returns the auth token from the request cookies
+// in the same format as the Authorization header (eg. "User TOKEN").
+//
+// Returns an empty string if there is no auth cookie.
+func cookieAuthToken(c echo.Context) string {
+	if cookie, err := c.Cookie(AuthCookieUser); err == nil && cookie.Value != "" {
+		return "User " + cookie.Value
+	}
+
+	if cookie, err := c.Cookie(AuthCookieAdmin); err == nil && cookie.Value != "" {
+		return "Admin " + cookie.Value
+	}
+
+	return ""
+}
+
+// hasValidCsrfToken checks whether the request is safe (GET, HEAD, OPTIONS)
+// or its CsrfHeader matches the CsrfCookie value.
+func hasValidCsrfToken(c echo.Context) bool {
+	switch c.Request().Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+
+	cookie, err := c.Cookie(CsrfCookie)
+	if err != nil || cookie.Value == "" {
+		return false
+	}
+
+	header := c.Request().Header.Get(CsrfHeader)
+
+	return subtle.ConstantTimeCompare([]byte(header), []byte(cookie.Value)) == 1
+}
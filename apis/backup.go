@@ -0,0 +1,137 @@
+package apis
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/tools/rest"
+)
+
+// BindBackupApi registers the backup api endpoints and the corresponding handlers.
+func BindBackupApi(app core.App, rg *echo.Group) {
+	api := backupApi{app: app}
+
+	subGroup := rg.Group("/backups", ActivityLogger(app), RequireSuperAdminAuth())
+	subGroup.GET("", api.list)
+	subGroup.POST("", api.create)
+	subGroup.POST("/upload", api.upload)
+	subGroup.GET("/:name", api.download)
+	subGroup.DELETE("/:name", api.delete)
+	subGroup.POST("/:name/restore", api.restore)
+}
+
+type backupApi struct {
+	app core.App
+}
+
+func (api *backupApi) list(c echo.Context) error {
+	backups, err := core.ListBackups(api.app)
+	if err != nil {
+		return rest.NewBadRequestError("Failed to load the backups list.", err)
+	}
+
+	return c.JSON(http.StatusOK, backups)
+}
+
+func (api *backupApi) create(c echo.Context) error {
+	form := forms.NewBackupCreate(api.app)
+	if err := c.Bind(form); err != nil {
+		return rest.NewBadRequestError("An error occurred while reading the submitted data.", err)
+	}
+
+	name, err := form.Submit()
+	if err != nil {
+		return rest.NewBadRequestError("Failed to create backup.", err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"name": name})
+}
+
+func (api *backupApi) upload(c echo.Context) error {
+	form := forms.NewBackupUpload(api.app)
+	form.File, _ = c.FormFile("file")
+
+	name, err := form.Submit()
+	if err != nil {
+		return rest.NewBadRequestError("Failed to upload backup.", err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"name": name})
+}
+
+func (api *backupApi) download(c echo.Context) error {
+	path, err := api.findBackupPath(c.PathParam("name"))
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return rest.NewNotFoundError("", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return rest.NewNotFoundError("", err)
+	}
+
+	name := filepath.Base(path)
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", name))
+	http.ServeContent(c.Response(), c.Request(), name, info.ModTime(), f)
+
+	return nil
+}
+
+func (api *backupApi) delete(c echo.Context) error {
+	path, err := api.findBackupPath(c.PathParam("name"))
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil {
+		return rest.NewBadRequestError("Failed to delete backup.", err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (api *backupApi) restore(c echo.Context) error {
+	path, err := api.findBackupPath(c.PathParam("name"))
+	if err != nil {
+		return err
+	}
+
+	form := forms.NewBackupRestore(api.app, filepath.Base(path))
+	if err := c.Bind(form); err != nil {
+		return rest.NewBadRequestError("An error occurred while reading the submitted data.", err)
+	}
+
+	if err := form.Submit(); err != nil {
+		return rest.NewBadRequestError("Failed to restore backup.", err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// findBackupPath returns the full path of the named backup archive
+// or a NotFound api error if the backup doesn't exist.
+func (api *backupApi) findBackupPath(name string) (string, error) {
+	if name == "" || filepath.Base(name) != name || filepath.Ext(name) != ".zip" {
+		return "", rest.NewNotFoundError("", nil)
+	}
+
+	path := filepath.Join(core.BackupsDir(api.app), name)
+
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return "", rest.NewNotFoundError("", err)
+	}
+
+	return path, nil
+}
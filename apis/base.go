@@ -2,9 +2,9 @@
 package apis
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/fs"
-	"log"
 	"net/http"
 	"net/url"
 	"path/filepath"
@@ -18,17 +18,52 @@ import (
 	"github.com/spf13/cast"
 )
 
+// The ids of the built-in global middlewares, listed in their execution order
+// (after the base path strip and the trailing slash removal, which are
+// always executed before routing).
+//
+// Custom middlewares could be positioned relative to them via the
+// OnBeforeServe event Middlewares chain, eg. to run before the auth context loading:
+//
+//	app.OnBeforeServe().Add(func(e *core.ServeEvent) error {
+//		return e.Middlewares.InsertBefore(apis.MiddlewareLoadAuthContext, "myMiddleware", myMiddleware)
+//	})
+//
+// Middlewares registered with `e.Middlewares.Use()` or directly with
+// `e.Router.Use()` are executed after the built-in ones (aka. after the
+// auth context loading) and before the route specific middlewares.
+const (
+	MiddlewareRecover                = "recover"
+	MiddlewareSecure                 = "secure"
+	MiddlewareEnforceHttps           = "enforceHttps"
+	MiddlewareRequireNoActiveRestore = "requireNoActiveRestore"
+	MiddlewareLoadAuthContext        = "loadAuthContext"
+)
+
 // InitApi creates a configured echo instance with registered
 // system and app specific routes and middlewares.
 func InitApi(app core.App) (*echo.Echo, error) {
 	e := echo.New()
 	e.Debug = app.IsDebug()
+	e.JSONSerializer = &jsonSerializer{app: app}
 
 	// default middlewares
+	e.Pre(StripBasePath(app))
 	e.Pre(middleware.RemoveTrailingSlash())
-	e.Use(middleware.Recover())
-	e.Use(middleware.Secure())
-	e.Use(LoadAuthContext(app))
+	middlewares := core.NewMiddlewaresChain()
+	if app.Tracer() != nil {
+		middlewares.Use(MiddlewareTracing, Tracing(app))
+	}
+	middlewares.Use(MiddlewareRecover, middleware.Recover())
+	middlewares.Use(MiddlewareSecure, middleware.Secure()) // note: the default config doesn't send HSTS
+	middlewares.Use(MiddlewareEnforceHttps, EnforceHttps(app))
+	middlewares.Use(MiddlewareRequireNoActiveRestore, RequireNoActiveRestore(app))
+	if app.IsDebug() {
+		middlewares.Use(MiddlewareDebugQueries, DebugQueries(app))
+	}
+	middlewares.Use(MiddlewareLoadAuthContext, LoadAuthContext(app))
+	middlewares.Use(MiddlewareLoadFeatureFlags, LoadFeatureFlags(app))
+	e.Use(middlewares.Handler())
 
 	// custom error handler
 	e.HTTPErrorHandler = func(c echo.Context, err error) {
@@ -41,18 +76,18 @@ func InitApi(app core.App) (*echo.Echo, error) {
 		switch v := err.(type) {
 		case *echo.HTTPError:
 			if v.Internal != nil && app.IsDebug() {
-				log.Println(v.Internal)
+				app.Logger().Println(v.Internal)
 			}
 			msg := fmt.Sprintf("%v", v.Message)
 			apiErr = rest.NewApiError(v.Code, msg, v)
 		case *rest.ApiError:
 			if app.IsDebug() && v.RawData() != nil {
-				log.Println(v.RawData())
+				app.Logger().Println(v.RawData())
 			}
 			apiErr = v
 		default:
 			if err != nil && app.IsDebug() {
-				log.Println(err)
+				app.Logger().Println(err)
 			}
 			apiErr = rest.NewBadRequestError("", err)
 		}
@@ -62,35 +97,62 @@ func InitApi(app core.App) (*echo.Echo, error) {
 		if c.Request().Method == http.MethodHead {
 			// @see https://github.com/labstack/echo/issues/608
 			cErr = c.NoContent(apiErr.Code)
+		} else if rest.AcceptsProblemDetails(c.Request()) {
+			// RFC 7807 problem details (opt-in via the Accept header)
+			var raw []byte
+			raw, cErr = json.Marshal(apiErr.ProblemDetails())
+			if cErr == nil {
+				cErr = c.Blob(apiErr.Code, rest.ProblemContentType, raw)
+			}
 		} else {
 			cErr = c.JSON(apiErr.Code, apiErr)
 		}
 
 		// truly rare case; eg. client already disconnected
 		if cErr != nil && app.IsDebug() {
-			log.Println(err)
+			app.Logger().Println(err)
 		}
 	}
 
 	// admin ui routes
-	bindStaticAdminUI(app, e)
+	if !app.IsApiDisabled(core.ApiGroupAdminUI) {
+		bindStaticAdminUI(app, e)
+	}
+
+	// custom single page application routes (if any)
+	bindSPA(app, e)
 
 	// default routes
 	api := e.Group("/api")
+	BindHealthApi(app, api)
 	BindSettingsApi(app, api)
 	BindAdminApi(app, api)
 	BindUserApi(app, api)
+	BindAuthApi(app, api)
 	BindCollectionApi(app, api)
 	BindRecordApi(app, api)
-	BindFileApi(app, api)
-	BindRealtimeApi(app, api)
-	BindLogsApi(app, api)
+	BindRecordArchiveApi(app, api)
+	BindRecordVersionApi(app, api)
+	BindQueryApi(app, api)
+	BindOpenApiApi(app, api)
+	if !app.IsApiDisabled(core.ApiGroupFiles) {
+		BindFileApi(app, api)
+	}
+	if !app.IsApiDisabled(core.ApiGroupRealtime) {
+		BindRealtimeApi(app, api)
+	}
+	if !app.IsApiDisabled(core.ApiGroupLogs) {
+		BindLogsApi(app, api)
+	}
+	BindLoginLockoutApi(app, api)
+	BindBackupApi(app, api)
 
 	// trigger the custom BeforeServe hook for the created api router
 	// allowing users to further adjust its options or register new routes
 	serveEvent := &core.ServeEvent{
-		App:    app,
-		Router: e,
+		App:         app,
+		Router:      e,
+		Middlewares: middlewares,
 	}
 	if err := app.OnBeforeServe().Trigger(serveEvent); err != nil {
 		return nil, err
@@ -128,12 +190,18 @@ func StaticDirectoryHandler(fileSystem fs.FS, disablePathUnescaping bool) echo.H
 
 // bindStaticAdminUI registers the endpoints that serves the static admin UI.
 func bindStaticAdminUI(app core.App, e *echo.Echo) error {
+	indexFS, distFS := ui.DistIndexHTML, ui.DistDirFS
+	if basePath := app.BasePath(); basePath != "" {
+		indexFS = newBasePathAdminUIFS(indexFS, basePath)
+		distFS = newBasePathAdminUIFS(distFS, basePath)
+	}
+
 	// serves /ui/dist/index.html file
 	// (explicit route is used to avoid conflicts with `RemoveTrailingSlash` middleware)
 	e.FileFS(
 		"/_",
 		"index.html",
-		ui.DistIndexHTML,
+		indexFS,
 		middleware.Gzip(),
 		installerRedirect(app),
 	)
@@ -142,7 +210,7 @@ func bindStaticAdminUI(app core.App, e *echo.Echo) error {
 	// (similar to echo.StaticFS but with gzip middleware enabled)
 	e.GET(
 		"/_/*",
-		StaticDirectoryHandler(ui.DistDirFS, false),
+		StaticDirectoryHandler(distFS, false),
 		middleware.Gzip(),
 	)
 
@@ -188,12 +256,12 @@ func installerRedirect(app core.App) echo.MiddlewareFunc {
 
 			if totalAdmins == 0 && !hasInstallerParam {
 				// redirect to the installer page
-				return c.Redirect(http.StatusTemporaryRedirect, "/_/?installer#")
+				return c.Redirect(http.StatusTemporaryRedirect, withBasePath(c, "/_/?installer#"))
 			}
 
 			if totalAdmins != 0 && hasInstallerParam {
 				// redirect to the home page
-				return c.Redirect(http.StatusTemporaryRedirect, "/_/#/")
+				return c.Redirect(http.StatusTemporaryRedirect, withBasePath(c, "/_/#/"))
 			}
 
 			return next(c)
@@ -0,0 +1,170 @@
+package apis
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// ContextBasePathKey is the request context key with the stripped
+// app base path (see [StripBasePath]).
+const ContextBasePathKey string = "basePath"
+
+// StripBasePath pre routing middleware removes the app base path
+// prefix from the request path, so that all routes (including the
+// custom ones) are registered and matched as if the app is served
+// from the root path.
+//
+// The requests outside of the base path are rejected with 404.
+func StripBasePath(app core.App) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			basePath := app.BasePath()
+			if basePath == "" {
+				return next(c)
+			}
+
+			req := c.Request()
+
+			stripped, ok := trimBasePath(req.URL.Path, basePath)
+			if !ok {
+				return echo.ErrNotFound
+			}
+			req.URL.Path = stripped
+
+			if req.URL.RawPath != "" {
+				req.URL.RawPath, _ = trimBasePath(req.URL.RawPath, basePath)
+			}
+
+			c.Set(ContextBasePathKey, basePath)
+
+			return next(c)
+		}
+	}
+}
+
+// trimBasePath removes the basePath prefix from p and reports
+// whether p is part of the base path.
+func trimBasePath(p string, basePath string) (string, bool) {
+	if p != basePath && !strings.HasPrefix(p, basePath+"/") {
+		return p, false
+	}
+
+	return "/" + strings.TrimPrefix(p[len(basePath):], "/"), true
+}
+
+// withBasePath prefixes the provided root relative path (eg. "/_/")
+// with the stripped request base path (if any), eg. for redirects
+// and other server generated urls.
+func withBasePath(c echo.Context, p string) string {
+	basePath, _ := c.Get(ContextBasePathKey).(string)
+
+	return basePath + p
+}
+
+// -------------------------------------------------------------------
+
+// basePathAdminUIFS wraps the embedded admin UI file system
+// and rewrites the root relative admin UI urls in the text
+// assets (eg. "/_/assets/...") to include the app base path.
+//
+// The admin UI is prebuilt for the root path (with "/_/" assets base
+// and "/" backend url), so the rewrite is required to be able to
+// serve it under a path prefix without rebuilding.
+type basePathAdminUIFS struct {
+	fsys     fs.FS
+	replacer *strings.Replacer
+	cache    sync.Map // name -> *basePathAdminUIFile
+}
+
+func newBasePathAdminUIFS(fsys fs.FS, basePath string) *basePathAdminUIFS {
+	return &basePathAdminUIFS{
+		fsys: fsys,
+		replacer: strings.NewReplacer(
+			// admin UI assets base
+			`"/_/`, `"`+basePath+`/_/`,
+			`'/_/`, `'`+basePath+`/_/`,
+			// admin UI api client backend url
+			`"/","en-US"`, `"`+basePath+`","en-US"`,
+		),
+	}
+}
+
+// Open implements [fs.FS] interface.
+func (f *basePathAdminUIFS) Open(name string) (fs.File, error) {
+	switch path.Ext(name) {
+	case ".html", ".js":
+	default:
+		return f.fsys.Open(name)
+	}
+
+	if cached, ok := f.cache.Load(name); ok {
+		return cached.(*basePathAdminUIFile).clone(), nil
+	}
+
+	original, err := f.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer original.Close()
+
+	info, err := original.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := io.ReadAll(original)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &basePathAdminUIFile{
+		name:    info.Name(),
+		modTime: info.ModTime(),
+		content: []byte(f.replacer.Replace(string(raw))),
+	}
+	f.cache.Store(name, file)
+
+	return file.clone(), nil
+}
+
+var (
+	_ fs.File     = (*basePathAdminUIFile)(nil)
+	_ fs.FileInfo = (*basePathAdminUIFile)(nil)
+	_ io.Seeker   = (*basePathAdminUIFile)(nil)
+)
+
+// basePathAdminUIFile is an in-memory rewritten admin UI file
+// (it implements also io.Seeker as required by http.ServeContent).
+type basePathAdminUIFile struct {
+	*bytes.Reader
+
+	name    string
+	modTime time.Time
+	content []byte
+}
+
+func (f *basePathAdminUIFile) clone() *basePathAdminUIFile {
+	return &basePathAdminUIFile{
+		Reader:  bytes.NewReader(f.content),
+		name:    f.name,
+		modTime: f.modTime,
+		content: f.content,
+	}
+}
+
+func (f *basePathAdminUIFile) Stat() (fs.FileInfo, error) { return f, nil }
+func (f *basePathAdminUIFile) Close() error               { return nil }
+func (f *basePathAdminUIFile) Name() string               { return f.name }
+func (f *basePathAdminUIFile) Size() int64                { return int64(len(f.content)) }
+func (f *basePathAdminUIFile) Mode() fs.FileMode          { return 0444 }
+func (f *basePathAdminUIFile) ModTime() time.Time         { return f.modTime }
+func (f *basePathAdminUIFile) IsDir() bool                { return false }
+func (f *basePathAdminUIFile) Sys() any                   { return nil }
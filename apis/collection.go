@@ -1,16 +1,20 @@
 package apis
 
 import (
+	"encoding/json"
 	"errors"
-	"log"
 	"net/http"
+	"path/filepath"
+	"strings"
 
 	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/forms"
 	"github.com/pocketbase/pocketbase/models"
 	"github.com/pocketbase/pocketbase/tools/rest"
 	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/spf13/cast"
 )
 
 // BindCollectionApi registers the collection api endpoints and the corresponding handlers.
@@ -19,10 +23,11 @@ func BindCollectionApi(app core.App, rg *echo.Group) {
 
 	subGroup := rg.Group("/collections", ActivityLogger(app), RequireAdminAuth())
 	subGroup.GET("", api.list)
-	subGroup.POST("", api.create)
+	subGroup.POST("", api.create, RequireSuperAdminAuth())
 	subGroup.GET("/:collection", api.view)
 	subGroup.PATCH("/:collection", api.update)
-	subGroup.DELETE("/:collection", api.delete)
+	subGroup.DELETE("/:collection", api.delete, RequireSuperAdminAuth())
+	subGroup.POST("/:collection/import", api.importRecords, LoadCollectionContext(app), RequireAdminCollectionAccess())
 }
 
 type collectionApi struct {
@@ -36,8 +41,23 @@ func (api *collectionApi) list(c echo.Context) error {
 
 	collections := []*models.Collection{}
 
+	query := api.app.Dao().CollectionQuery()
+
+	// list only the accessible collections of the restricted admins
+	admin, _ := c.Get(ContextAdminKey).(*models.Admin)
+	if admin != nil && !admin.IsSuper() {
+		ids := []any{}
+		for id := range admin.Permissions.Collections {
+			if admin.Permissions.CanRead(id) {
+				ids = append(ids, id)
+			}
+		}
+		query.AndWhere(dbx.In("id", ids...))
+	}
+
 	result, err := search.NewProvider(fieldResolver).
-		Query(api.app.Dao().CollectionQuery()).
+		Query(query).
+		Retry(api.app.Dao().WithRetry).
 		ParseAndExec(c.QueryString(), &collections)
 
 	if err != nil {
@@ -51,7 +71,7 @@ func (api *collectionApi) list(c echo.Context) error {
 	}
 
 	return api.app.OnCollectionsListRequest().Trigger(event, func(e *core.CollectionsListEvent) error {
-		return e.HttpContext.JSON(http.StatusOK, e.Result)
+		return listResponse(e.HttpContext, e.Result)
 	})
 }
 
@@ -61,6 +81,11 @@ func (api *collectionApi) view(c echo.Context) error {
 		return rest.NewNotFoundError("", err)
 	}
 
+	admin, _ := c.Get(ContextAdminKey).(*models.Admin)
+	if admin != nil && !admin.Permissions.CanRead(collection.Id) {
+		return rest.NewForbiddenError("You are not allowed to access this collection.", nil)
+	}
+
 	event := &core.CollectionViewEvent{
 		HttpContext: c,
 		Collection:  collection,
@@ -108,6 +133,11 @@ func (api *collectionApi) update(c echo.Context) error {
 		return rest.NewNotFoundError("", err)
 	}
 
+	admin, _ := c.Get(ContextAdminKey).(*models.Admin)
+	if admin != nil && !admin.Permissions.CanWrite(collection.Id) {
+		return rest.NewForbiddenError("You are not allowed to modify this collection.", nil)
+	}
+
 	form := forms.NewCollectionUpsert(api.app, collection)
 
 	// read
@@ -156,7 +186,7 @@ func (api *collectionApi) delete(c echo.Context) error {
 		if err := api.deleteCollectionFiles(e.Collection); err != nil && api.app.IsDebug() {
 			// non critical error - only log for debug
 			// (usually could happen because of S3 api limits)
-			log.Println(err)
+			api.app.Logger().Println(err)
 		}
 
 		return e.HttpContext.NoContent(http.StatusNoContent)
@@ -183,3 +213,89 @@ func (api *collectionApi) deleteCollectionFiles(collection *models.Collection) e
 
 	return nil
 }
+
+// importRecords imports the rows of the uploaded csv or json "file"
+// into the context collection.
+//
+// If the request Accept header is "application/x-ndjson", the import progress
+// is streamed as newline delimited json with the final report as last line.
+func (api *collectionApi) importRecords(c echo.Context) error {
+	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+	if collection == nil {
+		return rest.NewNotFoundError("", "Missing collection context.")
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return rest.NewBadRequestError("Missing or invalid import file.", err)
+	}
+
+	form := forms.NewRecordsImport(api.app, collection)
+	form.Format = c.FormValue("format")
+	if form.Format == "" {
+		form.Format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fileHeader.Filename)), ".")
+	}
+	if mode := c.FormValue("mode"); mode != "" {
+		form.Mode = mode
+	}
+	if batchSize := c.FormValue("batchSize"); batchSize != "" {
+		form.BatchSize = cast.ToInt(batchSize)
+	}
+	if mapping := c.FormValue("mapping"); mapping != "" {
+		if err := json.Unmarshal([]byte(mapping), &form.Mapping); err != nil {
+			return rest.NewBadRequestError("Failed to read the import mapping due to invalid formatting.", err)
+		}
+	}
+	form.KeyField = c.FormValue("keyField")
+	form.Sync = cast.ToBool(c.FormValue("sync"))
+	if relationKeys := c.FormValue("relationKeys"); relationKeys != "" {
+		if err := json.Unmarshal([]byte(relationKeys), &form.RelationKeys); err != nil {
+			return rest.NewBadRequestError("Failed to read the import relation keys due to invalid formatting.", err)
+		}
+	}
+
+	if err := form.Validate(); err != nil {
+		return rest.NewBadRequestError("An error occurred while validating the submitted data.", err)
+	}
+
+	// note: the multipart file is streamed from the disk if it is
+	// larger than the default max memory parse limit
+	file, err := fileHeader.Open()
+	if err != nil {
+		return rest.NewBadRequestError("Failed to open the import file.", err)
+	}
+	defer file.Close()
+
+	if !strings.Contains(c.Request().Header.Get("Accept"), "application/x-ndjson") {
+		result, err := form.Submit(file, nil)
+		if err != nil {
+			return rest.NewBadRequestError("Failed to import the file records.", err)
+		}
+
+		return c.JSON(http.StatusOK, result)
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "application/x-ndjson")
+	res.WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(res)
+
+	result, err := form.Submit(file, func(progress *forms.RecordsImportResult) {
+		encoder.Encode(map[string]any{
+			"processed": progress.Processed,
+			"imported":  progress.Imported,
+			"failed":    progress.Failed,
+			"created":   progress.Created,
+			"updated":   progress.Updated,
+			"deleted":   progress.Deleted,
+		})
+		res.Flush()
+	})
+	if err != nil {
+		// the headers are already sent so the error is reported as last line
+		return encoder.Encode(rest.NewBadRequestError("Failed to import the file records.", err))
+	}
+
+	return encoder.Encode(result)
+}
@@ -0,0 +1,122 @@
+package apis
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+const (
+	// MiddlewareDebugQueries is the id of the request queries log
+	// middleware (registered only if the app is in debug mode).
+	MiddlewareDebugQueries = "debugQueries"
+
+	// ContextQueryLogKey is the request context key with the
+	// request [core.QueryLog] (see [RequestQueryLog]).
+	ContextQueryLogKey string = "queryLog"
+
+	// DebugQueriesHeader is the response header with the JSON serialized
+	// list of the request executed db queries (see [DebugQueries]).
+	DebugQueriesHeader = "X-Debug-Queries"
+
+	// DebugQueriesTruncatedHeader is the response header that is set to
+	// "true" if the [DebugQueriesHeader] doesn't contain all request queries.
+	DebugQueriesTruncatedHeader = "X-Debug-Queries-Truncated"
+
+	// DebugQueriesMaxHeaderSize is the max size in bytes
+	// of the [DebugQueriesHeader] response header value.
+	DebugQueriesMaxHeaderSize = 16 << 10
+
+	// debugQueriesQueryParam is the admins only query parameter
+	// that enables the [DebugQueriesHeader] response header.
+	debugQueriesQueryParam = "debugQueries"
+)
+
+// DebugQueries middleware collects the db queries executed during
+// the request and stores their log in the request context under
+// [ContextQueryLogKey].
+//
+// If the request is from an admin and has a truthy "debugQueries"
+// query parameter, the collected queries are also returned in
+// the [DebugQueriesHeader] response header (truncated to
+// [DebugQueriesMaxHeaderSize]).
+//
+// Note that the queries are collected only in debug mode and
+// not for the long-lived streaming requests (eg. realtime connect).
+func DebugQueries(app core.App) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !app.IsDebug() || isStreamingRequest(c) {
+				return next(c)
+			}
+
+			req := c.Request()
+
+			ctx, log, stop := app.StartQueryLog(req.Context())
+			defer stop()
+
+			c.SetRequest(req.WithContext(ctx))
+			c.Set(ContextQueryLogKey, log)
+
+			c.Response().Before(func() {
+				if enabled, _ := strconv.ParseBool(c.QueryParam(debugQueriesQueryParam)); !enabled {
+					return
+				}
+
+				if admin, _ := c.Get(ContextAdminKey).(*models.Admin); admin == nil {
+					return
+				}
+
+				entries := log.Entries()
+				truncated := log.Dropped() > 0
+
+				raw, err := json.Marshal(entries)
+				for err == nil && len(raw) > DebugQueriesMaxHeaderSize {
+					entries = entries[:len(entries)/2]
+					truncated = true
+					raw, err = json.Marshal(entries)
+				}
+				if err != nil {
+					return
+				}
+
+				c.Response().Header().Set(DebugQueriesHeader, string(raw))
+				if truncated {
+					c.Response().Header().Set(DebugQueriesTruncatedHeader, "true")
+				}
+			})
+
+			return next(c)
+		}
+	}
+}
+
+// isStreamingRequest reports whether the request is
+// handled by a long-lived streaming route (eg. realtime SSE).
+func isStreamingRequest(c echo.Context) bool {
+	req := c.Request()
+
+	if req.Method == http.MethodGet {
+		switch c.Path() {
+		case "/api/realtime", "/api/logs/requests/stream":
+			return true
+		}
+	}
+
+	accept := req.Header.Get("Accept")
+
+	return strings.Contains(accept, "text/event-stream") || strings.Contains(accept, "application/x-ndjson")
+}
+
+// RequestQueryLog returns the db queries log of the current request
+// (nil if the app is not in debug mode).
+func RequestQueryLog(c echo.Context) *core.QueryLog {
+	log, _ := c.Get(ContextQueryLogKey).(*core.QueryLog)
+
+	return log
+}
@@ -0,0 +1,114 @@
+package apis
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+const (
+	// MiddlewareLoadFeatureFlags is the id of the built-in middleware
+	// that resolves the request feature flags (see [LoadFeatureFlags]).
+	MiddlewareLoadFeatureFlags = "loadFeatureFlags"
+
+	// ContextFeatureFlagsKey is the request context key with the
+	// resolved request feature flags (see [RequestFeatureFlags]).
+	ContextFeatureFlagsKey string = "featureFlags"
+
+	// FeatureFlagsHeader is the request header with the comma separated
+	// feature flags overrides, eg. "newFormat,-oldBehavior" enables
+	// "newFormat" and disables "oldBehavior".
+	FeatureFlagsHeader = "X-Feature-Flags"
+
+	// FeatureFlagsSignatureHeader is the request header with the hex encoded
+	// HMAC-SHA256 signature of the [FeatureFlagsHeader] value
+	// (see [SignFeatureFlags]).
+	FeatureFlagsSignatureHeader = "X-Feature-Flags-Signature"
+
+	// featureFlagsQueryParam is the admins only query parameter
+	// with the feature flags overrides (same format as the header).
+	featureFlagsQueryParam = "flags"
+)
+
+// SignFeatureFlags returns the hex encoded HMAC-SHA256 signature
+// of the provided [FeatureFlagsHeader] value.
+func SignFeatureFlags(secret string, value string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// LoadFeatureFlags middleware resolves the request feature flags
+// and stores them in the request context under [ContextFeatureFlagsKey].
+//
+// The flags defaults from the app settings could be overridden with:
+//   - a [FeatureFlagsHeader] signed with the settings feature flags secret
+//     (the overrides with missing or invalid signature are ignored)
+//   - the "flags" query parameter (admins only; applied after the header)
+//
+// Only the flags defined in the app settings could be overridden.
+func LoadFeatureFlags(app core.App) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			config := app.Settings().FeatureFlags
+			if len(config.Flags) == 0 {
+				return next(c) // no defined flags
+			}
+
+			flags := config.Defaults()
+
+			if header := c.Request().Header.Get(FeatureFlagsHeader); header != "" && config.Secret != "" {
+				signature := c.Request().Header.Get(FeatureFlagsSignatureHeader)
+				if hmac.Equal([]byte(SignFeatureFlags(config.Secret, header)), []byte(signature)) {
+					applyFeatureFlagsOverrides(flags, header)
+				}
+			}
+
+			if param := c.QueryParam(featureFlagsQueryParam); param != "" {
+				if admin, _ := c.Get(ContextAdminKey).(*models.Admin); admin != nil {
+					applyFeatureFlagsOverrides(flags, param)
+				}
+			}
+
+			c.Set(ContextFeatureFlagsKey, flags)
+
+			return next(c)
+		}
+	}
+}
+
+// RequestFeatureFlags returns the resolved request feature flags
+// indexed by their name (the returned map must not be modified).
+func RequestFeatureFlags(c echo.Context) map[string]bool {
+	flags, _ := c.Get(ContextFeatureFlagsKey).(map[string]bool)
+
+	return flags
+}
+
+// FeatureFlagEnabled reports whether the named feature flag
+// is enabled for the current request.
+//
+// Undefined flags are always disabled.
+func FeatureFlagEnabled(c echo.Context, name string) bool {
+	return RequestFeatureFlags(c)[name]
+}
+
+// applyFeatureFlagsOverrides applies the comma separated overrides
+// to the already defined flags (the unknown flags are ignored).
+func applyFeatureFlagsOverrides(flags map[string]bool, overrides string) {
+	for _, item := range strings.Split(overrides, ",") {
+		item = strings.TrimSpace(item)
+
+		enabled := !strings.HasPrefix(item, "-")
+		name := strings.TrimPrefix(item, "-")
+
+		if _, ok := flags[name]; ok {
+			flags[name] = enabled
+		}
+	}
+}
@@ -85,6 +85,11 @@ func (api *fileApi) download(c echo.Context) error {
 		}
 	}
 
+	// apply the field cache policy (could be further changed by the hook handlers)
+	if options.CacheControl != "" {
+		c.Response().Header().Set("Cache-Control", options.CacheControl)
+	}
+
 	event := &core.FileDownloadEvent{
 		HttpContext: c,
 		Record:      record,
@@ -95,7 +100,7 @@ func (api *fileApi) download(c echo.Context) error {
 	}
 
 	return api.app.OnFileDownloadRequest().Trigger(event, func(e *core.FileDownloadEvent) error {
-		if err := fs.Serve(e.HttpContext.Response(), e.ServedPath, e.ServedName); err != nil {
+		if err := fs.Serve(e.HttpContext.Response(), e.HttpContext.Request(), e.ServedPath, e.ServedName); err != nil {
 			return rest.NewNotFoundError("", err)
 		}
 
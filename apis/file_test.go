@@ -8,6 +8,8 @@ import (
 	"runtime"
 	"testing"
 
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/models/schema"
 	"github.com/pocketbase/pocketbase/tests"
 )
 
@@ -95,9 +97,127 @@ func TestFileDownload(t *testing.T) {
 				"OnFileDownloadRequest": 1,
 			},
 		},
+		{
+			Name:            "existing file - range request",
+			Method:          http.MethodGet,
+			Url:             "/api/files/demo/848a1dea-5ddd-42d6-a00d-030547bffcfe/8fe61d65-6a2e-4f11-87b3-d8a3170bfd4f.txt",
+			RequestHeaders:  map[string]string{"Range": "bytes=2-4"},
+			ExpectedStatus:  206,
+			ExpectedContent: []string{string(testFile[2:5])},
+			ExpectedHeaders: map[string]string{
+				"Accept-Ranges":  "bytes",
+				"Content-Range":  "bytes 2-4/10",
+				"Content-Length": "3",
+			},
+			ExpectedEvents: map[string]int{
+				"OnFileDownloadRequest": 1,
+			},
+		},
+		{
+			Name:            "existing file - multi-range request (should serve the first range)",
+			Method:          http.MethodGet,
+			Url:             "/api/files/demo/848a1dea-5ddd-42d6-a00d-030547bffcfe/8fe61d65-6a2e-4f11-87b3-d8a3170bfd4f.txt",
+			RequestHeaders:  map[string]string{"Range": "bytes=-3, 0-1"},
+			ExpectedStatus:  206,
+			ExpectedContent: []string{string(testFile[7:])},
+			ExpectedHeaders: map[string]string{
+				"Content-Range":  "bytes 7-9/10",
+				"Content-Length": "3",
+			},
+			ExpectedEvents: map[string]int{
+				"OnFileDownloadRequest": 1,
+			},
+		},
+		{
+			Name:           "existing file - unsatisfiable range request",
+			Method:         http.MethodGet,
+			Url:            "/api/files/demo/848a1dea-5ddd-42d6-a00d-030547bffcfe/8fe61d65-6a2e-4f11-87b3-d8a3170bfd4f.txt",
+			RequestHeaders: map[string]string{"Range": "bytes=100-"},
+			ExpectedStatus: 416,
+			ExpectedHeaders: map[string]string{
+				"Content-Range": "bytes */10",
+			},
+			ExpectedEvents: map[string]int{
+				"OnFileDownloadRequest": 1,
+			},
+		},
+		{
+			Name:           "existing file - matching If-None-Match",
+			Method:         http.MethodGet,
+			Url:            "/api/files/demo/848a1dea-5ddd-42d6-a00d-030547bffcfe/8fe61d65-6a2e-4f11-87b3-d8a3170bfd4f.txt",
+			RequestHeaders: map[string]string{"If-None-Match": "*"},
+			ExpectedStatus: 304,
+			ExpectedEvents: map[string]int{
+				"OnFileDownloadRequest": 1,
+			},
+		},
+		{
+			Name:            "existing file - without field cache policy",
+			Method:          http.MethodGet,
+			Url:             "/api/files/demo/848a1dea-5ddd-42d6-a00d-030547bffcfe/8fe61d65-6a2e-4f11-87b3-d8a3170bfd4f.txt",
+			ExpectedStatus:  200,
+			ExpectedContent: []string{string(testFile)},
+			ExpectedHeaders: map[string]string{
+				"Cache-Control": "",
+			},
+			ExpectedEvents: map[string]int{
+				"OnFileDownloadRequest": 1,
+			},
+		},
+		{
+			Name:            "existing file - with field cache policy",
+			Method:          http.MethodGet,
+			Url:             "/api/files/demo/848a1dea-5ddd-42d6-a00d-030547bffcfe/8fe61d65-6a2e-4f11-87b3-d8a3170bfd4f.txt",
+			BeforeFunc:      setDemoFileCacheControl("no-store"),
+			ExpectedStatus:  200,
+			ExpectedContent: []string{string(testFile)},
+			ExpectedHeaders: map[string]string{
+				"Cache-Control": "no-store",
+			},
+			ExpectedEvents: map[string]int{
+				"OnFileDownloadRequest": 1,
+			},
+		},
+		{
+			Name:            "existing image thumb - with field cache policy",
+			Method:          http.MethodGet,
+			Url:             "/api/files/demo/577bd676-aacb-4072-b7da-99d00ee210a4/4881bdef-06b4-4dea-8d97-6125ad242677.png?thumb=100x100",
+			BeforeFunc:      setDemoFileCacheControl("public, max-age=31536000, immutable"),
+			ExpectedStatus:  200,
+			ExpectedContent: []string{string(testThumb)},
+			ExpectedHeaders: map[string]string{
+				"Cache-Control": "public, max-age=31536000, immutable",
+			},
+			ExpectedEvents: map[string]int{
+				"OnFileDownloadRequest": 1,
+			},
+		},
 	}
 
 	for _, scenario := range scenarios {
 		scenario.Test(t)
 	}
 }
+
+func setDemoFileCacheControl(cacheControl string) func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+	return func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+		collection, err := app.Dao().FindCollectionByNameOrId("demo")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, field := range collection.Schema.Fields() {
+			if field.Type != schema.FieldTypeFile {
+				continue
+			}
+			field.InitOptions()
+			field.Options.(*schema.FileOptions).CacheControl = cacheControl
+		}
+
+		if err := app.Dao().SaveCollection(collection); err != nil {
+			t.Fatal(err)
+		}
+
+		app.ResetEventCalls()
+	}
+}
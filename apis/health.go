@@ -0,0 +1,62 @@
+package apis
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// BindHealthApi registers the health api endpoints.
+func BindHealthApi(app core.App, rg *echo.Group) {
+	api := healthApi{app: app}
+
+	subGroup := rg.Group("/health")
+	subGroup.GET("", api.live)
+	subGroup.GET("/ready", api.ready)
+}
+
+type healthApi struct {
+	app core.App
+}
+
+// live reports whether the app is able to serve requests.
+func (api *healthApi) live(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string]any{
+		"code":    http.StatusOK,
+		"message": "API is healthy.",
+	})
+}
+
+// ready runs the built-in db check and the registered app health checks
+// and responds with their aggregated result (503 if any critical check fails).
+//
+// The check error messages are returned only to admins.
+func (api *healthApi) ready(c echo.Context) error {
+	dbCheck := &core.HealthCheck{
+		Name:     "db",
+		Critical: true,
+		Check: func(ctx context.Context) error {
+			return api.app.DB().DB().PingContext(ctx)
+		},
+	}
+
+	checks := append([]*core.HealthCheck{dbCheck}, api.app.HealthChecks()...)
+
+	report := core.RunHealthChecks(c.Request().Context(), checks)
+
+	if admin, _ := c.Get(ContextAdminKey).(*models.Admin); admin == nil {
+		for _, result := range report.Checks {
+			result.Error = ""
+		}
+	}
+
+	status := http.StatusOK
+	if !report.Healthy {
+		status = http.StatusServiceUnavailable
+	}
+
+	return c.JSON(status, report)
+}
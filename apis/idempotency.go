@@ -0,0 +1,276 @@
+package apis
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/rest"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header with the client generated
+	// idempotency key (eg. an uuid) of a retry-safe request.
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotencyReplayedHeader is the response header that is set
+	// when the response is a replay of an already processed request.
+	IdempotencyReplayedHeader = "Idempotency-Replayed"
+
+	// IdempotencyKeyTtl is the duration (since the first request with
+	// the key) for which the idempotent request responses are stored.
+	IdempotencyKeyTtl = 24 * time.Hour
+
+	// idempotencyKeyMaxLength is the max allowed idempotency key length.
+	idempotencyKeyMaxLength = 255
+
+	// idempotencyCacheKey is the app cache key of the idempotency responses store.
+	idempotencyCacheKey = "@idempotency"
+)
+
+var (
+	// IdempotencyMaxEntries is the max number of stored idempotency keys
+	// per app (the oldest completed ones are evicted when reached).
+	IdempotencyMaxEntries = 10000
+
+	// IdempotencyMaxResponseSize is the max size in bytes of a stored
+	// response body (larger responses are not stored and their
+	// requests are not protected from duplicate execution).
+	IdempotencyMaxResponseSize = 1 << 20
+)
+
+// idempotencyCacheMux guards the idempotency store initialization
+// in the app cache.
+var idempotencyCacheMux sync.Mutex
+
+// idempotencyStore holds the idempotency entries of a single app.
+type idempotencyStore struct {
+	mux       sync.Mutex
+	lastPrune time.Time
+	entries   map[string]*idempotencyEntry
+}
+
+type idempotencyEntry struct {
+	mux     sync.Mutex
+	expires time.Time // immutable
+
+	// the stored response (set only after a successful response)
+	done     bool
+	bodyHash string
+	status   int
+	header   http.Header
+	body     []byte
+}
+
+// Idempotency middleware makes the route retry-safe for the requests
+// with an [IdempotencyKeyHeader] header.
+//
+// The first successful (2xx) response of each client key is stored
+// in the app cache for [IdempotencyKeyTtl] and it is returned as it is
+// on the repeated requests with the same key and request body instead of
+// invoking the route handler again. Repeated requests with the same key
+// but a different body are rejected with 422 Unprocessable Entity.
+// The failed requests are not stored and could be retried.
+//
+// The keys are scoped to the request client (auth model or ip), method
+// and path. Concurrent requests with the same key are serialized.
+// Requests without the header are not affected.
+func Idempotency(app core.App) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				return next(c)
+			}
+
+			if len(key) > idempotencyKeyMaxLength {
+				return rest.NewBadRequestError("The Idempotency-Key header value is too long.", nil)
+			}
+
+			store := appIdempotencyStore(app)
+			storeKey := requestClientKey(app, c) + ":" + c.Request().Method + ":" + c.Request().URL.Path + ":" + key
+			entry := store.acquire(storeKey)
+			defer store.release(storeKey, entry)
+
+			// hash the request body while it is read by the handler
+			hash := sha256.New()
+			body := io.TeeReader(c.Request().Body, hash)
+			c.Request().Body = io.NopCloser(body)
+
+			if entry.done {
+				if _, err := io.Copy(io.Discard, body); err != nil {
+					return rest.NewBadRequestError("Failed to read the request body.", err)
+				}
+
+				if hex.EncodeToString(hash.Sum(nil)) != entry.bodyHash {
+					return rest.NewApiError(
+						http.StatusUnprocessableEntity,
+						"The Idempotency-Key was already used with a different request body.",
+						nil,
+					)
+				}
+
+				for k, v := range entry.header {
+					c.Response().Header()[k] = v
+				}
+				c.Response().Header().Set(IdempotencyReplayedHeader, "true")
+				c.Response().WriteHeader(entry.status)
+				_, err := c.Response().Write(entry.body)
+				return err
+			}
+
+			recorder := &idempotencyRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+			defer func() {
+				c.Response().Writer = recorder.ResponseWriter
+			}()
+
+			if err := next(c); err != nil {
+				return err
+			}
+
+			status := c.Response().Status
+			if status < 200 || status >= 300 || recorder.exceeded {
+				return nil
+			}
+
+			// hash the rest of the body (if not fully read by the handler)
+			if _, err := io.Copy(io.Discard, body); err != nil {
+				return nil
+			}
+
+			entry.done = true
+			entry.bodyHash = hex.EncodeToString(hash.Sum(nil))
+			entry.status = status
+			entry.header = c.Response().Header().Clone()
+			entry.body = recorder.body.Bytes()
+
+			return nil
+		}
+	}
+}
+
+// appIdempotencyStore returns the idempotency store of the provided app
+// (creating it if missing).
+func appIdempotencyStore(app core.App) *idempotencyStore {
+	idempotencyCacheMux.Lock()
+	defer idempotencyCacheMux.Unlock()
+
+	s, _ := app.Cache().Get(idempotencyCacheKey).(*idempotencyStore)
+	if s == nil {
+		s = &idempotencyStore{entries: map[string]*idempotencyEntry{}}
+		app.Cache().Set(idempotencyCacheKey, s)
+	}
+
+	return s
+}
+
+// acquire returns the locked idempotency entry with the
+// provided key (creating it if missing or expired).
+func (s *idempotencyStore) acquire(key string) *idempotencyEntry {
+	s.mux.Lock()
+
+	now := time.Now()
+
+	entry := s.entries[key]
+	if entry == nil || now.After(entry.expires) {
+		// periodically drop the expired entries
+		if now.Sub(s.lastPrune) > time.Minute || len(s.entries) >= IdempotencyMaxEntries {
+			s.prune(now)
+		}
+
+		entry = &idempotencyEntry{expires: now.Add(IdempotencyKeyTtl)}
+		s.entries[key] = entry
+	}
+
+	s.mux.Unlock()
+
+	// wait for any concurrent request with the same key to complete
+	entry.mux.Lock()
+
+	return entry
+}
+
+// release unlocks the provided idempotency entry and removes it
+// from the store if no response was stored for its request
+// (eg. failed or too large response), so that only the completed
+// entries could accumulate in the store.
+func (s *idempotencyStore) release(key string, entry *idempotencyEntry) {
+	if !entry.done {
+		s.mux.Lock()
+		// the entry could have been already replaced (eg. expired or evicted)
+		if s.entries[key] == entry {
+			delete(s.entries, key)
+		}
+		s.mux.Unlock()
+	}
+
+	entry.mux.Unlock()
+}
+
+// prune drops the expired entries and, if the store is still full,
+// evicts the oldest completed ones to free space for a new entry.
+//
+// The in-progress entries are never evicted (their requests
+// hold the entry lock), so the limit could be exceeded by the
+// number of concurrent requests.
+func (s *idempotencyStore) prune(now time.Time) {
+	s.lastPrune = now
+
+	completed := make([]string, 0, len(s.entries))
+
+	for k, v := range s.entries {
+		if now.After(v.expires) {
+			delete(s.entries, k)
+			continue
+		}
+
+		if v.mux.TryLock() {
+			if v.done {
+				completed = append(completed, k)
+			}
+			v.mux.Unlock()
+		}
+	}
+
+	excess := len(s.entries) - IdempotencyMaxEntries + 1
+	if excess <= 0 {
+		return
+	}
+
+	sort.Slice(completed, func(i, j int) bool {
+		return s.entries[completed[i]].expires.Before(s.entries[completed[j]].expires)
+	})
+
+	for i := 0; i < excess && i < len(completed); i++ {
+		delete(s.entries, completed[i])
+	}
+}
+
+// idempotencyRecorder is a [http.ResponseWriter] that keeps a copy
+// of the written response body (up to [IdempotencyMaxResponseSize]).
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	body     bytes.Buffer
+	exceeded bool
+}
+
+func (r *idempotencyRecorder) Write(b []byte) (int, error) {
+	if !r.exceeded {
+		if r.body.Len()+len(b) > IdempotencyMaxResponseSize {
+			r.exceeded = true
+			r.body = bytes.Buffer{}
+		} else {
+			r.body.Write(b)
+		}
+	}
+
+	return r.ResponseWriter.Write(b)
+}
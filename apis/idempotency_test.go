@@ -0,0 +1,262 @@
+package apis_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestIdempotency(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	e, err := apis.InitApi(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collection, err := app.Dao().FindCollectionByNameOrId("demo3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	send := func(url string, body string, key string, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = ip + ":1234"
+		if key != "" {
+			req.Header.Set(apis.IdempotencyKeyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	countRecords := func() int {
+		var total int
+		app.Dao().RecordQuery(collection).Select("count(*)").Row(&total)
+		return total
+	}
+
+	url := "/api/collections/demo3/records"
+	initialTotal := countRecords()
+
+	// no key
+	// ---
+	send(url, `{"title":"a"}`, "", "1.1.1.1")
+	send(url, `{"title":"a"}`, "", "1.1.1.1")
+	if total := countRecords(); total != initialTotal+2 {
+		t.Fatalf("Expected %d records without idempotency key, got %d", initialTotal+2, total)
+	}
+	initialTotal += 2
+
+	// repeated key
+	// ---
+	first := send(url, `{"title":"b"}`, "key1", "1.1.1.1")
+	if first.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d (%s)", first.Code, first.Body.String())
+	}
+	if v := first.Header().Get(apis.IdempotencyReplayedHeader); v != "" {
+		t.Fatalf("Expected no replayed header for the first request, got %q", v)
+	}
+
+	second := send(url, `{"title":"b"}`, "key1", "1.1.1.1")
+	if second.Code != http.StatusOK {
+		t.Fatalf("Expected replayed status 200, got %d", second.Code)
+	}
+	if v := second.Header().Get(apis.IdempotencyReplayedHeader); v != "true" {
+		t.Fatalf("Expected replayed header to be true, got %q", v)
+	}
+	if second.Body.String() != first.Body.String() {
+		t.Fatalf("Expected replayed body %q, got %q", first.Body.String(), second.Body.String())
+	}
+	if ct := second.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Fatalf("Expected replayed json content type, got %q", ct)
+	}
+	if total := countRecords(); total != initialTotal+1 {
+		t.Fatalf("Expected %d records after the repeated key, got %d", initialTotal+1, total)
+	}
+	initialTotal++
+
+	// the same key of a different client
+	// ---
+	other := send(url, `{"title":"b"}`, "key1", "2.2.2.2")
+	if other.Header().Get(apis.IdempotencyReplayedHeader) != "" {
+		t.Fatal("Expected the key to be scoped to the request client")
+	}
+	if total := countRecords(); total != initialTotal+1 {
+		t.Fatalf("Expected %d records after another client request, got %d", initialTotal+1, total)
+	}
+	initialTotal++
+
+	// failed requests are not stored
+	// ---
+	failed := send(url, `{"`, "key2", "1.1.1.1")
+	if failed.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", failed.Code)
+	}
+	retried := send(url, `{"title":"c"}`, "key2", "1.1.1.1")
+	if retried.Code != http.StatusOK || retried.Header().Get(apis.IdempotencyReplayedHeader) != "" {
+		t.Fatalf("Expected the failed request to be retried, got %d (%s)", retried.Code, retried.Body.String())
+	}
+	initialTotal++
+
+	// the same key with a different body
+	// ---
+	mismatch := send(url, `{"title":"other"}`, "key1", "1.1.1.1")
+	if mismatch.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status 422 for a different body, got %d (%s)", mismatch.Code, mismatch.Body.String())
+	}
+	if total := countRecords(); total != initialTotal {
+		t.Fatalf("Expected %d records after the different body request, got %d", initialTotal, total)
+	}
+
+	// too long key
+	// ---
+	if rec := send(url, `{"title":"d"}`, strings.Repeat("a", 256), "1.1.1.1"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400 for too long key, got %d", rec.Code)
+	}
+
+	// concurrent requests with the same key
+	// ---
+	var wg sync.WaitGroup
+	bodies := make([]string, 5)
+	for i := range bodies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			bodies[i] = send(url, `{"title":"e"}`, "key3", "1.1.1.1").Body.String()
+		}(i)
+	}
+	wg.Wait()
+
+	for _, body := range bodies {
+		if body != bodies[0] {
+			t.Fatalf("Expected all concurrent responses to be the same, got %v", bodies)
+		}
+	}
+	if total := countRecords(); total != initialTotal+1 {
+		t.Fatalf("Expected %d records after the concurrent requests, got %d", initialTotal+1, total)
+	}
+	initialTotal++
+
+	// batch
+	// ---
+	batchUrl := url + "/batch"
+	batchBody := `{"records":[{"title":"f1"},{"title":"f2"}]}`
+	send(batchUrl, batchBody, "key4", "1.1.1.1")
+	batchReplay := send(batchUrl, batchBody, "key4", "1.1.1.1")
+	if batchReplay.Header().Get(apis.IdempotencyReplayedHeader) != "true" {
+		t.Fatalf("Expected the batch response to be replayed, got %q", batchReplay.Body.String())
+	}
+	if total := countRecords(); total != initialTotal+2 {
+		t.Fatalf("Expected %d records after the batch requests, got %d", initialTotal+2, total)
+	}
+}
+
+func TestIdempotencyLimits(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	e, err := apis.InitApi(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	send := func(e *echo.Echo, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/collections/demo3/records", strings.NewReader(`{"title":"test"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(apis.IdempotencyKeyHeader, key)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	isReplayed := func(rec *httptest.ResponseRecorder) bool {
+		return rec.Header().Get(apis.IdempotencyReplayedHeader) == "true"
+	}
+
+	oldMaxEntries := apis.IdempotencyMaxEntries
+	oldMaxResponseSize := apis.IdempotencyMaxResponseSize
+	defer func() {
+		apis.IdempotencyMaxEntries = oldMaxEntries
+		apis.IdempotencyMaxResponseSize = oldMaxResponseSize
+	}()
+
+	// max entries (the oldest entry is evicted)
+	// ---
+	apis.IdempotencyMaxEntries = 2
+
+	send(e, "a")
+	send(e, "b")
+	send(e, "c")
+
+	if !isReplayed(send(e, "c")) || !isReplayed(send(e, "b")) {
+		t.Fatal("Expected the newest entries to be kept")
+	}
+	if isReplayed(send(e, "a")) {
+		t.Fatal("Expected the oldest entry to be evicted")
+	}
+
+	// failed requests don't occupy the store
+	// (the completed entries are not evicted)
+	// ---
+	apis.IdempotencyMaxEntries = 3
+
+	send(e, "d")
+	send(e, "e")
+
+	for i := 0; i < 10; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/api/collections/demo3/records", strings.NewReader(`{`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(apis.IdempotencyKeyHeader, "failed"+strconv.Itoa(i))
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("Expected the request to fail with 400, got %d", rec.Code)
+		}
+	}
+
+	if !isReplayed(send(e, "d")) || !isReplayed(send(e, "e")) {
+		t.Fatal("Expected the completed entries to not be evicted by the failed requests")
+	}
+
+	// max response size (too large responses are not stored)
+	// ---
+	apis.IdempotencyMaxEntries = oldMaxEntries
+	apis.IdempotencyMaxResponseSize = 10
+
+	send(e, "large")
+	if isReplayed(send(e, "large")) {
+		t.Fatal("Expected the too large response to not be stored")
+	}
+
+	apis.IdempotencyMaxResponseSize = oldMaxResponseSize
+
+	// the keys are scoped to the app
+	// ---
+	send(e, "app")
+
+	app2, _ := tests.NewTestApp()
+	defer app2.Cleanup()
+
+	e2, err := apis.InitApi(app2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if isReplayed(send(e2, "app")) {
+		t.Fatal("Expected the idempotency keys to not be shared between apps")
+	}
+
+	if !isReplayed(send(e, "app")) {
+		t.Fatal("Expected the app key to be replayed")
+	}
+}
@@ -0,0 +1,57 @@
+package apis
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/spf13/cast"
+)
+
+// prettyJsonIndent is the indentation of the pretty printed JSON responses.
+const prettyJsonIndent = "  "
+
+// prettyJsonRoutesPrefix is the path prefix of the routes with
+// configurable JSON responses indentation (the records and collections apis).
+const prettyJsonRoutesPrefix = "/api/collections"
+
+// jsonSerializer extends the default echo JSON serializer with
+// configurable indentation of the records and collections api responses.
+//
+// The responses are written directly to the response writer as with
+// the default serializer, so the indentation doesn't affect the
+// content length handling of the other middlewares (eg. gzip).
+type jsonSerializer struct {
+	echo.DefaultJSONSerializer
+
+	app core.App
+}
+
+// Serialize implements [echo.JSONSerializer.Serialize].
+func (s *jsonSerializer) Serialize(c echo.Context, i any, indent string) error {
+	if strings.HasPrefix(c.Path(), prettyJsonRoutesPrefix) {
+		// replace the echo default "?pretty" handling
+		// (which is available for all clients)
+		indent = ""
+		if s.app.IsDebug() || isPrettyJsonRequested(s.app, c) {
+			indent = prettyJsonIndent
+		}
+	}
+
+	return s.DefaultJSONSerializer.Serialize(c, i, indent)
+}
+
+// isPrettyJsonRequested checks whether the JSON response of the
+// current request should be indented.
+//
+// The "?pretty=true" query parameter is taken into account only for admins.
+func isPrettyJsonRequested(app core.App, c echo.Context) bool {
+	if app.Settings().JsonResponses.Pretty {
+		return true
+	}
+
+	admin, _ := c.Get(ContextAdminKey).(*models.Admin)
+
+	return admin != nil && cast.ToBool(c.QueryParam("pretty"))
+}
@@ -0,0 +1,89 @@
+package apis
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/spf13/cast"
+)
+
+const (
+	// envelopeQueryParam is the query parameter used to disable the list response envelope.
+	envelopeQueryParam = "envelope"
+
+	// unenveloped list response pagination headers
+	headerTotalCount          = "X-Total-Count"
+	headerTotalCountEstimated = "X-Total-Count-Estimated"
+	headerPage                = "X-Page"
+	headerPerPage             = "X-Per-Page"
+)
+
+// listResponse writes the provided search result as json response.
+//
+// By default the result is returned as it is (aka. items + pagination envelope).
+// If the request has `?envelope=false`, only the result items array is returned
+// and the pagination info is moved in the X-Total-Count, X-Page and X-Per-Page
+// response headers (+ X-Total-Count-Estimated if the total is approximate).
+//
+// The pagination Link header is always set.
+func listResponse(c echo.Context, result *search.Result) error {
+	header := c.Response().Header()
+
+	if link := paginationLinkHeader(c, result); link != "" {
+		header.Set("Link", link)
+	}
+
+	rawEnvelope := c.QueryParam(envelopeQueryParam)
+	if rawEnvelope == "" || cast.ToBool(rawEnvelope) {
+		return c.JSON(http.StatusOK, result)
+	}
+
+	header.Set(headerTotalCount, strconv.Itoa(result.TotalItems))
+	if result.TotalEstimated {
+		header.Set(headerTotalCountEstimated, "true")
+	}
+	header.Set(headerPage, strconv.Itoa(result.Page))
+	header.Set(headerPerPage, strconv.Itoa(result.PerPage))
+
+	return c.JSON(http.StatusOK, result.Items)
+}
+
+// paginationLinkHeader builds a RFC 8288 Link header value with
+// the first, prev, next and last pages of the provided search result.
+func paginationLinkHeader(c echo.Context, result *search.Result) string {
+	if result.PerPage <= 0 {
+		return ""
+	}
+
+	totalPages := int(math.Ceil(float64(result.TotalItems) / float64(result.PerPage)))
+	if totalPages < 1 {
+		totalPages = 1
+	}
+
+	pageUrl := func(page int) string {
+		u := *c.Request().URL
+		query := u.Query()
+		query.Set(search.PageQueryParam, strconv.Itoa(page))
+		u.RawQuery = query.Encode()
+		return withBasePath(c, u.RequestURI())
+	}
+
+	links := []string{fmt.Sprintf(`<%s>; rel="first"`, pageUrl(1))}
+
+	if result.Page > 1 {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageUrl(result.Page-1)))
+	}
+
+	if result.Page < totalPages {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageUrl(result.Page+1)))
+	}
+
+	links = append(links, fmt.Sprintf(`<%s>; rel="last"`, pageUrl(totalPages)))
+
+	return strings.Join(links, ", ")
+}
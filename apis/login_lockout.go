@@ -0,0 +1,189 @@
+package apis
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/rest"
+	"github.com/pocketbase/pocketbase/tools/store"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	// loginLockoutCacheKey is the app cache key of the failed login attempts store.
+	loginLockoutCacheKey = "@loginLockouts"
+
+	// loginLockoutMaxEntries is the max number of tracked accounts
+	// (limits the memory usage in case of random emails flood).
+	loginLockoutMaxEntries = 10000
+)
+
+// LoginLockout defines the failed login attempts state of a single account.
+type LoginLockout struct {
+	Key         string         `json:"key"`
+	Failures    int            `json:"failures"`
+	LastFailure types.DateTime `json:"lastFailure"`
+	LockedUntil types.DateTime `json:"lockedUntil"`
+}
+
+var loginLockoutMux sync.Mutex
+
+// BindLoginLockoutApi registers the login lockouts api endpoints.
+func BindLoginLockoutApi(app core.App, rg *echo.Group) {
+	api := loginLockoutApi{app: app}
+
+	subGroup := rg.Group("/lockouts", ActivityLogger(app), RequireSuperAdminAuth())
+	subGroup.GET("", api.list)
+	subGroup.DELETE("", api.clear)
+	subGroup.DELETE("/:key", api.delete)
+}
+
+type loginLockoutApi struct {
+	app core.App
+}
+
+func (api *loginLockoutApi) list(c echo.Context) error {
+	result := []*LoginLockout{}
+
+	now := time.Now()
+	for _, item := range loginLockoutStore(api.app).GetAll() {
+		if !isLoginLockoutExpired(api.app, item, now) {
+			result = append(result, item)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+
+	return c.JSON(http.StatusOK, result)
+}
+
+func (api *loginLockoutApi) clear(c echo.Context) error {
+	api.app.Cache().Remove(loginLockoutCacheKey)
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (api *loginLockoutApi) delete(c echo.Context) error {
+	key := c.PathParam("key")
+
+	s := loginLockoutStore(api.app)
+	if key == "" || !s.Has(key) {
+		return rest.NewNotFoundError("", nil)
+	}
+
+	s.Remove(key)
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// -------------------------------------------------------------------
+
+// loginLockoutKey returns the login lockout key of the provided auth type and email.
+func loginLockoutKey(authType string, email string) string {
+	return authType + ":" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// loginLockoutStore returns the app failed login attempts store (creating it if missing).
+func loginLockoutStore(app core.App) *store.Store[*LoginLockout] {
+	loginLockoutMux.Lock()
+	defer loginLockoutMux.Unlock()
+
+	s, _ := app.Cache().Get(loginLockoutCacheKey).(*store.Store[*LoginLockout])
+	if s == nil {
+		s = store.New(map[string]*LoginLockout{})
+		app.Cache().Set(loginLockoutCacheKey, s)
+	}
+
+	return s
+}
+
+// isLoginLockoutExpired checks whether the failed attempts of the
+// provided lockout item are old enough to be ignored.
+func isLoginLockoutExpired(app core.App, item *LoginLockout, now time.Time) bool {
+	window := time.Duration(app.Settings().LoginLockout.Duration) * time.Second
+
+	return now.After(item.LastFailure.Time().Add(window)) && now.After(item.LockedUntil.Time())
+}
+
+// beginLoginAttempt registers a new login attempt for the provided key.
+//
+// It returns 429 error (with Retry-After header) if the login is
+// currently locked. Otherwise the attempt is counted in advance as
+// failed (and locks the login if the max allowed attempts are reached)
+// until it is reset with [resetLoginFailures] on successful login.
+//
+// The check and the counter increment are performed under a single
+// lock, so that the concurrent attempts can't bypass the limit.
+func beginLoginAttempt(app core.App, c echo.Context, key string) error {
+	settings := app.Settings().LoginLockout
+	if !settings.Enabled {
+		return nil
+	}
+
+	s := loginLockoutStore(app)
+
+	loginLockoutMux.Lock()
+	defer loginLockoutMux.Unlock()
+
+	now := time.Now()
+
+	item := s.Get(key)
+
+	if item != nil {
+		if retryAfter := item.LockedUntil.Time().Sub(now); retryAfter > 0 {
+			setRetryAfterHeader(c, retryAfter)
+
+			return rest.NewApiError(http.StatusTooManyRequests, "Too many failed login attempts. Please try again later.", nil)
+		}
+	}
+
+	if item == nil || isLoginLockoutExpired(app, item, now) {
+		item = &LoginLockout{Key: key}
+	} else {
+		clone := *item
+		item = &clone
+	}
+
+	item.Failures++
+	item.LastFailure, _ = types.ParseDateTime(now)
+
+	if item.Failures >= settings.MaxAttempts {
+		item.LockedUntil, _ = types.ParseDateTime(now.Add(time.Duration(settings.Duration) * time.Second))
+	}
+
+	if !s.SetIfLessThanLimit(key, item, loginLockoutMaxEntries) {
+		// drop the expired entries and try again
+		for k, v := range s.GetAll() {
+			if isLoginLockoutExpired(app, v, now) {
+				s.Remove(k)
+			}
+		}
+		s.SetIfLessThanLimit(key, item, loginLockoutMaxEntries)
+	}
+
+	return nil
+}
+
+// setRetryAfterHeader sets the response Retry-After header
+// with the provided duration rounded up to seconds.
+func setRetryAfterHeader(c echo.Context, retryAfter time.Duration) {
+	seconds := int(retryAfter.Seconds())
+	if retryAfter > time.Duration(seconds)*time.Second {
+		seconds++ // round up
+	}
+
+	c.Response().Header().Set("Retry-After", strconv.Itoa(seconds))
+}
+
+// resetLoginFailures removes the failed attempts state of the provided key.
+func resetLoginFailures(app core.App, key string) {
+	loginLockoutStore(app).Remove(key)
+}
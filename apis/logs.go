@@ -15,10 +15,13 @@ import (
 func BindLogsApi(app core.App, rg *echo.Group) {
 	api := logsApi{app: app}
 
-	subGroup := rg.Group("/logs", RequireAdminAuth())
+	subGroup := rg.Group("/logs", RequireSuperAdminAuth())
 	subGroup.GET("/requests", api.requestsList)
 	subGroup.GET("/requests/stats", api.requestsStats)
+	subGroup.GET("/requests/stream", api.requestsStream)
 	subGroup.GET("/requests/:id", api.requestView)
+
+	api.bindStreamEvents()
 }
 
 type logsApi struct {
@@ -36,13 +39,14 @@ func (api *logsApi) requestsList(c echo.Context) error {
 
 	result, err := search.NewProvider(fieldResolver).
 		Query(api.app.LogsDao().RequestQuery()).
+		Retry(api.app.LogsDao().WithRetry).
 		ParseAndExec(c.QueryString(), &[]*models.Request{})
 
 	if err != nil {
 		return rest.NewBadRequestError("", err)
 	}
 
-	return c.JSON(http.StatusOK, result)
+	return listResponse(c, result)
 }
 
 func (api *logsApi) requestsStats(c echo.Context) error {
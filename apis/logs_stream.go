@@ -0,0 +1,304 @@
+package apis
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/rest"
+	"github.com/pocketbase/pocketbase/tools/subscriptions"
+	"github.com/spf13/cast"
+)
+
+const (
+	// LogsStreamSubscription is the realtime subscription name of the
+	// new request logs (available only for super admins).
+	LogsStreamSubscription = "@logs/requests"
+
+	// LogsStreamDefaultBackfill is the default number of the last
+	// request logs sent on a logs stream connect.
+	LogsStreamDefaultBackfill = 20
+
+	// LogsStreamMaxBackfill is the max allowed number of the last
+	// request logs sent on a logs stream connect.
+	LogsStreamMaxBackfill = 500
+
+	// logsStreamFilterKey is the subscription client key of the logs stream filter.
+	logsStreamFilterKey = "@logsStreamFilter"
+
+	// logsStreamSendTimeout is the max time to wait for a busy
+	// logs stream client before dropping a log message.
+	logsStreamSendTimeout = 5 * time.Second
+)
+
+// requestsStream streams the new request logs as server-sent events.
+//
+// The last request logs (see the "backfill" query parameter) are sent
+// right after the connect message and they are optionally filtered by
+// the "status" (eg. "404,5xx"), "method" (eg. "get,post") and "path"
+// (url prefix) query parameters.
+func (api *logsApi) requestsStream(c echo.Context) error {
+	filter, err := newRequestLogsFilter(c)
+	if err != nil {
+		return rest.NewBadRequestError("Invalid logs stream filter.", err)
+	}
+
+	backfill := LogsStreamDefaultBackfill
+	if v := c.QueryParam("backfill"); v != "" {
+		backfill, err = strconv.Atoi(v)
+		if err != nil || backfill < 0 || backfill > LogsStreamMaxBackfill {
+			return rest.NewBadRequestError(fmt.Sprintf("The backfill must be a number between 0 and %d.", LogsStreamMaxBackfill), err)
+		}
+	}
+
+	// register new subscription client
+	client := subscriptions.NewDefaultClient()
+	client.Set(ContextAdminKey, c.Get(ContextAdminKey))
+	client.Set(logsStreamFilterKey, filter)
+	client.Subscribe(LogsStreamSubscription)
+	api.app.SubscriptionsBroker().Register(client)
+	defer api.app.SubscriptionsBroker().Unregister(client.Id())
+
+	c.Response().Header().Set("Content-Type", "text/event-stream; charset=UTF-8")
+	c.Response().Header().Set("Cache-Control", "no-store")
+	c.Response().Header().Set("Connection", "keep-alive")
+
+	w := c.Response()
+
+	// signalize established connection (aka. fire "connect" message)
+	writeServerSentEvent(w, client.Id(), "PB_CONNECT", `{"clientId":"`+client.Id()+`"}`)
+
+	if backfill > 0 {
+		logs := []*models.Request{}
+
+		query := api.app.LogsDao().RequestQuery().
+			OrderBy("created DESC", "rowid DESC").
+			Limit(int64(backfill))
+
+		if expr := filter.buildExpr(); expr != nil {
+			query.AndWhere(expr)
+		}
+
+		if err := query.All(&logs); err != nil && api.app.IsDebug() {
+			api.app.Logger().Println("Failed to load the logs stream backfill:", err)
+		}
+
+		// send the oldest first
+		for i := len(logs) - 1; i >= 0; i-- {
+			data, _ := json.Marshal(logs[i])
+			writeServerSentEvent(w, client.Id(), LogsStreamSubscription, string(data))
+		}
+	}
+
+	w.Flush()
+
+	for {
+		select {
+		case msg, ok := <-client.Channel():
+			if !ok {
+				return nil // channel is closed
+			}
+
+			writeServerSentEvent(w, client.Id(), msg.Name, msg.Data)
+			w.Flush()
+		case <-c.Request().Context().Done():
+			return nil // connection is closed
+		}
+	}
+}
+
+// bindStreamEvents registers the app event handlers that broadcast
+// the new request logs to the logs stream subscribers.
+func (api *logsApi) bindStreamEvents() {
+	requestsTable := (&models.Request{}).TableName()
+
+	api.app.OnModelAfterCreate().Add(func(e *core.ModelEvent) error {
+		if e.Model.TableName() != requestsTable {
+			return nil
+		}
+
+		log, _ := e.Model.(*models.Request)
+		if log == nil {
+			return nil
+		}
+
+		api.broadcastRequestLog(log)
+
+		return nil
+	})
+}
+
+// broadcastRequestLog sends the provided request log to all
+// logs stream subscribers whose filter matches the log.
+func (api *logsApi) broadcastRequestLog(log *models.Request) {
+	var data []byte
+
+	for _, client := range api.app.SubscriptionsBroker().Clients() {
+		if !client.HasSubscription(LogsStreamSubscription) {
+			continue
+		}
+
+		admin, _ := client.Get(ContextAdminKey).(*models.Admin)
+		if admin == nil || !admin.IsSuper() {
+			continue
+		}
+
+		// the clients subscribed through the realtime api have no filter
+		if filter, _ := client.Get(logsStreamFilterKey).(*requestLogsFilter); filter != nil && !filter.matches(log) {
+			continue
+		}
+
+		if data == nil {
+			var err error
+			if data, err = json.Marshal(log); err != nil {
+				return
+			}
+		}
+
+		msg := subscriptions.Message{
+			Name: LogsStreamSubscription,
+			Data: string(data),
+		}
+
+		select {
+		case client.Channel() <- msg:
+		case <-time.After(logsStreamSendTimeout):
+			// drop the message for unresponsive (or already disconnected) clients
+		}
+	}
+}
+
+// writeServerSentEvent writes a single server-sent event message
+// (the caller is responsible for flushing the response).
+func writeServerSentEvent(w http.ResponseWriter, id string, name string, data string) {
+	fmt.Fprint(w, "id:"+id+"\n")
+	fmt.Fprint(w, "event:"+name+"\n")
+	fmt.Fprint(w, "data:"+data+"\n\n")
+}
+
+// -------------------------------------------------------------------
+
+// logsStreamStatusRegex matches a valid status filter value
+// (exact status code or a status class, eg. "404", "40x", "4xx").
+var logsStreamStatusRegex = regexp.MustCompile(`^[1-5](\d\d|\dx|xx)$`)
+
+// requestLogsFilter defines the logs stream request logs filter.
+type requestLogsFilter struct {
+	// statuses could be exact status codes (eg. "404") or classes (eg. "5xx")
+	statuses   []string
+	methods    []string
+	pathPrefix string
+}
+
+// newRequestLogsFilter creates a new request logs filter
+// from the "status", "method" and "path" query parameters.
+func newRequestLogsFilter(c echo.Context) (*requestLogsFilter, error) {
+	filter := &requestLogsFilter{
+		statuses:   []string{},
+		methods:    []string{},
+		pathPrefix: c.QueryParam("path"),
+	}
+
+	for _, status := range strings.Split(c.QueryParam("status"), ",") {
+		status = strings.ToLower(strings.TrimSpace(status))
+		if status == "" {
+			continue
+		}
+
+		if !logsStreamStatusRegex.MatchString(status) {
+			return nil, fmt.Errorf("invalid status %q", status)
+		}
+
+		filter.statuses = append(filter.statuses, status)
+	}
+
+	for _, method := range strings.Split(c.QueryParam("method"), ",") {
+		if method = strings.ToLower(strings.TrimSpace(method)); method != "" {
+			filter.methods = append(filter.methods, method)
+		}
+	}
+
+	return filter, nil
+}
+
+// statusRange returns the [min, max) status codes range of the provided
+// status filter value (eg. "5xx" -> [500, 600), "404" -> [404, 405)).
+func (f *requestLogsFilter) statusRange(status string) (int, int) {
+	prefix := strings.TrimRight(status, "x")
+
+	step := 1
+	for i := len(prefix); i < 3; i++ {
+		step *= 10
+	}
+
+	min := cast.ToInt(prefix) * step
+
+	return min, min + step
+}
+
+// matches checks whether the provided request log satisfies the filter.
+func (f *requestLogsFilter) matches(log *models.Request) bool {
+	if len(f.methods) > 0 && !list.ExistInSlice(log.Method, f.methods) {
+		return false
+	}
+
+	if f.pathPrefix != "" && !strings.HasPrefix(log.Url, f.pathPrefix) {
+		return false
+	}
+
+	if len(f.statuses) == 0 {
+		return true
+	}
+
+	for _, status := range f.statuses {
+		if min, max := f.statusRange(status); log.Status >= min && log.Status < max {
+			return true
+		}
+	}
+
+	return false
+}
+
+// buildExpr returns the db expression of the filter.
+func (f *requestLogsFilter) buildExpr() dbx.Expression {
+	exprs := []dbx.Expression{}
+
+	if len(f.methods) > 0 {
+		methods := make([]any, len(f.methods))
+		for i, method := range f.methods {
+			methods[i] = method
+		}
+		exprs = append(exprs, dbx.In("method", methods...))
+	}
+
+	if f.pathPrefix != "" {
+		exprs = append(exprs, dbx.Like("url", f.pathPrefix).Match(false, true))
+	}
+
+	if len(f.statuses) > 0 {
+		statusExprs := make([]dbx.Expression, len(f.statuses))
+		for i, status := range f.statuses {
+			min, max := f.statusRange(status)
+			statusExprs[i] = dbx.NewExp(
+				fmt.Sprintf("[[status]] >= {:statusMin%d} AND [[status]] < {:statusMax%d}", i, i),
+				dbx.Params{fmt.Sprintf("statusMin%d", i): min, fmt.Sprintf("statusMax%d", i): max},
+			)
+		}
+		exprs = append(exprs, dbx.Or(statusExprs...))
+	}
+
+	if len(exprs) == 0 {
+		return nil
+	}
+
+	return dbx.And(exprs...)
+}
@@ -2,7 +2,7 @@ package apis
 
 import (
 	"fmt"
-	"log"
+	"net"
 	"net/http"
 	"strings"
 	"time"
@@ -77,8 +77,57 @@ func RequireAdminAuth() echo.MiddlewareFunc {
 	}
 }
 
-// RequireAdminAuthIfAny middleware requires a request to have
+// RequireSuperAdminAuth middleware requires a request to have
 // a valid admin Authorization header set (aka. `Authorization: Admin ...`)
+// of an admin with full (non collection restricted) access.
+func RequireSuperAdminAuth() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			admin, _ := c.Get(ContextAdminKey).(*models.Admin)
+			if admin == nil {
+				return rest.NewUnauthorizedError("The request requires admin authorization token to be set.", nil)
+			}
+
+			if !admin.IsSuper() {
+				return rest.NewForbiddenError("The request requires super admin permissions.", nil)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// RequireAdminCollectionAccess middleware forbids the requests of
+// collection restricted admins without access to the context collection
+// (read access for GET and HEAD requests and write access for all others).
+//
+// This middleware expects the collection to be already loaded in
+// the request context, so it should be registered after [apis.LoadCollectionContext()].
+func RequireAdminCollectionAccess() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			admin, _ := c.Get(ContextAdminKey).(*models.Admin)
+			collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+			if admin == nil || collection == nil {
+				return next(c)
+			}
+
+			method := c.Request().Method
+			if method == http.MethodGet || method == http.MethodHead {
+				if !admin.Permissions.CanRead(collection.Id) {
+					return rest.NewForbiddenError("You are not allowed to access this collection.", nil)
+				}
+			} else if !admin.Permissions.CanWrite(collection.Id) {
+				return rest.NewForbiddenError("You are not allowed to modify this collection.", nil)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// RequireAdminAuthIfAny middleware requires a request to have
+// a valid super admin Authorization header set (aka. `Authorization: Admin ...`)
 // ONLY if the application has at least 1 existing Admin model.
 func RequireAdminAuthOnlyIfAny(app core.App) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -90,7 +139,15 @@ func RequireAdminAuthOnlyIfAny(app core.App) echo.MiddlewareFunc {
 
 			admin, _ := c.Get(ContextAdminKey).(*models.Admin)
 
-			if admin != nil || totalAdmins == 0 {
+			if totalAdmins == 0 {
+				return next(c)
+			}
+
+			if admin != nil {
+				if !admin.IsSuper() {
+					return rest.NewForbiddenError("The request requires super admin permissions.", nil)
+				}
+
 				return next(c)
 			}
 
@@ -126,6 +183,8 @@ func RequireAdminOrUserAuth() echo.MiddlewareFunc {
 // This middleware is similar to [apis.RequireAdminOrUserAuth()] but
 // for the user token expects to have the same id as the path parameter
 // `ownerIdParam` (default to "id").
+//
+// Collection restricted admins are rejected.
 func RequireAdminOrOwnerAuth(ownerIdParam string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -145,6 +204,11 @@ func RequireAdminOrOwnerAuth(ownerIdParam string) echo.MiddlewareFunc {
 				return rest.NewForbiddenError("You are not allowed to perform this request.", nil)
 			}
 
+			// collection restricted admins are not allowed to manage the users
+			if admin != nil && !admin.IsSuper() {
+				return rest.NewForbiddenError("The request requires super admin permissions.", nil)
+			}
+
 			return next(c)
 		}
 	}
@@ -160,11 +224,18 @@ func LoadAuthContext(app core.App) echo.MiddlewareFunc {
 		return func(c echo.Context) error {
 			token := c.Request().Header.Get("Authorization")
 
+			// fallback to the auth cookie (if enabled)
+			var fromCookie bool
+			if token == "" && app.Settings().CookieAuth.Enabled {
+				token = cookieAuthToken(c)
+				fromCookie = token != ""
+			}
+
 			if token != "" {
 				if strings.HasPrefix(token, "User ") {
 					user, err := app.Dao().FindUserByToken(
 						token[5:],
-						app.Settings().UserAuthToken.Secret,
+						app.Settings().UserAuthToken.VerificationSecret(token[5:]),
 					)
 					if err == nil && user != nil {
 						c.Set(ContextUserKey, user)
@@ -172,7 +243,7 @@ func LoadAuthContext(app core.App) echo.MiddlewareFunc {
 				} else if strings.HasPrefix(token, "Admin ") {
 					admin, err := app.Dao().FindAdminByToken(
 						token[6:],
-						app.Settings().AdminAuthToken.Secret,
+						app.Settings().AdminAuthToken.VerificationSecret(token[6:]),
 					)
 					if err == nil && admin != nil {
 						c.Set(ContextAdminKey, admin)
@@ -180,6 +251,14 @@ func LoadAuthContext(app core.App) echo.MiddlewareFunc {
 				}
 			}
 
+			// the cookies are sent automatically by the browsers,
+			// so the non-safe cookie authenticated requests must be
+			// explicitly confirmed with the double-submit CSRF token
+			isAuthenticated := c.Get(ContextUserKey) != nil || c.Get(ContextAdminKey) != nil
+			if fromCookie && isAuthenticated && !hasValidCsrfToken(c) {
+				return rest.NewForbiddenError("Missing or invalid CSRF token.", nil)
+			}
+
 			return next(c)
 		}
 	}
@@ -204,6 +283,182 @@ func LoadCollectionContext(app core.App) echo.MiddlewareFunc {
 	}
 }
 
+// RequireNoActiveRestore middleware rejects all requests with
+// 503 Service Unavailable while an app backup restore is in progress.
+func RequireNoActiveRestore(app core.App) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if app.Cache().Has(core.CacheKeyActiveRestore) {
+				return rest.NewApiError(http.StatusServiceUnavailable, "A backup restore is in progress. Please try again later.", nil)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// EnforceHttps middleware redirects the plain HTTP requests to HTTPS
+// and sets the Strict-Transport-Security header for the secure ones
+// based on the app Https settings.
+//
+// The X-Forwarded-Proto header is taken into account only for requests
+// coming from one of the configured trusted proxies (to prevent spoofing).
+//
+// This middleware is expected to be registered by default for all routes.
+func EnforceHttps(app core.App) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			config := app.Settings().Https
+
+			if !isSecureRequest(app, c) {
+				if config.Redirect {
+					status := http.StatusPermanentRedirect
+					if method := c.Request().Method; method == http.MethodGet || method == http.MethodHead {
+						status = http.StatusMovedPermanently
+					}
+
+					return c.Redirect(status, "https://"+c.Request().Host+withBasePath(c, c.Request().URL.RequestURI()))
+				}
+
+				return next(c)
+			}
+
+			if config.HstsMaxAge > 0 {
+				value := fmt.Sprintf("max-age=%d", config.HstsMaxAge)
+				if config.HstsIncludeSubdomains {
+					value += "; includeSubDomains"
+				}
+				if config.HstsPreload {
+					value += "; preload"
+				}
+				c.Response().Header().Set(echo.HeaderStrictTransportSecurity, value)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// isSecureRequest checks whether the request was made over HTTPS,
+// either directly or through a trusted TLS-terminating proxy.
+func isSecureRequest(app core.App, c echo.Context) bool {
+	if c.IsTLS() {
+		return true
+	}
+
+	if c.Request().Header.Get(echo.HeaderXForwardedProto) != "https" {
+		return false
+	}
+
+	return app.Settings().Https.IsTrustedProxy(remoteIp(c))
+}
+
+// clientIp returns the request client ip address.
+//
+// The X-Forwarded-For and X-Real-IP headers are taken into account
+// only for requests coming from a trusted proxy.
+//
+// Since the leftmost X-Forwarded-For entries could be set by the client,
+// the header is walked from the right (skipping the trusted proxies hops)
+// and the first untrusted entry is returned.
+func clientIp(app core.App, c echo.Context) string {
+	ip := remoteIp(c)
+
+	https := app.Settings().Https
+
+	if !https.IsTrustedProxy(ip) {
+		return ip
+	}
+
+	if forwarded := c.Request().Header.Get(echo.HeaderXForwardedFor); forwarded != "" {
+		hops := strings.Split(forwarded, ",")
+
+		var last string
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if hop == "" {
+				break
+			}
+
+			if !https.IsTrustedProxy(hop) {
+				return hop
+			}
+
+			last = hop
+		}
+
+		// all hops are trusted proxies
+		if last != "" {
+			return last
+		}
+	}
+
+	if realIp := strings.TrimSpace(c.Request().Header.Get(echo.HeaderXRealIP)); realIp != "" {
+		return realIp
+	}
+
+	return ip
+}
+
+// requestClientKey returns a key identifying the request client
+// (the auth model id if authorized, otherwise the client ip).
+func requestClientKey(app core.App, c echo.Context) string {
+	if key := requestAuthKey(c); key != "" {
+		return key
+	}
+
+	return "ip:" + clientIp(app, c)
+}
+
+// requestAuthKey returns the identifier of the request auth model
+// (eg. "user:ID" or "admin:ID") or empty string for guests.
+func requestAuthKey(c echo.Context) string {
+	if user, _ := c.Get(ContextUserKey).(*models.User); user != nil {
+		return "user:" + user.Id
+	}
+
+	if admin, _ := c.Get(ContextAdminKey).(*models.Admin); admin != nil {
+		return "admin:" + admin.Id
+	}
+
+	return ""
+}
+
+// remoteIp returns the ip address of the direct request peer.
+func remoteIp(c echo.Context) string {
+	ip, _, err := net.SplitHostPort(c.Request().RemoteAddr)
+	if err != nil {
+		return c.Request().RemoteAddr
+	}
+
+	return ip
+}
+
+// CollectionSchemaHashHeader is the records api response header with
+// the context collection schema hash (see [models.Collection.SchemaHash]).
+const CollectionSchemaHashHeader = "X-Collection-Schema-Hash"
+
+// ApplyCollectionHeaders middleware sets the custom response headers
+// configured in the context collection options, as well as
+// the [CollectionSchemaHashHeader] header.
+//
+// Headers that are already set (eg. by the Secure middleware) are not overwritten.
+// The middleware does nothing if there is no collection in the request context,
+// so it should be registered after [apis.LoadCollectionContext()].
+func ApplyCollectionHeaders() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+			if collection != nil {
+				collection.Options.ApplyHeaders(c.Response().Header())
+				c.Response().Header().Set(CollectionSchemaHashHeader, collection.SchemaHash())
+			}
+
+			return next(c)
+		}
+	}
+}
+
 // ActivityLogger middleware takes care to save the request information
 // into the logs database.
 //
@@ -219,6 +474,12 @@ func ActivityLogger(app core.App) echo.MiddlewareFunc {
 				return err
 			}
 
+			// the collection has opted-out from logging the current action
+			collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+			if collection != nil && collection.Options.Logs.IsSkipped(recordRequestAction(c)) {
+				return err
+			}
+
 			httpRequest := c.Request()
 			httpResponse := c.Response()
 			status := httpResponse.Status
@@ -273,7 +534,7 @@ func ActivityLogger(app core.App) echo.MiddlewareFunc {
 						time.Sleep(10 * time.Second)
 						goto BeginSave
 					} else if app.IsDebug() {
-						log.Println("Log save failed:", logErr)
+						app.Logger().Println("Log save failed:", logErr)
 					}
 				}
 
@@ -288,7 +549,7 @@ func ActivityLogger(app core.App) echo.MiddlewareFunc {
 					if deleteErr == nil {
 						app.Cache().Set("lastLogsDeletedAt", now)
 					} else if app.IsDebug() {
-						log.Println("Logs delete failed:", deleteErr)
+						app.Logger().Println("Logs delete failed:", deleteErr)
 					}
 				}
 			})
@@ -297,3 +558,25 @@ func ActivityLogger(app core.App) echo.MiddlewareFunc {
 		}
 	}
 }
+
+// recordRequestAction returns the records api action
+// (see models.RecordAction*) of the provided request context.
+func recordRequestAction(c echo.Context) string {
+	if c.PathParam("filename") != "" {
+		return models.RecordActionFile
+	}
+
+	switch c.Request().Method {
+	case http.MethodPost:
+		return models.RecordActionCreate
+	case http.MethodPatch:
+		return models.RecordActionUpdate
+	case http.MethodDelete:
+		return models.RecordActionDelete
+	default:
+		if c.PathParam("id") != "" {
+			return models.RecordActionView
+		}
+		return models.RecordActionList
+	}
+}
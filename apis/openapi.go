@@ -0,0 +1,362 @@
+package apis
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/inflector"
+	"github.com/pocketbase/pocketbase/tools/rest"
+)
+
+// OpenApiVersion is the version of the generated OpenAPI spec.
+const OpenApiVersion = "3.0.3"
+
+// BindOpenApiApi registers the OpenAPI spec endpoint.
+func BindOpenApiApi(app core.App, rg *echo.Group) {
+	api := openApiApi{app: app}
+
+	rg.GET("/openapi.json", api.spec, ActivityLogger(app), RequireAdminAuth())
+}
+
+type openApiApi struct {
+	app core.App
+}
+
+// spec returns an OpenAPI spec of the records api
+// generated from the current collections.
+//
+// Restricted admins get a spec only of their accessible collections.
+func (api *openApiApi) spec(c echo.Context) error {
+	query := api.app.Dao().CollectionQuery().OrderBy("name ASC")
+
+	admin, _ := c.Get(ContextAdminKey).(*models.Admin)
+	if admin != nil && !admin.IsSuper() {
+		ids := []any{}
+		for id := range admin.Permissions.Collections {
+			if admin.Permissions.CanRead(id) {
+				ids = append(ids, id)
+			}
+		}
+		query.AndWhere(dbx.In("id", ids...))
+	}
+
+	collections := []*models.Collection{}
+	if err := query.All(&collections); err != nil {
+		return rest.NewBadRequestError("Failed to load the collections.", err)
+	}
+
+	settings := api.app.Settings()
+
+	return c.JSON(http.StatusOK, NewOpenApiSpec(settings.Meta.AppName, settings.Meta.AppUrl, collections))
+}
+
+// NewOpenApiSpec generates an OpenAPI spec describing the records
+// CRUD endpoints of the provided collections.
+func NewOpenApiSpec(appName string, appUrl string, collections []*models.Collection) map[string]any {
+	paths := map[string]any{}
+
+	schemas := map[string]any{
+		"ApiError": map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"code":    map[string]any{"type": "integer"},
+				"message": map[string]any{"type": "string"},
+				"data":    map[string]any{"type": "object"},
+			},
+		},
+	}
+
+	for _, collection := range collections {
+		name := openApiSchemaName(collection)
+
+		schemas[name] = openApiRecordSchema(collection)
+		schemas[name+"Upsert"] = openApiUpsertSchema(collection)
+		schemas[name+"List"] = map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"page":       map[string]any{"type": "integer"},
+				"perPage":    map[string]any{"type": "integer"},
+				"totalItems": map[string]any{"type": "integer"},
+				"items": map[string]any{
+					"type":  "array",
+					"items": openApiRef("schemas", name),
+				},
+			},
+		}
+
+		basePath := fmt.Sprintf("/api/collections/%s/records", collection.Name)
+
+		paths[basePath] = map[string]any{
+			"get": openApiOperation(collection, "list", collection.ListRule, map[string]any{
+				"parameters": []any{
+					openApiRef("parameters", "page"),
+					openApiRef("parameters", "perPage"),
+					openApiRef("parameters", "sort"),
+					openApiRef("parameters", "filter"),
+					openApiRef("parameters", "expand"),
+					openApiRef("parameters", "fields"),
+				},
+				"responses": openApiResponses("200", name+"List"),
+			}),
+			"post": openApiOperation(collection, "create", collection.CreateRule, map[string]any{
+				"requestBody": openApiRequestBody(name + "Upsert"),
+				"responses":   openApiResponses("200", name),
+			}),
+		}
+
+		paths[basePath+"/{id}"] = map[string]any{
+			"parameters": []any{
+				map[string]any{
+					"name":     "id",
+					"in":       "path",
+					"required": true,
+					"schema":   map[string]any{"type": "string"},
+				},
+			},
+			"get": openApiOperation(collection, "view", collection.ViewRule, map[string]any{
+				"parameters": []any{openApiRef("parameters", "expand"), openApiRef("parameters", "fields")},
+				"responses":  openApiResponses("200", name),
+			}),
+			"patch": openApiOperation(collection, "update", collection.UpdateRule, map[string]any{
+				"requestBody": openApiRequestBody(name + "Upsert"),
+				"responses":   openApiResponses("200", name),
+			}),
+			"delete": openApiOperation(collection, "delete", collection.DeleteRule, map[string]any{
+				"responses": openApiResponses("204", ""),
+			}),
+		}
+	}
+
+	return map[string]any{
+		"openapi": OpenApiVersion,
+		"info": map[string]any{
+			"title":   appName,
+			"version": "1.0.0",
+		},
+		"servers": []any{map[string]any{"url": appUrl}},
+		"paths":   paths,
+		"components": map[string]any{
+			"schemas": schemas,
+			"parameters": map[string]any{
+				"page":    openApiQueryParam("page", "integer", "The page (aka. offset) of the paginated list (default to 1)."),
+				"perPage": openApiQueryParam("perPage", "integer", "The max returned items per page (default to 30)."),
+				"sort":    openApiQueryParam("sort", "string", "The records order, eg. -created,title (DESC created, ASC title)."),
+				"filter":  openApiQueryParam("filter", "string", "The records filter expression, eg. (title~'abc' && created>'2022-01-01')."),
+				"expand":  openApiQueryParam("expand", "string", "The comma separated relation fields to expand, eg. author,tags.category. The returned fields of the expanded records could be limited with a projection, eg. author(name,avatar)."),
+				"fields":  openApiQueryParam("fields", "string", "The comma separated schema fields to return."),
+			},
+			"securitySchemes": map[string]any{
+				"AdminAuth": map[string]any{
+					"type":        "apiKey",
+					"in":          "header",
+					"name":        "Authorization",
+					"description": "Admin auth token in the format \"Admin {token}\".",
+				},
+				"UserAuth": map[string]any{
+					"type":        "apiKey",
+					"in":          "header",
+					"name":        "Authorization",
+					"description": "User auth token in the format \"User {token}\".",
+				},
+			},
+		},
+	}
+}
+
+// openApiSchemaName returns the components schema name of the collection records.
+func openApiSchemaName(collection *models.Collection) string {
+	return inflector.UcFirst(collection.Name) + "Record"
+}
+
+func openApiRef(kind string, name string) map[string]any {
+	return map[string]any{"$ref": "#/components/" + kind + "/" + name}
+}
+
+func openApiQueryParam(name string, paramType string, description string) map[string]any {
+	return map[string]any{
+		"name":        name,
+		"in":          "query",
+		"description": description,
+		"schema":      map[string]any{"type": paramType},
+	}
+}
+
+// openApiOperation returns a single records api operation definition.
+//
+// Operations with nil rule are marked as accessible only by admins.
+func openApiOperation(collection *models.Collection, action string, rule *string, props map[string]any) map[string]any {
+	result := map[string]any{
+		"operationId": action + inflector.UcFirst(collection.Name),
+		"tags":        []string{collection.Name},
+		"summary":     fmt.Sprintf("%s %s records", inflector.UcFirst(action), collection.Name),
+	}
+
+	if rule == nil {
+		result["security"] = []any{map[string]any{"AdminAuth": []string{}}}
+	} else {
+		// the auth is optional (the rule may allow guests)
+		result["security"] = []any{
+			map[string]any{},
+			map[string]any{"AdminAuth": []string{}},
+			map[string]any{"UserAuth": []string{}},
+		}
+	}
+
+	for k, v := range props {
+		result[k] = v
+	}
+
+	return result
+}
+
+func openApiRequestBody(schemaName string) map[string]any {
+	return map[string]any{
+		"required": true,
+		"content": map[string]any{
+			"application/json":                  map[string]any{"schema": openApiRef("schemas", schemaName)},
+			"multipart/form-data":               map[string]any{"schema": openApiRef("schemas", schemaName)},
+			"application/x-www-form-urlencoded": map[string]any{"schema": openApiRef("schemas", schemaName)},
+		},
+	}
+}
+
+func openApiResponses(status string, schemaName string) map[string]any {
+	success := map[string]any{"description": "Successful operation."}
+	if schemaName != "" {
+		success["content"] = map[string]any{
+			"application/json": map[string]any{"schema": openApiRef("schemas", schemaName)},
+		}
+	}
+
+	errorResponse := func(description string) map[string]any {
+		return map[string]any{
+			"description": description,
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": openApiRef("schemas", "ApiError")},
+			},
+		}
+	}
+
+	return map[string]any{
+		status: success,
+		"400":  errorResponse("Invalid request data."),
+		"403":  errorResponse("Missing access."),
+		"404":  errorResponse("Missing resource."),
+	}
+}
+
+// openApiRecordSchema returns the exported record object schema
+// (without the hidden "#" prefixed fields).
+func openApiRecordSchema(collection *models.Collection) map[string]any {
+	properties := map[string]any{
+		schema.ReservedFieldNameId:      map[string]any{"type": "string"},
+		schema.ReservedFieldNameCreated: map[string]any{"type": "string"},
+		schema.ReservedFieldNameUpdated: map[string]any{"type": "string"},
+		"@collectionId":                 map[string]any{"type": "string"},
+		"@collectionName":               map[string]any{"type": "string"},
+		"@expand":                       map[string]any{"type": "object"},
+	}
+
+	for _, field := range collection.Schema.Fields() {
+		if strings.HasPrefix(field.Name, "#") {
+			continue
+		}
+
+		name := field.Name
+		if alias, ok := collection.Options.Aliases[name]; ok {
+			name = alias
+		}
+
+		properties[name] = openApiFieldSchema(field, false)
+	}
+
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+}
+
+// openApiUpsertSchema returns the record create/update request body schema.
+func openApiUpsertSchema(collection *models.Collection) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+
+	for _, field := range collection.Schema.Fields() {
+		properties[field.Name] = openApiFieldSchema(field, true)
+
+		if field.Required {
+			required = append(required, field.Name)
+		}
+	}
+
+	result := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+
+	if len(required) > 0 {
+		result["required"] = required
+	}
+
+	return result
+}
+
+// openApiFieldSchema returns the schema of a single record field value.
+func openApiFieldSchema(field *schema.SchemaField, isInput bool) map[string]any {
+	field.InitOptions()
+
+	multiple := func(item map[string]any, maxSelect int) map[string]any {
+		if maxSelect == 1 {
+			return item
+		}
+		return map[string]any{"type": "array", "items": item, "maxItems": maxSelect}
+	}
+
+	nullable := func(item map[string]any, isNullable bool) map[string]any {
+		if isNullable {
+			item["nullable"] = true
+		}
+		return item
+	}
+
+	switch options := field.Options.(type) {
+	case *schema.NumberOptions:
+		return nullable(map[string]any{"type": "number"}, options.Nullable)
+	case *schema.DecimalOptions:
+		return map[string]any{"type": "string", "format": "decimal"}
+	case *schema.BoolOptions:
+		return nullable(map[string]any{"type": "boolean"}, options.Nullable)
+	case *schema.EmailOptions:
+		return map[string]any{"type": "string", "format": "email"}
+	case *schema.UrlOptions:
+		return map[string]any{"type": "string", "format": "uri"}
+	case *schema.DateOptions:
+		return nullable(map[string]any{"type": "string", "format": "date-time"}, options.Nullable)
+	case *schema.JsonOptions:
+		return map[string]any{}
+	case *schema.SelectOptions:
+		return multiple(map[string]any{"type": "string", "enum": options.Values}, options.MaxSelect)
+	case *schema.FileOptions:
+		if isInput {
+			return multiple(map[string]any{"type": "string", "format": "binary"}, options.MaxSelect)
+		}
+		return multiple(map[string]any{"type": "string"}, options.MaxSelect)
+	case *schema.RelationOptions:
+		return multiple(map[string]any{"type": "string", "description": "Related record id."}, options.MaxSelect)
+	case *schema.UserOptions:
+		return multiple(map[string]any{"type": "string", "description": "Related user id."}, options.MaxSelect)
+	case *schema.LocalizedOptions:
+		if isInput {
+			return map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}}
+		}
+		return map[string]any{"type": "string"}
+	default:
+		return map[string]any{"type": "string"}
+	}
+}
@@ -0,0 +1,170 @@
+package apis
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/tools/rest"
+)
+
+// ProxyConfig defines the reverse proxy handler options.
+type ProxyConfig struct {
+	// Auth is the middleware that guards the proxied requests
+	// (eg. [RequireUserAuth]).
+	//
+	// Defaults to [RequireAdminAuth] so that the upstream is never
+	// exposed by accident. Public proxies must explicitly set a
+	// pass-through middleware.
+	Auth echo.MiddlewareFunc
+
+	// Upstream is the base url of the proxied api (eg. "https://api.example.com/v1").
+	//
+	// The route wildcard path param (if any) is appended to the upstream path.
+	Upstream string
+
+	// Headers are server-side headers injected in every forwarded request
+	// (eg. the secret third-party api key).
+	Headers map[string]string
+
+	// Timeout is the max duration of the upstream request (0 for no timeout).
+	Timeout time.Duration
+
+	// OnRequest is an optional hook to adjust the outgoing upstream request
+	// (its headers, url, etc.). Returning an error aborts the request.
+	OnRequest func(c echo.Context, req *http.Request) error
+
+	// OnResponse is an optional hook to adjust the upstream response
+	// before it is streamed to the client. Returning an error responds
+	// with 502 Bad Gateway.
+	OnResponse func(c echo.Context, res *http.Response) error
+
+	// Transport is an optional custom upstream http transport
+	// (defaults to http.DefaultTransport).
+	Transport http.RoundTripper
+}
+
+// NewProxyHandler creates a new reverse proxy route handler that
+// forwards the requests to the configured upstream.
+//
+// The client Authorization and Cookie headers are never forwarded
+// (they contain the app auth tokens). The request and response bodies
+// are streamed without buffering.
+//
+// The proxied requests are guarded by the config Auth middleware
+// (admins only by default), eg:
+//
+//	app.OnBeforeServe().Add(func(e *core.ServeEvent) error {
+//		handler, err := apis.NewProxyHandler(apis.ProxyConfig{
+//			Auth:     apis.RequireUserAuth(),
+//			Upstream: "https://api.example.com/v1",
+//			Headers:  map[string]string{"X-Api-Key": os.Getenv("EXAMPLE_API_KEY")},
+//			Timeout:  30 * time.Second,
+//		})
+//		if err != nil {
+//			return err
+//		}
+//
+//		e.Router.Any("/api/example/*", handler)
+//
+//		return nil
+//	})
+func NewProxyHandler(config ProxyConfig) (echo.HandlerFunc, error) {
+	target, err := url.Parse(config.Upstream)
+	if err != nil || target.Scheme == "" || target.Host == "" {
+		return nil, errors.New("Invalid or missing proxy upstream url.")
+	}
+
+	auth := config.Auth
+	if auth == nil {
+		auth = RequireAdminAuth()
+	}
+
+	return auth(func(c echo.Context) error {
+		ctx := c.Request().Context()
+		if config.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, config.Timeout)
+			defer cancel()
+		}
+
+		outReq := c.Request().Clone(ctx)
+		outReq.URL.Scheme = target.Scheme
+		outReq.URL.Host = target.Host
+		outReq.URL.Path = joinProxyPath(target.Path, c.PathParam("*"))
+		outReq.URL.RawPath = ""
+		outReq.URL.RawQuery = joinProxyQuery(target.RawQuery, outReq.URL.RawQuery)
+		outReq.Host = target.Host
+
+		outReq.Header.Del("Authorization")
+		outReq.Header.Del("Cookie")
+		for k, v := range config.Headers {
+			outReq.Header.Set(k, v)
+		}
+
+		if config.OnRequest != nil {
+			if err := config.OnRequest(c, outReq); err != nil {
+				return err
+			}
+		}
+
+		var proxyErr error
+
+		proxy := &httputil.ReverseProxy{
+			Director:      func(r *http.Request) {}, // already prepared
+			Transport:     config.Transport,
+			FlushInterval: -1, // flush immediately (eg. for event streams)
+			ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+				proxyErr = err
+			},
+		}
+
+		if config.OnResponse != nil {
+			proxy.ModifyResponse = func(res *http.Response) error {
+				return config.OnResponse(c, res)
+			}
+		}
+
+		proxy.ServeHTTP(c.Response(), outReq)
+
+		if proxyErr != nil {
+			if c.Response().Committed {
+				return nil // the response was partially streamed and there is nothing else we can do
+			}
+
+			if errors.Is(proxyErr, context.DeadlineExceeded) {
+				return rest.NewApiError(http.StatusGatewayTimeout, "The upstream request timed out.", proxyErr)
+			}
+
+			return rest.NewApiError(http.StatusBadGateway, "Failed to proxy the request.", proxyErr)
+		}
+
+		return nil
+	}), nil
+}
+
+func joinProxyPath(base string, subpath string) string {
+	if subpath == "" {
+		if base == "" {
+			return "/"
+		}
+		return base
+	}
+
+	// clean the path to prevent escaping the upstream base path (eg. "../secret")
+	return strings.TrimSuffix(base, "/") + path.Clean("/"+subpath)
+}
+
+func joinProxyQuery(base string, query string) string {
+	if base == "" || query == "" {
+		return base + query
+	}
+
+	return base + "&" + query
+}
@@ -0,0 +1,181 @@
+package apis
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/resolvers"
+	"github.com/pocketbase/pocketbase/tools/rest"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// BindQueryApi registers the declarative records query api endpoint.
+func BindQueryApi(app core.App, rg *echo.Group) {
+	api := queryApi{app: app}
+
+	rg.POST("/query", api.query, ActivityLogger(app))
+}
+
+type queryApi struct {
+	app core.App
+}
+
+func (api *queryApi) query(c echo.Context) error {
+	form := forms.NewRecordsQuery()
+	if err := c.Bind(form); err != nil {
+		return rest.NewBadRequestError("An error occurred while reading the submitted data.", err)
+	}
+
+	if err := form.Validate(); err != nil {
+		return rest.NewBadRequestError("An error occurred while validating the submitted data.", err)
+	}
+
+	collection, err := api.app.Dao().FindCollectionByNameOrId(form.Collection)
+	if err != nil || collection == nil {
+		return rest.NewNotFoundError("", err)
+	}
+
+	admin, _ := c.Get(ContextAdminKey).(*models.Admin)
+	if admin != nil && !admin.Permissions.CanRead(collection.Id) {
+		return rest.NewForbiddenError("You are not allowed to access this collection.", nil)
+	}
+
+	if admin == nil && collection.ListRule == nil {
+		// only admins can access if the rule is nil
+		return rest.NewForbiddenError("Only admins can perform this action.", nil)
+	}
+
+	// forbid user/guest/restricted admin defined non-relational joins (aka. @collection.*)
+	if (admin == nil || !admin.IsSuper()) && (strings.Contains(form.Filter, "@collection") || strings.Contains(form.Sort, "@collection")) {
+		return rest.NewForbiddenError("Only super admins can filter by @collection.", nil)
+	}
+
+	if admin == nil && form.Filter != "" {
+		if err := search.FilterData(form.Filter).CheckLimits(api.app.Settings().Filter.Limits()); err != nil {
+			return rest.NewBadRequestError("Invalid filter parameters.", err)
+		}
+	}
+
+	// emulate list request data
+	requestData := map[string]any{
+		"method": "get",
+		"query":  map[string]any{},
+		"data":   map[string]any{},
+		"user":   nil,
+	}
+	user, _ := c.Get(ContextUserKey).(*models.User)
+	if user != nil {
+		requestData["user"], _ = user.AsMap()
+	}
+
+	fieldsResolver := resolvers.NewRecordFieldResolver(api.app.Dao(), collection, requestData)
+
+	query := api.app.Dao().RecordQuery(collection)
+
+	// non-admins can query only the published records
+	if expr := publishedRecordsExpr(collection); admin == nil && expr != nil {
+		query.AndWhere(expr)
+	}
+
+	searchProvider := search.NewProvider(fieldsResolver).
+		Query(query).
+		Retry(api.app.Dao().WithRetry).
+		Page(form.Page).
+		PerPage(form.PerPage).
+		AddFilter(search.FilterData(form.Filter))
+
+	if form.Sort != "" {
+		searchProvider.Sort(search.ParseSortFromString(form.Sort))
+	}
+
+	if admin == nil && collection.ListRule != nil {
+		searchProvider.AddFilter(search.FilterData(*collection.ListRule))
+	}
+
+	signature := recordsSearchSignature(
+		collection, admin, collection.ListRule, requestData,
+		"query", form.Filter, form.Sort, strconv.Itoa(form.Page), strconv.Itoa(form.PerPage),
+	)
+	result, rawRecords, err := execRecordsSearch(api.app, collection, signature, func(rows *[]dbx.NullStringMap) (*search.Result, error) {
+		return searchProvider.Exec(rows)
+	})
+	if err != nil {
+		return rest.NewBadRequestError("Invalid filter or sort parameters.", err)
+	}
+
+	records := models.NewRecordsFromNullStringMaps(collection, rawRecords)
+	localizeRecords(api.app, c, records)
+	if len(form.Fields) == 0 {
+		setRecordsExportFields(records, defaultExportFields(collection, true))
+	}
+
+	// expand the selected relations
+	// (reusing the records api expand func to enforce the related collections view rule)
+	if expands := form.ExpandPaths(); len(expands) > 0 {
+		if err := (&recordApi{app: api.app}).expandRecords(c, records, expands, requestData); err != nil {
+			return err
+		}
+	}
+
+	// load the configured back-relation counts
+	if countErr := api.app.Dao().CountRecordsRelations(records); countErr != nil && api.app.IsDebug() {
+		api.app.Logger().Println("Failed to count relations: ", countErr)
+	}
+
+	items := make([]map[string]any, len(records))
+	for i, record := range records {
+		items[i] = exportRecordSelection(record, &form.RecordsQuerySelection)
+	}
+
+	result.Items = items
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// exportRecordSelection exports the public record data
+// limited to the provided selection fields and expanded relations.
+func exportRecordSelection(record *models.Record, selection *forms.RecordsQuerySelection) map[string]any {
+	data := record.PublicExport()
+
+	expandData, _ := data["@expand"].(map[string]any)
+	delete(data, "@expand")
+
+	if selection == nil {
+		selection = &forms.RecordsQuerySelection{}
+	}
+
+	result := data
+	if len(selection.Fields) > 0 {
+		result = make(map[string]any, len(selection.Fields))
+		for _, field := range selection.Fields {
+			if v, ok := data[field]; ok {
+				result[field] = v
+			}
+		}
+	}
+
+	expandResult := map[string]any{}
+	for name, sub := range selection.Expand {
+		switch v := expandData[name].(type) {
+		case *models.Record:
+			expandResult[name] = exportRecordSelection(v, sub)
+		case []*models.Record:
+			list := make([]map[string]any, len(v))
+			for i, rel := range v {
+				list[i] = exportRecordSelection(rel, sub)
+			}
+			expandResult[name] = list
+		}
+	}
+	if len(expandResult) > 0 {
+		result["@expand"] = expandResult
+	}
+
+	return result
+}
@@ -0,0 +1,213 @@
+package apis
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/tools/rest"
+)
+
+// BindQueryParams binds and validates the request query parameters
+// into the provided struct pointer.
+//
+// Only the fields with a `query` tag are bound. The supported tags are:
+//
+//	query:"name"           // the query parameter name
+//	query:"name,required"  // the query parameter must be set and not empty
+//	default:"value"        // the value to use when the query parameter is missing
+//	enum:"a,b,c"           // the list of the allowed values
+//
+// The supported field types are string, bool, the int, uint and float
+// kinds, [time.Duration] and slices of them (populated from repeated
+// and/or comma separated query parameter values).
+//
+// If dst implements [validation.Validatable], its Validate() method
+// is called after the binding for the custom validation rules
+// (add also `json` tags to the fields if you want the validation
+// errors to be keyed by the query parameter names).
+//
+// Returns a 400 [rest.ApiError] with the invalid query parameters
+// errors in its data (similar to the forms validation errors).
+//
+// Example:
+//
+//	params := struct {
+//		Page   int      `query:"page" default:"1"`
+//		Status string   `query:"status,required" enum:"active,inactive"`
+//		Tags   []string `query:"tags"`
+//	}{}
+//
+//	if err := apis.BindQueryParams(c, &params); err != nil {
+//		return err
+//	}
+func BindQueryParams(c echo.Context, dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("BindQueryParams dst must be a struct pointer.")
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	queryParams := c.QueryParams()
+	errs := validation.Errors{}
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+
+		tag, ok := field.Tag.Lookup("query")
+		if !ok || !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		var values []string
+		for _, v := range queryParams[name] {
+			if v != "" {
+				values = append(values, v)
+			}
+		}
+
+		if len(values) == 0 {
+			if options == "required" {
+				errs[name] = validation.ErrRequired
+				continue
+			}
+
+			if d, ok := field.Tag.Lookup("default"); ok {
+				values = []string{d}
+			} else {
+				continue
+			}
+		}
+
+		if field.Type.Kind() == reflect.Slice {
+			var items []string
+			for _, v := range values {
+				items = append(items, strings.Split(v, ",")...)
+			}
+			values = items
+		} else {
+			values = values[:1]
+		}
+
+		if enum, ok := field.Tag.Lookup("enum"); ok {
+			if err := checkQueryParamEnum(values, strings.Split(enum, ",")); err != nil {
+				errs[name] = err
+				continue
+			}
+		}
+
+		if err := setQueryParamValue(rv.Field(i), values); err != nil {
+			if errors.Is(err, errUnsupportedQueryParamType) {
+				return fmt.Errorf("Unsupported query parameter %q type %s.", name, field.Type)
+			}
+			errs[name] = err
+		}
+	}
+
+	if len(errs) > 0 {
+		return rest.NewBadRequestError("An error occurred while validating the query parameters.", errs)
+	}
+
+	if v, ok := dst.(validation.Validatable); ok {
+		if err := v.Validate(); err != nil {
+			return rest.NewBadRequestError("An error occurred while validating the query parameters.", err)
+		}
+	}
+
+	return nil
+}
+
+var errUnsupportedQueryParamType = errors.New("unsupported query parameter type")
+
+var errInvalidQueryParamValue = validation.NewError("validation_invalid_value", "Invalid value.")
+
+func checkQueryParamEnum(values []string, allowed []string) error {
+	for _, v := range values {
+		found := false
+		for _, a := range allowed {
+			if v == strings.TrimSpace(a) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return validation.ErrInInvalid
+		}
+	}
+
+	return nil
+}
+
+func setQueryParamValue(v reflect.Value, values []string) error {
+	if v.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, raw := range values {
+			if err := setQueryParamScalar(slice.Index(i), raw); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+
+	return setQueryParamScalar(v, values[0])
+}
+
+func setQueryParamScalar(v reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+
+	// time.Duration is an int64 kind so it needs to be checked first
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return errInvalidQueryParamValue
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return errInvalidQueryParamValue
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return errInvalidQueryParamValue
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return errInvalidQueryParamValue
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return errInvalidQueryParamValue
+		}
+		v.SetFloat(n)
+	default:
+		return errUnsupportedQueryParamType
+	}
+
+	return nil
+}
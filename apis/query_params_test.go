@@ -0,0 +1,142 @@
+package apis_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/tools/rest"
+)
+
+type testQueryParams struct {
+	Page     int           `query:"page" json:"page" default:"1"`
+	Status   string        `query:"status,required" enum:"active,inactive"`
+	Tags     []string      `query:"tags"`
+	Ratio    float64       `query:"ratio"`
+	Verified bool          `query:"verified"`
+	Timeout  time.Duration `query:"timeout" default:"5s"`
+	Ignored  string
+}
+
+func (p testQueryParams) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(&p.Page, validation.Max(100)),
+	)
+}
+
+func TestBindQueryParams(t *testing.T) {
+	scenarios := []struct {
+		query          string
+		expectedErrors []string
+		expected       testQueryParams
+	}{
+		{
+			"",
+			[]string{"status"},
+			testQueryParams{},
+		},
+		{
+			"status=other&page=abc&ratio=abc",
+			[]string{"status", "page", "ratio"},
+			testQueryParams{},
+		},
+		{
+			// custom Validate() rules
+			"status=active&page=101",
+			[]string{"page"},
+			testQueryParams{},
+		},
+		{
+			"status=active&Ignored=test",
+			nil,
+			testQueryParams{Page: 1, Status: "active", Timeout: 5 * time.Second},
+		},
+		{
+			"status=inactive&page=3&tags=a,b&tags=c&ratio=1.5&verified=true&timeout=1m",
+			nil,
+			testQueryParams{
+				Page:     3,
+				Status:   "inactive",
+				Tags:     []string{"a", "b", "c"},
+				Ratio:    1.5,
+				Verified: true,
+				Timeout:  time.Minute,
+			},
+		},
+	}
+
+	for i, s := range scenarios {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/?"+s.query, nil)
+		c := e.NewContext(req, httptest.NewRecorder())
+
+		params := testQueryParams{}
+		err := apis.BindQueryParams(c, &params)
+
+		if len(s.expectedErrors) == 0 {
+			if err != nil {
+				t.Errorf("(%d) Expected nil, got %v", i, err)
+				continue
+			}
+
+			if params.Page != s.expected.Page ||
+				params.Status != s.expected.Status ||
+				len(params.Tags) != len(s.expected.Tags) ||
+				params.Ratio != s.expected.Ratio ||
+				params.Verified != s.expected.Verified ||
+				params.Timeout != s.expected.Timeout ||
+				params.Ignored != "" {
+				t.Errorf("(%d) Expected %v, got %v", i, s.expected, params)
+			}
+
+			for j, tag := range s.expected.Tags {
+				if params.Tags[j] != tag {
+					t.Errorf("(%d) Expected tag %q, got %q", i, tag, params.Tags[j])
+				}
+			}
+
+			continue
+		}
+
+		apiErr := &rest.ApiError{}
+		if !errors.As(err, &apiErr) {
+			t.Errorf("(%d) Expected ApiError, got %v", i, err)
+			continue
+		}
+
+		if apiErr.Code != http.StatusBadRequest {
+			t.Errorf("(%d) Expected status 400, got %d", i, apiErr.Code)
+		}
+
+		if len(apiErr.Data) != len(s.expectedErrors) {
+			t.Errorf("(%d) Expected errors %v, got %v", i, s.expectedErrors, apiErr.Data)
+		}
+		for _, k := range s.expectedErrors {
+			if _, ok := apiErr.Data[k]; !ok {
+				t.Errorf("(%d) Missing expected error key %q in %v", i, k, apiErr.Data)
+			}
+		}
+	}
+}
+
+func TestBindQueryParamsInvalidDst(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/?test=1", nil)
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	if err := apis.BindQueryParams(c, testQueryParams{}); err == nil {
+		t.Fatal("Expected non-pointer dst error, got nil")
+	}
+
+	unsupported := struct {
+		Test map[string]string `query:"test"`
+	}{}
+	if err := apis.BindQueryParams(c, &unsupported); err == nil {
+		t.Fatal("Expected unsupported type error, got nil")
+	}
+}
@@ -1,11 +1,10 @@
 package apis
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
-	"log"
 	"net/http"
 	"time"
 
@@ -14,6 +13,7 @@ import (
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/forms"
 	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
 	"github.com/pocketbase/pocketbase/resolvers"
 	"github.com/pocketbase/pocketbase/tools/rest"
 	"github.com/pocketbase/pocketbase/tools/search"
@@ -22,7 +22,7 @@ import (
 
 // BindRealtimeApi registers the realtime api endpoints.
 func BindRealtimeApi(app core.App, rg *echo.Group) {
-	api := realtimeApi{app: app}
+	api := realtimeApi{app: app, limiter: newRealtimeLimiter(), debouncer: newRealtimeDebouncer()}
 
 	subGroup := rg.Group("/realtime", ActivityLogger(app))
 	subGroup.GET("", api.connect)
@@ -31,11 +31,30 @@ func BindRealtimeApi(app core.App, rg *echo.Group) {
 	api.bindEvents()
 }
 
+const (
+	// realtimeDeltaKey is the client key of the subscribed record update delta mode.
+	realtimeDeltaKey = "@delta"
+
+	// realtimeOriginalDataKey is the request context key of the
+	// updated record data before the update.
+	realtimeOriginalDataKey = "@realtimeOriginalData"
+)
+
 type realtimeApi struct {
-	app core.App
+	app       core.App
+	limiter   *realtimeLimiter
+	debouncer *realtimeDebouncer
 }
 
 func (api *realtimeApi) connect(c echo.Context) error {
+	limitKey := requestClientKey(api.app, c)
+	retryAfter, ok := api.limiter.acquire(api.app.Settings().Realtime, limitKey, time.Now())
+	if !ok {
+		setRetryAfterHeader(c, retryAfter)
+		return rest.NewApiError(http.StatusTooManyRequests, "Too many realtime connections. Please try again later.", nil)
+	}
+	defer api.limiter.release(limitKey)
+
 	cancelCtx, cancelRequest := context.WithCancel(c.Request().Context())
 	defer cancelRequest()
 	c.SetRequest(c.Request().Clone(cancelCtx))
@@ -59,9 +78,7 @@ func (api *realtimeApi) connect(c echo.Context) error {
 	}
 
 	// signalize established connection (aka. fire "connect" message)
-	fmt.Fprint(c.Response(), "id:"+client.Id()+"\n")
-	fmt.Fprint(c.Response(), "event:PB_CONNECT\n")
-	fmt.Fprint(c.Response(), "data:{\"clientId\":\""+client.Id()+"\"}\n\n")
+	writeServerSentEvent(c.Response(), client.Id(), "PB_CONNECT", `{"clientId":"`+client.Id()+`"}`)
 	c.Response().Flush()
 
 	// start an idle timer to keep track of inactive/forgotten connections
@@ -77,23 +94,20 @@ func (api *realtimeApi) connect(c echo.Context) error {
 			if !ok {
 				// channel is closed
 				if api.app.IsDebug() {
-					log.Println("Realtime connection closed (closed channel):", client.Id())
+					api.app.Logger().Println("Realtime connection closed (closed channel):", client.Id())
 				}
 				return nil
 			}
 
-			w := c.Response()
-			fmt.Fprint(w, "id:"+client.Id()+"\n")
-			fmt.Fprint(w, "event:"+msg.Name+"\n")
-			fmt.Fprint(w, "data:"+msg.Data+"\n\n")
-			w.Flush()
+			writeServerSentEvent(c.Response(), client.Id(), msg.Name, msg.Data)
+			c.Response().Flush()
 
 			idleTimer.Stop()
 			idleTimer.Reset(idleDuration)
 		case <-c.Request().Context().Done():
 			// connection is closed
 			if api.app.IsDebug() {
-				log.Println("Realtime connection closed (cancelled request):", client.Id())
+				api.app.Logger().Println("Realtime connection closed (cancelled request):", client.Id())
 			}
 			return nil
 		}
@@ -137,6 +151,7 @@ func (api *realtimeApi) setSubscriptions(c echo.Context) error {
 		// update auth state
 		e.Client.Set(ContextAdminKey, e.HttpContext.Get(ContextAdminKey))
 		e.Client.Set(ContextUserKey, e.HttpContext.Get(ContextUserKey))
+		e.Client.Set(realtimeDeltaKey, form.Delta)
 
 		// unsubscribe from any previous existing subscriptions
 		e.Client.Unsubscribe()
@@ -207,17 +222,53 @@ func (api *realtimeApi) bindEvents() {
 	})
 
 	api.app.OnRecordAfterCreateRequest().Add(func(data *core.RecordCreateEvent) error {
-		api.broadcastRecord("create", data.Record)
+		api.broadcastRecord("create", data.Record, nil)
+		return nil
+	})
+
+	// store a copy of the record data before the update
+	// (the handler is registered before the user defined ones,
+	// so the record is not modified yet)
+	api.app.OnRecordBeforeUpdateRequest().Add(func(data *core.RecordUpdateEvent) error {
+		if data.HttpContext != nil {
+			data.HttpContext.Set(realtimeOriginalDataKey, data.Record.Data())
+		}
 		return nil
 	})
 
 	api.app.OnRecordAfterUpdateRequest().Add(func(data *core.RecordUpdateEvent) error {
-		api.broadcastRecord("update", data.Record)
+		var changedFields []string
+		if data.HttpContext != nil {
+			if original, ok := data.HttpContext.Get(realtimeOriginalDataKey).(map[string]any); ok {
+				changedFields = recordChangedFields(data.Record, original)
+			}
+		}
+
+		if window := api.app.Settings().Realtime.UpdatesDebounce; window > 0 && data.Record.Collection() != nil {
+			api.debouncer.schedule(
+				data.Record,
+				changedFields,
+				time.Duration(window)*time.Millisecond,
+				func(record *models.Record, changedFields []string) {
+					api.broadcastRecord("update", record, changedFields)
+				},
+			)
+			return nil
+		}
+
+		api.broadcastRecord("update", data.Record, changedFields)
 		return nil
 	})
 
 	api.app.OnRecordAfterDeleteRequest().Add(func(data *core.RecordDeleteEvent) error {
-		api.broadcastRecord("delete", data.Record)
+		// broadcast the pending debounced update (if any) before the delete
+		if data.Record.Collection() != nil {
+			if pending := api.debouncer.flush(data.Record); pending != nil {
+				api.broadcastRecord("update", pending.record, pending.changedFields)
+			}
+		}
+
+		api.broadcastRecord("delete", data.Record, nil)
 		return nil
 	})
 }
@@ -225,8 +276,8 @@ func (api *realtimeApi) bindEvents() {
 func (api *realtimeApi) canAccessRecord(client subscriptions.Client, record *models.Record, accessRule *string) bool {
 	admin, _ := client.Get(ContextAdminKey).(*models.Admin)
 	if admin != nil {
-		// admins can access everything
-		return true
+		// admins can access everything (except the restricted ones)
+		return admin.Permissions.CanRead(record.Collection().Id)
 	}
 
 	if accessRule == nil {
@@ -262,6 +313,11 @@ func (api *realtimeApi) canAccessRecord(client subscriptions.Client, record *mod
 		return nil
 	}
 
+	// non-admins can access only the published records
+	if expr := publishedRecordsExpr(record.Collection()); expr != nil {
+		ruleFunc = withPublishedRecordsFilter(ruleFunc, expr)
+	}
+
 	foundRecord, err := api.app.Dao().FindRecordById(record.Collection(), record.Id, ruleFunc)
 	if err == nil && foundRecord != nil {
 		return true
@@ -270,12 +326,13 @@ func (api *realtimeApi) canAccessRecord(client subscriptions.Client, record *mod
 	return false
 }
 
-type recordData struct {
-	Action string         `json:"action"`
-	Record *models.Record `json:"record"`
-}
-
-func (api *realtimeApi) broadcastRecord(action string, record *models.Record) error {
+// broadcastRecord sends the record action message to all subscribed clients
+// that satisfy the related collection access rule.
+//
+// changedFields are the names of the updated record fields (nil if unknown)
+// and they are used to build the update message delta of the clients
+// that have subscribed with a delta mode.
+func (api *realtimeApi) broadcastRecord(action string, record *models.Record, changedFields []string) error {
 	collection := record.Collection()
 	if collection == nil {
 		return errors.New("Record collection not set.")
@@ -293,18 +350,10 @@ func (api *realtimeApi) broadcastRecord(action string, record *models.Record) er
 		collection.Id:                       collection.ListRule,
 	}
 
-	recordData := &recordData{
-		Action: action,
-		Record: record,
-	}
-
-	serializedData, err := json.Marshal(recordData)
-	if err != nil {
-		if api.app.IsDebug() {
-			log.Println(err)
-		}
-		return err
-	}
+	// the record could have relations expanded with the access
+	// of the request auth model, so they must not be broadcasted
+	exportedRecord := record.PublicExport()
+	delete(exportedRecord, "@expand")
 
 	for _, client := range clients {
 		for subscription, rule := range subscriptionRuleMap {
@@ -316,6 +365,55 @@ func (api *realtimeApi) broadcastRecord(action string, record *models.Record) er
 				continue
 			}
 
+			// each client receives its own record data copy
+			// so that the hook handlers could safely modify it
+			clientRecord := make(map[string]any, len(exportedRecord))
+			for k, v := range exportedRecord {
+				clientRecord[k] = v
+			}
+
+			payload := map[string]any{
+				"action": action,
+				"record": clientRecord,
+			}
+
+			if changedFields != nil {
+				switch deltaMode, _ := client.Get(realtimeDeltaKey).(string); deltaMode {
+				case forms.RealtimeDeltaInclude:
+					payload["delta"] = recordDelta(record, clientRecord, changedFields)
+				case forms.RealtimeDeltaOnly:
+					payload["delta"] = recordDelta(record, clientRecord, changedFields)
+					delete(payload, "record")
+				}
+			}
+
+			event := &core.RealtimeRecordBroadcastEvent{
+				Client:       client,
+				Subscription: subscription,
+				Action:       action,
+				Record:       record,
+				Payload:      payload,
+			}
+
+			if err := api.app.OnRealtimeBeforeRecordBroadcast().Trigger(event); err != nil {
+				if api.app.IsDebug() {
+					api.app.Logger().Println(err)
+				}
+				continue
+			}
+
+			if event.Payload == nil {
+				continue // skip the client message
+			}
+
+			serializedData, err := json.Marshal(event.Payload)
+			if err != nil {
+				if api.app.IsDebug() {
+					api.app.Logger().Println(err)
+				}
+				continue
+			}
+
 			msg := subscriptions.Message{
 				Name: subscription,
 				Data: string(serializedData),
@@ -328,6 +426,49 @@ func (api *realtimeApi) broadcastRecord(action string, record *models.Record) er
 	return nil
 }
 
+// recordChangedFields returns the names of the record schema fields
+// whose values are different from the ones in the original data.
+func recordChangedFields(record *models.Record, original map[string]any) []string {
+	result := []string{}
+
+	for _, field := range record.Collection().Schema.Fields() {
+		oldValue, _ := json.Marshal(original[field.Name])
+		newValue, _ := json.Marshal(record.GetDataValue(field.Name))
+
+		if !bytes.Equal(oldValue, newValue) {
+			result = append(result, field.Name)
+		}
+	}
+
+	return result
+}
+
+// recordDelta returns the changed fields subset of the exported client record.
+//
+// The delta always contains the record id and updated date.
+// The hidden changed fields are not included since they
+// are already missing from the exported client record.
+func recordDelta(record *models.Record, clientRecord map[string]any, changedFields []string) map[string]any {
+	result := map[string]any{
+		schema.ReservedFieldNameId:      clientRecord[schema.ReservedFieldNameId],
+		schema.ReservedFieldNameUpdated: clientRecord[schema.ReservedFieldNameUpdated],
+	}
+
+	aliases := record.Collection().Options.Aliases
+
+	for _, name := range changedFields {
+		if alias, ok := aliases[name]; ok {
+			name = alias
+		}
+
+		if v, ok := clientRecord[name]; ok {
+			result[name] = v
+		}
+	}
+
+	return result
+}
+
 type getter interface {
 	Get(string) any
 }
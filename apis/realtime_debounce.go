@@ -0,0 +1,102 @@
+package apis
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/list"
+)
+
+// realtimePendingUpdate defines a single debounced record update broadcast.
+type realtimePendingUpdate struct {
+	record        *models.Record
+	changedFields []string // nil if unknown
+	timer         *time.Timer
+}
+
+// realtimeDebouncer coalesces the update broadcasts of the same record
+// within a short window (see [core.RealtimeConfig.UpdatesDebounce])
+// into a single broadcast carrying the latest record state.
+type realtimeDebouncer struct {
+	mux     sync.Mutex
+	pending map[string]*realtimePendingUpdate
+}
+
+func newRealtimeDebouncer() *realtimeDebouncer {
+	return &realtimeDebouncer{
+		pending: map[string]*realtimePendingUpdate{},
+	}
+}
+
+// realtimeDebounceKey returns the debouncer key of the provided record.
+func realtimeDebounceKey(record *models.Record) string {
+	return record.Collection().Id + "/" + record.Id
+}
+
+// schedule registers a record update that will be passed to broadcastFunc
+// once the window since the first not broadcasted update of the same
+// record elapses.
+//
+// The changed fields of the coalesced updates are merged
+// (if any of them is unknown, the merged result is also unknown).
+func (d *realtimeDebouncer) schedule(
+	record *models.Record,
+	changedFields []string,
+	window time.Duration,
+	broadcastFunc func(record *models.Record, changedFields []string),
+) {
+	key := realtimeDebounceKey(record)
+
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if pending, ok := d.pending[key]; ok {
+		pending.record = record
+		if pending.changedFields == nil || changedFields == nil {
+			pending.changedFields = nil
+		} else {
+			pending.changedFields = list.ToUniqueStringSlice(append(pending.changedFields, changedFields...))
+		}
+		return
+	}
+
+	pending := &realtimePendingUpdate{
+		record:        record,
+		changedFields: changedFields,
+	}
+
+	pending.timer = time.AfterFunc(window, func() {
+		d.mux.Lock()
+		if d.pending[key] != pending {
+			d.mux.Unlock()
+			return // already flushed
+		}
+		delete(d.pending, key)
+		record, changedFields := pending.record, pending.changedFields
+		d.mux.Unlock()
+
+		broadcastFunc(record, changedFields)
+	})
+
+	d.pending[key] = pending
+}
+
+// flush cancels the scheduled update of the provided record and returns
+// its latest pending state (nil if there is no pending update).
+func (d *realtimeDebouncer) flush(record *models.Record) *realtimePendingUpdate {
+	key := realtimeDebounceKey(record)
+
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	pending, ok := d.pending[key]
+	if !ok {
+		return nil
+	}
+
+	pending.timer.Stop()
+	delete(d.pending, key)
+
+	return pending
+}
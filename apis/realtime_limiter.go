@@ -0,0 +1,112 @@
+package apis
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// realtimeBusyRetryAfter is the retry hint returned to the clients
+// that exceed the concurrent realtime connections limits.
+const realtimeBusyRetryAfter = 10 * time.Second
+
+// realtimeLimiter tracks the active realtime connections and the
+// recent connect attempts in order to enforce the [core.RealtimeConfig] limits.
+//
+// Only the new connections are rejected, the already established
+// ones are never affected by the limits.
+type realtimeLimiter struct {
+	mux         sync.Mutex
+	total       int
+	connections map[string]int
+	attempts    map[string][]time.Time
+	lastPrune   time.Time
+}
+
+func newRealtimeLimiter() *realtimeLimiter {
+	return &realtimeLimiter{
+		connections: map[string]int{},
+		attempts:    map[string][]time.Time{},
+	}
+}
+
+// acquire registers a new connection for the provided client key.
+//
+// If any of the configured limits is exceeded, the connection is not
+// registered and the returned duration is the suggested retry backoff.
+func (l *realtimeLimiter) acquire(config core.RealtimeConfig, key string, now time.Time) (time.Duration, bool) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	window := time.Duration(config.ConnectRateDuration) * time.Second
+
+	// periodically drop the expired attempts of all clients
+	if now.Sub(l.lastPrune) > window {
+		for k := range l.attempts {
+			l.pruneAttempts(k, now, window)
+		}
+		l.lastPrune = now
+	}
+
+	if config.MaxConnections > 0 && l.total >= config.MaxConnections {
+		return realtimeBusyRetryAfter, false
+	}
+
+	if config.MaxClientConnections > 0 && l.connections[key] >= config.MaxClientConnections {
+		return realtimeBusyRetryAfter, false
+	}
+
+	if config.ConnectRateLimit > 0 {
+		l.pruneAttempts(key, now, window)
+
+		if attempts := l.attempts[key]; len(attempts) >= config.ConnectRateLimit {
+			return attempts[0].Add(window).Sub(now), false
+		}
+
+		l.attempts[key] = append(l.attempts[key], now)
+	}
+
+	l.total++
+	l.connections[key]++
+
+	return 0, true
+}
+
+// release unregisters a single connection of the provided client key.
+func (l *realtimeLimiter) release(key string) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	if l.connections[key] <= 0 {
+		return
+	}
+
+	l.total--
+	l.connections[key]--
+
+	if l.connections[key] == 0 {
+		delete(l.connections, key)
+	}
+}
+
+// pruneAttempts removes the key attempts that are older than window.
+//
+// Note: must be called with locked mux.
+func (l *realtimeLimiter) pruneAttempts(key string, now time.Time, window time.Duration) {
+	attempts := l.attempts[key]
+
+	var expired int
+	for _, t := range attempts {
+		if now.Sub(t) < window {
+			break
+		}
+		expired++
+	}
+
+	if expired == len(attempts) {
+		delete(l.attempts, key)
+	} else if expired > 0 {
+		l.attempts[key] = append([]time.Time{}, attempts[expired:]...)
+	}
+}
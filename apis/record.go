@@ -1,10 +1,13 @@
 package apis
 
 import (
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
+	"net/url"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/labstack/echo/v5"
 	"github.com/pocketbase/dbx"
@@ -15,10 +18,18 @@ import (
 	"github.com/pocketbase/pocketbase/resolvers"
 	"github.com/pocketbase/pocketbase/tools/rest"
 	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/spf13/cast"
 )
 
 const expandQueryParam = "expand"
 
+// RecordIdHeader is the response header with the id of the created record.
+const RecordIdHeader = "X-Record-Id"
+
+// localeQueryParam is the query parameter with the preferred records
+// localized fields locale(s) (use "*" to return all locale values).
+const localeQueryParam = "locale"
+
 // BindRecordApi registers the record api endpoints and the corresponding handlers.
 func BindRecordApi(app core.App, rg *echo.Group) {
 	api := recordApi{app: app}
@@ -27,13 +38,26 @@ func BindRecordApi(app core.App, rg *echo.Group) {
 		"/collections/:collection/records",
 		ActivityLogger(app),
 		LoadCollectionContext(app),
+		RequireAdminCollectionAccess(),
+		ApplyCollectionHeaders(),
 	)
 
 	subGroup.GET("", api.list)
-	subGroup.POST("", api.create)
+	subGroup.POST("", api.create, Idempotency(app))
+	subGroup.POST("/batch", api.batchCreate, Idempotency(app))
 	subGroup.GET("/:id", api.view)
-	subGroup.PATCH("/:id", api.update)
-	subGroup.DELETE("/:id", api.delete)
+	subGroup.PATCH("/:id", api.update, RequireRecordUnlocked(app))
+	subGroup.DELETE("/:id", api.delete, RequireRecordUnlocked(app))
+	subGroup.POST("/:id/publish", api.publish, RequireRecordUnlocked(app))
+	subGroup.POST("/:id/relations/:field/attach", api.attachRelations, RequireRecordUnlocked(app))
+	subGroup.POST("/:id/relations/:field/detach", api.detachRelations, RequireRecordUnlocked(app))
+	subGroup.POST("/:id/lock", api.lock)
+	subGroup.POST("/:id/lock/refresh", api.refreshLock)
+	subGroup.DELETE("/:id/lock", api.unlock)
+	subGroup.GET("/:id/render/:template", api.renderTemplate)
+	subGroup.POST("/:id/render", api.renderCustomTemplate, RequireAdminAuth())
+
+	bindRecordCacheEvents(app)
 }
 
 type recordApi struct {
@@ -52,42 +76,72 @@ func (api *recordApi) list(c echo.Context) error {
 		return rest.NewForbiddenError("Only admins can perform this action.", nil)
 	}
 
-	// forbid user/guest defined non-relational joins (aka. @collection.*)
+	// forbid user/guest/restricted admin defined non-relational joins (aka. @collection.*)
 	queryStr := c.QueryString()
-	if admin == nil && queryStr != "" && (strings.Contains(queryStr, "@collection") || strings.Contains(queryStr, "%40collection")) {
-		return rest.NewForbiddenError("Only admins can filter by @collection.", nil)
+	if (admin == nil || !admin.IsSuper()) && queryStr != "" && (strings.Contains(queryStr, "@collection") || strings.Contains(queryStr, "%40collection")) {
+		return rest.NewForbiddenError("Only super admins can filter by @collection.", nil)
 	}
 
 	requestData := api.exportRequestData(c)
 
-	fieldsResolver := resolvers.NewRecordFieldResolver(api.app.Dao(), collection, requestData)
+	fieldsResolver := resolvers.NewRecordFieldResolver(requestDao(api.app, c), collection, requestData)
+
+	query := requestDao(api.app, c).RecordQuery(collection)
+
+	// the coalesced queries are shared between multiple requests
+	// so they shouldn't be canceled with the (first) request context
+	if !collection.Options.CoalesceReads {
+		query.WithContext(c.Request().Context())
+	}
+
+	// non-admins can list only the published records
+	if expr := publishedRecordsExpr(collection); admin == nil && expr != nil {
+		query.AndWhere(expr)
+	}
 
 	searchProvider := search.NewProvider(fieldsResolver).
-		Query(api.app.Dao().RecordQuery(collection))
+		Query(query).
+		Retry(requestDao(api.app, c).WithRetry)
 
-	if admin == nil && collection.ListRule != nil {
-		searchProvider.AddFilter(search.FilterData(*collection.ListRule))
+	if admin == nil {
+		searchProvider.FilterLimits(api.app.Settings().Filter.Limits())
+
+		if collection.ListRule != nil {
+			searchProvider.AddFilter(search.FilterData(*collection.ListRule))
+		}
+	}
+
+	// the estimate is for the entire collection so it is
+	// applicable only for the unfiltered list requests
+	if threshold := collection.Options.EstimatedCountThreshold; threshold > 0 &&
+		c.QueryParam(search.FilterQueryParam) == "" &&
+		(admin != nil || (*collection.ListRule == "" && !collection.Options.Publish.Enabled)) {
+		searchProvider.EstimatedCount(int64(threshold), func() (int64, error) {
+			return requestDao(api.app, c).EstimateRecordsCount(collection)
+		})
 	}
 
-	var rawRecords = []dbx.NullStringMap{}
-	result, err := searchProvider.ParseAndExec(queryStr, &rawRecords)
+	signature := recordsSearchSignature(collection, admin, collection.ListRule, requestData, queryStr)
+	result, rawRecords, err := execRecordsSearch(api.app, collection, signature, func(rows *[]dbx.NullStringMap) (*search.Result, error) {
+		return searchProvider.ParseAndExec(queryStr, rows)
+	})
 	if err != nil {
 		return rest.NewBadRequestError("Invalid filter parameters.", err)
 	}
 
 	records := models.NewRecordsFromNullStringMaps(collection, rawRecords)
+	localizeRecords(api.app, c, records)
+	setRecordsExportFields(records, requestExportFields(c, collection, true))
 
 	// expand records relations
-	expands := strings.Split(c.QueryParam(expandQueryParam), ",")
-	if len(expands) > 0 {
-		expandErr := api.app.Dao().ExpandRecords(
-			records,
-			expands,
-			api.expandFunc(c, requestData),
-		)
-		if expandErr != nil && api.app.IsDebug() {
-			log.Println("Failed to expand relations: ", expandErr)
-		}
+	expands := requestExpands(c, collection)
+	if err := api.expandRecords(c, records, expands, requestData); err != nil {
+		return err
+	}
+
+	// load the configured back-relation counts
+	if countErr := requestDao(api.app, c).CountRecordsRelations(records); countErr != nil && api.app.IsDebug() {
+		api.app.Logger().Println("Failed to count relations: ", countErr)
 	}
 
 	result.Items = records
@@ -99,8 +153,10 @@ func (api *recordApi) list(c echo.Context) error {
 		Result:      result,
 	}
 
-	return api.app.OnRecordsListRequest().Trigger(event, func(e *core.RecordsListEvent) error {
-		return e.HttpContext.JSON(http.StatusOK, e.Result)
+	return traceHook(api.app, c, "OnRecordsListRequest", func() error {
+		return api.app.OnRecordsListRequest().Trigger(event, func(e *core.RecordsListEvent) error {
+			return listResponse(e.HttpContext, e.Result)
+		})
 	})
 }
 
@@ -110,57 +166,87 @@ func (api *recordApi) view(c echo.Context) error {
 		return rest.NewNotFoundError("", "Missing collection context.")
 	}
 
+	record, err := api.findViewRecord(c, collection)
+	if err != nil {
+		return err
+	}
+
+	event := &core.RecordViewEvent{
+		HttpContext: c,
+		Record:      record,
+	}
+
+	return traceHook(api.app, c, "OnRecordViewRequest", func() error {
+		return api.app.OnRecordViewRequest().Trigger(event, func(e *core.RecordViewEvent) error {
+			return e.HttpContext.JSON(http.StatusOK, e.Record)
+		})
+	})
+}
+
+// findViewRecord loads the requested record (by id or slug) applying
+// the collection view rule and the request localization, fields and expand.
+func (api *recordApi) findViewRecord(c echo.Context, collection *models.Collection) (*models.Record, error) {
 	admin, _ := c.Get(ContextAdminKey).(*models.Admin)
 	if admin == nil && collection.ViewRule == nil {
 		// only admins can access if the rule is nil
-		return rest.NewForbiddenError("Only admins can perform this action.", nil)
+		return nil, rest.NewForbiddenError("Only admins can perform this action.", nil)
 	}
 
 	recordId := c.PathParam("id")
 	if recordId == "" {
-		return rest.NewNotFoundError("", nil)
+		return nil, rest.NewNotFoundError("", nil)
 	}
 
 	requestData := api.exportRequestData(c)
 
-	ruleFunc := func(q *dbx.SelectQuery) error {
-		if admin == nil && collection.ViewRule != nil && *collection.ViewRule != "" {
-			resolver := resolvers.NewRecordFieldResolver(api.app.Dao(), collection, requestData)
+	var ruleFunc func(q *dbx.SelectQuery) error
+	if admin == nil && collection.ViewRule != nil && *collection.ViewRule != "" {
+		ruleFunc = func(q *dbx.SelectQuery) error {
+			resolver := resolvers.NewRecordFieldResolver(requestDao(api.app, c), collection, requestData)
 			expr, err := search.FilterData(*collection.ViewRule).BuildExpr(resolver)
 			if err != nil {
 				return err
 			}
 			resolver.UpdateQuery(q)
 			q.AndWhere(expr)
+			return nil
 		}
-		return nil
 	}
 
-	record, fetchErr := api.app.Dao().FindRecordById(collection, recordId, ruleFunc)
+	// non-admins can view only the published records
+	if publishedExpr := publishedRecordsExpr(collection); admin == nil && publishedExpr != nil {
+		ruleFunc = withPublishedRecordsFilter(ruleFunc, publishedExpr)
+	}
+
+	var record *models.Record
+	var fetchErr error
+	if collection.Options.Cache.Enabled {
+		record, fetchErr = findCachedRecord(api.app, collection, recordId, ruleFunc)
+	} else {
+		record, fetchErr = requestDao(api.app, c).FindRecordById(collection, recordId, ruleFunc)
+	}
 	if fetchErr != nil || record == nil {
-		return rest.NewNotFoundError("", fetchErr)
+		// fallback to the collection slug fields (if any)
+		record, fetchErr = requestDao(api.app, c).FindRecordBySlug(collection, recordId, ruleFunc)
+	}
+	if fetchErr != nil || record == nil {
+		return nil, rest.NewNotFoundError("", fetchErr)
 	}
 
-	expands := strings.Split(c.QueryParam(expandQueryParam), ",")
-	if len(expands) > 0 {
-		expandErr := api.app.Dao().ExpandRecord(
-			record,
-			expands,
-			api.expandFunc(c, requestData),
-		)
-		if expandErr != nil && api.app.IsDebug() {
-			log.Println("Failed to expand relations: ", expandErr)
-		}
+	localizeRecords(api.app, c, []*models.Record{record})
+	setRecordsExportFields([]*models.Record{record}, requestExportFields(c, collection, false))
+
+	expands := requestExpands(c, collection)
+	if err := api.expandRecords(c, []*models.Record{record}, expands, requestData); err != nil {
+		return nil, err
 	}
 
-	event := &core.RecordViewEvent{
-		HttpContext: c,
-		Record:      record,
+	// load the configured back-relation counts
+	if countErr := requestDao(api.app, c).CountRecordRelations(record); countErr != nil && api.app.IsDebug() {
+		api.app.Logger().Println("Failed to count relations: ", countErr)
 	}
 
-	return api.app.OnRecordViewRequest().Trigger(event, func(e *core.RecordViewEvent) error {
-		return e.HttpContext.JSON(http.StatusOK, e.Record)
-	})
+	return record, nil
 }
 
 func (api *recordApi) create(c echo.Context) error {
@@ -180,7 +266,7 @@ func (api *recordApi) create(c echo.Context) error {
 	// temporary save the record and check it against the create rule
 	if admin == nil && collection.CreateRule != nil && *collection.CreateRule != "" {
 		ruleFunc := func(q *dbx.SelectQuery) error {
-			resolver := resolvers.NewRecordFieldResolver(api.app.Dao(), collection, requestData)
+			resolver := resolvers.NewRecordFieldResolver(requestDao(api.app, c), collection, requestData)
 			expr, err := search.FilterData(*collection.CreateRule).BuildExpr(resolver)
 			if err != nil {
 				return err
@@ -200,13 +286,22 @@ func (api *recordApi) create(c echo.Context) error {
 			_, fetchErr := txDao.FindRecordById(collection, testRecord.Id, ruleFunc)
 			return fetchErr
 		})
+		if errors.Is(testErr, forms.ErrRecordIdConflict) {
+			return rest.NewApiError(http.StatusConflict, testErr.Error(), nil)
+		}
 		if testErr != nil {
 			return rest.NewBadRequestError("Failed to create record.", testErr)
 		}
 	}
 
 	record := models.NewRecord(collection)
+	if err := checkExpandPathsLimit(api.app, splitExpandParam(c.QueryParam(expandQueryParam))); err != nil {
+		return err
+	}
+
 	form := forms.NewRecordUpsert(api.app, record)
+	form.SetDao(requestDao(api.app, c))
+	form.SetNestedCreateFunc(api.nestedRecordCreateFunc(c, requestData))
 
 	// load request
 	if err := form.LoadData(c.Request()); err != nil {
@@ -218,20 +313,133 @@ func (api *recordApi) create(c echo.Context) error {
 		Record:      record,
 	}
 
-	handlerErr := api.app.OnRecordBeforeCreateRequest().Trigger(event, func(e *core.RecordCreateEvent) error {
-		// create the record
-		if err := form.Submit(); err != nil {
-			return rest.NewBadRequestError("Failed to create record.", err)
-		}
+	var submitted bool
 
-		return e.HttpContext.JSON(http.StatusOK, e.Record)
+	handlerErr := traceHook(api.app, c, "OnRecordBeforeCreateRequest", func() error {
+		return api.app.OnRecordBeforeCreateRequest().Trigger(event, func(e *core.RecordCreateEvent) error {
+			// create the record
+			var apiErr *rest.ApiError
+			if err := form.Submit(); errors.Is(err, forms.ErrRecordIdConflict) {
+				return rest.NewApiError(http.StatusConflict, err.Error(), nil)
+			} else if errors.As(err, &apiErr) {
+				return apiErr // eg. forbidden nested record create
+			} else if err != nil {
+				return rest.NewBadRequestError("Failed to create record.", err)
+			}
+
+			submitted = true
+
+			return nil
+		})
 	})
+	if handlerErr != nil {
+		return handlerErr
+	}
 
-	if handlerErr == nil {
-		api.app.OnRecordAfterCreateRequest().Trigger(event)
+	api.triggerNestedRecordsAfterCreate(c, form)
+
+	traceHook(api.app, c, "OnRecordAfterCreateRequest", func() error {
+		return api.app.OnRecordAfterCreateRequest().Trigger(event)
+	})
+
+	if !submitted {
+		return nil // the submit was skipped by a before hook
 	}
 
-	return handlerErr
+	// expand the created record relations
+	api.expandSubmittedRecord(c, event.Record, requestData)
+
+	status := http.StatusOK
+	if api.app.Settings().RecordsApi.CreatedStatus {
+		status = http.StatusCreated
+	}
+
+	setRecordLocationHeaders(c, event.Record)
+
+	return c.JSON(status, event.Record)
+}
+
+// batchCreate creates multiple collection records at once.
+//
+// By default the batch is atomic (all records are created or none of them).
+// With the "partial" mode each record is created in its own transaction
+// and the successfully created records are preserved.
+//
+// In both modes the response reports the result of each batch item.
+func (api *recordApi) batchCreate(c echo.Context) error {
+	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+	if collection == nil {
+		return rest.NewNotFoundError("", "Missing collection context.")
+	}
+
+	admin, _ := c.Get(ContextAdminKey).(*models.Admin)
+	if admin == nil && collection.CreateRule == nil {
+		// only admins can access if the rule is nil
+		return rest.NewForbiddenError("Only admins can perform this action.", nil)
+	}
+
+	form := forms.NewRecordsBatchCreate(api.app, collection)
+
+	// load request
+	if err := c.Bind(form); err != nil {
+		return rest.NewBadRequestError("Failed to read the submitted data due to invalid formatting.", err)
+	}
+
+	if err := form.Validate(); err != nil {
+		return rest.NewBadRequestError("An error occurred while validating the submitted data.", err)
+	}
+
+	var checkFunc func(txDao *daos.Dao, record *models.Record) error
+
+	// check each created record against the create rule
+	if admin == nil && *collection.CreateRule != "" {
+		queryParams := map[string]any{}
+		echo.BindQueryParams(c, &queryParams)
+
+		var user map[string]any
+		if loggedUser, _ := c.Get(ContextUserKey).(*models.User); loggedUser != nil {
+			user, _ = loggedUser.AsMap()
+		}
+
+		checkFunc = func(txDao *daos.Dao, record *models.Record) error {
+			requestData := map[string]any{
+				"method":  c.Request().Method,
+				"query":   queryParams,
+				"data":    record.Data(),
+				"user":    user,
+				"locales": requestLocales(c),
+			}
+
+			_, err := txDao.FindRecordById(collection, record.Id, func(q *dbx.SelectQuery) error {
+				resolver := resolvers.NewRecordFieldResolver(txDao, collection, requestData)
+				expr, err := search.FilterData(*collection.CreateRule).BuildExpr(resolver)
+				if err != nil {
+					return err
+				}
+				resolver.UpdateQuery(q)
+				q.AndWhere(expr)
+				return nil
+			})
+			if err != nil {
+				return rest.NewForbiddenError("The record doesn't satisfy the collection create rule.", nil)
+			}
+
+			return nil
+		}
+	}
+
+	result, err := form.Submit(checkFunc)
+	if err != nil {
+		return rest.NewBadRequestError("Failed to create the batch records.", err)
+	}
+
+	for _, item := range result.Items {
+		if item.Record != nil {
+			localizeRecords(api.app, c, []*models.Record{item.Record})
+		}
+	}
+
+	return c.JSON(http.StatusOK, result)
 }
 
 func (api *recordApi) update(c echo.Context) error {
@@ -255,7 +463,7 @@ func (api *recordApi) update(c echo.Context) error {
 
 	ruleFunc := func(q *dbx.SelectQuery) error {
 		if admin == nil && collection.UpdateRule != nil && *collection.UpdateRule != "" {
-			resolver := resolvers.NewRecordFieldResolver(api.app.Dao(), collection, requestData)
+			resolver := resolvers.NewRecordFieldResolver(requestDao(api.app, c), collection, requestData)
 			expr, err := search.FilterData(*collection.UpdateRule).BuildExpr(resolver)
 			if err != nil {
 				return err
@@ -267,12 +475,19 @@ func (api *recordApi) update(c echo.Context) error {
 	}
 
 	// fetch record
-	record, fetchErr := api.app.Dao().FindRecordById(collection, recordId, ruleFunc)
+	record, fetchErr := requestDao(api.app, c).FindRecordById(collection, recordId, ruleFunc)
 	if fetchErr != nil || record == nil {
 		return rest.NewNotFoundError("", fetchErr)
 	}
 
+	if err := checkExpandPathsLimit(api.app, splitExpandParam(c.QueryParam(expandQueryParam))); err != nil {
+		return err
+	}
+
 	form := forms.NewRecordUpsert(api.app, record)
+	form.SetDao(requestDao(api.app, c))
+	form.SetUpdatedBy(requestAuthKey(c))
+	form.SetNestedCreateFunc(api.nestedRecordCreateFunc(c, requestData))
 
 	// load request
 	if err := form.LoadData(c.Request()); err != nil {
@@ -284,20 +499,41 @@ func (api *recordApi) update(c echo.Context) error {
 		Record:      record,
 	}
 
-	handlerErr := api.app.OnRecordBeforeUpdateRequest().Trigger(event, func(e *core.RecordUpdateEvent) error {
-		// update the record
-		if err := form.Submit(); err != nil {
-			return rest.NewBadRequestError("Failed to update record.", err)
-		}
+	var submitted bool
+
+	handlerErr := traceHook(api.app, c, "OnRecordBeforeUpdateRequest", func() error {
+		return api.app.OnRecordBeforeUpdateRequest().Trigger(event, func(e *core.RecordUpdateEvent) error {
+			// update the record
+			var apiErr *rest.ApiError
+			if err := form.Submit(); errors.As(err, &apiErr) {
+				return apiErr // eg. forbidden nested record create
+			} else if err != nil {
+				return rest.NewBadRequestError("Failed to update record.", err)
+			}
+
+			submitted = true
 
-		return e.HttpContext.JSON(http.StatusOK, e.Record)
+			return nil
+		})
 	})
+	if handlerErr != nil {
+		return handlerErr
+	}
 
-	if handlerErr == nil {
-		api.app.OnRecordAfterUpdateRequest().Trigger(event)
+	api.triggerNestedRecordsAfterCreate(c, form)
+
+	traceHook(api.app, c, "OnRecordAfterUpdateRequest", func() error {
+		return api.app.OnRecordAfterUpdateRequest().Trigger(event)
+	})
+
+	if !submitted {
+		return nil // the submit was skipped by a before hook
 	}
 
-	return handlerErr
+	// expand the updated record relations
+	api.expandSubmittedRecord(c, event.Record, requestData)
+
+	return c.JSON(http.StatusOK, event.Record)
 }
 
 func (api *recordApi) delete(c echo.Context) error {
@@ -321,7 +557,7 @@ func (api *recordApi) delete(c echo.Context) error {
 
 	ruleFunc := func(q *dbx.SelectQuery) error {
 		if admin == nil && collection.DeleteRule != nil && *collection.DeleteRule != "" {
-			resolver := resolvers.NewRecordFieldResolver(api.app.Dao(), collection, requestData)
+			resolver := resolvers.NewRecordFieldResolver(requestDao(api.app, c), collection, requestData)
 			expr, err := search.FilterData(*collection.DeleteRule).BuildExpr(resolver)
 			if err != nil {
 				return err
@@ -332,7 +568,7 @@ func (api *recordApi) delete(c echo.Context) error {
 		return nil
 	}
 
-	record, fetchErr := api.app.Dao().FindRecordById(collection, recordId, ruleFunc)
+	record, fetchErr := requestDao(api.app, c).FindRecordById(collection, recordId, ruleFunc)
 	if fetchErr != nil || record == nil {
 		return rest.NewNotFoundError("", fetchErr)
 	}
@@ -342,29 +578,47 @@ func (api *recordApi) delete(c echo.Context) error {
 		Record:      record,
 	}
 
-	handlerErr := api.app.OnRecordBeforeDeleteRequest().Trigger(event, func(e *core.RecordDeleteEvent) error {
-		// delete the record
-		if err := api.app.Dao().DeleteRecord(e.Record); err != nil {
-			return rest.NewBadRequestError("Failed to delete record. Make sure that the record is not part of a required relation reference.", err)
-		}
+	handlerErr := traceHook(api.app, c, "OnRecordBeforeDeleteRequest", func() error {
+		return api.app.OnRecordBeforeDeleteRequest().Trigger(event, func(e *core.RecordDeleteEvent) error {
+			// delete the record
+			if err := requestDao(api.app, c).DeleteRecordBy(e.Record, requestAuthKey(e.HttpContext)); err != nil {
+				return rest.NewBadRequestError("Failed to delete record. Make sure that the record is not part of a required relation reference.", err)
+			}
 
-		// try to delete the record files
-		if err := api.deleteRecordFiles(e.Record); err != nil && api.app.IsDebug() {
-			// non critical error - only log for debug
-			// (usually could happen due to S3 api limits)
-			log.Println(err)
-		}
+			// try to delete the record files
+			// (the archived records files are kept so that they could be restored)
+			if !collection.Options.Archive {
+				if err := api.deleteRecordFiles(e.Record); err != nil && api.app.IsDebug() {
+					// non critical error - only log for debug
+					// (usually could happen due to S3 api limits)
+					api.app.Logger().Println(err)
+				}
+			}
 
-		return e.HttpContext.NoContent(http.StatusNoContent)
+			return e.HttpContext.NoContent(http.StatusNoContent)
+		})
 	})
 
 	if handlerErr == nil {
-		api.app.OnRecordAfterDeleteRequest().Trigger(event)
+		traceHook(api.app, c, "OnRecordAfterDeleteRequest", func() error {
+			return api.app.OnRecordAfterDeleteRequest().Trigger(event)
+		})
 	}
 
 	return handlerErr
 }
 
+// setRecordLocationHeaders sets the Location and X-Record-Id
+// response headers of the provided newly created record.
+//
+// The Location header is relative to the current records list request path.
+func setRecordLocationHeaders(c echo.Context, record *models.Record) {
+	location := withBasePath(c, strings.TrimSuffix(c.Request().URL.Path, "/")+"/"+url.PathEscape(record.Id))
+
+	c.Response().Header().Set(echo.HeaderLocation, location)
+	c.Response().Header().Set(RecordIdHeader, record.Id)
+}
+
 func (api *recordApi) deleteRecordFiles(record *models.Record) error {
 	fs, err := api.app.NewFilesystem()
 	if err != nil {
@@ -394,6 +648,7 @@ func (api *recordApi) exportRequestData(c echo.Context) map[string]any {
 	result["query"] = queryParams
 	result["data"] = bodyData
 	result["user"] = nil
+	result["locales"] = requestLocales(c)
 
 	loggedUser, _ := c.Get(ContextUserKey).(*models.User)
 	if loggedUser != nil {
@@ -403,21 +658,105 @@ func (api *recordApi) exportRequestData(c echo.Context) map[string]any {
 	return result
 }
 
+// errExpandRecordsLimit is returned by the expand fetch func when the
+// configured max number of expanded records per request is exceeded.
+var errExpandRecordsLimit = errors.New("The max number of expanded records was exceeded.")
+
+// checkExpandPathsLimit returns a bad request error if the expands
+// have more distinct paths than the configured max expand paths limit.
+func checkExpandPathsLimit(app core.App, expands []string) error {
+	paths, _ := parseExpands(expands)
+
+	max := app.Settings().Expand.MaxPaths
+	if max > 0 && len(daos.NormalizeExpands(paths)) > max {
+		return rest.NewBadRequestError(fmt.Sprintf("Too many expand paths (max %d).", max), nil)
+	}
+
+	return nil
+}
+
+// requestExpands returns the request expand paths or the collection
+// auto expand paths if the expand query parameter is not set at all.
+func requestExpands(c echo.Context, collection *models.Collection) []string {
+	if _, ok := c.QueryParams()[expandQueryParam]; !ok {
+		return collection.Options.AutoExpand
+	}
+
+	return splitExpandParam(c.QueryParam(expandQueryParam))
+}
+
+// expandSubmittedRecord localizes and expands the relations of the
+// created or updated record.
+//
+// The record is already persisted at this point,
+// so the expand errors are only logged.
+func (api *recordApi) expandSubmittedRecord(c echo.Context, record *models.Record, requestData map[string]any) {
+	localizeRecords(api.app, c, []*models.Record{record})
+
+	expands := splitExpandParam(c.QueryParam(expandQueryParam))
+	expandErr := api.expandRecords(c, []*models.Record{record}, expands, requestData)
+	if expandErr != nil && api.app.IsDebug() {
+		api.app.Logger().Println("Failed to expand relations: ", expandErr)
+	}
+}
+
+// expandRecords expands the records relations while enforcing
+// the configured expand limits.
+//
+// Only the limits errors are returned, the other expand errors
+// are just logged (in debug mode).
+func (api *recordApi) expandRecords(c echo.Context, records []*models.Record, expands []string, requestData map[string]any) error {
+	if err := checkExpandPathsLimit(api.app, expands); err != nil {
+		return err
+	}
+
+	paths, projections := parseExpands(expands)
+
+	expandErr := requestDao(api.app, c).ExpandRecords(records, paths, api.expandFunc(c, requestData))
+	if errors.Is(expandErr, errExpandRecordsLimit) {
+		return rest.NewBadRequestError(
+			fmt.Sprintf("Too many expanded records (max %d).", api.app.Settings().Expand.MaxRecords),
+			nil,
+		)
+	}
+	if expandErr != nil && api.app.IsDebug() {
+		api.app.Logger().Println("Failed to expand relations: ", expandErr)
+	}
+
+	setExpandExportFields(records, projections)
+
+	return nil
+}
+
 func (api *recordApi) expandFunc(c echo.Context, requestData map[string]any) daos.ExpandFetchFunc {
 	admin, _ := c.Get(ContextAdminKey).(*models.Admin)
+	maxRecords := api.app.Settings().Expand.MaxRecords
+
+	// the total number of the fetched records for the current request
+	var totalRecords int
 
 	return func(relCollection *models.Collection, relIds []string) ([]*models.Record, error) {
-		return api.app.Dao().FindRecordsByIds(relCollection, relIds, func(q *dbx.SelectQuery) error {
+		// the relations from collections that the requester is not allowed
+		// to view at all are filtered out (instead of failing the whole expand)
+		if admin != nil && !admin.Permissions.CanRead(relCollection.Id) {
+			return []*models.Record{}, nil
+		}
+		if admin == nil && relCollection.ViewRule == nil {
+			return []*models.Record{}, nil
+		}
+
+		rels, err := requestDao(api.app, c).FindRecordsByIds(relCollection, relIds, func(q *dbx.SelectQuery) error {
 			if admin != nil {
 				return nil // admin can access everything
 			}
 
-			if relCollection.ViewRule == nil {
-				return fmt.Errorf("Only admins can view collection %q records", relCollection.Name)
+			// apply the related collection view rule to each related record
+			if expr := publishedRecordsExpr(relCollection); expr != nil {
+				q.AndWhere(expr)
 			}
 
 			if *relCollection.ViewRule != "" {
-				resolver := resolvers.NewRecordFieldResolver(api.app.Dao(), relCollection, requestData)
+				resolver := resolvers.NewRecordFieldResolver(requestDao(api.app, c), relCollection, requestData)
 				expr, err := search.FilterData(*(relCollection.ViewRule)).BuildExpr(resolver)
 				if err != nil {
 					return err
@@ -428,5 +767,93 @@ func (api *recordApi) expandFunc(c echo.Context, requestData map[string]any) dao
 
 			return nil
 		})
+		if err != nil {
+			return nil, err
+		}
+
+		totalRecords += len(rels)
+		if maxRecords > 0 && totalRecords > maxRecords {
+			return nil, errExpandRecordsLimit
+		}
+
+		localizeRecords(api.app, c, rels)
+
+		return rels, nil
+	}
+}
+
+// localizeRecords sets the request preferred locales to the provided
+// records (see [models.Record.SetLocale]) and the app timezone as their
+// dates export location (if enabled in the app timezone settings).
+//
+// It also applies the app empty relations serialization preference.
+func localizeRecords(app core.App, c echo.Context, records []*models.Record) {
+	locales := requestLocales(c)
+
+	var loc *time.Location
+	if timezone := app.Settings().Timezone; timezone.SerializeDates {
+		loc = timezone.Location()
 	}
+
+	emptyRelationAsString := app.Settings().RecordsApi.EmptyRelationAsString
+
+	for _, record := range records {
+		record.SetLocale(locales...)
+		record.SetExportLocation(loc)
+		record.SetEmptyRelationAsString(emptyRelationAsString)
+	}
+}
+
+// requestLocales returns the request preferred locales loaded from
+// the locale query parameter (comma separated) or from the
+// Accept-Language header (ordered by their quality value).
+func requestLocales(c echo.Context) []string {
+	if param := c.QueryParam(localeQueryParam); param != "" {
+		result := []string{}
+		for _, locale := range strings.Split(param, ",") {
+			if locale = strings.TrimSpace(locale); locale != "" {
+				result = append(result, locale)
+			}
+		}
+		return result
+	}
+
+	header := c.Request().Header.Get("Accept-Language")
+	if header == "" {
+		return nil
+	}
+
+	type weightedLocale struct {
+		locale string
+		q      float64
+	}
+
+	weighted := []weightedLocale{}
+	for _, part := range strings.Split(header, ",") {
+		locale, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if locale == "" || locale == "*" {
+			continue // the wildcard means "any", aka. the default locale
+		}
+
+		q := 1.0
+		if params = strings.TrimSpace(params); strings.HasPrefix(params, "q=") {
+			q = cast.ToFloat64(strings.TrimPrefix(params, "q="))
+		}
+		if q <= 0 {
+			continue
+		}
+
+		weighted = append(weighted, weightedLocale{locale, q})
+	}
+
+	sort.SliceStable(weighted, func(i, j int) bool {
+		return weighted[i].q > weighted[j].q
+	})
+
+	result := make([]string, len(weighted))
+	for i, item := range weighted {
+		result[i] = item.locale
+	}
+
+	return result
 }
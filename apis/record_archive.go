@@ -0,0 +1,95 @@
+package apis
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/rest"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// BindRecordArchiveApi registers the archived (aka. deleted) records api endpoints.
+func BindRecordArchiveApi(app core.App, rg *echo.Group) {
+	api := recordArchiveApi{app: app}
+
+	subGroup := rg.Group(
+		"/collections/:collection/archive",
+		ActivityLogger(app),
+		LoadCollectionContext(app),
+		RequireAdminAuth(),
+		RequireAdminCollectionAccess(),
+	)
+	subGroup.GET("", api.list)
+	subGroup.POST("/:id/restore", api.restore)
+}
+
+type recordArchiveApi struct {
+	app core.App
+}
+
+var archivedRecordFilterFields = []string{
+	"id", "recordId", "deletedBy", "created",
+}
+
+func (api *recordArchiveApi) list(c echo.Context) error {
+	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+	if collection == nil {
+		return rest.NewNotFoundError("", "Missing collection context.")
+	}
+
+	fieldResolver := search.NewSimpleFieldResolver(archivedRecordFilterFields...)
+
+	query := api.app.Dao().ArchivedRecordQuery().
+		AndWhere(dbx.HashExp{"collectionId": collection.Id})
+
+	result, err := search.NewProvider(fieldResolver).
+		Query(query).
+		Retry(api.app.Dao().WithRetry).
+		ParseAndExec(c.QueryString(), &[]*models.ArchivedRecord{})
+
+	if err != nil {
+		return rest.NewBadRequestError("", err)
+	}
+
+	return listResponse(c, result)
+}
+
+// restore reinserts the archived record in its collection.
+//
+// The restored record goes through the regular record create request hooks.
+func (api *recordArchiveApi) restore(c echo.Context) error {
+	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+	if collection == nil {
+		return rest.NewNotFoundError("", "Missing collection context.")
+	}
+
+	archived, err := api.app.Dao().FindArchivedRecordById(collection.Id, c.PathParam("id"))
+	if err != nil || archived == nil {
+		return rest.NewNotFoundError("", err)
+	}
+
+	form := forms.NewRecordArchiveRestore(api.app, collection, archived)
+
+	event := &core.RecordCreateEvent{
+		HttpContext: c,
+		Record:      form.Record(),
+	}
+
+	handlerErr := api.app.OnRecordBeforeCreateRequest().Trigger(event, func(e *core.RecordCreateEvent) error {
+		if err := form.Submit(); err != nil {
+			return rest.NewBadRequestError("Failed to restore the archived record.", err)
+		}
+
+		return e.HttpContext.JSON(http.StatusOK, e.Record)
+	})
+
+	if handlerErr == nil {
+		api.app.OnRecordAfterCreateRequest().Trigger(event)
+	}
+
+	return handlerErr
+}
@@ -0,0 +1,139 @@
+package apis
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/store"
+)
+
+// recordCachePrefix is the app cache key prefix of the collection records read cache stores.
+const recordCachePrefix = "@recordCache."
+
+var recordCacheMux sync.Mutex
+
+type cachedRecordRow struct {
+	row     dbx.NullStringMap
+	expires time.Time
+}
+
+// recordCacheStore returns the records read cache store of the provided collection
+// (creating it if missing and create is true).
+func recordCacheStore(app core.App, collectionId string, create bool) *store.Store[*cachedRecordRow] {
+	recordCacheMux.Lock()
+	defer recordCacheMux.Unlock()
+
+	key := recordCachePrefix + collectionId
+
+	s, _ := app.Cache().Get(key).(*store.Store[*cachedRecordRow])
+	if s == nil && create {
+		s = store.New(map[string]*cachedRecordRow{})
+		app.Cache().Set(key, s)
+	}
+
+	return s
+}
+
+// findCachedRecord returns the record with the specified id, loading
+// the raw record row from the collection read cache (if not expired).
+//
+// On cache miss the raw (unfiltered) record row is fetched and stored in the cache.
+// The access filter, if any, is always checked against the db on each call.
+func findCachedRecord(
+	app core.App,
+	collection *models.Collection,
+	recordId string,
+	filter func(q *dbx.SelectQuery) error,
+) (*models.Record, error) {
+	options := collection.Options.Cache
+	cache := recordCacheStore(app, collection.Id, true)
+
+	var row dbx.NullStringMap
+
+	if cached := cache.Get(recordId); cached != nil && time.Now().Before(cached.expires) {
+		row = cached.row
+	} else {
+		row = dbx.NullStringMap{}
+
+		err := app.Dao().RecordQuery(collection).
+			AndWhere(dbx.HashExp{collection.Name + ".id": recordId}).
+			Limit(1).
+			One(row)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := &cachedRecordRow{
+			row:     row,
+			expires: time.Now().Add(time.Duration(options.Ttl) * time.Second),
+		}
+		if !cache.SetIfLessThanLimit(recordId, entry, options.MaxEntries) {
+			// the limit is reached - drop the expired entries and try again
+			pruneRecordCache(cache)
+			cache.SetIfLessThanLimit(recordId, entry, options.MaxEntries)
+		}
+	}
+
+	if filter != nil {
+		if err := checkRecordFilter(app.Dao(), collection, recordId, filter); err != nil {
+			return nil, err
+		}
+	}
+
+	return models.NewRecordFromNullStringMap(collection, row), nil
+}
+
+// checkRecordFilter checks whether the record with the specified id satisfies the provided filter.
+func checkRecordFilter(
+	dao *daos.Dao,
+	collection *models.Collection,
+	recordId string,
+	filter func(q *dbx.SelectQuery) error,
+) error {
+	query := dao.RecordQuery(collection).
+		Select(collection.Name + ".id").
+		AndWhere(dbx.HashExp{collection.Name + ".id": recordId})
+
+	if err := filter(query); err != nil {
+		return err
+	}
+
+	row := dbx.NullStringMap{}
+
+	return query.Limit(1).One(row)
+}
+
+// pruneRecordCache removes all expired entries from the provided cache store.
+func pruneRecordCache(cache *store.Store[*cachedRecordRow]) {
+	now := time.Now()
+
+	for id, entry := range cache.GetAll() {
+		if !now.Before(entry.expires) {
+			cache.Remove(id)
+		}
+	}
+}
+
+// bindRecordCacheEvents registers the app event hooks that take care
+// to invalidate the records read cache on record or collection change.
+func bindRecordCacheEvents(app core.App) {
+	invalidate := func(e *core.ModelEvent) error {
+		switch m := e.Model.(type) {
+		case *models.Record:
+			if cache := recordCacheStore(app, m.Collection().Id, false); cache != nil {
+				cache.Remove(m.Id)
+			}
+		case *models.Collection:
+			app.Cache().Remove(recordCachePrefix + m.Id)
+		}
+
+		return nil
+	}
+
+	app.OnModelAfterUpdate().Add(invalidate)
+	app.OnModelAfterDelete().Add(invalidate)
+}
@@ -0,0 +1,158 @@
+package apis_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestRecordViewCache(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	e, err := apis.InitApi(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collection, err := app.Dao().FindCollectionByNameOrId("demo3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	collection.Options.Cache.Enabled = true
+	collection.Options.Cache.MaxEntries = 10
+	collection.Options.Cache.Ttl = 60
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	recordId := "2c542824-9de1-42fe-8924-e57c86267760"
+	url := "/api/collections/demo3/records/" + recordId
+
+	view := func(expectedStatus int, expectedContent string) {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		e.ServeHTTP(recorder, req)
+
+		if recorder.Code != expectedStatus {
+			t.Fatalf("Expected status %d, got %d (%s)", expectedStatus, recorder.Code, recorder.Body.String())
+		}
+
+		if !strings.Contains(recorder.Body.String(), expectedContent) {
+			t.Fatalf("Expected %s in response body, got %s", expectedContent, recorder.Body.String())
+		}
+	}
+
+	if _, err := app.Dao().DB().Update("demo3", dbx.Params{"title": "lorem"}, dbx.HashExp{"id": recordId}).Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	// populate the cache
+	view(200, `"title":"lorem"`)
+
+	// change the record bypassing the model hooks
+	_, err = app.Dao().DB().Update("demo3", dbx.Params{"title": "changed"}, dbx.HashExp{"id": recordId}).Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// should return the cached record
+	view(200, `"title":"lorem"`)
+
+	// the access rule should be applied even for cached records
+	rule := "title = 'lorem'"
+	collection.ViewRule = &rule
+	if _, err := app.Dao().DB().Update("_collections", dbx.Params{"viewRule": rule}, dbx.HashExp{"id": collection.Id}).Execute(); err != nil {
+		t.Fatal(err)
+	}
+	view(404, `"data":{}`)
+
+	// should invalidate the record cache
+	record, err := app.Dao().FindRecordById(collection, recordId, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record.SetDataValue("title", "lorem")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.Dao().DB().Update("demo3", dbx.Params{"title": "changed2"}, dbx.HashExp{"id": recordId}).Execute(); err != nil {
+		t.Fatal(err)
+	}
+	rule = ""
+	if _, err := app.Dao().DB().Update("_collections", dbx.Params{"viewRule": rule}, dbx.HashExp{"id": collection.Id}).Execute(); err != nil {
+		t.Fatal(err)
+	}
+	view(200, `"title":"changed2"`)
+
+	// delete should invalidate the record cache
+	if err := app.Dao().DeleteRecord(record); err != nil {
+		t.Fatal(err)
+	}
+	view(404, `"data":{}`)
+}
+
+func TestRecordViewCacheMaxEntries(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	e, err := apis.InitApi(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collection, err := app.Dao().FindCollectionByNameOrId("demo4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	collection.ViewRule = new(string)
+	collection.Options.Cache.Enabled = true
+	collection.Options.Cache.MaxEntries = 1
+	collection.Options.Cache.Ttl = 60
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := []string{
+		"054f9f24-0a0a-4e09-87b1-bc7ff2b336a2",
+		"b84cd893-7119-43c9-8505-3c4e22da28a9",
+	}
+
+	for _, id := range ids {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/collections/demo4/records/"+id, nil)
+		e.ServeHTTP(recorder, req)
+
+		if recorder.Code != 200 {
+			t.Fatalf("Expected status 200, got %d (%s)", recorder.Code, recorder.Body.String())
+		}
+	}
+
+	// change the records bypassing the model hooks
+	_, err = app.Dao().DB().Update("demo4", dbx.Params{"title": "changed"}, nil).Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectations := map[string]string{
+		ids[0]: `"title":"demo1"`,   // cached
+		ids[1]: `"title":"changed"`, // not cached because of the limit
+	}
+
+	for id, expected := range expectations {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/collections/demo4/records/"+id, nil)
+		e.ServeHTTP(recorder, req)
+
+		if !strings.Contains(recorder.Body.String(), expected) {
+			t.Errorf("Expected %s in response body, got %s", expected, recorder.Body.String())
+		}
+	}
+}
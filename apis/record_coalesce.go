@@ -0,0 +1,111 @@
+package apis
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/pocketbase/pocketbase/tools/singleflight"
+)
+
+// recordsSearchGroupKey is the app cache key of the coalesced records searches group.
+const recordsSearchGroupKey = "@recordsSearchGroup"
+
+var recordsSearchGroupMux sync.Mutex
+
+// coalescedRecordsSearch defines the shared raw result of a records search.
+type coalescedRecordsSearch struct {
+	result *search.Result
+	rows   []dbx.NullStringMap
+}
+
+// recordsSearchGroup returns the app coalesced records searches group
+// (creating it if missing).
+func recordsSearchGroup(app core.App) *singleflight.Group[*coalescedRecordsSearch] {
+	recordsSearchGroupMux.Lock()
+	defer recordsSearchGroupMux.Unlock()
+
+	g, _ := app.Cache().Get(recordsSearchGroupKey).(*singleflight.Group[*coalescedRecordsSearch])
+	if g == nil {
+		g = &singleflight.Group[*coalescedRecordsSearch]{}
+		app.Cache().Set(recordsSearchGroupKey, g)
+	}
+
+	return g
+}
+
+// recordsSearchSignature returns the signature of a records search request
+// built from the provided search parts (eg. the url query string).
+//
+// Admins and guests/users have different signatures because of the collection rules.
+// The request data (including the authorized user) is also part of the signature
+// if any of the rules or the search parts reference it (aka. @request.*).
+func recordsSearchSignature(
+	collection *models.Collection,
+	admin *models.Admin,
+	rule *string,
+	requestData map[string]any,
+	parts ...string,
+) string {
+	signature := []string{collection.Id}
+
+	if admin != nil {
+		signature = append(signature, "admin")
+	} else {
+		signature = append(signature, "guest")
+
+		checks := append([]string{}, parts...)
+		if rule != nil {
+			checks = append(checks, *rule)
+		}
+
+		for _, check := range checks {
+			if strings.Contains(check, "@request") || strings.Contains(check, "%40request") {
+				raw, _ := json.Marshal(requestData)
+				signature = append(signature, string(raw))
+				break
+			}
+		}
+	}
+
+	return strings.Join(append(signature, parts...), "\n")
+}
+
+// execRecordsSearch executes the provided records search provider.
+//
+// If the collection has enabled reads coalescing, the concurrent
+// searches with the same signature share a single db execution.
+func execRecordsSearch(
+	app core.App,
+	collection *models.Collection,
+	signature string,
+	exec func(rows *[]dbx.NullStringMap) (*search.Result, error),
+) (*search.Result, []dbx.NullStringMap, error) {
+	if !collection.Options.CoalesceReads {
+		rows := []dbx.NullStringMap{}
+		result, err := exec(&rows)
+		return result, rows, err
+	}
+
+	shared, err, _ := recordsSearchGroup(app).Do(signature, func() (*coalescedRecordsSearch, error) {
+		rows := []dbx.NullStringMap{}
+		result, err := exec(&rows)
+		if err != nil {
+			return nil, err
+		}
+		return &coalescedRecordsSearch{result: result, rows: rows}, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// shallow copy the result since the items are replaced per request
+	// (the raw rows are only read, so they are safe to be shared)
+	result := *shared.result
+
+	return &result, shared.rows, nil
+}
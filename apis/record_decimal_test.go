@@ -0,0 +1,246 @@
+package apis_test
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+// createDecimalTestCollection creates a public "products" collection
+// with a decimal "price" field and a few records.
+func createDecimalTestCollection(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+	rule := ""
+
+	collection := &models.Collection{
+		Name:       "products",
+		ListRule:   &rule,
+		ViewRule:   &rule,
+		CreateRule: &rule,
+		Schema: schema.NewSchema(
+			&schema.SchemaField{
+				Name:    "price",
+				Type:    schema.FieldTypeDecimal,
+				Options: &schema.DecimalOptions{Scale: 2},
+			},
+		),
+	}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	prices := map[string]any{
+		"p1": "9.99",
+		"p2": 10,
+		"p3": 0.1 + 0.2, // 0.30000000000000004
+	}
+
+	for id, price := range prices {
+		record := models.NewRecord(collection)
+		record.Id = id
+		record.MarkAsNew()
+		record.SetDataValue("price", price)
+		if err := app.Dao().SaveRecord(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	app.ResetEventCalls()
+}
+
+func TestRecordsDecimalField(t *testing.T) {
+	listUrl := func(filter string) string {
+		return "/api/collections/products/records?sort=price&filter=" + url.QueryEscape(filter)
+	}
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:           "view",
+			Method:         http.MethodGet,
+			Url:            "/api/collections/products/records/p3",
+			BeforeFunc:     createDecimalTestCollection,
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"id":"p3"`,
+				`"price":"0.30"`,
+			},
+			ExpectedEvents: map[string]int{"OnRecordViewRequest": 1},
+		},
+		{
+			Name:           "filter with exact literal comparison",
+			Method:         http.MethodGet,
+			Url:            listUrl("price <= 9.99"),
+			BeforeFunc:     createDecimalTestCollection,
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"totalItems":2,`,
+				`"items":[{"@collectionId":`,
+				`"id":"p3"`,
+				`"id":"p1"`,
+			},
+			ExpectedEvents: map[string]int{"OnRecordsListRequest": 1},
+		},
+		{
+			Name:           "filter with text literal and different scale",
+			Method:         http.MethodGet,
+			Url:            listUrl("price = '0.3' || '10' = price"),
+			BeforeFunc:     createDecimalTestCollection,
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"totalItems":2,`,
+				`"id":"p3"`,
+				`"id":"p2"`,
+			},
+			ExpectedEvents: map[string]int{"OnRecordsListRequest": 1},
+		},
+		{
+			Name:           "filter with in list",
+			Method:         http.MethodGet,
+			Url:            listUrl("price in (9.99, 0.30)"),
+			BeforeFunc:     createDecimalTestCollection,
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"totalItems":2,`,
+				`"id":"p3"`,
+				`"id":"p1"`,
+			},
+			ExpectedEvents: map[string]int{"OnRecordsListRequest": 1},
+		},
+		{
+			Name:            "filter equal with more decimals than the scale",
+			Method:          http.MethodGet,
+			Url:             listUrl("price = 9.999"),
+			BeforeFunc:      createDecimalTestCollection,
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:            "filter not equal with more decimals than the scale",
+			Method:          http.MethodGet,
+			Url:             listUrl("price != 9.999"),
+			BeforeFunc:      createDecimalTestCollection,
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:            "filter in list with more decimals than the scale",
+			Method:          http.MethodGet,
+			Url:             listUrl("price in (9.99, 9.999)"),
+			BeforeFunc:      createDecimalTestCollection,
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:           "filter less than with more decimals than the scale",
+			Method:         http.MethodGet,
+			Url:            listUrl("price < 9.991"),
+			BeforeFunc:     createDecimalTestCollection,
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"totalItems":2,`,
+				`"id":"p3"`,
+				`"id":"p1"`,
+			},
+			ExpectedEvents: map[string]int{"OnRecordsListRequest": 1},
+		},
+		{
+			Name:           "filter less than or equal with more decimals than the scale",
+			Method:         http.MethodGet,
+			Url:            listUrl("price <= 9.995"),
+			BeforeFunc:     createDecimalTestCollection,
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"totalItems":2,`,
+				`"id":"p3"`,
+				`"id":"p1"`,
+			},
+			ExpectedEvents: map[string]int{"OnRecordsListRequest": 1},
+		},
+		{
+			Name:           "filter greater than with more decimals than the scale",
+			Method:         http.MethodGet,
+			Url:            listUrl("price > 9.995"),
+			BeforeFunc:     createDecimalTestCollection,
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"totalItems":1,`,
+				`"id":"p2"`,
+			},
+			ExpectedEvents: map[string]int{"OnRecordsListRequest": 1},
+		},
+		{
+			Name:           "filter greater than or equal with more decimals than the scale",
+			Method:         http.MethodGet,
+			Url:            listUrl("price >= 9.991"),
+			BeforeFunc:     createDecimalTestCollection,
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"totalItems":1,`,
+				`"id":"p2"`,
+			},
+			ExpectedEvents: map[string]int{"OnRecordsListRequest": 1},
+		},
+		{
+			Name:           "filter flipped operands with more decimals than the scale",
+			Method:         http.MethodGet,
+			Url:            listUrl("9.995 >= price && 9.981 < price"),
+			BeforeFunc:     createDecimalTestCollection,
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"totalItems":1,`,
+				`"id":"p1"`,
+			},
+			ExpectedEvents: map[string]int{"OnRecordsListRequest": 1},
+		},
+		{
+			Name:            "filter with invalid literal",
+			Method:          http.MethodGet,
+			Url:             listUrl("price <= 'abc'"),
+			BeforeFunc:      createDecimalTestCollection,
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+		},
+		{
+			Name:           "create with rounded value",
+			Method:         http.MethodPost,
+			Url:            "/api/collections/products/records",
+			Body:           strings.NewReader(`{"price":19.999}`),
+			BeforeFunc:     createDecimalTestCollection,
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"price":"20.00"`,
+			},
+			ExpectedEvents: map[string]int{
+				"OnRecordBeforeCreateRequest": 1,
+				"OnRecordAfterCreateRequest":  1,
+				"OnModelBeforeCreate":         1,
+				"OnModelAfterCreate":          1,
+			},
+			AfterFunc: func(t *testing.T, app *tests.TestApp, e *echo.Echo) {
+				collection, _ := app.Dao().FindCollectionByNameOrId("products")
+
+				var scaled int64
+				err := app.Dao().RecordQuery(collection).
+					Select("price").
+					AndWhere(dbx.NewExp("[[id]] NOT IN ('p1', 'p2', 'p3')")).
+					Row(&scaled)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if scaled != 2000 {
+					t.Fatalf("Expected the price to be stored as scaled integer 2000, got %d", scaled)
+				}
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
@@ -0,0 +1,177 @@
+package apis
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/list"
+)
+
+// fieldsQueryParam is the query parameter used to select the returned record fields.
+const fieldsQueryParam = "fields"
+
+// defaultExportFields returns the record schema fields that are
+// exported by default (all fields except the list excluded ones
+// for the list responses).
+//
+// Returns nil if all fields should be exported.
+func defaultExportFields(collection *models.Collection, isList bool) []string {
+	if !isList || len(collection.Options.ListExcludedFields) == 0 {
+		return nil
+	}
+
+	result := []string{}
+	for _, field := range collection.Schema.Fields() {
+		if !list.ExistInSlice(field.Name, collection.Options.ListExcludedFields) {
+			result = append(result, field.Name)
+		}
+	}
+
+	return result
+}
+
+// requestExportFields returns the record schema fields to export based
+// on the request "fields" query parameter (comma separated field names or
+// aliases, where "*" stands for the default fields).
+//
+// Returns nil if all fields should be exported.
+func requestExportFields(c echo.Context, collection *models.Collection, isList bool) []string {
+	param := c.QueryParam(fieldsQueryParam)
+	if param == "" {
+		return defaultExportFields(collection, isList)
+	}
+
+	return resolveExportFields(collection, strings.Split(param, ","), isList)
+}
+
+// resolveExportFields returns the collection schema fields matching the
+// provided field names or aliases (where "*" stands for the default fields).
+//
+// Returns nil if all fields should be exported.
+func resolveExportFields(collection *models.Collection, names []string, isList bool) []string {
+	aliases := make(map[string]string, len(collection.Options.Aliases))
+	for name, alias := range collection.Options.Aliases {
+		aliases[alias] = name
+	}
+
+	result := []string{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+
+		if name == "*" {
+			defaults := defaultExportFields(collection, isList)
+			if defaults == nil {
+				return nil // all fields
+			}
+			result = append(result, defaults...)
+			continue
+		}
+
+		if original, ok := aliases[name]; ok {
+			name = original
+		}
+
+		if collection.Schema.GetFieldByName(name) != nil {
+			result = append(result, name)
+		}
+	}
+
+	return list.NonzeroUniques(result)
+}
+
+// setRecordsExportFields limits the exported schema fields of the provided records.
+func setRecordsExportFields(records []*models.Record, fields []string) {
+	for _, record := range records {
+		record.SetExportFields(fields)
+	}
+}
+
+// splitExpandParam splits the raw expand query parameter by the commas
+// that are not part of a field projection (eg. "a(b,c),d" -> ["a(b,c)", "d"]).
+func splitExpandParam(param string) []string {
+	result := []string{}
+
+	var depth, start int
+	for i, ch := range param {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				result = append(result, param[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(result, param[start:])
+}
+
+// parseExpands extracts the optional field projections from the provided
+// expand items (eg. "author(name,avatar)" or "author.profile(bio)").
+//
+// Returns the plain expand paths and the projected fields of the
+// expanded records grouped by their normalized expand path.
+func parseExpands(expands []string) ([]string, map[string][]string) {
+	paths := make([]string, 0, len(expands))
+	projections := map[string][]string{}
+
+	for _, expand := range expands {
+		expand = strings.ReplaceAll(expand, " ", "")
+
+		open := strings.Index(expand, "(")
+		if open < 0 || !strings.HasSuffix(expand, ")") {
+			paths = append(paths, expand)
+			continue
+		}
+
+		path := strings.Trim(expand[:open], ".")
+		paths = append(paths, path)
+
+		fields := projections[path]
+		if fields == nil {
+			fields = []string{}
+		}
+		for _, name := range strings.Split(expand[open+1:len(expand)-1], ",") {
+			if name != "" && !list.ExistInSlice(name, fields) {
+				fields = append(fields, name)
+			}
+		}
+		projections[path] = fields
+	}
+
+	return paths, projections
+}
+
+// setExpandExportFields limits the exported schema fields of the
+// expanded relation records based on the provided projections
+// (see [parseExpands]).
+//
+// The expanded records without projection export all their fields.
+func setExpandExportFields(records []*models.Record, projections map[string][]string) {
+	for path, names := range projections {
+		current := records
+
+		for _, part := range strings.Split(path, ".") {
+			next := []*models.Record{}
+			for _, record := range current {
+				switch v := record.GetExpand()[part].(type) {
+				case *models.Record:
+					next = append(next, v)
+				case []*models.Record:
+					next = append(next, v...)
+				}
+			}
+			current = next
+		}
+
+		for _, record := range current {
+			record.SetExportFields(resolveExportFields(record.Collection(), names, false))
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package apis
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/resolvers"
+	"github.com/pocketbase/pocketbase/tools/rest"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/pocketbase/pocketbase/tools/store"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	// recordLocksCacheKey is the app cache key of the record locks store.
+	recordLocksCacheKey = "@recordLocks"
+
+	// recordLocksMaxEntries is the max number of simultaneously held record locks.
+	recordLocksMaxEntries = 10000
+)
+
+// RecordLockDuration is the lifetime of a record lock
+// (the lock holder is expected to refresh it before it expires).
+var RecordLockDuration = 5 * time.Minute
+
+// RecordLock defines an advisory edit lock of a single record.
+type RecordLock struct {
+	CollectionId string         `json:"collectionId"`
+	RecordId     string         `json:"recordId"`
+	Holder       string         `json:"holder"`
+	Expires      types.DateTime `json:"expires"`
+}
+
+// IsExpired checks whether the lock has already expired.
+func (l *RecordLock) IsExpired() bool {
+	return !time.Now().Before(l.Expires.Time())
+}
+
+var (
+	// recordLocksMux guards the record locks acquire/release operations.
+	recordLocksMux sync.Mutex
+
+	// recordLocksStoreMux guards the lazy record locks store initialization.
+	recordLocksStoreMux sync.Mutex
+)
+
+// RequireRecordUnlocked middleware returns 423 error if the
+// requested record (aka. the ":id" path parameter) is currently
+// locked by someone else than the request auth model.
+func RequireRecordUnlocked(app core.App) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+			if collection == nil {
+				return next(c)
+			}
+
+			lock := findRecordLock(app, collection.Id, c.PathParam("id"))
+			if lock != nil && lock.Holder != recordLockHolder(c) {
+				return recordLockedError(c, lock)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// lock acquires (or extends if already held) the record edit lock.
+//
+// Requires admin or the collection update rule to be satisfied.
+func (api *recordApi) lock(c echo.Context) error {
+	collection, record, err := api.findLockableRecord(c)
+	if err != nil {
+		return err
+	}
+
+	holder := recordLockHolder(c)
+
+	recordLocksMux.Lock()
+	defer recordLocksMux.Unlock()
+
+	lock := findRecordLock(api.app, collection.Id, record.Id)
+	if lock != nil && lock.Holder != holder {
+		return recordLockedError(c, lock)
+	}
+
+	lock = &RecordLock{
+		CollectionId: collection.Id,
+		RecordId:     record.Id,
+		Holder:       holder,
+	}
+	lock.Expires, _ = types.ParseDateTime(time.Now().Add(RecordLockDuration))
+
+	if !saveRecordLock(api.app, lock) {
+		return rest.NewApiError(http.StatusServiceUnavailable, "Too many active record locks. Please try again later.", nil)
+	}
+
+	return c.JSON(http.StatusOK, lock)
+}
+
+// refreshLock extends the expiration of an already held record edit lock.
+func (api *recordApi) refreshLock(c echo.Context) error {
+	collection, record, err := api.findLockableRecord(c)
+	if err != nil {
+		return err
+	}
+
+	recordLocksMux.Lock()
+	defer recordLocksMux.Unlock()
+
+	lock := findRecordLock(api.app, collection.Id, record.Id)
+	if lock == nil {
+		return rest.NewNotFoundError("The record is not locked or the lock has expired.", nil)
+	}
+
+	if lock.Holder != recordLockHolder(c) {
+		return recordLockedError(c, lock)
+	}
+
+	clone := *lock
+	clone.Expires, _ = types.ParseDateTime(time.Now().Add(RecordLockDuration))
+	saveRecordLock(api.app, &clone)
+
+	return c.JSON(http.StatusOK, &clone)
+}
+
+// unlock releases the record edit lock.
+//
+// Admins could also release locks held by someone else.
+func (api *recordApi) unlock(c echo.Context) error {
+	collection, record, err := api.findLockableRecord(c)
+	if err != nil {
+		return err
+	}
+
+	recordLocksMux.Lock()
+	defer recordLocksMux.Unlock()
+
+	lock := findRecordLock(api.app, collection.Id, record.Id)
+	if lock != nil {
+		admin, _ := c.Get(ContextAdminKey).(*models.Admin)
+		if admin == nil && lock.Holder != recordLockHolder(c) {
+			return recordLockedError(c, lock)
+		}
+
+		recordLocksStore(api.app).Remove(recordLockKey(collection.Id, record.Id))
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// findLockableRecord returns the context collection and the requested
+// record if it could be updated by the current request auth model.
+func (api *recordApi) findLockableRecord(c echo.Context) (*models.Collection, *models.Record, error) {
+	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+	if collection == nil {
+		return nil, nil, rest.NewNotFoundError("", "Missing collection context.")
+	}
+
+	if recordLockHolder(c) == "" {
+		return nil, nil, rest.NewUnauthorizedError("The request requires admin or user authorization token to be set.", nil)
+	}
+
+	admin, _ := c.Get(ContextAdminKey).(*models.Admin)
+	if admin == nil && collection.UpdateRule == nil {
+		// only admins can access if the rule is nil
+		return nil, nil, rest.NewForbiddenError("Only admins can perform this action.", nil)
+	}
+
+	requestData := api.exportRequestData(c)
+
+	ruleFunc := func(q *dbx.SelectQuery) error {
+		if admin == nil && *collection.UpdateRule != "" {
+			resolver := resolvers.NewRecordFieldResolver(api.app.Dao(), collection, requestData)
+			expr, err := search.FilterData(*collection.UpdateRule).BuildExpr(resolver)
+			if err != nil {
+				return err
+			}
+			resolver.UpdateQuery(q)
+			q.AndWhere(expr)
+		}
+		return nil
+	}
+
+	record, fetchErr := api.app.Dao().FindRecordById(collection, c.PathParam("id"), ruleFunc)
+	if fetchErr != nil || record == nil {
+		return nil, nil, rest.NewNotFoundError("", fetchErr)
+	}
+
+	return collection, record, nil
+}
+
+// -------------------------------------------------------------------
+
+// recordLockKey returns the record locks store key of the provided record.
+func recordLockKey(collectionId string, recordId string) string {
+	return collectionId + "/" + recordId
+}
+
+// recordLockHolder returns the lock holder identifier of the
+// current request auth model (or empty string for guests).
+func recordLockHolder(c echo.Context) string {
+	if admin, _ := c.Get(ContextAdminKey).(*models.Admin); admin != nil {
+		return "admin:" + admin.Id
+	}
+
+	if user, _ := c.Get(ContextUserKey).(*models.User); user != nil {
+		return "user:" + user.Id
+	}
+
+	return ""
+}
+
+// recordLocksStore returns the app record locks store (creating it if missing).
+func recordLocksStore(app core.App) *store.Store[*RecordLock] {
+	recordLocksStoreMux.Lock()
+	defer recordLocksStoreMux.Unlock()
+
+	s, _ := app.Cache().Get(recordLocksCacheKey).(*store.Store[*RecordLock])
+	if s == nil {
+		s = store.New(map[string]*RecordLock{})
+		app.Cache().Set(recordLocksCacheKey, s)
+	}
+
+	return s
+}
+
+// findRecordLock returns the active (aka. not expired) lock
+// of the provided record or nil if the record is not locked.
+func findRecordLock(app core.App, collectionId string, recordId string) *RecordLock {
+	lock := recordLocksStore(app).Get(recordLockKey(collectionId, recordId))
+	if lock == nil || lock.IsExpired() {
+		return nil
+	}
+
+	return lock
+}
+
+// saveRecordLock stores the provided lock, dropping the expired
+// ones if the max allowed locks limit is reached.
+func saveRecordLock(app core.App, lock *RecordLock) bool {
+	s := recordLocksStore(app)
+	key := recordLockKey(lock.CollectionId, lock.RecordId)
+
+	if s.Has(key) {
+		s.Set(key, lock)
+		return true
+	}
+
+	if s.SetIfLessThanLimit(key, lock, recordLocksMaxEntries) {
+		return true
+	}
+
+	for k, v := range s.GetAll() {
+		if v.IsExpired() {
+			s.Remove(k)
+		}
+	}
+
+	return s.SetIfLessThanLimit(key, lock, recordLocksMaxEntries)
+}
+
+// recordLockedError returns 423 error (with Retry-After header)
+// for the provided active record lock.
+func recordLockedError(c echo.Context, lock *RecordLock) error {
+	setRetryAfterHeader(c, time.Until(lock.Expires.Time()))
+
+	return rest.NewApiError(http.StatusLocked, "The record is currently locked for editing by someone else.", nil)
+}
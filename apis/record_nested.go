@@ -0,0 +1,86 @@
+package apis
+
+import (
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/resolvers"
+	"github.com/pocketbase/pocketbase/tools/rest"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// nestedRecordCreateFunc returns a [forms.NestedRecordCreateFunc] that
+// checks the nested relation record collection create rule and
+// triggers the before record create request hooks.
+//
+// The create rule is checked with the nested record own submitted
+// data as "@request.data" (the other request fields are shared).
+func (api *recordApi) nestedRecordCreateFunc(c echo.Context, requestData map[string]any) forms.NestedRecordCreateFunc {
+	admin, _ := c.Get(ContextAdminKey).(*models.Admin)
+
+	return func(txDao *daos.Dao, record *models.Record, data map[string]any, create func() error) error {
+		collection := record.Collection()
+
+		if admin == nil && collection.CreateRule == nil {
+			// only admins can access if the rule is nil
+			return rest.NewForbiddenError("Only admins can create "+collection.Name+" records.", nil)
+		}
+
+		// restricted admins (see [RequireAdminCollectionAccess])
+		if admin != nil && !admin.Permissions.CanWrite(collection.Id) {
+			return rest.NewForbiddenError("You are not allowed to modify the "+collection.Name+" collection.", nil)
+		}
+
+		event := &core.RecordCreateEvent{
+			HttpContext: c,
+			Record:      record,
+		}
+
+		return api.app.OnRecordBeforeCreateRequest().Trigger(event, func(e *core.RecordCreateEvent) error {
+			if err := create(); err != nil {
+				return err
+			}
+
+			if admin != nil || *collection.CreateRule == "" {
+				return nil
+			}
+
+			// check the created record against the create rule
+			// (on failure the entire transaction is reverted)
+			nestedRequestData := make(map[string]any, len(requestData))
+			for k, v := range requestData {
+				nestedRequestData[k] = v
+			}
+			nestedRequestData["data"] = data
+
+			ruleFunc := func(q *dbx.SelectQuery) error {
+				resolver := resolvers.NewRecordFieldResolver(txDao, collection, nestedRequestData)
+				expr, err := search.FilterData(*collection.CreateRule).BuildExpr(resolver)
+				if err != nil {
+					return err
+				}
+				resolver.UpdateQuery(q)
+				q.AndWhere(expr)
+				return nil
+			}
+
+			_, err := txDao.FindRecordById(collection, e.Record.Id, ruleFunc)
+
+			return err
+		})
+	}
+}
+
+// triggerNestedRecordsAfterCreate triggers the after record create request
+// hooks for the nested relation records created with the provided form.
+func (api *recordApi) triggerNestedRecordsAfterCreate(c echo.Context, form *forms.RecordUpsert) {
+	for _, record := range form.NestedRecords() {
+		api.app.OnRecordAfterCreateRequest().Trigger(&core.RecordCreateEvent{
+			HttpContext: c,
+			Record:      record,
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package apis
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/resolvers"
+	"github.com/pocketbase/pocketbase/tools/rest"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// publishedRecordsExpr returns the collection published records filter
+// expression (or nil if the collection publish workflow is not enabled).
+//
+// Records with publish date in the future are considered not published yet.
+func publishedRecordsExpr(collection *models.Collection) dbx.Expression {
+	options := collection.Options.Publish
+	if !options.Enabled {
+		return nil
+	}
+
+	expr := dbx.HashExp{collection.Name + "." + options.StatusField: models.RecordStatusPublished}
+
+	if options.PublishedAtField == "" {
+		return expr
+	}
+
+	col := fmt.Sprintf("[[%s.%s]]", collection.Name, options.PublishedAtField)
+
+	return dbx.And(expr, dbx.NewExp(
+		fmt.Sprintf("(%s IS NULL OR %s = '' OR %s <= {:publishNow})", col, col, col),
+		dbx.Params{"publishNow": types.NowDateTime().String()},
+	))
+}
+
+// withPublishedRecordsFilter wraps the provided (optional)
+// records query filter and extends it with the published expression.
+func withPublishedRecordsFilter(filter func(q *dbx.SelectQuery) error, expr dbx.Expression) func(q *dbx.SelectQuery) error {
+	return func(q *dbx.SelectQuery) error {
+		if filter != nil {
+			if err := filter(q); err != nil {
+				return err
+			}
+		}
+		q.AndWhere(expr)
+		return nil
+	}
+}
+
+// publishRequest defines the optional record publish request data.
+type publishRequest struct {
+	// PublishedAt is the optional record publish date
+	// (a future date schedules the record publishing).
+	PublishedAt string `form:"publishedAt" json:"publishedAt"`
+}
+
+// publish changes the record status to published.
+//
+// Requires admin or the collection update rule to be satisfied.
+func (api *recordApi) publish(c echo.Context) error {
+	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+	if collection == nil {
+		return rest.NewNotFoundError("", "Missing collection context.")
+	}
+
+	options := collection.Options.Publish
+	if !options.Enabled {
+		return rest.NewBadRequestError("The collection doesn't have the publish workflow enabled.", nil)
+	}
+
+	admin, _ := c.Get(ContextAdminKey).(*models.Admin)
+	if admin == nil && collection.UpdateRule == nil {
+		// only admins can access if the rule is nil
+		return rest.NewForbiddenError("Only admins can perform this action.", nil)
+	}
+
+	data := &publishRequest{}
+	if err := c.Bind(data); err != nil {
+		return rest.NewBadRequestError("An error occurred while reading the submitted data.", err)
+	}
+
+	publishedAt := types.NowDateTime()
+	if data.PublishedAt != "" {
+		var err error
+		if publishedAt, err = types.ParseDateTime(data.PublishedAt); err != nil || publishedAt.IsZero() {
+			return rest.NewBadRequestError("Invalid publishedAt date.", err)
+		}
+	}
+
+	requestData := api.exportRequestData(c)
+
+	ruleFunc := func(q *dbx.SelectQuery) error {
+		if admin == nil && *collection.UpdateRule != "" {
+			resolver := resolvers.NewRecordFieldResolver(api.app.Dao(), collection, requestData)
+			expr, err := search.FilterData(*collection.UpdateRule).BuildExpr(resolver)
+			if err != nil {
+				return err
+			}
+			resolver.UpdateQuery(q)
+			q.AndWhere(expr)
+		}
+		return nil
+	}
+
+	record, fetchErr := api.app.Dao().FindRecordById(collection, c.PathParam("id"), ruleFunc)
+	if fetchErr != nil || record == nil {
+		return rest.NewNotFoundError("", fetchErr)
+	}
+
+	record.SetDataValue(options.StatusField, models.RecordStatusPublished)
+	if options.PublishedAtField != "" {
+		record.SetDataValue(options.PublishedAtField, publishedAt)
+	}
+
+	event := &core.RecordPublishEvent{
+		HttpContext: c,
+		Record:      record,
+	}
+
+	handlerErr := api.app.OnRecordBeforePublishRequest().Trigger(event, func(e *core.RecordPublishEvent) error {
+		if err := api.app.Dao().SaveRecord(e.Record); err != nil {
+			return rest.NewBadRequestError("Failed to publish record.", err)
+		}
+
+		return e.HttpContext.JSON(http.StatusOK, e.Record)
+	})
+
+	if handlerErr == nil {
+		api.app.OnRecordAfterPublishRequest().Trigger(event)
+	}
+
+	return handlerErr
+}
@@ -0,0 +1,168 @@
+package apis
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/forms/validators"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/resolvers"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/rest"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// relationsRequest defines the record relation attach/detach request data.
+type relationsRequest struct {
+	// Ids is the list of the related record ids to attach or detach.
+	Ids []string `form:"ids" json:"ids"`
+}
+
+// attachRelations adds the submitted ids to the record multiple
+// relation field (the already attached ids are ignored).
+//
+// Requires admin or the collection update rule to be satisfied.
+func (api *recordApi) attachRelations(c echo.Context) error {
+	return api.changeRelations(c, func(current []string, ids []string) []string {
+		for _, id := range ids {
+			if !list.ExistInSlice(id, current) {
+				current = append(current, id)
+			}
+		}
+		return current
+	})
+}
+
+// detachRelations removes the submitted ids from the record multiple
+// relation field (the not attached ids are ignored).
+//
+// Requires admin or the collection update rule to be satisfied.
+func (api *recordApi) detachRelations(c echo.Context) error {
+	return api.changeRelations(c, func(current []string, ids []string) []string {
+		result := make([]string, 0, len(current))
+		for _, id := range current {
+			if !list.ExistInSlice(id, ids) {
+				result = append(result, id)
+			}
+		}
+		return result
+	})
+}
+
+// changeRelations updates the record multiple relation field with
+// the ids returned by changeFunc.
+//
+// The record is reloaded and saved within a single write transaction,
+// so that concurrent relation changes of the same record don't
+// overwrite each other.
+func (api *recordApi) changeRelations(c echo.Context, changeFunc func(current []string, ids []string) []string) error {
+	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+	if collection == nil {
+		return rest.NewNotFoundError("", "Missing collection context.")
+	}
+
+	admin, _ := c.Get(ContextAdminKey).(*models.Admin)
+	if admin == nil && collection.UpdateRule == nil {
+		// only admins can access if the rule is nil
+		return rest.NewForbiddenError("Only admins can perform this action.", nil)
+	}
+
+	field := collection.Schema.GetFieldByName(c.PathParam("field"))
+	if field == nil || field.Type != schema.FieldTypeRelation {
+		return rest.NewNotFoundError("Missing relation field.", nil)
+	}
+
+	field.InitOptions()
+	if options, _ := field.Options.(*schema.RelationOptions); options == nil || options.MaxSelect == 1 {
+		return rest.NewBadRequestError("The field must be a multiple relation field.", nil)
+	}
+
+	data := &relationsRequest{}
+	if err := c.Bind(data); err != nil {
+		return rest.NewBadRequestError("An error occurred while reading the submitted data.", err)
+	}
+
+	ids := list.ToUniqueStringSlice(data.Ids)
+	if len(ids) == 0 {
+		return rest.NewBadRequestError("Missing relation ids.", nil)
+	}
+
+	if err := checkExpandPathsLimit(api.app, splitExpandParam(c.QueryParam(expandQueryParam))); err != nil {
+		return err
+	}
+
+	requestData := api.exportRequestData(c)
+
+	ruleFunc := func(q *dbx.SelectQuery) error {
+		if admin == nil && *collection.UpdateRule != "" {
+			resolver := resolvers.NewRecordFieldResolver(api.app.Dao(), collection, requestData)
+			expr, err := search.FilterData(*collection.UpdateRule).BuildExpr(resolver)
+			if err != nil {
+				return err
+			}
+			resolver.UpdateQuery(q)
+			q.AndWhere(expr)
+		}
+		return nil
+	}
+
+	var event *core.RecordUpdateEvent
+
+	txErr := api.app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+		// acquire the db write lock before reading the record
+		// to prevent lost updates from concurrent requests
+		_, err := txDao.DB().NewQuery("UPDATE {{" + collection.Name + "}} SET [[id]] = [[id]] WHERE [[id]] = {:id}").
+			Bind(dbx.Params{"id": c.PathParam("id")}).
+			Execute()
+		if err != nil {
+			return err
+		}
+
+		record, fetchErr := txDao.FindRecordById(collection, c.PathParam("id"), ruleFunc)
+		if fetchErr != nil || record == nil {
+			return rest.NewNotFoundError("", fetchErr)
+		}
+
+		current := list.ToUniqueStringSlice(record.GetDataValue(field.Name))
+		record.SetDataValue(field.Name, changeFunc(current, ids))
+
+		recordData := map[string]any{}
+		for _, f := range collection.Schema.Fields() {
+			recordData[f.Name] = record.GetDataValue(f.Name)
+		}
+
+		if err := validators.NewRecordDataValidator(txDao, record, nil).Validate(recordData); err != nil {
+			return rest.NewBadRequestError("Failed to update record.", err)
+		}
+
+		event = &core.RecordUpdateEvent{
+			HttpContext: c,
+			Record:      record,
+		}
+
+		return traceHook(api.app, c, "OnRecordBeforeUpdateRequest", func() error {
+			return api.app.OnRecordBeforeUpdateRequest().Trigger(event, func(e *core.RecordUpdateEvent) error {
+				if err := txDao.SaveRecordBy(e.Record, requestAuthKey(c)); err != nil {
+					return rest.NewBadRequestError("Failed to update record.", err)
+				}
+				return nil
+			})
+		})
+	})
+	if txErr != nil {
+		return txErr
+	}
+
+	traceHook(api.app, c, "OnRecordAfterUpdateRequest", func() error {
+		return api.app.OnRecordAfterUpdateRequest().Trigger(event)
+	})
+
+	// expand the updated record relations
+	api.expandSubmittedRecord(c, event.Record, requestData)
+
+	return c.JSON(http.StatusOK, event.Record)
+}
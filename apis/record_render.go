@@ -0,0 +1,80 @@
+package apis
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/rest"
+	"github.com/pocketbase/pocketbase/tools/template"
+)
+
+// renderRequest defines the admin only custom record template request data.
+type renderRequest struct {
+	Format  string `form:"format" json:"format"`
+	Content string `form:"content" json:"content"`
+}
+
+// renderTemplate renders the requested record with the
+// specified named collection template.
+//
+// The record is loaded as with the view api (aka. the collection
+// view rule, expand and fields query parameters are applied).
+func (api *recordApi) renderTemplate(c echo.Context) error {
+	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+	if collection == nil {
+		return rest.NewNotFoundError("", "Missing collection context.")
+	}
+
+	tmpl, ok := collection.Options.Templates[c.PathParam("template")]
+	if !ok {
+		return rest.NewNotFoundError("Missing collection template.", nil)
+	}
+
+	return api.renderRecord(c, collection, tmpl)
+}
+
+// renderCustomTemplate renders the requested record
+// with the template submitted in the request body.
+//
+// Requires admin authorization.
+func (api *recordApi) renderCustomTemplate(c echo.Context) error {
+	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+	if collection == nil {
+		return rest.NewNotFoundError("", "Missing collection context.")
+	}
+
+	data := &renderRequest{}
+	if err := c.Bind(data); err != nil {
+		return rest.NewBadRequestError("An error occurred while reading the submitted data.", err)
+	}
+
+	tmpl := models.RecordTemplate{Format: data.Format, Content: data.Content}
+	if err := tmpl.Validate(); err != nil {
+		return rest.NewBadRequestError("Invalid template.", err)
+	}
+
+	return api.renderRecord(c, collection, tmpl)
+}
+
+func (api *recordApi) renderRecord(c echo.Context, collection *models.Collection, tmpl models.RecordTemplate) error {
+	record, err := api.findViewRecord(c, collection)
+	if err != nil {
+		return err
+	}
+
+	output, err := tmpl.Render(c.Request().Context(), record)
+	if err != nil {
+		if errors.Is(err, template.ErrTimeout) || errors.Is(err, template.ErrOutputTooLarge) {
+			return rest.NewApiError(http.StatusUnprocessableEntity, err.Error(), nil)
+		}
+		return rest.NewBadRequestError("Failed to render the template.", err)
+	}
+
+	if tmpl.Format == template.FormatHtml {
+		return c.HTML(http.StatusOK, output)
+	}
+
+	return c.String(http.StatusOK, output)
+}
@@ -0,0 +1,234 @@
+package apis
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/resolvers"
+	"github.com/pocketbase/pocketbase/tools/rest"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/spf13/cast"
+)
+
+// BindRecordVersionApi registers the record versions api endpoints.
+//
+// The versions could be listed and viewed by the clients satisfying
+// the collection view rule and restored by the clients satisfying
+// the collection update rule (admins are always allowed).
+// The versions updatedBy identifier is visible only to the admins.
+func BindRecordVersionApi(app core.App, rg *echo.Group) {
+	api := recordVersionApi{app: app}
+
+	subGroup := rg.Group(
+		"/collections/:collection/records/:id/versions",
+		ActivityLogger(app),
+		LoadCollectionContext(app),
+		RequireAdminCollectionAccess(),
+	)
+	subGroup.GET("", api.list)
+	subGroup.GET("/:version", api.view)
+	subGroup.POST("/:version/restore", api.restore, RequireRecordUnlocked(app))
+}
+
+type recordVersionApi struct {
+	app core.App
+}
+
+var recordVersionFilterFields = []string{
+	"id", "version", "updatedBy", "created",
+}
+
+// recordVersionPublicFilterFields are the versions filter fields
+// allowed for the non-admin clients (the updatedBy is visible only to admins).
+var recordVersionPublicFilterFields = []string{
+	"id", "version", "created",
+}
+
+// recordVersionResponse defines the single record version api response
+// (the version model and the full reconstructed record state).
+type recordVersionResponse struct {
+	*models.RecordVersion
+
+	Record *models.Record `json:"record"`
+}
+
+func (api *recordVersionApi) list(c echo.Context) error {
+	record, err := api.findRecord(c, false)
+	if err != nil {
+		return err
+	}
+
+	admin, _ := c.Get(ContextAdminKey).(*models.Admin)
+
+	fieldResolver := search.NewSimpleFieldResolver(recordVersionPublicFilterFields...)
+	if admin != nil {
+		fieldResolver = search.NewSimpleFieldResolver(recordVersionFilterFields...)
+	}
+
+	query := api.app.Dao().RecordVersionQuery().
+		AndWhere(dbx.HashExp{
+			"collectionId": record.Collection().Id,
+			"recordId":     record.Id,
+		})
+
+	provider := search.NewProvider(fieldResolver).
+		Query(query).
+		Retry(api.app.Dao().WithRetry)
+
+	// newest first by default
+	if c.QueryParam(search.SortQueryParam) == "" {
+		provider.AddSort(search.SortField{Name: "version", Direction: search.SortDesc})
+	}
+
+	versions := []*models.RecordVersion{}
+
+	result, err := provider.ParseAndExec(c.QueryString(), &versions)
+	if err != nil {
+		return rest.NewBadRequestError("", err)
+	}
+
+	// skip the "hidden" fields (similar to the record public export)
+	for _, v := range versions {
+		for key := range v.Data {
+			if strings.HasPrefix(key, "#") {
+				delete(v.Data, key)
+			}
+		}
+
+		if admin == nil {
+			v.UpdatedBy = ""
+		}
+	}
+
+	return listResponse(c, result)
+}
+
+func (api *recordVersionApi) view(c echo.Context) error {
+	record, err := api.findRecord(c, false)
+	if err != nil {
+		return err
+	}
+
+	version, state, err := api.findVersion(c, record)
+	if err != nil {
+		return err
+	}
+
+	if admin, _ := c.Get(ContextAdminKey).(*models.Admin); admin == nil {
+		version.UpdatedBy = ""
+	}
+
+	return c.JSON(http.StatusOK, &recordVersionResponse{
+		RecordVersion: version,
+		Record:        state,
+	})
+}
+
+// restore updates the record with the version state.
+//
+// The restore goes through the regular record update request hooks.
+func (api *recordVersionApi) restore(c echo.Context) error {
+	record, err := api.findRecord(c, true)
+	if err != nil {
+		return err
+	}
+
+	_, state, err := api.findVersion(c, record)
+	if err != nil {
+		return err
+	}
+
+	form := forms.NewRecordVersionRestore(api.app, record, state)
+	form.SetUpdatedBy(requestAuthKey(c))
+
+	event := &core.RecordUpdateEvent{
+		HttpContext: c,
+		Record:      form.Record(),
+	}
+
+	handlerErr := api.app.OnRecordBeforeUpdateRequest().Trigger(event, func(e *core.RecordUpdateEvent) error {
+		if err := form.Submit(); err != nil {
+			return rest.NewBadRequestError("Failed to restore the record version.", err)
+		}
+
+		return e.HttpContext.JSON(http.StatusOK, e.Record)
+	})
+
+	if handlerErr == nil {
+		api.app.OnRecordAfterUpdateRequest().Trigger(event)
+	}
+
+	return handlerErr
+}
+
+// findRecord loads the requested record applying the collection
+// view rule (or the update rule if forUpdate is set).
+func (api *recordVersionApi) findRecord(c echo.Context, forUpdate bool) (*models.Record, error) {
+	collection, _ := c.Get(ContextCollectionKey).(*models.Collection)
+	if collection == nil {
+		return nil, rest.NewNotFoundError("", "Missing collection context.")
+	}
+
+	rule := collection.ViewRule
+	if forUpdate {
+		rule = collection.UpdateRule
+	}
+
+	admin, _ := c.Get(ContextAdminKey).(*models.Admin)
+	if admin == nil && rule == nil {
+		// only admins can access if the rule is nil
+		return nil, rest.NewForbiddenError("Only admins can perform this action.", nil)
+	}
+
+	requestData := (&recordApi{app: api.app}).exportRequestData(c)
+
+	var ruleFunc func(q *dbx.SelectQuery) error
+	if admin == nil && *rule != "" {
+		ruleFunc = func(q *dbx.SelectQuery) error {
+			resolver := resolvers.NewRecordFieldResolver(api.app.Dao(), collection, requestData)
+			expr, err := search.FilterData(*rule).BuildExpr(resolver)
+			if err != nil {
+				return err
+			}
+			resolver.UpdateQuery(q)
+			q.AndWhere(expr)
+			return nil
+		}
+	}
+
+	// non-admins can view only the published records
+	if publishedExpr := publishedRecordsExpr(collection); admin == nil && !forUpdate && publishedExpr != nil {
+		ruleFunc = withPublishedRecordsFilter(ruleFunc, publishedExpr)
+	}
+
+	record, err := api.app.Dao().FindRecordById(collection, c.PathParam("id"), ruleFunc)
+	if err != nil || record == nil {
+		return nil, rest.NewNotFoundError("", err)
+	}
+
+	return record, nil
+}
+
+func (api *recordVersionApi) findVersion(c echo.Context, record *models.Record) (*models.RecordVersion, *models.Record, error) {
+	number, err := cast.ToIntE(c.PathParam("version"))
+	if err != nil || number <= 0 {
+		return nil, nil, rest.NewNotFoundError("", err)
+	}
+
+	version, err := api.app.Dao().FindRecordVersion(record, number)
+	if err != nil || version == nil {
+		return nil, nil, rest.NewNotFoundError("", err)
+	}
+
+	state, err := api.app.Dao().FindRecordVersionState(record, number)
+	if err != nil {
+		return nil, nil, rest.NewNotFoundError("", err)
+	}
+
+	return version, state, nil
+}
@@ -13,9 +13,10 @@ import (
 func BindSettingsApi(app core.App, rg *echo.Group) {
 	api := settingsApi{app: app}
 
-	subGroup := rg.Group("/settings", ActivityLogger(app), RequireAdminAuth())
+	subGroup := rg.Group("/settings", ActivityLogger(app), RequireSuperAdminAuth())
 	subGroup.GET("", api.list)
 	subGroup.PATCH("", api.set)
+	subGroup.POST("/tokens/rotate", api.rotateTokenKey)
 }
 
 type settingsApi struct {
@@ -69,3 +70,17 @@ func (api *settingsApi) set(c echo.Context) error {
 
 	return handlerErr
 }
+
+func (api *settingsApi) rotateTokenKey(c echo.Context) error {
+	form := forms.NewSettingsTokenKeyRotate(api.app)
+	if err := c.Bind(form); err != nil {
+		return rest.NewBadRequestError("An error occurred while reading the submitted data.", err)
+	}
+
+	keyId, err := form.Submit()
+	if err != nil {
+		return rest.NewBadRequestError("Failed to rotate the token signing key.", err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"keyId": keyId})
+}
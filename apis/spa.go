@@ -0,0 +1,76 @@
+package apis
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+	"github.com/labstack/echo/v5/middleware"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// bindSPA registers the app single page application catch-all route (if configured).
+//
+// The api and admin UI routes are more specific, so they always take precedence.
+func bindSPA(app core.App, e *echo.Echo) {
+	config := app.SPAConfig()
+	if config == nil || config.FS == nil {
+		return
+	}
+
+	handler := spaHandler(*config)
+
+	e.GET("/*", handler, middleware.Gzip())
+	e.HEAD("/*", handler, middleware.Gzip())
+}
+
+// spaHandler serves the static files from the SPA file system
+// and falls back to the SPA index file for the unknown paths
+// without extension (aka. the client-side routes).
+//
+// The index file is served with "no-cache", so that the clients
+// always revalidate it, while the other static files are cached
+// for the configured AssetsMaxAge.
+func spaHandler(config core.SPAConfig) echo.HandlerFunc {
+	assetsCacheControl := fmt.Sprintf("public, max-age=%d", int64(config.AssetsMaxAge.Seconds()))
+
+	return func(c echo.Context) error {
+		p, err := url.PathUnescape(c.PathParam("*"))
+		if err != nil {
+			return echo.ErrNotFound
+		}
+
+		// fs.FS.Open() considers names with leading `/` as invalid
+		name := strings.TrimPrefix(path.Clean("/"+p), "/")
+
+		// unknown api and admin UI paths are not part of the SPA
+		if name == "api" || strings.HasPrefix(name, "api/") || name == "_" || strings.HasPrefix(name, "_/") {
+			return echo.ErrNotFound
+		}
+
+		if name != "" && name != config.Index {
+			info, err := fs.Stat(config.FS, name)
+			if err == nil && !info.IsDir() {
+				c.Response().Header().Set("Cache-Control", assetsCacheControl)
+				return c.FileFS(name, config.FS)
+			}
+
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+
+			// missing static file
+			if err != nil && path.Ext(name) != "" {
+				return echo.ErrNotFound
+			}
+		}
+
+		c.Response().Header().Set("Cache-Control", "no-cache")
+
+		return c.FileFS(config.Index, config.FS)
+	}
+}
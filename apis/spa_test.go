@@ -0,0 +1,117 @@
+package apis_test
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestSPA(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.SetSPAConfig(core.SPAConfig{
+		FS: fstest.MapFS{
+			"index.html":     {Data: []byte("<html>spa</html>")},
+			"assets/app.js":  {Data: []byte("console.log('spa')")},
+			"assets/app.css": {Data: []byte("body{}")},
+		},
+		AssetsMaxAge: 10 * time.Minute,
+	})
+
+	e, err := apis.InitApi(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		url                  string
+		expectedStatus       int
+		expectedContent      string
+		expectedContentType  string
+		expectedCacheControl string
+	}{
+		{"/", 200, "<html>spa</html>", "text/html", "no-cache"},
+		{"/index.html", 200, "<html>spa</html>", "text/html", "no-cache"},
+		{"/some/client/route", 200, "<html>spa</html>", "text/html", "no-cache"},
+		{"/assets", 200, "<html>spa</html>", "text/html", "no-cache"},
+		{"/assets/app.js", 200, "console.log('spa')", "javascript", "public, max-age=600"},
+		{"/assets/app.css", 200, "body{}", "text/css", "public, max-age=600"},
+		{"/assets/missing.js", 404, "", "", ""},
+		{"/../index.html", 200, "<html>spa</html>", "text/html", "no-cache"},
+		// the api and admin UI routes take precedence
+		{"/api/settings", 401, `"data":{}`, "application/json", ""},
+		{"/api/missing", 404, `"data":{}`, "application/json", ""},
+		{"/_/", 200, "<!DOCTYPE html>", "text/html", ""},
+		{"/_/missing", 404, "", "", ""},
+	}
+
+	for _, s := range scenarios {
+		req := httptest.NewRequest(http.MethodGet, s.url, nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		if rec.Code != s.expectedStatus {
+			t.Errorf("[%s] Expected status %d, got %d", s.url, s.expectedStatus, rec.Code)
+			continue
+		}
+
+		if !strings.Contains(rec.Body.String(), s.expectedContent) {
+			t.Errorf("[%s] Expected content %q, got %q", s.url, s.expectedContent, rec.Body.String())
+		}
+
+		if ct := rec.Header().Get("Content-Type"); !strings.Contains(ct, s.expectedContentType) {
+			t.Errorf("[%s] Expected content type %q, got %q", s.url, s.expectedContentType, ct)
+		}
+
+		if cc := rec.Header().Get("Cache-Control"); cc != s.expectedCacheControl {
+			t.Errorf("[%s] Expected cache control %q, got %q", s.url, s.expectedCacheControl, cc)
+		}
+	}
+
+	// gzip
+	req := httptest.NewRequest(http.MethodGet, "/assets/app.js", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Expected gzip content encoding, got %q", rec.Header().Get("Content-Encoding"))
+	}
+
+	reader, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(reader)
+	if string(body) != "console.log('spa')" {
+		t.Fatalf("Unexpected gzip body %q", body)
+	}
+}
+
+func TestSPANotConfigured(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	e, err := apis.InitApi(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/some/client/route", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("Expected status 404, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,154 @@
+package apis
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/tools/rest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// MiddlewareTracing is the id of the requests tracing middleware
+// (registered only if the app tracing is enabled).
+const MiddlewareTracing = "tracing"
+
+// tracePropagator extracts the W3C trace context (aka. the traceparent
+// and tracestate headers) from the inbound requests.
+var tracePropagator = propagation.TraceContext{}
+
+// Tracing middleware starts a new server span for each request
+// (or a producer span for the long-lived realtime connections)
+// as a child of the inbound traceparent header span (if any).
+//
+// The span is stored in the request context, so that the db queries
+// and hooks executed with it are recorded as its child spans
+// (the records api executes all of its queries with the request context).
+func Tracing(app core.App) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			tracer := app.Tracer()
+			if tracer == nil {
+				return next(c)
+			}
+
+			req := c.Request()
+			route := c.Path()
+
+			kind := trace.SpanKindServer
+			name := req.Method + " " + route
+			if route == "/api/realtime" && req.Method == http.MethodGet {
+				kind = trace.SpanKindProducer
+				name = "realtime connect"
+			}
+
+			ctx := tracePropagator.Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+
+			ctx, span := tracer.Start(
+				ctx,
+				name,
+				trace.WithSpanKind(kind),
+				trace.WithAttributes(
+					attribute.String("http.method", req.Method),
+					attribute.String("http.route", route),
+					attribute.String("http.target", req.URL.RequestURI()),
+				),
+			)
+			defer span.End()
+
+			c.SetRequest(req.WithContext(ctx))
+
+			start := time.Now()
+
+			err := next(c)
+
+			status := c.Response().Status
+			if err != nil {
+				status = errorStatus(err)
+				span.RecordError(err)
+			}
+
+			span.SetAttributes(
+				attribute.Int("http.status_code", status),
+				attribute.Float64("http.latency_ms", float64(time.Since(start).Microseconds())/1000),
+			)
+
+			if status >= 500 {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+
+			return err
+		}
+	}
+}
+
+// traceHook executes the provided hook trigger func within
+// a child span of the request span (if the tracing is enabled).
+func traceHook(app core.App, c echo.Context, name string, trigger func() error) error {
+	tracer := app.Tracer()
+	if tracer == nil || !trace.SpanContextFromContext(c.Request().Context()).IsValid() {
+		return trigger()
+	}
+
+	ctx, span := tracer.Start(c.Request().Context(), "hook "+name)
+	defer span.End()
+
+	// make the hook span the parent of the queries executed within the hook
+	req := c.Request()
+	c.SetRequest(req.WithContext(ctx))
+	defer c.SetRequest(req)
+
+	err := trigger()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
+
+// requestDao returns the app Dao bound to the request context values
+// (eg. the tracing span), so that its queries are recorded as children
+// of the request span.
+//
+// The request cancellation is not propagated to the queries to avoid
+// aborting the already started writes when the client disconnects.
+func requestDao(app core.App, c echo.Context) *daos.Dao {
+	return app.Dao().WithContext(detachedContext{c.Request().Context()})
+}
+
+// detachedContext is a context that keeps the values of its parent
+// but is never canceled and has no deadline.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+func (ctx detachedContext) Value(key any) any {
+	return ctx.parent.Value(key)
+}
+
+// errorStatus returns the http status code of the provided handler error.
+func errorStatus(err error) int {
+	var apiErr *rest.ApiError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Code
+	}
+
+	return http.StatusBadRequest
+}
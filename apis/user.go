@@ -1,19 +1,22 @@
 package apis
 
 import (
-	"log"
+	"fmt"
 	"net/http"
 
 	"github.com/labstack/echo/v5"
 	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
 	"github.com/pocketbase/pocketbase/forms"
 	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
 	"github.com/pocketbase/pocketbase/tokens"
 	"github.com/pocketbase/pocketbase/tools/auth"
 	"github.com/pocketbase/pocketbase/tools/rest"
 	"github.com/pocketbase/pocketbase/tools/routine"
 	"github.com/pocketbase/pocketbase/tools/search"
 	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/spf13/cast"
 	"golang.org/x/oauth2"
 )
 
@@ -23,7 +26,7 @@ func BindUserApi(app core.App, rg *echo.Group) {
 
 	subGroup := rg.Group("/users", ActivityLogger(app))
 	subGroup.GET("/auth-methods", api.authMethods)
-	subGroup.POST("/auth-via-oauth2", api.oauth2Auth, RequireGuestOnly())
+	subGroup.POST("/auth-via-oauth2", api.oauth2Auth)
 	subGroup.POST("/auth-via-email", api.emailAuth, RequireGuestOnly())
 	subGroup.POST("/request-password-reset", api.requestPasswordReset)
 	subGroup.POST("/confirm-password-reset", api.confirmPasswordReset)
@@ -32,12 +35,19 @@ func BindUserApi(app core.App, rg *echo.Group) {
 	subGroup.POST("/request-email-change", api.requestEmailChange, RequireUserAuth())
 	subGroup.POST("/confirm-email-change", api.confirmEmailChange)
 	subGroup.POST("/refresh", api.refresh, RequireUserAuth())
+	subGroup.POST("/logout", api.logout)
+	subGroup.POST("/auth-verify", api.verifyToken)
 	// crud
-	subGroup.GET("", api.list, RequireAdminAuth())
+	subGroup.GET("", api.list, RequireSuperAdminAuth())
 	subGroup.POST("", api.create)
 	subGroup.GET("/:id", api.view, RequireAdminOrOwnerAuth("id"))
-	subGroup.PATCH("/:id", api.update, RequireAdminAuth())
+	subGroup.PATCH("/:id", api.update, RequireSuperAdminAuth())
 	subGroup.DELETE("/:id", api.delete, RequireAdminOrOwnerAuth("id"))
+	subGroup.GET("/:id/external-auths", api.listExternalAuths, RequireAdminOrOwnerAuth("id"))
+	subGroup.DELETE("/:id/external-auths/:provider", api.unlinkExternalAuth, RequireAdminOrOwnerAuth("id"))
+	subGroup.POST("/:id/refresh-token-key", api.refreshTokenKey, RequireAdminOrOwnerAuth("id"))
+
+	bindUserWebhooks(app)
 }
 
 type userApi struct {
@@ -58,6 +68,8 @@ func (api *userApi) authResponse(c echo.Context, user *models.User, meta any) er
 	}
 
 	return api.app.OnUserAuthRequest().Trigger(event, func(e *core.UserAuthEvent) error {
+		setAuthCookies(api.app, e.HttpContext, AuthCookieUser, e.Token, api.app.Settings().UserAuthToken.Duration)
+
 		result := map[string]any{
 			"token": e.Token,
 			"user":  e.User,
@@ -80,6 +92,40 @@ func (api *userApi) refresh(c echo.Context) error {
 	return api.authResponse(c, user, nil)
 }
 
+// verifyToken verifies the submitted user auth token and
+// returns its user model (aka. the same as the owner user view).
+func (api *userApi) verifyToken(c echo.Context) error {
+	form := forms.NewUserTokenVerify(api.app)
+	if readErr := c.Bind(form); readErr != nil {
+		return rest.NewBadRequestError("An error occurred while reading the submitted data.", readErr)
+	}
+
+	if err := form.Validate(); err != nil {
+		return rest.NewBadRequestError("An error occurred while validating the submitted data.", err)
+	}
+
+	user, submitErr := form.Submit()
+	if submitErr != nil {
+		return rest.NewUnauthorizedError("Invalid or expired auth token.", nil)
+	}
+
+	event := &core.UserViewEvent{
+		HttpContext: c,
+		User:        user,
+	}
+
+	return api.app.OnUserViewRequest().Trigger(event, func(e *core.UserViewEvent) error {
+		return e.HttpContext.JSON(http.StatusOK, e.User)
+	})
+}
+
+// logout clears the user auth cookies (if any).
+func (api *userApi) logout(c echo.Context) error {
+	clearAuthCookies(api.app, c, AuthCookieUser)
+
+	return c.NoContent(http.StatusNoContent)
+}
+
 type providerInfo struct {
 	Name                string `json:"name"`
 	State               string `json:"state"`
@@ -112,7 +158,7 @@ func (api *userApi) authMethods(c echo.Context) error {
 		provider, err := auth.NewProviderByName(name)
 		if err != nil {
 			if api.app.IsDebug() {
-				log.Println(err)
+				api.app.Logger().Println(err)
 			}
 
 			// skip provider
@@ -121,7 +167,7 @@ func (api *userApi) authMethods(c echo.Context) error {
 
 		if err := config.SetupProvider(provider); err != nil {
 			if api.app.IsDebug() {
-				log.Println(err)
+				api.app.Logger().Println(err)
 			}
 
 			// skip provider
@@ -149,35 +195,76 @@ func (api *userApi) authMethods(c echo.Context) error {
 	return c.JSON(http.StatusOK, result)
 }
 
+// oauth2Auth authenticates the user via an OAuth2 provider.
+//
+// If the request is authorized as user, the OAuth2 identity
+// is linked to the current logged user.
 func (api *userApi) oauth2Auth(c echo.Context) error {
-	form := forms.NewUserOauth2Login(api.app)
-	if readErr := c.Bind(form); readErr != nil {
+	if admin, _ := c.Get(ContextAdminKey).(*models.Admin); admin != nil {
+		return rest.NewBadRequestError("The request can be accessed only by guests or authorized users.", nil)
+	}
+
+	loggedUser, _ := c.Get(ContextUserKey).(*models.User)
+
+	form := forms.NewUserOauth2Login(api.app, loggedUser)
+	readErr := c.Bind(form)
+
+	attempt := newAuthAttemptEvent(api.app, c, core.AuthSubjectUser, core.AuthMethodOAuth2, "")
+	attempt.Provider = form.Provider
+
+	if readErr != nil {
+		triggerAuthFailure(api.app, attempt, core.AuthFailureInvalidRequest)
 		return rest.NewBadRequestError("An error occurred while reading the submitted data.", readErr)
 	}
 
 	user, authData, submitErr := form.Submit()
+	if authData != nil {
+		attempt.Identity = authData.Id
+	}
 	if submitErr != nil {
+		triggerAuthFailure(api.app, attempt, oauth2FailureReason(submitErr))
 		return rest.NewBadRequestError("Failed to authenticate.", submitErr)
 	}
 
+	attempt.User = user
+	triggerAuthSuccess(api.app, attempt)
+
 	return api.authResponse(c, user, authData)
 }
 
 func (api *userApi) emailAuth(c echo.Context) error {
+	form := forms.NewUserEmailLogin(api.app)
+	readErr := c.Bind(form)
+
+	attempt := newAuthAttemptEvent(api.app, c, core.AuthSubjectUser, core.AuthMethodEmail, form.Email)
+
 	if !api.app.Settings().EmailAuth.Enabled {
+		triggerAuthFailure(api.app, attempt, core.AuthFailureMethodDisabled)
 		return rest.NewBadRequestError("Email/Password authentication is not enabled.", nil)
 	}
 
-	form := forms.NewUserEmailLogin(api.app)
-	if readErr := c.Bind(form); readErr != nil {
+	if readErr != nil {
+		triggerAuthFailure(api.app, attempt, core.AuthFailureInvalidRequest)
 		return rest.NewBadRequestError("An error occurred while reading the submitted data.", readErr)
 	}
 
+	lockoutKey := loginLockoutKey("user", form.Email)
+	if err := beginLoginAttempt(api.app, c, lockoutKey); err != nil {
+		triggerAuthFailure(api.app, attempt, core.AuthFailureLockedOut)
+		return err
+	}
+
 	user, submitErr := form.Submit()
 	if submitErr != nil {
+		triggerAuthFailure(api.app, attempt, loginFailureReason(submitErr))
 		return rest.NewBadRequestError("Failed to authenticate.", submitErr)
 	}
 
+	resetLoginFailures(api.app, lockoutKey)
+
+	attempt.User = user
+	triggerAuthSuccess(api.app, attempt)
+
 	return api.authResponse(c, user, nil)
 }
 
@@ -195,7 +282,7 @@ func (api *userApi) requestPasswordReset(c echo.Context) error {
 	// the result to the user (prevents users enumeration)
 	routine.FireAndForget(func() {
 		if err := form.Submit(); err != nil && api.app.IsDebug() {
-			log.Println(err)
+			api.app.Logger().Println(err)
 		}
 	})
 
@@ -245,6 +332,8 @@ func (api *userApi) confirmEmailChange(c echo.Context) error {
 		return rest.NewBadRequestError("Failed to confirm email change.", submitErr)
 	}
 
+	sendAuthWebhooks(api.app, core.AuthWebhookEventEmailChange, user)
+
 	return api.authResponse(c, user, nil)
 }
 
@@ -262,7 +351,7 @@ func (api *userApi) requestVerification(c echo.Context) error {
 	// the result to the user (prevents users enumeration)
 	routine.FireAndForget(func() {
 		if err := form.Submit(); err != nil && api.app.IsDebug() {
-			log.Println(err)
+			api.app.Logger().Println(err)
 		}
 	})
 
@@ -280,6 +369,8 @@ func (api *userApi) confirmVerification(c echo.Context) error {
 		return rest.NewBadRequestError("An error occurred while submitting the form.", submitErr)
 	}
 
+	sendAuthWebhooks(api.app, core.AuthWebhookEventVerify, user)
+
 	return api.authResponse(c, user, nil)
 }
 
@@ -296,6 +387,7 @@ func (api *userApi) list(c echo.Context) error {
 
 	result, searchErr := search.NewProvider(fieldResolver).
 		Query(api.app.Dao().UserQuery()).
+		Retry(api.app.Dao().WithRetry).
 		ParseAndExec(c.QueryString(), &users)
 	if searchErr != nil {
 		return rest.NewBadRequestError("", searchErr)
@@ -313,7 +405,7 @@ func (api *userApi) list(c echo.Context) error {
 	}
 
 	return api.app.OnUsersListRequest().Trigger(event, func(e *core.UsersListEvent) error {
-		return e.HttpContext.JSON(http.StatusOK, e.Result)
+		return listResponse(e.HttpContext, e.Result)
 	})
 }
 
@@ -338,6 +430,81 @@ func (api *userApi) view(c echo.Context) error {
 	})
 }
 
+func (api *userApi) listExternalAuths(c echo.Context) error {
+	id := c.PathParam("id")
+	if id == "" {
+		return rest.NewNotFoundError("", nil)
+	}
+
+	user, err := api.app.Dao().FindUserById(id)
+	if err != nil || user == nil {
+		return rest.NewNotFoundError("", err)
+	}
+
+	externalAuths, err := api.app.Dao().FindAllExternalAuthsByUserId(user.Id)
+	if err != nil {
+		return rest.NewBadRequestError("Failed to fetch the user external auths.", err)
+	}
+
+	return c.JSON(http.StatusOK, externalAuths)
+}
+
+func (api *userApi) unlinkExternalAuth(c echo.Context) error {
+	id := c.PathParam("id")
+	provider := c.PathParam("provider")
+	if id == "" || provider == "" {
+		return rest.NewNotFoundError("", nil)
+	}
+
+	user, err := api.app.Dao().FindUserById(id)
+	if err != nil || user == nil {
+		return rest.NewNotFoundError("", err)
+	}
+
+	externalAuth, err := api.app.Dao().FindExternalAuthByUserIdAndProvider(user.Id, provider)
+	if err != nil || externalAuth == nil {
+		return rest.NewNotFoundError("Missing external auth provider relation.", err)
+	}
+
+	if err := api.app.Dao().DeleteExternalAuth(externalAuth); err != nil {
+		return rest.NewBadRequestError("Failed to unlink the external auth provider.", err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// refreshTokenKey regenerates the user token key, invalidating
+// all previously issued tokens of the user (the other users are not affected).
+//
+// If the user refreshes its own token key, a new auth token is returned
+// so that the current session could continue.
+//
+// Note that the password reset/change also refreshes the user token key.
+func (api *userApi) refreshTokenKey(c echo.Context) error {
+	id := c.PathParam("id")
+	if id == "" {
+		return rest.NewNotFoundError("", nil)
+	}
+
+	user, err := api.app.Dao().FindUserById(id)
+	if err != nil || user == nil {
+		return rest.NewNotFoundError("", err)
+	}
+
+	user.RefreshTokenKey()
+
+	if err := api.app.Dao().SaveUser(user); err != nil {
+		return rest.NewBadRequestError("Failed to refresh the user token key.", err)
+	}
+
+	authUser, _ := c.Get(ContextUserKey).(*models.User)
+	if authUser != nil && authUser.Id == user.Id {
+		return api.authResponse(c, user, nil)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
 func (api *userApi) create(c echo.Context) error {
 	if !api.app.Settings().EmailAuth.Enabled {
 		return rest.NewBadRequestError("Email/Password authentication is not enabled.", nil)
@@ -422,17 +589,40 @@ func (api *userApi) delete(c echo.Context) error {
 		return rest.NewNotFoundError("", err)
 	}
 
+	// report the records that will be deleted or updated
+	// without actually deleting the user
+	if cast.ToBool(c.QueryParam("dryRun")) {
+		report, err := api.app.Dao().DeleteUserDryRun(user)
+		if err != nil {
+			return rest.NewBadRequestError("Failed to delete user. Make sure that the user is not part of a required relation reference.", err)
+		}
+
+		return c.JSON(http.StatusOK, exportUserDeleteReport(report))
+	}
+
 	event := &core.UserDeleteEvent{
 		HttpContext: c,
 		User:        user,
 	}
 
 	handlerErr := api.app.OnUserBeforeDeleteRequest().Trigger(event, func(e *core.UserDeleteEvent) error {
+		// find the cascade deleted records (to cleanup their files)
+		report, err := api.app.Dao().DeleteUserDryRun(e.User)
+		if err != nil {
+			return rest.NewBadRequestError("Failed to delete user. Make sure that the user is not part of a required relation reference.", err)
+		}
+
 		// delete the user model
 		if err := api.app.Dao().DeleteUser(e.User); err != nil {
 			return rest.NewBadRequestError("Failed to delete user. Make sure that the user is not part of a required relation reference.", err)
 		}
 
+		if err := api.deleteRecordsFiles(report.DeletedRecords); err != nil && api.app.IsDebug() {
+			// non critical error - only log for debug
+			// (usually could happen due to S3 api limits)
+			api.app.Logger().Println(err)
+		}
+
 		return e.HttpContext.NoContent(http.StatusNoContent)
 	})
 
@@ -442,3 +632,66 @@ func (api *userApi) delete(c echo.Context) error {
 
 	return handlerErr
 }
+
+func (api *userApi) deleteRecordsFiles(records []*models.Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	fs, err := api.app.NewFilesystem()
+	if err != nil {
+		return err
+	}
+	defer fs.Close()
+
+	var totalFailed int
+	for _, record := range records {
+		totalFailed += len(fs.DeletePrefix(record.BaseFilesPath()))
+	}
+
+	if totalFailed > 0 {
+		return fmt.Errorf("Failed to delete %d record files.", totalFailed)
+	}
+
+	return nil
+}
+
+type userDeleteReportRecord struct {
+	Id             string   `json:"id"`
+	CollectionId   string   `json:"collectionId"`
+	CollectionName string   `json:"collectionName"`
+	Files          []string `json:"files,omitempty"`
+}
+
+// exportUserDeleteReport exports the provided user delete dry run report
+// in the format {"deleted": [...], "updated": [...]}.
+func exportUserDeleteReport(report *daos.UserDeleteReport) map[string]any {
+	export := func(records []*models.Record, withFiles bool) []userDeleteReportRecord {
+		result := make([]userDeleteReportRecord, len(records))
+
+		for i, record := range records {
+			item := userDeleteReportRecord{
+				Id:             record.Id,
+				CollectionId:   record.Collection().Id,
+				CollectionName: record.Collection().Name,
+			}
+
+			if withFiles {
+				for _, field := range record.Collection().Schema.Fields() {
+					if field.Type == schema.FieldTypeFile {
+						item.Files = append(item.Files, record.GetStringSliceDataValue(field.Name)...)
+					}
+				}
+			}
+
+			result[i] = item
+		}
+
+		return result
+	}
+
+	return map[string]any{
+		"deleted": export(report.DeletedRecords, true),
+		"updated": export(report.UpdatedRecords, false),
+	}
+}
@@ -0,0 +1,96 @@
+package apis
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/routine"
+	"github.com/pocketbase/pocketbase/tools/webhook"
+)
+
+// authWebhookSender is the sender used for the auth events webhooks delivery.
+var authWebhookSender = &webhook.Sender{}
+
+// authWebhookPayload defines the delivered auth webhook event data.
+type authWebhookPayload struct {
+	Event     string         `json:"event"`
+	Timestamp int64          `json:"timestamp"`
+	User      map[string]any `json:"user"`
+}
+
+// bindUserWebhooks registers the app hooks that dispatch
+// the register and delete auth webhook events.
+//
+// The verify and email change events are dispatched directly
+// from their confirm handlers.
+func bindUserWebhooks(app core.App) {
+	app.OnUserAfterCreateRequest().Add(func(e *core.UserCreateEvent) error {
+		sendAuthWebhooks(app, core.AuthWebhookEventRegister, e.User)
+		return nil
+	})
+
+	app.OnUserAfterOauth2Register().Add(func(e *core.UserOauth2RegisterEvent) error {
+		sendAuthWebhooks(app, core.AuthWebhookEventRegister, e.User)
+		return nil
+	})
+
+	app.OnUserAfterDeleteRequest().Add(func(e *core.UserDeleteEvent) error {
+		sendAuthWebhooks(app, core.AuthWebhookEventDelete, e.User)
+		return nil
+	})
+}
+
+// sendAuthWebhooks delivers in the background the auth event
+// to all enabled webhook endpoints subscribed for it.
+func sendAuthWebhooks(app core.App, event string, user *models.User) {
+	if user == nil {
+		return
+	}
+
+	for _, endpoint := range app.Settings().AuthWebhooks.Endpoints {
+		if !endpoint.Enabled || !endpoint.HasEvent(event) {
+			continue
+		}
+
+		body, err := authWebhookBody(event, user, endpoint.ExcludeFields)
+		if err != nil {
+			app.Logger().Println("Failed to build auth webhook payload:", err)
+			return
+		}
+
+		url := endpoint.Url
+		secret := endpoint.Secret
+
+		routine.FireAndForget(func() {
+			if err := authWebhookSender.Send(url, secret, body); err != nil {
+				app.Logger().Printf("Failed to deliver %q auth webhook: %v\n", event, err)
+			}
+		})
+	}
+}
+
+// authWebhookBody builds the auth webhook event payload
+// without the excluded and the user secret fields.
+func authWebhookBody(event string, user *models.User, excludeFields []string) ([]byte, error) {
+	data, err := user.AsMap()
+	if err != nil {
+		return nil, err
+	}
+
+	// never deliver the user secrets
+	// (they should be already omitted from the json serialization)
+	delete(data, "passwordHash")
+	delete(data, "tokenKey")
+
+	for _, field := range excludeFields {
+		delete(data, field)
+	}
+
+	return json.Marshal(authWebhookPayload{
+		Event:     event,
+		Timestamp: time.Now().Unix(),
+		User:      data,
+	})
+}
@@ -1,12 +1,22 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"os"
+	"path"
+	"strings"
+	"time"
 
+	"github.com/AlecAivazis/survey/v2"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/migrations/diff"
 	"github.com/pocketbase/pocketbase/migrations/logs"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/inflector"
 	"github.com/pocketbase/pocketbase/tools/migrate"
 	"github.com/spf13/cobra"
 )
@@ -18,13 +28,19 @@ Supported arguments are:
 - up                 - runs all available migrations.
 - down [number]      - reverts the last [number] applied migrations.
 - create folder name - creates new migration template file.
+- snapshot           - saves the current collections state as snapshot.
+- diff name [folder] - generates new migration file from the changes
+                       between the collections snapshot and the current
+                       collections state (and updates the snapshot).
 `
 	var databaseFlag string
+	var snapshotFlag string
+	var renameFlag []string
 
 	command := &cobra.Command{
 		Use:       "migrate",
 		Short:     "Executes DB migration scripts",
-		ValidArgs: []string{"up", "down", "create"},
+		ValidArgs: []string{"up", "down", "create", "snapshot", "diff"},
 		Long:      desc,
 		Run: func(command *cobra.Command, args []string) {
 			// normalize
@@ -32,6 +48,24 @@ Supported arguments are:
 				databaseFlag = "db"
 			}
 
+			if len(args) > 0 && (args[0] == "snapshot" || args[0] == "diff") {
+				if databaseFlag != "db" {
+					log.Fatalf("The %q command is supported only for the db database.", args[0])
+				}
+
+				var err error
+				if args[0] == "snapshot" {
+					err = migrateSnapshot(app, snapshotFlag)
+				} else {
+					err = migrateDiff(app, snapshotFlag, renameFlag, args)
+				}
+				if err != nil {
+					log.Fatal(err)
+				}
+
+				return
+			}
+
 			connections := migrationsConnectionsMap(app)
 
 			runner, err := migrate.NewRunner(
@@ -55,6 +89,20 @@ Supported arguments are:
 		"specify the database connection to use (db or logs)",
 	)
 
+	command.PersistentFlags().StringVar(
+		&snapshotFlag,
+		"snapshot",
+		"pb_collections_snapshot.json",
+		"the collections snapshot file used by the snapshot and diff commands",
+	)
+
+	command.PersistentFlags().StringArrayVar(
+		&renameFlag,
+		"rename",
+		nil,
+		"field rename hint for the diff command in the format collection.oldField:newField",
+	)
+
 	return command
 }
 
@@ -75,3 +123,108 @@ func migrationsConnectionsMap(app core.App) map[string]migrationsConnection {
 		},
 	}
 }
+
+func migrateSnapshot(app core.App, snapshotFile string) error {
+	collections := []*models.Collection{}
+	if err := app.Dao().CollectionQuery().All(&collections); err != nil {
+		return err
+	}
+
+	if err := diff.SaveSnapshot(snapshotFile, collections); err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully saved collections snapshot %q\n", snapshotFile)
+	return nil
+}
+
+func migrateDiff(app core.App, snapshotFile string, renameHints []string, args []string) error {
+	if len(args) < 2 {
+		return errors.New("Missing migration file name")
+	}
+
+	renames, err := parseRenameHints(renameHints)
+	if err != nil {
+		return err
+	}
+
+	oldState, err := diff.LoadSnapshot(snapshotFile)
+	if err != nil {
+		return err
+	}
+
+	newState := []*models.Collection{}
+	if err := app.Dao().CollectionQuery().All(&newState); err != nil {
+		return err
+	}
+
+	content, err := diff.GenerateMigration(oldState, newState, renames)
+	if err != nil {
+		if errors.Is(err, diff.ErrNoChanges) {
+			fmt.Println("No collection changes since the last snapshot.")
+			return nil
+		}
+		return err
+	}
+
+	var dir string
+	if len(args) == 3 {
+		dir = args[2]
+	}
+	if dir == "" {
+		// similar to the create command, it is expected
+		// the user to be in the app working directory
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		dir = path.Join(wd, "migrations")
+	}
+
+	resultFilePath := path.Join(
+		dir,
+		fmt.Sprintf("%d_%s.go", time.Now().Unix(), inflector.Snakecase(args[1])),
+	)
+
+	confirm := false
+	prompt := &survey.Confirm{
+		Message: fmt.Sprintf("Do you really want to create migration %q?", resultFilePath),
+	}
+	survey.AskOne(prompt, &confirm)
+	if !confirm {
+		fmt.Println("The command has been cancelled")
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(resultFilePath, content, 0644); err != nil {
+		return fmt.Errorf("Failed to save migration file %q\n", resultFilePath)
+	}
+
+	if err := diff.SaveSnapshot(snapshotFile, newState); err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully created file %q\n", resultFilePath)
+	return nil
+}
+
+// parseRenameHints converts the "collection.oldField:newField"
+// rename hints into a diff.Compare renames map.
+func parseRenameHints(hints []string) (map[string]string, error) {
+	result := make(map[string]string, len(hints))
+
+	for _, hint := range hints {
+		parts := strings.SplitN(hint, ":", 2)
+		if len(parts) != 2 || !strings.Contains(parts[0], ".") || parts[1] == "" {
+			return nil, fmt.Errorf("Invalid rename hint %q (expected collection.oldField:newField)", hint)
+		}
+
+		result[parts[0]] = parts[1]
+	}
+
+	return result, nil
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/spf13/cobra"
+)
+
+// NewMirrorsCommand creates and returns new command for managing
+// the collections denormalized mirror fields.
+func NewMirrorsCommand(app core.App) *cobra.Command {
+	desc := `
+Supported arguments are:
+- backfill [collection] - recomputes the mirror fields of the existing
+                          records of the specified collection (or of all
+                          collections with mirrors if not set) and clears
+                          their stale mark left by a failed automatic sync.
+`
+
+	command := &cobra.Command{
+		Use:       "mirrors",
+		Short:     "Manages the collections denormalized mirror fields",
+		ValidArgs: []string{"backfill"},
+		Long:      desc,
+		Run: func(command *cobra.Command, args []string) {
+			if len(args) == 0 || args[0] != "backfill" {
+				log.Fatal("Missing or unsupported mirrors command argument.")
+			}
+
+			var collectionNameOrId string
+			if len(args) > 1 {
+				collectionNameOrId = args[1]
+			}
+
+			if err := mirrorsBackfill(app, collectionNameOrId); err != nil {
+				log.Fatal(err)
+			}
+		},
+	}
+
+	return command
+}
+
+func mirrorsBackfill(app core.App, collectionNameOrId string) error {
+	collections := []*models.Collection{}
+
+	if collectionNameOrId != "" {
+		collection, err := app.Dao().FindCollectionByNameOrId(collectionNameOrId)
+		if err != nil {
+			return fmt.Errorf("Missing collection %q.", collectionNameOrId)
+		}
+		collections = append(collections, collection)
+	} else if err := app.Dao().CollectionQuery().All(&collections); err != nil {
+		return err
+	}
+
+	for _, collection := range collections {
+		if len(collection.Options.Mirrors) == 0 {
+			continue
+		}
+
+		updated, err := app.Dao().BackfillRecordMirrors(collection)
+		if err != nil {
+			return fmt.Errorf("Failed to backfill the %q mirrors: %w", collection.Name, err)
+		}
+
+		fmt.Printf("Successfully backfilled %q (%d updated records)\n", collection.Name, updated)
+	}
+
+	return nil
+}
@@ -49,11 +49,16 @@ func NewServeCommand(app core.App, showStartBanner bool) *cobra.Command {
 				panic(err)
 			}
 
+			// start the auto backups scheduler
+			go runAutoBackups(app)
+
 			// configure cors
 			router.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 				Skipper:      middleware.DefaultSkipper,
 				AllowOrigins: allowedOrigins,
 				AllowMethods: []string{http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPatch, http.MethodPost, http.MethodDelete},
+				// allow reading the unenveloped list response pagination headers
+				ExposeHeaders: []string{"X-Total-Count", "X-Page", "X-Per-Page", "Link"},
 			}))
 
 			// start http server
@@ -88,9 +93,9 @@ func NewServeCommand(app core.App, showStartBanner bool) *cobra.Command {
 					schema = "https"
 				}
 				bold := color.New(color.Bold).Add(color.FgGreen)
-				bold.Printf("> Server started at: %s\n", color.CyanString("%s://%s", schema, serverConfig.Addr))
-				fmt.Printf("  - REST API: %s\n", color.CyanString("%s://%s/api/", schema, serverConfig.Addr))
-				fmt.Printf("  - Admin UI: %s\n", color.CyanString("%s://%s/_/", schema, serverConfig.Addr))
+				bold.Printf("> Server started at: %s\n", color.CyanString("%s://%s%s", schema, serverConfig.Addr, app.BasePath()))
+				fmt.Printf("  - REST API: %s\n", color.CyanString("%s://%s%s/api/", schema, serverConfig.Addr, app.BasePath()))
+				fmt.Printf("  - Admin UI: %s\n", color.CyanString("%s://%s%s/_/", schema, serverConfig.Addr, app.BasePath()))
 			}
 
 			var serveErr error
@@ -153,3 +158,28 @@ func runMigrations(app core.App) error {
 
 	return nil
 }
+
+// runAutoBackups periodically checks and creates
+// a new app backup when the configured auto interval has passed.
+func runAutoBackups(app core.App) {
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		runAutoBackup(app)
+	}
+}
+
+// runAutoBackup executes a single auto backup check
+// recovering from a panic so that the next ticks are not stopped.
+func runAutoBackup(app core.App) {
+	defer func() {
+		if r := recover(); r != nil {
+			app.Logger().Println("Auto backup panic:", r)
+		}
+	}()
+
+	if err := core.AutoBackup(app); err != nil {
+		app.Logger().Println("Auto backup failure:", err)
+	}
+}
@@ -4,6 +4,9 @@
 package core
 
 import (
+	"context"
+	"log"
+
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/daos"
 	"github.com/pocketbase/pocketbase/tools/filesystem"
@@ -11,6 +14,7 @@ import (
 	"github.com/pocketbase/pocketbase/tools/mailer"
 	"github.com/pocketbase/pocketbase/tools/store"
 	"github.com/pocketbase/pocketbase/tools/subscriptions"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // App defines the main PocketBase app interface.
@@ -18,6 +22,10 @@ type App interface {
 	// DB returns the default app database instance.
 	DB() *dbx.DB
 
+	// ReadDB returns the default app database read pool instance
+	// (it is the same as DB() if DBConfig().SeparateReadPool is not enabled).
+	ReadDB() *dbx.DB
+
 	// Dao returns the default app Dao instance.
 	//
 	// This Dao could operate only on the tables and models
@@ -42,10 +50,42 @@ type App interface {
 	// (used for settings encryption).
 	EncryptionEnv() string
 
+	// DBConfig returns the app databases connection pool and pragmas config.
+	DBConfig() DBConfig
+
+	// Logger returns the app logger.
+	Logger() *log.Logger
+
+	// Tracer returns the app OpenTelemetry tracer
+	// (nil if tracing is not enabled).
+	Tracer() trace.Tracer
+
+	// IsApiDisabled reports whether the provided built-in api group
+	// (eg. ApiGroupAdminUI) is disabled and shouldn't be registered.
+	IsApiDisabled(group string) bool
+
+	// SPAConfig returns the app single page application config
+	// (nil if the app doesn't serve a SPA).
+	SPAConfig() *SPAConfig
+
+	// BasePath returns the app routes path prefix
+	// (empty string if the app is served from the root path).
+	BasePath() string
+
+	// InstanceId returns the app instance identifier.
+	InstanceId() string
+
+	// TokensAudience returns the "aud" claim of the app issued tokens.
+	TokensAudience() string
+
 	// IsDebug returns whether the app is in debug mode
 	// (showing more detailed error logs, executed sql statements, etc.).
 	IsDebug() bool
 
+	// StartQueryLog starts collecting the db queries executed in debug mode
+	// and returns the query log context, the log and its stop func.
+	StartQueryLog(ctx context.Context) (context.Context, *QueryLog, func())
+
 	// Settings returns the loaded app settings.
 	Settings() *Settings
 
@@ -75,6 +115,24 @@ type App interface {
 	// (eg. closing db connections).
 	ResetBootstrapState() error
 
+	// CreateBackup creates a new backup archive with the provided name
+	// in the app backups directory (see [core.BackupsDir]).
+	CreateBackup(name string) error
+
+	// RestoreBackup replaces the current app data with the one from
+	// the specified stored backup archive and reinitializes the app.
+	RestoreBackup(name string) error
+
+	// AddHealthCheck registers a new named critical health check
+	// that is included in the app readiness report.
+	//
+	// The returned check could be used to further adjust its options
+	// (eg. to mark it as informational or to change its timeout).
+	AddHealthCheck(name string, check HealthCheckFunc) *HealthCheck
+
+	// HealthChecks returns the registered app health checks.
+	HealthChecks() []*HealthCheck
+
 	// ---------------------------------------------------------------
 	// App event hooks
 	// ---------------------------------------------------------------
@@ -111,6 +169,14 @@ type App interface {
 	// existing entry from the DB.
 	OnModelAfterDelete() *hook.Hook[*ModelEvent]
 
+	// OnRecordFieldsChange registers and returns a new hook that is
+	// triggered after updating a record of the specified collection,
+	// only if at least one of the listed fields has changed
+	// (or any schema field if no fields are listed).
+	//
+	// The event contains the old and new values of the changed fields.
+	OnRecordFieldsChange(collectionNameOrId string, fields ...string) *hook.Hook[*RecordFieldsChangeEvent]
+
 	// ---------------------------------------------------------------
 	// Mailer event hooks
 	// ---------------------------------------------------------------
@@ -126,6 +192,17 @@ type App interface {
 	// admin password reset email was successfully sent.
 	OnMailerAfterAdminResetPasswordSend() *hook.Hook[*MailerAdminEvent]
 
+	// OnMailerBeforeAdminMagicLinkSend hook is triggered right before
+	// sending a magic link login email to an admin.
+	//
+	// Could be used to send your own custom email template if
+	// hook.StopPropagation is returned in one of its listeners.
+	OnMailerBeforeAdminMagicLinkSend() *hook.Hook[*MailerAdminEvent]
+
+	// OnMailerAfterAdminMagicLinkSend hook is triggered after
+	// admin magic link login email was successfully sent.
+	OnMailerAfterAdminMagicLinkSend() *hook.Hook[*MailerAdminEvent]
+
 	// OnMailerBeforeUserResetPasswordSend hook is triggered right before
 	// sending a password reset email to a user.
 	//
@@ -176,6 +253,15 @@ type App interface {
 	// subscriptions were successfully changed.
 	OnRealtimeAfterSubscribeRequest() *hook.Hook[*RealtimeSubscribeEvent]
 
+	// OnRealtimeBeforeRecordBroadcast hook is triggered for each subscribed
+	// client right before sending a record change message
+	// (after the collection access rule check).
+	//
+	// Could be used to customize the message payload per client
+	// (eg. to hide some of the record fields) or to skip the message
+	// by setting the event Payload to nil.
+	OnRealtimeBeforeRecordBroadcast() *hook.Hook[*RealtimeRecordBroadcastEvent]
+
 	// ---------------------------------------------------------------
 	// Settings API event hooks
 	// ---------------------------------------------------------------
@@ -203,6 +289,17 @@ type App interface {
 	// File API event hooks
 	// ---------------------------------------------------------------
 
+	// OnFileBeforeUpload hook is triggered before each record file
+	// is written to the storage.
+	//
+	// Could be used to transform the uploaded file content (eg. to strip
+	// the images EXIF metadata) by wrapping or replacing the event reader.
+	// The handlers are executed in the order of their registration,
+	// allowing multiple transforms to be chained.
+	//
+	// Returning an error (or a failed transform read) aborts the record save.
+	OnFileBeforeUpload() *hook.Hook[*FileUploadEvent]
+
 	// OnFileDownloadRequest hook is triggered before each API File download request.
 	//
 	// Could be used to validate or modify the file response before
@@ -263,6 +360,31 @@ type App interface {
 	// authenticated admin data and token.
 	OnAdminAuthRequest() *hook.Hook[*AdminAuthEvent]
 
+	// ---------------------------------------------------------------
+	// Auth attempt event hooks
+	// ---------------------------------------------------------------
+
+	// OnAuthSuccess hook is triggered after each successful API Admin
+	// or User sign-in attempt (email/password and OAuth2).
+	//
+	// Could be used for auditing (eg. streaming the events to a SIEM).
+	// The hook errors are only logged and don't affect the response.
+	OnAuthSuccess() *hook.Hook[*AuthAttemptEvent]
+
+	// OnAuthFailure hook is triggered after each failed API Admin
+	// or User sign-in attempt (email/password and OAuth2).
+	//
+	// Could be used for auditing (eg. streaming the events to a SIEM).
+	// The hook errors are only logged and don't affect the response.
+	OnAuthFailure() *hook.Hook[*AuthAttemptEvent]
+
+	// OnAuthMeRequest hook is triggered on each API current auth
+	// identity request (aka. GET /api/auth/me).
+	//
+	// Could be used to enrich the response with extra Meta data
+	// (eg. the authenticated admin or user permissions).
+	OnAuthMeRequest() *hook.Hook[*AuthMeEvent]
+
 	// ---------------------------------------------------------------
 	// User API event hooks
 	// ---------------------------------------------------------------
@@ -302,8 +424,9 @@ type App interface {
 	// OnUserBeforeDeleteRequest hook is triggered before each API User
 	// delete request (after model load and before actual deletion).
 	//
-	// Could be used to additionally validate the request data or implement
-	// completely different delete behavior (returning hook.StopPropagation).
+	// Could be used to additionally validate the request data, cleanup or
+	// anonymize the user related data or implement completely different
+	// delete behavior (returning hook.StopPropagation).
 	OnUserBeforeDeleteRequest() *hook.Hook[*UserDeleteEvent]
 
 	// OnUserAfterDeleteRequest hook is triggered after each
@@ -330,50 +453,65 @@ type App interface {
 
 	// ---------------------------------------------------------------
 	// Record API event hooks
+	//
+	// The optional tags limit the registered handlers to the records
+	// of the specified collections (by name or id, or "*" for all), eg.:
+	//	app.OnRecordAfterCreateRequest("posts", "comments").Add(...)
 	// ---------------------------------------------------------------
 
 	// OnRecordsListRequest hook is triggered on each API Records list request.
 	//
 	// Could be used to validate or modify the response before returning it to the client.
-	OnRecordsListRequest() *hook.Hook[*RecordsListEvent]
+	OnRecordsListRequest(tags ...string) *hook.TaggedHook[*RecordsListEvent]
 
 	// OnRecordViewRequest hook is triggered on each API Record view request.
 	//
 	// Could be used to validate or modify the response before returning it to the client.
-	OnRecordViewRequest() *hook.Hook[*RecordViewEvent]
+	OnRecordViewRequest(tags ...string) *hook.TaggedHook[*RecordViewEvent]
 
 	// OnRecordBeforeCreateRequest hook is triggered before each API Record
 	// create request (after request data load and before model persistence).
 	//
 	// Could be used to additionally validate the request data or implement
 	// completely different persistence behavior (returning hook.StopPropagation).
-	OnRecordBeforeCreateRequest() *hook.Hook[*RecordCreateEvent]
+	OnRecordBeforeCreateRequest(tags ...string) *hook.TaggedHook[*RecordCreateEvent]
 
 	// OnRecordAfterCreateRequest hook is triggered after each
 	// successful API Record create request.
-	OnRecordAfterCreateRequest() *hook.Hook[*RecordCreateEvent]
+	OnRecordAfterCreateRequest(tags ...string) *hook.TaggedHook[*RecordCreateEvent]
 
 	// OnRecordBeforeUpdateRequest hook is triggered before each API Record
 	// update request (after request data load and before model persistence).
 	//
 	// Could be used to additionally validate the request data or implement
 	// completely different persistence behavior (returning hook.StopPropagation).
-	OnRecordBeforeUpdateRequest() *hook.Hook[*RecordUpdateEvent]
+	OnRecordBeforeUpdateRequest(tags ...string) *hook.TaggedHook[*RecordUpdateEvent]
 
 	// OnRecordAfterUpdateRequest hook is triggered after each
 	// successful API Record update request.
-	OnRecordAfterUpdateRequest() *hook.Hook[*RecordUpdateEvent]
+	OnRecordAfterUpdateRequest(tags ...string) *hook.TaggedHook[*RecordUpdateEvent]
 
 	// OnRecordBeforeDeleteRequest hook is triggered before each API Record
 	// delete request (after model load and before actual deletion).
 	//
 	// Could be used to additionally validate the request data or implement
 	// completely different delete behavior (returning hook.StopPropagation).
-	OnRecordBeforeDeleteRequest() *hook.Hook[*RecordDeleteEvent]
+	OnRecordBeforeDeleteRequest(tags ...string) *hook.TaggedHook[*RecordDeleteEvent]
 
 	// OnRecordAfterDeleteRequest hook is triggered after each
 	// successful API Record delete request.
-	OnRecordAfterDeleteRequest() *hook.Hook[*RecordDeleteEvent]
+	OnRecordAfterDeleteRequest(tags ...string) *hook.TaggedHook[*RecordDeleteEvent]
+
+	// OnRecordBeforePublishRequest hook is triggered before each API Record
+	// publish request (after the record status change and before its persistence).
+	//
+	// Could be used to additionally validate the record or implement
+	// completely different publish behavior (returning hook.StopPropagation).
+	OnRecordBeforePublishRequest(tags ...string) *hook.TaggedHook[*RecordPublishEvent]
+
+	// OnRecordAfterPublishRequest hook is triggered after each
+	// successful API Record publish request.
+	OnRecordAfterPublishRequest(tags ...string) *hook.TaggedHook[*RecordPublishEvent]
 
 	// ---------------------------------------------------------------
 	// Collection API event hooks
@@ -0,0 +1,313 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/archive"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	// LocalBackupsDirName is the name of the app data subdirectory
+	// where the backup archives are stored.
+	LocalBackupsDirName string = "backups"
+
+	// AutoBackupPrefix is the name prefix of the automatically created backups.
+	AutoBackupPrefix string = "auto_pb_backup_"
+
+	// CacheKeyActiveRestore is the app cache key that is set
+	// while a backup restore is in progress.
+	CacheKeyActiveRestore string = "@activeRestore"
+)
+
+// list of the live db files that are not included in the backup archives
+// (the backups contain a consistent VACUUM INTO snapshot of the dbs instead)
+var backupSkipPaths = []string{
+	LocalBackupsDirName,
+	"data.db-shm",
+	"data.db-wal",
+	"logs.db-shm",
+	"logs.db-wal",
+}
+
+// BackupFileInfo defines a single backup archive file info.
+type BackupFileInfo struct {
+	Name     string         `json:"name"`
+	Size     int64          `json:"size"`
+	Modified types.DateTime `json:"modified"`
+}
+
+// BackupsDir returns the local directory path where the app backups are stored.
+func BackupsDir(app App) string {
+	return filepath.Join(app.DataDir(), LocalBackupsDirName)
+}
+
+// ListBackups returns the info of all stored app backups
+// (sorted by their modification date DESC).
+func ListBackups(app App) ([]*BackupFileInfo, error) {
+	result := []*BackupFileInfo{}
+
+	entries, err := os.ReadDir(BackupsDir(app))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return result, nil
+		}
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".zip") {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		modified, _ := types.ParseDateTime(info.ModTime())
+
+		result = append(result, &BackupFileInfo{
+			Name:     entry.Name(),
+			Size:     info.Size(),
+			Modified: modified,
+		})
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Modified.Time().After(result[j].Modified.Time())
+	})
+
+	return result, nil
+}
+
+// AutoBackup creates a new automatic app backup if the configured
+// `Settings.Backups.AutoInterval` has passed since the last one and
+// deletes the oldest automatic backups exceeding `Settings.Backups.AutoMaxKeep`.
+//
+// This method does nothing if the auto backups are disabled
+// or a backup restore is in progress.
+func AutoBackup(app App) error {
+	// the app settings and db are reset during the restore
+	if app.Cache().Has(CacheKeyActiveRestore) {
+		return nil
+	}
+
+	settings := app.Settings()
+	if settings == nil {
+		return nil // not bootstrapped
+	}
+
+	config := settings.Backups
+	if config.AutoInterval <= 0 {
+		return nil // disabled
+	}
+
+	backups, err := ListBackups(app)
+	if err != nil {
+		return err
+	}
+
+	autoBackups := []*BackupFileInfo{}
+	for _, b := range backups {
+		if strings.HasPrefix(b.Name, AutoBackupPrefix) {
+			autoBackups = append(autoBackups, b)
+		}
+	}
+
+	interval := time.Duration(config.AutoInterval) * time.Hour
+	if len(autoBackups) > 0 && time.Since(autoBackups[0].Modified.Time()) < interval {
+		return nil // not yet
+	}
+
+	name := AutoBackupPrefix + time.Now().UTC().Format("20060102150405") + ".zip"
+	if err := app.CreateBackup(name); err != nil {
+		return err
+	}
+
+	// prepend the new backup and delete the exceeding old ones
+	autoBackups = append([]*BackupFileInfo{{Name: name}}, autoBackups...)
+	if config.AutoMaxKeep > 0 && len(autoBackups) > config.AutoMaxKeep {
+		for _, b := range autoBackups[config.AutoMaxKeep:] {
+			if err := os.Remove(filepath.Join(BackupsDir(app), b.Name)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// CreateBackup creates a new backup zip archive with the provided name
+// in the app backups directory.
+//
+// The archive contains a VACUUM INTO snapshot of the app databases and
+// all other app data directory files (eg. the local storage uploads).
+//
+// NB! When the S3 storage is enabled, the uploaded files are not included.
+func (app *BaseApp) CreateBackup(name string) error {
+	if err := checkBackupName(name); err != nil {
+		return err
+	}
+
+	if !app.backupMux.TryLock() {
+		return errors.New("Another backup/restore operation is already in progress.")
+	}
+	defer app.backupMux.Unlock()
+
+	dest := filepath.Join(BackupsDir(app), name)
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("Backup %q already exists.", name)
+	}
+
+	tempDir := filepath.Join(BackupsDir(app), ".pb_temp_backup_"+security.RandomString(10))
+	if err := os.MkdirAll(tempDir, os.ModePerm); err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+
+	// create a consistent snapshot of the dbs
+	snapshots := map[string]*dbx.DB{
+		"data.db": app.DB(),
+		"logs.db": app.LogsDB(),
+	}
+	for file, db := range snapshots {
+		_, err := db.NewQuery("VACUUM INTO {:path}").
+			Bind(dbx.Params{"path": filepath.Join(tempDir, file)}).
+			Execute()
+		if err != nil {
+			return err
+		}
+	}
+
+	// the snapshots dir files take precedence over the live db files
+	return archive.Create(dest, []string{tempDir, app.DataDir()}, backupSkipPaths...)
+}
+
+// RestoreBackup replaces the current app data with the one from the
+// specified backup archive and reinitializes the app.
+//
+// While the restore is in progress the app db connections are closed
+// and [CacheKeyActiveRestore] is set in the app cache, so that the
+// apis could reject the incoming requests.
+//
+// If the restored app fails to bootstrap, the previous app data is restored back.
+func (app *BaseApp) RestoreBackup(name string) error {
+	if err := checkBackupName(name); err != nil {
+		return err
+	}
+
+	if !app.backupMux.TryLock() {
+		return errors.New("Another backup/restore operation is already in progress.")
+	}
+	defer app.backupMux.Unlock()
+
+	src := filepath.Join(BackupsDir(app), name)
+	if _, err := os.Stat(src); err != nil {
+		return fmt.Errorf("Missing or invalid backup %q.", name)
+	}
+
+	// set as early as possible to reject the new requests
+	// and the auto backups before the app state is reset
+	app.Cache().Set(CacheKeyActiveRestore, name)
+	defer app.Cache().Remove(CacheKeyActiveRestore)
+
+	suffix := security.RandomString(10)
+
+	extractDir := filepath.Join(BackupsDir(app), ".pb_temp_restore_"+suffix)
+	defer os.RemoveAll(extractDir)
+
+	if err := archive.Extract(src, extractDir); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(filepath.Join(extractDir, "data.db")); err != nil {
+		return errors.New("Invalid backup archive - missing data.db.")
+	}
+
+	// close the db connections to stop all writes
+	if err := app.ResetBootstrapState(); err != nil {
+		return err
+	}
+
+	oldDir := filepath.Join(BackupsDir(app), ".pb_temp_old_"+suffix)
+	defer os.RemoveAll(oldDir)
+
+	if err := moveDirEntries(app.DataDir(), oldDir, LocalBackupsDirName); err != nil {
+		return app.revertRestore(oldDir, err)
+	}
+
+	if err := moveDirEntries(extractDir, app.DataDir()); err != nil {
+		return app.revertRestore(oldDir, err)
+	}
+
+	if err := app.Bootstrap(); err != nil {
+		return app.revertRestore(oldDir, err)
+	}
+
+	return nil
+}
+
+// revertRestore moves back the old app data files and bootstraps the app again.
+func (app *BaseApp) revertRestore(oldDir string, restoreErr error) error {
+	app.ResetBootstrapState()
+
+	entries, _ := os.ReadDir(app.DataDir())
+	for _, entry := range entries {
+		if entry.Name() == LocalBackupsDirName {
+			continue
+		}
+		os.RemoveAll(filepath.Join(app.DataDir(), entry.Name()))
+	}
+
+	if err := moveDirEntries(oldDir, app.DataDir()); err != nil {
+		return fmt.Errorf("%v (failed to revert: %v)", restoreErr, err)
+	}
+
+	if err := app.Bootstrap(); err != nil {
+		return fmt.Errorf("%v (failed to revert: %v)", restoreErr, err)
+	}
+
+	return restoreErr
+}
+
+// moveDirEntries moves all src directory entries into the dest directory.
+func moveDirEntries(src string, dest string, skipNames ...string) error {
+	if err := os.MkdirAll(dest, os.ModePerm); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if list.ExistInSlice(entry.Name(), skipNames) {
+			continue
+		}
+
+		if err := os.Rename(filepath.Join(src, entry.Name()), filepath.Join(dest, entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkBackupName(name string) error {
+	if name == "" || filepath.Base(name) != name || !strings.HasSuffix(name, ".zip") {
+		return fmt.Errorf("Invalid backup name %q.", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package core_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestBackupsDir(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	expected := filepath.Join(app.DataDir(), "backups")
+
+	if dir := core.BackupsDir(app); dir != expected {
+		t.Fatalf("Expected %q, got %q", expected, dir)
+	}
+}
+
+func TestCreateBackupInvalidName(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	names := []string{"", "test", "test.txt", "../test.zip", "a/test.zip"}
+
+	for _, name := range names {
+		if err := app.CreateBackup(name); err == nil {
+			t.Errorf("Expected error for name %q, got nil", name)
+		}
+	}
+}
+
+func TestCreateAndListBackups(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// no backups dir
+	backups, err := core.ListBackups(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 0 {
+		t.Fatalf("Expected no backups, got %d", len(backups))
+	}
+
+	if err := app.CreateBackup("test1.zip"); err != nil {
+		t.Fatal(err)
+	}
+
+	// duplicated name
+	if err := app.CreateBackup("test1.zip"); err == nil {
+		t.Fatal("Expected error for duplicated backup name, got nil")
+	}
+
+	if err := app.CreateBackup("test2.zip"); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, err = core.ListBackups(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(backups) != 2 {
+		t.Fatalf("Expected 2 backups, got %d", len(backups))
+	}
+
+	for _, b := range backups {
+		if b.Size == 0 {
+			t.Errorf("Expected non-empty backup %q", b.Name)
+		}
+	}
+
+	// ensure that the temp snapshot dirs were cleaned up
+	entries, _ := os.ReadDir(core.BackupsDir(app))
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".pb_temp") {
+			t.Errorf("Expected temp dir %q to be deleted", entry.Name())
+		}
+	}
+}
+
+func TestRestoreBackup(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if err := app.RestoreBackup("missing.zip"); err == nil {
+		t.Fatal("Expected error for missing backup, got nil")
+	}
+
+	if err := app.CreateBackup("test.zip"); err != nil {
+		t.Fatal(err)
+	}
+
+	// modify the current app data
+	collection, err := app.Dao().FindCollectionByNameOrId("demo3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Dao().DeleteCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(app.DataDir(), "new.txt"), []byte("test"), 0644)
+
+	if err := app.RestoreBackup("test.zip"); err != nil {
+		t.Fatal(err)
+	}
+
+	if app.Cache().Has(core.CacheKeyActiveRestore) {
+		t.Fatal("Expected the active restore cache key to be removed")
+	}
+
+	if _, err := app.Dao().FindCollectionByNameOrId("demo3"); err != nil {
+		t.Fatalf("Expected the demo3 collection to be restored, got %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(app.DataDir(), "new.txt")); err == nil {
+		t.Fatal("Expected new.txt to be removed after the restore")
+	}
+
+	if _, err := os.Stat(filepath.Join(core.BackupsDir(app), "test.zip")); err != nil {
+		t.Fatal("Expected the backups to be preserved after the restore")
+	}
+}
+
+func TestAutoBackup(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// disabled
+	if err := core.AutoBackup(app); err != nil {
+		t.Fatal(err)
+	}
+	if backups, _ := core.ListBackups(app); len(backups) != 0 {
+		t.Fatalf("Expected no backups, got %d", len(backups))
+	}
+
+	app.Settings().Backups.AutoInterval = 1
+	app.Settings().Backups.AutoMaxKeep = 2
+
+	// create some old auto backups
+	os.MkdirAll(core.BackupsDir(app), os.ModePerm)
+	oldNames := []string{core.AutoBackupPrefix + "1.zip", core.AutoBackupPrefix + "2.zip", "manual.zip"}
+	for i, name := range oldNames {
+		path := filepath.Join(core.BackupsDir(app), name)
+		os.WriteFile(path, []byte("test"), 0644)
+		modTime := time.Now().Add(time.Duration(-10+i) * time.Hour)
+		os.Chtimes(path, modTime, modTime)
+	}
+
+	if err := core.AutoBackup(app); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, _ := core.ListBackups(app)
+
+	names := []string{}
+	for _, b := range backups {
+		names = append(names, b.Name)
+	}
+
+	// 1 new auto + 1 old auto + 1 manual
+	if len(names) != 3 {
+		t.Fatalf("Expected 3 backups, got %v", names)
+	}
+
+	if !strings.HasPrefix(names[0], core.AutoBackupPrefix) {
+		t.Fatalf("Expected the new auto backup to be first, got %v", names)
+	}
+
+	if _, err := os.Stat(filepath.Join(core.BackupsDir(app), oldNames[0])); err == nil {
+		t.Fatalf("Expected the oldest auto backup to be deleted, got %v", names)
+	}
+
+	// the interval hasn't passed yet
+	if err := core.AutoBackup(app); err != nil {
+		t.Fatal(err)
+	}
+	if backups, _ := core.ListBackups(app); len(backups) != 3 {
+		t.Fatalf("Expected 3 backups, got %d", len(backups))
+	}
+}
+
+func TestAutoBackupDuringRestore(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Settings().Backups.AutoInterval = 1
+
+	// simulate the restore state between the app reset and bootstrap
+	app.Cache().Set(core.CacheKeyActiveRestore, "test.zip")
+	if err := app.ResetBootstrapState(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := core.AutoBackup(app); err != nil {
+		t.Fatalf("Expected the auto backup to be skipped, got %v", err)
+	}
+
+	// reset state without the active restore cache key
+	app.Cache().Remove(core.CacheKeyActiveRestore)
+	if err := core.AutoBackup(app); err != nil {
+		t.Fatalf("Expected the auto backup to be skipped, got %v", err)
+	}
+
+	if backups, _ := core.ListBackups(app); len(backups) != 0 {
+		t.Fatalf("Expected no backups, got %d", len(backups))
+	}
+
+	if err := app.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+}
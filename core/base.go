@@ -5,8 +5,12 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"log"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/fatih/color"
@@ -19,25 +23,61 @@ import (
 	"github.com/pocketbase/pocketbase/tools/security"
 	"github.com/pocketbase/pocketbase/tools/store"
 	"github.com/pocketbase/pocketbase/tools/subscriptions"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var _ App = (*BaseApp)(nil)
 
+// Built-in api groups that could be disabled with [BaseAppConfig.DisabledApis].
+//
+// The routes of a disabled group are not registered (aka. they respond with 404).
+const (
+	ApiGroupAdminUI  string = "adminUI"
+	ApiGroupLogs     string = "logs"
+	ApiGroupRealtime string = "realtime"
+	ApiGroupFiles    string = "files"
+)
+
 // BaseApp implements core.App and defines the base PocketBase app structure.
 type BaseApp struct {
 	// configurable parameters
 	isDebug       bool
 	dataDir       string
 	encryptionEnv string
+	dbConfig      DBConfig
+	logger        *log.Logger
+	tracer        trace.Tracer
+	disabledApis  map[string]struct{}
+	instanceId    string
+	instance      *instanceParam
+	recordMirrors recordMirrorsCache
+	dbFunctions   map[string]DBFunction
+	spaConfig     *SPAConfig
+	basePath      string
+	mailSender    mailer.SenderFunc
+
+	// defaultSettings is an optional func to modify the
+	// default settings before loading the stored ones
+	defaultSettings func(s *Settings)
 
 	// internals
 	cache               *store.Store[any]
 	settings            *Settings
 	db                  *dbx.DB
+	readDB              *dbx.DB
 	dao                 *daos.Dao
 	logsDB              *dbx.DB
 	logsDao             *daos.Dao
 	subscriptionsBroker *subscriptions.Broker
+	backupMux           sync.Mutex
+	mailProvidersMux    sync.Mutex
+	mailProviders       map[string]*mailer.FailoverProvider
+	queryLogs           queryLogs
+	healthChecksMux     sync.RWMutex
+	healthChecks        map[string]*HealthCheck
+
+	recordFieldsChangeMux           sync.RWMutex
+	recordFieldsChangeSubscriptions []*recordFieldsChangeSubscription
 
 	// serve event hooks
 	onBeforeServe *hook.Hook[*ServeEvent]
@@ -53,6 +93,8 @@ type BaseApp struct {
 	// mailer event hooks
 	onMailerBeforeAdminResetPasswordSend *hook.Hook[*MailerAdminEvent]
 	onMailerAfterAdminResetPasswordSend  *hook.Hook[*MailerAdminEvent]
+	onMailerBeforeAdminMagicLinkSend     *hook.Hook[*MailerAdminEvent]
+	onMailerAfterAdminMagicLinkSend      *hook.Hook[*MailerAdminEvent]
 	onMailerBeforeUserResetPasswordSend  *hook.Hook[*MailerUserEvent]
 	onMailerAfterUserResetPasswordSend   *hook.Hook[*MailerUserEvent]
 	onMailerBeforeUserVerificationSend   *hook.Hook[*MailerUserEvent]
@@ -64,6 +106,7 @@ type BaseApp struct {
 	onRealtimeConnectRequest         *hook.Hook[*RealtimeConnectEvent]
 	onRealtimeBeforeSubscribeRequest *hook.Hook[*RealtimeSubscribeEvent]
 	onRealtimeAfterSubscribeRequest  *hook.Hook[*RealtimeSubscribeEvent]
+	onRealtimeBeforeRecordBroadcast  *hook.Hook[*RealtimeRecordBroadcastEvent]
 
 	// settings api event hooks
 	onSettingsListRequest         *hook.Hook[*SettingsListEvent]
@@ -71,6 +114,7 @@ type BaseApp struct {
 	onSettingsAfterUpdateRequest  *hook.Hook[*SettingsUpdateEvent]
 
 	// file api event hooks
+	onFileBeforeUpload    *hook.Hook[*FileUploadEvent]
 	onFileDownloadRequest *hook.Hook[*FileDownloadEvent]
 
 	// admin api event hooks
@@ -84,6 +128,11 @@ type BaseApp struct {
 	onAdminAfterDeleteRequest  *hook.Hook[*AdminDeleteEvent]
 	onAdminAuthRequest         *hook.Hook[*AdminAuthEvent]
 
+	// auth attempt event hooks
+	onAuthSuccess   *hook.Hook[*AuthAttemptEvent]
+	onAuthFailure   *hook.Hook[*AuthAttemptEvent]
+	onAuthMeRequest *hook.Hook[*AuthMeEvent]
+
 	// user api event hooks
 	onUsersListRequest         *hook.Hook[*UsersListEvent]
 	onUserViewRequest          *hook.Hook[*UserViewEvent]
@@ -98,14 +147,16 @@ type BaseApp struct {
 	onUserAfterOauth2Register  *hook.Hook[*UserOauth2RegisterEvent]
 
 	// record api event hooks
-	onRecordsListRequest        *hook.Hook[*RecordsListEvent]
-	onRecordViewRequest         *hook.Hook[*RecordViewEvent]
-	onRecordBeforeCreateRequest *hook.Hook[*RecordCreateEvent]
-	onRecordAfterCreateRequest  *hook.Hook[*RecordCreateEvent]
-	onRecordBeforeUpdateRequest *hook.Hook[*RecordUpdateEvent]
-	onRecordAfterUpdateRequest  *hook.Hook[*RecordUpdateEvent]
-	onRecordBeforeDeleteRequest *hook.Hook[*RecordDeleteEvent]
-	onRecordAfterDeleteRequest  *hook.Hook[*RecordDeleteEvent]
+	onRecordsListRequest         *hook.Hook[*RecordsListEvent]
+	onRecordViewRequest          *hook.Hook[*RecordViewEvent]
+	onRecordBeforeCreateRequest  *hook.Hook[*RecordCreateEvent]
+	onRecordAfterCreateRequest   *hook.Hook[*RecordCreateEvent]
+	onRecordBeforeUpdateRequest  *hook.Hook[*RecordUpdateEvent]
+	onRecordAfterUpdateRequest   *hook.Hook[*RecordUpdateEvent]
+	onRecordBeforeDeleteRequest  *hook.Hook[*RecordDeleteEvent]
+	onRecordAfterDeleteRequest   *hook.Hook[*RecordDeleteEvent]
+	onRecordBeforePublishRequest *hook.Hook[*RecordPublishEvent]
+	onRecordAfterPublishRequest  *hook.Hook[*RecordPublishEvent]
 
 	// collection api event hooks
 	onCollectionsListRequest        *hook.Hook[*CollectionsListEvent]
@@ -118,17 +169,92 @@ type BaseApp struct {
 	onCollectionAfterDeleteRequest  *hook.Hook[*CollectionDeleteEvent]
 }
 
+// BaseAppConfig defines the BaseApp configuration options.
+//
+// The unset (zero value) fields fallback to their defaults.
+type BaseAppConfig struct {
+	// DataDir is the app data directory path.
+	DataDir string
+
+	// EncryptionEnv is the name of the env variable whose value
+	// (32 chars) is used as the app settings encryption key.
+	EncryptionEnv string
+
+	// IsDebug enables the app debug mode.
+	IsDebug bool
+
+	// DB is the app databases config (default to DefaultDBConfig()).
+	DB *DBConfig
+
+	// DefaultSettings is an optional func to modify the default app settings
+	// (eg. the app name or the smtp config) before loading the stored ones.
+	//
+	// Note that the stored settings have priority over the defaults,
+	// so the func changes are applied only for the not yet persisted settings.
+	DefaultSettings func(s *Settings)
+
+	// Logger is the app logger (default to log.Default()).
+	Logger *log.Logger
+
+	// TracerProvider is an optional OpenTelemetry tracer provider.
+	//
+	// The requests, hooks and db queries tracing is enabled only
+	// if the provider is set.
+	//
+	// Only the db queries executed with the request context are recorded
+	// (eg. the records api queries or a custom route queries executed
+	// with app.Dao().WithContext(c.Request().Context())).
+	TracerProvider trace.TracerProvider
+
+	// DisabledApis is an optional list of built-in api groups
+	// that shouldn't be registered (eg. ApiGroupAdminUI).
+	DisabledApis []string
+
+	// SPA is an optional single page application that is served
+	// from the app root path (the api and admin UI routes take precedence).
+	SPA *SPAConfig
+
+	// InstanceId is an optional app instance identifier used as the
+	// issued tokens "aud" claim (default to a random id generated and
+	// persisted on the first app start).
+	//
+	// The tokens audience could be also shared between multiple
+	// instances with the Meta.TokensAudience setting.
+	InstanceId string
+
+	// BasePath is an optional path prefix (eg. "/pb") under which all
+	// app routes are served, eg. when the app is mounted behind a
+	// gateway together with other services on the same domain.
+	BasePath string
+}
+
 // NewBaseApp creates and returns a new BaseApp instance
 // configured with the provided arguments.
 //
 // To initialize the app, you need to call `app.Bootsrap()`.
 func NewBaseApp(dataDir string, encryptionEnv string, isDebug bool) *BaseApp {
-	return &BaseApp{
-		dataDir:             dataDir,
-		isDebug:             isDebug,
-		encryptionEnv:       encryptionEnv,
+	return NewBaseAppWithConfig(BaseAppConfig{
+		DataDir:       dataDir,
+		EncryptionEnv: encryptionEnv,
+		IsDebug:       isDebug,
+	})
+}
+
+// NewBaseAppWithConfig creates and returns a new BaseApp instance
+// configured with the provided config.
+//
+// To initialize the app, you need to call `app.Bootsrap()`.
+func NewBaseAppWithConfig(config BaseAppConfig) *BaseApp {
+	app := &BaseApp{
+		dataDir:             config.DataDir,
+		isDebug:             config.IsDebug,
+		encryptionEnv:       config.EncryptionEnv,
+		dbConfig:            DefaultDBConfig(),
+		dbFunctions:         map[string]DBFunction{},
+		logger:              config.Logger,
+		defaultSettings:     config.DefaultSettings,
+		tracer:              newAppTracer(config.TracerProvider),
 		cache:               store.New[any](nil),
-		settings:            NewSettings(),
 		subscriptionsBroker: subscriptions.NewBroker(),
 
 		// serve event hooks
@@ -145,6 +271,8 @@ func NewBaseApp(dataDir string, encryptionEnv string, isDebug bool) *BaseApp {
 		// mailer event hooks
 		onMailerBeforeAdminResetPasswordSend: &hook.Hook[*MailerAdminEvent]{},
 		onMailerAfterAdminResetPasswordSend:  &hook.Hook[*MailerAdminEvent]{},
+		onMailerBeforeAdminMagicLinkSend:     &hook.Hook[*MailerAdminEvent]{},
+		onMailerAfterAdminMagicLinkSend:      &hook.Hook[*MailerAdminEvent]{},
 		onMailerBeforeUserResetPasswordSend:  &hook.Hook[*MailerUserEvent]{},
 		onMailerAfterUserResetPasswordSend:   &hook.Hook[*MailerUserEvent]{},
 		onMailerBeforeUserVerificationSend:   &hook.Hook[*MailerUserEvent]{},
@@ -156,6 +284,7 @@ func NewBaseApp(dataDir string, encryptionEnv string, isDebug bool) *BaseApp {
 		onRealtimeConnectRequest:         &hook.Hook[*RealtimeConnectEvent]{},
 		onRealtimeBeforeSubscribeRequest: &hook.Hook[*RealtimeSubscribeEvent]{},
 		onRealtimeAfterSubscribeRequest:  &hook.Hook[*RealtimeSubscribeEvent]{},
+		onRealtimeBeforeRecordBroadcast:  &hook.Hook[*RealtimeRecordBroadcastEvent]{},
 
 		// settings API event hooks
 		onSettingsListRequest:         &hook.Hook[*SettingsListEvent]{},
@@ -163,6 +292,7 @@ func NewBaseApp(dataDir string, encryptionEnv string, isDebug bool) *BaseApp {
 		onSettingsAfterUpdateRequest:  &hook.Hook[*SettingsUpdateEvent]{},
 
 		// file API event hooks
+		onFileBeforeUpload:    &hook.Hook[*FileUploadEvent]{},
 		onFileDownloadRequest: &hook.Hook[*FileDownloadEvent]{},
 
 		// admin API event hooks
@@ -176,6 +306,11 @@ func NewBaseApp(dataDir string, encryptionEnv string, isDebug bool) *BaseApp {
 		onAdminAfterDeleteRequest:  &hook.Hook[*AdminDeleteEvent]{},
 		onAdminAuthRequest:         &hook.Hook[*AdminAuthEvent]{},
 
+		// auth attempt event hooks
+		onAuthSuccess:   &hook.Hook[*AuthAttemptEvent]{},
+		onAuthFailure:   &hook.Hook[*AuthAttemptEvent]{},
+		onAuthMeRequest: &hook.Hook[*AuthMeEvent]{},
+
 		// user API event hooks
 		onUsersListRequest:         &hook.Hook[*UsersListEvent]{},
 		onUserViewRequest:          &hook.Hook[*UserViewEvent]{},
@@ -190,14 +325,16 @@ func NewBaseApp(dataDir string, encryptionEnv string, isDebug bool) *BaseApp {
 		onUserAfterOauth2Register:  &hook.Hook[*UserOauth2RegisterEvent]{},
 
 		// record API event hooks
-		onRecordsListRequest:        &hook.Hook[*RecordsListEvent]{},
-		onRecordViewRequest:         &hook.Hook[*RecordViewEvent]{},
-		onRecordBeforeCreateRequest: &hook.Hook[*RecordCreateEvent]{},
-		onRecordAfterCreateRequest:  &hook.Hook[*RecordCreateEvent]{},
-		onRecordBeforeUpdateRequest: &hook.Hook[*RecordUpdateEvent]{},
-		onRecordAfterUpdateRequest:  &hook.Hook[*RecordUpdateEvent]{},
-		onRecordBeforeDeleteRequest: &hook.Hook[*RecordDeleteEvent]{},
-		onRecordAfterDeleteRequest:  &hook.Hook[*RecordDeleteEvent]{},
+		onRecordsListRequest:         &hook.Hook[*RecordsListEvent]{},
+		onRecordViewRequest:          &hook.Hook[*RecordViewEvent]{},
+		onRecordBeforeCreateRequest:  &hook.Hook[*RecordCreateEvent]{},
+		onRecordAfterCreateRequest:   &hook.Hook[*RecordCreateEvent]{},
+		onRecordBeforeUpdateRequest:  &hook.Hook[*RecordUpdateEvent]{},
+		onRecordAfterUpdateRequest:   &hook.Hook[*RecordUpdateEvent]{},
+		onRecordBeforeDeleteRequest:  &hook.Hook[*RecordDeleteEvent]{},
+		onRecordAfterDeleteRequest:   &hook.Hook[*RecordDeleteEvent]{},
+		onRecordBeforePublishRequest: &hook.Hook[*RecordPublishEvent]{},
+		onRecordAfterPublishRequest:  &hook.Hook[*RecordPublishEvent]{},
 
 		// collection API event hooks
 		onCollectionsListRequest:        &hook.Hook[*CollectionsListEvent]{},
@@ -209,6 +346,33 @@ func NewBaseApp(dataDir string, encryptionEnv string, isDebug bool) *BaseApp {
 		onCollectionBeforeDeleteRequest: &hook.Hook[*CollectionDeleteEvent]{},
 		onCollectionAfterDeleteRequest:  &hook.Hook[*CollectionDeleteEvent]{},
 	}
+
+	if config.DB != nil {
+		app.SetDBConfig(*config.DB)
+	}
+
+	app.SetDisabledApis(config.DisabledApis...)
+
+	if config.SPA != nil {
+		app.SetSPAConfig(*config.SPA)
+	}
+
+	app.SetInstanceId(config.InstanceId)
+
+	app.SetBasePath(config.BasePath)
+
+	if app.logger == nil {
+		app.logger = log.Default()
+	}
+
+	app.settings = app.newDefaultSettings()
+
+	// register the built-in app timezone db functions
+	for name, fn := range app.timezoneDBFunctions() {
+		app.RegisterDBFunction(name, fn)
+	}
+
+	return app
 }
 
 // Bootstrap initializes the application
@@ -219,6 +383,10 @@ func (app *BaseApp) Bootstrap() error {
 		return err
 	}
 
+	if err := app.dbConfig.Validate(); err != nil {
+		return fmt.Errorf("Invalid db config: %w", err)
+	}
+
 	// ensure that data dir exist
 	if err := os.MkdirAll(app.DataDir(), os.ModePerm); err != nil {
 		return err
@@ -248,6 +416,13 @@ func (app *BaseApp) ResetBootstrapState() error {
 		}
 	}
 
+	if app.readDB != nil {
+		if err := app.readDB.Close(); err != nil {
+			return err
+		}
+		app.readDB = nil
+	}
+
 	if app.logsDB != nil {
 		if err := app.logsDB.Close(); err != nil {
 			return err
@@ -257,6 +432,7 @@ func (app *BaseApp) ResetBootstrapState() error {
 	app.dao = nil
 	app.logsDao = nil
 	app.settings = nil
+	app.recordMirrors.reset()
 
 	return nil
 }
@@ -266,6 +442,16 @@ func (app *BaseApp) DB() *dbx.DB {
 	return app.db
 }
 
+// ReadDB returns the app database read pool instance
+// (fallbacks to DB() if DBConfig().SeparateReadPool is not enabled).
+func (app *BaseApp) ReadDB() *dbx.DB {
+	if app.readDB != nil {
+		return app.readDB
+	}
+
+	return app.db
+}
+
 // Dao returns the default app Dao instance.
 func (app *BaseApp) Dao() *daos.Dao {
 	return app.dao
@@ -292,6 +478,112 @@ func (app *BaseApp) EncryptionEnv() string {
 	return app.encryptionEnv
 }
 
+// DBConfig returns the app databases connection pool and pragmas config.
+func (app *BaseApp) DBConfig() DBConfig {
+	return app.dbConfig
+}
+
+// SetDBConfig replaces the app databases config.
+//
+// The config is applied on the next Bootstrap() call.
+func (app *BaseApp) SetDBConfig(config DBConfig) {
+	app.dbConfig = config.normalize()
+}
+
+// Logger returns the app logger.
+func (app *BaseApp) Logger() *log.Logger {
+	return app.logger
+}
+
+// Tracer returns the app OpenTelemetry tracer
+// (nil if the app was created without a TracerProvider).
+func (app *BaseApp) Tracer() trace.Tracer {
+	return app.tracer
+}
+
+// IsApiDisabled reports whether the provided built-in api group is disabled.
+func (app *BaseApp) IsApiDisabled(group string) bool {
+	_, ok := app.disabledApis[group]
+	return ok
+}
+
+// SetDisabledApis replaces the disabled built-in api groups.
+//
+// The change is applied on the next apis.InitApi() call.
+func (app *BaseApp) SetDisabledApis(groups ...string) {
+	app.disabledApis = make(map[string]struct{}, len(groups))
+	for _, group := range groups {
+		app.disabledApis[group] = struct{}{}
+	}
+}
+
+// SPAConfig returns the app single page application config
+// (nil if the app doesn't serve a SPA).
+func (app *BaseApp) SPAConfig() *SPAConfig {
+	return app.spaConfig
+}
+
+// SetSPAConfig replaces the app single page application config.
+//
+// The change is applied on the next apis.InitApi() call.
+func (app *BaseApp) SetSPAConfig(config SPAConfig) {
+	normalized := config.normalize()
+	app.spaConfig = &normalized
+}
+
+// BasePath returns the normalized app routes path prefix
+// (eg. "/pb" or empty string if the app is served from the root path).
+func (app *BaseApp) BasePath() string {
+	return app.basePath
+}
+
+// SetBasePath replaces the app routes path prefix.
+//
+// The change is applied on the next apis.InitApi() call.
+func (app *BaseApp) SetBasePath(basePath string) {
+	basePath = strings.Trim(basePath, "/ ")
+	if basePath != "" {
+		basePath = "/" + basePath
+	}
+
+	app.basePath = basePath
+}
+
+// InstanceId returns the app instance identifier
+// (default to the random persisted install id).
+//
+// Returns an empty string if there is no explicit instance id
+// and the install id is not loaded yet (eg. before the migrations).
+func (app *BaseApp) InstanceId() string {
+	if app.instanceId != "" {
+		return app.instanceId
+	}
+
+	if app.instance != nil {
+		return app.instance.Id
+	}
+
+	return ""
+}
+
+// SetInstanceId replaces the app instance identifier
+// (set to empty string to use the default persisted install id).
+func (app *BaseApp) SetInstanceId(id string) {
+	app.instanceId = id
+}
+
+// TokensAudience returns the "aud" claim of the app issued tokens.
+//
+// Returns the Meta.TokensAudience setting (if set)
+// or fallbacks to the app instance id.
+func (app *BaseApp) TokensAudience() string {
+	if aud := app.Settings().Meta.TokensAudience; aud != "" {
+		return aud
+	}
+
+	return app.InstanceId()
+}
+
 // IsDebug returns whether the app is in debug mode
 // (showing more detailed error logs, executed sql statements, etc.).
 func (app *BaseApp) IsDebug() bool {
@@ -313,20 +605,82 @@ func (app *BaseApp) SubscriptionsBroker() *subscriptions.Broker {
 	return app.subscriptionsBroker
 }
 
+// SetMailSender registers a custom mail sender that receives the
+// composed app emails and takes care of their delivery
+// (eg. via a transactional email provider HTTP API).
+//
+// When set, the SMTP settings are ignored by [BaseApp.NewMailClient].
+// Set to nil to restore the default SMTP/Sendmail delivery.
+func (app *BaseApp) SetMailSender(sender func(message *mailer.Message) error) {
+	app.mailSender = sender
+}
+
 // NewMailClient creates and returns a new SMTP or Sendmail client
 // based on the current app settings.
+//
+// If failover SMTP servers or a rate limit are configured, the returned
+// client tries the servers in order and logs the failed send attempts.
+//
+// If a custom mail sender is registered with [BaseApp.SetMailSender],
+// it is returned instead.
 func (app *BaseApp) NewMailClient() mailer.Mailer {
-	if app.Settings().Smtp.Enabled {
-		return mailer.NewSmtpClient(
-			app.Settings().Smtp.Host,
-			app.Settings().Smtp.Port,
-			app.Settings().Smtp.Username,
-			app.Settings().Smtp.Password,
-			app.Settings().Smtp.Tls,
-		)
+	if app.mailSender != nil {
+		return app.mailSender
+	}
+
+	smtp := app.Settings().Smtp
+
+	if !smtp.Enabled {
+		return &mailer.Sendmail{}
+	}
+
+	if len(smtp.Failover) == 0 && smtp.MaxPerMinute == 0 {
+		return mailer.NewSmtpClient(smtp.Host, smtp.Port, smtp.Username, smtp.Password, smtp.Tls)
 	}
 
-	return &mailer.Sendmail{}
+	client := mailer.NewFailoverClient(app.smtpProviders(smtp.Servers())...)
+	client.OnSend = func(provider string, attempt int, err error) {
+		if err != nil {
+			app.Logger().Printf("Failed to send email via %s (attempt %d): %v", provider, attempt, err)
+		} else if attempt > 1 || app.IsDebug() {
+			app.Logger().Printf("Email sent via %s (attempt %d)", provider, attempt)
+		}
+	}
+
+	return client
+}
+
+// smtpProviders returns the mail providers of the provided SMTP servers.
+//
+// The providers are reused between the mail clients so that their
+// rate limit and circuit breaker state is preserved.
+func (app *BaseApp) smtpProviders(servers []SmtpServerConfig) []*mailer.FailoverProvider {
+	app.mailProvidersMux.Lock()
+	defer app.mailProvidersMux.Unlock()
+
+	current := make(map[string]*mailer.FailoverProvider, len(servers))
+	result := make([]*mailer.FailoverProvider, 0, len(servers))
+
+	for _, server := range servers {
+		key := fmt.Sprintf("%s|%d|%s|%s|%v|%d", server.Host, server.Port, server.Username, server.Password, server.Tls, server.MaxPerMinute)
+
+		provider := app.mailProviders[key]
+		if provider == nil {
+			provider = mailer.NewFailoverProvider(
+				fmt.Sprintf("%s:%d", server.Host, server.Port),
+				mailer.NewSmtpClient(server.Host, server.Port, server.Username, server.Password, server.Tls),
+				server.MaxPerMinute,
+			)
+		}
+
+		current[key] = provider
+		result = append(result, provider)
+	}
+
+	// drop the providers of the no longer configured servers
+	app.mailProviders = current
+
+	return result
 }
 
 // NewFilesystem creates a new local or S3 filesystem instance
@@ -352,7 +706,7 @@ func (app *BaseApp) NewFilesystem() (*filesystem.System, error) {
 // RefreshSettings reinitializes and reloads the stored application settings.
 func (app *BaseApp) RefreshSettings() error {
 	if app.settings == nil {
-		app.settings = NewSettings()
+		app.settings = app.newDefaultSettings()
 	}
 
 	encryptionKey := os.Getenv(app.EncryptionEnv())
@@ -362,14 +716,20 @@ func (app *BaseApp) RefreshSettings() error {
 		return err
 	}
 
-	if param == nil {
-		// no settings were previously stored
+	// no settings were previously stored, aka. a new install
+	isNewInstall := param == nil
+
+	if err := app.refreshInstance(isNewInstall); err != nil {
+		return err
+	}
+
+	if isNewInstall {
 		return app.Dao().SaveParam(models.ParamAppSettings, app.settings, encryptionKey)
 	}
 
 	// load the settings from the stored param into the app ones
 	// ---
-	newSettings := NewSettings()
+	newSettings := app.newDefaultSettings()
 
 	// try first without decryption
 	plainDecodeErr := json.Unmarshal(param.Value, newSettings)
@@ -457,6 +817,14 @@ func (app *BaseApp) OnMailerAfterAdminResetPasswordSend() *hook.Hook[*MailerAdmi
 	return app.onMailerAfterAdminResetPasswordSend
 }
 
+func (app *BaseApp) OnMailerBeforeAdminMagicLinkSend() *hook.Hook[*MailerAdminEvent] {
+	return app.onMailerBeforeAdminMagicLinkSend
+}
+
+func (app *BaseApp) OnMailerAfterAdminMagicLinkSend() *hook.Hook[*MailerAdminEvent] {
+	return app.onMailerAfterAdminMagicLinkSend
+}
+
 func (app *BaseApp) OnMailerBeforeUserResetPasswordSend() *hook.Hook[*MailerUserEvent] {
 	return app.onMailerBeforeUserResetPasswordSend
 }
@@ -497,6 +865,10 @@ func (app *BaseApp) OnRealtimeAfterSubscribeRequest() *hook.Hook[*RealtimeSubscr
 	return app.onRealtimeAfterSubscribeRequest
 }
 
+func (app *BaseApp) OnRealtimeBeforeRecordBroadcast() *hook.Hook[*RealtimeRecordBroadcastEvent] {
+	return app.onRealtimeBeforeRecordBroadcast
+}
+
 // -------------------------------------------------------------------
 // Settings API event hooks
 // -------------------------------------------------------------------
@@ -517,6 +889,10 @@ func (app *BaseApp) OnSettingsAfterUpdateRequest() *hook.Hook[*SettingsUpdateEve
 // File API event hooks
 // -------------------------------------------------------------------
 
+func (app *BaseApp) OnFileBeforeUpload() *hook.Hook[*FileUploadEvent] {
+	return app.onFileBeforeUpload
+}
+
 func (app *BaseApp) OnFileDownloadRequest() *hook.Hook[*FileDownloadEvent] {
 	return app.onFileDownloadRequest
 }
@@ -561,6 +937,22 @@ func (app *BaseApp) OnAdminAuthRequest() *hook.Hook[*AdminAuthEvent] {
 	return app.onAdminAuthRequest
 }
 
+// -------------------------------------------------------------------
+// Auth attempt event hooks
+// -------------------------------------------------------------------
+
+func (app *BaseApp) OnAuthSuccess() *hook.Hook[*AuthAttemptEvent] {
+	return app.onAuthSuccess
+}
+
+func (app *BaseApp) OnAuthFailure() *hook.Hook[*AuthAttemptEvent] {
+	return app.onAuthFailure
+}
+
+func (app *BaseApp) OnAuthMeRequest() *hook.Hook[*AuthMeEvent] {
+	return app.onAuthMeRequest
+}
+
 // -------------------------------------------------------------------
 // User API event hooks
 // -------------------------------------------------------------------
@@ -613,36 +1005,44 @@ func (app *BaseApp) OnUserAfterOauth2Register() *hook.Hook[*UserOauth2RegisterEv
 // Record API event hooks
 // -------------------------------------------------------------------
 
-func (app *BaseApp) OnRecordsListRequest() *hook.Hook[*RecordsListEvent] {
-	return app.onRecordsListRequest
+func (app *BaseApp) OnRecordsListRequest(tags ...string) *hook.TaggedHook[*RecordsListEvent] {
+	return hook.NewTaggedHook(app.onRecordsListRequest, tags...)
+}
+
+func (app *BaseApp) OnRecordViewRequest(tags ...string) *hook.TaggedHook[*RecordViewEvent] {
+	return hook.NewTaggedHook(app.onRecordViewRequest, tags...)
+}
+
+func (app *BaseApp) OnRecordBeforeCreateRequest(tags ...string) *hook.TaggedHook[*RecordCreateEvent] {
+	return hook.NewTaggedHook(app.onRecordBeforeCreateRequest, tags...)
 }
 
-func (app *BaseApp) OnRecordViewRequest() *hook.Hook[*RecordViewEvent] {
-	return app.onRecordViewRequest
+func (app *BaseApp) OnRecordAfterCreateRequest(tags ...string) *hook.TaggedHook[*RecordCreateEvent] {
+	return hook.NewTaggedHook(app.onRecordAfterCreateRequest, tags...)
 }
 
-func (app *BaseApp) OnRecordBeforeCreateRequest() *hook.Hook[*RecordCreateEvent] {
-	return app.onRecordBeforeCreateRequest
+func (app *BaseApp) OnRecordBeforeUpdateRequest(tags ...string) *hook.TaggedHook[*RecordUpdateEvent] {
+	return hook.NewTaggedHook(app.onRecordBeforeUpdateRequest, tags...)
 }
 
-func (app *BaseApp) OnRecordAfterCreateRequest() *hook.Hook[*RecordCreateEvent] {
-	return app.onRecordAfterCreateRequest
+func (app *BaseApp) OnRecordAfterUpdateRequest(tags ...string) *hook.TaggedHook[*RecordUpdateEvent] {
+	return hook.NewTaggedHook(app.onRecordAfterUpdateRequest, tags...)
 }
 
-func (app *BaseApp) OnRecordBeforeUpdateRequest() *hook.Hook[*RecordUpdateEvent] {
-	return app.onRecordBeforeUpdateRequest
+func (app *BaseApp) OnRecordBeforeDeleteRequest(tags ...string) *hook.TaggedHook[*RecordDeleteEvent] {
+	return hook.NewTaggedHook(app.onRecordBeforeDeleteRequest, tags...)
 }
 
-func (app *BaseApp) OnRecordAfterUpdateRequest() *hook.Hook[*RecordUpdateEvent] {
-	return app.onRecordAfterUpdateRequest
+func (app *BaseApp) OnRecordAfterDeleteRequest(tags ...string) *hook.TaggedHook[*RecordDeleteEvent] {
+	return hook.NewTaggedHook(app.onRecordAfterDeleteRequest, tags...)
 }
 
-func (app *BaseApp) OnRecordBeforeDeleteRequest() *hook.Hook[*RecordDeleteEvent] {
-	return app.onRecordBeforeDeleteRequest
+func (app *BaseApp) OnRecordBeforePublishRequest(tags ...string) *hook.TaggedHook[*RecordPublishEvent] {
+	return hook.NewTaggedHook(app.onRecordBeforePublishRequest, tags...)
 }
 
-func (app *BaseApp) OnRecordAfterDeleteRequest() *hook.Hook[*RecordDeleteEvent] {
-	return app.onRecordAfterDeleteRequest
+func (app *BaseApp) OnRecordAfterPublishRequest(tags ...string) *hook.TaggedHook[*RecordPublishEvent] {
+	return hook.NewTaggedHook(app.onRecordAfterPublishRequest, tags...)
 }
 
 // -------------------------------------------------------------------
@@ -687,57 +1087,102 @@ func (app *BaseApp) OnCollectionAfterDeleteRequest() *hook.Hook[*CollectionDelet
 
 func (app *BaseApp) initLogsDB() error {
 	var connectErr error
-	app.logsDB, connectErr = connectDB(filepath.Join(app.DataDir(), "logs.db"))
+	logsConfig := app.dbConfig
+	logsConfig.SeparateReadPool = false // the logs are rarely read
+	app.logsDB, _, connectErr = connectDBPools(filepath.Join(app.DataDir(), "logs.db"), logsConfig, app.timezoneDBFunctions())
 	if connectErr != nil {
 		return connectErr
 	}
 
-	app.logsDao = app.createDao(app.logsDB)
+	app.logsDao = app.createDao(app.logsDB, nil)
 
 	return nil
 }
 
 func (app *BaseApp) initDataDB() error {
 	var connectErr error
-	app.db, connectErr = connectDB(filepath.Join(app.DataDir(), "data.db"))
+	app.db, app.readDB, connectErr = connectDBPools(filepath.Join(app.DataDir(), "data.db"), app.dbConfig, app.dbFunctions)
 	if connectErr != nil {
 		return connectErr
 	}
 
-	app.db.QueryLogFunc = func(ctx context.Context, t time.Duration, sql string, rows *sql.Rows, err error) {
-		if app.IsDebug() {
-			color.HiBlack("[%.2fms] %v\n", float64(t.Milliseconds()), sql)
+	for _, db := range []*dbx.DB{app.db, app.readDB} {
+		if db == nil {
+			continue
 		}
-	}
 
-	app.db.ExecLogFunc = func(ctx context.Context, t time.Duration, sql string, result sql.Result, err error) {
-		if app.IsDebug() {
-			color.HiBlack("[%.2fms] %v\n", float64(t.Milliseconds()), sql)
+		db.QueryLogFunc = func(ctx context.Context, t time.Duration, sql string, rows *sql.Rows, err error) {
+			if app.IsDebug() {
+				color.HiBlack("[%.2fms] %v\n", float64(t.Milliseconds()), sql)
+				app.logQuery(ctx, t, sql, err)
+			}
+
+			if app.tracer != nil {
+				traceDBQuery(app.tracer, ctx, "db.query", t, sql, err)
+			}
+		}
+
+		db.ExecLogFunc = func(ctx context.Context, t time.Duration, sql string, result sql.Result, err error) {
+			if app.IsDebug() {
+				color.HiBlack("[%.2fms] %v\n", float64(t.Milliseconds()), sql)
+				app.logQuery(ctx, t, sql, err)
+			}
+
+			if app.tracer != nil {
+				traceDBQuery(app.tracer, ctx, "db.exec", t, sql, err)
+			}
 		}
 	}
 
-	app.dao = app.createDao(app.db)
+	app.dao = app.createDao(app.db, app.readDB)
 
 	return nil
 }
 
-func (app *BaseApp) createDao(db dbx.Builder) *daos.Dao {
-	dao := daos.New(db)
+// newDefaultSettings creates a new Settings instance
+// with the app default settings overrides applied.
+func (app *BaseApp) newDefaultSettings() *Settings {
+	settings := NewSettings()
+
+	if app.defaultSettings != nil {
+		app.defaultSettings(settings)
+	}
+
+	return settings
+}
+
+func (app *BaseApp) createDao(db *dbx.DB, readDB *dbx.DB) *daos.Dao {
+	var dao *daos.Dao
+	if readDB != nil {
+		dao = daos.NewWithReadDB(db, readDB)
+	} else {
+		dao = daos.New(db)
+	}
 
 	dao.BeforeCreateFunc = func(eventDao *daos.Dao, m models.Model) error {
+		if err := fillRecordMirrors(eventDao, m); err != nil {
+			return err
+		}
 		return app.OnModelBeforeCreate().Trigger(&ModelEvent{eventDao, m})
 	}
 
 	dao.AfterCreateFunc = func(eventDao *daos.Dao, m models.Model) {
 		app.OnModelAfterCreate().Trigger(&ModelEvent{eventDao, m})
+		app.onCollectionChange(eventDao, m)
 	}
 
 	dao.BeforeUpdateFunc = func(eventDao *daos.Dao, m models.Model) error {
+		if err := fillRecordMirrors(eventDao, m); err != nil {
+			return err
+		}
 		return app.OnModelBeforeUpdate().Trigger(&ModelEvent{eventDao, m})
 	}
 
 	dao.AfterUpdateFunc = func(eventDao *daos.Dao, m models.Model) {
 		app.OnModelAfterUpdate().Trigger(&ModelEvent{eventDao, m})
+		app.triggerRecordFieldsChange(eventDao, m)
+		app.onCollectionChange(eventDao, m)
+		app.syncRecordMirrors(eventDao, dao, m)
 	}
 
 	dao.BeforeDeleteFunc = func(eventDao *daos.Dao, m models.Model) error {
@@ -746,7 +1191,17 @@ func (app *BaseApp) createDao(db dbx.Builder) *daos.Dao {
 
 	dao.AfterDeleteFunc = func(eventDao *daos.Dao, m models.Model) {
 		app.OnModelAfterDelete().Trigger(&ModelEvent{eventDao, m})
+		app.onCollectionChange(eventDao, m)
+	}
+
+	dao.TokenAudienceFunc = app.TokensAudience
+	dao.LegacyTokensDeadlineFunc = app.legacyTokensDeadline
+	dao.CreateIdRetries = app.dbConfig.CreateIdRetries
+	dao.Retry = app.dbConfig.retryOptions()
+	dao.TimezoneFunc = func() *time.Location {
+		return app.Settings().Timezone.Location()
 	}
+	dao.RecordMirrorsFunc = app.recordMirrors.find
 
 	return dao
 }
@@ -1,10 +1,18 @@
 package core
 
 import (
+	"io"
+	"log"
+	"net/mail"
 	"os"
 	"testing"
+	"testing/fstest"
+	"time"
 
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/models"
 	"github.com/pocketbase/pocketbase/tools/mailer"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestNewBaseApp(t *testing.T) {
@@ -38,6 +46,111 @@ func TestNewBaseApp(t *testing.T) {
 	}
 }
 
+func TestNewBaseAppWithConfig(t *testing.T) {
+	const testDataDir = "./pb_base_app_test_data_dir/"
+	defer os.RemoveAll(testDataDir)
+
+	logger := log.New(io.Discard, "", 0)
+	dbConfig := DefaultDBConfig()
+	dbConfig.Synchronous = "full"
+
+	app := NewBaseAppWithConfig(BaseAppConfig{
+		DataDir:       testDataDir,
+		EncryptionEnv: "test_env",
+		IsDebug:       true,
+		DB:            &dbConfig,
+		DefaultSettings: func(s *Settings) {
+			s.Meta.AppName = "test_app"
+		},
+		Logger:       logger,
+		DisabledApis: []string{ApiGroupLogs},
+		SPA:          &SPAConfig{FS: fstest.MapFS{}},
+		InstanceId:   "test_instance",
+		BasePath:     " /pb/ ",
+	})
+	defer app.ResetBootstrapState()
+
+	if app.dataDir != testDataDir || app.encryptionEnv != "test_env" || !app.isDebug {
+		t.Fatalf("Unexpected base app options %q, %q, %v", app.dataDir, app.encryptionEnv, app.isDebug)
+	}
+
+	if app.DBConfig().Synchronous != DBSynchronousFull {
+		t.Fatalf("Expected synchronous %q, got %q", DBSynchronousFull, app.DBConfig().Synchronous)
+	}
+
+	if app.Logger() != logger {
+		t.Fatal("Expected the config logger to be set")
+	}
+
+	if app.Tracer() != nil {
+		t.Fatal("Expected nil tracer without TracerProvider")
+	}
+
+	if !app.IsApiDisabled(ApiGroupLogs) || app.IsApiDisabled(ApiGroupFiles) {
+		t.Fatal("Expected only the logs api group to be disabled")
+	}
+
+	if spa := app.SPAConfig(); spa == nil || spa.Index != "index.html" || spa.AssetsMaxAge != time.Hour {
+		t.Fatalf("Expected the SPA config with default options, got %v", spa)
+	}
+
+	if app.InstanceId() != "test_instance" {
+		t.Fatalf("Expected instance id %q, got %q", "test_instance", app.InstanceId())
+	}
+
+	if app.BasePath() != "/pb" {
+		t.Fatalf("Expected base path %q, got %q", "/pb", app.BasePath())
+	}
+
+	tracedApp := NewBaseAppWithConfig(BaseAppConfig{
+		TracerProvider: trace.NewNoopTracerProvider(),
+	})
+	if tracedApp.Tracer() == nil {
+		t.Fatal("Expected non-nil tracer with TracerProvider")
+	}
+
+	if app.Settings().Meta.AppName != "test_app" {
+		t.Fatalf("Expected app name test_app, got %q", app.Settings().Meta.AppName)
+	}
+
+	// the defaults overrides must be preserved after bootstrap
+	if err := app.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+
+	if app.Settings().Meta.AppName != "test_app" {
+		t.Fatalf("Expected app name test_app after bootstrap, got %q", app.Settings().Meta.AppName)
+	}
+
+	// zero config defaults
+	app2 := NewBaseAppWithConfig(BaseAppConfig{})
+
+	if app2.Logger() != log.Default() {
+		t.Fatal("Expected the default logger")
+	}
+
+	if app2.DBConfig() != DefaultDBConfig() {
+		t.Fatalf("Expected the default db config, got %v", app2.DBConfig())
+	}
+
+	if app2.SPAConfig() != nil {
+		t.Fatalf("Expected nil SPA config, got %v", app2.SPAConfig())
+	}
+
+	if app2.IsApiDisabled(ApiGroupLogs) {
+		t.Fatal("Expected no disabled api groups by default")
+	}
+
+	if app2.BasePath() != "" {
+		t.Fatalf("Expected empty base path, got %q", app2.BasePath())
+	}
+
+	app2.SetDisabledApis(ApiGroupAdminUI, ApiGroupRealtime)
+	if !app2.IsApiDisabled(ApiGroupAdminUI) || !app2.IsApiDisabled(ApiGroupRealtime) || app2.IsApiDisabled(ApiGroupLogs) {
+		t.Fatal("Expected the admin ui and realtime api groups to be disabled")
+	}
+}
+
 func TestBaseAppBootstrap(t *testing.T) {
 	const testDataDir = "./pb_base_app_test_data_dir/"
 	defer os.RemoveAll(testDataDir)
@@ -195,6 +308,14 @@ func TestBaseAppGetters(t *testing.T) {
 		t.Fatalf("Getter app.OnMailerAfterAdminResetPasswordSend does not match or nil (%v vs %v)", app.OnMailerAfterAdminResetPasswordSend(), app.onMailerAfterAdminResetPasswordSend)
 	}
 
+	if app.onMailerBeforeAdminMagicLinkSend != app.OnMailerBeforeAdminMagicLinkSend() || app.OnMailerBeforeAdminMagicLinkSend() == nil {
+		t.Fatalf("Getter app.OnMailerBeforeAdminMagicLinkSend does not match or nil (%v vs %v)", app.OnMailerBeforeAdminMagicLinkSend(), app.onMailerBeforeAdminMagicLinkSend)
+	}
+
+	if app.onMailerAfterAdminMagicLinkSend != app.OnMailerAfterAdminMagicLinkSend() || app.OnMailerAfterAdminMagicLinkSend() == nil {
+		t.Fatalf("Getter app.OnMailerAfterAdminMagicLinkSend does not match or nil (%v vs %v)", app.OnMailerAfterAdminMagicLinkSend(), app.onMailerAfterAdminMagicLinkSend)
+	}
+
 	if app.onMailerBeforeUserResetPasswordSend != app.OnMailerBeforeUserResetPasswordSend() || app.OnMailerBeforeUserResetPasswordSend() == nil {
 		t.Fatalf("Getter app.OnMailerBeforeUserResetPasswordSend does not match or nil (%v vs %v)", app.OnMailerBeforeUserResetPasswordSend(), app.onMailerBeforeUserResetPasswordSend)
 	}
@@ -231,6 +352,10 @@ func TestBaseAppGetters(t *testing.T) {
 		t.Fatalf("Getter app.OnRealtimeAfterSubscribeRequest does not match or nil (%v vs %v)", app.OnRealtimeAfterSubscribeRequest(), app.onRealtimeAfterSubscribeRequest)
 	}
 
+	if app.onRealtimeBeforeRecordBroadcast != app.OnRealtimeBeforeRecordBroadcast() || app.OnRealtimeBeforeRecordBroadcast() == nil {
+		t.Fatalf("Getter app.OnRealtimeBeforeRecordBroadcast does not match or nil (%v vs %v)", app.OnRealtimeBeforeRecordBroadcast(), app.onRealtimeBeforeRecordBroadcast)
+	}
+
 	if app.onSettingsListRequest != app.OnSettingsListRequest() || app.OnSettingsListRequest() == nil {
 		t.Fatalf("Getter app.OnSettingsListRequest does not match or nil (%v vs %v)", app.OnSettingsListRequest(), app.onSettingsListRequest)
 	}
@@ -243,6 +368,10 @@ func TestBaseAppGetters(t *testing.T) {
 		t.Fatalf("Getter app.OnSettingsAfterUpdateRequest does not match or nil (%v vs %v)", app.OnSettingsAfterUpdateRequest(), app.onSettingsAfterUpdateRequest)
 	}
 
+	if app.onFileBeforeUpload != app.OnFileBeforeUpload() || app.OnFileBeforeUpload() == nil {
+		t.Fatalf("Getter app.OnFileBeforeUpload does not match or nil (%v vs %v)", app.OnFileBeforeUpload(), app.onFileBeforeUpload)
+	}
+
 	if app.onFileDownloadRequest != app.OnFileDownloadRequest() || app.OnFileDownloadRequest() == nil {
 		t.Fatalf("Getter app.OnFileDownloadRequest does not match or nil (%v vs %v)", app.OnFileDownloadRequest(), app.onFileDownloadRequest)
 	}
@@ -315,6 +444,18 @@ func TestBaseAppGetters(t *testing.T) {
 		t.Fatalf("Getter app.OnUserAfterDeleteRequest does not match or nil (%v vs %v)", app.OnUserAfterDeleteRequest(), app.onUserAfterDeleteRequest)
 	}
 
+	if app.onAuthSuccess != app.OnAuthSuccess() || app.OnAuthSuccess() == nil {
+		t.Fatalf("Getter app.OnAuthSuccess does not match or nil (%v vs %v)", app.OnAuthSuccess(), app.onAuthSuccess)
+	}
+
+	if app.onAuthFailure != app.OnAuthFailure() || app.OnAuthFailure() == nil {
+		t.Fatalf("Getter app.OnAuthFailure does not match or nil (%v vs %v)", app.OnAuthFailure(), app.onAuthFailure)
+	}
+
+	if app.onAuthMeRequest != app.OnAuthMeRequest() || app.OnAuthMeRequest() == nil {
+		t.Fatalf("Getter app.OnAuthMeRequest does not match or nil (%v vs %v)", app.OnAuthMeRequest(), app.onAuthMeRequest)
+	}
+
 	if app.onUserAuthRequest != app.OnUserAuthRequest() || app.OnUserAuthRequest() == nil {
 		t.Fatalf("Getter app.OnUserAuthRequest does not match or nil (%v vs %v)", app.OnUserAuthRequest(), app.onUserAuthRequest)
 	}
@@ -327,38 +468,46 @@ func TestBaseAppGetters(t *testing.T) {
 		t.Fatalf("Getter app.OnUserAfterOauth2Register does not match or nil (%v vs %v)", app.OnUserAfterOauth2Register(), app.onUserAfterOauth2Register)
 	}
 
-	if app.onRecordsListRequest != app.OnRecordsListRequest() || app.OnRecordsListRequest() == nil {
+	if app.onRecordsListRequest != app.OnRecordsListRequest().Hook || app.OnRecordsListRequest() == nil {
 		t.Fatalf("Getter app.OnRecordsListRequest does not match or nil (%v vs %v)", app.OnRecordsListRequest(), app.onRecordsListRequest)
 	}
 
-	if app.onRecordViewRequest != app.OnRecordViewRequest() || app.OnRecordViewRequest() == nil {
+	if app.onRecordViewRequest != app.OnRecordViewRequest().Hook || app.OnRecordViewRequest() == nil {
 		t.Fatalf("Getter app.OnRecordViewRequest does not match or nil (%v vs %v)", app.OnRecordViewRequest(), app.onRecordViewRequest)
 	}
 
-	if app.onRecordBeforeCreateRequest != app.OnRecordBeforeCreateRequest() || app.OnRecordBeforeCreateRequest() == nil {
+	if app.onRecordBeforeCreateRequest != app.OnRecordBeforeCreateRequest().Hook || app.OnRecordBeforeCreateRequest() == nil {
 		t.Fatalf("Getter app.OnRecordBeforeCreateRequest does not match or nil (%v vs %v)", app.OnRecordBeforeCreateRequest(), app.onRecordBeforeCreateRequest)
 	}
 
-	if app.onRecordAfterCreateRequest != app.OnRecordAfterCreateRequest() || app.OnRecordAfterCreateRequest() == nil {
+	if app.onRecordAfterCreateRequest != app.OnRecordAfterCreateRequest().Hook || app.OnRecordAfterCreateRequest() == nil {
 		t.Fatalf("Getter app.OnRecordAfterCreateRequest does not match or nil (%v vs %v)", app.OnRecordAfterCreateRequest(), app.onRecordAfterCreateRequest)
 	}
 
-	if app.onRecordBeforeUpdateRequest != app.OnRecordBeforeUpdateRequest() || app.OnRecordBeforeUpdateRequest() == nil {
+	if app.onRecordBeforeUpdateRequest != app.OnRecordBeforeUpdateRequest().Hook || app.OnRecordBeforeUpdateRequest() == nil {
 		t.Fatalf("Getter app.OnRecordBeforeUpdateRequest does not match or nil (%v vs %v)", app.OnRecordBeforeUpdateRequest(), app.onRecordBeforeUpdateRequest)
 	}
 
-	if app.onRecordAfterUpdateRequest != app.OnRecordAfterUpdateRequest() || app.OnRecordAfterUpdateRequest() == nil {
+	if app.onRecordAfterUpdateRequest != app.OnRecordAfterUpdateRequest().Hook || app.OnRecordAfterUpdateRequest() == nil {
 		t.Fatalf("Getter app.OnRecordAfterUpdateRequest does not match or nil (%v vs %v)", app.OnRecordAfterUpdateRequest(), app.onRecordAfterUpdateRequest)
 	}
 
-	if app.onRecordBeforeDeleteRequest != app.OnRecordBeforeDeleteRequest() || app.OnRecordBeforeDeleteRequest() == nil {
+	if app.onRecordBeforeDeleteRequest != app.OnRecordBeforeDeleteRequest().Hook || app.OnRecordBeforeDeleteRequest() == nil {
 		t.Fatalf("Getter app.OnRecordBeforeDeleteRequest does not match or nil (%v vs %v)", app.OnRecordBeforeDeleteRequest(), app.onRecordBeforeDeleteRequest)
 	}
 
-	if app.onRecordAfterDeleteRequest != app.OnRecordAfterDeleteRequest() || app.OnRecordAfterDeleteRequest() == nil {
+	if app.onRecordAfterDeleteRequest != app.OnRecordAfterDeleteRequest().Hook || app.OnRecordAfterDeleteRequest() == nil {
 		t.Fatalf("Getter app.OnRecordAfterDeleteRequest does not match or nil (%v vs %v)", app.OnRecordAfterDeleteRequest(), app.onRecordAfterDeleteRequest)
 	}
 
+	if app.onRecordBeforePublishRequest != app.OnRecordBeforePublishRequest().Hook || app.OnRecordBeforePublishRequest() == nil {
+		t.Fatalf("Getter app.OnRecordBeforePublishRequest does not match or nil (%v vs %v)", app.OnRecordBeforePublishRequest(), app.onRecordBeforePublishRequest)
+	}
+
+	if app.onRecordAfterPublishRequest != app.OnRecordAfterPublishRequest().Hook || app.OnRecordAfterPublishRequest() == nil {
+		t.Fatalf("Getter app.OnRecordAfterPublishRequest does not match or nil (%v vs %v)", app.OnRecordAfterPublishRequest(), app.onRecordAfterPublishRequest)
+	}
+
 	if app.onCollectionsListRequest != app.OnCollectionsListRequest() || app.OnCollectionsListRequest() == nil {
 		t.Fatalf("Getter app.OnCollectionsListRequest does not match or nil (%v vs %v)", app.OnCollectionsListRequest(), app.onCollectionsListRequest)
 	}
@@ -409,6 +558,69 @@ func TestBaseAppNewMailClient(t *testing.T) {
 	if val, ok := client2.(*mailer.SmtpClient); !ok {
 		t.Fatalf("Expected mailer.SmtpClient instance, got %v", val)
 	}
+
+	app.Settings().Smtp.Failover = []SmtpServerConfig{{Host: "example.com", Port: 25}}
+
+	client3 := app.NewMailClient()
+	if val, ok := client3.(*mailer.FailoverClient); !ok {
+		t.Fatalf("Expected mailer.FailoverClient instance, got %v", val)
+	}
+
+	// the providers state should be preserved between the clients
+	providers1 := app.smtpProviders(app.Settings().Smtp.Servers())
+	providers2 := app.smtpProviders(app.Settings().Smtp.Servers())
+	if len(providers1) != 2 || len(providers2) != 2 {
+		t.Fatalf("Expected 2 providers, got %d and %d", len(providers1), len(providers2))
+	}
+	for i := range providers1 {
+		if providers1[i] != providers2[i] {
+			t.Fatalf("(%d) Expected the same provider instance", i)
+		}
+	}
+	if name := providers1[1].Name(); name != "example.com:25" {
+		t.Fatalf("Expected the failover provider name to be %q, got %q", "example.com:25", name)
+	}
+}
+
+func TestBaseAppSetMailSender(t *testing.T) {
+	const testDataDir = "./pb_base_app_test_data_dir/"
+	defer os.RemoveAll(testDataDir)
+
+	app := NewBaseApp(testDataDir, "pb_test_env", false)
+	app.Settings().Smtp.Enabled = true
+
+	var sent []*mailer.Message
+	app.SetMailSender(func(message *mailer.Message) error {
+		sent = append(sent, message)
+		return nil
+	})
+
+	client := app.NewMailClient()
+	if _, ok := client.(mailer.SenderFunc); !ok {
+		t.Fatalf("Expected mailer.SenderFunc instance, got %v", client)
+	}
+
+	err := client.Send(
+		mail.Address{Address: "from@example.com"},
+		mail.Address{Address: "to@example.com"},
+		"test",
+		"<p>test</p>",
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sent) != 1 || sent[0].To.Address != "to@example.com" || sent[0].Subject != "test" {
+		t.Fatalf("Expected the message to be delivered by the custom sender, got %v", sent)
+	}
+
+	// restore the default sender
+	app.SetMailSender(nil)
+
+	if val, ok := app.NewMailClient().(*mailer.SmtpClient); !ok {
+		t.Fatalf("Expected mailer.SmtpClient instance, got %v", val)
+	}
 }
 
 func TestBaseAppNewFilesystem(t *testing.T) {
@@ -436,3 +648,168 @@ func TestBaseAppNewFilesystem(t *testing.T) {
 		t.Fatalf("Expected nil s3 filesystem, got %v", s3)
 	}
 }
+
+func TestBaseAppTokensAudience(t *testing.T) {
+	app1 := NewBaseApp("./pb_base_app_test_data_dir_1/", "pb_test_env", false)
+	app2 := NewBaseApp("./pb_base_app_test_data_dir_2/", "pb_test_env", false)
+
+	// not loaded install id
+	if app1.InstanceId() != "" {
+		t.Fatalf("Expected empty instance id before the install id is loaded, got %q", app1.InstanceId())
+	}
+
+	// default install id
+	app1.instance = &instanceParam{Id: "install1"}
+	app2.instance = &instanceParam{Id: "install2"}
+	if app1.InstanceId() != "install1" || app2.InstanceId() != "install2" {
+		t.Fatalf("Expected the install ids, got %q and %q", app1.InstanceId(), app2.InstanceId())
+	}
+
+	if app1.TokensAudience() != app1.InstanceId() {
+		t.Fatalf("Expected the tokens audience to default to the instance id, got %q", app1.TokensAudience())
+	}
+
+	// shared audience
+	app1.Settings().Meta.TokensAudience = "shared"
+	app2.Settings().Meta.TokensAudience = "shared"
+	if app1.TokensAudience() != "shared" || app2.TokensAudience() != "shared" {
+		t.Fatalf("Expected the shared tokens audience, got %q and %q", app1.TokensAudience(), app2.TokensAudience())
+	}
+
+	// explicit instance id
+	app1.Settings().Meta.TokensAudience = ""
+	app1.SetInstanceId("test")
+	if app1.TokensAudience() != "test" {
+		t.Fatalf("Expected tokens audience %q, got %q", "test", app1.TokensAudience())
+	}
+}
+
+func TestBaseAppRefreshInstance(t *testing.T) {
+	testDataDir, err := os.MkdirTemp("", "pb_instance_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(testDataDir)
+
+	newApp := func(withParamsTable bool) *BaseApp {
+		app := NewBaseApp(testDataDir, "pb_test_env", false)
+		if err := app.Bootstrap(); err != nil {
+			t.Fatal(err)
+		}
+
+		if withParamsTable {
+			_, err := app.DB().NewQuery(`
+				CREATE TABLE {{_params}} (
+					[[id]]      TEXT PRIMARY KEY,
+					[[key]]     TEXT UNIQUE NOT NULL,
+					[[value]]   JSON DEFAULT NULL,
+					[[created]] TEXT DEFAULT "" NOT NULL,
+					[[updated]] TEXT DEFAULT "" NOT NULL
+				);
+			`).Execute()
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if err := app.RefreshSettings(); err != nil {
+			t.Fatal(err)
+		}
+
+		return app
+	}
+
+	// new install
+	// ---
+	app1 := newApp(true)
+	defer app1.ResetBootstrapState()
+
+	id := app1.InstanceId()
+	if len(id) != 20 {
+		t.Fatalf("Expected random 20 chars instance id, got %q", id)
+	}
+
+	if app1.TokensAudience() != id {
+		t.Fatalf("Expected the tokens audience to default to the instance id, got %q", app1.TokensAudience())
+	}
+
+	if deadline := app1.legacyTokensDeadline(); deadline.IsZero() || deadline.After(time.Now()) {
+		t.Fatalf("Expected the legacy tokens to be rejected for a new install, got deadline %v", deadline)
+	}
+
+	// the install id is persisted
+	// ---
+	app2 := newApp(false)
+	defer app2.ResetBootstrapState()
+
+	if app2.InstanceId() != id {
+		t.Fatalf("Expected the persisted instance id %q, got %q", id, app2.InstanceId())
+	}
+
+	// existing install without install id
+	// ---
+	if _, err := app2.DB().Delete("_params", dbx.HashExp{"key": models.ParamAppInstance}).Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	app3 := newApp(false)
+	defer app3.ResetBootstrapState()
+
+	if app3.InstanceId() == "" || app3.InstanceId() == id {
+		t.Fatalf("Expected a new instance id, got %q", app3.InstanceId())
+	}
+
+	minDeadline := time.Now().Add(LegacyTokensGracePeriod - time.Minute)
+	if deadline := app3.legacyTokensDeadline(); deadline.Before(minDeadline) {
+		t.Fatalf("Expected the legacy tokens grace period for an existing install, got deadline %v", deadline)
+	}
+}
+
+func TestBaseAppRecordRequestHooksTags(t *testing.T) {
+	const testDataDir = "./pb_base_app_test_data_dir/"
+	defer os.RemoveAll(testDataDir)
+
+	app := NewBaseApp(testDataDir, "pb_test_env", false)
+
+	posts := &models.Collection{Name: "posts"}
+	posts.Id = "posts_id"
+	comments := &models.Collection{Name: "comments"}
+	comments.Id = "comments_id"
+
+	calls := ""
+	app.OnRecordAfterCreateRequest().Add(func(e *RecordCreateEvent) error { calls += "1"; return nil })
+	app.OnRecordAfterCreateRequest("posts").Add(func(e *RecordCreateEvent) error { calls += "2"; return nil })
+	app.OnRecordAfterCreateRequest("missing", "comments_id").Add(func(e *RecordCreateEvent) error { calls += "3"; return nil })
+	app.OnRecordAfterCreateRequest("*").Add(func(e *RecordCreateEvent) error { calls += "4"; return nil })
+
+	scenarios := []struct {
+		collection *models.Collection
+		expected   string
+	}{
+		{posts, "124"},
+		{comments, "134"},
+		{&models.Collection{Name: "other"}, "14"},
+	}
+
+	for _, s := range scenarios {
+		calls = ""
+
+		event := &RecordCreateEvent{Record: models.NewRecord(s.collection)}
+		if err := app.OnRecordAfterCreateRequest().Trigger(event); err != nil {
+			t.Fatal(err)
+		}
+
+		if calls != s.expected {
+			t.Errorf("[%s] Expected calls %q, got %q", s.collection.Name, s.expected, calls)
+		}
+	}
+
+	// list event tags
+	listCalls := 0
+	app.OnRecordsListRequest("posts").Add(func(e *RecordsListEvent) error { listCalls++; return nil })
+	app.OnRecordsListRequest().Trigger(&RecordsListEvent{Collection: comments})
+	app.OnRecordsListRequest().Trigger(&RecordsListEvent{Collection: posts})
+	if listCalls != 1 {
+		t.Fatalf("Expected 1 list hook call, got %d", listCalls)
+	}
+}
@@ -0,0 +1,242 @@
+package core
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// Common SQLite journal modes.
+const (
+	DBJournalModeWal    string = "WAL"
+	DBJournalModeDelete string = "DELETE"
+)
+
+// Common SQLite synchronous modes.
+const (
+	DBSynchronousOff    string = "OFF"
+	DBSynchronousNormal string = "NORMAL"
+	DBSynchronousFull   string = "FULL"
+)
+
+// DBConfig defines the app databases connection pool and SQLite pragmas options.
+//
+// A single SQLite database allows only one writer at a time (in WAL mode
+// the readers don't block the writer and vice versa). When a connection
+// fails to acquire the write lock within BusyTimeout, the query fails
+// with "database is locked".
+//
+// By default all queries share a single connection pool.
+// With SeparateReadPool enabled, the main pool is restricted to a single
+// connection, so that the writes are queued in Go instead of competing
+// for the SQLite write lock, and the Dao select queries are executed
+// through a separate multi-connection (query only) read pool.
+//
+// Note that with SeparateReadPool enabled, any write made with app.Dao()
+// while another app.Dao().RunInTransaction() call is still running
+// (eg. in a model hook) will block until the transaction completes,
+// so always use the transaction Dao passed to the callback.
+type DBConfig struct {
+	// BusyTimeout is the max duration to wait for a database lock
+	// before failing with "database is locked".
+	BusyTimeout time.Duration
+
+	// JournalMode is the SQLite journal_mode pragma (eg. "WAL").
+	JournalMode string
+
+	// Synchronous is the SQLite synchronous pragma (eg. "NORMAL").
+	Synchronous string
+
+	// MaxOpenConns is the max number of open connections of the
+	// main pool (or of the read pool when SeparateReadPool is enabled).
+	MaxOpenConns int
+
+	// MaxIdleConns is the max number of idle connections of the
+	// main pool (or of the read pool when SeparateReadPool is enabled).
+	MaxIdleConns int
+
+	// ConnMaxIdleTime is the max duration a connection may stay idle
+	// before being closed (0 for no limit).
+	ConnMaxIdleTime time.Duration
+
+	// SeparateReadPool enables the single writer connection
+	// and separate readers pool mode (see the [DBConfig] docs).
+	SeparateReadPool bool
+
+	// CreateIdRetries is the max number of insert retries with a new
+	// auto generated id on primary key collision (see [daos.Dao.CreateIdRetries]).
+	CreateIdRetries int
+
+	// RetryMaxAttempts is the max number of attempts of the Dao operations
+	// failed with a transient "database is locked" error (see [daos.Dao.Retry]).
+	//
+	// Values <= 1 disable the retry.
+	RetryMaxAttempts int
+
+	// RetryMaxTime is the max total duration of all attempts
+	// of a single retried operation (0 for no limit).
+	RetryMaxTime time.Duration
+
+	// RetryBaseDelay is the initial retry backoff delay
+	// (doubled and randomized with jitter on each next retry).
+	RetryBaseDelay time.Duration
+
+	// RetryMaxDelay is the max retry backoff delay (0 for no limit).
+	RetryMaxDelay time.Duration
+}
+
+// DefaultDBConfig returns the default app databases config.
+func DefaultDBConfig() DBConfig {
+	return DBConfig{
+		BusyTimeout:     10 * time.Second,
+		JournalMode:     DBJournalModeWal,
+		Synchronous:     DBSynchronousNormal,
+		MaxOpenConns:    120,
+		MaxIdleConns:    20,
+		ConnMaxIdleTime: 3 * time.Minute,
+		CreateIdRetries: daos.DefaultCreateIdRetries,
+		RetryMaxTime:    5 * time.Second,
+		RetryBaseDelay:  20 * time.Millisecond,
+		RetryMaxDelay:   time.Second,
+	}
+}
+
+// Validate makes DBConfig validatable by implementing [validation.Validatable] interface.
+func (c DBConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.BusyTimeout, validation.Min(time.Duration(0))),
+		validation.Field(
+			&c.JournalMode,
+			validation.Required,
+			validation.In("DELETE", "TRUNCATE", "PERSIST", "MEMORY", "WAL", "OFF"),
+		),
+		validation.Field(
+			&c.Synchronous,
+			validation.Required,
+			validation.In("OFF", "NORMAL", "FULL", "EXTRA"),
+		),
+		validation.Field(&c.MaxOpenConns, validation.Min(0)),
+		validation.Field(&c.MaxIdleConns, validation.Min(0)),
+		validation.Field(&c.ConnMaxIdleTime, validation.Min(time.Duration(0))),
+		validation.Field(&c.CreateIdRetries, validation.Min(0)),
+		validation.Field(&c.RetryMaxAttempts, validation.Min(0)),
+		validation.Field(&c.RetryMaxTime, validation.Min(time.Duration(0))),
+		validation.Field(&c.RetryBaseDelay, validation.Min(time.Duration(0))),
+		validation.Field(&c.RetryMaxDelay, validation.Min(time.Duration(0))),
+	)
+}
+
+// retryOptions returns the Dao retry options from the config.
+func (c DBConfig) retryOptions() daos.RetryOptions {
+	return daos.RetryOptions{
+		MaxAttempts: c.RetryMaxAttempts,
+		MaxTime:     c.RetryMaxTime,
+		BaseDelay:   c.RetryBaseDelay,
+		MaxDelay:    c.RetryMaxDelay,
+	}
+}
+
+// normalize returns a config copy with uppercased pragma values.
+func (c DBConfig) normalize() DBConfig {
+	c.JournalMode = strings.ToUpper(c.JournalMode)
+	c.Synchronous = strings.ToUpper(c.Synchronous)
+
+	return c
+}
+
+// DBFunction defines a custom SQLite scalar function implementation.
+//
+// The args are the raw SQLite values (int64, float64, string, []byte or nil)
+// and the returned value is expected to be one of the same types.
+type DBFunction func(args ...any) (any, error)
+
+var dbFunctionNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// RegisterDBFunction registers a custom SQLite scalar function
+// with the provided name on the app data database connections.
+//
+// The function is also allowed to be called in the collection rules
+// and the records search filters (eg. `myFunc(title, 'abc') = true`).
+//
+// Note that the function is applied only on the new connections,
+// so it must be registered before the app Bootstrap() call.
+// Because of the pure Go SQLite driver limitations, in non-cgo
+// builds the function names are shared between all app instances.
+func (app *BaseApp) RegisterDBFunction(name string, fn DBFunction) error {
+	if !dbFunctionNameRegex.MatchString(name) {
+		return errors.New("Invalid db function name " + name + ".")
+	}
+
+	if fn == nil {
+		return errors.New("Missing db function implementation.")
+	}
+
+	app.dbFunctions[name] = fn
+
+	search.AllowFilterFunction(name)
+
+	return nil
+}
+
+// dbPragmas defines the SQLite pragmas applied on every new connection.
+type dbPragmas struct {
+	busyTimeout int64 // in ms
+	journalMode string
+	synchronous string
+	queryOnly   bool
+}
+
+// connectDBPool opens a new db connection pool with the provided
+// pragmas, custom functions and pool limits.
+func connectDBPool(dbPath string, pragmas dbPragmas, functions map[string]DBFunction, maxOpen int, maxIdle int, maxIdleTime time.Duration) (*dbx.DB, error) {
+	db, err := connectDB(dbPath, pragmas, functions)
+	if err != nil {
+		return nil, err
+	}
+
+	db.DB().SetMaxOpenConns(maxOpen)
+	db.DB().SetMaxIdleConns(maxIdle)
+	db.DB().SetConnMaxIdleTime(maxIdleTime)
+
+	return db, nil
+}
+
+// connectDBPools opens the main db pool and, if SeparateReadPool is
+// enabled, the read pool (otherwise the returned readDB is nil).
+func connectDBPools(dbPath string, config DBConfig, functions map[string]DBFunction) (db *dbx.DB, readDB *dbx.DB, err error) {
+	pragmas := dbPragmas{
+		busyTimeout: config.BusyTimeout.Milliseconds(),
+		journalMode: config.JournalMode,
+		synchronous: config.Synchronous,
+	}
+
+	if !config.SeparateReadPool {
+		db, err = connectDBPool(dbPath, pragmas, functions, config.MaxOpenConns, config.MaxIdleConns, config.ConnMaxIdleTime)
+		return db, nil, err
+	}
+
+	// single writer connection
+	db, err = connectDBPool(dbPath, pragmas, functions, 1, 1, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the journal mode is persistent and already set by the writer
+	readPragmas := pragmas
+	readPragmas.journalMode = ""
+	readPragmas.queryOnly = true
+
+	readDB, err = connectDBPool(dbPath, readPragmas, functions, config.MaxOpenConns, config.MaxIdleConns, config.ConnMaxIdleTime)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	return db, readDB, nil
+}
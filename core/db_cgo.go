@@ -3,20 +3,41 @@
 package core
 
 import (
+	"database/sql"
 	"fmt"
+	"strings"
+	"sync/atomic"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 	"github.com/pocketbase/dbx"
 )
 
-func connectDB(dbPath string) (*dbx.DB, error) {
-	pragmas := "_foreign_keys=1&_journal_mode=WAL&_synchronous=NORMAL&_busy_timeout=8000"
+// sqliteDriversCounter is used to generate unique names for the
+// custom functions drivers (sql.Register panics on duplicated names).
+var sqliteDriversCounter int64
 
-	db, openErr := dbx.MustOpen("sqlite3", fmt.Sprintf("%s?%s", dbPath, pragmas))
+func connectDB(dbPath string, pragmas dbPragmas, functions map[string]DBFunction) (*dbx.DB, error) {
+	params := []string{
+		"_foreign_keys=1",
+		fmt.Sprintf("_busy_timeout=%d", pragmas.busyTimeout),
+		"_synchronous=" + pragmas.synchronous,
+	}
+	if pragmas.journalMode != "" {
+		params = append(params, "_journal_mode="+pragmas.journalMode)
+	}
+	if pragmas.queryOnly {
+		params = append(params, "_query_only=1")
+	}
+
+	db, openErr := dbx.MustOpen(sqliteDriverName(functions), fmt.Sprintf("%s?%s", dbPath, strings.Join(params, "&")))
 	if openErr != nil {
 		return nil, openErr
 	}
 
+	if pragmas.queryOnly {
+		return db, nil
+	}
+
 	// additional pragmas not supported through the dsn string
 	_, err := db.NewQuery(`
 		pragma journal_size_limit = 100000000;
@@ -24,3 +45,36 @@ func connectDB(dbPath string) (*dbx.DB, error) {
 
 	return db, err
 }
+
+// sqliteDriverName returns the name of a sqlite3 driver that
+// registers the provided custom functions on each new connection
+// (or the default driver name if there are no custom functions).
+func sqliteDriverName(functions map[string]DBFunction) string {
+	if len(functions) == 0 {
+		return "sqlite3"
+	}
+
+	// copy to prevent the functions change after the driver registration
+	registered := make(map[string]DBFunction, len(functions))
+	for name, fn := range functions {
+		registered[name] = fn
+	}
+
+	name := fmt.Sprintf("pb_sqlite3_%d", atomic.AddInt64(&sqliteDriversCounter, 1))
+
+	sql.Register(name, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			for fnName, fn := range registered {
+				if err := conn.RegisterFunc(fnName, (func(args ...any) (any, error))(fn), true); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+
+	// use the same query builder as the default sqlite3 driver
+	dbx.BuilderFuncMap[name] = dbx.BuilderFuncMap["sqlite3"]
+
+	return name
+}
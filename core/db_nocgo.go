@@ -3,14 +3,78 @@
 package core
 
 import (
+	"database/sql/driver"
 	"fmt"
+	"strings"
+	"sync"
 
 	"github.com/pocketbase/dbx"
-	_ "modernc.org/sqlite"
+	"modernc.org/sqlite"
 )
 
-func connectDB(dbPath string) (*dbx.DB, error) {
-	pragmas := "_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)&_pragma=busy_timeout(8000)&_pragma=journal_size_limit(100000000)"
+var (
+	sqliteFunctionsMux sync.Mutex
 
-	return dbx.MustOpen("sqlite", fmt.Sprintf("%s?%s", dbPath, pragmas))
+	// sqliteFunctions holds the latest implementation of each globally
+	// registered driver function (the driver doesn't allow re-registration).
+	sqliteFunctions = map[string]DBFunction{}
+)
+
+func connectDB(dbPath string, pragmas dbPragmas, functions map[string]DBFunction) (*dbx.DB, error) {
+	if err := registerSqliteFunctions(functions); err != nil {
+		return nil, err
+	}
+
+	params := []string{
+		"_pragma=foreign_keys(1)",
+		fmt.Sprintf("_pragma=busy_timeout(%d)", pragmas.busyTimeout),
+		"_pragma=synchronous(" + pragmas.synchronous + ")",
+	}
+	if pragmas.journalMode != "" {
+		params = append(params, "_pragma=journal_mode("+pragmas.journalMode+")")
+	}
+	if pragmas.queryOnly {
+		params = append(params, "_pragma=query_only(1)")
+	} else {
+		params = append(params, "_pragma=journal_size_limit(100000000)")
+	}
+
+	return dbx.MustOpen("sqlite", fmt.Sprintf("%s?%s", dbPath, strings.Join(params, "&")))
+}
+
+// registerSqliteFunctions registers the provided custom functions
+// in the driver (the already registered names are only updated).
+func registerSqliteFunctions(functions map[string]DBFunction) error {
+	sqliteFunctionsMux.Lock()
+	defer sqliteFunctionsMux.Unlock()
+
+	for name, fn := range functions {
+		_, exists := sqliteFunctions[name]
+
+		sqliteFunctions[name] = fn
+
+		if exists {
+			continue
+		}
+
+		fnName := name
+		err := sqlite.RegisterDeterministicScalarFunction(fnName, -1, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+			sqliteFunctionsMux.Lock()
+			current := sqliteFunctions[fnName]
+			sqliteFunctionsMux.Unlock()
+
+			values := make([]any, len(args))
+			for i, arg := range args {
+				values[i] = arg
+			}
+
+			return current(values...)
+		})
+		if err != nil {
+			delete(sqliteFunctions, name)
+			return err
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,254 @@
+package core
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/spf13/cast"
+)
+
+func TestDefaultDBConfig(t *testing.T) {
+	config := DefaultDBConfig()
+
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Expected the default config to be valid, got %v", err)
+	}
+
+	if config.JournalMode != DBJournalModeWal {
+		t.Fatalf("Expected journal mode %q, got %q", DBJournalModeWal, config.JournalMode)
+	}
+
+	if config.SeparateReadPool {
+		t.Fatal("Expected the separate read pool to be disabled by default")
+	}
+
+	if config.CreateIdRetries != daos.DefaultCreateIdRetries {
+		t.Fatalf("Expected %d create id retries, got %d", daos.DefaultCreateIdRetries, config.CreateIdRetries)
+	}
+
+	if config.RetryMaxAttempts != 0 {
+		t.Fatalf("Expected the transient errors retry to be disabled by default, got %d max attempts", config.RetryMaxAttempts)
+	}
+}
+
+func TestDBConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      DBConfig
+		expectError bool
+	}{
+		// zero value
+		{DBConfig{}, true},
+		// invalid pragmas
+		{
+			DBConfig{JournalMode: "invalid", Synchronous: DBSynchronousNormal},
+			true,
+		},
+		{
+			DBConfig{JournalMode: DBJournalModeWal, Synchronous: "invalid"},
+			true,
+		},
+		// negative pool values
+		{
+			DBConfig{JournalMode: DBJournalModeWal, Synchronous: DBSynchronousNormal, MaxOpenConns: -1},
+			true,
+		},
+		{
+			DBConfig{JournalMode: DBJournalModeWal, Synchronous: DBSynchronousNormal, BusyTimeout: -time.Second},
+			true,
+		},
+		{
+			DBConfig{JournalMode: DBJournalModeWal, Synchronous: DBSynchronousNormal, CreateIdRetries: -1},
+			true,
+		},
+		{
+			DBConfig{JournalMode: DBJournalModeWal, Synchronous: DBSynchronousNormal, RetryMaxAttempts: -1},
+			true,
+		},
+		{
+			DBConfig{JournalMode: DBJournalModeWal, Synchronous: DBSynchronousNormal, RetryMaxTime: -time.Second},
+			true,
+		},
+		{
+			DBConfig{JournalMode: DBJournalModeWal, Synchronous: DBSynchronousNormal, RetryBaseDelay: -time.Second},
+			true,
+		},
+		{
+			DBConfig{JournalMode: DBJournalModeWal, Synchronous: DBSynchronousNormal, RetryMaxDelay: -time.Second},
+			true,
+		},
+		// valid
+		{
+			DBConfig{JournalMode: DBJournalModeDelete, Synchronous: DBSynchronousFull},
+			false,
+		},
+		{
+			DBConfig{JournalMode: DBJournalModeWal, Synchronous: DBSynchronousNormal, RetryMaxAttempts: 5, RetryMaxTime: time.Second},
+			false,
+		},
+	}
+
+	for i, s := range scenarios {
+		result := s.config.Validate()
+
+		if result != nil && !s.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && s.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestBaseAppSetDBConfig(t *testing.T) {
+	app := NewBaseApp("", "", false)
+
+	config := DefaultDBConfig()
+	config.JournalMode = "delete"
+	config.Synchronous = "full"
+
+	app.SetDBConfig(config)
+
+	if v := app.DBConfig().JournalMode; v != DBJournalModeDelete {
+		t.Fatalf("Expected normalized journal mode %q, got %q", DBJournalModeDelete, v)
+	}
+
+	if v := app.DBConfig().Synchronous; v != DBSynchronousFull {
+		t.Fatalf("Expected normalized synchronous %q, got %q", DBSynchronousFull, v)
+	}
+}
+
+func TestBaseAppBootstrapInvalidDBConfig(t *testing.T) {
+	const testDataDir = "./pb_base_app_test_data_dir/"
+	defer os.RemoveAll(testDataDir)
+
+	app := NewBaseApp(testDataDir, "pb_test_env", false)
+	defer app.ResetBootstrapState()
+
+	app.SetDBConfig(DBConfig{})
+
+	if err := app.Bootstrap(); err == nil {
+		t.Fatal("Expected Bootstrap error, got nil")
+	}
+}
+
+func TestBaseAppSeparateReadPool(t *testing.T) {
+	const testDataDir = "./pb_base_app_test_data_dir/"
+	defer os.RemoveAll(testDataDir)
+
+	app := NewBaseApp(testDataDir, "pb_test_env", false)
+	defer app.ResetBootstrapState()
+
+	config := DefaultDBConfig()
+	config.SeparateReadPool = true
+	config.MaxOpenConns = 5
+	app.SetDBConfig(config)
+
+	if err := app.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+
+	if app.ReadDB() == nil || app.ReadDB() == app.DB() {
+		t.Fatal("Expected a separate read pool")
+	}
+
+	if app.Dao().ReadDB() != app.ReadDB() {
+		t.Fatal("Expected the app Dao to use the read pool")
+	}
+
+	if v := app.DB().DB().Stats().MaxOpenConnections; v != 1 {
+		t.Fatalf("Expected a single writer connection, got %d", v)
+	}
+
+	if v := app.ReadDB().DB().Stats().MaxOpenConnections; v != 5 {
+		t.Fatalf("Expected 5 max read connections, got %d", v)
+	}
+
+	if _, err := app.DB().NewQuery("CREATE TABLE test_pool (id TEXT)").Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the read pool must be query only
+	if _, err := app.ReadDB().Insert("test_pool", dbx.Params{"id": "a"}).Execute(); err == nil {
+		t.Fatal("Expected the read pool insert to fail")
+	}
+
+	if _, err := app.DB().Insert("test_pool", dbx.Params{"id": "a"}).Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	var total int
+	if err := app.ReadDB().Select("count(*)").From("test_pool").Row(&total); err != nil || total != 1 {
+		t.Fatalf("Expected 1 row to be read from the read pool, got %d (%v)", total, err)
+	}
+
+	// the journal mode is set by the writer
+	var mode string
+	if err := app.ReadDB().NewQuery("PRAGMA journal_mode").Row(&mode); err != nil || mode != "wal" {
+		t.Fatalf("Expected wal journal mode, got %q (%v)", mode, err)
+	}
+
+	if err := app.ResetBootstrapState(); err != nil {
+		t.Fatal(err)
+	}
+
+	if app.readDB != nil {
+		t.Fatal("Expected the read pool to be released")
+	}
+}
+
+func TestBaseAppRegisterDBFunction(t *testing.T) {
+	const testDataDir = "./pb_base_app_test_data_dir/"
+	defer os.RemoveAll(testDataDir)
+
+	app := NewBaseApp(testDataDir, "pb_test_env", false)
+	defer app.ResetBootstrapState()
+
+	concat := func(args ...any) (any, error) {
+		var result string
+		for _, arg := range args {
+			result += cast.ToString(arg)
+		}
+		return result, nil
+	}
+
+	if err := app.RegisterDBFunction("invalid name", concat); err == nil {
+		t.Fatal("Expected invalid name error, got nil")
+	}
+
+	if err := app.RegisterDBFunction("test_fn", nil); err == nil {
+		t.Fatal("Expected missing implementation error, got nil")
+	}
+
+	if err := app.RegisterDBFunction("test_concat", concat); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+
+	var result string
+	if err := app.DB().NewQuery("SELECT test_concat('a', 1, 'b')").Row(&result); err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "a1b" {
+		t.Fatalf("Expected %q, got %q", "a1b", result)
+	}
+
+	// the function must be allowed in the search filters
+	expr, err := search.FilterData("test_concat(title, 'a') = 'xa'").BuildExpr(search.NewSimpleFieldResolver("title"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if raw := expr.Build(app.DB(), dbx.Params{}); !strings.HasPrefix(raw, "test_concat([[title]], {:") {
+		t.Fatalf("Unexpected filter expression %q", raw)
+	}
+}
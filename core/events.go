@@ -1,11 +1,14 @@
 package core
 
 import (
+	"io"
+
 	"github.com/pocketbase/pocketbase/daos"
 	"github.com/pocketbase/pocketbase/models"
 	"github.com/pocketbase/pocketbase/models/schema"
 	"github.com/pocketbase/pocketbase/tools/auth"
 	"github.com/pocketbase/pocketbase/tools/mailer"
+	"github.com/pocketbase/pocketbase/tools/rest"
 	"github.com/pocketbase/pocketbase/tools/search"
 	"github.com/pocketbase/pocketbase/tools/subscriptions"
 
@@ -19,6 +22,10 @@ import (
 type ServeEvent struct {
 	App    App
 	Router *echo.Echo
+
+	// Middlewares is the ordered chain of the global router middlewares
+	// (see the apis package for the built-in middleware ids and their order).
+	Middlewares *MiddlewaresChain
 }
 
 // -------------------------------------------------------------------
@@ -30,6 +37,20 @@ type ModelEvent struct {
 	Model models.Model
 }
 
+// RecordFieldChange defines the old and new value of a single changed record field.
+type RecordFieldChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+type RecordFieldsChangeEvent struct {
+	Dao    *daos.Dao
+	Record *models.Record
+
+	// Changes contains the watched changed fields indexed by their name.
+	Changes map[string]*RecordFieldChange
+}
+
 // -------------------------------------------------------------------
 // Mailer events data
 // -------------------------------------------------------------------
@@ -61,6 +82,22 @@ type RealtimeSubscribeEvent struct {
 	Subscriptions []string
 }
 
+type RealtimeRecordBroadcastEvent struct {
+	Client       subscriptions.Client
+	Subscription string
+	Action       string
+	Record       *models.Record
+
+	// Payload is the serializable message data that will be sent to the client
+	// (by default a {"action":..., "record":...} map with a client copy of
+	// the exported record). Set it to nil to skip the client message.
+	//
+	// For the update messages of clients subscribed with a delta mode,
+	// the map has also a "delta" key with the changed fields (and
+	// the "record" key is missing for the "only" delta mode).
+	Payload any
+}
+
 // -------------------------------------------------------------------
 // Settings API events data
 // -------------------------------------------------------------------
@@ -80,6 +117,25 @@ type SettingsUpdateEvent struct {
 // Record API events data
 // -------------------------------------------------------------------
 
+// collectionTags returns the hook tags of the provided collection
+// (aka. its id and name).
+func collectionTags(collection *models.Collection) []string {
+	if collection == nil {
+		return nil
+	}
+
+	return []string{collection.Id, collection.Name}
+}
+
+// recordTags returns the hook tags of the provided record collection.
+func recordTags(record *models.Record) []string {
+	if record == nil {
+		return nil
+	}
+
+	return collectionTags(record.Collection())
+}
+
 type RecordsListEvent struct {
 	HttpContext echo.Context
 	Collection  *models.Collection
@@ -87,26 +143,61 @@ type RecordsListEvent struct {
 	Result      *search.Result
 }
 
+// Tags implements [hook.Tagger] interface.
+func (e *RecordsListEvent) Tags() []string {
+	return collectionTags(e.Collection)
+}
+
 type RecordViewEvent struct {
 	HttpContext echo.Context
 	Record      *models.Record
 }
 
+// Tags implements [hook.Tagger] interface.
+func (e *RecordViewEvent) Tags() []string {
+	return recordTags(e.Record)
+}
+
 type RecordCreateEvent struct {
 	HttpContext echo.Context
 	Record      *models.Record
 }
 
+// Tags implements [hook.Tagger] interface.
+func (e *RecordCreateEvent) Tags() []string {
+	return recordTags(e.Record)
+}
+
 type RecordUpdateEvent struct {
 	HttpContext echo.Context
 	Record      *models.Record
 }
 
+// Tags implements [hook.Tagger] interface.
+func (e *RecordUpdateEvent) Tags() []string {
+	return recordTags(e.Record)
+}
+
 type RecordDeleteEvent struct {
 	HttpContext echo.Context
 	Record      *models.Record
 }
 
+// Tags implements [hook.Tagger] interface.
+func (e *RecordDeleteEvent) Tags() []string {
+	return recordTags(e.Record)
+}
+
+type RecordPublishEvent struct {
+	HttpContext echo.Context
+	Record      *models.Record
+}
+
+// Tags implements [hook.Tagger] interface.
+func (e *RecordPublishEvent) Tags() []string {
+	return recordTags(e.Record)
+}
+
 // -------------------------------------------------------------------
 // Admin API events data
 // -------------------------------------------------------------------
@@ -143,6 +234,78 @@ type AdminAuthEvent struct {
 	Token       string
 }
 
+// -------------------------------------------------------------------
+// Auth attempt events data
+// -------------------------------------------------------------------
+
+// Auth attempt methods.
+const (
+	AuthMethodEmail     string = "email"
+	AuthMethodOAuth2    string = "oauth2"
+	AuthMethodMagicLink string = "magicLink"
+)
+
+// Auth attempt subjects.
+const (
+	AuthSubjectAdmin string = "admin"
+	AuthSubjectUser  string = "user"
+)
+
+// Auth attempt failure reasons.
+//
+// Note that a missing account and a wrong password are both reported
+// as AuthFailureInvalidCredentials to avoid accounts enumeration.
+const (
+	AuthFailureInvalidRequest     string = "invalid_request"
+	AuthFailureInvalidCredentials string = "invalid_credentials"
+	AuthFailureLockedOut          string = "locked_out"
+	AuthFailureMethodDisabled     string = "method_disabled"
+	AuthFailureOAuth2             string = "oauth2_failed"
+)
+
+// AuthAttemptEvent defines the data of a single admin or user sign-in attempt.
+type AuthAttemptEvent struct {
+	HttpContext echo.Context
+
+	// Subject is the kind of the authenticated account ("admin" or "user").
+	Subject string
+
+	// Method is the used auth method ("email" or "oauth2").
+	Method string
+
+	// Provider is the OAuth2 provider name (empty for the other methods).
+	Provider string
+
+	// Identity is the attempted account identity as submitted by the client
+	// (the email address for the email method and the OAuth2 provider
+	// user id for the oauth2 method, if available).
+	Identity string
+
+	// ClientIp is the client ip address (see [Settings.Https] trusted proxies).
+	ClientIp string
+
+	// Admin is the authenticated admin (set only on admin success).
+	Admin *models.Admin
+
+	// User is the authenticated user (set only on user success).
+	User *models.User
+
+	// FailureReason is one of the AuthFailure* constants (empty on success).
+	FailureReason string
+}
+
+// AuthMeEvent defines the data of the current auth identity request.
+//
+// Only one of Admin and User is set.
+type AuthMeEvent struct {
+	HttpContext echo.Context
+	Admin       *models.Admin
+	User        *models.User
+
+	// Meta is an optional extra response data (eg. permissions).
+	Meta map[string]any
+}
+
 // -------------------------------------------------------------------
 // User API events data
 // -------------------------------------------------------------------
@@ -220,6 +383,19 @@ type CollectionDeleteEvent struct {
 // File API events data
 // -------------------------------------------------------------------
 
+type FileUploadEvent struct {
+	Collection *models.Collection
+	Record     *models.Record
+	FileField  *schema.SchemaField
+	File       *rest.UploadedFile
+	// Reader is the file content to write in the storage
+	// (could be wrapped or replaced to transform the content).
+	Reader io.Reader
+	// ContentType is the stored file content type
+	// (leave empty for auto detection).
+	ContentType string
+}
+
 type FileDownloadEvent struct {
 	HttpContext echo.Context
 	Collection  *models.Collection
@@ -0,0 +1,163 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultHealthCheckTimeout is the default max duration of a single health check.
+const DefaultHealthCheckTimeout = 5 * time.Second
+
+// HealthCheckFunc defines a health check function.
+//
+// The check is considered failed if the function returns an error
+// or doesn't complete before the provided context is done.
+type HealthCheckFunc func(ctx context.Context) error
+
+// HealthCheck defines a single named app health check.
+type HealthCheck struct {
+	// Name is the unique name of the check.
+	Name string
+
+	// Critical marks the check as required for the app readiness.
+	//
+	// Failed informational (aka. non-critical) checks are reported
+	// but don't affect the overall health status.
+	Critical bool
+
+	// Timeout is the max duration of the check (default to DefaultHealthCheckTimeout).
+	Timeout time.Duration
+
+	// Check is the check function.
+	Check HealthCheckFunc
+}
+
+// HealthCheckResult defines the result of a single health check.
+type HealthCheckResult struct {
+	Name     string `json:"name"`
+	Critical bool   `json:"critical"`
+	Healthy  bool   `json:"healthy"`
+	Error    string `json:"error,omitempty"`
+	Duration int64  `json:"duration"` // in ms
+}
+
+// HealthReport defines the aggregated result of multiple health checks.
+type HealthReport struct {
+	// Healthy reports whether all critical checks have passed.
+	Healthy bool                 `json:"healthy"`
+	Checks  []*HealthCheckResult `json:"checks"`
+}
+
+// RunHealthChecks executes in parallel the provided health checks
+// and returns their aggregated result (in the same order as the checks).
+func RunHealthChecks(ctx context.Context, checks []*HealthCheck) *HealthReport {
+	report := &HealthReport{
+		Healthy: true,
+		Checks:  make([]*HealthCheckResult, len(checks)),
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(len(checks))
+
+	for i, check := range checks {
+		go func(i int, check *HealthCheck) {
+			defer wg.Done()
+			report.Checks[i] = runHealthCheck(ctx, check)
+		}(i, check)
+	}
+
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Critical && !result.Healthy {
+			report.Healthy = false
+			break
+		}
+	}
+
+	return report
+}
+
+// runHealthCheck executes a single health check within its timeout.
+func runHealthCheck(ctx context.Context, check *HealthCheck) *HealthCheckResult {
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = DefaultHealthCheckTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result := &HealthCheckResult{Name: check.Name, Critical: check.Critical}
+
+	start := time.Now()
+
+	// buffered to not block the check goroutine in case of timeout
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- errors.New("the health check panicked")
+			}
+		}()
+		done <- check.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = errors.New("the health check timed out")
+	}
+
+	result.Duration = time.Since(start).Milliseconds()
+	result.Healthy = err == nil
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// AddHealthCheck registers a new named critical health check
+// (replacing the existing one with the same name, if any).
+//
+// The returned check could be used to further adjust its options, eg.:
+//
+//	app.AddHealthCheck("queue", checkQueue).Critical = false
+func (app *BaseApp) AddHealthCheck(name string, check HealthCheckFunc) *HealthCheck {
+	app.healthChecksMux.Lock()
+	defer app.healthChecksMux.Unlock()
+
+	if app.healthChecks == nil {
+		app.healthChecks = map[string]*HealthCheck{}
+	}
+
+	app.healthChecks[name] = &HealthCheck{
+		Name:     name,
+		Critical: true,
+		Check:    check,
+	}
+
+	return app.healthChecks[name]
+}
+
+// HealthChecks returns the registered app health checks sorted by their name.
+func (app *BaseApp) HealthChecks() []*HealthCheck {
+	app.healthChecksMux.RLock()
+	defer app.healthChecksMux.RUnlock()
+
+	result := make([]*HealthCheck, 0, len(app.healthChecks))
+	for _, check := range app.healthChecks {
+		result = append(result, check)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
@@ -0,0 +1,147 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRunHealthChecks(t *testing.T) {
+	okCheck := func(ctx context.Context) error { return nil }
+	failCheck := func(ctx context.Context) error { return errors.New("test") }
+	slowCheck := func(ctx context.Context) error {
+		time.Sleep(500 * time.Millisecond)
+		return nil
+	}
+	panicCheck := func(ctx context.Context) error { panic("test") }
+
+	scenarios := []struct {
+		name          string
+		checks        []*HealthCheck
+		expectHealthy bool
+		expectResults []bool
+	}{
+		{
+			"no checks",
+			nil,
+			true,
+			[]bool{},
+		},
+		{
+			"passing checks",
+			[]*HealthCheck{
+				{Name: "a", Critical: true, Check: okCheck},
+				{Name: "b", Critical: false, Check: okCheck},
+			},
+			true,
+			[]bool{true, true},
+		},
+		{
+			"failing informational check",
+			[]*HealthCheck{
+				{Name: "a", Critical: true, Check: okCheck},
+				{Name: "b", Critical: false, Check: failCheck},
+			},
+			true,
+			[]bool{true, false},
+		},
+		{
+			"failing critical check",
+			[]*HealthCheck{
+				{Name: "a", Critical: true, Check: failCheck},
+				{Name: "b", Critical: false, Check: okCheck},
+			},
+			false,
+			[]bool{false, true},
+		},
+		{
+			"timed out critical check",
+			[]*HealthCheck{
+				{Name: "a", Critical: true, Timeout: 10 * time.Millisecond, Check: slowCheck},
+			},
+			false,
+			[]bool{false},
+		},
+		{
+			"panicking critical check",
+			[]*HealthCheck{
+				{Name: "a", Critical: true, Check: panicCheck},
+			},
+			false,
+			[]bool{false},
+		},
+	}
+
+	for _, s := range scenarios {
+		report := RunHealthChecks(context.Background(), s.checks)
+
+		if report.Healthy != s.expectHealthy {
+			t.Errorf("[%s] Expected healthy %v, got %v", s.name, s.expectHealthy, report.Healthy)
+		}
+
+		if len(report.Checks) != len(s.expectResults) {
+			t.Errorf("[%s] Expected %d results, got %d", s.name, len(s.expectResults), len(report.Checks))
+			continue
+		}
+
+		for i, result := range report.Checks {
+			if result.Name != s.checks[i].Name {
+				t.Errorf("[%s] Expected result %d to be for check %q, got %q", s.name, i, s.checks[i].Name, result.Name)
+			}
+			if result.Healthy != s.expectResults[i] {
+				t.Errorf("[%s] Expected result %d healthy %v, got %v", s.name, i, s.expectResults[i], result.Healthy)
+			}
+			if result.Healthy == (result.Error != "") {
+				t.Errorf("[%s] Unexpected result %d error %q", s.name, i, result.Error)
+			}
+		}
+	}
+}
+
+func TestRunHealthChecksParallel(t *testing.T) {
+	check := func(ctx context.Context) error {
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	}
+
+	checks := []*HealthCheck{
+		{Name: "a", Check: check},
+		{Name: "b", Check: check},
+		{Name: "c", Check: check},
+	}
+
+	start := time.Now()
+	RunHealthChecks(context.Background(), checks)
+
+	if d := time.Since(start); d >= 300*time.Millisecond {
+		t.Fatalf("Expected the checks to run in parallel, took %v", d)
+	}
+}
+
+func TestBaseAppHealthChecks(t *testing.T) {
+	app := NewBaseApp(t.TempDir(), "pb_test_env", false)
+
+	if total := len(app.HealthChecks()); total != 0 {
+		t.Fatalf("Expected no health checks, got %d", total)
+	}
+
+	check := func(ctx context.Context) error { return nil }
+
+	app.AddHealthCheck("b", check)
+	app.AddHealthCheck("a", check).Critical = false
+	app.AddHealthCheck("b", check).Timeout = time.Second // replace
+
+	checks := app.HealthChecks()
+	if len(checks) != 2 {
+		t.Fatalf("Expected 2 health checks, got %d", len(checks))
+	}
+
+	if checks[0].Name != "a" || checks[0].Critical {
+		t.Fatalf("Expected informational check a, got %v", checks[0])
+	}
+
+	if checks[1].Name != "b" || !checks[1].Critical || checks[1].Timeout != time.Second {
+		t.Fatalf("Expected critical check b with 1s timeout, got %v", checks[1])
+	}
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// LegacyTokensGracePeriod is the duration (since the install id
+// generation of an existing app) for which the previously issued
+// tokens without "aud" claim are still accepted.
+//
+// It matches the default auth token duration, so that the already
+// issued auth tokens could expire naturally.
+const LegacyTokensGracePeriod = 14 * 24 * time.Hour
+
+// instanceParam defines the persisted app install identity.
+type instanceParam struct {
+	// Id is the random install id (the default tokens audience).
+	Id string `json:"id"`
+
+	// LegacyTokensDeadline is the time after which the
+	// tokens without "aud" claim are rejected.
+	LegacyTokensDeadline types.DateTime `json:"legacyTokensDeadline"`
+}
+
+// refreshInstance loads the persisted app install identity
+// (generating and storing a new one if missing).
+//
+// The tokens without "aud" claim are rejected immediately
+// for new installs and after [LegacyTokensGracePeriod] for the
+// existing ones (they are issued before the audience support).
+func (app *BaseApp) refreshInstance(isNewInstall bool) error {
+	param, err := app.Dao().FindParamByKey(models.ParamAppInstance)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	instance := &instanceParam{}
+
+	if param != nil {
+		if err := json.Unmarshal(param.Value, instance); err != nil {
+			return err
+		}
+	}
+
+	if instance.Id == "" {
+		deadline := time.Now()
+		if !isNewInstall {
+			deadline = deadline.Add(LegacyTokensGracePeriod)
+		}
+
+		instance.Id = security.RandomString(20)
+		instance.LegacyTokensDeadline, _ = types.ParseDateTime(deadline)
+
+		if err := app.Dao().SaveParam(models.ParamAppInstance, instance); err != nil {
+			return err
+		}
+	}
+
+	app.instance = instance
+
+	return nil
+}
+
+// legacyTokensDeadline returns the time after which the tokens
+// without "aud" claim are rejected (zero time if not loaded yet).
+func (app *BaseApp) legacyTokensDeadline() time.Time {
+	if app.instance == nil {
+		return time.Time{}
+	}
+
+	return app.instance.LegacyTokensDeadline.Time()
+}
@@ -0,0 +1,142 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/labstack/echo/v5"
+)
+
+// MiddlewaresChain defines an ordered list of named global router middlewares.
+//
+// Unlike `echo.Use()`, which always appends, the chain allows inserting
+// a middleware at a specific position relative to an already registered one
+// (eg. before the built-in auth context loader).
+//
+// The chain is applied on every request, so it could be modified
+// at any time before serving (eg. in the OnBeforeServe hook).
+type MiddlewaresChain struct {
+	mux   sync.RWMutex
+	items []*namedMiddleware
+}
+
+type namedMiddleware struct {
+	id string
+	fn echo.MiddlewareFunc
+}
+
+// NewMiddlewaresChain creates a new empty middlewares chain.
+func NewMiddlewaresChain() *MiddlewaresChain {
+	return &MiddlewaresChain{}
+}
+
+// Use appends the middleware with the specified id at the end of the chain.
+//
+// id could be empty if the middleware doesn't need to be referenced later.
+func (c *MiddlewaresChain) Use(id string, m echo.MiddlewareFunc) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return c.insert(len(c.items), id, m)
+}
+
+// InsertBefore inserts the middleware with the specified id
+// right before the middleware with targetId.
+func (c *MiddlewaresChain) InsertBefore(targetId string, id string, m echo.MiddlewareFunc) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	index := c.indexOf(targetId)
+	if index < 0 {
+		return fmt.Errorf("Missing middleware with id %q.", targetId)
+	}
+
+	return c.insert(index, id, m)
+}
+
+// InsertAfter inserts the middleware with the specified id
+// right after the middleware with targetId.
+func (c *MiddlewaresChain) InsertAfter(targetId string, id string, m echo.MiddlewareFunc) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	index := c.indexOf(targetId)
+	if index < 0 {
+		return fmt.Errorf("Missing middleware with id %q.", targetId)
+	}
+
+	return c.insert(index+1, id, m)
+}
+
+// Remove removes the middleware with the specified id from the chain (if exists).
+func (c *MiddlewaresChain) Remove(id string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if index := c.indexOf(id); index >= 0 {
+		c.items = append(c.items[:index], c.items[index+1:]...)
+	}
+}
+
+// Ids returns the ids of the chain middlewares in their execution order
+// (the unnamed middlewares are returned as empty strings).
+func (c *MiddlewaresChain) Ids() []string {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+
+	result := make([]string, len(c.items))
+	for i, item := range c.items {
+		result[i] = item.id
+	}
+
+	return result
+}
+
+// Handler returns a single middleware that executes the chain
+// middlewares in their current order.
+func (c *MiddlewaresChain) Handler() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			c.mux.RLock()
+			h := next
+			for i := len(c.items) - 1; i >= 0; i-- {
+				h = c.items[i].fn(h)
+			}
+			c.mux.RUnlock()
+
+			return h(ctx)
+		}
+	}
+}
+
+func (c *MiddlewaresChain) indexOf(id string) int {
+	if id == "" {
+		return -1
+	}
+
+	for i, item := range c.items {
+		if item.id == id {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (c *MiddlewaresChain) insert(index int, id string, m echo.MiddlewareFunc) error {
+	if m == nil {
+		return fmt.Errorf("Middleware %q must not be nil.", id)
+	}
+
+	if c.indexOf(id) >= 0 {
+		return fmt.Errorf("Middleware with id %q is already registered.", id)
+	}
+
+	item := &namedMiddleware{id: id, fn: m}
+
+	c.items = append(c.items, nil)
+	copy(c.items[index+1:], c.items[index:])
+	c.items[index] = item
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+)
+
+func TestMiddlewaresChain(t *testing.T) {
+	calls := ""
+
+	mock := func(name string) echo.MiddlewareFunc {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(c echo.Context) error {
+				calls += name
+				return next(c)
+			}
+		}
+	}
+
+	chain := NewMiddlewaresChain()
+
+	if err := chain.Use("a", mock("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.Use("c", mock("c")); err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.Use("", mock("_")); err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.InsertBefore("c", "b", mock("b")); err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.InsertBefore("a", "first", mock("1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.InsertAfter("c", "d", mock("d")); err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.Use("x", mock("x")); err != nil {
+		t.Fatal(err)
+	}
+	chain.Remove("x")
+	chain.Remove("missing")
+
+	// errors
+	if err := chain.Use("a", mock("a")); err == nil {
+		t.Fatal("Expected duplicated id error")
+	}
+	if err := chain.InsertBefore("missing", "e", mock("e")); err == nil {
+		t.Fatal("Expected missing target error")
+	}
+	if err := chain.InsertAfter("missing", "e", mock("e")); err == nil {
+		t.Fatal("Expected missing target error")
+	}
+	if err := chain.InsertAfter("", "e", mock("e")); err == nil {
+		t.Fatal("Expected empty target error")
+	}
+	if err := chain.Use("e", nil); err == nil {
+		t.Fatal("Expected nil middleware error")
+	}
+
+	expectedIds := "first,a,b,c,d,"
+	if ids := strings.Join(chain.Ids(), ","); ids != expectedIds {
+		t.Fatalf("Expected ids %q, got %q", expectedIds, ids)
+	}
+
+	e := echo.New()
+	e.Use(chain.Handler())
+	e.GET("/", func(c echo.Context) error {
+		calls += "h"
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	// the chain changes after the handler registration are also applied
+	chain.Use("f", mock("f"))
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status %d, got %d", http.StatusNoContent, rec.Code)
+	}
+
+	if expected := "1abcd_fh"; calls != expected {
+		t.Fatalf("Expected calls %q, got %q", expected, calls)
+	}
+}
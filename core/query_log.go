@@ -0,0 +1,142 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// QueryLogMaxEntries is the max number of collected entries
+// of a single query log (the exceeding queries are only counted).
+const QueryLogMaxEntries = 500
+
+// QueryLogEntry defines a single executed db query.
+type QueryLogEntry struct {
+	// Sql is the executed sql statement with its bound params values.
+	Sql string `json:"sql"`
+
+	// Duration is the query execution time in milliseconds.
+	Duration float64 `json:"duration"`
+
+	// Error is the query error message (if any).
+	Error string `json:"error,omitempty"`
+}
+
+// QueryLog collects the db queries executed during a single request
+// (see [BaseApp.StartQueryLog]).
+type QueryLog struct {
+	mux     sync.Mutex
+	entries []*QueryLogEntry
+	dropped int
+}
+
+// Entries returns a copy of the collected query log entries.
+func (l *QueryLog) Entries() []*QueryLogEntry {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	return append([]*QueryLogEntry{}, l.entries...)
+}
+
+// TotalDuration returns the sum of the collected queries execution
+// time in milliseconds.
+func (l *QueryLog) TotalDuration() float64 {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	var total float64
+	for _, entry := range l.entries {
+		total += entry.Duration
+	}
+
+	return total
+}
+
+// Dropped returns the number of the queries that were not
+// collected because of the [QueryLogMaxEntries] limit.
+func (l *QueryLog) Dropped() int {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	return l.dropped
+}
+
+func (l *QueryLog) add(entry *QueryLogEntry) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	if len(l.entries) >= QueryLogMaxEntries {
+		l.dropped++
+		return
+	}
+
+	l.entries = append(l.entries, entry)
+}
+
+// queryLogContextKey is the context key of the active query log.
+type queryLogContextKey struct{}
+
+// queryLogs defines the list of the active app query logs.
+type queryLogs struct {
+	mux  sync.RWMutex
+	logs map[*QueryLog]struct{}
+}
+
+// StartQueryLog starts collecting the db queries executed with the
+// returned context (or any of its descendants) until the returned
+// stop func is called.
+//
+// Only the queries executed with the query log context are collected
+// (eg. with app.Dao().WithContext(ctx)), so the queries of the default
+// app.Dao() and of the other concurrent requests are not part of the log.
+//
+// The queries are collected only when the app is in debug mode.
+func (app *BaseApp) StartQueryLog(ctx context.Context) (context.Context, *QueryLog, func()) {
+	log := &QueryLog{}
+
+	app.queryLogs.mux.Lock()
+	if app.queryLogs.logs == nil {
+		app.queryLogs.logs = map[*QueryLog]struct{}{}
+	}
+	app.queryLogs.logs[log] = struct{}{}
+	app.queryLogs.mux.Unlock()
+
+	stop := func() {
+		app.queryLogs.mux.Lock()
+		delete(app.queryLogs.logs, log)
+		app.queryLogs.mux.Unlock()
+	}
+
+	return context.WithValue(ctx, queryLogContextKey{}, log), log, stop
+}
+
+// logQuery adds an already executed db query to the
+// active query log of the provided context (if any).
+func (app *BaseApp) logQuery(ctx context.Context, t time.Duration, sql string, err error) {
+	if ctx == nil {
+		return
+	}
+
+	log, _ := ctx.Value(queryLogContextKey{}).(*QueryLog)
+	if log == nil {
+		return
+	}
+
+	app.queryLogs.mux.RLock()
+	_, isActive := app.queryLogs.logs[log]
+	app.queryLogs.mux.RUnlock()
+
+	if !isActive {
+		return
+	}
+
+	entry := &QueryLogEntry{
+		Sql:      sql,
+		Duration: float64(t.Microseconds()) / 1000,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	log.add(entry)
+}
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+)
+
+func TestBaseAppStartQueryLog(t *testing.T) {
+	const testDataDir = "./pb_base_app_test_data_dir/"
+	defer os.RemoveAll(testDataDir)
+
+	app := NewBaseApp(testDataDir, "pb_test_env", true)
+	defer app.ResetBootstrapState()
+
+	if err := app.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx1, log1, stop1 := app.StartQueryLog(context.Background())
+	ctx2, log2, stop2 := app.StartQueryLog(context.Background())
+
+	var result int
+
+	// without context
+	if err := app.DB().NewQuery("SELECT 1").Row(&result); err != nil {
+		t.Fatal(err)
+	}
+
+	// with a query log context
+	if err := app.DB().NewQuery("SELECT {:value}").Bind(dbx.Params{"value": 2}).WithContext(ctx1).Row(&result); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.DB().NewQuery("SELECT 3").WithContext(ctx2).Row(&result); err != nil {
+		t.Fatal(err)
+	}
+
+	// failed query
+	app.DB().NewQuery("SELECT missing FROM missing").WithContext(ctx1).Execute()
+
+	stop1()
+
+	// stopped log
+	if err := app.DB().NewQuery("SELECT 4").WithContext(ctx1).Row(&result); err != nil {
+		t.Fatal(err)
+	}
+
+	stop2()
+
+	scenarios := []struct {
+		log            *QueryLog
+		expectedSql    []string
+		expectedErrors []bool
+	}{
+		{log1, []string{"SELECT 2", "SELECT missing FROM missing"}, []bool{false, true}},
+		{log2, []string{"SELECT 3"}, []bool{false}},
+	}
+
+	for i, s := range scenarios {
+		entries := s.log.Entries()
+
+		sqls := make([]string, len(entries))
+		for j, entry := range entries {
+			sqls[j] = entry.Sql
+
+			if entry.Duration < 0 {
+				t.Errorf("(%d) Expected non-negative duration, got %v", i, entry.Duration)
+			}
+
+			if hasErr := entry.Error != ""; hasErr != s.expectedErrors[j] {
+				t.Errorf("(%d) Expected entry %d hasErr %v, got %v", i, j, s.expectedErrors[j], hasErr)
+			}
+		}
+
+		if strings.Join(sqls, ";") != strings.Join(s.expectedSql, ";") {
+			t.Errorf("(%d) Expected queries %v, got %v", i, s.expectedSql, sqls)
+		}
+
+		if s.log.TotalDuration() < 0 {
+			t.Errorf("(%d) Expected non-negative total duration, got %v", i, s.log.TotalDuration())
+		}
+	}
+}
+
+func TestBaseAppStartQueryLogWithoutDebug(t *testing.T) {
+	const testDataDir = "./pb_base_app_test_data_dir/"
+	defer os.RemoveAll(testDataDir)
+
+	app := NewBaseApp(testDataDir, "pb_test_env", false)
+	defer app.ResetBootstrapState()
+
+	if err := app.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, log, stop := app.StartQueryLog(context.Background())
+	defer stop()
+
+	var result int
+	if err := app.DB().NewQuery("SELECT 1").WithContext(ctx).Row(&result); err != nil {
+		t.Fatal(err)
+	}
+
+	if total := len(log.Entries()); total != 0 {
+		t.Fatalf("Expected no collected queries, got %d", total)
+	}
+}
+
+func TestBaseAppStartQueryLogMaxEntries(t *testing.T) {
+	const testDataDir = "./pb_base_app_test_data_dir/"
+	defer os.RemoveAll(testDataDir)
+
+	app := NewBaseApp(testDataDir, "pb_test_env", true)
+	defer app.ResetBootstrapState()
+
+	if err := app.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, log, stop := app.StartQueryLog(context.Background())
+	defer stop()
+
+	var result int
+	for i := 0; i < QueryLogMaxEntries+5; i++ {
+		if err := app.DB().NewQuery("SELECT 1").WithContext(ctx).Row(&result); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if total := len(log.Entries()); total != QueryLogMaxEntries {
+		t.Fatalf("Expected %d collected queries, got %d", QueryLogMaxEntries, total)
+	}
+
+	if dropped := log.Dropped(); dropped != 5 {
+		t.Fatalf("Expected 5 dropped queries, got %d", dropped)
+	}
+}
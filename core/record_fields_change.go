@@ -0,0 +1,134 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/list"
+)
+
+// recordFieldsChangeSubscription defines a single registered
+// OnRecordFieldsChange hook with its watched fields.
+type recordFieldsChangeSubscription struct {
+	collectionNameOrId string
+	fields             []string
+	hook               *hook.Hook[*RecordFieldsChangeEvent]
+}
+
+// matchCollection checks whether the subscription targets the provided collection.
+func (s *recordFieldsChangeSubscription) matchCollection(collection *models.Collection) bool {
+	return s.collectionNameOrId == collection.Id ||
+		strings.EqualFold(s.collectionNameOrId, collection.Name)
+}
+
+// OnRecordFieldsChange registers a new hook that is triggered after
+// successfully updating a record of the specified collection, but only
+// if at least one of the watched fields has changed.
+//
+// If no fields are specified, a change in any of the collection
+// schema fields triggers the hook.
+//
+// The changes are computed against the record data as it was loaded
+// from the db, so records without tracked original state (eg. updated
+// via a manually initialized model) don't trigger the hook.
+//
+// Each call registers a separate subscription, eg.:
+//
+//	app.OnRecordFieldsChange("orders", "status").Add(func(e *core.RecordFieldsChangeEvent) error {
+//		log.Println(e.Changes["status"].Old, "->", e.Changes["status"].New)
+//		return nil
+//	})
+func (app *BaseApp) OnRecordFieldsChange(collectionNameOrId string, fields ...string) *hook.Hook[*RecordFieldsChangeEvent] {
+	app.recordFieldsChangeMux.Lock()
+	defer app.recordFieldsChangeMux.Unlock()
+
+	subscription := &recordFieldsChangeSubscription{
+		collectionNameOrId: collectionNameOrId,
+		fields:             list.ToUniqueStringSlice(fields),
+		hook:               &hook.Hook[*RecordFieldsChangeEvent]{},
+	}
+
+	app.recordFieldsChangeSubscriptions = append(app.recordFieldsChangeSubscriptions, subscription)
+
+	return subscription.hook
+}
+
+// triggerRecordFieldsChange triggers the OnRecordFieldsChange hooks
+// matching the changed fields of the provided updated model.
+func (app *BaseApp) triggerRecordFieldsChange(dao *daos.Dao, m models.Model) {
+	record, ok := m.(*models.Record)
+	if !ok || record.Collection() == nil {
+		return
+	}
+
+	original := record.OriginalData()
+	if original == nil {
+		return // nothing to compare with
+	}
+
+	app.recordFieldsChangeMux.RLock()
+	subscriptions := make([]*recordFieldsChangeSubscription, 0, len(app.recordFieldsChangeSubscriptions))
+	for _, s := range app.recordFieldsChangeSubscriptions {
+		if s.matchCollection(record.Collection()) {
+			subscriptions = append(subscriptions, s)
+		}
+	}
+	app.recordFieldsChangeMux.RUnlock()
+
+	if len(subscriptions) == 0 {
+		return
+	}
+
+	changes := recordFieldChanges(record, original)
+	if len(changes) == 0 {
+		return
+	}
+
+	for _, s := range subscriptions {
+		watched := changes
+		if len(s.fields) > 0 {
+			watched = map[string]*RecordFieldChange{}
+			for _, name := range s.fields {
+				if change, ok := changes[name]; ok {
+					watched[name] = change
+				}
+			}
+		}
+
+		if len(watched) == 0 {
+			continue
+		}
+
+		s.hook.Trigger(&RecordFieldsChangeEvent{
+			Dao:     dao,
+			Record:  record,
+			Changes: watched,
+		})
+	}
+}
+
+// recordFieldChanges returns the old and new values of the record
+// schema fields that differ from the provided original data.
+func recordFieldChanges(record *models.Record, original map[string]any) map[string]*RecordFieldChange {
+	result := map[string]*RecordFieldChange{}
+
+	for _, field := range record.Collection().Schema.Fields() {
+		oldValue := original[field.Name]
+		newValue := record.GetDataValue(field.Name)
+
+		// compare the serialized values to normalize the different
+		// representations of the same value (eg. dates)
+		oldRaw, _ := json.Marshal(oldValue)
+		newRaw, _ := json.Marshal(newValue)
+
+		if !bytes.Equal(oldRaw, newRaw) {
+			result[field.Name] = &RecordFieldChange{Old: oldValue, New: newValue}
+		}
+	}
+
+	return result
+}
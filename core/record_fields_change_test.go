@@ -0,0 +1,108 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestOnRecordFieldsChange(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, err := app.Dao().FindCollectionByNameOrId("demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := map[string][]*core.RecordFieldsChangeEvent{}
+	register := func(key string, nameOrId string, fields ...string) {
+		app.OnRecordFieldsChange(nameOrId, fields...).Add(func(e *core.RecordFieldsChangeEvent) error {
+			calls[key] = append(calls[key], e)
+			return nil
+		})
+	}
+	register("title", "demo", "title")
+	register("missing", "demo", "missing")
+	register("any", collection.Id)
+	register("other", "demo2", "title")
+
+	record, err := app.Dao().FindFirstRecordByData(collection, "id", "577bd676-aacb-4072-b7da-99d00ee210a4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldTitle := record.GetStringDataValue("title")
+
+	// save without changes
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 0 {
+		t.Fatalf("Expected no hook calls, got %v", calls)
+	}
+
+	// change the watched field
+	record.SetDataValue("title", "new title")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedCalls := map[string]int{"title": 1, "any": 1}
+	if len(calls) != len(expectedCalls) {
+		t.Fatalf("Expected calls %v, got %v", expectedCalls, calls)
+	}
+	for key, total := range expectedCalls {
+		if len(calls[key]) != total {
+			t.Fatalf("Expected %d %q calls, got %d", total, key, len(calls[key]))
+		}
+	}
+
+	e := calls["title"][0]
+	if e.Record != record {
+		t.Fatal("Expected the event record to be the saved record")
+	}
+	if len(e.Changes) != 1 {
+		t.Fatalf("Expected only the title change, got %v", e.Changes)
+	}
+	if e.Changes["title"].Old != oldTitle || e.Changes["title"].New != "new title" {
+		t.Fatalf("Expected %q -> %q change, got %v", oldTitle, "new title", e.Changes["title"])
+	}
+
+	// the saved state becomes the new original
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls["title"]) != 1 || len(calls["any"]) != 1 {
+		t.Fatalf("Expected no new hook calls, got %v", calls)
+	}
+}
+
+func TestOnRecordFieldsChangeWithoutOriginal(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, err := app.Dao().FindCollectionByNameOrId("demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	app.OnRecordFieldsChange("demo").Add(func(e *core.RecordFieldsChangeEvent) error {
+		calls++
+		return nil
+	})
+
+	// manually initialized existing record without tracked original state
+	record := models.NewRecord(collection)
+	record.Id = "577bd676-aacb-4072-b7da-99d00ee210a4"
+	record.SetDataValue("title", "new title")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 0 {
+		t.Fatalf("Expected no hook calls, got %d", calls)
+	}
+}
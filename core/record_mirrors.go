@@ -0,0 +1,119 @@
+package core
+
+import (
+	"sync"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// fillRecordMirrors fills the mirror fields of the
+// provided model (if it is a record with mirrors).
+func fillRecordMirrors(dao *daos.Dao, m models.Model) error {
+	record, ok := m.(*models.Record)
+	if !ok {
+		return nil
+	}
+
+	return dao.FillRecordMirrors(record)
+}
+
+// syncRecordMirrors propagates the changes of the provided updated
+// model (if it is a record) to the records that mirror its fields.
+//
+// The sync is performed with dao after the eventDao transaction commit
+// (if any) to not hold the db write lock while updating the dependent records.
+//
+// Because the model is already updated, the sync errors (eg. because of
+// too many dependent records) are only logged. The not synced mirror
+// collections are marked as stale by the dao and they could be fixed
+// with the "mirrors backfill" command.
+func (app *BaseApp) syncRecordMirrors(eventDao *daos.Dao, dao *daos.Dao, m models.Model) {
+	record, ok := m.(*models.Record)
+	if !ok || record.Collection() == nil {
+		return
+	}
+
+	// snapshot the changed record state since its original data
+	// could be refreshed before the transaction commit
+	source := models.NewRecord(record.Collection())
+	if original := record.OriginalData(); original != nil {
+		source.Load(original)
+		source.RefreshOriginalData()
+	}
+	source.Load(record.Data())
+	source.Id = record.Id
+
+	eventDao.AfterCommit(func() {
+		if _, err := dao.SyncRecordMirrors(source, app.Settings().Mirrors.MaxAffectedRecords); err != nil {
+			app.Logger().Printf(
+				"Failed to sync the %q record %q mirrors (run the \"mirrors backfill\" command to fix them): %v\n",
+				source.Collection().Name, source.Id, err,
+			)
+		}
+	})
+}
+
+// onCollectionChange resets the cached record mirrors
+// if the provided changed model is a collection.
+func (app *BaseApp) onCollectionChange(dao *daos.Dao, m models.Model) {
+	if _, ok := m.(*models.Collection); !ok {
+		return
+	}
+
+	app.recordMirrors.reset()
+
+	// reset again in case the cache was reloaded
+	// before the collection change commit
+	dao.AfterCommit(app.recordMirrors.reset)
+}
+
+// recordMirrorsCache caches the collections mirror options
+// to avoid loading them on every record update.
+type recordMirrorsCache struct {
+	mux     sync.RWMutex
+	version int
+	mirrors map[string][]*daos.RecordMirror // nil if not loaded
+}
+
+// find returns the cached collections mirror options
+// (loading them with the provided dao if not cached).
+//
+// The options loaded within a transaction are not cached
+// since the transaction could be rolled back.
+func (c *recordMirrorsCache) find(dao *daos.Dao) (map[string][]*daos.RecordMirror, error) {
+	c.mux.RLock()
+	mirrors := c.mirrors
+	version := c.version
+	c.mux.RUnlock()
+
+	if mirrors != nil {
+		return mirrors, nil
+	}
+
+	mirrors, err := dao.FindRecordMirrors()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, isTx := dao.DB().(*dbx.Tx); !isTx {
+		c.mux.Lock()
+		// skip if the cache was reset during the load
+		if c.version == version {
+			c.mirrors = mirrors
+		}
+		c.mux.Unlock()
+	}
+
+	return mirrors, nil
+}
+
+// reset clears the cached mirror options.
+func (c *recordMirrorsCache) reset() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.version++
+	c.mirrors = nil
+}
@@ -4,12 +4,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
 	"github.com/pocketbase/pocketbase/tools/auth"
+	"github.com/pocketbase/pocketbase/tools/search"
 	"github.com/pocketbase/pocketbase/tools/security"
 )
 
@@ -23,21 +28,36 @@ const (
 type Settings struct {
 	mux sync.RWMutex
 
-	Meta                    MetaConfig         `form:"meta" json:"meta"`
-	Logs                    LogsConfig         `form:"logs" json:"logs"`
-	Smtp                    SmtpConfig         `form:"smtp" json:"smtp"`
-	S3                      S3Config           `form:"s3" json:"s3"`
-	AdminAuthToken          TokenConfig        `form:"adminAuthToken" json:"adminAuthToken"`
-	AdminPasswordResetToken TokenConfig        `form:"adminPasswordResetToken" json:"adminPasswordResetToken"`
-	UserAuthToken           TokenConfig        `form:"userAuthToken" json:"userAuthToken"`
-	UserPasswordResetToken  TokenConfig        `form:"userPasswordResetToken" json:"userPasswordResetToken"`
-	UserEmailChangeToken    TokenConfig        `form:"userEmailChangeToken" json:"userEmailChangeToken"`
-	UserVerificationToken   TokenConfig        `form:"userVerificationToken" json:"userVerificationToken"`
-	EmailAuth               EmailAuthConfig    `form:"emailAuth" json:"emailAuth"`
-	GoogleAuth              AuthProviderConfig `form:"googleAuth" json:"googleAuth"`
-	FacebookAuth            AuthProviderConfig `form:"facebookAuth" json:"facebookAuth"`
-	GithubAuth              AuthProviderConfig `form:"githubAuth" json:"githubAuth"`
-	GitlabAuth              AuthProviderConfig `form:"gitlabAuth" json:"gitlabAuth"`
+	Meta                    MetaConfig           `form:"meta" json:"meta"`
+	Logs                    LogsConfig           `form:"logs" json:"logs"`
+	Backups                 BackupsConfig        `form:"backups" json:"backups"`
+	Https                   HttpsConfig          `form:"https" json:"https"`
+	Smtp                    SmtpConfig           `form:"smtp" json:"smtp"`
+	S3                      S3Config             `form:"s3" json:"s3"`
+	AdminAuthToken          TokenConfig          `form:"adminAuthToken" json:"adminAuthToken"`
+	AdminPasswordResetToken TokenConfig          `form:"adminPasswordResetToken" json:"adminPasswordResetToken"`
+	AdminMagicLinkToken     TokenConfig          `form:"adminMagicLinkToken" json:"adminMagicLinkToken"`
+	UserAuthToken           TokenConfig          `form:"userAuthToken" json:"userAuthToken"`
+	UserPasswordResetToken  TokenConfig          `form:"userPasswordResetToken" json:"userPasswordResetToken"`
+	UserEmailChangeToken    TokenConfig          `form:"userEmailChangeToken" json:"userEmailChangeToken"`
+	UserVerificationToken   TokenConfig          `form:"userVerificationToken" json:"userVerificationToken"`
+	EmailAuth               EmailAuthConfig      `form:"emailAuth" json:"emailAuth"`
+	AdminMagicLink          AdminMagicLinkConfig `form:"adminMagicLink" json:"adminMagicLink"`
+	LoginLockout            LoginLockoutConfig   `form:"loginLockout" json:"loginLockout"`
+	CookieAuth              CookieAuthConfig     `form:"cookieAuth" json:"cookieAuth"`
+	Expand                  ExpandConfig         `form:"expand" json:"expand"`
+	Filter                  FilterConfig         `form:"filter" json:"filter"`
+	Realtime                RealtimeConfig       `form:"realtime" json:"realtime"`
+	RecordsApi              RecordsApiConfig     `form:"recordsApi" json:"recordsApi"`
+	JsonResponses           JsonResponsesConfig  `form:"jsonResponses" json:"jsonResponses"`
+	Timezone                TimezoneConfig       `form:"timezone" json:"timezone"`
+	FeatureFlags            FeatureFlagsConfig   `form:"featureFlags" json:"featureFlags"`
+	Mirrors                 MirrorsConfig        `form:"mirrors" json:"mirrors"`
+	AuthWebhooks            AuthWebhooksConfig   `form:"authWebhooks" json:"authWebhooks"`
+	GoogleAuth              AuthProviderConfig   `form:"googleAuth" json:"googleAuth"`
+	FacebookAuth            AuthProviderConfig   `form:"facebookAuth" json:"facebookAuth"`
+	GithubAuth              AuthProviderConfig   `form:"githubAuth" json:"githubAuth"`
+	GitlabAuth              AuthProviderConfig   `form:"gitlabAuth" json:"gitlabAuth"`
 }
 
 // NewSettings creates and returns a new default Settings instance.
@@ -55,6 +75,15 @@ func NewSettings() *Settings {
 		Logs: LogsConfig{
 			MaxDays: 7,
 		},
+		Backups: BackupsConfig{
+			AutoInterval: 0, // disabled
+			AutoMaxKeep:  3,
+		},
+		Https: HttpsConfig{
+			Redirect:       false,
+			TrustedProxies: []string{},
+			HstsMaxAge:     0, // disabled
+		},
 		Smtp: SmtpConfig{
 			Enabled:  false,
 			Host:     "smtp.example.com",
@@ -62,6 +91,7 @@ func NewSettings() *Settings {
 			Username: "",
 			Password: "",
 			Tls:      false,
+			Failover: []SmtpServerConfig{},
 		},
 		AdminAuthToken: TokenConfig{
 			Secret:   security.RandomString(50),
@@ -71,6 +101,10 @@ func NewSettings() *Settings {
 			Secret:   security.RandomString(50),
 			Duration: 1800, // 30 minutes,
 		},
+		AdminMagicLinkToken: TokenConfig{
+			Secret:   security.RandomString(50),
+			Duration: 600, // 10 minutes,
+		},
 		UserAuthToken: TokenConfig{
 			Secret:   security.RandomString(50),
 			Duration: 1209600, // 14 days,
@@ -91,6 +125,47 @@ func NewSettings() *Settings {
 			Enabled:           true,
 			MinPasswordLength: 8,
 		},
+		AdminMagicLink: AdminMagicLinkConfig{
+			Enabled: false,
+		},
+		LoginLockout: LoginLockoutConfig{
+			Enabled:     true,
+			MaxAttempts: 5,
+			Duration:    300, // 5 minutes
+		},
+		CookieAuth: CookieAuthConfig{
+			Enabled:  false,
+			Secure:   true,
+			SameSite: CookieSameSiteLax,
+		},
+		Expand: ExpandConfig{
+			MaxPaths:   20,
+			MaxRecords: 10000,
+		},
+		Filter: FilterConfig{
+			MaxConditions:    50,
+			MaxDepth:         10,
+			MaxLikeWildcards: 40,
+		},
+		Realtime: RealtimeConfig{
+			MaxConnections:       0, // no limit
+			MaxClientConnections: 0, // no limit
+			ConnectRateLimit:     0, // disabled
+			ConnectRateDuration:  60,
+			UpdatesDebounce:      0, // disabled
+		},
+		Timezone: TimezoneConfig{
+			Name: "UTC",
+		},
+		FeatureFlags: FeatureFlagsConfig{
+			Flags: []FeatureFlag{},
+		},
+		Mirrors: MirrorsConfig{
+			MaxAffectedRecords: 1000,
+		},
+		AuthWebhooks: AuthWebhooksConfig{
+			Endpoints: []AuthWebhookConfig{},
+		},
 		GoogleAuth: AuthProviderConfig{
 			Enabled:            false,
 			AllowRegistrations: true,
@@ -118,8 +193,11 @@ func (s *Settings) Validate() error {
 	return validation.ValidateStruct(s,
 		validation.Field(&s.Meta),
 		validation.Field(&s.Logs),
+		validation.Field(&s.Backups),
+		validation.Field(&s.Https),
 		validation.Field(&s.AdminAuthToken),
 		validation.Field(&s.AdminPasswordResetToken),
+		validation.Field(&s.AdminMagicLinkToken),
 		validation.Field(&s.UserAuthToken),
 		validation.Field(&s.UserPasswordResetToken),
 		validation.Field(&s.UserEmailChangeToken),
@@ -127,6 +205,15 @@ func (s *Settings) Validate() error {
 		validation.Field(&s.Smtp),
 		validation.Field(&s.S3),
 		validation.Field(&s.EmailAuth),
+		validation.Field(&s.LoginLockout),
+		validation.Field(&s.CookieAuth),
+		validation.Field(&s.Expand),
+		validation.Field(&s.Filter),
+		validation.Field(&s.Realtime),
+		validation.Field(&s.Timezone),
+		validation.Field(&s.FeatureFlags),
+		validation.Field(&s.Mirrors),
+		validation.Field(&s.AuthWebhooks),
 		validation.Field(&s.GoogleAuth),
 		validation.Field(&s.FacebookAuth),
 		validation.Field(&s.GithubAuth),
@@ -156,6 +243,20 @@ func (s *Settings) Clone() (*Settings, error) {
 	return settings, nil
 }
 
+// TokenConfigs returns the settings token configs keyed by their json name
+// (eg. "userAuthToken").
+func (s *Settings) TokenConfigs() map[string]*TokenConfig {
+	return map[string]*TokenConfig{
+		"adminAuthToken":          &s.AdminAuthToken,
+		"adminPasswordResetToken": &s.AdminPasswordResetToken,
+		"adminMagicLinkToken":     &s.AdminMagicLinkToken,
+		"userAuthToken":           &s.UserAuthToken,
+		"userPasswordResetToken":  &s.UserPasswordResetToken,
+		"userEmailChangeToken":    &s.UserEmailChangeToken,
+		"userVerificationToken":   &s.UserVerificationToken,
+	}
+}
+
 // RedactClone creates a new deep copy of the current settings,
 // while replacing the secret values with `******`.
 func (s *Settings) RedactClone() (*Settings, error) {
@@ -169,8 +270,10 @@ func (s *Settings) RedactClone() (*Settings, error) {
 	sensitiveFields := []*string{
 		&clone.Smtp.Password,
 		&clone.S3.Secret,
+		&clone.FeatureFlags.Secret,
 		&clone.AdminAuthToken.Secret,
 		&clone.AdminPasswordResetToken.Secret,
+		&clone.AdminMagicLinkToken.Secret,
 		&clone.UserAuthToken.Secret,
 		&clone.UserPasswordResetToken.Secret,
 		&clone.UserEmailChangeToken.Secret,
@@ -181,6 +284,18 @@ func (s *Settings) RedactClone() (*Settings, error) {
 		&clone.GitlabAuth.ClientSecret,
 	}
 
+	for _, config := range clone.TokenConfigs() {
+		for i := range config.Keys {
+			sensitiveFields = append(sensitiveFields, &config.Keys[i].Secret)
+		}
+	}
+	for i := range clone.Smtp.Failover {
+		sensitiveFields = append(sensitiveFields, &clone.Smtp.Failover[i].Password)
+	}
+	for i := range clone.AuthWebhooks.Endpoints {
+		sensitiveFields = append(sensitiveFields, &clone.AuthWebhooks.Endpoints[i].Secret)
+	}
+
 	// mask all sensitive fields
 	for _, v := range sensitiveFields {
 		if v != nil && *v != "" {
@@ -204,9 +319,27 @@ func (s *Settings) NamedAuthProviderConfigs() map[string]AuthProviderConfig {
 
 // -------------------------------------------------------------------
 
+// tokenKeyIdRegex matches a valid token signing key id.
+var tokenKeyIdRegex = regexp.MustCompile(`^[\w\-]+$`)
+
 type TokenConfig struct {
+	// Secret is the legacy token signing key.
+	//
+	// It is used to sign the new tokens when SigningKeyId is not set
+	// and to verify the tokens without "kid" header.
 	Secret   string `form:"secret" json:"secret"`
 	Duration int64  `form:"duration" json:"duration"`
+
+	// SigningKeyId is the id of the Keys item used to sign the new tokens.
+	SigningKeyId string `form:"signingKeyId" json:"signingKeyId"`
+
+	// Keys is the set of the named token keys that are valid for verification
+	// (tokens with "kid" header are verified only with the matching key).
+	//
+	// To rotate the signing key without invalidating the existing tokens,
+	// add a new key, set it as SigningKeyId and remove the old one
+	// only after all of its issued tokens have expired.
+	Keys []TokenKey `form:"keys" json:"keys"`
 }
 
 // Validate makes TokenConfig validatable by implementing [validation.Validatable] interface.
@@ -214,6 +347,106 @@ func (c TokenConfig) Validate() error {
 	return validation.ValidateStruct(&c,
 		validation.Field(&c.Secret, validation.Required, validation.Length(30, 300)),
 		validation.Field(&c.Duration, validation.Required, validation.Min(5), validation.Max(31536000)),
+		validation.Field(&c.SigningKeyId, validation.By(c.checkSigningKeyId)),
+		validation.Field(&c.Keys, validation.By(checkUniqueTokenKeyIds)),
+	)
+}
+
+func (c TokenConfig) checkSigningKeyId(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil // legacy secret
+	}
+
+	if _, ok := c.findKey(v); !ok {
+		return validation.NewError("validation_missing_signing_key", fmt.Sprintf("Missing token key %q.", v))
+	}
+
+	return nil
+}
+
+func checkUniqueTokenKeyIds(value any) error {
+	v, _ := value.([]TokenKey)
+
+	ids := make(map[string]struct{}, len(v))
+	for _, key := range v {
+		if _, ok := ids[key.Id]; ok {
+			return validation.NewError("validation_duplicated_key_id", fmt.Sprintf("Duplicated token key id %q.", key.Id))
+		}
+		ids[key.Id] = struct{}{}
+	}
+
+	return nil
+}
+
+func (c TokenConfig) findKey(id string) (TokenKey, bool) {
+	for _, key := range c.Keys {
+		if key.Id == id {
+			return key, true
+		}
+	}
+
+	return TokenKey{}, false
+}
+
+// SigningKey returns the id and the secret of the key
+// that should be used to sign the new tokens.
+//
+// The returned id is empty if the legacy Secret is used.
+func (c TokenConfig) SigningKey() (string, string) {
+	if c.SigningKeyId != "" {
+		if key, ok := c.findKey(c.SigningKeyId); ok {
+			return key.Id, key.Secret
+		}
+	}
+
+	return "", c.Secret
+}
+
+// VerificationSecret returns the secret that should be used to verify
+// the provided token based on its "kid" header.
+//
+// Tokens without "kid" header fallback to the legacy Secret.
+// Returns an empty string if the "kid" header doesn't match any of the Keys.
+func (c TokenConfig) VerificationSecret(token string) string {
+	kid := security.ParseUnverifiedJWTKeyId(token)
+	if kid == "" {
+		return c.Secret
+	}
+
+	key, _ := c.findKey(kid)
+
+	return key.Secret
+}
+
+// RotateKey generates a new random token key,
+// adds it to the Keys set and marks it as the signing one.
+//
+// The previous keys remain valid for verification until removed.
+// Returns the id of the new key.
+func (c *TokenConfig) RotateKey() string {
+	key := TokenKey{
+		Id:     security.RandomString(15),
+		Secret: security.RandomString(50),
+	}
+
+	c.Keys = append(c.Keys, key)
+	c.SigningKeyId = key.Id
+
+	return key.Id
+}
+
+// TokenKey defines a single named token signing key.
+type TokenKey struct {
+	Id     string `form:"id" json:"id"`
+	Secret string `form:"secret" json:"secret"`
+}
+
+// Validate makes TokenKey validatable by implementing [validation.Validatable] interface.
+func (k TokenKey) Validate() error {
+	return validation.ValidateStruct(&k,
+		validation.Field(&k.Id, validation.Required, validation.Length(1, 100), validation.Match(tokenKeyIdRegex)),
+		validation.Field(&k.Secret, validation.Required, validation.Length(30, 300)),
 	)
 }
 
@@ -231,6 +464,16 @@ type SmtpConfig struct {
 	// When set to false StartTLS command is send, leaving the server
 	// to decide whether to upgrade the connection or not.
 	Tls bool `form:"tls" json:"tls"`
+
+	// MaxPerMinute is the max number of emails per minute that could
+	// be sent through the server (0 means no limit).
+	MaxPerMinute int `form:"maxPerMinute" json:"maxPerMinute"`
+
+	// Failover is an optional list with additional SMTP servers (ordered
+	// by priority) that are tried when the send through the previous one fails.
+	//
+	// A server with several consecutive send failures is temporary skipped.
+	Failover []SmtpServerConfig `form:"failover" json:"failover"`
 }
 
 // Validate makes SmtpConfig validatable by implementing [validation.Validatable] interface.
@@ -238,6 +481,43 @@ func (c SmtpConfig) Validate() error {
 	return validation.ValidateStruct(&c,
 		validation.Field(&c.Host, is.Host, validation.When(c.Enabled, validation.Required)),
 		validation.Field(&c.Port, validation.When(c.Enabled, validation.Required), validation.Min(0)),
+		validation.Field(&c.MaxPerMinute, validation.Min(0)),
+		validation.Field(&c.Failover),
+	)
+}
+
+// Servers returns the primary and the failover SMTP servers ordered by priority.
+func (c SmtpConfig) Servers() []SmtpServerConfig {
+	result := make([]SmtpServerConfig, 0, len(c.Failover)+1)
+
+	result = append(result, SmtpServerConfig{
+		Host:         c.Host,
+		Port:         c.Port,
+		Username:     c.Username,
+		Password:     c.Password,
+		Tls:          c.Tls,
+		MaxPerMinute: c.MaxPerMinute,
+	})
+
+	return append(result, c.Failover...)
+}
+
+// SmtpServerConfig defines a single failover SMTP server configuration.
+type SmtpServerConfig struct {
+	Host         string `form:"host" json:"host"`
+	Port         int    `form:"port" json:"port"`
+	Username     string `form:"username" json:"username"`
+	Password     string `form:"password" json:"password"`
+	Tls          bool   `form:"tls" json:"tls"`
+	MaxPerMinute int    `form:"maxPerMinute" json:"maxPerMinute"`
+}
+
+// Validate makes SmtpServerConfig validatable by implementing [validation.Validatable] interface.
+func (c SmtpServerConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Host, validation.Required, is.Host),
+		validation.Field(&c.Port, validation.Required, validation.Min(0)),
+		validation.Field(&c.MaxPerMinute, validation.Min(0)),
 	)
 }
 
@@ -273,6 +553,12 @@ type MetaConfig struct {
 	UserVerificationUrl       string `form:"userVerificationUrl" json:"userVerificationUrl"`
 	UserResetPasswordUrl      string `form:"userResetPasswordUrl" json:"userResetPasswordUrl"`
 	UserConfirmEmailChangeUrl string `form:"userConfirmEmailChangeUrl" json:"userConfirmEmailChangeUrl"`
+
+	// TokensAudience is an optional "aud" claim of the issued tokens.
+	//
+	// By default the tokens are scoped to the app instance id. Set the same
+	// value in multiple instances to allow them to share their tokens.
+	TokensAudience string `form:"tokensAudience" json:"tokensAudience"`
 }
 
 // Validate makes MetaConfig validatable by implementing [validation.Validatable] interface.
@@ -280,6 +566,7 @@ func (c MetaConfig) Validate() error {
 	return validation.ValidateStruct(&c,
 		validation.Field(&c.AppName, validation.Required, validation.Length(1, 255)),
 		validation.Field(&c.AppUrl, validation.Required, is.URL),
+		validation.Field(&c.TokensAudience, validation.Length(0, 255)),
 		validation.Field(&c.SenderName, validation.Required, validation.Length(1, 255)),
 		validation.Field(&c.SenderAddress, is.Email, validation.Required),
 		validation.Field(
@@ -332,14 +619,521 @@ func (c LogsConfig) Validate() error {
 
 // -------------------------------------------------------------------
 
+type BackupsConfig struct {
+	// AutoInterval specifies the interval (in hours) between the
+	// automatically created backups (0 disables the auto backups).
+	AutoInterval int `form:"autoInterval" json:"autoInterval"`
+
+	// AutoMaxKeep specifies the max number of auto backups to keep
+	// (the oldest ones are deleted first; 0 means no limit).
+	AutoMaxKeep int `form:"autoMaxKeep" json:"autoMaxKeep"`
+}
+
+// Validate makes BackupsConfig validatable by implementing [validation.Validatable] interface.
+func (c BackupsConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.AutoInterval, validation.Min(0)),
+		validation.Field(&c.AutoMaxKeep, validation.Min(0)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+type HttpsConfig struct {
+	// Redirect enables the redirect of the plain HTTP requests to HTTPS.
+	Redirect bool `form:"redirect" json:"redirect"`
+
+	// TrustedProxies is a list of proxy IPs or CIDR ranges whose
+	// X-Forwarded-Proto, X-Forwarded-For and X-Real-IP headers
+	// are trusted (eg. "127.0.0.1", "10.0.0.0/8").
+	TrustedProxies []string `form:"trustedProxies" json:"trustedProxies"`
+
+	// HstsMaxAge specifies the Strict-Transport-Security max-age (in seconds)
+	// that is sent with the HTTPS responses (0 disables the header).
+	HstsMaxAge            int  `form:"hstsMaxAge" json:"hstsMaxAge"`
+	HstsIncludeSubdomains bool `form:"hstsIncludeSubdomains" json:"hstsIncludeSubdomains"`
+	HstsPreload           bool `form:"hstsPreload" json:"hstsPreload"`
+}
+
+// Validate makes HttpsConfig validatable by implementing [validation.Validatable] interface.
+func (c HttpsConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.TrustedProxies, validation.Each(validation.By(checkIpOrCidr))),
+		validation.Field(&c.HstsMaxAge, validation.Min(0)),
+	)
+}
+
+// IsTrustedProxy checks whether the provided ip is from a trusted proxy.
+func (c HttpsConfig) IsTrustedProxy(ip string) bool {
+	parsedIp := net.ParseIP(ip)
+	if parsedIp == nil {
+		return false
+	}
+
+	for _, item := range c.TrustedProxies {
+		if _, ipNet, err := net.ParseCIDR(item); err == nil {
+			if ipNet.Contains(parsedIp) {
+				return true
+			}
+		} else if trustedIp := net.ParseIP(item); trustedIp != nil && trustedIp.Equal(parsedIp) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func checkIpOrCidr(value any) error {
+	v, _ := value.(string)
+
+	if net.ParseIP(v) != nil {
+		return nil
+	}
+
+	if _, _, err := net.ParseCIDR(v); err == nil {
+		return nil
+	}
+
+	return validation.NewError("validation_invalid_ip_or_cidr", "Must be a valid IP address or CIDR range.")
+}
+
+// -------------------------------------------------------------------
+
+type LoginLockoutConfig struct {
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// MaxAttempts is the number of consecutive failed login attempts
+	// after which the account login is temporary locked.
+	MaxAttempts int `form:"maxAttempts" json:"maxAttempts"`
+
+	// Duration specifies how long (in seconds) the account login remains locked.
+	// It is also used as window for resetting the failed attempts counter.
+	Duration int `form:"duration" json:"duration"`
+}
+
+// Validate makes LoginLockoutConfig validatable by implementing [validation.Validatable] interface.
+func (c LoginLockoutConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.MaxAttempts, validation.When(c.Enabled, validation.Required), validation.Min(0)),
+		validation.Field(&c.Duration, validation.When(c.Enabled, validation.Required), validation.Min(0)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+type AdminMagicLinkConfig struct {
+	// Enabled indicates whether the admins could authenticate
+	// with a short-lived single-use link sent to their email.
+	Enabled bool `form:"enabled" json:"enabled"`
+}
+
+// -------------------------------------------------------------------
+
+const (
+	CookieSameSiteLax    string = "lax"
+	CookieSameSiteStrict string = "strict"
+	CookieSameSiteNone   string = "none"
+)
+
+type CookieAuthConfig struct {
+	// Enabled indicates whether to set the auth token as HttpOnly cookie
+	// on successful auth (the token is still returned in the response body).
+	//
+	// The cookie is used only when the request Authorization header is missing.
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// Secure restricts the auth cookies only to https requests.
+	Secure bool `form:"secure" json:"secure"`
+
+	// SameSite is the auth cookies SameSite attribute ("lax", "strict" or "none").
+	SameSite string `form:"sameSite" json:"sameSite"`
+
+	// Domain is an optional auth cookies domain (defaults to the request host).
+	Domain string `form:"domain" json:"domain"`
+}
+
+// Validate makes CookieAuthConfig validatable by implementing [validation.Validatable] interface.
+func (c CookieAuthConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(
+			&c.SameSite,
+			validation.When(c.Enabled, validation.Required),
+			validation.In(CookieSameSiteLax, CookieSameSiteStrict, CookieSameSiteNone),
+		),
+		validation.Field(&c.Domain, is.Domain),
+	)
+}
+
+// HttpSameSite returns the http.SameSite mode of the config SameSite value.
+func (c CookieAuthConfig) HttpSameSite() http.SameSite {
+	switch c.SameSite {
+	case CookieSameSiteStrict:
+		return http.SameSiteStrictMode
+	case CookieSameSiteNone:
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
+// -------------------------------------------------------------------
+
+// ExpandConfig defines the per request records relations expand limits.
+type ExpandConfig struct {
+	// MaxPaths is the max number of distinct expand paths
+	// per request (0 for no limit).
+	MaxPaths int `form:"maxPaths" json:"maxPaths"`
+
+	// MaxRecords is the max total number of expanded related
+	// records loaded per request (0 for no limit).
+	MaxRecords int `form:"maxRecords" json:"maxRecords"`
+}
+
+// Validate makes ExpandConfig validatable by implementing [validation.Validatable] interface.
+func (c ExpandConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.MaxPaths, validation.Min(0)),
+		validation.Field(&c.MaxRecords, validation.Min(0)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+// FilterConfig defines the complexity limits of the
+// client provided records list filters (admins are not limited).
+type FilterConfig struct {
+	// MaxConditions is the max number of comparison
+	// expressions of a single filter (0 for no limit).
+	MaxConditions int `form:"maxConditions" json:"maxConditions"`
+
+	// MaxDepth is the max parenthesized groups nesting
+	// depth of a single filter (0 for no limit).
+	MaxDepth int `form:"maxDepth" json:"maxDepth"`
+
+	// MaxLikeWildcards is the max total number of the like
+	// operands wildcards of a single filter (0 for no limit).
+	MaxLikeWildcards int `form:"maxLikeWildcards" json:"maxLikeWildcards"`
+}
+
+// Validate makes FilterConfig validatable by implementing [validation.Validatable] interface.
+func (c FilterConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.MaxConditions, validation.Min(0)),
+		validation.Field(&c.MaxDepth, validation.Min(0)),
+		validation.Field(&c.MaxLikeWildcards, validation.Min(0)),
+	)
+}
+
+// Limits returns the config as [search.FilterLimits].
+func (c FilterConfig) Limits() search.FilterLimits {
+	return search.FilterLimits{
+		MaxConditions:    c.MaxConditions,
+		MaxDepth:         c.MaxDepth,
+		MaxLikeWildcards: c.MaxLikeWildcards,
+	}
+}
+
+// -------------------------------------------------------------------
+
+// RealtimeConfig defines the realtime connections limits.
+//
+// The per client limits are tracked by the request auth record
+// (if authorized) or by the client ip.
+type RealtimeConfig struct {
+	// MaxConnections is the max number of concurrent
+	// realtime connections (0 for no limit).
+	MaxConnections int `form:"maxConnections" json:"maxConnections"`
+
+	// MaxClientConnections is the max number of concurrent
+	// realtime connections of a single client (0 for no limit).
+	MaxClientConnections int `form:"maxClientConnections" json:"maxClientConnections"`
+
+	// ConnectRateLimit is the max number of new realtime connections
+	// of a single client within ConnectRateDuration (0 for no limit).
+	ConnectRateLimit int `form:"connectRateLimit" json:"connectRateLimit"`
+
+	// ConnectRateDuration is the connect rate limit window in seconds.
+	ConnectRateDuration int64 `form:"connectRateDuration" json:"connectRateDuration"`
+
+	// UpdatesDebounce is the window in milliseconds within which the
+	// updates of the same record are coalesced into a single realtime
+	// broadcast with the latest record state (0 to broadcast every update).
+	//
+	// A record delete broadcasts its pending update immediately.
+	UpdatesDebounce int64 `form:"updatesDebounce" json:"updatesDebounce"`
+}
+
+// Validate makes RealtimeConfig validatable by implementing [validation.Validatable] interface.
+func (c RealtimeConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.MaxConnections, validation.Min(0)),
+		validation.Field(&c.MaxClientConnections, validation.Min(0)),
+		validation.Field(&c.ConnectRateLimit, validation.Min(0)),
+		validation.Field(
+			&c.ConnectRateDuration,
+			validation.When(c.ConnectRateLimit > 0, validation.Required),
+			validation.Min(int64(0)),
+		),
+		validation.Field(&c.UpdatesDebounce, validation.Min(int64(0))),
+	)
+}
+
+// -------------------------------------------------------------------
+
+// RecordsApiConfig defines the records api response options.
+type RecordsApiConfig struct {
+	// CreatedStatus enables responding with 201 (instead of 200)
+	// status code on successful record create.
+	CreatedStatus bool `form:"createdStatus" json:"createdStatus"`
+
+	// EmptyRelationAsString enables serializing the empty single
+	// relation fields as empty string (instead of the default null).
+	//
+	// The empty multiple relation fields are always serialized as [].
+	// Both null and "" are accepted as empty relation on create/update.
+	EmptyRelationAsString bool `form:"emptyRelationAsString" json:"emptyRelationAsString"`
+}
+
+// JsonResponsesConfig defines the records and collections api JSON responses format options.
+type JsonResponsesConfig struct {
+	// Pretty enables indenting the JSON responses for all clients.
+	//
+	// Admins could also request indented responses with the
+	// "?pretty=true" query parameter (regardless of this option).
+	Pretty bool `form:"pretty" json:"pretty"`
+}
+
+// -------------------------------------------------------------------
+
+// TimezoneConfig defines the app (aka. business) timezone used to
+// interpret the stored dates, eg. when grouping them by day with
+// the tz_date() and tz_strftime() db functions.
+//
+// The dates are always stored in UTC and this option only
+// affects their interpretation.
+//
+// For timezones that observe DST, the local days are not always
+// 24 hours long (eg. "America/New_York" has a 23 hours day in March
+// and a 25 hours day in November). The UTC offset is resolved based on
+// each date own instant, so the dates are always grouped in their
+// correct local day, but the dates from the repeated hour at the end
+// of the DST fall into the same local hour bucket.
+type TimezoneConfig struct {
+	// Name is the IANA timezone name (eg. "America/New_York").
+	Name string `form:"name" json:"name"`
+
+	// SerializeDates enables serializing the records date fields in the
+	// app timezone with explicit UTC offset (eg. "2022-05-01 06:00:00.000-04:00").
+	SerializeDates bool `form:"serializeDates" json:"serializeDates"`
+}
+
+// Validate makes TimezoneConfig validatable by implementing [validation.Validatable] interface.
+func (c TimezoneConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Name, validation.Required, validation.By(checkTimezoneName)),
+	)
+}
+
+// Location returns the configured timezone location
+// (fallbacks to UTC if the timezone name is invalid).
+func (c TimezoneConfig) Location() *time.Location {
+	loc, err := loadTimezoneLocation(c.Name)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+func checkTimezoneName(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil
+	}
+
+	if _, err := loadTimezoneLocation(v); err != nil {
+		return validation.NewError("validation_invalid_timezone", "Invalid or unknown timezone name.")
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
+// featureFlagNameRegex matches a valid feature flag name.
+var featureFlagNameRegex = regexp.MustCompile(`^[\w\-\.]+$`)
+
+// FeatureFlagsConfig defines the app feature flags, aka. named
+// switches that could be used to gradually roll out behavior changes.
+//
+// The flags could be overridden per request with a signed
+// X-Feature-Flags header (or by admins with the "flags" query parameter).
+type FeatureFlagsConfig struct {
+	// Flags lists the defined feature flags with their default state.
+	//
+	// Only the defined flags could be overridden per request.
+	Flags []FeatureFlag `form:"flags" json:"flags"`
+
+	// Secret is the key used to verify the feature flags header signature
+	// (the header overrides are ignored if it is not set).
+	Secret string `form:"secret" json:"secret"`
+}
+
+// Validate makes FeatureFlagsConfig validatable by implementing [validation.Validatable] interface.
+func (c FeatureFlagsConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Flags, validation.By(checkUniqueFeatureFlagNames)),
+		validation.Field(&c.Secret, validation.Length(10, 255)),
+	)
+}
+
+// Defaults returns the default state of the defined feature flags indexed by their name.
+func (c FeatureFlagsConfig) Defaults() map[string]bool {
+	result := make(map[string]bool, len(c.Flags))
+
+	for _, flag := range c.Flags {
+		result[flag.Name] = flag.Enabled
+	}
+
+	return result
+}
+
+func checkUniqueFeatureFlagNames(value any) error {
+	v, _ := value.([]FeatureFlag)
+
+	names := make(map[string]struct{}, len(v))
+	for _, flag := range v {
+		if _, ok := names[flag.Name]; ok {
+			return validation.NewError("validation_duplicated_feature_flag", fmt.Sprintf("Duplicated feature flag %q.", flag.Name))
+		}
+		names[flag.Name] = struct{}{}
+	}
+
+	return nil
+}
+
+// FeatureFlag defines a single named feature flag.
+type FeatureFlag struct {
+	Name    string `form:"name" json:"name"`
+	Enabled bool   `form:"enabled" json:"enabled"`
+}
+
+// Validate makes FeatureFlag validatable by implementing [validation.Validatable] interface.
+func (c FeatureFlag) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Name, validation.Required, validation.Length(1, 100), validation.Match(featureFlagNameRegex)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+// MirrorsConfig defines the denormalized mirror fields sync options
+// (see [models.RecordMirrorOptions]).
+type MirrorsConfig struct {
+	// MaxAffectedRecords is the max total number of dependent records
+	// that could be updated on a single source record change (0 for no limit).
+	//
+	// Mirrors with more dependent records are not synced automatically,
+	// their collections are marked as stale and have to be backfilled
+	// manually with the "mirrors backfill" command.
+	MaxAffectedRecords int `form:"maxAffectedRecords" json:"maxAffectedRecords"`
+}
+
+// Validate makes MirrorsConfig validatable by implementing [validation.Validatable] interface.
+func (c MirrorsConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.MaxAffectedRecords, validation.Min(0)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+// The supported auth webhook events.
+const (
+	AuthWebhookEventRegister    = "register"
+	AuthWebhookEventVerify      = "verify"
+	AuthWebhookEventEmailChange = "emailChange"
+	AuthWebhookEventDelete      = "delete"
+)
+
+// AuthWebhooksConfig defines the outbound user auth events webhooks.
+type AuthWebhooksConfig struct {
+	Endpoints []AuthWebhookConfig `form:"endpoints" json:"endpoints"`
+}
+
+// Validate makes AuthWebhooksConfig validatable by implementing [validation.Validatable] interface.
+func (c AuthWebhooksConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Endpoints),
+	)
+}
+
+// AuthWebhookConfig defines a single auth events webhook endpoint.
+type AuthWebhookConfig struct {
+	Enabled bool   `form:"enabled" json:"enabled"`
+	Url     string `form:"url" json:"url"`
+
+	// Secret is used to sign the delivered payloads
+	// (see the `tools/webhook` package).
+	Secret string `form:"secret" json:"secret"`
+
+	// Events is the list of the subscribed auth events
+	// (register, verify, emailChange, delete).
+	Events []string `form:"events" json:"events"`
+
+	// ExcludeFields is a list of user fields to omit from the
+	// delivered payload (eg. "email", "profile").
+	//
+	// The user password hash and token key are never delivered.
+	ExcludeFields []string `form:"excludeFields" json:"excludeFields"`
+}
+
+// Validate makes AuthWebhookConfig validatable by implementing [validation.Validatable] interface.
+func (c AuthWebhookConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Url, validation.Required, is.URL),
+		validation.Field(&c.Secret, validation.Required, validation.Length(10, 255)),
+		validation.Field(
+			&c.Events,
+			validation.Required,
+			validation.Each(validation.In(
+				AuthWebhookEventRegister,
+				AuthWebhookEventVerify,
+				AuthWebhookEventEmailChange,
+				AuthWebhookEventDelete,
+			)),
+		),
+	)
+}
+
+// HasEvent reports whether the webhook endpoint is subscribed for the specified event.
+func (c AuthWebhookConfig) HasEvent(event string) bool {
+	for _, e := range c.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// -------------------------------------------------------------------
+
 type AuthProviderConfig struct {
-	Enabled            bool   `form:"enabled" json:"enabled"`
-	AllowRegistrations bool   `form:"allowRegistrations" json:"allowRegistrations"`
-	ClientId           string `form:"clientId" json:"clientId,omitempty"`
-	ClientSecret       string `form:"clientSecret" json:"clientSecret,omitempty"`
-	AuthUrl            string `form:"authUrl" json:"authUrl,omitempty"`
-	TokenUrl           string `form:"tokenUrl" json:"tokenUrl,omitempty"`
-	UserApiUrl         string `form:"userApiUrl" json:"userApiUrl,omitempty"`
+	Enabled            bool `form:"enabled" json:"enabled"`
+	AllowRegistrations bool `form:"allowRegistrations" json:"allowRegistrations"`
+
+	// RequireLinkConfirmation disables the automatic linking of the
+	// provider identity to an existing user with the same email.
+	//
+	// In this case the user has to authenticate first with their
+	// existing account and then to link the provider identity.
+	RequireLinkConfirmation bool `form:"requireLinkConfirmation" json:"requireLinkConfirmation"`
+
+	ClientId     string `form:"clientId" json:"clientId,omitempty"`
+	ClientSecret string `form:"clientSecret" json:"clientSecret,omitempty"`
+	AuthUrl      string `form:"authUrl" json:"authUrl,omitempty"`
+	TokenUrl     string `form:"tokenUrl" json:"tokenUrl,omitempty"`
+	UserApiUrl   string `form:"userApiUrl" json:"userApiUrl,omitempty"`
 }
 
 // Validate makes `ProviderConfig` validatable by implementing [validation.Validatable] interface.
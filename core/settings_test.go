@@ -2,11 +2,14 @@ package core_test
 
 import (
 	"encoding/json"
+	"net/http"
 	"strings"
 	"testing"
 
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tools/auth"
+	"github.com/pocketbase/pocketbase/tools/security"
 )
 
 func TestSettingsValidate(t *testing.T) {
@@ -15,18 +18,27 @@ func TestSettingsValidate(t *testing.T) {
 	// set invalid settings data
 	s.Meta.AppName = ""
 	s.Logs.MaxDays = -10
+	s.Backups.AutoInterval = -10
 	s.Smtp.Enabled = true
 	s.Smtp.Host = ""
 	s.S3.Enabled = true
 	s.S3.Endpoint = "invalid"
 	s.AdminAuthToken.Duration = -10
 	s.AdminPasswordResetToken.Duration = -10
+	s.AdminMagicLinkToken.Duration = -10
 	s.UserAuthToken.Duration = -10
 	s.UserPasswordResetToken.Duration = -10
 	s.UserEmailChangeToken.Duration = -10
 	s.UserVerificationToken.Duration = -10
 	s.EmailAuth.Enabled = true
 	s.EmailAuth.MinPasswordLength = -10
+	s.LoginLockout.MaxAttempts = -1
+	s.CookieAuth.SameSite = "invalid"
+	s.Expand.MaxPaths = -1
+	s.Filter.MaxDepth = -1
+	s.Realtime.MaxConnections = -1
+	s.AuthWebhooks.Endpoints = []core.AuthWebhookConfig{{Url: "invalid"}}
+	s.Https.HstsMaxAge = -1
 	s.GoogleAuth.Enabled = true
 	s.GoogleAuth.ClientId = ""
 	s.FacebookAuth.Enabled = true
@@ -45,15 +57,24 @@ func TestSettingsValidate(t *testing.T) {
 	expectations := []string{
 		`"meta":{`,
 		`"logs":{`,
+		`"backups":{`,
+		`"https":{`,
 		`"smtp":{`,
 		`"s3":{`,
 		`"adminAuthToken":{`,
 		`"adminPasswordResetToken":{`,
+		`"adminMagicLinkToken":{`,
 		`"userAuthToken":{`,
 		`"userPasswordResetToken":{`,
 		`"userEmailChangeToken":{`,
 		`"userVerificationToken":{`,
 		`"emailAuth":{`,
+		`"loginLockout":{`,
+		`"cookieAuth":{`,
+		`"expand":{`,
+		`"filter":{`,
+		`"realtime":{`,
+		`"authWebhooks":{`,
 		`"googleAuth":{`,
 		`"facebookAuth":{`,
 		`"githubAuth":{`,
@@ -150,9 +171,13 @@ func TestSettingsRedactClone(t *testing.T) {
 	s1.Meta.AppName = "test123" // control field
 	s1.Smtp.Password = "test123"
 	s1.Smtp.Tls = true
+	s1.Smtp.Failover = []core.SmtpServerConfig{{Host: "example.com", Port: 25, Password: "test123"}}
+	s1.AuthWebhooks.Endpoints = []core.AuthWebhookConfig{{Enabled: true, Url: "https://example.com/hook", Secret: "test123", Events: []string{"register"}}}
 	s1.S3.Secret = "test123"
 	s1.AdminAuthToken.Secret = "test123"
+	s1.AdminAuthToken.Keys = []core.TokenKey{{Id: "test", Secret: "test123"}}
 	s1.AdminPasswordResetToken.Secret = "test123"
+	s1.AdminMagicLinkToken.Secret = "test123"
 	s1.UserAuthToken.Secret = "test123"
 	s1.UserPasswordResetToken.Secret = "test123"
 	s1.UserEmailChangeToken.Secret = "test123"
@@ -172,7 +197,7 @@ func TestSettingsRedactClone(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	expected := `{"meta":{"appName":"test123","appUrl":"http://localhost:8090","senderName":"Support","senderAddress":"support@example.com","userVerificationUrl":"%APP_URL%/_/#/users/confirm-verification/%TOKEN%","userResetPasswordUrl":"%APP_URL%/_/#/users/confirm-password-reset/%TOKEN%","userConfirmEmailChangeUrl":"%APP_URL%/_/#/users/confirm-email-change/%TOKEN%"},"logs":{"maxDays":7},"smtp":{"enabled":false,"host":"smtp.example.com","port":587,"username":"","password":"******","tls":true},"s3":{"enabled":false,"bucket":"","region":"","endpoint":"","accessKey":"","secret":"******"},"adminAuthToken":{"secret":"******","duration":1209600},"adminPasswordResetToken":{"secret":"******","duration":1800},"userAuthToken":{"secret":"******","duration":1209600},"userPasswordResetToken":{"secret":"******","duration":1800},"userEmailChangeToken":{"secret":"******","duration":1800},"userVerificationToken":{"secret":"******","duration":604800},"emailAuth":{"enabled":true,"exceptDomains":null,"onlyDomains":null,"minPasswordLength":8},"googleAuth":{"enabled":false,"allowRegistrations":true,"clientSecret":"******"},"facebookAuth":{"enabled":false,"allowRegistrations":true,"clientSecret":"******"},"githubAuth":{"enabled":false,"allowRegistrations":true,"clientSecret":"******"},"gitlabAuth":{"enabled":false,"allowRegistrations":true,"clientSecret":"******"}}`
+	expected := `{"meta":{"appName":"test123","appUrl":"http://localhost:8090","senderName":"Support","senderAddress":"support@example.com","userVerificationUrl":"%APP_URL%/_/#/users/confirm-verification/%TOKEN%","userResetPasswordUrl":"%APP_URL%/_/#/users/confirm-password-reset/%TOKEN%","userConfirmEmailChangeUrl":"%APP_URL%/_/#/users/confirm-email-change/%TOKEN%","tokensAudience":""},"logs":{"maxDays":7},"backups":{"autoInterval":0,"autoMaxKeep":3},"https":{"redirect":false,"trustedProxies":[],"hstsMaxAge":0,"hstsIncludeSubdomains":false,"hstsPreload":false},"smtp":{"enabled":false,"host":"smtp.example.com","port":587,"username":"","password":"******","tls":true,"maxPerMinute":0,"failover":[{"host":"example.com","port":25,"username":"","password":"******","tls":false,"maxPerMinute":0}]},"s3":{"enabled":false,"bucket":"","region":"","endpoint":"","accessKey":"","secret":"******"},"adminAuthToken":{"secret":"******","duration":1209600,"signingKeyId":"","keys":[{"id":"test","secret":"******"}]},"adminPasswordResetToken":{"secret":"******","duration":1800,"signingKeyId":"","keys":null},"adminMagicLinkToken":{"secret":"******","duration":600,"signingKeyId":"","keys":null},"userAuthToken":{"secret":"******","duration":1209600,"signingKeyId":"","keys":null},"userPasswordResetToken":{"secret":"******","duration":1800,"signingKeyId":"","keys":null},"userEmailChangeToken":{"secret":"******","duration":1800,"signingKeyId":"","keys":null},"userVerificationToken":{"secret":"******","duration":604800,"signingKeyId":"","keys":null},"emailAuth":{"enabled":true,"exceptDomains":null,"onlyDomains":null,"minPasswordLength":8},"adminMagicLink":{"enabled":false},"loginLockout":{"enabled":true,"maxAttempts":5,"duration":300},"cookieAuth":{"enabled":false,"secure":true,"sameSite":"lax","domain":""},"expand":{"maxPaths":20,"maxRecords":10000},"filter":{"maxConditions":50,"maxDepth":10,"maxLikeWildcards":40},"realtime":{"maxConnections":0,"maxClientConnections":0,"connectRateLimit":0,"connectRateDuration":60,"updatesDebounce":0},"recordsApi":{"createdStatus":false,"emptyRelationAsString":false},"jsonResponses":{"pretty":false},"timezone":{"name":"UTC","serializeDates":false},"featureFlags":{"flags":[],"secret":""},"mirrors":{"maxAffectedRecords":1000},"authWebhooks":{"endpoints":[{"enabled":true,"url":"https://example.com/hook","secret":"******","events":["register"],"excludeFields":null}]},"googleAuth":{"enabled":false,"allowRegistrations":true,"requireLinkConfirmation":false,"clientSecret":"******"},"facebookAuth":{"enabled":false,"allowRegistrations":true,"requireLinkConfirmation":false,"clientSecret":"******"},"githubAuth":{"enabled":false,"allowRegistrations":true,"requireLinkConfirmation":false,"clientSecret":"******"},"gitlabAuth":{"enabled":false,"allowRegistrations":true,"requireLinkConfirmation":false,"clientSecret":"******"}}`
 
 	if encodedStr := string(encoded); encodedStr != expected {
 		t.Fatalf("Expected %v, got \n%v", expected, encodedStr)
@@ -195,7 +220,7 @@ func TestNamedAuthProviderConfigs(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	expected := `{"facebook":{"enabled":false,"allowRegistrations":true,"clientId":"facebook_test"},"github":{"enabled":false,"allowRegistrations":true,"clientId":"github_test"},"gitlab":{"enabled":true,"allowRegistrations":true,"clientId":"gitlab_test"},"google":{"enabled":false,"allowRegistrations":true,"clientId":"google_test"}}`
+	expected := `{"facebook":{"enabled":false,"allowRegistrations":true,"requireLinkConfirmation":false,"clientId":"facebook_test"},"github":{"enabled":false,"allowRegistrations":true,"requireLinkConfirmation":false,"clientId":"github_test"},"gitlab":{"enabled":true,"allowRegistrations":true,"requireLinkConfirmation":false,"clientId":"gitlab_test"},"google":{"enabled":false,"allowRegistrations":true,"requireLinkConfirmation":false,"clientId":"google_test"}}`
 
 	if encodedStr := string(encoded); encodedStr != expected {
 		t.Fatalf("Expected the same serialization, got %v", encodedStr)
@@ -228,6 +253,52 @@ func TestTokenConfigValidate(t *testing.T) {
 			},
 			false,
 		},
+		// missing signing key
+		{
+			core.TokenConfig{
+				Secret:       "testtesttesttesttesttesttestte",
+				Duration:     100,
+				SigningKeyId: "missing",
+			},
+			true,
+		},
+		// invalid keys
+		{
+			core.TokenConfig{
+				Secret:   "testtesttesttesttesttesttestte",
+				Duration: 100,
+				Keys: []core.TokenKey{
+					{Id: "invalid id", Secret: "testtesttesttesttesttesttestte"},
+					{Id: "test", Secret: "test"},
+				},
+			},
+			true,
+		},
+		// duplicated key ids
+		{
+			core.TokenConfig{
+				Secret:   "testtesttesttesttesttesttestte",
+				Duration: 100,
+				Keys: []core.TokenKey{
+					{Id: "test", Secret: "testtesttesttesttesttesttestte"},
+					{Id: "test", Secret: "testtesttesttesttesttesttestte"},
+				},
+			},
+			true,
+		},
+		// valid data with keys
+		{
+			core.TokenConfig{
+				Secret:       "testtesttesttesttesttesttestte",
+				Duration:     100,
+				SigningKeyId: "test2",
+				Keys: []core.TokenKey{
+					{Id: "test1", Secret: "testtesttesttesttesttesttestte"},
+					{Id: "test2", Secret: "testtesttesttesttesttesttestte"},
+				},
+			},
+			false,
+		},
 	}
 
 	for i, scenario := range scenarios {
@@ -243,6 +314,90 @@ func TestTokenConfigValidate(t *testing.T) {
 	}
 }
 
+func TestTokenConfigSigningKey(t *testing.T) {
+	config := core.TokenConfig{
+		Secret: "legacy",
+		Keys: []core.TokenKey{
+			{Id: "test1", Secret: "secret1"},
+			{Id: "test2", Secret: "secret2"},
+		},
+	}
+
+	scenarios := []struct {
+		signingKeyId   string
+		expectedId     string
+		expectedSecret string
+	}{
+		{"", "", "legacy"},
+		{"missing", "", "legacy"},
+		{"test2", "test2", "secret2"},
+	}
+
+	for i, s := range scenarios {
+		config.SigningKeyId = s.signingKeyId
+
+		id, secret := config.SigningKey()
+
+		if id != s.expectedId || secret != s.expectedSecret {
+			t.Errorf("(%d) Expected (%q, %q), got (%q, %q)", i, s.expectedId, s.expectedSecret, id, secret)
+		}
+	}
+}
+
+func TestTokenConfigVerificationSecret(t *testing.T) {
+	config := core.TokenConfig{
+		Secret: "legacy",
+		Keys: []core.TokenKey{
+			{Id: "test1", Secret: "secret1"},
+		},
+	}
+
+	withoutKid, _ := security.NewToken(jwt.MapClaims{"id": "test"}, "legacy", 10)
+	withKid, _ := security.NewTokenWithKeyId(jwt.MapClaims{"id": "test"}, "test1", "secret1", 10)
+	withUnknownKid, _ := security.NewTokenWithKeyId(jwt.MapClaims{"id": "test"}, "missing", "secret1", 10)
+
+	scenarios := []struct {
+		token    string
+		expected string
+	}{
+		{withoutKid, "legacy"},
+		{withKid, "secret1"},
+		{withUnknownKid, ""},
+	}
+
+	for i, s := range scenarios {
+		if secret := config.VerificationSecret(s.token); secret != s.expected {
+			t.Errorf("(%d) Expected %q, got %q", i, s.expected, secret)
+		}
+	}
+}
+
+func TestTokenConfigRotateKey(t *testing.T) {
+	config := core.TokenConfig{
+		Secret:   "testtesttesttesttesttesttestte",
+		Duration: 100,
+	}
+
+	id1 := config.RotateKey()
+	id2 := config.RotateKey()
+
+	if id1 == "" || id1 == id2 {
+		t.Fatalf("Expected 2 different key ids, got %q and %q", id1, id2)
+	}
+
+	if config.SigningKeyId != id2 {
+		t.Fatalf("Expected signing key %q, got %q", id2, config.SigningKeyId)
+	}
+
+	if len(config.Keys) != 2 {
+		t.Fatalf("Expected 2 keys, got %v", config.Keys)
+	}
+
+	if err := config.Validate(); err != nil {
+		t.Fatalf("Expected the rotated config to be valid, got %v", err)
+	}
+}
+
 func TestSmtpConfigValidate(t *testing.T) {
 	scenarios := []struct {
 		config      core.SmtpConfig
@@ -277,6 +432,39 @@ func TestSmtpConfigValidate(t *testing.T) {
 			},
 			false,
 		},
+		// invalid failover server
+		{
+			core.SmtpConfig{
+				Enabled:  true,
+				Host:     "example.com",
+				Port:     100,
+				Failover: []core.SmtpServerConfig{{Host: "example.com"}},
+			},
+			true,
+		},
+		// negative rate limit
+		{
+			core.SmtpConfig{
+				Enabled:      true,
+				Host:         "example.com",
+				Port:         100,
+				MaxPerMinute: -1,
+			},
+			true,
+		},
+		// valid data with failover servers
+		{
+			core.SmtpConfig{
+				Enabled:      true,
+				Host:         "example.com",
+				Port:         100,
+				MaxPerMinute: 10,
+				Failover: []core.SmtpServerConfig{
+					{Host: "example2.com", Port: 25, MaxPerMinute: 5},
+				},
+			},
+			false,
+		},
 	}
 
 	for i, scenario := range scenarios {
@@ -453,6 +641,266 @@ func TestLogsConfigValidate(t *testing.T) {
 	}
 }
 
+func TestBackupsConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      core.BackupsConfig
+		expectError bool
+	}{
+		// zero values
+		{
+			core.BackupsConfig{},
+			false,
+		},
+		// invalid data
+		{
+			core.BackupsConfig{AutoInterval: -1, AutoMaxKeep: -1},
+			true,
+		},
+		// valid data
+		{
+			core.BackupsConfig{AutoInterval: 24, AutoMaxKeep: 5},
+			false,
+		},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestHttpsConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      core.HttpsConfig
+		expectError bool
+	}{
+		// zero values
+		{
+			core.HttpsConfig{},
+			false,
+		},
+		// invalid data
+		{
+			core.HttpsConfig{HstsMaxAge: -1},
+			true,
+		},
+		{
+			core.HttpsConfig{TrustedProxies: []string{"127.0.0.1", "invalid"}},
+			true,
+		},
+		{
+			core.HttpsConfig{TrustedProxies: []string{"10.0.0.0/33"}},
+			true,
+		},
+		// valid data
+		{
+			core.HttpsConfig{
+				Redirect:       true,
+				TrustedProxies: []string{"127.0.0.1", "::1", "10.0.0.0/8"},
+				HstsMaxAge:     31536000,
+			},
+			false,
+		},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestHttpsConfigIsTrustedProxy(t *testing.T) {
+	config := core.HttpsConfig{
+		TrustedProxies: []string{"127.0.0.1", "10.0.0.0/8", "invalid"},
+	}
+
+	scenarios := []struct {
+		ip       string
+		expected bool
+	}{
+		{"", false},
+		{"invalid", false},
+		{"127.0.0.1", true},
+		{"127.0.0.2", false},
+		{"10.1.2.3", true},
+		{"11.1.2.3", false},
+	}
+
+	for i, s := range scenarios {
+		if result := config.IsTrustedProxy(s.ip); result != s.expected {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, result)
+		}
+	}
+}
+
+func TestLoginLockoutConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      core.LoginLockoutConfig
+		expectError bool
+	}{
+		// zero values (disabled)
+		{
+			core.LoginLockoutConfig{},
+			false,
+		},
+		// zero values (enabled)
+		{
+			core.LoginLockoutConfig{Enabled: true},
+			true,
+		},
+		// invalid data
+		{
+			core.LoginLockoutConfig{MaxAttempts: -1, Duration: -1},
+			true,
+		},
+		// valid data
+		{
+			core.LoginLockoutConfig{Enabled: true, MaxAttempts: 3, Duration: 60},
+			false,
+		},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestCookieAuthConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      core.CookieAuthConfig
+		expectError bool
+	}{
+		// zero values (disabled)
+		{
+			core.CookieAuthConfig{},
+			false,
+		},
+		// zero values (enabled)
+		{
+			core.CookieAuthConfig{Enabled: true},
+			true,
+		},
+		// invalid data
+		{
+			core.CookieAuthConfig{SameSite: "invalid", Domain: "invalid domain"},
+			true,
+		},
+		// valid data
+		{
+			core.CookieAuthConfig{Enabled: true, Secure: true, SameSite: core.CookieSameSiteStrict, Domain: "example.com"},
+			false,
+		},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestCookieAuthConfigHttpSameSite(t *testing.T) {
+	scenarios := []struct {
+		sameSite string
+		expected http.SameSite
+	}{
+		{"", http.SameSiteLaxMode},
+		{core.CookieSameSiteLax, http.SameSiteLaxMode},
+		{core.CookieSameSiteStrict, http.SameSiteStrictMode},
+		{core.CookieSameSiteNone, http.SameSiteNoneMode},
+	}
+
+	for _, s := range scenarios {
+		config := core.CookieAuthConfig{SameSite: s.sameSite}
+		if result := config.HttpSameSite(); result != s.expected {
+			t.Errorf("[%s] Expected %v, got %v", s.sameSite, s.expected, result)
+		}
+	}
+}
+
+func TestExpandConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      core.ExpandConfig
+		expectError bool
+	}{
+		// zero values (no limits)
+		{core.ExpandConfig{}, false},
+		// invalid data
+		{core.ExpandConfig{MaxPaths: -1}, true},
+		{core.ExpandConfig{MaxRecords: -1}, true},
+		// valid data
+		{core.ExpandConfig{MaxPaths: 10, MaxRecords: 100}, false},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestFilterConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      core.FilterConfig
+		expectError bool
+	}{
+		// zero values (no limits)
+		{core.FilterConfig{}, false},
+		// invalid data
+		{core.FilterConfig{MaxConditions: -1}, true},
+		{core.FilterConfig{MaxDepth: -1}, true},
+		{core.FilterConfig{MaxLikeWildcards: -1}, true},
+		// valid data
+		{core.FilterConfig{MaxConditions: 10, MaxDepth: 2, MaxLikeWildcards: 5}, false},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
 func TestAuthProviderConfigValidate(t *testing.T) {
 	scenarios := []struct {
 		config      core.AuthProviderConfig
@@ -537,7 +985,7 @@ func TestAuthProviderConfigSetupProvider(t *testing.T) {
 		t.Error(err)
 	}
 	encoded, _ := json.Marshal(c2)
-	expected := `{"enabled":true,"allowRegistrations":false,"clientId":"test_ClientId","clientSecret":"test_ClientSecret","authUrl":"test_AuthUrl","tokenUrl":"test_TokenUrl","userApiUrl":"test_UserApiUrl"}`
+	expected := `{"enabled":true,"allowRegistrations":false,"requireLinkConfirmation":false,"clientId":"test_ClientId","clientSecret":"test_ClientSecret","authUrl":"test_AuthUrl","tokenUrl":"test_TokenUrl","userApiUrl":"test_UserApiUrl"}`
 	if string(encoded) != expected {
 		t.Errorf("Expected %s, got %s", expected, string(encoded))
 	}
@@ -616,3 +1064,182 @@ func TestEmailAuthConfigValidate(t *testing.T) {
 		}
 	}
 }
+
+func TestRealtimeConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      core.RealtimeConfig
+		expectError bool
+	}{
+		// zero values (no limits)
+		{core.RealtimeConfig{}, false},
+		// invalid data
+		{core.RealtimeConfig{MaxConnections: -1}, true},
+		{core.RealtimeConfig{MaxClientConnections: -1}, true},
+		{core.RealtimeConfig{ConnectRateLimit: -1}, true},
+		{core.RealtimeConfig{ConnectRateDuration: -1}, true},
+		{core.RealtimeConfig{ConnectRateLimit: 10}, true},
+		{core.RealtimeConfig{UpdatesDebounce: -1}, true},
+		// valid data
+		{core.RealtimeConfig{MaxConnections: 100, MaxClientConnections: 5, ConnectRateLimit: 10, ConnectRateDuration: 60, UpdatesDebounce: 100}, false},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestRealtimeConfigDefaults(t *testing.T) {
+	config := core.NewSettings().Realtime
+
+	// the per client limits are opt-in to not break the existing
+	// clients behind a shared IP (eg. NAT or corporate proxy)
+	if config.MaxClientConnections != 0 {
+		t.Fatalf("Expected MaxClientConnections to be disabled by default, got %d", config.MaxClientConnections)
+	}
+
+	if config.ConnectRateLimit != 0 {
+		t.Fatalf("Expected ConnectRateLimit to be disabled by default, got %d", config.ConnectRateLimit)
+	}
+}
+
+func TestTimezoneConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      core.TimezoneConfig
+		expectError bool
+	}{
+		// zero values
+		{core.TimezoneConfig{}, true},
+		// invalid data
+		{core.TimezoneConfig{Name: "invalid"}, true},
+		{core.TimezoneConfig{Name: "Europe/Missing"}, true},
+		// valid data
+		{core.TimezoneConfig{Name: "UTC"}, false},
+		{core.TimezoneConfig{Name: "America/New_York", SerializeDates: true}, false},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestTimezoneConfigLocation(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		expected string
+	}{
+		{"", "UTC"},
+		{"invalid", "UTC"},
+		{"UTC", "UTC"},
+		{"America/New_York", "America/New_York"},
+	}
+
+	for i, scenario := range scenarios {
+		result := core.TimezoneConfig{Name: scenario.name}.Location()
+
+		if result.String() != scenario.expected {
+			t.Errorf("(%d) Expected %q, got %q", i, scenario.expected, result.String())
+		}
+	}
+}
+
+func TestFeatureFlagsConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      core.FeatureFlagsConfig
+		expectError bool
+	}{
+		// zero values
+		{core.FeatureFlagsConfig{}, false},
+		// invalid data
+		{core.FeatureFlagsConfig{Flags: []core.FeatureFlag{{Name: ""}}}, true},
+		{core.FeatureFlagsConfig{Flags: []core.FeatureFlag{{Name: "invalid name"}}}, true},
+		{core.FeatureFlagsConfig{Flags: []core.FeatureFlag{{Name: "test"}, {Name: "test", Enabled: true}}}, true},
+		{core.FeatureFlagsConfig{Secret: "123"}, true},
+		// valid data
+		{core.FeatureFlagsConfig{Flags: []core.FeatureFlag{{Name: "test-1"}, {Name: "test.2", Enabled: true}}, Secret: "1234567890"}, false},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestFeatureFlagsConfigDefaults(t *testing.T) {
+	config := core.FeatureFlagsConfig{
+		Flags: []core.FeatureFlag{{Name: "a", Enabled: true}, {Name: "b"}},
+	}
+
+	result := config.Defaults()
+
+	if len(result) != 2 || !result["a"] || result["b"] {
+		t.Fatalf("Unexpected defaults %v", result)
+	}
+}
+
+func TestAuthWebhookConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		config      core.AuthWebhookConfig
+		expectError bool
+	}{
+		// zero values
+		{core.AuthWebhookConfig{}, true},
+		// invalid data
+		{core.AuthWebhookConfig{Url: "invalid", Secret: "1234567890", Events: []string{"register"}}, true},
+		{core.AuthWebhookConfig{Url: "https://example.com", Secret: "123", Events: []string{"register"}}, true},
+		{core.AuthWebhookConfig{Url: "https://example.com", Secret: "1234567890", Events: []string{"missing"}}, true},
+		// valid data
+		{core.AuthWebhookConfig{Url: "https://example.com", Secret: "1234567890", Events: []string{"register", "verify", "emailChange", "delete"}}, false},
+	}
+
+	for i, scenario := range scenarios {
+		result := scenario.config.Validate()
+
+		if result != nil && !scenario.expectError {
+			t.Errorf("(%d) Didn't expect error, got %v", i, result)
+		}
+
+		if result == nil && scenario.expectError {
+			t.Errorf("(%d) Expected error, got nil", i)
+		}
+	}
+}
+
+func TestAuthWebhookConfigHasEvent(t *testing.T) {
+	config := core.AuthWebhookConfig{Events: []string{"register", "delete"}}
+
+	scenarios := map[string]bool{
+		"register":    true,
+		"delete":      true,
+		"verify":      false,
+		"emailChange": false,
+	}
+
+	for event, expected := range scenarios {
+		if result := config.HasEvent(event); result != expected {
+			t.Errorf("(%s) Expected %v, got %v", event, expected, result)
+		}
+	}
+}
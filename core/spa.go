@@ -0,0 +1,34 @@
+package core
+
+import (
+	"io/fs"
+	"time"
+)
+
+// SPAConfig defines the options of a single page application
+// that is served from the app root path (see [BaseAppConfig.SPA]).
+type SPAConfig struct {
+	// FS is the file system with the SPA static files.
+	FS fs.FS
+
+	// Index is the SPA entry html file that is served for the root
+	// path and for the unknown client-side routes (default to "index.html").
+	Index string
+
+	// AssetsMaxAge is the browser cache max age of the static
+	// files, except the index file (default to 1 hour).
+	AssetsMaxAge time.Duration
+}
+
+// normalize returns a config copy with the zero value fields set to their defaults.
+func (c SPAConfig) normalize() SPAConfig {
+	if c.Index == "" {
+		c.Index = "index.html"
+	}
+
+	if c.AssetsMaxAge == 0 {
+		c.AssetsMaxAge = time.Hour
+	}
+
+	return c
+}
@@ -0,0 +1,135 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // embed the timezone database for systems without one
+
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
+)
+
+// TimezoneStrftimeDBFunction is the name of the built-in db function that
+// formats the stored UTC dates in the app timezone (see [TimezoneConfig]).
+//
+// It has the same signature as the SQLite strftime (except the modifiers),
+// eg. `tz_strftime('%Y-%m-%d', created) = '2022-05-01'`.
+const TimezoneStrftimeDBFunction = daos.TimezoneStrftimeFunc
+
+// TimezoneDateDBFunction is the name of the built-in db function that
+// returns the app timezone day ("YYYY-MM-DD") of the stored UTC date,
+// eg. `tz_date(created) = '2022-05-01'`.
+const TimezoneDateDBFunction = "tz_date"
+
+// timezoneLocations caches the loaded timezone locations by their name.
+var timezoneLocations sync.Map
+
+// loadTimezoneLocation returns the (cached) location with the provided IANA name.
+func loadTimezoneLocation(name string) (*time.Location, error) {
+	if loc, ok := timezoneLocations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+
+	timezoneLocations.Store(name, loc)
+
+	return loc, nil
+}
+
+// timezoneDBFunctions returns the built-in app timezone db functions.
+func (app *BaseApp) timezoneDBFunctions() map[string]DBFunction {
+	return map[string]DBFunction{
+		TimezoneStrftimeDBFunction: func(args ...any) (any, error) {
+			if len(args) != 2 {
+				return nil, errors.New(TimezoneStrftimeDBFunction + " expects exactly 2 arguments")
+			}
+
+			return app.timezoneStrftime(cast.ToString(args[0]), args[1])
+		},
+		TimezoneDateDBFunction: func(args ...any) (any, error) {
+			if len(args) != 1 {
+				return nil, errors.New(TimezoneDateDBFunction + " expects exactly 1 argument")
+			}
+
+			return app.timezoneStrftime("%Y-%m-%d", args[0])
+		},
+	}
+}
+
+// timezoneStrftime formats the provided UTC date value in the app timezone.
+//
+// Returns nil for empty or invalid dates (similar to the SQLite strftime).
+func (app *BaseApp) timezoneStrftime(format string, value any) (any, error) {
+	if b, ok := value.([]byte); ok {
+		value = string(b)
+	}
+
+	date, err := types.ParseDateTime(value)
+	if err != nil || date.IsZero() {
+		return nil, nil
+	}
+
+	loc := time.UTC
+	if settings := app.Settings(); settings != nil {
+		loc = settings.Timezone.Location()
+	}
+
+	return Strftime(format, date.Time().In(loc)), nil
+}
+
+// Strftime formats t according to the SQLite strftime format specifiers
+// (%d, %f, %H, %j, %m, %M, %s, %S, %w, %W, %Y and %%).
+func Strftime(format string, t time.Time) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i == len(format)-1 {
+			sb.WriteByte(format[i])
+			continue
+		}
+
+		i++
+
+		switch format[i] {
+		case 'd':
+			fmt.Fprintf(&sb, "%02d", t.Day())
+		case 'f':
+			fmt.Fprintf(&sb, "%02d.%03d", t.Second(), t.Nanosecond()/int(time.Millisecond))
+		case 'H':
+			fmt.Fprintf(&sb, "%02d", t.Hour())
+		case 'j':
+			fmt.Fprintf(&sb, "%03d", t.YearDay())
+		case 'm':
+			fmt.Fprintf(&sb, "%02d", int(t.Month()))
+		case 'M':
+			fmt.Fprintf(&sb, "%02d", t.Minute())
+		case 's':
+			sb.WriteString(strconv.FormatInt(t.Unix(), 10))
+		case 'S':
+			fmt.Fprintf(&sb, "%02d", t.Second())
+		case 'w':
+			sb.WriteString(strconv.Itoa(int(t.Weekday())))
+		case 'W':
+			// week of the year (00-53) with Monday as the first day of the week
+			fmt.Fprintf(&sb, "%02d", (t.YearDay()+6-(int(t.Weekday())+6)%7)/7)
+		case 'Y':
+			fmt.Fprintf(&sb, "%04d", t.Year())
+		case '%':
+			sb.WriteByte('%')
+		default:
+			sb.WriteByte('%')
+			sb.WriteByte(format[i])
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestStrftime(t *testing.T) {
+	date := time.Date(2022, 1, 3, 4, 5, 6, 789000000, time.UTC)
+
+	scenarios := []struct {
+		format   string
+		expected string
+	}{
+		{"", ""},
+		{"test", "test"},
+		{"%Y-%m-%d %H:%M:%S", "2022-01-03 04:05:06"},
+		{"%f", "06.789"},
+		{"%j %w %W", "003 1 01"},
+		{"%s", "1641182706"},
+		{"%% %x %", "% %x %"},
+	}
+
+	for i, scenario := range scenarios {
+		result := Strftime(scenario.format, date)
+
+		if result != scenario.expected {
+			t.Errorf("(%d) Expected %q, got %q", i, scenario.expected, result)
+		}
+	}
+}
+
+func TestBaseAppTimezoneDBFunctions(t *testing.T) {
+	const testDataDir = "./pb_base_app_test_data_dir/"
+	defer os.RemoveAll(testDataDir)
+
+	app := NewBaseApp(testDataDir, "pb_test_env", false)
+	defer app.ResetBootstrapState()
+
+	if err := app.Bootstrap(); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		timezone string
+		query    string
+		expected string
+	}{
+		{"UTC", "SELECT tz_date('2022-05-01 02:00:00.000')", "2022-05-01"},
+		{"America/New_York", "SELECT tz_date('2022-05-01 02:00:00.000')", "2022-04-30"},
+		{"America/New_York", "SELECT tz_strftime('%Y-%m-%d %H:%M', '2022-05-01 02:00:00.000')", "2022-04-30 22:00"},
+		// DST end (the repeated 01:00-02:00 local hour)
+		{"America/New_York", "SELECT tz_strftime('%H', '2022-11-06 05:30:00.000')", "01"},
+		{"America/New_York", "SELECT tz_strftime('%H', '2022-11-06 06:30:00.000')", "01"},
+		{"America/New_York", "SELECT ifnull(tz_date(''), 'null')", "null"},
+	}
+
+	for i, scenario := range scenarios {
+		app.Settings().Timezone.Name = scenario.timezone
+
+		var result string
+		if err := app.DB().NewQuery(scenario.query).Row(&result); err != nil {
+			t.Errorf("(%d) %v", i, err)
+			continue
+		}
+
+		if result != scenario.expected {
+			t.Errorf("(%d) Expected %q, got %q", i, scenario.expected, result)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the instrumentation name of the app tracer.
+const TracerName = "github.com/pocketbase/pocketbase"
+
+// newAppTracer returns the app tracer of the provided
+// tracer provider (nil if the provider is not set).
+func newAppTracer(provider trace.TracerProvider) trace.Tracer {
+	if provider == nil {
+		return nil
+	}
+
+	return provider.Tracer(TracerName)
+}
+
+// traceDBQuery records an already executed db query as a child span
+// of the active ctx span.
+//
+// Queries executed without a traced context (eg. with the default
+// app.Dao() outside of a request) are not recorded to avoid flooding
+// the trace backend with unrelated root spans.
+func traceDBQuery(tracer trace.Tracer, ctx context.Context, name string, t time.Duration, sql string, err error) {
+	if ctx == nil || !trace.SpanContextFromContext(ctx).IsValid() {
+		return
+	}
+
+	end := time.Now()
+
+	_, span := tracer.Start(
+		ctx,
+		name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(end.Add(-t)),
+		trace.WithAttributes(
+			attribute.String("db.system", "sqlite"),
+			attribute.String("db.statement", sql),
+		),
+	)
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End(trace.WithTimestamp(end))
+}
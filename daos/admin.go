@@ -49,6 +49,10 @@ func (dao *Dao) FindAdminByEmail(email string) (*models.Admin, error) {
 //
 // Returns an error if the JWT token is invalid or expired.
 func (dao *Dao) FindAdminByToken(token string, baseTokenKey string) (*models.Admin, error) {
+	if baseTokenKey == "" {
+		return nil, errors.New("Missing or unknown token verification key.")
+	}
+
 	unverifiedClaims, err := security.ParseUnverifiedJWT(token)
 	if err != nil {
 		return nil, err
@@ -68,7 +72,12 @@ func (dao *Dao) FindAdminByToken(token string, baseTokenKey string) (*models.Adm
 	verificationKey := admin.TokenKey + baseTokenKey
 
 	// verify token signature
-	if _, err := security.ParseJWT(token, verificationKey); err != nil {
+	claims, err := security.ParseJWT(token, verificationKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dao.checkTokenAudience(claims); err != nil {
 		return nil, err
 	}
 
@@ -84,6 +93,19 @@ func (dao *Dao) TotalAdmins() (int, error) {
 	return total, err
 }
 
+// TotalSuperAdmins returns the number of existing admin records
+// with full (non collection restricted) access.
+func (dao *Dao) TotalSuperAdmins() (int, error) {
+	var total int
+
+	err := dao.AdminQuery().
+		Select("count(*)").
+		AndWhere(dbx.NewExp("COALESCE(json_extract([[permissions]], '$.restricted'), 0) = 0")).
+		Row(&total)
+
+	return total, err
+}
+
 // IsAdminEmailUnique checks if the provided email address is not
 // already in use by other admins.
 func (dao *Dao) IsAdminEmailUnique(email string, excludeId string) bool {
@@ -104,7 +126,8 @@ func (dao *Dao) IsAdminEmailUnique(email string, excludeId string) bool {
 
 // DeleteAdmin deletes the provided Admin model.
 //
-// Returns an error if there is only 1 admin.
+// Returns an error if there is only 1 admin or if the admin
+// is the only existing super admin.
 func (dao *Dao) DeleteAdmin(admin *models.Admin) error {
 	total, err := dao.TotalAdmins()
 	if err != nil {
@@ -115,6 +138,17 @@ func (dao *Dao) DeleteAdmin(admin *models.Admin) error {
 		return errors.New("You cannot delete the only existing admin.")
 	}
 
+	if admin.IsSuper() {
+		totalSuper, err := dao.TotalSuperAdmins()
+		if err != nil {
+			return err
+		}
+
+		if totalSuper == 1 {
+			return errors.New("You cannot delete the only existing super admin.")
+		}
+	}
+
 	return dao.Delete(admin)
 }
 
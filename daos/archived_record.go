@@ -0,0 +1,56 @@
+package daos
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// ArchivedRecordQuery returns a new ArchivedRecord select query.
+func (dao *Dao) ArchivedRecordQuery() *dbx.SelectQuery {
+	return dao.ModelQuery(&models.ArchivedRecord{})
+}
+
+// FindArchivedRecordById returns a single ArchivedRecord model
+// of the provided collection by its id.
+func (dao *Dao) FindArchivedRecordById(collectionId string, id string) (*models.ArchivedRecord, error) {
+	model := &models.ArchivedRecord{}
+
+	err := dao.ArchivedRecordQuery().
+		AndWhere(dbx.HashExp{
+			"id":           id,
+			"collectionId": collectionId,
+		}).
+		Limit(1).
+		One(model)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// SaveArchivedRecord upserts the provided ArchivedRecord model.
+func (dao *Dao) SaveArchivedRecord(model *models.ArchivedRecord) error {
+	return dao.Save(model)
+}
+
+// DeleteArchivedRecord deletes the provided ArchivedRecord model.
+func (dao *Dao) DeleteArchivedRecord(model *models.ArchivedRecord) error {
+	return dao.Delete(model)
+}
+
+// archiveRecord stores a snapshot of the provided record.
+func (dao *Dao) archiveRecord(record *models.Record, deletedBy string) error {
+	data := record.Data()
+	data[schema.ReservedFieldNameCreated] = record.Created
+	data[schema.ReservedFieldNameUpdated] = record.Updated
+
+	return dao.SaveArchivedRecord(&models.ArchivedRecord{
+		CollectionId: record.Collection().Id,
+		RecordId:     record.Id,
+		Data:         data,
+		DeletedBy:    deletedBy,
+	})
+}
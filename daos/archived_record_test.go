@@ -0,0 +1,91 @@
+package daos_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestDeleteRecordWithArchive(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, _ := app.Dao().FindCollectionByNameOrId("demo3")
+
+	countArchived := func() int {
+		var total int
+		app.Dao().ArchivedRecordQuery().
+			Select("count(*)").
+			AndWhere(dbx.HashExp{"collectionId": collection.Id}).
+			Row(&total)
+		return total
+	}
+
+	// archive disabled
+	// ---
+	record1 := models.NewRecord(collection)
+	record1.SetDataValue("title", "test1")
+	if err := app.Dao().SaveRecord(record1); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Dao().DeleteRecord(record1); err != nil {
+		t.Fatal(err)
+	}
+	if total := countArchived(); total != 0 {
+		t.Fatalf("Expected no archived records, got %d", total)
+	}
+
+	// archive enabled
+	// ---
+	collection.Options.Archive = true
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	record2 := models.NewRecord(collection)
+	record2.SetDataValue("title", "test2")
+	if err := app.Dao().SaveRecord(record2); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Dao().DeleteRecordBy(record2, "admin:test"); err != nil {
+		t.Fatal(err)
+	}
+
+	archived := []*models.ArchivedRecord{}
+	if err := app.Dao().ArchivedRecordQuery().AndWhere(dbx.HashExp{"collectionId": collection.Id}).All(&archived); err != nil {
+		t.Fatal(err)
+	}
+	if len(archived) != 1 {
+		t.Fatalf("Expected 1 archived record, got %d", len(archived))
+	}
+	if archived[0].RecordId != record2.Id {
+		t.Fatalf("Expected archived record id %q, got %q", record2.Id, archived[0].RecordId)
+	}
+	if archived[0].DeletedBy != "admin:test" {
+		t.Fatalf("Expected deletedBy %q, got %q", "admin:test", archived[0].DeletedBy)
+	}
+	if archived[0].Data["title"] != "test2" {
+		t.Fatalf("Expected the archived title to be %q, got %v", "test2", archived[0].Data["title"])
+	}
+	if archived[0].Data["created"] == nil {
+		t.Fatal("Expected the archived data to include the record created date")
+	}
+
+	found, err := app.Dao().FindArchivedRecordById(collection.Id, archived[0].Id)
+	if err != nil || found.RecordId != record2.Id {
+		t.Fatalf("Expected to find the archived record, got %v (%v)", found, err)
+	}
+
+	if _, err := app.Dao().FindArchivedRecordById("missing", archived[0].Id); err == nil {
+		t.Fatal("Expected error for archived record of another collection")
+	}
+
+	if err := app.Dao().DeleteArchivedRecord(found); err != nil {
+		t.Fatal(err)
+	}
+	if total := countArchived(); total != 0 {
+		t.Fatalf("Expected the archived record to be deleted, got %d", total)
+	}
+}
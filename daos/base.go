@@ -4,24 +4,50 @@
 package daos
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/models"
 )
 
+// DefaultCreateIdRetries is the default max number of insert retries
+// with a new auto generated id on primary key collision.
+const DefaultCreateIdRetries = 3
+
 // New creates a new Dao instance with the provided db builder.
 func New(db dbx.Builder) *Dao {
 	return &Dao{
-		db: db,
+		db:              db,
+		CreateIdRetries: DefaultCreateIdRetries,
+	}
+}
+
+// NewWithReadDB creates a new Dao instance that executes
+// its select queries through readDB and all other queries through db.
+//
+// Note that inside a transaction all queries are executed
+// through the transaction.
+func NewWithReadDB(db dbx.Builder, readDB dbx.Builder) *Dao {
+	return &Dao{
+		db:              db,
+		readDB:          readDB,
+		CreateIdRetries: DefaultCreateIdRetries,
 	}
 }
 
 // Dao handles various db operations.
 // Think of Dao as a repository and service layer in one.
 type Dao struct {
-	db dbx.Builder
+	db     dbx.Builder
+	readDB dbx.Builder
+
+	// tx holds the state of the dao transaction (nil if not a transaction dao)
+	tx *txState
 
 	BeforeCreateFunc func(eventDao *Dao, m models.Model) error
 	AfterCreateFunc  func(eventDao *Dao, m models.Model)
@@ -29,6 +55,42 @@ type Dao struct {
 	AfterUpdateFunc  func(eventDao *Dao, m models.Model)
 	BeforeDeleteFunc func(eventDao *Dao, m models.Model) error
 	AfterDeleteFunc  func(eventDao *Dao, m models.Model)
+
+	// TokenAudienceFunc is an optional func that returns the expected
+	// "aud" claim of the verified auth tokens.
+	//
+	// Tokens without "aud" claim are accepted for backward compatibility
+	// only until the LegacyTokensDeadlineFunc time.
+	TokenAudienceFunc func() string
+
+	// LegacyTokensDeadlineFunc is an optional func that returns the time
+	// after which the tokens without "aud" claim are rejected
+	// (zero time for no deadline).
+	LegacyTokensDeadlineFunc func() time.Time
+
+	// CreateIdRetries is the max number of insert retries with a new
+	// id when the auto generated id of the created model collides
+	// with an existing one (aka. primary key unique violation).
+	//
+	// Explicitly set (eg. client-supplied) ids are never regenerated.
+	CreateIdRetries int
+
+	// Retry specifies the retry options of the Dao model operations,
+	// record finders and top level transactions failed with a transient
+	// SQLite error (eg. "database is locked").
+	//
+	// The retry is disabled by default.
+	Retry RetryOptions
+
+	// TimezoneFunc returns the location used to interpret the stored
+	// UTC dates in the date bucketing queries (eg. [Dao.RequestsStats]).
+	//
+	// Defaults to UTC if not set.
+	TimezoneFunc func() *time.Location
+
+	// RecordMirrorsFunc is an optional func that returns the (eg. cached)
+	// result of [Dao.FindRecordMirrors] used when syncing the record mirrors.
+	RecordMirrorsFunc func(dao *Dao) (map[string][]*RecordMirror, error)
 }
 
 // DB returns the internal db builder (*dbx.DB or *dbx.TX).
@@ -36,22 +98,108 @@ func (dao *Dao) DB() dbx.Builder {
 	return dao.db
 }
 
+// ReadDB returns the db builder used for the Dao select queries
+// (fallbacks to DB() if there is no separate read db).
+func (dao *Dao) ReadDB() dbx.Builder {
+	if dao.readDB != nil {
+		return dao.readDB
+	}
+
+	return dao.db
+}
+
+// WithContext returns a shallow copy of the current Dao whose queries
+// are executed with the provided ctx (eg. to associate them with the
+// request tracing span or to cancel them).
+//
+// Transaction Dao instances are returned unmodified, because their
+// context is set when the transaction is started.
+func (dao *Dao) WithContext(ctx context.Context) *Dao {
+	db, ok := dao.db.(*dbx.DB)
+	if !ok {
+		return dao
+	}
+
+	clone := *dao
+	clone.db = db.WithContext(ctx)
+
+	if readDB, ok := dao.readDB.(*dbx.DB); ok {
+		clone.readDB = readDB.WithContext(ctx)
+	}
+
+	return &clone
+}
+
+// txState defines the state of a single dao transaction execution.
+type txState struct {
+	// afterCommit holds the functions that will be called
+	// after the transaction commit.
+	afterCommit []func()
+
+	// rollback holds the functions that restore the state of the models
+	// changed in the transaction (eg. the new flag of the created models)
+	// in case the transaction is rolled back.
+	rollback []func()
+
+	// sideEffects indicates that the transaction has changes outside of the
+	// db (see [Dao.MarkSideEffect]) and it is not safe to be executed again.
+	sideEffects bool
+}
+
+// AfterCommit registers fn to be called after the current dao
+// transaction is successfully committed.
+//
+// If the dao is not a transaction dao, fn is called immediately.
+// The functions registered in a rolled back transaction are discarded.
+func (dao *Dao) AfterCommit(fn func()) {
+	if dao.tx == nil {
+		fn()
+		return
+	}
+
+	dao.tx.afterCommit = append(dao.tx.afterCommit, fn)
+}
+
+// MarkSideEffect marks the current dao transaction as having changes
+// outside of the db (eg. uploaded files), so that it is not executed
+// again when it fails with a transient error (see [Dao.RunInTransaction]).
+//
+// The After* model funcs mark their transaction automatically.
+// It is no-op if the dao is not a transaction dao.
+func (dao *Dao) MarkSideEffect() {
+	if dao.tx != nil {
+		dao.tx.sideEffects = true
+	}
+}
+
 // ModelQuery creates a new query with preset Select and From fields
 // based on the provided model argument.
 func (dao *Dao) ModelQuery(m models.Model) *dbx.SelectQuery {
 	tableName := m.TableName()
-	return dao.db.Select(fmt.Sprintf("{{%s}}.*", tableName)).From(tableName)
+	return dao.ReadDB().Select(fmt.Sprintf("{{%s}}.*", tableName)).From(tableName)
 }
 
 // FindById finds a single db record with the specified id and
 // scans the result into m.
 func (dao *Dao) FindById(m models.Model, id string) error {
-	return dao.ModelQuery(m).Where(dbx.HashExp{"id": id}).Limit(1).One(m)
+	return dao.WithRetry(func() error {
+		return dao.ModelQuery(m).Where(dbx.HashExp{"id": id}).Limit(1).One(m)
+	})
 }
 
 // RunInTransaction wraps fn into a transaction.
 //
 // It is safe to nest RunInTransaction calls.
+//
+// If the retry is enabled (see [Dao.Retry]), the top level transaction
+// is rolled back and fn is executed again when it fails with a transient
+// db error, but only if the failed execution didn't have side effects
+// outside of the db (aka. it didn't call any of the After* model funcs
+// and fn didn't call [Dao.MarkSideEffect]). Other fn side effects should
+// be deferred with [Dao.AfterCommit].
+//
+// On rollback the created models are marked as new again,
+// so that they could be saved again.
 func (dao *Dao) RunInTransaction(fn func(txDao *Dao) error) error {
 	switch txOrDB := dao.db.(type) {
 	case *dbx.Tx:
@@ -59,48 +207,80 @@ func (dao *Dao) RunInTransaction(fn func(txDao *Dao) error) error {
 		// so execute the function within the current transaction
 		return fn(dao)
 	case *dbx.DB:
-		return txOrDB.Transactional(func(tx *dbx.Tx) error {
-			txDao := New(tx)
+		var state *txState
+		return dao.retry(func() error {
+			state = &txState{}
+			return dao.runInTransaction(txOrDB, state, fn)
+		}, func(err error) bool {
+			return !state.sideEffects && IsTransientError(err)
+		})
+	}
 
-			txDao.BeforeCreateFunc = func(eventDao *Dao, m models.Model) error {
-				if dao.BeforeCreateFunc != nil {
-					return dao.BeforeCreateFunc(eventDao, m)
-				}
-				return nil
+	return errors.New("Failed to start transaction (unknown dao.db)")
+}
+
+func (dao *Dao) runInTransaction(db *dbx.DB, state *txState, fn func(txDao *Dao) error) error {
+	err := db.Transactional(func(tx *dbx.Tx) error {
+		txDao := New(tx)
+		txDao.tx = state
+
+		txDao.BeforeCreateFunc = func(eventDao *Dao, m models.Model) error {
+			if dao.BeforeCreateFunc != nil {
+				return dao.BeforeCreateFunc(eventDao, m)
 			}
-			txDao.AfterCreateFunc = func(eventDao *Dao, m models.Model) {
-				if dao.AfterCreateFunc != nil {
-					dao.AfterCreateFunc(eventDao, m)
-				}
+			return nil
+		}
+		txDao.AfterCreateFunc = func(eventDao *Dao, m models.Model) {
+			if dao.AfterCreateFunc != nil {
+				state.sideEffects = true
+				dao.AfterCreateFunc(eventDao, m)
 			}
-			txDao.BeforeUpdateFunc = func(eventDao *Dao, m models.Model) error {
-				if dao.BeforeUpdateFunc != nil {
-					return dao.BeforeUpdateFunc(eventDao, m)
-				}
-				return nil
+		}
+		txDao.BeforeUpdateFunc = func(eventDao *Dao, m models.Model) error {
+			if dao.BeforeUpdateFunc != nil {
+				return dao.BeforeUpdateFunc(eventDao, m)
 			}
-			txDao.AfterUpdateFunc = func(eventDao *Dao, m models.Model) {
-				if dao.AfterUpdateFunc != nil {
-					dao.AfterUpdateFunc(eventDao, m)
-				}
+			return nil
+		}
+		txDao.AfterUpdateFunc = func(eventDao *Dao, m models.Model) {
+			if dao.AfterUpdateFunc != nil {
+				state.sideEffects = true
+				dao.AfterUpdateFunc(eventDao, m)
 			}
-			txDao.BeforeDeleteFunc = func(eventDao *Dao, m models.Model) error {
-				if dao.BeforeDeleteFunc != nil {
-					return dao.BeforeDeleteFunc(eventDao, m)
-				}
-				return nil
+		}
+		txDao.BeforeDeleteFunc = func(eventDao *Dao, m models.Model) error {
+			if dao.BeforeDeleteFunc != nil {
+				return dao.BeforeDeleteFunc(eventDao, m)
 			}
-			txDao.AfterDeleteFunc = func(eventDao *Dao, m models.Model) {
-				if dao.AfterDeleteFunc != nil {
-					dao.AfterDeleteFunc(eventDao, m)
-				}
+			return nil
+		}
+		txDao.AfterDeleteFunc = func(eventDao *Dao, m models.Model) {
+			if dao.AfterDeleteFunc != nil {
+				state.sideEffects = true
+				dao.AfterDeleteFunc(eventDao, m)
 			}
+		}
+		txDao.TokenAudienceFunc = dao.TokenAudienceFunc
+		txDao.LegacyTokensDeadlineFunc = dao.LegacyTokensDeadlineFunc
+		txDao.CreateIdRetries = dao.CreateIdRetries
+		txDao.TimezoneFunc = dao.TimezoneFunc
+		txDao.RecordMirrorsFunc = dao.RecordMirrorsFunc
+		txDao.Retry = dao.Retry
+
+		return fn(txDao)
+	})
+	if err != nil {
+		for i := len(state.rollback) - 1; i >= 0; i-- {
+			state.rollback[i]()
+		}
+		return err
+	}
 
-			return fn(txDao)
-		})
+	for _, fn := range state.afterCommit {
+		fn()
 	}
 
-	return errors.New("Failed to start transaction (unknown dao.db)")
+	return nil
 }
 
 // Delete deletes the provided model.
@@ -115,7 +295,9 @@ func (dao *Dao) Delete(m models.Model) error {
 		}
 	}
 
-	deleteErr := dao.db.Model(m).Delete()
+	deleteErr := dao.WithRetry(func() error {
+		return dao.db.Model(m).Delete()
+	})
 	if deleteErr != nil {
 		return deleteErr
 	}
@@ -127,13 +309,38 @@ func (dao *Dao) Delete(m models.Model) error {
 	return nil
 }
 
-// Save upserts (update or create if primary key is not set) the provided model.
+// Save upserts the provided model (create if the model is new, see [models.Model.IsNew]).
 func (dao *Dao) Save(m models.Model) error {
-	if m.HasId() {
-		return dao.update(m)
+	if m.IsNew() {
+		var regenerateId func() error
+		if !m.HasId() {
+			regenerateId = func() error {
+				m.RefreshId()
+				return nil
+			}
+		}
+
+		return dao.create(m, regenerateId)
+	}
+
+	return dao.update(m)
+}
+
+// CreateWithIdGenerator creates the provided new model with an id
+// set by generateId, calling it again to set a new id if the insert
+// fails due to id collision (up to dao.CreateIdRetries times).
+//
+// generateId is expected to replace the current model id.
+func (dao *Dao) CreateWithIdGenerator(m models.Model, generateId func() error) error {
+	if err := generateId(); err != nil {
+		return err
 	}
 
-	return dao.create(m)
+	if !m.HasId() {
+		return errors.New("The id generator didn't set a model id.")
+	}
+
+	return dao.create(m, generateId)
 }
 
 func (dao *Dao) update(m models.Model) error {
@@ -149,23 +356,21 @@ func (dao *Dao) update(m models.Model) error {
 		}
 	}
 
-	if v, ok := any(m).(models.ColumnValueMapper); ok {
-		dataMap := v.ColumnValueMap()
-
-		_, err := dao.db.Update(
-			m.TableName(),
-			dataMap,
-			dbx.HashExp{"id": m.GetId()},
-		).Execute()
+	err := dao.WithRetry(func() error {
+		if v, ok := any(m).(models.ColumnValueMapper); ok {
+			_, err := dao.db.Update(
+				m.TableName(),
+				v.ColumnValueMap(),
+				dbx.HashExp{"id": m.GetId()},
+			).Execute()
 
-		if err != nil {
-			return err
-		}
-	} else {
-		err := dao.db.Model(m).Update()
-		if err != nil {
 			return err
 		}
+
+		return dao.db.Model(m).Update()
+	})
+	if err != nil {
+		return err
 	}
 
 	if dao.AfterUpdateFunc != nil {
@@ -175,7 +380,13 @@ func (dao *Dao) update(m models.Model) error {
 	return nil
 }
 
-func (dao *Dao) create(m models.Model) error {
+// create inserts the provided model.
+//
+// regenerateId is an optional func that sets a new model id
+// before retrying the insert on primary key collision.
+// The before create func is called again for each retry
+// so that it always receives the actually inserted model id.
+func (dao *Dao) create(m models.Model, regenerateId func() error) error {
 	if !m.HasId() {
 		// auto generate id
 		m.RefreshId()
@@ -189,29 +400,107 @@ func (dao *Dao) create(m models.Model) error {
 		m.RefreshUpdated()
 	}
 
-	if dao.BeforeCreateFunc != nil {
-		if err := dao.BeforeCreateFunc(dao, m); err != nil {
-			return err
+	for attempt := 0; ; attempt++ {
+		if dao.BeforeCreateFunc != nil {
+			if err := dao.BeforeCreateFunc(dao, m); err != nil {
+				return err
+			}
 		}
-	}
 
-	if v, ok := any(m).(models.ColumnValueMapper); ok {
-		dataMap := v.ColumnValueMap()
+		err := dao.insert(m)
+		if err == nil {
+			break
+		}
 
-		_, err := dao.db.Insert(m.TableName(), dataMap).Execute()
-		if err != nil {
+		if regenerateId == nil || attempt >= dao.CreateIdRetries || !IsIdCollision(err, m.TableName()) {
 			return err
 		}
-	} else {
-		err := dao.db.Model(m).Insert()
-		if err != nil {
+
+		if err := regenerateId(); err != nil {
 			return err
 		}
 	}
 
+	// clears the explicit new flag (if any)
+	m.MarkAsNotNew()
+
+	// restore the new state if the insert is rolled back
+	// (the model keeps its id, so it is created with it on the next save)
+	if dao.tx != nil {
+		dao.tx.rollback = append(dao.tx.rollback, m.MarkAsNew)
+	}
+
 	if dao.AfterCreateFunc != nil {
 		dao.AfterCreateFunc(dao, m)
 	}
 
 	return nil
 }
+
+func (dao *Dao) insert(m models.Model) error {
+	return dao.WithRetry(func() error {
+		if v, ok := any(m).(models.ColumnValueMapper); ok {
+			_, err := dao.db.Insert(m.TableName(), v.ColumnValueMap()).Execute()
+			return err
+		}
+
+		return dao.db.Model(m).Insert()
+	})
+}
+
+// IsIdCollision checks whether the provided insert error is
+// a primary key unique violation of the specified table.
+//
+// Only the single "tableName.id" column violation is matched
+// (eg. "UNIQUE constraint failed: demo.id", optionally followed
+// by the driver extended error code like " (2067)").
+func IsIdCollision(err error, tableName string) bool {
+	if err == nil {
+		return false
+	}
+
+	const prefix = "UNIQUE constraint failed: "
+
+	msg := err.Error()
+
+	i := strings.LastIndex(msg, prefix)
+	if i < 0 {
+		return false
+	}
+
+	columns := msg[i+len(prefix):]
+	if j := strings.Index(columns, " ("); j >= 0 {
+		columns = columns[:j]
+	}
+
+	return columns == tableName+".id"
+}
+
+// checkTokenAudience checks whether the provided verified token claims
+// match with the dao expected tokens audience (if any).
+func (dao *Dao) checkTokenAudience(claims jwt.MapClaims) error {
+	if dao.TokenAudienceFunc == nil {
+		return nil
+	}
+
+	aud := dao.TokenAudienceFunc()
+	if aud == "" {
+		return nil
+	}
+
+	if _, ok := claims["aud"]; !ok {
+		if dao.LegacyTokensDeadlineFunc != nil {
+			if deadline := dao.LegacyTokensDeadlineFunc(); !deadline.IsZero() && time.Now().After(deadline) {
+				return errors.New("Tokens without audience are no longer accepted.")
+			}
+		}
+
+		return nil
+	}
+
+	if !claims.VerifyAudience(aud, true) {
+		return errors.New("Invalid token audience.")
+	}
+
+	return nil
+}
@@ -1,9 +1,11 @@
 package daos_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 
+	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/daos"
 	"github.com/pocketbase/pocketbase/models"
 	"github.com/pocketbase/pocketbase/tests"
@@ -20,6 +22,76 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestNewWithReadDB(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	dao := daos.NewWithReadDB(testApp.DB(), testApp.LogsDB())
+
+	if dao.DB() != testApp.DB() {
+		t.Fatal("Expected DB() to be the write db")
+	}
+
+	if dao.ReadDB() != testApp.LogsDB() {
+		t.Fatal("Expected ReadDB() to be the read db")
+	}
+
+	// the select queries are executed through the read db
+	// (the logs db has the requests table)
+	total := 0
+	if err := dao.ModelQuery(&models.Request{}).Select("count(*)").Row(&total); err != nil {
+		t.Fatal(err)
+	}
+
+	// fallback to the write db
+	dao2 := daos.New(testApp.DB())
+	if dao2.ReadDB() != testApp.DB() {
+		t.Fatal("Expected ReadDB() to fallback to DB()")
+	}
+}
+
+func TestDaoWithContext(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "test")
+
+	dao := daos.NewWithReadDB(testApp.DB(), testApp.LogsDB())
+	dao.CreateIdRetries = 5
+
+	ctxDao := dao.WithContext(ctx)
+
+	if ctxDao == dao {
+		t.Fatal("Expected a new Dao instance")
+	}
+
+	if ctxDao.CreateIdRetries != 5 {
+		t.Fatalf("Expected the Dao options to be copied, got %d", ctxDao.CreateIdRetries)
+	}
+
+	if db, _ := ctxDao.DB().(*dbx.DB); db == nil || db.Context() != ctx {
+		t.Fatal("Expected the db to be associated with the context")
+	}
+
+	if db, _ := ctxDao.ReadDB().(*dbx.DB); db == nil || db.Context() != ctx {
+		t.Fatal("Expected the read db to be associated with the context")
+	}
+
+	// the original dao shouldn't be modified
+	if dao.DB() != testApp.DB() || testApp.DB().Context() != nil {
+		t.Fatal("Expected the original db to be unmodified")
+	}
+
+	// transaction daos are returned as they are
+	dao.RunInTransaction(func(txDao *daos.Dao) error {
+		if txDao.WithContext(ctx) != txDao {
+			t.Fatal("Expected the transaction Dao to be returned unmodified")
+		}
+		return nil
+	})
+}
+
 func TestDaoModelQuery(t *testing.T) {
 	testApp, _ := tests.NewTestApp()
 	defer testApp.Cleanup()
@@ -132,6 +204,48 @@ func TestDaoRunInTransaction(t *testing.T) {
 	}
 }
 
+func TestDaoAfterCommit(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	// non transaction dao
+	calls := 0
+	testApp.Dao().AfterCommit(func() { calls++ })
+	if calls != 1 {
+		t.Fatalf("Expected the func to be called immediately, got %d calls", calls)
+	}
+
+	// committed transaction
+	calls = 0
+	testApp.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+		txDao.AfterCommit(func() { calls++ })
+
+		// nested transactions share the parent transaction
+		return txDao.RunInTransaction(func(tx2Dao *daos.Dao) error {
+			tx2Dao.AfterCommit(func() { calls++ })
+
+			if calls != 0 {
+				t.Fatalf("Expected the funcs to not be called before the commit, got %d calls", calls)
+			}
+
+			return nil
+		})
+	})
+	if calls != 2 {
+		t.Fatalf("Expected the funcs to be called after the commit, got %d calls", calls)
+	}
+
+	// rolled back transaction
+	calls = 0
+	testApp.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+		txDao.AfterCommit(func() { calls++ })
+		return errors.New("test")
+	})
+	if calls != 0 {
+		t.Fatalf("Expected the funcs to not be called on rollback, got %d calls", calls)
+	}
+}
+
 func TestDaoSaveCreate(t *testing.T) {
 	testApp, _ := tests.NewTestApp()
 	defer testApp.Cleanup()
@@ -158,6 +272,170 @@ func TestDaoSaveCreate(t *testing.T) {
 	}
 }
 
+func TestDaoSaveCreateWithId(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	model := &models.Admin{}
+	model.Id = "test_custom_id"
+	model.Email = "test_new@example.com"
+	model.MarkAsNew()
+	if err := testApp.Dao().Save(model); err != nil {
+		t.Fatal(err)
+	}
+
+	if model.IsNew() {
+		t.Fatal("Expected the model to not be new after save")
+	}
+
+	// refresh
+	model, err := testApp.Dao().FindAdminById("test_custom_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if model.Email != "test_new@example.com" {
+		t.Fatalf("Expected model email to be test_new@example.com, got %v", model.Email)
+	}
+
+	if v := testApp.EventCalls["OnModelAfterCreate"]; v != 1 {
+		t.Fatalf("Expected OnModelAfterCreate to be called exactly one time, got %d", v)
+	}
+}
+
+// collidingAdmin is an Admin model with predefined "auto generated" ids.
+type collidingAdmin struct {
+	models.Admin
+
+	ids []string
+}
+
+func (m *collidingAdmin) RefreshId() {
+	m.Id = m.ids[0]
+	m.ids = m.ids[1:]
+}
+
+func TestDaoSaveCreateIdCollisionRetry(t *testing.T) {
+	const existingId = "2b4a97cc-3f83-4d01-a26b-3d77bc842d3c"
+
+	scenarios := []struct {
+		name        string
+		retries     int
+		explicitId  string
+		ids         []string
+		expectError bool
+		expectedId  string
+	}{
+		{"without retries", 0, "", []string{existingId, "new_id"}, true, ""},
+		{"exceeded retries", 1, "", []string{existingId, existingId, "new_id"}, true, ""},
+		{"successful retry", 2, "", []string{existingId, existingId, "new_id"}, false, "new_id"},
+		{"explicit id", 2, existingId, []string{"new_id"}, true, ""},
+	}
+
+	for _, s := range scenarios {
+		testApp, _ := tests.NewTestApp()
+
+		dao := testApp.Dao()
+		dao.CreateIdRetries = s.retries
+
+		model := &collidingAdmin{ids: s.ids}
+		model.Email = "test_new@example.com"
+		if s.explicitId != "" {
+			model.Id = s.explicitId
+			model.MarkAsNew()
+		}
+
+		beforeCreateIds := []string{}
+		dao.BeforeCreateFunc = func(eventDao *daos.Dao, m models.Model) error {
+			beforeCreateIds = append(beforeCreateIds, m.GetId())
+			return nil
+		}
+
+		err := dao.Save(model)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("[%s] Expected hasErr %v, got %v (%v)", s.name, s.expectError, hasErr, err)
+		}
+
+		if !hasErr {
+			if model.Id != s.expectedId {
+				t.Errorf("[%s] Expected id %q, got %q", s.name, s.expectedId, model.Id)
+			}
+
+			if _, err := dao.FindAdminById(s.expectedId); err != nil {
+				t.Errorf("[%s] Expected the model to be created, got %v", s.name, err)
+			}
+
+			// the before create func should receive the inserted id
+			if last := beforeCreateIds[len(beforeCreateIds)-1]; last != s.expectedId {
+				t.Errorf("[%s] Expected the last before create id %q, got %q", s.name, s.expectedId, last)
+			}
+		}
+
+		testApp.Cleanup()
+	}
+}
+
+func TestIsIdCollision(t *testing.T) {
+	scenarios := []struct {
+		err      error
+		expected bool
+	}{
+		{nil, false},
+		{errors.New("test"), false},
+		{errors.New("UNIQUE constraint failed: demo.id"), true},
+		{errors.New("constraint failed: UNIQUE constraint failed: demo.id (2067)"), true},
+		{errors.New("UNIQUE constraint failed: demo.identifier"), false},
+		{errors.New("UNIQUE constraint failed: demo.id, demo.title"), false},
+		{errors.New("UNIQUE constraint failed: demo2.id"), false},
+		{errors.New("UNIQUE constraint failed: other_demo.id"), false},
+		{errors.New("NOT NULL constraint failed: demo.id"), false},
+	}
+
+	for i, s := range scenarios {
+		if result := daos.IsIdCollision(s.err, "demo"); result != s.expected {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, result)
+		}
+	}
+}
+
+func TestDaoCreateWithIdGenerator(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	ids := []string{"2b4a97cc-3f83-4d01-a26b-3d77bc842d3c", "new_id"}
+
+	model := &models.Admin{}
+	model.Email = "test_new@example.com"
+
+	err := testApp.Dao().CreateWithIdGenerator(model, func() error {
+		model.Id = ids[0]
+		ids = ids[1:]
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if model.Id != "new_id" {
+		t.Fatalf("Expected id %q, got %q", "new_id", model.Id)
+	}
+
+	if v := testApp.EventCalls["OnModelAfterCreate"]; v != 1 {
+		t.Fatalf("Expected OnModelAfterCreate to be called exactly one time, got %d", v)
+	}
+
+	// generator error
+	genErr := errors.New("test")
+	err = testApp.Dao().CreateWithIdGenerator(&models.Admin{}, func() error {
+		return genErr
+	})
+	if err != genErr {
+		t.Fatalf("Expected the generator error, got %v", err)
+	}
+}
+
 func TestDaoSaveUpdate(t *testing.T) {
 	testApp, _ := tests.NewTestApp()
 	defer testApp.Cleanup()
@@ -1,6 +1,7 @@
 package daos
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"strings"
@@ -19,13 +20,15 @@ func (dao *Dao) CollectionQuery() *dbx.SelectQuery {
 func (dao *Dao) FindCollectionByNameOrId(nameOrId string) (*models.Collection, error) {
 	model := &models.Collection{}
 
-	err := dao.CollectionQuery().
-		AndWhere(dbx.Or(
-			dbx.HashExp{"id": nameOrId},
-			dbx.HashExp{"name": nameOrId},
-		)).
-		Limit(1).
-		One(model)
+	err := dao.WithRetry(func() error {
+		return dao.CollectionQuery().
+			AndWhere(dbx.Or(
+				dbx.HashExp{"id": nameOrId},
+				dbx.HashExp{"name": nameOrId},
+			)).
+			Limit(1).
+			One(model)
+	})
 
 	if err != nil {
 		return nil, err
@@ -132,12 +135,20 @@ func (dao *Dao) DeleteCollection(collection *models.Collection) error {
 			return err
 		}
 
+		if err := txDao.deleteCollectionRecordVersions(collection.Id); err != nil {
+			return err
+		}
+
 		return txDao.Delete(collection)
 	})
 }
 
 // SaveCollection upserts the provided Collection model and updates
 // its related records table schema.
+//
+// If the collection has a preset id that doesn't exist yet,
+// a new collection with that id is created
+// (eg. when replaying migrations generated from another environment).
 func (dao *Dao) SaveCollection(collection *models.Collection) error {
 	var oldCollection *models.Collection
 
@@ -146,15 +157,21 @@ func (dao *Dao) SaveCollection(collection *models.Collection) error {
 		// note: the select is outside of the transaction to prevent SQLITE_LOCKED error when mixing read&write in a single transaction
 		var findErr error
 		oldCollection, findErr = dao.FindCollectionByNameOrId(collection.Id)
-		if findErr != nil {
+		if findErr != nil && !errors.Is(findErr, sql.ErrNoRows) {
 			return findErr
 		}
 	}
 
 	return dao.RunInTransaction(func(txDao *Dao) error {
 		// persist the collection model
-		if err := txDao.Save(collection); err != nil {
-			return err
+		var saveErr error
+		if oldCollection == nil {
+			saveErr = txDao.create(collection, nil)
+		} else {
+			saveErr = txDao.Save(collection)
+		}
+		if saveErr != nil {
+			return saveErr
 		}
 
 		// sync the changes with the related records table
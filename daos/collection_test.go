@@ -213,6 +213,39 @@ func TestSaveCollectionCreate(t *testing.T) {
 	}
 }
 
+func TestSaveCollectionCreateWithId(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := &models.Collection{
+		Name: "new_test",
+		Schema: schema.NewSchema(
+			&schema.SchemaField{
+				Type: schema.FieldTypeText,
+				Name: "test",
+			},
+		),
+	}
+	collection.Id = "00000000-0000-0000-0000-000000000001"
+
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := app.Dao().FindCollectionByNameOrId(collection.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if found.Name != collection.Name {
+		t.Fatalf("Expected collection %s, got %s", collection.Name, found.Name)
+	}
+
+	if !app.Dao().HasTable(collection.Name) {
+		t.Fatalf("Expected records table %s to be created", collection.Name)
+	}
+}
+
 func TestSaveCollectionUpdate(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -0,0 +1,190 @@
+package daos_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestExternalAuthQuery(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	expected := "SELECT {{_externalAuths}}.* FROM `_externalAuths`"
+
+	sql := app.Dao().ExternalAuthQuery().Build().SQL()
+	if sql != expected {
+		t.Errorf("Expected sql %s, got %s", expected, sql)
+	}
+}
+
+func TestFindAllExternalAuthsByUserId(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		userId      string
+		expectedIds []string
+	}{
+		{"", []string{}},
+		{"missing", []string{}},
+		{"97cc3d3d-6ba2-383f-b42a-7bc84d27410c", []string{}},
+		{"4d0197cc-2b4a-3f83-a26b-d77bc8423d3c", []string{"abcdefghijklmn1", "abcdefghijklmn2"}},
+	}
+
+	for i, s := range scenarios {
+		auths, err := app.Dao().FindAllExternalAuthsByUserId(s.userId)
+		if err != nil {
+			t.Errorf("(%d) Unexpected error %v", i, err)
+			continue
+		}
+
+		if len(auths) != len(s.expectedIds) {
+			t.Errorf("(%d) Expected %d auths, got %d", i, len(s.expectedIds), len(auths))
+			continue
+		}
+
+		for j, id := range s.expectedIds {
+			if auths[j].Id != id {
+				t.Errorf("(%d) Expected auth %d to be %q, got %q", i, j, id, auths[j].Id)
+			}
+		}
+	}
+}
+
+func TestFindExternalAuthByProvider(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		provider   string
+		providerId string
+		expectedId string
+	}{
+		{"", "", ""},
+		{"github", "", ""},
+		{"github", "test123", ""},
+		{"google", "test456", ""},
+		{"google", "test123", "abcdefghijklmn1"},
+		{"gitlab", "456", "abcdefghijklmn2"},
+	}
+
+	for i, s := range scenarios {
+		auth, err := app.Dao().FindExternalAuthByProvider(s.provider, s.providerId)
+
+		hasErr := err != nil
+		expectErr := s.expectedId == ""
+		if hasErr != expectErr {
+			t.Errorf("(%d) Expected hasErr %v, got %v", i, expectErr, err)
+			continue
+		}
+
+		if auth != nil && auth.Id != s.expectedId {
+			t.Errorf("(%d) Expected external auth %q, got %q", i, s.expectedId, auth.Id)
+		}
+	}
+}
+
+func TestFindExternalAuthByUserIdAndProvider(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		userId     string
+		provider   string
+		expectedId string
+	}{
+		{"", "", ""},
+		{"", "google", ""},
+		{"4d0197cc-2b4a-3f83-a26b-d77bc8423d3c", "github", ""},
+		{"4d0197cc-2b4a-3f83-a26b-d77bc8423d3c", "google", "abcdefghijklmn1"},
+		{"7bc84d27-6ba2-b42a-383f-4197cc3d3d0c", "google", "abcdefghijklmn3"},
+	}
+
+	for i, s := range scenarios {
+		auth, err := app.Dao().FindExternalAuthByUserIdAndProvider(s.userId, s.provider)
+
+		hasErr := err != nil
+		expectErr := s.expectedId == ""
+		if hasErr != expectErr {
+			t.Errorf("(%d) Expected hasErr %v, got %v", i, expectErr, err)
+			continue
+		}
+
+		if auth != nil && auth.Id != s.expectedId {
+			t.Errorf("(%d) Expected external auth %q, got %q", i, s.expectedId, auth.Id)
+		}
+	}
+}
+
+func TestSaveExternalAuth(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// missing required fields
+	if err := app.Dao().SaveExternalAuth(&models.ExternalAuth{}); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	// duplicated provider identity
+	dup := &models.ExternalAuth{
+		UserId:     "97cc3d3d-6ba2-383f-b42a-7bc84d27410c",
+		Provider:   "google",
+		ProviderId: "test123",
+	}
+	if err := app.Dao().SaveExternalAuth(dup); err == nil {
+		t.Fatal("Expected unique constraint error, got nil")
+	}
+
+	auth := &models.ExternalAuth{
+		UserId:     "97cc3d3d-6ba2-383f-b42a-7bc84d27410c",
+		Provider:   "google",
+		ProviderId: "test999",
+	}
+	if err := app.Dao().SaveExternalAuth(auth); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := app.Dao().FindExternalAuthByProvider("google", "test999")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if found.Id != auth.Id || found.UserId != auth.UserId {
+		t.Fatalf("Expected %v, got %v", auth, found)
+	}
+}
+
+func TestDeleteExternalAuth(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	auth, _ := app.Dao().FindExternalAuthByProvider("google", "test123")
+	if auth == nil {
+		t.Fatal("Expected to find the test external auth")
+	}
+
+	if err := app.Dao().DeleteExternalAuth(auth); err != nil {
+		t.Fatal(err)
+	}
+
+	if found, _ := app.Dao().FindExternalAuthByProvider("google", "test123"); found != nil {
+		t.Fatalf("Expected the external auth to be deleted, got %v", found)
+	}
+}
+
+func TestDeleteUserCascadeExternalAuths(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, _ := app.Dao().FindUserById("4d0197cc-2b4a-3f83-a26b-d77bc8423d3c")
+	if err := app.Dao().DeleteUser(user); err != nil {
+		t.Fatal(err)
+	}
+
+	auths, _ := app.Dao().FindAllExternalAuthsByUserId(user.Id)
+	if len(auths) != 0 {
+		t.Fatalf("Expected the user external auths to be deleted, got %v", auths)
+	}
+}
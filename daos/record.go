@@ -1,8 +1,10 @@
 package daos
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/pocketbase/dbx"
@@ -10,14 +12,48 @@ import (
 	"github.com/pocketbase/pocketbase/models/schema"
 	"github.com/pocketbase/pocketbase/tools/list"
 	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
 )
 
 // RecordQuery returns a new Record select query.
 func (dao *Dao) RecordQuery(collection *models.Collection) *dbx.SelectQuery {
 	tableName := collection.Name
-	selectCols := fmt.Sprintf("%s.*", dao.DB().QuoteSimpleColumnName(tableName))
+	selectCols := fmt.Sprintf("%s.*", dao.ReadDB().QuoteSimpleColumnName(tableName))
 
-	return dao.DB().Select(selectCols).From(tableName)
+	return dao.ReadDB().Select(selectCols).From(tableName)
+}
+
+// EstimateRecordsCount returns the estimated number of the collection
+// records based on the SQLite stat tables (without a full table scan).
+//
+// Returns an error if the collection table has no collected
+// statistics (see https://www.sqlite.org/lang_analyze.html).
+func (dao *Dao) EstimateRecordsCount(collection *models.Collection) (int64, error) {
+	stats := []string{}
+
+	err := dao.ReadDB().Select("stat").
+		From("sqlite_stat1").
+		Where(dbx.HashExp{"tbl": collection.Name}).
+		Column(&stats)
+	if err != nil {
+		return 0, err
+	}
+
+	// the first stat integer is the approximate number of the
+	// table rows (or of the index rows in case of a partial index)
+	var result int64 = -1
+	for _, stat := range stats {
+		first, _, _ := strings.Cut(stat, " ")
+		if n, err := strconv.ParseInt(first, 10, 64); err == nil && n > result {
+			result = n
+		}
+	}
+
+	if result < 0 {
+		return 0, errors.New("Missing collection table statistics.")
+	}
+
+	return result, nil
 }
 
 // FindRecordById finds the Record model by its id.
@@ -38,7 +74,51 @@ func (dao *Dao) FindRecordById(
 	}
 
 	row := dbx.NullStringMap{}
-	if err := query.Limit(1).One(row); err != nil {
+	err := dao.WithRetry(func() error {
+		return query.Limit(1).One(row)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return models.NewRecordFromNullStringMap(collection, row), nil
+}
+
+// FindRecordBySlug finds the first Record model with the provided
+// value in any of the collection slug fields.
+//
+// Returns [sql.ErrNoRows] if the collection doesn't have slug fields.
+func (dao *Dao) FindRecordBySlug(
+	collection *models.Collection,
+	slug string,
+	filter func(q *dbx.SelectQuery) error,
+) (*models.Record, error) {
+	tableName := collection.Name
+
+	var exprs []dbx.Expression
+	for _, field := range collection.Schema.Fields() {
+		if field.Type == schema.FieldTypeSlug {
+			exprs = append(exprs, dbx.HashExp{tableName + "." + field.Name: slug})
+		}
+	}
+
+	if len(exprs) == 0 || slug == "" {
+		return nil, sql.ErrNoRows
+	}
+
+	query := dao.RecordQuery(collection).AndWhere(dbx.Or(exprs...))
+
+	if filter != nil {
+		if err := filter(query); err != nil {
+			return nil, err
+		}
+	}
+
+	row := dbx.NullStringMap{}
+	err := dao.WithRetry(func() error {
+		return query.Limit(1).One(row)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -64,7 +144,11 @@ func (dao *Dao) FindRecordsByIds(
 	}
 
 	rows := []dbx.NullStringMap{}
-	if err := query.All(&rows); err != nil {
+	err := dao.WithRetry(func() error {
+		rows = rows[:0] // All appends to the slice
+		return query.All(&rows)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -84,9 +168,12 @@ func (dao *Dao) FindRecordsByExpr(collection *models.Collection, expr dbx.Expres
 
 	rows := []dbx.NullStringMap{}
 
-	err := dao.RecordQuery(collection).
-		AndWhere(expr).
-		All(&rows)
+	err := dao.WithRetry(func() error {
+		rows = rows[:0] // All appends to the slice
+		return dao.RecordQuery(collection).
+			AndWhere(expr).
+			All(&rows)
+	})
 
 	if err != nil {
 		return nil, err
@@ -100,10 +187,12 @@ func (dao *Dao) FindRecordsByExpr(collection *models.Collection, expr dbx.Expres
 func (dao *Dao) FindFirstRecordByData(collection *models.Collection, key string, value any) (*models.Record, error) {
 	row := dbx.NullStringMap{}
 
-	err := dao.RecordQuery(collection).
-		AndWhere(dbx.HashExp{key: value}).
-		Limit(1).
-		One(row)
+	err := dao.WithRetry(func() error {
+		return dao.RecordQuery(collection).
+			AndWhere(dbx.HashExp{key: value}).
+			Limit(1).
+			One(row)
+	})
 
 	if err != nil {
 		return nil, err
@@ -136,6 +225,14 @@ func (dao *Dao) IsRecordValueUnique(
 		normalizedVal = val
 	}
 
+	// decimals are stored as scaled integers
+	if field := collection.Schema.GetFieldByName(key); field != nil && field.Type == schema.FieldTypeDecimal {
+		field.InitOptions()
+		if options, _ := field.Options.(*schema.DecimalOptions); options != nil {
+			normalizedVal, _ = options.Scaled(value)
+		}
+	}
+
 	err := dao.RecordQuery(collection).
 		Select("count(*)").
 		AndWhere(dbx.Not(dbx.HashExp{"id": excludeId})).
@@ -188,8 +285,122 @@ func (dao *Dao) FindUserRelatedRecords(user *models.User) ([]*models.Record, err
 }
 
 // SaveRecord upserts the provided Record model.
+//
+// If the record collection has enabled versions option, the previous
+// state of an updated record is stored (see [Dao.SaveRecordBy]).
 func (dao *Dao) SaveRecord(record *models.Record) error {
-	return dao.Save(record)
+	return dao.SaveRecordBy(record, "")
+}
+
+// SaveRecordBy is similar to [Dao.SaveRecord] but also stores the
+// provided update client identifier (eg. "admin:ID") in the new
+// record version (if the record collection has enabled versions option).
+func (dao *Dao) SaveRecordBy(record *models.Record, updatedBy string) error {
+	var err error
+	if !record.IsNew() && record.Collection().Options.Versions {
+		err = dao.RunInTransaction(func(txDao *Dao) error {
+			return txDao.saveRecordWithVersion(record, updatedBy)
+		})
+	} else {
+		err = dao.Save(record)
+	}
+	if err != nil {
+		return err
+	}
+
+	// the persisted data becomes the record original state
+	record.RefreshOriginalData()
+
+	return nil
+}
+
+// CreateRecordWithIdGenerator creates the provided new Record model
+// with an id set by generateId (see [Dao.CreateWithIdGenerator]).
+func (dao *Dao) CreateRecordWithIdGenerator(record *models.Record, generateId func() error) error {
+	if err := dao.CreateWithIdGenerator(record, generateId); err != nil {
+		return err
+	}
+
+	// the persisted data becomes the record original state
+	record.RefreshOriginalData()
+
+	return nil
+}
+
+// UpsertRecord updates the collection record matching the values of the
+// specified uniqueFields (eg. "external_id") or creates a new one if there
+// is no such record.
+//
+// values must contain all uniqueFields and could contain only collection
+// schema fields or "id". The lookup and the save are executed in a single
+// transaction and the regular create or update model hooks are triggered.
+//
+// Note that the uniqueFields are expected to have a unique constraint
+// (or unique index), otherwise an error is returned if the lookup
+// matches more than one record.
+func (dao *Dao) UpsertRecord(collection *models.Collection, uniqueFields []string, values map[string]any) (*models.Record, error) {
+	if len(uniqueFields) == 0 {
+		return nil, errors.New("Missing upsert unique fields.")
+	}
+
+	for key := range values {
+		if key != schema.ReservedFieldNameId && collection.Schema.GetFieldByName(key) == nil {
+			return nil, fmt.Errorf("Unknown collection %q field %q.", collection.Name, key)
+		}
+	}
+
+	// normalize the lookup values the same way as when persisted
+	lookup := dbx.HashExp{}
+	for _, name := range uniqueFields {
+		value, ok := values[name]
+		if !ok {
+			return nil, fmt.Errorf("Missing unique field %q value.", name)
+		}
+
+		if field := collection.Schema.GetFieldByName(name); field != nil {
+			value = field.PrepareValue(value)
+		}
+
+		lookup[name] = value
+	}
+
+	var record *models.Record
+
+	txErr := dao.RunInTransaction(func(txDao *Dao) error {
+		rows := []dbx.NullStringMap{}
+		if err := txDao.RecordQuery(collection).AndWhere(lookup).Limit(2).All(&rows); err != nil {
+			return err
+		}
+
+		switch len(rows) {
+		case 0:
+			record = models.NewRecord(collection)
+		case 1:
+			record = models.NewRecordFromNullStringMap(collection, rows[0])
+		default:
+			return fmt.Errorf("Found more than one %q record matching the unique fields %v.", collection.Name, uniqueFields)
+		}
+
+		for key, value := range values {
+			if key == schema.ReservedFieldNameId {
+				// the id could be only set on create
+				if !record.HasId() {
+					record.Id = cast.ToString(value)
+					record.MarkAsNew()
+				}
+				continue
+			}
+			record.SetDataValue(key, value)
+		}
+
+		return txDao.SaveRecord(record)
+	})
+
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	return record, nil
 }
 
 // DeleteRecord deletes the provided Record model.
@@ -199,7 +410,17 @@ func (dao *Dao) SaveRecord(record *models.Record) error {
 //
 // The delete operation may fail if the record is part of a required
 // reference in another record (aka. cannot be deleted or set to NULL).
+//
+// If the record collection has enabled archive option, a snapshot
+// of the record is stored before its deletion (see [Dao.DeleteRecordBy]).
 func (dao *Dao) DeleteRecord(record *models.Record) error {
+	return dao.DeleteRecordBy(record, "")
+}
+
+// DeleteRecordBy is similar to [Dao.DeleteRecord] but also stores
+// the provided delete client identifier (eg. "admin:ID") in the
+// archived records snapshots (including the cascade deleted ones).
+func (dao *Dao) DeleteRecordBy(record *models.Record, deletedBy string) error {
 	// check for references
 	// note: the select is outside of the transaction to prevent SQLITE_LOCKED error when mixing read&write in a single transaction
 	refs, err := dao.FindCollectionReferences(record.Collection(), "")
@@ -218,10 +439,17 @@ func (dao *Dao) DeleteRecord(record *models.Record) error {
 
 				rows := []dbx.NullStringMap{}
 
+				// single relations are stored as plain id strings
+				// (the exact match could also use the relation field index)
+				var refExpr dbx.Expression = dbx.Like(field.Name, record.Id).Match(true, true)
+				if options != nil && options.MaxSelect <= 1 {
+					refExpr = dbx.HashExp{field.Name: record.Id}
+				}
+
 				// note: the select is not using the transaction dao to prevent SQLITE_LOCKED error when mixing read&write in a single transaction
 				err := dao.RecordQuery(refCollection).
 					AndWhere(dbx.Not(dbx.HashExp{"id": record.Id})).
-					AndWhere(dbx.Like(field.Name, record.Id).Match(true, true)).
+					AndWhere(refExpr).
 					All(&rows)
 				if err != nil {
 					return err
@@ -242,7 +470,7 @@ func (dao *Dao) DeleteRecord(record *models.Record) error {
 					// cascade delete the reference
 					// (only if there are no other active references in case of multiple select)
 					if options.CascadeDelete && len(ids) == 0 {
-						if err := txDao.DeleteRecord(refRecord); err != nil {
+						if err := txDao.DeleteRecordBy(refRecord, deletedBy); err != nil {
 							return err
 						}
 						// no further action are needed (the reference is deleted)
@@ -262,6 +490,16 @@ func (dao *Dao) DeleteRecord(record *models.Record) error {
 			}
 		}
 
+		// (the versions of the archived records are kept
+		// so that they are still available after restore)
+		if record.Collection().Options.Archive {
+			if err := txDao.archiveRecord(record, deletedBy); err != nil {
+				return err
+			}
+		} else if err := txDao.DeleteRecordVersions(record); err != nil {
+			return err
+		}
+
 		return txDao.Delete(record)
 	})
 }
@@ -298,6 +536,20 @@ func (dao *Dao) SyncRecordTableSchema(newCollection *models.Collection, oldColle
 			return indexErr
 		}
 
+		// add the collection defined indexes
+		for _, idx := range newCollection.Options.Indexes {
+			if err := dao.createRecordTableIndex(newCollection, idx); err != nil {
+				return err
+			}
+		}
+
+		// add the relation fields indexes (if enabled)
+		for name, column := range newCollection.RelationIndexes() {
+			if _, err := dao.DB().CreateIndex(tableName, name, column).Execute(); err != nil {
+				return err
+			}
+		}
+
 		return nil
 	}
 
@@ -308,6 +560,32 @@ func (dao *Dao) SyncRecordTableSchema(newCollection *models.Collection, oldColle
 		oldSchema := oldCollection.Schema
 		newSchema := newCollection.Schema
 
+		// drop the deleted or changed indexes
+		// (before the columns changes because indexed columns cannot be dropped)
+		for _, oldIdx := range oldCollection.Options.Indexes {
+			if newIdx := findCollectionIndex(newCollection, oldIdx.Name); newIdx != nil && newIdx.Equal(oldIdx) {
+				continue // unchanged
+			}
+
+			_, err := txDao.DB().DropIndex(oldTableName, oldIdx.TableIndexName(oldCollection)).Execute()
+			if err != nil {
+				return err
+			}
+		}
+
+		// drop the no longer needed relation fields indexes
+		newRelationIndexes := newCollection.RelationIndexes()
+		oldRelationIndexes := oldCollection.RelationIndexes()
+		for name := range oldRelationIndexes {
+			if _, ok := newRelationIndexes[name]; ok {
+				continue // still needed
+			}
+
+			if _, err := txDao.DB().DropIndex(oldTableName, name).Execute(); err != nil {
+				return err
+			}
+		}
+
 		// check for renamed table
 		if !strings.EqualFold(oldTableName, newTableName) {
 			_, err := dao.DB().RenameTable(oldTableName, newTableName).Execute()
@@ -346,6 +624,69 @@ func (dao *Dao) SyncRecordTableSchema(newCollection *models.Collection, oldColle
 			}
 		}
 
+		// keep the stored record versions in sync with the renamed and deleted fields
+		if err := txDao.syncRecordVersionsSchema(newCollection, oldCollection); err != nil {
+			return err
+		}
+
+		// create the new or changed indexes
+		for _, newIdx := range newCollection.Options.Indexes {
+			if oldIdx := findCollectionIndex(oldCollection, newIdx.Name); oldIdx != nil && oldIdx.Equal(newIdx) {
+				continue // unchanged
+			}
+
+			if err := txDao.createRecordTableIndex(newCollection, newIdx); err != nil {
+				return err
+			}
+		}
+
+		// create the new relation fields indexes
+		for name, column := range newRelationIndexes {
+			if _, ok := oldRelationIndexes[name]; ok {
+				continue // already exist
+			}
+
+			if _, err := txDao.DB().CreateIndex(newTableName, name, column).Execute(); err != nil {
+				return err
+			}
+		}
+
 		return nil
 	})
 }
+
+// createRecordTableIndex creates a single collection defined records table index.
+func (dao *Dao) createRecordTableIndex(collection *models.Collection, idx models.CollectionIndex) error {
+	columns := make([]string, len(idx.Fields))
+	for i, field := range idx.Fields {
+		columns[i] = "[[" + field + "]]"
+	}
+
+	var sql strings.Builder
+
+	sql.WriteString("CREATE ")
+	if idx.Unique {
+		sql.WriteString("UNIQUE ")
+	}
+	sql.WriteString("INDEX {{" + idx.TableIndexName(collection) + "}} ")
+	sql.WriteString("ON {{" + collection.Name + "}} (" + strings.Join(columns, ", ") + ")")
+	if idx.Where != "" {
+		sql.WriteString(" WHERE " + idx.Where)
+	}
+
+	if _, err := dao.DB().NewQuery(sql.String()).Execute(); err != nil {
+		return fmt.Errorf("Failed to create index %q: %w", idx.Name, err)
+	}
+
+	return nil
+}
+
+func findCollectionIndex(collection *models.Collection, name string) *models.CollectionIndex {
+	for i, idx := range collection.Options.Indexes {
+		if strings.EqualFold(idx.Name, name) {
+			return &collection.Options.Indexes[i]
+		}
+	}
+
+	return nil
+}
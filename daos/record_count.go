@@ -0,0 +1,118 @@
+package daos
+
+import (
+	"fmt"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// CountRecordRelations loads the configured collection back-relation counts
+// (see [models.CollectionOptions.Counts]) of a single Record model.
+func (dao *Dao) CountRecordRelations(record *models.Record) error {
+	return dao.CountRecordsRelations([]*models.Record{record})
+}
+
+// CountRecordsRelations loads the configured collection back-relation counts
+// (see [models.CollectionOptions.Counts]) of the provided Record models list.
+//
+// All records are expected to be from the same collection.
+//
+// Each count is resolved with a single grouped query for the whole
+// list (instead of a subquery per record), so it is safe to be used
+// with paginated results.
+func (dao *Dao) CountRecordsRelations(records []*models.Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	mainCollection := records[0].Collection()
+	if len(mainCollection.Options.Counts) == 0 {
+		return nil // nothing to count
+	}
+
+	ids := make([]any, len(records))
+	for i, record := range records {
+		ids[i] = record.Id
+	}
+
+	counts := make(map[string]map[string]int, len(records))
+	for _, record := range records {
+		counts[record.Id] = make(map[string]int, len(mainCollection.Options.Counts))
+	}
+
+	for _, options := range mainCollection.Options.Counts {
+		totals, err := dao.countBackRelations(mainCollection, options, ids)
+		if err != nil {
+			return err
+		}
+
+		for id, recordCounts := range counts {
+			recordCounts[options.Name] = totals[id]
+		}
+	}
+
+	for _, record := range records {
+		record.SetCounts(counts[record.Id])
+	}
+
+	return nil
+}
+
+// countBackRelations returns the number of options.CollectionId records
+// referencing each of the provided mainCollection record ids.
+func (dao *Dao) countBackRelations(mainCollection *models.Collection, options models.RelationCountOptions, ids []any) (map[string]int, error) {
+	relCollection, err := dao.FindCollectionByNameOrId(options.CollectionId)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't find collection %q.", options.CollectionId)
+	}
+
+	relField := relCollection.Schema.GetFieldByName(options.Field)
+	if relField == nil || relField.Type != schema.FieldTypeRelation {
+		return nil, fmt.Errorf("Couldn't find relation field %q in collection %q.", options.Field, relCollection.Name)
+	}
+	relField.InitOptions()
+	relFieldOptions, _ := relField.Options.(*schema.RelationOptions)
+	if relFieldOptions == nil || relFieldOptions.CollectionId != mainCollection.Id {
+		return nil, fmt.Errorf("Field %q doesn't reference collection %q.", options.Field, mainCollection.Name)
+	}
+
+	rows := []struct {
+		Id    string `db:"id"`
+		Total int    `db:"total"`
+	}{}
+
+	column := fmt.Sprintf("[[%s.%s]]", relCollection.Name, relField.Name)
+	query := dao.ReadDB().Select("count(*) as total").From(relCollection.Name)
+
+	if relFieldOptions.MaxSelect == 1 {
+		// single relations are stored as plain id strings
+		query.AndSelect(column + " as id").
+			AndWhere(dbx.In(relCollection.Name+"."+relField.Name, ids...)).
+			GroupBy(relCollection.Name + "." + relField.Name)
+	} else {
+		// multiple relations are stored as serialized json arrays
+		query.AndSelect("[[__rel.value]] as id").
+			InnerJoin(
+				fmt.Sprintf(
+					"json_each(CASE WHEN json_valid(%s) THEN %s ELSE json_array(%s) END) as __rel",
+					column, column, column,
+				),
+				nil,
+			).
+			AndWhere(dbx.In("__rel.value", ids...)).
+			GroupBy("__rel.value")
+	}
+
+	if err := query.All(&rows); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]int, len(rows))
+	for _, row := range rows {
+		result[row.Id] = row.Total
+	}
+
+	return result, nil
+}
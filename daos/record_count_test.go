@@ -0,0 +1,146 @@
+package daos_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestCountRecordsRelations(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	col, _ := app.Dao().FindCollectionByNameOrId("demo4")
+
+	validCounts := []models.RelationCountOptions{
+		{Name: "single", CollectionId: col.Id, Field: "onerel"},
+		{Name: "multiple", CollectionId: col.Id, Field: "manyrels"},
+	}
+
+	allIds := []string{
+		"054f9f24-0a0a-4e09-87b1-bc7ff2b336a2",
+		"b84cd893-7119-43c9-8505-3c4e22da28a9",
+		"df55c8ff-45ef-4c82-8aed-6e2183fe1125",
+		"b8ba58f9-e2d7-42a0-b0e7-a11efd98236b",
+	}
+
+	scenarios := []struct {
+		counts      []models.RelationCountOptions
+		recordIds   []string
+		expected    map[string]string
+		expectError bool
+	}{
+		// no configured counts
+		{
+			nil,
+			allIds,
+			map[string]string{
+				"054f9f24-0a0a-4e09-87b1-bc7ff2b336a2": "null",
+				"b84cd893-7119-43c9-8505-3c4e22da28a9": "null",
+				"df55c8ff-45ef-4c82-8aed-6e2183fe1125": "null",
+				"b8ba58f9-e2d7-42a0-b0e7-a11efd98236b": "null",
+			},
+			false,
+		},
+		// missing collection
+		{
+			[]models.RelationCountOptions{{Name: "test", CollectionId: "missing", Field: "onerel"}},
+			allIds,
+			nil,
+			true,
+		},
+		// non relation field
+		{
+			[]models.RelationCountOptions{{Name: "test", CollectionId: col.Id, Field: "title"}},
+			allIds,
+			nil,
+			true,
+		},
+		// relation field to another collection
+		{
+			[]models.RelationCountOptions{{Name: "test", CollectionId: "demo2", Field: "onerel"}},
+			allIds,
+			nil,
+			true,
+		},
+		// valid counts
+		{
+			validCounts,
+			allIds,
+			map[string]string{
+				"054f9f24-0a0a-4e09-87b1-bc7ff2b336a2": `{"multiple":0,"single":1}`,
+				"b84cd893-7119-43c9-8505-3c4e22da28a9": `{"multiple":2,"single":0}`,
+				"df55c8ff-45ef-4c82-8aed-6e2183fe1125": `{"multiple":1,"single":0}`,
+				"b8ba58f9-e2d7-42a0-b0e7-a11efd98236b": `{"multiple":0,"single":1}`,
+			},
+			false,
+		},
+		// valid counts for a subset of records
+		{
+			validCounts,
+			[]string{"b84cd893-7119-43c9-8505-3c4e22da28a9"},
+			map[string]string{
+				"b84cd893-7119-43c9-8505-3c4e22da28a9": `{"multiple":2,"single":0}`,
+			},
+			false,
+		},
+	}
+
+	for i, s := range scenarios {
+		col.Options.Counts = s.counts
+
+		records, err := app.Dao().FindRecordsByIds(col, s.recordIds, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		countErr := app.Dao().CountRecordsRelations(records)
+
+		hasErr := countErr != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr to be %v, got %v (%v)", i, s.expectError, hasErr, countErr)
+			continue
+		}
+
+		if hasErr {
+			continue
+		}
+
+		if len(records) != len(s.expected) {
+			t.Errorf("(%d) Expected %d records, got %d", i, len(s.expected), len(records))
+			continue
+		}
+
+		for _, record := range records {
+			encoded, _ := json.Marshal(record.GetCounts())
+			if string(encoded) != s.expected[record.Id] {
+				t.Errorf("(%d) Expected record %s counts %s, got %s", i, record.Id, s.expected[record.Id], encoded)
+			}
+		}
+	}
+}
+
+func TestCountRecordRelations(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	col, _ := app.Dao().FindCollectionByNameOrId("demo4")
+	col.Options.Counts = []models.RelationCountOptions{
+		{Name: "test", CollectionId: col.Id, Field: "manyrels"},
+	}
+
+	record, err := app.Dao().FindFirstRecordByData(col, "id", "b84cd893-7119-43c9-8505-3c4e22da28a9")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Dao().CountRecordRelations(record); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := record.GetCounts()["test"]; v != 2 {
+		t.Fatalf("Expected count 2, got %d", v)
+	}
+}
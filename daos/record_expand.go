@@ -14,6 +14,10 @@ import (
 const MaxExpandDepth = 6
 
 // ExpandFetchFunc defines the function that is used to fetch the expanded relation records.
+//
+// The function could return only a subset of the requested relation records
+// (eg. only the accessible ones) and the ones that are not returned are
+// marked as filtered out (see [models.Record.GetExpandFiltered]).
 type ExpandFetchFunc func(relCollection *models.Collection, relIds []string) ([]*models.Record, error)
 
 // ExpandRecord expands the relations of a single Record model.
@@ -22,52 +26,81 @@ func (dao *Dao) ExpandRecord(record *models.Record, expands []string, fetchFunc
 }
 
 // ExpandRecords expands the relations of the provided Record models list.
+//
+// The relations are loaded in batches, aka. with a single fetchFunc call
+// per relation field and expand level, no matter of the number of records
+// (the expand paths with common prefix, eg. "a.b" and "a.c", also share
+// the same fetched relation records).
 func (dao *Dao) ExpandRecords(records []*models.Record, expands []string, fetchFunc ExpandFetchFunc) error {
-	normalized := normalizeExpands(expands)
-
-	for _, expand := range normalized {
-		if err := dao.expandRecords(records, expand, fetchFunc, 1); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return dao.expandRecords(records, NormalizeExpands(expands), fetchFunc, 1)
 }
 
 // notes:
 // - fetchFunc must be non-nil func
 // - all records are expected to be from the same collection
-// - if MaxExpandDepth is reached, the function returns nil ignoring the remaining expand path
-func (dao *Dao) expandRecords(records []*models.Record, expandPath string, fetchFunc ExpandFetchFunc, recursionLevel int) error {
+// - if MaxExpandDepth is reached, the function returns nil ignoring the remaining expand paths
+func (dao *Dao) expandRecords(records []*models.Record, expandPaths []string, fetchFunc ExpandFetchFunc, recursionLevel int) error {
 	if fetchFunc == nil {
 		return errors.New("Relation records fetchFunc is not set.")
 	}
 
-	if expandPath == "" || recursionLevel > MaxExpandDepth || len(records) == 0 {
+	if len(expandPaths) == 0 || recursionLevel > MaxExpandDepth || len(records) == 0 {
 		return nil
 	}
 
-	parts := strings.SplitN(expandPath, ".", 2)
+	// group the expand paths by their first (aka. current level) field
+	fields := []string{}
+	subPaths := map[string][]string{}
+	for _, path := range expandPaths {
+		parts := strings.SplitN(path, ".", 2)
+		if _, ok := subPaths[parts[0]]; !ok {
+			fields = append(fields, parts[0])
+			subPaths[parts[0]] = []string{}
+		}
+		if len(parts) > 1 {
+			subPaths[parts[0]] = append(subPaths[parts[0]], parts[1])
+		}
+	}
+
+	for _, field := range fields {
+		if err := dao.expandRecordsField(records, field, subPaths[field], fetchFunc, recursionLevel); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
 
+// expandRecordsField expands a single relation field of the provided
+// records list and their nested relations defined with subPaths.
+func (dao *Dao) expandRecordsField(records []*models.Record, fieldName string, subPaths []string, fetchFunc ExpandFetchFunc, recursionLevel int) error {
 	// extract the relation field (if exist)
 	mainCollection := records[0].Collection()
-	relField := mainCollection.Schema.GetFieldByName(parts[0])
+	relField := mainCollection.Schema.GetFieldByName(fieldName)
 	if relField == nil {
-		return fmt.Errorf("Couldn't find field %q in collection %q.", parts[0], mainCollection.Name)
+		return fmt.Errorf("Couldn't find field %q in collection %q.", fieldName, mainCollection.Name)
 	}
 	relField.InitOptions()
 	relFieldOptions, _ := relField.Options.(*schema.RelationOptions)
-
-	relCollection, err := dao.FindCollectionByNameOrId(relFieldOptions.CollectionId)
-	if err != nil {
-		return fmt.Errorf("Couldn't find collection %q.", relFieldOptions.CollectionId)
+	if relFieldOptions == nil {
+		return fmt.Errorf("Field %q is not a relation field.", fieldName)
 	}
 
-	// extract the id of the relations to expand
+	// extract the unique ids of the relations to expand
 	relIds := []string{}
 	for _, record := range records {
 		relIds = append(relIds, record.GetStringSliceDataValue(relField.Name)...)
 	}
+	relIds = list.ToUniqueStringSlice(relIds)
+
+	if len(relIds) == 0 {
+		return nil // nothing to expand
+	}
+
+	relCollection, err := dao.FindCollectionByNameOrId(relFieldOptions.CollectionId)
+	if err != nil {
+		return fmt.Errorf("Couldn't find collection %q.", relFieldOptions.CollectionId)
+	}
 
 	// fetch rels
 	rels, relsErr := fetchFunc(relCollection, relIds)
@@ -76,9 +109,8 @@ func (dao *Dao) expandRecords(records []*models.Record, expandPath string, fetch
 	}
 
 	// expand nested fields
-	if len(parts) > 1 {
-		err := dao.expandRecords(rels, parts[1], fetchFunc, recursionLevel+1)
-		if err != nil {
+	if len(subPaths) > 0 {
+		if err := dao.expandRecords(rels, subPaths, fetchFunc, recursionLevel+1); err != nil {
 			return err
 		}
 	}
@@ -99,6 +131,17 @@ func (dao *Dao) expandRecords(records []*models.Record, expandPath string, fetch
 			}
 		}
 
+		// mark the relations that were filtered out by the fetchFunc
+		// (eg. because of the related collection view rule)
+		if filtered := len(relIds) - len(validRels); filtered > 0 {
+			filteredData := model.GetExpandFiltered()
+			if filteredData == nil {
+				filteredData = map[string]int{}
+			}
+			filteredData[relField.Name] = filtered
+			model.SetExpandFiltered(filteredData)
+		}
+
 		if len(validRels) == 0 {
 			continue // no valid relations
 		}
@@ -117,9 +160,9 @@ func (dao *Dao) expandRecords(records []*models.Record, expandPath string, fetch
 	return nil
 }
 
-// normalizeExpands normalizes expand strings and merges self containing paths
+// NormalizeExpands normalizes expand strings and merges self containing paths
 // (eg. ["a.b.c", "a.b", "   test  ", "  ", "test"] -> ["a.b.c", "test"]).
-func normalizeExpands(paths []string) []string {
+func NormalizeExpands(paths []string) []string {
 	result := []string{}
 
 	// normalize paths
@@ -1,10 +1,14 @@
 package daos_test
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/pocketbase/pocketbase/daos"
 	"github.com/pocketbase/pocketbase/models"
@@ -145,6 +149,64 @@ func TestExpandRecords(t *testing.T) {
 	}
 }
 
+func TestExpandRecordsQueriesCount(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	col, _ := app.Dao().FindCollectionByNameOrId("demo4")
+
+	var queries int32
+	app.DB().QueryLogFunc = func(ctx context.Context, d time.Duration, rawSql string, rows *sql.Rows, err error) {
+		atomic.AddInt32(&queries, 1)
+	}
+
+	fetchFunc := func(c *models.Collection, ids []string) ([]*models.Record, error) {
+		return app.Dao().FindRecordsByIds(c, ids, nil)
+	}
+
+	// pages with increasing size (the first record relations cover
+	// the relations of the other records for each expand level)
+	pages := [][]string{
+		{"b8ba58f9-e2d7-42a0-b0e7-a11efd98236b"},
+		{"b8ba58f9-e2d7-42a0-b0e7-a11efd98236b", "df55c8ff-45ef-4c82-8aed-6e2183fe1125"},
+		{
+			"b8ba58f9-e2d7-42a0-b0e7-a11efd98236b",
+			"df55c8ff-45ef-4c82-8aed-6e2183fe1125",
+			"b84cd893-7119-43c9-8505-3c4e22da28a9",
+			"054f9f24-0a0a-4e09-87b1-bc7ff2b336a2",
+		},
+	}
+
+	expectedQueries := int32(-1)
+
+	for i, ids := range pages {
+		records, err := app.Dao().FindRecordsByIds(col, ids, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != len(ids) {
+			t.Fatalf("(%d) Expected %d records, got %d", i, len(ids), len(records))
+		}
+
+		atomic.StoreInt32(&queries, 0)
+
+		if err := app.Dao().ExpandRecords(records, []string{"manyrels.onerel", "manyrels.manyrels"}, fetchFunc); err != nil {
+			t.Fatalf("(%d) %v", i, err)
+		}
+
+		total := atomic.LoadInt32(&queries)
+		if total == 0 {
+			t.Fatalf("(%d) Expected the relations to be queried", i)
+		}
+
+		if expectedQueries == -1 {
+			expectedQueries = total
+		} else if total != expectedQueries {
+			t.Errorf("(%d) Expected %d queries (same as the single record page), got %d", i, expectedQueries, total)
+		}
+	}
+}
+
 func TestExpandRecord(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -256,3 +318,38 @@ func TestExpandRecord(t *testing.T) {
 		}
 	}
 }
+
+func TestExpandRecordFiltered(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	col, _ := app.Dao().FindCollectionByNameOrId("demo4")
+
+	record, err := app.Dao().FindFirstRecordByData(col, "id", "b8ba58f9-e2d7-42a0-b0e7-a11efd98236b")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// return only the first of the requested relations
+	fetchFunc := func(c *models.Collection, ids []string) ([]*models.Record, error) {
+		return app.Dao().FindRecordsByIds(c, ids[:1], nil)
+	}
+
+	if err := app.Dao().ExpandRecord(record, []string{"onerel", "manyrels"}, fetchFunc); err != nil {
+		t.Fatal(err)
+	}
+
+	if rels, _ := record.GetExpand()["manyrels"].([]*models.Record); len(rels) != 1 {
+		t.Fatalf("Expected 1 expanded manyrels record, got %v", rels)
+	}
+
+	filtered := record.GetExpandFiltered()
+	if len(filtered) != 1 || filtered["manyrels"] != 1 {
+		t.Fatalf("Expected only 1 filtered manyrels record, got %v", filtered)
+	}
+
+	raw, _ := json.Marshal(record)
+	if !strings.Contains(string(raw), `"@expandFiltered":{"manyrels":1}`) {
+		t.Fatalf("Expected the filtered indicator to be exported, got %s", raw)
+	}
+}
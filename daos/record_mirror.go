@@ -0,0 +1,357 @@
+package daos
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/list"
+)
+
+// mirrorsBatchSize is the max number of dependent records
+// loaded at once when syncing or backfilling the mirror fields.
+const mirrorsBatchSize = 100
+
+// ErrTooManyMirrorRecords is returned by [Dao.SyncRecordMirrors] when the
+// number of the dependent records exceeds the max allowed affected records.
+var ErrTooManyMirrorRecords = errors.New("Too many mirror records to update.")
+
+// RecordMirror defines a single mirror options with its dependent collection.
+type RecordMirror struct {
+	Collection *models.Collection
+	Options    models.RecordMirrorOptions
+}
+
+// FindRecordMirrors returns all collections mirror options
+// (with their dependent collection) grouped by their source collection id.
+func (dao *Dao) FindRecordMirrors() (map[string][]*RecordMirror, error) {
+	collections := []*models.Collection{}
+
+	err := dao.CollectionQuery().
+		AndWhere(dbx.NewExp("json_array_length(json_extract([[options]], '$.mirrors')) > 0")).
+		All(&collections)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string][]*RecordMirror{}
+	for _, c := range collections {
+		for _, options := range c.Options.Mirrors {
+			if sourceId := mirrorSourceCollectionId(c, options); sourceId != "" {
+				result[sourceId] = append(result[sourceId], &RecordMirror{Collection: c, Options: options})
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// findSourceRecordMirrors returns the mirror options (with their collection)
+// that mirror a field of the provided source collection.
+func (dao *Dao) findSourceRecordMirrors(source *models.Collection) ([]*RecordMirror, error) {
+	var mirrors map[string][]*RecordMirror
+	var err error
+
+	if dao.RecordMirrorsFunc != nil {
+		mirrors, err = dao.RecordMirrorsFunc(dao)
+	} else {
+		mirrors, err = dao.FindRecordMirrors()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return mirrors[source.Id], nil
+}
+
+// mirrorSourceCollectionId returns the id of the collection
+// referenced by the mirror relation field (if valid).
+func mirrorSourceCollectionId(collection *models.Collection, options models.RecordMirrorOptions) string {
+	field := collection.Schema.GetFieldByName(options.RelationField)
+	if field == nil || field.Type != schema.FieldTypeRelation {
+		return ""
+	}
+
+	field.InitOptions()
+	relOptions, _ := field.Options.(*schema.RelationOptions)
+	if relOptions == nil {
+		return ""
+	}
+
+	return relOptions.CollectionId
+}
+
+// FillRecordMirrors sets the mirror fields of the provided record
+// (see [models.CollectionOptions.Mirrors]) from the related source records.
+//
+// Only the mirrors of new records or with changed relation field
+// (compared to the record original data) are filled.
+func (dao *Dao) FillRecordMirrors(record *models.Record) error {
+	collection := record.Collection()
+	if collection == nil || len(collection.Options.Mirrors) == 0 {
+		return nil
+	}
+
+	original := record.OriginalData()
+
+	for _, options := range collection.Options.Mirrors {
+		relId := record.GetStringDataValue(options.RelationField)
+
+		if original != nil && !record.IsNew() && fmt.Sprint(original[options.RelationField]) == fmt.Sprint(record.GetDataValue(options.RelationField)) {
+			continue // unchanged relation
+		}
+
+		value, err := dao.mirrorSourceValue(collection, options, relId)
+		if err != nil {
+			return err
+		}
+
+		record.SetDataValue(options.Field, value)
+	}
+
+	return nil
+}
+
+// mirrorSourceValue returns the mirrored value of the source record with relId
+// (returns nil if the relation is empty or the source record is missing).
+func (dao *Dao) mirrorSourceValue(collection *models.Collection, options models.RecordMirrorOptions, relId string) (any, error) {
+	if relId == "" {
+		return nil, nil
+	}
+
+	sourceCollection, err := dao.FindCollectionByNameOrId(mirrorSourceCollectionId(collection, options))
+	if err != nil {
+		return nil, fmt.Errorf("Missing %q mirror source collection.", options.Field)
+	}
+
+	source, err := dao.FindRecordById(sourceCollection, relId, nil)
+	if err != nil {
+		return nil, nil // missing source record
+	}
+
+	return source.GetDataValue(options.SourceField), nil
+}
+
+// SyncRecordMirrors updates the mirror fields of the records that mirror a
+// changed field of the provided source record and returns the number
+// of the updated records.
+//
+// The dependent records are loaded and saved in batches (with their
+// model hooks, so the chained mirrors are also updated). If maxAffected is
+// positive and the total number of the dependent records of the source
+// record mirrors exceeds it, the exceeding mirrors are not synced and
+// [ErrTooManyMirrorRecords] is returned.
+//
+// The collections of the not synced (skipped or failed) mirrors are marked
+// as stale (see [Dao.FindStaleRecordMirrors]) and their records could be
+// fixed later with [Dao.BackfillRecordMirrors].
+func (dao *Dao) SyncRecordMirrors(source *models.Record, maxAffected int) (int, error) {
+	if source.Collection() == nil {
+		return 0, nil
+	}
+
+	mirrors, err := dao.findSourceRecordMirrors(source.Collection())
+	if err != nil {
+		return 0, err
+	}
+
+	original := source.OriginalData()
+
+	var total int
+	var affected int
+	var lastErr error
+	stale := []string{}
+
+	for _, m := range mirrors {
+		value := source.GetDataValue(m.Options.SourceField)
+
+		if original != nil && mirrorValuesEqual(original[m.Options.SourceField], value) {
+			continue // unchanged source field
+		}
+
+		expr := dbx.HashExp{m.Collection.Name + "." + m.Options.RelationField: source.Id}
+
+		if maxAffected > 0 {
+			var count int
+			if err := dao.RecordQuery(m.Collection).Select("count(*)").AndWhere(expr).Row(&count); err != nil {
+				lastErr = err
+				stale = append(stale, m.Collection.Id)
+				continue
+			}
+			if affected+count > maxAffected {
+				lastErr = fmt.Errorf("%w (%s.%s: %d > %d)", ErrTooManyMirrorRecords, m.Collection.Name, m.Options.Field, affected+count, maxAffected)
+				stale = append(stale, m.Collection.Id)
+				continue
+			}
+			affected += count
+		}
+
+		updated, err := dao.updateMirrorRecords(m, expr, func(record *models.Record) (any, error) {
+			return value, nil
+		})
+		total += updated
+		if err != nil {
+			lastErr = err
+			stale = append(stale, m.Collection.Id)
+		}
+	}
+
+	if len(stale) > 0 {
+		if err := dao.MarkRecordMirrorsStale(stale...); err != nil {
+			return total, err
+		}
+	}
+
+	return total, lastErr
+}
+
+// FindStaleRecordMirrors returns the ids of the collections
+// whose mirror fields are marked as out of sync and need to be backfilled
+// (see [Dao.SyncRecordMirrors] and [Dao.BackfillRecordMirrors]).
+func (dao *Dao) FindStaleRecordMirrors() ([]string, error) {
+	result := []string{}
+
+	param, err := dao.FindParamByKey(models.ParamStaleMirrors)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return result, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(param.Value, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// MarkRecordMirrorsStale marks the mirror fields of the
+// provided collections as out of sync.
+func (dao *Dao) MarkRecordMirrorsStale(collectionIds ...string) error {
+	ids, err := dao.FindStaleRecordMirrors()
+	if err != nil {
+		return err
+	}
+
+	newIds := list.ToUniqueStringSlice(append(ids, collectionIds...))
+	if len(newIds) == len(ids) {
+		return nil // already marked
+	}
+
+	return dao.SaveParam(models.ParamStaleMirrors, newIds)
+}
+
+// unmarkRecordMirrorsStale removes the stale mark
+// of the provided collection mirror fields (if any).
+func (dao *Dao) unmarkRecordMirrorsStale(collectionId string) error {
+	ids, err := dao.FindStaleRecordMirrors()
+	if err != nil {
+		return err
+	}
+
+	newIds := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id != collectionId {
+			newIds = append(newIds, id)
+		}
+	}
+
+	if len(newIds) == len(ids) {
+		return nil // not marked
+	}
+
+	return dao.SaveParam(models.ParamStaleMirrors, newIds)
+}
+
+// BackfillRecordMirrors recomputes the mirror fields of all records
+// of the provided collection and returns the number of the updated records.
+//
+// It could be used to fill the mirror fields of the existing records
+// after adding a new mirror or after a skipped [Dao.SyncRecordMirrors].
+func (dao *Dao) BackfillRecordMirrors(collection *models.Collection) (int, error) {
+	var total int
+
+	for _, options := range collection.Options.Mirrors {
+		m := &RecordMirror{Collection: collection, Options: options}
+
+		updated, err := dao.updateMirrorRecords(m, nil, func(record *models.Record) (any, error) {
+			return dao.mirrorSourceValue(collection, options, record.GetStringDataValue(options.RelationField))
+		})
+		total += updated
+		if err != nil {
+			return total, err
+		}
+	}
+
+	if err := dao.unmarkRecordMirrorsStale(collection.Id); err != nil {
+		return total, err
+	}
+
+	return total, nil
+}
+
+// updateMirrorRecords updates in batches the mirror field of the
+// collection records matching expr with the value returned by valueFunc
+// (only the records with different mirror value are saved).
+func (dao *Dao) updateMirrorRecords(m *RecordMirror, expr dbx.Expression, valueFunc func(record *models.Record) (any, error)) (int, error) {
+	var total int
+	var lastId string
+
+	for {
+		query := dao.RecordQuery(m.Collection).
+			AndWhere(dbx.NewExp("[["+m.Collection.Name+".id]] > {:lastId}", dbx.Params{"lastId": lastId})).
+			OrderBy(m.Collection.Name + ".id ASC").
+			Limit(mirrorsBatchSize)
+
+		if expr != nil {
+			query.AndWhere(expr)
+		}
+
+		rows := []dbx.NullStringMap{}
+		if err := query.All(&rows); err != nil {
+			return total, err
+		}
+
+		for _, record := range models.NewRecordsFromNullStringMaps(m.Collection, rows) {
+			lastId = record.Id
+
+			value, err := valueFunc(record)
+			if err != nil {
+				return total, err
+			}
+
+			oldValue := record.GetDataValue(m.Options.Field)
+
+			record.SetDataValue(m.Options.Field, value)
+
+			if mirrorValuesEqual(oldValue, record.GetDataValue(m.Options.Field)) {
+				continue
+			}
+
+			if err := dao.SaveRecord(record); err != nil {
+				return total, err
+			}
+
+			total++
+		}
+
+		if len(rows) < mirrorsBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// mirrorValuesEqual compares the serialized values to normalize the
+// different representations of the same value (eg. dates).
+func mirrorValuesEqual(a, b any) bool {
+	rawA, _ := json.Marshal(a)
+	rawB, _ := json.Marshal(b)
+
+	return bytes.Equal(rawA, rawB)
+}
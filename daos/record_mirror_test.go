@@ -0,0 +1,299 @@
+package daos_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+// createMirrorTestCollections creates an "authors" collection and a
+// "posts" collection with "authorName" mirror of the post author name.
+func createMirrorTestCollections(t *testing.T, app *tests.TestApp) (*models.Collection, *models.Collection) {
+	authors := &models.Collection{
+		Name: "authors",
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "name", Type: schema.FieldTypeText},
+		),
+	}
+	if err := app.Dao().SaveCollection(authors); err != nil {
+		t.Fatal(err)
+	}
+
+	posts := &models.Collection{
+		Name: "posts",
+		Schema: schema.NewSchema(
+			&schema.SchemaField{
+				Name:    "author",
+				Type:    schema.FieldTypeRelation,
+				Options: &schema.RelationOptions{MaxSelect: 1, CollectionId: authors.Id},
+			},
+			&schema.SchemaField{Name: "authorName", Type: schema.FieldTypeText},
+		),
+		Options: models.CollectionOptions{
+			Mirrors: []models.RecordMirrorOptions{
+				{Field: "authorName", RelationField: "author", SourceField: "name"},
+			},
+		},
+	}
+	if err := app.Dao().SaveCollection(posts); err != nil {
+		t.Fatal(err)
+	}
+
+	return authors, posts
+}
+
+func TestRecordMirrors(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	authors, posts := createMirrorTestCollections(t, app)
+
+	author := models.NewRecord(authors)
+	author.SetDataValue("name", "John")
+	if err := app.Dao().SaveRecord(author); err != nil {
+		t.Fatal(err)
+	}
+
+	// fill on create
+	for i := 0; i < 3; i++ {
+		post := models.NewRecord(posts)
+		post.SetDataValue("author", author.Id)
+		post.SetDataValue("authorName", "invalid")
+		if err := app.Dao().SaveRecord(post); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assertAuthorNames := func(name string, expected string) {
+		records, err := app.Dao().FindRecordsByExpr(posts, dbx.Not(dbx.HashExp{"id": ""}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 3 {
+			t.Fatalf("[%s] Expected 3 posts, got %d", name, len(records))
+		}
+		for _, r := range records {
+			if v := r.GetStringDataValue("authorName"); v != expected {
+				t.Fatalf("[%s] Expected authorName %q, got %q", name, expected, v)
+			}
+		}
+	}
+
+	assertAuthorNames("create", "John")
+
+	// sync on source change
+	author.SetDataValue("name", "Jane")
+	if err := app.Dao().SaveRecord(author); err != nil {
+		t.Fatal(err)
+	}
+	assertAuthorNames("sync", "Jane")
+
+	// max affected records safeguard
+	app.Settings().Mirrors.MaxAffectedRecords = 2
+	author, _ = app.Dao().FindRecordById(authors, author.Id, nil)
+	author.SetDataValue("name", "Bob")
+	if err := app.Dao().SaveRecord(author); err != nil {
+		t.Fatal(err)
+	}
+	assertAuthorNames("max affected", "Jane")
+
+	stale, err := app.Dao().FindStaleRecordMirrors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stale) != 1 || stale[0] != posts.Id {
+		t.Fatalf("Expected the posts mirrors to be marked as stale, got %v", stale)
+	}
+
+	author, _ = app.Dao().FindRecordById(authors, author.Id, nil)
+	author.SetDataValue("name", "Bob2")
+	updated, err := app.Dao().SyncRecordMirrors(author, 2)
+	if !errors.Is(err, daos.ErrTooManyMirrorRecords) {
+		t.Fatalf("Expected ErrTooManyMirrorRecords, got %v", err)
+	}
+	if updated != 0 {
+		t.Fatalf("Expected 0 updated records, got %d", updated)
+	}
+
+	// backfill
+	updated, err = app.Dao().BackfillRecordMirrors(posts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated != 3 {
+		t.Fatalf("Expected 3 backfilled records, got %d", updated)
+	}
+	assertAuthorNames("backfill", "Bob")
+
+	stale, err = app.Dao().FindStaleRecordMirrors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stale) != 0 {
+		t.Fatalf("Expected the stale mark to be removed after backfill, got %v", stale)
+	}
+
+	// nothing to backfill
+	updated, err = app.Dao().BackfillRecordMirrors(posts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated != 0 {
+		t.Fatalf("Expected 0 backfilled records, got %d", updated)
+	}
+}
+
+func TestFillRecordMirrorsOnRelationChange(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	authors, posts := createMirrorTestCollections(t, app)
+
+	author1 := models.NewRecord(authors)
+	author1.SetDataValue("name", "John")
+	author2 := models.NewRecord(authors)
+	author2.SetDataValue("name", "Jane")
+	for _, a := range []*models.Record{author1, author2} {
+		if err := app.Dao().SaveRecord(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	post := models.NewRecord(posts)
+	post.SetDataValue("author", author1.Id)
+	if err := app.Dao().SaveRecord(post); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		author   string
+		expected string
+	}{
+		{author2.Id, "Jane"},
+		{"", ""},
+		{"missing", ""},
+		{author1.Id, "John"},
+	}
+
+	for i, s := range scenarios {
+		post, _ = app.Dao().FindRecordById(posts, post.Id, nil)
+		post.SetDataValue("author", s.author)
+		if err := app.Dao().FillRecordMirrors(post); err != nil {
+			t.Fatalf("(%d) %v", i, err)
+		}
+
+		if v := post.GetStringDataValue("authorName"); v != s.expected {
+			t.Errorf("(%d) Expected authorName %q, got %q", i, s.expected, v)
+		}
+	}
+}
+
+func TestSyncRecordMirrorsAfterCommit(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	authors, posts := createMirrorTestCollections(t, app)
+
+	author := models.NewRecord(authors)
+	author.SetDataValue("name", "John")
+	if err := app.Dao().SaveRecord(author); err != nil {
+		t.Fatal(err)
+	}
+
+	post := models.NewRecord(posts)
+	post.SetDataValue("author", author.Id)
+	if err := app.Dao().SaveRecord(post); err != nil {
+		t.Fatal(err)
+	}
+
+	authorName := func(dao *daos.Dao) string {
+		record, err := dao.FindRecordById(posts, post.Id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return record.GetStringDataValue("authorName")
+	}
+
+	err := app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+		author.SetDataValue("name", "Jane")
+		if err := txDao.SaveRecord(author); err != nil {
+			return err
+		}
+
+		if name := authorName(txDao); name != "John" {
+			t.Fatalf("Expected the mirrors to not be synced before the commit, got %q", name)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if name := authorName(app.Dao()); name != "Jane" {
+		t.Fatalf("Expected the mirrors to be synced after the commit, got %q", name)
+	}
+}
+
+func TestSyncRecordMirrorsCollectionChange(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	authors, _ := createMirrorTestCollections(t, app)
+
+	author := models.NewRecord(authors)
+	author.SetDataValue("name", "John")
+	if err := app.Dao().SaveRecord(author); err != nil {
+		t.Fatal(err)
+	}
+
+	// load the mirrors
+	if _, err := app.Dao().SyncRecordMirrors(author, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// new dependent collection
+	comments := &models.Collection{
+		Name: "comments",
+		Schema: schema.NewSchema(
+			&schema.SchemaField{
+				Name:    "author",
+				Type:    schema.FieldTypeRelation,
+				Options: &schema.RelationOptions{MaxSelect: 1, CollectionId: authors.Id},
+			},
+			&schema.SchemaField{Name: "authorName", Type: schema.FieldTypeText},
+		),
+		Options: models.CollectionOptions{
+			Mirrors: []models.RecordMirrorOptions{
+				{Field: "authorName", RelationField: "author", SourceField: "name"},
+			},
+		},
+	}
+	if err := app.Dao().SaveCollection(comments); err != nil {
+		t.Fatal(err)
+	}
+
+	comment := models.NewRecord(comments)
+	comment.SetDataValue("author", author.Id)
+	if err := app.Dao().SaveRecord(comment); err != nil {
+		t.Fatal(err)
+	}
+
+	author.SetDataValue("name", "Jane")
+	if err := app.Dao().SaveRecord(author); err != nil {
+		t.Fatal(err)
+	}
+
+	comment, err := app.Dao().FindRecordById(comments, comment.Id, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := comment.GetStringDataValue("authorName"); name != "Jane" {
+		t.Fatalf("Expected the new collection mirrors to be synced, got %q", name)
+	}
+}
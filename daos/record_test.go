@@ -3,6 +3,7 @@ package daos_test
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/pocketbase/dbx"
@@ -66,6 +67,68 @@ func TestFindRecordById(t *testing.T) {
 	}
 }
 
+func TestFindRecordBySlug(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, _ := app.Dao().FindCollectionByNameOrId("demo3")
+
+	// no slug fields
+	if _, err := app.Dao().FindRecordBySlug(collection, "test", nil); err == nil {
+		t.Fatal("Expected error for collection without slug fields")
+	}
+
+	collection.Schema.AddField(&schema.SchemaField{
+		Name:    "slug",
+		Type:    schema.FieldTypeSlug,
+		Options: &schema.SlugOptions{Source: "title"},
+	})
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	record := models.NewRecord(collection)
+	record.SetDataValue("title", "test")
+	record.SetDataValue("slug", "test-slug")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		slug        string
+		filter      func(q *dbx.SelectQuery) error
+		expectError bool
+	}{
+		{"", nil, true},
+		{"missing", nil, true},
+		{"test-slug", nil, false},
+		{"test-slug", func(q *dbx.SelectQuery) error {
+			q.AndWhere(dbx.HashExp{"title": "missing"})
+			return nil
+		}, true},
+		{"test-slug", func(q *dbx.SelectQuery) error {
+			return errors.New("test error")
+		}, true},
+		{"test-slug", func(q *dbx.SelectQuery) error {
+			q.AndWhere(dbx.HashExp{"title": "test"})
+			return nil
+		}, false},
+	}
+
+	for i, scenario := range scenarios {
+		result, err := app.Dao().FindRecordBySlug(collection, scenario.slug, scenario.filter)
+
+		hasErr := err != nil
+		if hasErr != scenario.expectError {
+			t.Errorf("(%d) Expected hasErr to be %v, got %v (%v)", i, scenario.expectError, hasErr, err)
+		}
+
+		if result != nil && result.Id != record.Id {
+			t.Errorf("(%d) Expected record with id %s, got %s", i, record.Id, result.Id)
+		}
+	}
+}
+
 func TestFindRecordsByIds(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -343,6 +406,120 @@ func TestSaveRecord(t *testing.T) {
 	}
 }
 
+func TestUpsertRecord(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, _ := app.Dao().FindCollectionByNameOrId("demo2")
+
+	// make the bool field value non unique
+	dummy, _ := app.Dao().FindRecordById(collection, "63c2ab80-84ab-4057-a592-4604a731f78f", nil)
+	dummy.SetDataValue("bool", false)
+	if err := app.Dao().SaveRecord(dummy); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name           string
+		uniqueFields   []string
+		values         map[string]any
+		expectError    bool
+		expectId       string
+		expectedEvents map[string]int
+	}{
+		{
+			"missing unique fields",
+			nil,
+			map[string]any{"text": "test1"},
+			true,
+			"",
+			nil,
+		},
+		{
+			"missing unique field value",
+			[]string{"text"},
+			map[string]any{"number": 1},
+			true,
+			"",
+			nil,
+		},
+		{
+			"unknown field",
+			[]string{"text"},
+			map[string]any{"text": "test1", "unknown": 1},
+			true,
+			"",
+			nil,
+		},
+		{
+			"multiple matching records",
+			[]string{"bool"},
+			map[string]any{"bool": false, "number": 1},
+			true,
+			"",
+			nil,
+		},
+		{
+			"update existing record",
+			[]string{"text"},
+			map[string]any{"id": "ignored", "text": "test1", "number": 789},
+			false,
+			"94568ca2-0bee-49d7-b749-06cb97956fd9",
+			map[string]int{"OnModelBeforeUpdate": 1, "OnModelAfterUpdate": 1},
+		},
+		{
+			"create new record",
+			[]string{"text", "number"},
+			map[string]any{"id": "upsert_test_id", "text": "test_new", "number": "789"},
+			false,
+			"upsert_test_id",
+			map[string]int{"OnModelBeforeCreate": 1, "OnModelAfterCreate": 1},
+		},
+	}
+
+	for _, s := range scenarios {
+		app.ResetEventCalls()
+
+		record, err := app.Dao().UpsertRecord(collection, s.uniqueFields, s.values)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("[%s] Expected hasErr %v, got %v (%v)", s.name, s.expectError, hasErr, err)
+			continue
+		}
+
+		if hasErr {
+			if len(app.EventCalls) != 0 {
+				t.Errorf("[%s] Expected no events, got %v", s.name, app.EventCalls)
+			}
+			continue
+		}
+
+		if record.Id != s.expectId {
+			t.Errorf("[%s] Expected record id %q, got %q", s.name, s.expectId, record.Id)
+		}
+
+		saved, err := app.Dao().FindRecordById(collection, s.expectId, nil)
+		if err != nil {
+			t.Errorf("[%s] Failed to find the upserted record: %v", s.name, err)
+			continue
+		}
+
+		if v := saved.GetIntDataValue("number"); v != 789 {
+			t.Errorf("[%s] Expected number 789, got %d", s.name, v)
+		}
+
+		if len(app.EventCalls) != len(s.expectedEvents) {
+			t.Errorf("[%s] Expected events %v, got %v", s.name, s.expectedEvents, app.EventCalls)
+		}
+		for event, total := range s.expectedEvents {
+			if app.EventCalls[event] != total {
+				t.Errorf("[%s] Expected %s to be called %d times, got %d", s.name, event, total, app.EventCalls[event])
+			}
+		}
+	}
+}
+
 func TestDeleteRecord(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -474,3 +651,236 @@ func TestSyncRecordTableSchema(t *testing.T) {
 		}
 	}
 }
+
+func TestSyncRecordTableSchemaIndexes(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	findIndexes := func(table string) map[string]string {
+		rows := []struct {
+			Name string `db:"name"`
+			Sql  string `db:"sql"`
+		}{}
+
+		err := app.Dao().DB().Select("name", "sql").
+			From("sqlite_master").
+			AndWhere(dbx.HashExp{"type": "index", "tbl_name": table}).
+			AndWhere(dbx.NewExp("[[name]] LIKE '\\_idx\\_%' ESCAPE '\\'")).
+			All(&rows)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result := map[string]string{}
+		for _, row := range rows {
+			result[row.Name] = row.Sql
+		}
+
+		return result
+	}
+
+	collection := &models.Collection{
+		Name: "indexes_test",
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "a", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "b", Type: schema.FieldTypeText},
+		),
+		Options: models.CollectionOptions{
+			Indexes: []models.CollectionIndex{
+				{Name: "idx_a", Fields: []string{"a"}},
+				{Name: "idx_ab", Fields: []string{"a", "b"}, Unique: true, Where: "b != ''"},
+			},
+		},
+	}
+
+	// create
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	indexes := findIndexes("indexes_test")
+	if len(indexes) != 2 {
+		t.Fatalf("Expected 2 indexes, got %v", indexes)
+	}
+	idxAB := indexes[collection.Options.Indexes[1].TableIndexName(collection)]
+	if !strings.Contains(idxAB, "UNIQUE INDEX") || !strings.Contains(idxAB, "WHERE b != ''") {
+		t.Fatalf("Unexpected idx_ab definition %q", idxAB)
+	}
+
+	// update (rename the table, drop idx_a, change idx_ab and add idx_b)
+	collection.Name = "indexes_test_renamed"
+	collection.Options.Indexes = []models.CollectionIndex{
+		{Name: "idx_ab", Fields: []string{"b", "a"}},
+		{Name: "idx_b", Fields: []string{"b", "created"}},
+	}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	indexes = findIndexes("indexes_test_renamed")
+	if len(indexes) != 2 {
+		t.Fatalf("Expected 2 indexes, got %v", indexes)
+	}
+	idxAB = indexes[collection.Options.Indexes[0].TableIndexName(collection)]
+	if strings.Contains(idxAB, "UNIQUE") || !strings.Contains(idxAB, "(`b`, `a`)") {
+		t.Fatalf("Unexpected updated idx_ab definition %q", idxAB)
+	}
+	if _, ok := indexes[collection.Options.Indexes[1].TableIndexName(collection)]; !ok {
+		t.Fatalf("Missing idx_b index in %v", indexes)
+	}
+
+	// indexed columns could be deleted together with their indexes
+	collection.Schema.RemoveField(collection.Schema.GetFieldByName("a").Id)
+	collection.Options.Indexes = collection.Options.Indexes[1:]
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+	if indexes = findIndexes("indexes_test_renamed"); len(indexes) != 1 {
+		t.Fatalf("Expected 1 index, got %v", indexes)
+	}
+
+	// invalid index definition should rollback the changes
+	collection.Schema.AddField(&schema.SchemaField{Name: "c", Type: schema.FieldTypeText})
+	collection.Options.Indexes = []models.CollectionIndex{
+		{Name: "idx_invalid", Fields: []string{"b"}, Where: "missing = 1"},
+	}
+	if err := app.Dao().SaveCollection(collection); err == nil {
+		t.Fatal("Expected the invalid index creation to fail")
+	}
+	if indexes = findIndexes("indexes_test_renamed"); len(indexes) != 1 {
+		t.Fatalf("Expected the original index to remain, got %v", indexes)
+	}
+	if cols, _ := app.Dao().GetTableColumns("indexes_test_renamed"); list.ExistInSlice("c", cols) {
+		t.Fatalf("Expected the new column to be rolled back, got %v", cols)
+	}
+}
+
+func TestSyncRecordTableSchemaRelationIndexes(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	findIndexes := func(table string) map[string]string {
+		rows := []struct {
+			Name string `db:"name"`
+			Sql  string `db:"sql"`
+		}{}
+
+		err := app.Dao().DB().Select("name", "sql").
+			From("sqlite_master").
+			AndWhere(dbx.HashExp{"type": "index", "tbl_name": table}).
+			AndWhere(dbx.NewExp("[[name]] LIKE '\\_ridx\\_%' ESCAPE '\\'")).
+			All(&rows)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result := map[string]string{}
+		for _, row := range rows {
+			result[row.Name] = row.Sql
+		}
+
+		return result
+	}
+
+	collection := &models.Collection{
+		Name: "relation_indexes_test",
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "title", Type: schema.FieldTypeText},
+			&schema.SchemaField{
+				Name:    "one",
+				Type:    schema.FieldTypeRelation,
+				Options: &schema.RelationOptions{MaxSelect: 1, CollectionId: "f12f3eb6-b980-4bf6-b1e4-36de0450c8be"},
+			},
+			&schema.SchemaField{
+				Name:    "many",
+				Type:    schema.FieldTypeRelation,
+				Options: &schema.RelationOptions{MaxSelect: 5, CollectionId: "f12f3eb6-b980-4bf6-b1e4-36de0450c8be"},
+			},
+		),
+		Options: models.CollectionOptions{RelationIndexes: true},
+	}
+
+	// create
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	indexes := findIndexes("relation_indexes_test")
+	if len(indexes) != 1 {
+		t.Fatalf("Expected 1 relation index, got %v", indexes)
+	}
+	for name, column := range collection.RelationIndexes() {
+		if !strings.Contains(indexes[name], "(`"+column+"`)") {
+			t.Fatalf("Expected index %q on column %q, got %v", name, column, indexes)
+		}
+	}
+
+	// renamed table and relation field should preserve the index
+	collection.Name = "relation_indexes_test_renamed"
+	collection.Schema.GetFieldByName("one").Name = "one_renamed"
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+	if indexes = findIndexes("relation_indexes_test_renamed"); len(indexes) != 1 {
+		t.Fatalf("Expected 1 relation index, got %v", indexes)
+	}
+
+	// indexed relation field could be deleted together with its index
+	collection.Schema.RemoveField(collection.Schema.GetFieldByName("one_renamed").Id)
+	collection.Schema.AddField(&schema.SchemaField{
+		Name:    "two",
+		Type:    schema.FieldTypeRelation,
+		Options: &schema.RelationOptions{MaxSelect: 1, CollectionId: "f12f3eb6-b980-4bf6-b1e4-36de0450c8be"},
+	})
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+	indexes = findIndexes("relation_indexes_test_renamed")
+	if len(indexes) != 1 {
+		t.Fatalf("Expected 1 relation index, got %v", indexes)
+	}
+	for _, sql := range indexes {
+		if !strings.Contains(sql, "(`two`)") {
+			t.Fatalf("Expected index on the new relation field, got %q", sql)
+		}
+	}
+
+	// disabled option should drop the indexes
+	collection.Options.RelationIndexes = false
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+	if indexes = findIndexes("relation_indexes_test_renamed"); len(indexes) != 0 {
+		t.Fatalf("Expected no relation indexes, got %v", indexes)
+	}
+}
+
+func TestEstimateRecordsCount(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, _ := app.Dao().FindCollectionByNameOrId("demo")
+
+	// no collected statistics
+	if _, err := app.Dao().EstimateRecordsCount(collection); err == nil {
+		t.Fatal("Expected error without collected statistics, got nil")
+	}
+
+	if _, err := app.Dao().DB().NewQuery("ANALYZE").Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	var total int64
+	if err := app.Dao().RecordQuery(collection).Select("count(*)").Row(&total); err != nil {
+		t.Fatal(err)
+	}
+
+	estimate, err := app.Dao().EstimateRecordsCount(collection)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if estimate != total {
+		t.Fatalf("Expected estimate %d, got %d", total, estimate)
+	}
+}
@@ -0,0 +1,229 @@
+package daos
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// recordVersionsSyncBatchSize is the number of record versions
+// loaded at once on collection schema change.
+const recordVersionsSyncBatchSize = 500
+
+// RecordVersionQuery returns a new RecordVersion select query.
+func (dao *Dao) RecordVersionQuery() *dbx.SelectQuery {
+	return dao.ModelQuery(&models.RecordVersion{})
+}
+
+// FindRecordVersion returns a single RecordVersion model
+// of the provided record by its version number.
+func (dao *Dao) FindRecordVersion(record *models.Record, version int) (*models.RecordVersion, error) {
+	model := &models.RecordVersion{}
+
+	err := dao.RecordVersionQuery().
+		AndWhere(dbx.HashExp{
+			"collectionId": record.Collection().Id,
+			"recordId":     record.Id,
+			"version":      version,
+		}).
+		Limit(1).
+		One(model)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// FindRecordVersionState returns a new Record model with the full
+// state of the provided record version.
+//
+// Because only the changed fields are stored, the state is
+// reconstructed by applying the stored values of all newer versions
+// (from the newest to the requested one) on the current record data.
+func (dao *Dao) FindRecordVersionState(record *models.Record, version int) (*models.Record, error) {
+	versions := []*models.RecordVersion{}
+
+	err := dao.RecordVersionQuery().
+		AndWhere(dbx.HashExp{
+			"collectionId": record.Collection().Id,
+			"recordId":     record.Id,
+		}).
+		AndWhere(dbx.NewExp("[[version]] >= {:version}", dbx.Params{"version": version})).
+		OrderBy("version DESC").
+		All(&versions)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(versions) == 0 || versions[len(versions)-1].Version != version {
+		return nil, errors.New("Missing record version.")
+	}
+
+	data := record.Data()
+	data[schema.ReservedFieldNameUpdated] = record.Updated
+	for _, v := range versions {
+		for key, value := range v.Data {
+			data[key] = value
+		}
+	}
+
+	state := models.NewRecord(record.Collection())
+	if err := state.Load(data); err != nil {
+		return nil, err
+	}
+	state.Id = record.Id
+	state.Created = record.Created
+
+	return state, nil
+}
+
+// DeleteRecordVersions deletes all stored versions of the provided record.
+func (dao *Dao) DeleteRecordVersions(record *models.Record) error {
+	_, err := dao.DB().Delete((&models.RecordVersion{}).TableName(), dbx.HashExp{
+		"collectionId": record.Collection().Id,
+		"recordId":     record.Id,
+	}).Execute()
+
+	return err
+}
+
+// deleteCollectionRecordVersions deletes the stored versions
+// of all records of the provided collection.
+func (dao *Dao) deleteCollectionRecordVersions(collectionId string) error {
+	_, err := dao.DB().Delete((&models.RecordVersion{}).TableName(), dbx.HashExp{
+		"collectionId": collectionId,
+	}).Execute()
+
+	return err
+}
+
+// syncRecordVersionsSchema renames the stored versions values of the
+// renamed collection fields and removes the ones of the deleted fields,
+// so that the older versions could still be restored (and a new field
+// with the name of a deleted one doesn't get its stale values).
+//
+// Note that a version value of a field with changed type or options
+// could still fail the restore validation.
+func (dao *Dao) syncRecordVersionsSchema(newCollection *models.Collection, oldCollection *models.Collection) error {
+	// old field name -> new field name (empty for deleted)
+	renamed := map[string]string{}
+	for _, oldField := range oldCollection.Schema.Fields() {
+		newField := newCollection.Schema.GetFieldById(oldField.Id)
+		if newField == nil {
+			renamed[oldField.Name] = ""
+		} else if newField.Name != oldField.Name {
+			renamed[oldField.Name] = newField.Name
+		}
+	}
+
+	if len(renamed) == 0 {
+		return nil
+	}
+
+	var lastId string
+	for {
+		versions := []*models.RecordVersion{}
+
+		err := dao.RecordVersionQuery().
+			AndWhere(dbx.HashExp{"collectionId": oldCollection.Id}).
+			AndWhere(dbx.NewExp("[[id]] > {:lastId}", dbx.Params{"lastId": lastId})).
+			OrderBy("id ASC").
+			Limit(recordVersionsSyncBatchSize).
+			All(&versions)
+		if err != nil {
+			return err
+		}
+
+		for _, v := range versions {
+			data := types.JsonMap{}
+			for key, value := range v.Data {
+				if newName, ok := renamed[key]; ok {
+					if newName == "" {
+						continue // deleted field
+					}
+					key = newName
+				}
+				data[key] = value
+			}
+
+			_, err := dao.DB().Update(v.TableName(), dbx.Params{"data": data}, dbx.HashExp{"id": v.Id}).Execute()
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(versions) < recordVersionsSyncBatchSize {
+			return nil
+		}
+
+		lastId = versions[len(versions)-1].Id
+	}
+}
+
+// saveRecordWithVersion updates the provided record and stores
+// the values of its changed fields as they were before the update
+// as a new record version.
+//
+// The previous state is loaded from the db (instead of relying on the
+// record original data) so that the changes made by the model
+// hooks are also taken into account.
+//
+// It is expected to be called within a transaction.
+func (dao *Dao) saveRecordWithVersion(record *models.Record, updatedBy string) error {
+	previous, _ := dao.FindRecordById(record.Collection(), record.Id, nil)
+
+	if err := dao.Save(record); err != nil {
+		return err
+	}
+
+	if previous == nil {
+		return nil // nothing to compare with
+	}
+
+	data := map[string]any{}
+	for _, field := range record.Collection().Schema.Fields() {
+		oldValue := previous.GetDataValue(field.Name)
+
+		// compare the serialized values to normalize the different
+		// representations of the same value (eg. dates)
+		oldRaw, _ := json.Marshal(oldValue)
+		newRaw, _ := json.Marshal(record.GetDataValue(field.Name))
+
+		if !bytes.Equal(oldRaw, newRaw) {
+			data[field.Name] = oldValue
+		}
+	}
+
+	if len(data) == 0 {
+		return nil // no changes
+	}
+
+	data[schema.ReservedFieldNameUpdated] = previous.Updated
+
+	var lastVersion int
+	err := dao.RecordVersionQuery().
+		Select("coalesce(max([[version]]), 0)").
+		AndWhere(dbx.HashExp{
+			"collectionId": record.Collection().Id,
+			"recordId":     record.Id,
+		}).
+		Row(&lastVersion)
+	if err != nil {
+		return err
+	}
+
+	return dao.Save(&models.RecordVersion{
+		CollectionId: record.Collection().Id,
+		RecordId:     record.Id,
+		Version:      lastVersion + 1,
+		Data:         data,
+		UpdatedBy:    updatedBy,
+	})
+}
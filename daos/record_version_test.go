@@ -0,0 +1,204 @@
+package daos_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestSaveRecordWithVersions(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, _ := app.Dao().FindCollectionByNameOrId("demo3")
+
+	countVersions := func(record *models.Record) int {
+		var total int
+		app.Dao().RecordVersionQuery().
+			Select("count(*)").
+			AndWhere(dbx.HashExp{"collectionId": collection.Id, "recordId": record.Id}).
+			Row(&total)
+		return total
+	}
+
+	record, err := app.Dao().FindRecordById(collection, "2c542824-9de1-42fe-8924-e57c86267760", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// versions disabled
+	// ---
+	record.SetDataValue("title", "disabled")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+	if total := countVersions(record); total != 0 {
+		t.Fatalf("Expected no versions, got %d", total)
+	}
+
+	// versions enabled
+	// ---
+	collection.Options.Versions = true
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+	record, _ = app.Dao().FindRecordById(collection, record.Id, nil)
+
+	// new records are not versioned
+	newRecord := models.NewRecord(collection)
+	newRecord.SetDataValue("title", "new")
+	if err := app.Dao().SaveRecord(newRecord); err != nil {
+		t.Fatal(err)
+	}
+	if total := countVersions(newRecord); total != 0 {
+		t.Fatalf("Expected no versions for the new record, got %d", total)
+	}
+
+	record.SetDataValue("title", "v2")
+	if err := app.Dao().SaveRecordBy(record, "admin:test"); err != nil {
+		t.Fatal(err)
+	}
+
+	// unchanged data
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	record.SetDataValue("title", "v3")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	if total := countVersions(record); total != 2 {
+		t.Fatalf("Expected 2 versions, got %d", total)
+	}
+
+	version1, err := app.Dao().FindRecordVersion(record, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version1.UpdatedBy != "admin:test" {
+		t.Fatalf("Expected updatedBy %q, got %q", "admin:test", version1.UpdatedBy)
+	}
+	if len(version1.Data) != 2 || version1.Data["title"] != "disabled" || version1.Data["updated"] == nil {
+		t.Fatalf("Expected only the changed title and updated values, got %v", version1.Data)
+	}
+
+	// state reconstruction
+	// ---
+	scenarios := []struct {
+		version       int
+		expectError   bool
+		expectedTitle string
+	}{
+		{0, true, ""},
+		{3, true, ""},
+		{1, false, "disabled"},
+		{2, false, "v2"},
+	}
+
+	for i, s := range scenarios {
+		state, err := app.Dao().FindRecordVersionState(record, s.version)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+			continue
+		}
+
+		if hasErr {
+			continue
+		}
+
+		if state.Id != record.Id {
+			t.Errorf("(%d) Expected state id %q, got %q", i, record.Id, state.Id)
+		}
+		if v := state.GetStringDataValue("title"); v != s.expectedTitle {
+			t.Errorf("(%d) Expected title %q, got %q", i, s.expectedTitle, v)
+		}
+		if state.Created.String() != record.Created.String() {
+			t.Errorf("(%d) Expected created %q, got %q", i, record.Created.String(), state.Created.String())
+		}
+	}
+
+	// the versions are deleted together with the record
+	if err := app.Dao().DeleteRecord(record); err != nil {
+		t.Fatal(err)
+	}
+	if total := countVersions(record); total != 0 {
+		t.Fatalf("Expected the record versions to be deleted, got %d", total)
+	}
+}
+
+func TestRecordVersionsCollectionChange(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := &models.Collection{
+		Name:    "versioned",
+		Options: models.CollectionOptions{Versions: true},
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "title", Type: schema.FieldTypeText},
+			&schema.SchemaField{Name: "note", Type: schema.FieldTypeText},
+		),
+	}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	record := models.NewRecord(collection)
+	record.SetDataValue("title", "v1")
+	record.SetDataValue("note", "n1")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	record.SetDataValue("title", "v2")
+	record.SetDataValue("note", "n2")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	// rename "title" and delete "note"
+	collection.Schema.GetFieldByName("title").Name = "name"
+	collection.Schema.RemoveField(collection.Schema.GetFieldByName("note").Id)
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	version, err := app.Dao().FindRecordVersion(record, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := version.Data["note"]; ok {
+		t.Fatalf("Expected the deleted field value to be removed, got %v", version.Data)
+	}
+	if _, ok := version.Data["title"]; ok {
+		t.Fatalf("Expected the renamed field old key to be removed, got %v", version.Data)
+	}
+
+	record, _ = app.Dao().FindRecordById(collection, record.Id, nil)
+	state, err := app.Dao().FindRecordVersionState(record, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := state.GetStringDataValue("name"); v != "v1" {
+		t.Fatalf("Expected the renamed field version value %q, got %q", "v1", v)
+	}
+
+	// the versions are deleted together with the collection
+	if err := app.Dao().DeleteCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+	var total int
+	app.Dao().RecordVersionQuery().
+		Select("count(*)").
+		AndWhere(dbx.HashExp{"collectionId": collection.Id}).
+		Row(&total)
+	if total != 0 {
+		t.Fatalf("Expected the collection versions to be deleted, got %d", total)
+	}
+}
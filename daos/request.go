@@ -35,20 +35,43 @@ type RequestsStatsItem struct {
 }
 
 // RequestsStats returns hourly grouped requests logs statistics.
+//
+// The hours are bucketed in the Dao timezone (see [Dao.TimezoneFunc])
+// and the returned item dates are the UTC start of each local hour.
 func (dao *Dao) RequestsStats(expr dbx.Expression) ([]*RequestsStatsItem, error) {
-	result := []*RequestsStatsItem{}
+	rows := []struct {
+		Total int    `db:"total"`
+		Date  string `db:"date"`
+	}{}
 
 	query := dao.RequestQuery().
-		Select("count(id) as total", "strftime('%Y-%m-%d %H:00:00', created) as date").
+		Select("count(id) as total", dao.strftimeExpr("%Y-%m-%d %H:00:00", "created")+" as date").
 		GroupBy("date")
 
 	if expr != nil {
 		query.AndWhere(expr)
 	}
 
-	err := query.All(&result)
+	if err := query.All(&rows); err != nil {
+		return nil, err
+	}
+
+	loc := dao.timezone()
+
+	result := make([]*RequestsStatsItem, 0, len(rows))
+	for _, row := range rows {
+		date, err := time.ParseInLocation("2006-01-02 15:04:05", row.Date, loc)
+		if err != nil {
+			return nil, err
+		}
+
+		item := &RequestsStatsItem{Total: row.Total}
+		item.Date, _ = types.ParseDateTime(date)
+
+		result = append(result, item)
+	}
 
-	return result, err
+	return result, nil
 }
 
 // DeleteOldRequests delete all requests that are created before createdBefore.
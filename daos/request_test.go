@@ -74,6 +74,28 @@ func TestRequestsStats(t *testing.T) {
 	}
 }
 
+func TestRequestsStatsWithTimezone(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	tests.MockRequestLogsData(app)
+
+	app.Settings().Timezone.Name = "Asia/Kolkata" // +05:30
+
+	// 10:00 UTC is 15:30 local time, aka. the 15:00 local hour bucket
+	expected := `[{"total":1,"date":"2022-05-01 09:30:00.000"},{"total":1,"date":"2022-05-02 09:30:00.000"}]`
+
+	result, err := app.LogsDao().RequestsStats(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, _ := json.Marshal(result)
+	if string(encoded) != expected {
+		t.Fatalf("Expected %s, got %s", expected, string(encoded))
+	}
+}
+
 func TestDeleteOldRequests(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -0,0 +1,112 @@
+package daos
+
+import (
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+)
+
+// RetryOptions defines the Dao retry options of the operations
+// failed with a transient SQLite error (see [IsTransientError]).
+type RetryOptions struct {
+	// MaxAttempts is the max number of executions of a single operation
+	// (including the first one).
+	//
+	// Values <= 1 disable the retry.
+	MaxAttempts int
+
+	// MaxTime is the max total duration of all operation attempts
+	// (no new attempt is made if its delay exceeds it, 0 for no limit).
+	MaxTime time.Duration
+
+	// BaseDelay is the delay before the first retry
+	// (doubled before each next one).
+	BaseDelay time.Duration
+
+	// MaxDelay is the max delay before a single retry (0 for no limit).
+	MaxDelay time.Duration
+}
+
+// delay returns the randomized exponential backoff delay
+// before the specified retry (starting from 1).
+func (o RetryOptions) delay(retry int) time.Duration {
+	delay := o.BaseDelay
+	for i := 1; i < retry && (o.MaxDelay <= 0 || delay < o.MaxDelay); i++ {
+		delay *= 2
+	}
+
+	if o.MaxDelay > 0 && delay > o.MaxDelay {
+		delay = o.MaxDelay
+	}
+
+	// "equal jitter" - keep at least half of the delay
+	// and randomize the rest to spread the concurrent retries
+	half := int64(delay / 2)
+	if half <= 0 {
+		return delay
+	}
+
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+// IsTransientError reports whether err is a transient SQLite error
+// (SQLITE_BUSY or SQLITE_LOCKED) and the failed operation could
+// succeed if retried.
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	// the error codes are checked by their messages to support
+	// both the cgo and the pure Go SQLite drivers
+	msg := err.Error()
+
+	return strings.Contains(msg, "database is locked") ||
+		strings.Contains(msg, "database table is locked") ||
+		strings.Contains(msg, "database schema is locked") ||
+		strings.Contains(msg, "SQLITE_BUSY") ||
+		strings.Contains(msg, "SQLITE_LOCKED")
+}
+
+// WithRetry executes fn and retries it on transient db errors
+// according to dao.Retry (all other errors are returned immediately).
+//
+// Within a transaction fn is executed only once, because a failed
+// statement can't be safely retried as part of the same transaction
+// (the whole transaction is retried instead, see [Dao.RunInTransaction]).
+//
+// It could be used to execute custom queries, eg.:
+//
+//	err := dao.WithRetry(func() error {
+//		result = result[:0] // All appends to the slice
+//		return dao.DB().NewQuery("SELECT ...").All(&result)
+//	})
+func (dao *Dao) WithRetry(fn func() error) error {
+	if _, ok := dao.db.(*dbx.Tx); ok {
+		return fn()
+	}
+
+	return dao.retry(fn, IsTransientError)
+}
+
+// retry executes fn and retries it according to dao.Retry
+// while it fails with an error accepted by shouldRetry.
+func (dao *Dao) retry(fn func() error, shouldRetry func(err error) bool) error {
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= dao.Retry.MaxAttempts || !shouldRetry(err) {
+			return err
+		}
+
+		delay := dao.Retry.delay(attempt)
+		if dao.Retry.MaxTime > 0 && time.Since(start)+delay > dao.Retry.MaxTime {
+			return err
+		}
+
+		time.Sleep(delay)
+	}
+}
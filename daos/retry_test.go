@@ -0,0 +1,275 @@
+package daos_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestIsTransientError(t *testing.T) {
+	scenarios := []struct {
+		err      error
+		expected bool
+	}{
+		{nil, false},
+		{errors.New("test"), false},
+		{errors.New("UNIQUE constraint failed: demo.id"), false},
+		{errors.New("database is locked"), true},
+		{errors.New("database is locked (5) (SQLITE_BUSY)"), true},
+		{errors.New("database table is locked"), true},
+		{errors.New("database schema is locked: main"), true},
+		{errors.New("disk I/O error (SQLITE_BUSY_RECOVERY)"), true},
+		{errors.New("SQLITE_LOCKED_SHAREDCACHE"), true},
+	}
+
+	for i, s := range scenarios {
+		if result := daos.IsTransientError(s.err); result != s.expected {
+			t.Errorf("(%d) Expected %v for %v, got %v", i, s.expected, s.err, result)
+		}
+	}
+}
+
+func TestDaoWithRetry(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	transientErr := errors.New("database is locked")
+	otherErr := errors.New("test")
+
+	scenarios := []struct {
+		name             string
+		retry            daos.RetryOptions
+		errs             []error
+		expectedAttempts int
+		expectedErr      error
+	}{
+		{
+			"disabled retry",
+			daos.RetryOptions{},
+			[]error{transientErr, nil},
+			1,
+			transientErr,
+		},
+		{
+			"success on first attempt",
+			daos.RetryOptions{MaxAttempts: 3, BaseDelay: time.Millisecond},
+			[]error{nil},
+			1,
+			nil,
+		},
+		{
+			"non transient error",
+			daos.RetryOptions{MaxAttempts: 3, BaseDelay: time.Millisecond},
+			[]error{otherErr, nil},
+			1,
+			otherErr,
+		},
+		{
+			"success after retry",
+			daos.RetryOptions{MaxAttempts: 3, BaseDelay: time.Millisecond},
+			[]error{transientErr, transientErr, nil},
+			3,
+			nil,
+		},
+		{
+			"max attempts reached",
+			daos.RetryOptions{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond},
+			[]error{transientErr, transientErr, transientErr, nil},
+			3,
+			transientErr,
+		},
+		{
+			"max time reached",
+			daos.RetryOptions{MaxAttempts: 10, BaseDelay: 50 * time.Millisecond, MaxTime: 60 * time.Millisecond},
+			[]error{transientErr, transientErr, transientErr, nil},
+			2,
+			transientErr,
+		},
+	}
+
+	for _, s := range scenarios {
+		dao := daos.New(testApp.DB())
+		dao.Retry = s.retry
+
+		attempts := 0
+		err := dao.WithRetry(func() error {
+			attempts++
+			return s.errs[attempts-1]
+		})
+
+		if attempts != s.expectedAttempts {
+			t.Errorf("[%s] Expected %d attempts, got %d", s.name, s.expectedAttempts, attempts)
+		}
+
+		if err != s.expectedErr {
+			t.Errorf("[%s] Expected error %v, got %v", s.name, s.expectedErr, err)
+		}
+	}
+}
+
+func TestDaoRunInTransactionRetry(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	dao := daos.New(testApp.DB())
+	dao.Retry = daos.RetryOptions{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	transientErr := errors.New("database is locked")
+
+	txAttempts := 0
+	nestedAttempts := 0
+	err := dao.RunInTransaction(func(txDao *daos.Dao) error {
+		txAttempts++
+
+		// the statements within the transaction shouldn't be retried
+		txDao.WithRetry(func() error {
+			nestedAttempts++
+			return transientErr
+		})
+
+		if txAttempts < 3 {
+			return transientErr
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Expected the transaction to succeed, got %v", err)
+	}
+
+	if txAttempts != 3 {
+		t.Fatalf("Expected the transaction to be executed 3 times, got %d", txAttempts)
+	}
+
+	if nestedAttempts != 3 {
+		t.Fatalf("Expected the nested operation to be executed once per transaction, got %d", nestedAttempts)
+	}
+
+	// non transient errors shouldn't be retried
+	txAttempts = 0
+	err = dao.RunInTransaction(func(txDao *daos.Dao) error {
+		txAttempts++
+		return errors.New("test")
+	})
+
+	if err == nil || txAttempts != 1 {
+		t.Fatalf("Expected the transaction to fail once, got %d attempts (%v)", txAttempts, err)
+	}
+}
+
+func TestDaoRunInTransactionRetryCreatedModels(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	dao := daos.New(testApp.DB())
+	dao.Retry = daos.RetryOptions{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	collection, err := dao.FindCollectionByNameOrId("demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := models.NewRecord(collection)
+	record.SetDataValue("title", "retry")
+
+	attempts := 0
+	err = dao.RunInTransaction(func(txDao *daos.Dao) error {
+		attempts++
+
+		if err := txDao.Save(record); err != nil {
+			return err
+		}
+
+		if attempts < 2 {
+			return errors.New("database is locked")
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected the transaction to succeed, got %v", err)
+	}
+
+	if attempts != 2 {
+		t.Fatalf("Expected the transaction to be executed 2 times, got %d", attempts)
+	}
+
+	// the record should be created again by the retried transaction
+	if _, err := dao.FindRecordById(collection, record.Id, nil); err != nil {
+		t.Fatalf("Expected the record to be created, got %v", err)
+	}
+}
+
+func TestDaoRunInTransactionNoRetryWithSideEffects(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	collection, err := testApp.Dao().FindCollectionByNameOrId("demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name string
+		fn   func(txDao *daos.Dao, record *models.Record) error
+	}{
+		{
+			"after model func",
+			func(txDao *daos.Dao, record *models.Record) error {
+				return txDao.Save(record)
+			},
+		},
+		{
+			"explicit side effect",
+			func(txDao *daos.Dao, record *models.Record) error {
+				txDao.MarkSideEffect()
+				return nil
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		afterCreateCalls := 0
+
+		dao := daos.New(testApp.DB())
+		dao.Retry = daos.RetryOptions{MaxAttempts: 3, BaseDelay: time.Millisecond}
+		dao.AfterCreateFunc = func(eventDao *daos.Dao, m models.Model) {
+			afterCreateCalls++
+		}
+
+		record := models.NewRecord(collection)
+		record.SetDataValue("title", "side effects")
+
+		attempts := 0
+		err := dao.RunInTransaction(func(txDao *daos.Dao) error {
+			attempts++
+
+			if err := s.fn(txDao, record); err != nil {
+				return err
+			}
+
+			return errors.New("database is locked")
+		})
+		if err == nil {
+			t.Errorf("[%s] Expected the transaction to fail", s.name)
+		}
+
+		if attempts != 1 {
+			t.Errorf("[%s] Expected the transaction to be executed once, got %d", s.name, attempts)
+		}
+
+		if afterCreateCalls > 1 {
+			t.Errorf("[%s] Expected the after create func to be called at most once, got %d", s.name, afterCreateCalls)
+		}
+
+		// the rolled back record should be saved as new
+		if !record.IsNew() {
+			t.Errorf("[%s] Expected the rolled back record to be marked as new", s.name)
+		}
+	}
+}
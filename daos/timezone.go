@@ -0,0 +1,34 @@
+package daos
+
+import "time"
+
+// TimezoneStrftimeFunc is the name of the SQLite strftime compatible db
+// function that formats the stored UTC dates in the Dao timezone.
+//
+// The function is expected to be registered on the Dao db connections
+// by its owner (eg. core.BaseApp) when [Dao.TimezoneFunc] is set.
+const TimezoneStrftimeFunc = "tz_strftime"
+
+// timezone returns the Dao timezone location (fallbacks to UTC).
+func (dao *Dao) timezone() *time.Location {
+	if dao.TimezoneFunc == nil {
+		return time.UTC
+	}
+
+	if loc := dao.TimezoneFunc(); loc != nil {
+		return loc
+	}
+
+	return time.UTC
+}
+
+// strftimeExpr returns the db expression that formats the
+// provided column date in the Dao timezone.
+func (dao *Dao) strftimeExpr(format string, column string) string {
+	fn := "strftime"
+	if dao.timezone() != time.UTC {
+		fn = TimezoneStrftimeFunc
+	}
+
+	return fn + "('" + format + "', [[" + column + "]])"
+}
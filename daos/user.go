@@ -124,6 +124,10 @@ func (dao *Dao) FindUserByEmail(email string) (*models.User, error) {
 // This method also auto loads the related user profile record
 // into the found model.
 func (dao *Dao) FindUserByToken(token string, baseTokenKey string) (*models.User, error) {
+	if baseTokenKey == "" {
+		return nil, errors.New("Missing or unknown token verification key.")
+	}
+
 	unverifiedClaims, err := security.ParseUnverifiedJWT(token)
 	if err != nil {
 		return nil, err
@@ -143,7 +147,12 @@ func (dao *Dao) FindUserByToken(token string, baseTokenKey string) (*models.User
 	verificationKey := user.TokenKey + baseTokenKey
 
 	// verify token signature
-	if _, err := security.ParseJWT(token, verificationKey); err != nil {
+	claims, err := security.ParseJWT(token, verificationKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dao.checkTokenAudience(claims); err != nil {
 		return nil, err
 	}
 
@@ -241,6 +250,82 @@ func (dao *Dao) DeleteUser(user *models.User) error {
 	})
 }
 
+// UserDeleteReport defines the records changes that are
+// applied on [Dao.DeleteUser] (eg. due to cascade delete).
+type UserDeleteReport struct {
+	// DeletedRecords is a list with all cascade deleted records.
+	DeletedRecords []*models.Record
+
+	// UpdatedRecords is a list with all records that had
+	// their user or relation reference(s) unset.
+	UpdatedRecords []*models.Record
+}
+
+// errDryRun is used to rollback the dry run transactions.
+var errDryRun = errors.New("dry run")
+
+// DeleteUserDryRun reports the records that will be deleted or updated
+// on [Dao.DeleteUser] WITHOUT actually persisting the changes.
+//
+// The dry run executes the regular delete within a transaction that is
+// always rolled back and doesn't trigger the dao model event hooks.
+//
+// Note that this method cannot be called within a transaction.
+func (dao *Dao) DeleteUserDryRun(user *models.User) (*UserDeleteReport, error) {
+	db, ok := dao.db.(*dbx.DB)
+	if !ok {
+		return nil, errors.New("The delete dry run is not supported within a transaction.")
+	}
+
+	report := &UserDeleteReport{
+		DeletedRecords: []*models.Record{},
+		UpdatedRecords: []*models.Record{},
+	}
+
+	deleted := map[string]struct{}{}
+	updated := map[string]*models.Record{}
+	updatedOrder := []string{}
+
+	dryDao := New(db)
+	dryDao.BeforeDeleteFunc = func(eventDao *Dao, m models.Model) error {
+		if record, ok := m.(*models.Record); ok {
+			deleted[record.Collection().Id+"/"+record.Id] = struct{}{}
+			report.DeletedRecords = append(report.DeletedRecords, record)
+		}
+		return nil
+	}
+	dryDao.BeforeUpdateFunc = func(eventDao *Dao, m models.Model) error {
+		if record, ok := m.(*models.Record); ok {
+			key := record.Collection().Id + "/" + record.Id
+			if _, ok := updated[key]; !ok {
+				updatedOrder = append(updatedOrder, key)
+			}
+			updated[key] = record
+		}
+		return nil
+	}
+
+	err := dryDao.RunInTransaction(func(txDao *Dao) error {
+		if err := txDao.DeleteUser(user); err != nil {
+			return err
+		}
+
+		return errDryRun
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+
+	// exclude the updated records that were later deleted
+	for _, key := range updatedOrder {
+		if _, ok := deleted[key]; !ok {
+			report.UpdatedRecords = append(report.UpdatedRecords, updated[key])
+		}
+	}
+
+	return report, nil
+}
+
 // SaveUser upserts the provided User model.
 //
 // An empty profile record will be created if the user
@@ -0,0 +1,76 @@
+package forms
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// AdminMagicLinkLogin defines an admin magic link login form.
+type AdminMagicLinkLogin struct {
+	app core.App
+
+	Token string `form:"token" json:"token"`
+}
+
+// NewAdminMagicLinkLogin creates new admin magic link login form.
+func NewAdminMagicLinkLogin(app core.App) *AdminMagicLinkLogin {
+	return &AdminMagicLinkLogin{
+		app: app,
+	}
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *AdminMagicLinkLogin) Validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Token, validation.Required, validation.By(form.checkToken)),
+	)
+}
+
+func (form *AdminMagicLinkLogin) checkToken(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil // nothing to check
+	}
+
+	if admin, _ := form.findAdmin(v); admin == nil {
+		return validation.NewError("validation_invalid_token", "Invalid or expired token.")
+	}
+
+	return nil
+}
+
+func (form *AdminMagicLinkLogin) findAdmin(token string) (*models.Admin, error) {
+	return form.app.Dao().FindAdminByToken(
+		token,
+		form.app.Settings().AdminMagicLinkToken.VerificationSecret(token),
+	)
+}
+
+// Submit validates and submits the admin magic link login form.
+// On success returns the admin model associated to `form.Token`.
+//
+// The token is invalidated and couldn't be used again.
+func (form *AdminMagicLinkLogin) Submit() (*models.Admin, error) {
+	if !form.app.Settings().AdminMagicLink.Enabled {
+		return nil, ErrAdminMagicLinkDisabled
+	}
+
+	if err := form.Validate(); err != nil {
+		return nil, err
+	}
+
+	admin, err := form.findAdmin(form.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	if !consumeAdminMagicLink(form.app, form.Token) {
+		return nil, validation.Errors{"token": validation.NewError(
+			"validation_invalid_token",
+			"The token was already used or has expired.",
+		)}
+	}
+
+	return admin, nil
+}
@@ -0,0 +1,83 @@
+package forms_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tokens"
+	"github.com/spf13/cast"
+)
+
+func TestAdminMagicLinkLoginSubmit(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	testApp.Settings().AdminMagicLink.Enabled = true
+
+	var issuedToken string
+	testApp.OnMailerBeforeAdminMagicLinkSend().Add(func(e *core.MailerAdminEvent) error {
+		issuedToken = cast.ToString(e.Meta["token"])
+		return nil
+	})
+
+	requestForm := forms.NewAdminMagicLinkRequest(testApp)
+	requestForm.Email = "test@example.com"
+	if err := requestForm.Submit(); err != nil {
+		t.Fatal(err)
+	}
+
+	admin, _ := testApp.Dao().FindAdminByEmail("test@example.com")
+
+	// valid but not issued with a request token
+	notIssuedToken, _ := tokens.NewAdminMagicLinkToken(testApp, admin)
+
+	// issued token of another type
+	authToken, _ := tokens.NewAdminAuthToken(testApp, admin)
+
+	scenarios := []struct {
+		name        string
+		token       string
+		expectError bool
+	}{
+		{"empty token", "", true},
+		{"invalid token", "invalid", true},
+		{"admin auth token", authToken, true},
+		{"not issued token", notIssuedToken, true},
+		{"issued token", issuedToken, false},
+		{"already used token", issuedToken, true},
+	}
+
+	for _, s := range scenarios {
+		form := forms.NewAdminMagicLinkLogin(testApp)
+		form.Token = s.token
+
+		result, err := form.Submit()
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("[%s] Expected hasErr to be %v, got %v (%v)", s.name, s.expectError, hasErr, err)
+			continue
+		}
+
+		if !s.expectError && result.Id != admin.Id {
+			t.Errorf("[%s] Expected admin %q, got %v", s.name, admin.Id, result)
+		}
+	}
+}
+
+func TestAdminMagicLinkLoginSubmitDisabled(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	admin, _ := testApp.Dao().FindAdminByEmail("test@example.com")
+	token, _ := tokens.NewAdminMagicLinkToken(testApp, admin)
+
+	form := forms.NewAdminMagicLinkLogin(testApp)
+	form.Token = token
+
+	if _, err := form.Submit(); err != forms.ErrAdminMagicLinkDisabled {
+		t.Fatalf("Expected ErrAdminMagicLinkDisabled, got %v", err)
+	}
+}
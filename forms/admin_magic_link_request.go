@@ -0,0 +1,134 @@
+package forms
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/mails"
+	"github.com/pocketbase/pocketbase/tokens"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/store"
+)
+
+// adminMagicLinksCacheKey is the app cache key of the issued
+// (and still unused) admin magic link token hashes.
+const adminMagicLinksCacheKey = "@adminMagicLinks"
+
+var adminMagicLinksMux sync.Mutex
+
+// ErrAdminMagicLinkDisabled is returned when the admin magic link login is not enabled.
+var ErrAdminMagicLinkDisabled = errors.New("The admin magic link login is not enabled.")
+
+// AdminMagicLinkRequest defines an admin magic link login request form.
+type AdminMagicLinkRequest struct {
+	app core.App
+
+	Email string `form:"email" json:"email"`
+}
+
+// NewAdminMagicLinkRequest creates new admin magic link login request form.
+func NewAdminMagicLinkRequest(app core.App) *AdminMagicLinkRequest {
+	return &AdminMagicLinkRequest{
+		app: app,
+	}
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+//
+// This method doesn't verify that admin with `form.Email` exists (this is done on Submit).
+func (form *AdminMagicLinkRequest) Validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(
+			&form.Email,
+			validation.Required,
+			validation.Length(1, 255),
+			is.Email,
+		),
+	)
+}
+
+// Submit validates and submits the form.
+// On success sends a single-use login link email to the `form.Email` admin.
+func (form *AdminMagicLinkRequest) Submit() error {
+	if !form.app.Settings().AdminMagicLink.Enabled {
+		return ErrAdminMagicLinkDisabled
+	}
+
+	if err := form.Validate(); err != nil {
+		return err
+	}
+
+	admin, err := form.app.Dao().FindAdminByEmail(form.Email)
+	if err != nil {
+		return err
+	}
+
+	token, err := tokens.NewAdminMagicLinkToken(form.app, admin)
+	if err != nil {
+		return err
+	}
+
+	duration := time.Duration(form.app.Settings().AdminMagicLinkToken.Duration) * time.Second
+	registerAdminMagicLink(form.app, token, time.Now().Add(duration))
+
+	if err := mails.SendAdminMagicLink(form.app, admin, token); err != nil {
+		consumeAdminMagicLink(form.app, token)
+		return err
+	}
+
+	return nil
+}
+
+// adminMagicLinksStore returns the app store with the issued magic
+// link token hashes and their expiration time.
+//
+// Note: the caller must hold adminMagicLinksMux.
+func adminMagicLinksStore(app core.App) *store.Store[time.Time] {
+	s, _ := app.Cache().Get(adminMagicLinksCacheKey).(*store.Store[time.Time])
+	if s == nil {
+		s = store.New(map[string]time.Time{})
+		app.Cache().Set(adminMagicLinksCacheKey, s)
+	}
+	return s
+}
+
+// registerAdminMagicLink stores the hash of the provided magic link token
+// (also dropping the expired ones).
+func registerAdminMagicLink(app core.App, token string, expires time.Time) {
+	adminMagicLinksMux.Lock()
+	defer adminMagicLinksMux.Unlock()
+
+	s := adminMagicLinksStore(app)
+
+	now := time.Now()
+	for k, v := range s.GetAll() {
+		if now.After(v) {
+			s.Remove(k)
+		}
+	}
+
+	s.Set(security.S256Challenge(token), expires)
+}
+
+// consumeAdminMagicLink removes the provided magic link token from the store
+// and reports whether it was still unused and not expired.
+func consumeAdminMagicLink(app core.App, token string) bool {
+	adminMagicLinksMux.Lock()
+	defer adminMagicLinksMux.Unlock()
+
+	s := adminMagicLinksStore(app)
+
+	key := security.S256Challenge(token)
+	if !s.Has(key) {
+		return false
+	}
+
+	expires := s.Get(key)
+	s.Remove(key)
+
+	return time.Now().Before(expires)
+}
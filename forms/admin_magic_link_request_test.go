@@ -0,0 +1,82 @@
+package forms_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestAdminMagicLinkRequestValidate(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	form := forms.NewAdminMagicLinkRequest(testApp)
+
+	scenarios := []struct {
+		email       string
+		expectError bool
+	}{
+		{"", true},
+		{"invalid", true},
+		{"missing@example.com", false}, // doesn't check for existing admin
+		{"test@example.com", false},
+	}
+
+	for i, s := range scenarios {
+		form.Email = s.email
+
+		err := form.Validate()
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr to be %v, got %v (%v)", i, s.expectError, hasErr, err)
+		}
+	}
+}
+
+func TestAdminMagicLinkRequestSubmit(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	form := forms.NewAdminMagicLinkRequest(testApp)
+
+	// disabled
+	form.Email = "test@example.com"
+	if err := form.Submit(); err != forms.ErrAdminMagicLinkDisabled {
+		t.Fatalf("Expected ErrAdminMagicLinkDisabled, got %v", err)
+	}
+
+	testApp.Settings().AdminMagicLink.Enabled = true
+
+	scenarios := []struct {
+		email       string
+		expectError bool
+	}{
+		{"", true},
+		{"invalid", true},
+		{"missing@example.com", true},
+		{"test@example.com", false},
+		{"test@example.com", false}, // could be requested multiple times
+	}
+
+	for i, s := range scenarios {
+		testApp.TestMailer.TotalSend = 0 // reset
+		form.Email = s.email
+
+		err := form.Submit()
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr to be %v, got %v (%v)", i, s.expectError, hasErr, err)
+		}
+
+		expectedMails := 1
+		if s.expectError {
+			expectedMails = 0
+		}
+		if testApp.TestMailer.TotalSend != expectedMails {
+			t.Errorf("(%d) Expected %d mail(s) to be sent, got %d", i, expectedMails, testApp.TestMailer.TotalSend)
+		}
+	}
+}
@@ -40,7 +40,7 @@ func (form *AdminPasswordResetConfirm) checkToken(value any) error {
 
 	admin, err := form.app.Dao().FindAdminByToken(
 		v,
-		form.app.Settings().AdminPasswordResetToken.Secret,
+		form.app.Settings().AdminPasswordResetToken.VerificationSecret(v),
 	)
 	if err != nil || admin == nil {
 		return validation.NewError("validation_invalid_token", "Invalid or expired token.")
@@ -58,7 +58,7 @@ func (form *AdminPasswordResetConfirm) Submit() (*models.Admin, error) {
 
 	admin, err := form.app.Dao().FindAdminByToken(
 		form.Token,
-		form.app.Settings().AdminPasswordResetToken.Secret,
+		form.app.Settings().AdminPasswordResetToken.VerificationSecret(form.Token),
 	)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,100 @@
+package forms
+
+import (
+	"fmt"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// AdminPermissionsUpsert defines an admin permissions (collection grants) update form.
+type AdminPermissionsUpsert struct {
+	app   core.App
+	admin *models.Admin
+
+	Restricted  bool              `form:"restricted" json:"restricted"`
+	Collections map[string]string `form:"collections" json:"collections"`
+}
+
+// NewAdminPermissionsUpsert creates new permissions upsert form for the provided admin model.
+func NewAdminPermissionsUpsert(app core.App, admin *models.Admin) *AdminPermissionsUpsert {
+	form := &AdminPermissionsUpsert{
+		app:   app,
+		admin: admin,
+	}
+
+	// load defaults
+	form.Restricted = admin.Permissions.Restricted
+	form.Collections = map[string]string{}
+	for id, access := range admin.Permissions.Collections {
+		form.Collections[id] = access
+	}
+
+	return form
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *AdminPermissionsUpsert) Validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(
+			&form.Restricted,
+			validation.By(form.checkLastSuperAdmin),
+		),
+		validation.Field(
+			&form.Collections,
+			validation.By(form.checkCollectionGrants),
+		),
+	)
+}
+
+func (form *AdminPermissionsUpsert) checkLastSuperAdmin(value any) error {
+	v, _ := value.(bool)
+	if !v || !form.admin.IsSuper() {
+		return nil // not a super admin demotion
+	}
+
+	total, err := form.app.Dao().TotalSuperAdmins()
+	if err != nil || total <= 1 {
+		return validation.NewError("validation_last_super_admin", "You cannot restrict the only existing super admin.")
+	}
+
+	return nil
+}
+
+func (form *AdminPermissionsUpsert) checkCollectionGrants(value any) error {
+	v, _ := value.(map[string]string)
+
+	for id, access := range v {
+		if access != models.AdminAccessRead && access != models.AdminAccessWrite {
+			return validation.NewError(
+				"validation_invalid_collection_access",
+				fmt.Sprintf("Invalid access %q for collection %q (must be %q or %q).", access, id, models.AdminAccessRead, models.AdminAccessWrite),
+			)
+		}
+
+		collection, err := form.app.Dao().FindCollectionByNameOrId(id)
+		if err != nil || collection == nil || collection.Id != id {
+			return validation.NewError(
+				"validation_invalid_collection",
+				fmt.Sprintf("Missing or invalid collection id %q.", id),
+			)
+		}
+	}
+
+	return nil
+}
+
+// Submit validates the form and updates the admin model permissions.
+func (form *AdminPermissionsUpsert) Submit() error {
+	if err := form.Validate(); err != nil {
+		return err
+	}
+
+	form.admin.Permissions = models.AdminPermissions{
+		Restricted:  form.Restricted,
+		Collections: form.Collections,
+	}
+
+	return form.app.Dao().SaveAdmin(form.admin)
+}
@@ -0,0 +1,141 @@
+package forms_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestNewAdminPermissionsUpsert(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	admin := &models.Admin{}
+	admin.Permissions.Restricted = true
+	admin.Permissions.Collections = map[string]string{"test": models.AdminAccessRead}
+
+	form := forms.NewAdminPermissionsUpsert(app, admin)
+
+	// test defaults
+	if !form.Restricted {
+		t.Error("Expected Restricted to be true")
+	}
+	if len(form.Collections) != 1 || form.Collections["test"] != models.AdminAccessRead {
+		t.Errorf("Expected Collections %v, got %v", admin.Permissions.Collections, form.Collections)
+	}
+
+	// the form collections should be a copy
+	form.Collections["new"] = models.AdminAccessWrite
+	if len(admin.Permissions.Collections) != 1 {
+		t.Error("Expected the admin permissions to not be modified")
+	}
+}
+
+func TestAdminPermissionsUpsertValidateAndSubmit(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		restrictOther  bool
+		jsonData       string
+		expectedErrors []string
+	}{
+		{
+			"empty data",
+			false,
+			`{}`,
+			nil,
+		},
+		{
+			"invalid collection and access",
+			false,
+			`{"restricted":true,"collections":{"demo":"read"}}`,
+			[]string{"collections"},
+		},
+		{
+			"invalid access value",
+			false,
+			`{"restricted":true,"collections":{"3f2888f8-075d-49fe-9d09-ea7e951000dc":"admin"}}`,
+			[]string{"collections"},
+		},
+		{
+			"restricting the last super admin",
+			true,
+			`{"restricted":true,"collections":{"3f2888f8-075d-49fe-9d09-ea7e951000dc":"read"}}`,
+			[]string{"restricted"},
+		},
+		{
+			"valid grants",
+			false,
+			`{"restricted":true,"collections":{"3f2888f8-075d-49fe-9d09-ea7e951000dc":"read","2c1010aa-b8fe-41d9-a980-99534ca8a167":"write"}}`,
+			nil,
+		},
+	}
+
+	for _, s := range scenarios {
+		app, _ := tests.NewTestApp()
+
+		admin, err := app.Dao().FindAdminByEmail("test@example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if s.restrictOther {
+			other, err := app.Dao().FindAdminByEmail("test2@example.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			other.Permissions.Restricted = true
+			if err := app.Dao().SaveAdmin(other); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		form := forms.NewAdminPermissionsUpsert(app, admin)
+
+		// load data
+		loadErr := json.Unmarshal([]byte(s.jsonData), form)
+		if loadErr != nil {
+			t.Errorf("[%s] Failed to load form data: %v", s.name, loadErr)
+			app.Cleanup()
+			continue
+		}
+
+		submitErr := form.Submit()
+
+		// parse errors
+		errs, ok := submitErr.(validation.Errors)
+		if !ok && submitErr != nil {
+			t.Errorf("[%s] Failed to parse errors %v", s.name, submitErr)
+			app.Cleanup()
+			continue
+		}
+
+		// check errors
+		if len(errs) > len(s.expectedErrors) {
+			t.Errorf("[%s] Expected error keys %v, got %v", s.name, s.expectedErrors, errs)
+		}
+		for _, k := range s.expectedErrors {
+			if _, ok := errs[k]; !ok {
+				t.Errorf("[%s] Missing expected error key %q in %v", s.name, k, errs)
+			}
+		}
+
+		if len(s.expectedErrors) == 0 {
+			updated, _ := app.Dao().FindAdminById(admin.Id)
+
+			encoded, _ := json.Marshal(updated.Permissions)
+			expected, _ := json.Marshal(models.AdminPermissions{
+				Restricted:  form.Restricted,
+				Collections: form.Collections,
+			})
+			if string(encoded) != string(expected) {
+				t.Errorf("[%s] Expected permissions %s, got %s", s.name, expected, encoded)
+			}
+		}
+
+		app.Cleanup()
+	}
+}
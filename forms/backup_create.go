@@ -0,0 +1,71 @@
+package forms
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+var backupNameRegex = regexp.MustCompile(`^[a-zA-Z0-9][\w\-\.]*\.zip$`)
+
+// BackupCreate defines a new app backup create form.
+type BackupCreate struct {
+	app core.App
+
+	// Name is the optional backup archive name
+	// (if not set, a name based on the current date is generated).
+	Name string `form:"name" json:"name"`
+}
+
+// NewBackupCreate creates new backup create form for the provided app.
+func NewBackupCreate(app core.App) *BackupCreate {
+	return &BackupCreate{app: app}
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *BackupCreate) Validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(
+			&form.Name,
+			validation.Length(1, 100),
+			validation.Match(backupNameRegex),
+			validation.By(form.checkUniqueName),
+		),
+	)
+}
+
+func (form *BackupCreate) checkUniqueName(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil // nothing to check
+	}
+
+	if _, err := os.Stat(filepath.Join(core.BackupsDir(form.app), v)); err == nil {
+		return validation.NewError("validation_backup_name_exists", "Backup with the specified name already exists.")
+	}
+
+	return nil
+}
+
+// Submit validates the form and creates a new app backup.
+//
+// On success returns the name of the created backup.
+func (form *BackupCreate) Submit() (string, error) {
+	if form.Name == "" {
+		form.Name = "pb_backup_" + time.Now().UTC().Format("20060102150405") + ".zip"
+	}
+
+	if err := form.Validate(); err != nil {
+		return "", err
+	}
+
+	if err := form.app.CreateBackup(form.Name); err != nil {
+		return "", err
+	}
+
+	return form.Name, nil
+}
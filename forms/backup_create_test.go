@@ -0,0 +1,86 @@
+package forms_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestBackupCreateValidate(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	os.MkdirAll(core.BackupsDir(app), os.ModePerm)
+	os.WriteFile(filepath.Join(core.BackupsDir(app), "existing.zip"), []byte("test"), 0644)
+
+	scenarios := []struct {
+		name        string
+		expectError bool
+	}{
+		{"", false},
+		{"test", true},
+		{"test.txt", true},
+		{"../test.zip", true},
+		{"_test.zip", true},
+		{strings.Repeat("a", 97) + ".zip", true},
+		{"existing.zip", true},
+		{"test-1_2.3.zip", false},
+	}
+
+	for i, s := range scenarios {
+		form := forms.NewBackupCreate(app)
+		form.Name = s.name
+
+		err := form.Validate()
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr to be %v, got %v (%v)", i, s.expectError, hasErr, err)
+		}
+	}
+}
+
+func TestBackupCreateSubmit(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name        string
+		expectError bool
+	}{
+		{"invalid", true},
+		{"", false},
+		{"test.zip", false},
+		{"test.zip", true}, // already exists
+	}
+
+	for i, s := range scenarios {
+		form := forms.NewBackupCreate(app)
+		form.Name = s.name
+
+		name, err := form.Submit()
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr to be %v, got %v (%v)", i, s.expectError, hasErr, err)
+			continue
+		}
+
+		if s.expectError {
+			continue
+		}
+
+		if s.name != "" && name != s.name {
+			t.Errorf("(%d) Expected name %q, got %q", i, s.name, name)
+		}
+
+		if _, err := os.Stat(filepath.Join(core.BackupsDir(app), name)); err != nil {
+			t.Errorf("(%d) Expected backup %q to be created, got %v", i, name, err)
+		}
+	}
+}
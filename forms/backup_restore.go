@@ -0,0 +1,45 @@
+package forms
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// BackupRestore defines an app backup restore form.
+type BackupRestore struct {
+	app  core.App
+	name string
+
+	// Confirm must match the name of the backup to restore
+	// (used as an explicit confirmation of the destructive action).
+	Confirm string `form:"confirm" json:"confirm"`
+}
+
+// NewBackupRestore creates new backup restore form for the
+// provided app and backup name.
+func NewBackupRestore(app core.App, name string) *BackupRestore {
+	return &BackupRestore{
+		app:  app,
+		name: name,
+	}
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *BackupRestore) Validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(
+			&form.Confirm,
+			validation.Required,
+			validation.In(form.name).Error("Must match the name of the backup to restore."),
+		),
+	)
+}
+
+// Submit validates the form and restores the app data from the form backup.
+func (form *BackupRestore) Submit() error {
+	if err := form.Validate(); err != nil {
+		return err
+	}
+
+	return form.app.RestoreBackup(form.name)
+}
@@ -0,0 +1,65 @@
+package forms_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestBackupRestoreValidate(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		confirm     string
+		expectError bool
+	}{
+		{"", true},
+		{"other.zip", true},
+		{"test.zip", false},
+	}
+
+	for i, s := range scenarios {
+		form := forms.NewBackupRestore(app, "test.zip")
+		form.Confirm = s.confirm
+
+		err := form.Validate()
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr to be %v, got %v (%v)", i, s.expectError, hasErr, err)
+		}
+	}
+}
+
+func TestBackupRestoreSubmit(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if err := app.CreateBackup("test.zip"); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name        string
+		confirm     string
+		expectError bool
+	}{
+		{"test.zip", "", true},
+		{"missing.zip", "missing.zip", true},
+		{"test.zip", "test.zip", false},
+	}
+
+	for i, s := range scenarios {
+		form := forms.NewBackupRestore(app, s.name)
+		form.Confirm = s.confirm
+
+		err := form.Submit()
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr to be %v, got %v (%v)", i, s.expectError, hasErr, err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package forms
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// BackupUpload defines a backup archive upload form.
+type BackupUpload struct {
+	app core.App
+
+	File *multipart.FileHeader `form:"file" json:"file"`
+}
+
+// NewBackupUpload creates new backup upload form for the provided app.
+func NewBackupUpload(app core.App) *BackupUpload {
+	return &BackupUpload{app: app}
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *BackupUpload) Validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.File, validation.Required, validation.By(form.checkFile)),
+	)
+}
+
+func (form *BackupUpload) checkFile(value any) error {
+	v, _ := value.(*multipart.FileHeader)
+	if v == nil {
+		return nil // nothing to check
+	}
+
+	if !backupNameRegex.MatchString(v.Filename) || len(v.Filename) > 100 {
+		return validation.NewError("validation_invalid_backup_name", "The file must be a zip archive with a valid name.")
+	}
+
+	if _, err := os.Stat(filepath.Join(core.BackupsDir(form.app), v.Filename)); err == nil {
+		return validation.NewError("validation_backup_name_exists", "Backup with the specified name already exists.")
+	}
+
+	return nil
+}
+
+// Submit validates the form and stores the uploaded archive
+// in the app backups directory.
+//
+// On success returns the name of the stored backup.
+func (form *BackupUpload) Submit() (string, error) {
+	if err := form.Validate(); err != nil {
+		return "", err
+	}
+
+	src, err := form.File.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	if err := os.MkdirAll(core.BackupsDir(form.app), os.ModePerm); err != nil {
+		return "", err
+	}
+
+	destPath := filepath.Join(core.BackupsDir(form.app), form.File.Filename)
+
+	dest, err := os.OpenFile(destPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return "", errors.New("Failed to store the backup file.")
+	}
+	defer dest.Close()
+
+	if _, err := io.Copy(dest, src); err != nil {
+		os.Remove(destPath)
+		return "", err
+	}
+
+	return form.File.Filename, nil
+}
@@ -0,0 +1,75 @@
+package forms_test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestBackupUploadValidateAndSubmit(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		filename    string
+		expectError bool
+	}{
+		{"", true},
+		{"test.txt", true},
+		{"test.zip", false},
+		{"test.zip", true}, // already exists
+	}
+
+	for i, s := range scenarios {
+		form := forms.NewBackupUpload(app)
+
+		if s.filename != "" {
+			form.File = mockFileHeader(t, s.filename, "test")
+		}
+
+		name, err := form.Submit()
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr to be %v, got %v (%v)", i, s.expectError, hasErr, err)
+			continue
+		}
+
+		if s.expectError {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(core.BackupsDir(app), name))
+		if err != nil || string(content) != "test" {
+			t.Errorf("(%d) Expected the uploaded backup to be stored, got %q (%v)", i, content, err)
+		}
+	}
+}
+
+func mockFileHeader(t *testing.T, filename string, content string) *multipart.FileHeader {
+	body := new(bytes.Buffer)
+	mp := multipart.NewWriter(body)
+	w, err := mp.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(content))
+	mp.Close()
+
+	req := httptest.NewRequest("POST", "/", body)
+	req.Header.Set("Content-Type", mp.FormDataContentType())
+
+	_, fh, err := req.FormFile("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return fh
+}
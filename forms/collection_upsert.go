@@ -1,7 +1,9 @@
 package forms
 
 import (
+	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
@@ -9,6 +11,7 @@ import (
 	"github.com/pocketbase/pocketbase/models"
 	"github.com/pocketbase/pocketbase/models/schema"
 	"github.com/pocketbase/pocketbase/resolvers"
+	"github.com/pocketbase/pocketbase/tools/list"
 	"github.com/pocketbase/pocketbase/tools/search"
 )
 
@@ -20,14 +23,15 @@ type CollectionUpsert struct {
 	collection *models.Collection
 	isCreate   bool
 
-	Name       string        `form:"name" json:"name"`
-	System     bool          `form:"system" json:"system"`
-	Schema     schema.Schema `form:"schema" json:"schema"`
-	ListRule   *string       `form:"listRule" json:"listRule"`
-	ViewRule   *string       `form:"viewRule" json:"viewRule"`
-	CreateRule *string       `form:"createRule" json:"createRule"`
-	UpdateRule *string       `form:"updateRule" json:"updateRule"`
-	DeleteRule *string       `form:"deleteRule" json:"deleteRule"`
+	Name       string                   `form:"name" json:"name"`
+	System     bool                     `form:"system" json:"system"`
+	Schema     schema.Schema            `form:"schema" json:"schema"`
+	ListRule   *string                  `form:"listRule" json:"listRule"`
+	ViewRule   *string                  `form:"viewRule" json:"viewRule"`
+	CreateRule *string                  `form:"createRule" json:"createRule"`
+	UpdateRule *string                  `form:"updateRule" json:"updateRule"`
+	DeleteRule *string                  `form:"deleteRule" json:"deleteRule"`
+	Options    models.CollectionOptions `form:"options" json:"options"`
 }
 
 // NewCollectionUpsert creates new collection upsert form for the provided Collection model
@@ -48,6 +52,39 @@ func NewCollectionUpsert(app core.App, collection *models.Collection) *Collectio
 	form.UpdateRule = collection.UpdateRule
 	form.DeleteRule = collection.DeleteRule
 
+	// copy the options headers to prevent modifying the original collection
+	form.Options.Headers = make(map[string]string, len(collection.Options.Headers))
+	for k, v := range collection.Options.Headers {
+		form.Options.Headers[k] = v
+	}
+	form.Options.Cache = collection.Options.Cache
+	form.Options.Id = collection.Options.Id
+	form.Options.Counts = append([]models.RelationCountOptions{}, collection.Options.Counts...)
+	form.Options.Logs.Disabled = collection.Options.Logs.Disabled
+	form.Options.Logs.SkipActions = append([]string{}, collection.Options.Logs.SkipActions...)
+	form.Options.Indexes = make([]models.CollectionIndex, len(collection.Options.Indexes))
+	for i, idx := range collection.Options.Indexes {
+		idx.Fields = append([]string{}, idx.Fields...)
+		form.Options.Indexes[i] = idx
+	}
+	form.Options.Aliases = make(map[string]string, len(collection.Options.Aliases))
+	for k, v := range collection.Options.Aliases {
+		form.Options.Aliases[k] = v
+	}
+	form.Options.AutoExpand = append([]string{}, collection.Options.AutoExpand...)
+	form.Options.Archive = collection.Options.Archive
+	form.Options.EstimatedCountThreshold = collection.Options.EstimatedCountThreshold
+	form.Options.Publish = collection.Options.Publish
+	form.Options.ListExcludedFields = append([]string{}, collection.Options.ListExcludedFields...)
+	form.Options.CoalesceReads = collection.Options.CoalesceReads
+	form.Options.Templates = make(map[string]models.RecordTemplate, len(collection.Options.Templates))
+	for k, v := range collection.Options.Templates {
+		form.Options.Templates[k] = v
+	}
+	form.Options.Mirrors = append([]models.RecordMirrorOptions{}, collection.Options.Mirrors...)
+	form.Options.RelationIndexes = collection.Options.RelationIndexes
+	form.Options.Versions = collection.Options.Versions
+
 	clone, _ := collection.Schema.Clone()
 	if clone != nil {
 		form.Schema = *clone
@@ -79,12 +116,25 @@ func (form *CollectionUpsert) Validate() error {
 			validation.By(form.ensureNoSystemFieldsChange),
 			validation.By(form.ensureNoFieldsTypeChange),
 			validation.By(form.ensureNoFieldsNameReuse),
+			validation.By(form.checkSlugFieldsSource),
+			validation.By(form.checkFileNameGenerators),
 		),
 		validation.Field(&form.ListRule, validation.By(form.checkRule)),
 		validation.Field(&form.ViewRule, validation.By(form.checkRule)),
 		validation.Field(&form.CreateRule, validation.By(form.checkRule)),
 		validation.Field(&form.UpdateRule, validation.By(form.checkRule)),
 		validation.Field(&form.DeleteRule, validation.By(form.checkRule)),
+		validation.Field(
+			&form.Options,
+			validation.By(form.checkRelationCounts),
+			validation.By(form.checkIndexesFields),
+			validation.By(form.checkAliasesFields),
+			validation.By(form.checkIdGenerator),
+			validation.By(form.checkAutoExpandFields),
+			validation.By(form.checkPublishFields),
+			validation.By(form.checkListExcludedFields),
+			validation.By(form.checkMirrorFields),
+		),
 	)
 }
 
@@ -131,11 +181,32 @@ func (form *CollectionUpsert) ensureNoFieldsTypeChange(value any) error {
 		if oldField != nil && oldField.Type != field.Type {
 			return validation.NewError("validation_field_type_change", "Field type cannot be changed.")
 		}
+
+		// eg. number field "nullable" option change
+		if oldField != nil && oldField.ColDefinition() != field.ColDefinition() {
+			return validation.NewError("validation_field_storage_change", "Field storage options cannot be changed.")
+		}
+
+		// the decimals are stored as integers scaled by the field scale
+		if oldField != nil && field.Type == schema.FieldTypeDecimal && decimalScale(oldField) != decimalScale(field) {
+			return validation.NewError("validation_field_storage_change", "Field storage options cannot be changed.")
+		}
 	}
 
 	return nil
 }
 
+// decimalScale returns the scale option of the provided decimal field.
+func decimalScale(field *schema.SchemaField) int {
+	field.InitOptions()
+
+	if options, _ := field.Options.(*schema.DecimalOptions); options != nil {
+		return options.Scale
+	}
+
+	return 0
+}
+
 func (form *CollectionUpsert) ensureNoSystemFieldsChange(value any) error {
 	v, _ := value.(schema.Schema)
 
@@ -168,6 +239,271 @@ func (form *CollectionUpsert) ensureNoFieldsNameReuse(value any) error {
 	return nil
 }
 
+func (form *CollectionUpsert) checkSlugFieldsSource(value any) error {
+	v, _ := value.(schema.Schema)
+
+	for _, field := range v.Fields() {
+		if field.Type != schema.FieldTypeSlug {
+			continue
+		}
+
+		field.InitOptions()
+		options, _ := field.Options.(*schema.SlugOptions)
+		if options == nil {
+			continue
+		}
+
+		source := v.GetFieldByName(options.Source)
+		if source == nil || source.Type == schema.FieldTypeSlug {
+			return validation.NewError(
+				"validation_invalid_slug_source",
+				fmt.Sprintf("The %q slug source must be an existing non-slug field.", field.Name),
+			)
+		}
+	}
+
+	return nil
+}
+
+func (form *CollectionUpsert) checkRelationCounts(value any) error {
+	v, _ := value.(models.CollectionOptions)
+
+	for _, options := range v.Counts {
+		var relCollection *models.Collection
+		if options.CollectionId == form.collection.Id && !form.isCreate {
+			// self-reference (use the submitted schema)
+			relCollection = &models.Collection{Schema: form.Schema}
+		} else {
+			relCollection, _ = form.app.Dao().FindCollectionByNameOrId(options.CollectionId)
+		}
+
+		if relCollection == nil {
+			return validation.Errors{"counts": validation.NewError(
+				"validation_invalid_count_collection",
+				fmt.Sprintf("Missing or invalid collection %q.", options.CollectionId),
+			)}
+		}
+
+		field := relCollection.Schema.GetFieldByName(options.Field)
+		if field != nil && field.Type == schema.FieldTypeRelation {
+			field.InitOptions()
+			fieldOptions, _ := field.Options.(*schema.RelationOptions)
+			if fieldOptions != nil && !form.isCreate && fieldOptions.CollectionId == form.collection.Id {
+				continue // valid back-relation
+			}
+		}
+
+		return validation.Errors{"counts": validation.NewError(
+			"validation_invalid_count_field",
+			fmt.Sprintf("%q is not a relation field referencing the current collection.", options.Field),
+		)}
+	}
+
+	return nil
+}
+
+func (form *CollectionUpsert) checkIndexesFields(value any) error {
+	v, _ := value.(models.CollectionOptions)
+
+	for _, idx := range v.Indexes {
+		for _, name := range idx.Fields {
+			if name == schema.ReservedFieldNameId ||
+				name == schema.ReservedFieldNameCreated ||
+				name == schema.ReservedFieldNameUpdated ||
+				form.Schema.GetFieldByName(name) != nil {
+				continue
+			}
+
+			return validation.Errors{"indexes": validation.NewError(
+				"validation_invalid_index_field",
+				fmt.Sprintf("Index %q references missing field %q.", idx.Name, name),
+			)}
+		}
+	}
+
+	return nil
+}
+
+func (form *CollectionUpsert) checkFileNameGenerators(value any) error {
+	v, _ := value.(schema.Schema)
+
+	for _, field := range v.Fields() {
+		if field.Type != schema.FieldTypeFile {
+			continue
+		}
+
+		field.InitOptions()
+		options, _ := field.Options.(*schema.FileOptions)
+		if options == nil {
+			continue
+		}
+
+		if options.NameStrategy == schema.FileNameStrategyCustom && options.NameGenerator != "" && !HasFileNameGenerator(options.NameGenerator) {
+			return validation.NewError(
+				"validation_missing_file_name_generator",
+				fmt.Sprintf("Missing %q file name generator %q.", field.Name, options.NameGenerator),
+			)
+		}
+	}
+
+	return nil
+}
+
+func (form *CollectionUpsert) checkIdGenerator(value any) error {
+	v, _ := value.(models.CollectionOptions)
+
+	if v.Id.Type == models.RecordIdTypeCustom && v.Id.Generator != "" && !HasRecordIdGenerator(v.Id.Generator) {
+		return validation.Errors{"id": validation.Errors{"generator": validation.NewError(
+			"validation_missing_id_generator",
+			fmt.Sprintf("Missing record id generator %q.", v.Id.Generator),
+		)}}
+	}
+
+	return nil
+}
+
+// checkAutoExpandFields checks only the first segment of each
+// auto expand path because the nested relations could change later.
+func (form *CollectionUpsert) checkAutoExpandFields(value any) error {
+	v, _ := value.(models.CollectionOptions)
+
+	for _, path := range v.AutoExpand {
+		name := strings.SplitN(path, ".", 2)[0]
+
+		field := form.Schema.GetFieldByName(name)
+		if field == nil || field.Type != schema.FieldTypeRelation {
+			return validation.Errors{"autoExpand": validation.NewError(
+				"validation_invalid_auto_expand_field",
+				fmt.Sprintf("Auto expand path %q must start with a relation field.", path),
+			)}
+		}
+	}
+
+	return nil
+}
+
+func (form *CollectionUpsert) checkPublishFields(value any) error {
+	v, _ := value.(models.CollectionOptions)
+
+	if !v.Publish.Enabled {
+		return nil
+	}
+
+	status := form.Schema.GetFieldByName(v.Publish.StatusField)
+	if status != nil && status.Type == schema.FieldTypeSelect {
+		status.InitOptions()
+		options, _ := status.Options.(*schema.SelectOptions)
+		if options == nil ||
+			options.MaxSelect > 1 ||
+			!list.ExistInSlice(models.RecordStatusDraft, options.Values) ||
+			!list.ExistInSlice(models.RecordStatusPublished, options.Values) {
+			status = nil
+		}
+	} else if status != nil && status.Type != schema.FieldTypeText {
+		status = nil
+	}
+	if status == nil {
+		return validation.Errors{"publish": validation.Errors{"statusField": validation.NewError(
+			"validation_invalid_publish_status_field",
+			fmt.Sprintf("%q must be a text or single select field with %q and %q values.", v.Publish.StatusField, models.RecordStatusDraft, models.RecordStatusPublished),
+		)}}
+	}
+
+	if v.Publish.PublishedAtField != "" {
+		publishedAt := form.Schema.GetFieldByName(v.Publish.PublishedAtField)
+		if publishedAt == nil || publishedAt.Type != schema.FieldTypeDate {
+			return validation.Errors{"publish": validation.Errors{"publishedAtField": validation.NewError(
+				"validation_invalid_publish_date_field",
+				fmt.Sprintf("%q must be a date field.", v.Publish.PublishedAtField),
+			)}}
+		}
+	}
+
+	return nil
+}
+
+func (form *CollectionUpsert) checkMirrorFields(value any) error {
+	v, _ := value.(models.CollectionOptions)
+
+	for i, options := range v.Mirrors {
+		index := strconv.Itoa(i)
+
+		field := form.Schema.GetFieldByName(options.Field)
+		if field == nil || field.Type == schema.FieldTypeRelation || field.Type == schema.FieldTypeFile {
+			return validation.Errors{"mirrors": validation.Errors{index: validation.Errors{"field": validation.NewError(
+				"validation_invalid_mirror_field",
+				fmt.Sprintf("%q must be an existing non relation and non file field.", options.Field),
+			)}}}
+		}
+
+		var relOptions *schema.RelationOptions
+		if relField := form.Schema.GetFieldByName(options.RelationField); relField != nil && relField.Type == schema.FieldTypeRelation {
+			relField.InitOptions()
+			relOptions, _ = relField.Options.(*schema.RelationOptions)
+		}
+		if relOptions == nil || relOptions.MaxSelect != 1 {
+			return validation.Errors{"mirrors": validation.Errors{index: validation.Errors{"relationField": validation.NewError(
+				"validation_invalid_mirror_relation_field",
+				fmt.Sprintf("%q must be a single relation field.", options.RelationField),
+			)}}}
+		}
+
+		var sourceCollection *models.Collection
+		if relOptions.CollectionId == form.collection.Id && !form.isCreate {
+			// self-reference (use the submitted schema)
+			sourceCollection = &models.Collection{Schema: form.Schema}
+		} else {
+			sourceCollection, _ = form.app.Dao().FindCollectionByNameOrId(relOptions.CollectionId)
+		}
+
+		if sourceCollection == nil || sourceCollection.Schema.GetFieldByName(options.SourceField) == nil {
+			return validation.Errors{"mirrors": validation.Errors{index: validation.Errors{"sourceField": validation.NewError(
+				"validation_invalid_mirror_source_field",
+				fmt.Sprintf("%q must be an existing field of the related collection.", options.SourceField),
+			)}}}
+		}
+	}
+
+	return nil
+}
+
+func (form *CollectionUpsert) checkListExcludedFields(value any) error {
+	v, _ := value.(models.CollectionOptions)
+
+	for _, name := range v.ListExcludedFields {
+		if form.Schema.GetFieldByName(name) == nil {
+			return validation.Errors{"listExcludedFields": validation.NewError(
+				"validation_invalid_list_excluded_field",
+				fmt.Sprintf("Missing list excluded field %q.", name),
+			)}
+		}
+	}
+
+	return nil
+}
+
+func (form *CollectionUpsert) checkAliasesFields(value any) error {
+	v, _ := value.(models.CollectionOptions)
+
+	for name, alias := range v.Aliases {
+		if form.Schema.GetFieldByName(name) == nil {
+			return validation.Errors{"aliases": validation.NewError(
+				"validation_invalid_alias_field",
+				fmt.Sprintf("Alias %q references missing field %q.", alias, name),
+			)}
+		}
+
+		if form.Schema.GetFieldByName(alias) != nil {
+			return validation.Errors{"aliases": validation.NewError(
+				"validation_alias_field_conflict",
+				fmt.Sprintf("Alias %q conflicts with an existing field name.", alias),
+			)}
+		}
+	}
+
+	return nil
+}
+
 func (form *CollectionUpsert) checkRule(value any) error {
 	v, _ := value.(*string)
 
@@ -210,6 +546,7 @@ func (form *CollectionUpsert) Submit() error {
 	form.collection.CreateRule = form.CreateRule
 	form.collection.UpdateRule = form.UpdateRule
 	form.collection.DeleteRule = form.DeleteRule
+	form.collection.Options = form.Options
 
 	return form.app.Dao().SaveCollection(form.collection)
 }
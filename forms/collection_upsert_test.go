@@ -33,6 +33,7 @@ func TestNewCollectionUpsert(t *testing.T) {
 		Name: "test",
 		Type: schema.FieldTypeText,
 	})
+	collection.Options.Headers = map[string]string{"Cache-Control": "no-cache"}
 
 	form := forms.NewCollectionUpsert(app, collection)
 
@@ -64,6 +65,16 @@ func TestNewCollectionUpsert(t *testing.T) {
 		t.Errorf("Expected DeleteRule %v, got %v", collection.DeleteRule, form.DeleteRule)
 	}
 
+	if v := form.Options.Headers["Cache-Control"]; v != "no-cache" {
+		t.Errorf("Expected Options.Headers to be loaded, got %v", form.Options.Headers)
+	}
+
+	// modify the form headers to verify that they don't affect the collection
+	form.Options.Headers["X-Test"] = "123"
+	if _, ok := collection.Options.Headers["X-Test"]; ok {
+		t.Errorf("Expected the collection Options.Headers to not be modified, got %v", collection.Options.Headers)
+	}
+
 	// store previous state and modify the collection schema to verify
 	// that the form.Schema is a deep clone
 	loadedSchema, _ := collection.Schema.MarshalJSON()
@@ -99,9 +110,10 @@ func TestCollectionUpsertValidate(t *testing.T) {
 				"viewRule": "missing = '123'",
 				"createRule": "missing = '123'",
 				"updateRule": "missing = '123'",
-				"deleteRule": "missing = '123'"
+				"deleteRule": "missing = '123'",
+				"options": {"headers": {"Invalid Name": "123"}}
 			}`,
-			[]string{"name", "schema", "listRule", "viewRule", "createRule", "updateRule", "deleteRule"},
+			[]string{"name", "schema", "listRule", "viewRule", "createRule", "updateRule", "deleteRule", "options"},
 		},
 		{
 			`{
@@ -114,7 +126,124 @@ func TestCollectionUpsertValidate(t *testing.T) {
 				"viewRule": "test='123'",
 				"createRule": "test='123'",
 				"updateRule": "test='123'",
-				"deleteRule": "test='123'"
+				"deleteRule": "test='123'",
+				"options": {"headers": {"Cache-Control": "no-cache"}}
+			}`,
+			[]string{},
+		},
+		{
+			`{
+				"name": "test",
+				"schema": [
+					{"name":"test","type":"text"}
+				],
+				"options": {"indexes": [{"name":"idx1","fields":["test","missing"]}]}
+			}`,
+			[]string{"options"},
+		},
+		{
+			`{
+				"name": "test",
+				"schema": [
+					{"name":"test","type":"text"}
+				],
+				"options": {"indexes": [{"name":"idx1","fields":["test","created"],"unique":true,"where":"test != ''"}]}
+			}`,
+			[]string{},
+		},
+		{
+			`{
+				"name": "test",
+				"schema": [
+					{"name":"test","type":"text"}
+				],
+				"options": {"aliases": {"missing": "alias"}}
+			}`,
+			[]string{"options"},
+		},
+		{
+			`{
+				"name": "test",
+				"schema": [
+					{"name":"test","type":"text"},
+					{"name":"test2","type":"text"}
+				],
+				"options": {"aliases": {"test": "test2"}}
+			}`,
+			[]string{"options"},
+		},
+		{
+			`{
+				"name": "test",
+				"schema": [
+					{"name":"test","type":"text"}
+				],
+				"options": {"aliases": {"test": "test_alias"}}
+			}`,
+			[]string{},
+		},
+		{
+			`{
+				"name": "test",
+				"schema": [
+					{"name":"test","type":"text"}
+				],
+				"options": {"id": {"type": "custom", "generator": "missing"}}
+			}`,
+			[]string{"options"},
+		},
+		{
+			`{
+				"name": "test",
+				"schema": [
+					{"name":"test","type":"text"}
+				],
+				"options": {"id": {"type": "sequence", "prefix": "INV-", "padding": 5}}
+			}`,
+			[]string{},
+		},
+		{
+			`{
+				"name": "test",
+				"schema": [
+					{"name":"title","type":"text"},
+					{"name":"rel","type":"relation","options":{"maxSelect":1,"collectionId":"f12f3eb6-b980-4bf6-b1e4-36de0450c8be"}},
+					{"name":"rels","type":"relation","options":{"maxSelect":2,"collectionId":"f12f3eb6-b980-4bf6-b1e4-36de0450c8be"}}
+				],
+				"options": {"mirrors": [{"field":"title","relationField":"rels","sourceField":"title"}]}
+			}`,
+			[]string{"options"},
+		},
+		{
+			`{
+				"name": "test",
+				"schema": [
+					{"name":"title","type":"text"},
+					{"name":"rel","type":"relation","options":{"maxSelect":1,"collectionId":"f12f3eb6-b980-4bf6-b1e4-36de0450c8be"}}
+				],
+				"options": {"mirrors": [{"field":"title","relationField":"rel","sourceField":"missing"}]}
+			}`,
+			[]string{"options"},
+		},
+		{
+			`{
+				"name": "test",
+				"schema": [
+					{"name":"title","type":"text"},
+					{"name":"rel","type":"relation","options":{"maxSelect":1,"collectionId":"f12f3eb6-b980-4bf6-b1e4-36de0450c8be"}}
+				],
+				"options": {"mirrors": [{"field":"rel","relationField":"rel","sourceField":"title"}]}
+			}`,
+			[]string{"options"},
+		},
+		{
+			`{
+				"name": "test",
+				"schema": [
+					{"name":"title","type":"text"},
+					{"name":"rel","type":"relation","options":{"maxSelect":1,"collectionId":"f12f3eb6-b980-4bf6-b1e4-36de0450c8be"}}
+				],
+				"options": {"mirrors": [{"field":"title","relationField":"rel","sourceField":"title"}]}
 			}`,
 			[]string{},
 		},
@@ -231,6 +360,30 @@ func TestCollectionUpsertSubmit(t *testing.T) {
 			}`,
 			[]string{"schema"},
 		},
+		// create failure - missing slug source field
+		{
+			"",
+			`{
+				"name": "test_new",
+				"schema": [
+					{"name":"title","type":"text"},
+					{"name":"slug","type":"slug","options":{"source":"missing"}}
+				]
+			}`,
+			[]string{"schema"},
+		},
+		// create failure - slug source field referencing another slug
+		{
+			"",
+			`{
+				"name": "test_new",
+				"schema": [
+					{"name":"slug1","type":"slug","options":{"source":"slug2"}},
+					{"name":"slug2","type":"slug","options":{"source":"slug1"}}
+				]
+			}`,
+			[]string{"schema"},
+		},
 		// create success
 		{
 			"",
@@ -260,6 +413,30 @@ func TestCollectionUpsertSubmit(t *testing.T) {
 			}`,
 			[]string{"schema"},
 		},
+		// update success - new decimal field
+		{
+			"test_new",
+			`{
+				"schema": [
+					{"id":"a123456","name":"test1","type":"text"},
+					{"id":"b123456","name":"test2","type":"email"},
+					{"id":"c123456","name":"test3","type":"decimal","options":{"scale":2}}
+				]
+			}`,
+			[]string{},
+		},
+		// update failure - changing the decimal field scale
+		{
+			"test_new",
+			`{
+				"schema": [
+					{"id":"a123456","name":"test1","type":"text"},
+					{"id":"b123456","name":"test2","type":"email"},
+					{"id":"c123456","name":"test3","type":"decimal","options":{"scale":3}}
+				]
+			}`,
+			[]string{"schema"},
+		},
 		// update failure - rename fields to existing field names (aka. reusing field names)
 		{
 			"test_new",
@@ -323,7 +500,8 @@ func TestCollectionUpsertSubmit(t *testing.T) {
 				"viewRule": "test='123'",
 				"createRule": "test='123'",
 				"updateRule": "test='123'",
-				"deleteRule": "test='123'"
+				"deleteRule": "test='123'",
+				"options": {"headers": {"Cache-Control": "no-cache"}}
 			}`,
 			[]string{},
 		},
@@ -366,6 +544,112 @@ func TestCollectionUpsertSubmit(t *testing.T) {
 			}`,
 			[]string{},
 		},
+		// update failure - relation count with missing collection
+		{
+			"demo_update",
+			`{"options":{"counts":[{"name":"test","collectionId":"missing","field":"onerel"}]}}`,
+			[]string{"options"},
+		},
+		// update failure - relation count with non relation field
+		{
+			"demo_update",
+			`{"options":{"counts":[{"name":"test","collectionId":"demo2","field":"title"}]}}`,
+			[]string{"options"},
+		},
+		// update failure - relation count with relation field to another collection
+		{
+			"demo_update",
+			`{"options":{"counts":[{"name":"test","collectionId":"demo4","field":"onerel"}]}}`,
+			[]string{"options"},
+		},
+		// update success - relation count
+		{
+			"demo_update",
+			`{"options":{"counts":[{"name":"test","collectionId":"demo2","field":"manyrels"}]}}`,
+			[]string{},
+		},
+		// update success - self relation count
+		{
+			"demo4",
+			`{"options":{"counts":[{"name":"test","collectionId":"f12f3eb6-b980-4bf6-b1e4-36de0450c8be","field":"onerel"}]}}`,
+			[]string{},
+		},
+		// update failure - auto expand with invalid path
+		{
+			"demo4",
+			`{"options":{"autoExpand":["onerel..manyrels"]}}`,
+			[]string{"options"},
+		},
+		// update failure - auto expand with non relation field
+		{
+			"demo4",
+			`{"options":{"autoExpand":["title"]}}`,
+			[]string{"options"},
+		},
+		// update success - auto expand
+		{
+			"demo4",
+			`{"options":{"autoExpand":["onerel","manyrels.onerel"]}}`,
+			[]string{},
+		},
+		// update failure - list excluded missing field
+		{
+			"demo4",
+			`{"options":{"listExcludedFields":["title","missing"]}}`,
+			[]string{"options"},
+		},
+		// update success - list excluded fields
+		{
+			"demo4",
+			`{"options":{"listExcludedFields":["title","onerel"]}}`,
+			[]string{},
+		},
+		// create failure - publish with missing status field
+		{
+			"",
+			`{
+				"name": "test_publish",
+				"schema": [{"name":"title","type":"text"}],
+				"options": {"publish":{"enabled":true,"statusField":"missing"}}
+			}`,
+			[]string{"options"},
+		},
+		// create failure - publish with select status field without the status values
+		{
+			"",
+			`{
+				"name": "test_publish",
+				"schema": [{"name":"status","type":"select","options":{"maxSelect":1,"values":["draft","other"]}}],
+				"options": {"publish":{"enabled":true,"statusField":"status"}}
+			}`,
+			[]string{"options"},
+		},
+		// create failure - publish with non date publishedAt field
+		{
+			"",
+			`{
+				"name": "test_publish",
+				"schema": [
+					{"name":"status","type":"text"},
+					{"name":"publishedAt","type":"text"}
+				],
+				"options": {"publish":{"enabled":true,"statusField":"status","publishedAtField":"publishedAt"}}
+			}`,
+			[]string{"options"},
+		},
+		// create success - publish
+		{
+			"",
+			`{
+				"name": "test_publish",
+				"schema": [
+					{"name":"status","type":"select","options":{"maxSelect":1,"values":["draft","published"]}},
+					{"name":"publishedAt","type":"date"}
+				],
+				"options": {"publish":{"enabled":true,"statusField":"status","publishedAtField":"publishedAt"}}
+			}`,
+			[]string{},
+		},
 	}
 
 	for i, s := range scenarios {
@@ -443,6 +727,12 @@ func TestCollectionUpsertSubmit(t *testing.T) {
 			t.Errorf("(%d) Expected DeleteRule %v, got %v", i, collection.DeleteRule, form.DeleteRule)
 		}
 
+		formOptions, _ := form.Options.MarshalJSON()
+		collectionOptions, _ := collection.Options.MarshalJSON()
+		if string(formOptions) != string(collectionOptions) {
+			t.Errorf("(%d) Expected Options %v, got %v", i, string(collectionOptions), string(formOptions))
+		}
+
 		formSchema, _ := form.Schema.MarshalJSON()
 		collectionSchema, _ := collection.Schema.MarshalJSON()
 		if string(formSchema) != string(collectionSchema) {
@@ -450,3 +740,53 @@ func TestCollectionUpsertSubmit(t *testing.T) {
 		}
 	}
 }
+
+func TestCollectionUpsertReplaceOptionsHeaders(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, err := app.Dao().FindCollectionByNameOrId("demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	collection.Options.Headers = map[string]string{"Cache-Control": "no-cache", "X-Old": "1"}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		data     string
+		expected map[string]string
+	}{
+		// options without headers key - keep the existing ones
+		{`{"options":{"autoExpand":[]}}`, map[string]string{"Cache-Control": "no-cache", "X-Old": "1"}},
+		// replace
+		{`{"options":{"headers":{"X-New":"2"}}}`, map[string]string{"X-New": "2"}},
+		// remove all
+		{`{"options":{"headers":{}}}`, map[string]string{}},
+	}
+
+	for i, s := range scenarios {
+		collection, _ := app.Dao().FindCollectionByNameOrId("demo")
+
+		form := forms.NewCollectionUpsert(app, collection)
+		if err := json.Unmarshal([]byte(s.data), form); err != nil {
+			t.Fatalf("(%d) Failed to load form data: %v", i, err)
+		}
+
+		if err := form.Submit(); err != nil {
+			t.Fatalf("(%d) Failed to submit the form: %v", i, err)
+		}
+
+		collection, _ = app.Dao().FindCollectionByNameOrId("demo")
+
+		if len(collection.Options.Headers) != len(s.expected) {
+			t.Fatalf("(%d) Expected headers %v, got %v", i, s.expected, collection.Options.Headers)
+		}
+		for k, v := range s.expected {
+			if collection.Options.Headers[k] != v {
+				t.Fatalf("(%d) Expected headers %v, got %v", i, s.expected, collection.Options.Headers)
+			}
+		}
+	}
+}
@@ -4,10 +4,25 @@ import (
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 )
 
+// Realtime record update delta modes.
+const (
+	// RealtimeDeltaNone sends only the full updated record (default).
+	RealtimeDeltaNone = ""
+
+	// RealtimeDeltaInclude sends the changed fields delta alongside the full updated record.
+	RealtimeDeltaInclude = "include"
+
+	// RealtimeDeltaOnly sends only the changed fields delta instead of the full updated record.
+	RealtimeDeltaOnly = "only"
+)
+
 // RealtimeSubscribe defines a RealtimeSubscribe request form.
 type RealtimeSubscribe struct {
 	ClientId      string   `form:"clientId" json:"clientId"`
 	Subscriptions []string `form:"subscriptions" json:"subscriptions"`
+
+	// Delta is the record update messages delta mode (see RealtimeDelta* constants).
+	Delta string `form:"delta" json:"delta"`
 }
 
 // NewRealtimeSubscribe creates new RealtimeSubscribe request form.
@@ -19,5 +34,6 @@ func NewRealtimeSubscribe() *RealtimeSubscribe {
 func (form *RealtimeSubscribe) Validate() error {
 	return validation.ValidateStruct(form,
 		validation.Field(&form.ClientId, validation.Required, validation.Length(1, 255)),
+		validation.Field(&form.Delta, validation.In(RealtimeDeltaInclude, RealtimeDeltaOnly)),
 	)
 }
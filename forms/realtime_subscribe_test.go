@@ -10,16 +10,21 @@ import (
 func TestRealtimeSubscribeValidate(t *testing.T) {
 	scenarios := []struct {
 		clientId    string
+		delta       string
 		expectError bool
 	}{
-		{"", true},
-		{strings.Repeat("a", 256), true},
-		{"test", false},
+		{"", "", true},
+		{strings.Repeat("a", 256), "", true},
+		{"test", "", false},
+		{"test", "invalid", true},
+		{"test", forms.RealtimeDeltaInclude, false},
+		{"test", forms.RealtimeDeltaOnly, false},
 	}
 
 	for i, s := range scenarios {
 		form := forms.NewRealtimeSubscribe()
 		form.ClientId = s.clientId
+		form.Delta = s.delta
 
 		err := form.Validate()
 
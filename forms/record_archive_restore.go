@@ -0,0 +1,65 @@
+package forms
+
+import (
+	"errors"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/forms/validators"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// RecordArchiveRestore defines an archived record restore form.
+type RecordArchiveRestore struct {
+	app      core.App
+	archived *models.ArchivedRecord
+	record   *models.Record
+}
+
+// NewRecordArchiveRestore creates a new restore form for the provided
+// archived record (with the provided collection as the record collection).
+func NewRecordArchiveRestore(app core.App, collection *models.Collection, archived *models.ArchivedRecord) *RecordArchiveRestore {
+	record := models.NewRecord(collection)
+	record.Load(archived.Data)
+	record.Id = archived.RecordId
+	record.MarkAsNew()
+
+	return &RecordArchiveRestore{
+		app:      app,
+		archived: archived,
+		record:   record,
+	}
+}
+
+// Record returns the record that will be restored.
+func (form *RecordArchiveRestore) Record() *models.Record {
+	return form.record
+}
+
+// Submit validates the archived record data against the current
+// collection schema and reinserts the record with its original id.
+//
+// On success the archived record snapshot is deleted.
+func (form *RecordArchiveRestore) Submit() error {
+	return form.app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+		if existing, _ := txDao.FindRecordById(form.record.Collection(), form.record.Id, nil); existing != nil {
+			return errors.New("A record with the archived record id already exists.")
+		}
+
+		data := map[string]any{}
+		for _, field := range form.record.Collection().Schema.Fields() {
+			data[field.Name] = form.record.GetDataValue(field.Name)
+		}
+
+		validator := validators.NewRecordDataValidator(txDao, form.record, nil)
+		if err := validator.Validate(data); err != nil {
+			return err
+		}
+
+		if err := txDao.SaveRecord(form.record); err != nil {
+			return err
+		}
+
+		return txDao.DeleteArchivedRecord(form.archived)
+	})
+}
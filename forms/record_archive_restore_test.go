@@ -0,0 +1,61 @@
+package forms_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestRecordArchiveRestoreSubmit(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, _ := app.Dao().FindCollectionByNameOrId("demo3")
+	collection.Options.Archive = true
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := app.Dao().FindRecordById(collection, "2c542824-9de1-42fe-8924-e57c86267760", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	originalCreated := record.Created
+
+	if err := app.Dao().DeleteRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	archived := &models.ArchivedRecord{}
+	if err := app.Dao().ArchivedRecordQuery().Limit(1).One(archived); err != nil {
+		t.Fatal(err)
+	}
+
+	form := forms.NewRecordArchiveRestore(app, collection, archived)
+	if err := form.Submit(); err != nil {
+		t.Fatalf("Expected the restore to succeed, got %v", err)
+	}
+
+	restored, err := app.Dao().FindRecordById(collection, record.Id, nil)
+	if err != nil {
+		t.Fatalf("Expected the record to be restored, got %v", err)
+	}
+	if restored.GetStringDataValue("title") != record.GetStringDataValue("title") {
+		t.Fatalf("Expected title %q, got %q", record.GetStringDataValue("title"), restored.GetStringDataValue("title"))
+	}
+	if restored.Created.String() != originalCreated.String() {
+		t.Fatalf("Expected created %q, got %q", originalCreated.String(), restored.Created.String())
+	}
+
+	if _, err := app.Dao().FindArchivedRecordById(collection.Id, archived.Id); err == nil {
+		t.Fatal("Expected the archived record to be deleted after the restore")
+	}
+
+	// restore with an already existing record id
+	conflicting := forms.NewRecordArchiveRestore(app, collection, archived)
+	if err := conflicting.Submit(); err == nil {
+		t.Fatal("Expected the restore of an existing record id to fail")
+	}
+}
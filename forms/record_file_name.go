@@ -0,0 +1,182 @@
+package forms
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/rest"
+	"github.com/pocketbase/pocketbase/tools/store"
+)
+
+// FileNameMaxLength is the max allowed length of a non-random uploaded file name.
+const FileNameMaxLength = 150
+
+// fileNameMaxDuplicates is the max number of suffixed name
+// variants to try before giving up due to name collisions.
+const fileNameMaxDuplicates = 1000
+
+// fileNameMaxExtLength is the max number of preserved file extension characters.
+const fileNameMaxExtLength = 20
+
+// fileNameUnsafeCharsRegex matches the file name characters
+// that are not url and path safe.
+var fileNameUnsafeCharsRegex = regexp.MustCompile(`[^\w\-]+`)
+
+// FileNameGeneratorFunc defines a custom uploaded file name generator function.
+//
+// The returned name is sanitized and suffixed in case of a collision
+// with another file of the same record. Returning an empty string
+// fallbacks to the default random name.
+//
+// Note that the id of a new record is known only if it was client-supplied.
+type FileNameGeneratorFunc func(record *models.Record, field *schema.SchemaField, file *rest.UploadedFile) (string, error)
+
+var fileNameGenerators = store.New(map[string]FileNameGeneratorFunc{})
+
+// RegisterFileNameGenerator registers a custom uploaded file name
+// generator with the specified name, so that it could be used by the
+// file fields with "custom" name strategy, eg:
+//
+//	forms.RegisterFileNameGenerator("prefixed", func(record *models.Record, field *schema.SchemaField, file *rest.UploadedFile) (string, error) {
+//		return field.Name + "_" + file.Header().Filename, nil
+//	})
+//
+// Registering a generator with an already existing name replaces it.
+func RegisterFileNameGenerator(name string, fn FileNameGeneratorFunc) {
+	fileNameGenerators.Set(name, fn)
+}
+
+// HasFileNameGenerator checks whether a custom uploaded file name
+// generator with the specified name is registered.
+func HasFileNameGenerator(name string) bool {
+	return fileNameGenerators.Has(name)
+}
+
+// nameUploadedFiles assigns the storage names of the provided
+// field uploaded files based on the field name strategy.
+//
+// The files of fields with the default random strategy are left unchanged.
+func (form *RecordUpsert) nameUploadedFiles(field *schema.SchemaField, files []*rest.UploadedFile) error {
+	options, _ := field.Options.(*schema.FileOptions)
+	if options == nil {
+		return nil
+	}
+
+	var generator FileNameGeneratorFunc
+
+	switch options.NameStrategy {
+	case schema.FileNameStrategyOriginal:
+		generator = func(record *models.Record, field *schema.SchemaField, file *rest.UploadedFile) (string, error) {
+			return file.Header().Filename, nil
+		}
+	case schema.FileNameStrategyCustom:
+		generator = fileNameGenerators.Get(options.NameGenerator)
+		if generator == nil {
+			return fmt.Errorf("Missing file name generator %q.", options.NameGenerator)
+		}
+	default:
+		return nil // keep the random names
+	}
+
+	for i, file := range files {
+		name, err := generator(form.record, field, file)
+		if err != nil {
+			return err
+		}
+
+		name = sanitizeFileName(name)
+		if name == "" {
+			continue // fallback to the random name
+		}
+
+		name, err = form.uniqueFileName(name, files[:i])
+		if err != nil {
+			return err
+		}
+
+		file.SetName(name)
+	}
+
+	return nil
+}
+
+// uniqueFileName suffixes the provided file name (eg. "test_1.txt")
+// if it collides with another file of the form record
+// or with one of the provided pending (not queued yet) files.
+//
+// The names of the files marked for deletion are also considered
+// taken because they are deleted only after the new files upload.
+func (form *RecordUpsert) uniqueFileName(name string, pending []*rest.UploadedFile) (string, error) {
+	taken := []string{}
+	for _, field := range form.record.Collection().Schema.Fields() {
+		if field.Type == schema.FieldTypeFile {
+			taken = append(taken, form.record.GetStringSliceDataValue(field.Name)...)
+		}
+	}
+	for _, file := range form.filesToUpload {
+		taken = append(taken, file.Name())
+	}
+	for _, file := range pending {
+		taken = append(taken, file.Name())
+	}
+
+	if !list.ExistInSlice(name, taken) {
+		return name, nil
+	}
+
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+
+	for i := 1; i <= fileNameMaxDuplicates; i++ {
+		suffix := fmt.Sprintf("_%d", i)
+
+		candidate := base + suffix + ext
+		if len(candidate) > FileNameMaxLength {
+			candidate = base[:len(base)-len(suffix)] + suffix + ext
+		}
+
+		if !list.ExistInSlice(candidate, taken) {
+			return candidate, nil
+		}
+	}
+
+	return "", fmt.Errorf("Failed to generate a unique file name for %q.", name)
+}
+
+// sanitizeFileName normalizes the provided file name so that it is
+// url and path safe (eg. "../My file (1).PNG" -> "My_file_1.PNG").
+//
+// Returns an empty string if nothing meaningful remains from the name.
+func sanitizeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+
+	base = strings.Trim(fileNameUnsafeCharsRegex.ReplaceAllString(base, "_"), "_-")
+	ext = fileNameUnsafeCharsRegex.ReplaceAllString(strings.TrimPrefix(ext, "."), "")
+
+	if base == "" {
+		return ""
+	}
+
+	if len(ext) > fileNameMaxExtLength {
+		ext = ext[:fileNameMaxExtLength]
+	}
+
+	if ext != "" {
+		ext = "." + ext
+	}
+
+	// the extension is preserved on truncation
+	if max := FileNameMaxLength - len(ext); len(base) > max {
+		base = base[:max]
+	}
+
+	return base + ext
+}
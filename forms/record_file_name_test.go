@@ -0,0 +1,233 @@
+package forms_test
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/rest"
+)
+
+// fileNameTestRequest returns a multipart request with
+// the provided file field uploaded files (with "test" content).
+func fileNameTestRequest(t *testing.T, field string, filenames ...string) *http.Request {
+	body := new(bytes.Buffer)
+	mp := multipart.NewWriter(body)
+
+	for _, name := range filenames {
+		w, err := mp.CreateFormFile(field, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte("test"))
+	}
+	mp.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set(echo.HeaderContentType, mp.FormDataContentType())
+
+	return req
+}
+
+// fileNameTestRecord returns a demo4 record with the
+// provided "manyfiles" field naming options.
+func fileNameTestRecord(t *testing.T, app *tests.TestApp, strategy string, generator string) *models.Record {
+	collection, _ := app.Dao().FindCollectionByNameOrId("demo4")
+
+	field := collection.Schema.GetFieldByName("manyfiles")
+	options, _ := field.Options.(*schema.FileOptions)
+	options.NameStrategy = strategy
+	options.NameGenerator = generator
+
+	record, err := app.Dao().FindFirstRecordByData(collection, "id", "df55c8ff-45ef-4c82-8aed-6e2183fe1125")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return record
+}
+
+func TestRecordUpsertFileNameRandom(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	record := fileNameTestRecord(t, app, "", "")
+
+	form := forms.NewRecordUpsert(app, record)
+	if err := form.LoadData(fileNameTestRequest(t, "manyfiles", "test.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := form.Submit(); err != nil {
+		t.Fatal(err)
+	}
+
+	names := record.GetStringSliceDataValue("manyfiles")
+	if len(names) != 1 || names[0] == "test.txt" || !strings.HasSuffix(names[0], ".txt") {
+		t.Fatalf("Expected a single random .txt file name, got %v", names)
+	}
+}
+
+func TestRecordUpsertFileNameOriginal(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	record := fileNameTestRecord(t, app, schema.FileNameStrategyOriginal, "")
+
+	// existing file in another field of the same record
+	existing := record.GetStringDataValue("onefile")
+
+	form := forms.NewRecordUpsert(app, record)
+	req := fileNameTestRequest(t, "manyfiles", "../My File (1).txt", "My File (1).txt", existing)
+	if err := form.LoadData(req); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := form.Submit(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"My_File_1.txt",
+		"My_File_1_1.txt",
+		strings.TrimSuffix(existing, ".png") + "_1.png",
+	}
+
+	names := record.GetStringSliceDataValue("manyfiles")
+	if len(names) != len(expected) {
+		t.Fatalf("Expected names %v, got %v", expected, names)
+	}
+
+	fs, _ := app.NewFilesystem()
+	defer fs.Close()
+
+	for i, name := range expected {
+		if names[i] != name {
+			t.Fatalf("Expected names %v, got %v", expected, names)
+		}
+
+		if exists, _ := fs.Exists(record.BaseFilesPath() + "/" + name); !exists {
+			t.Fatalf("Expected file %q to be stored", name)
+		}
+	}
+}
+
+func TestRecordUpsertFileNameCustom(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	forms.RegisterFileNameGenerator("test_record", func(record *models.Record, field *schema.SchemaField, file *rest.UploadedFile) (string, error) {
+		return record.Id + "_" + field.Name + "_" + file.Header().Filename, nil
+	})
+	forms.RegisterFileNameGenerator("test_empty", func(record *models.Record, field *schema.SchemaField, file *rest.UploadedFile) (string, error) {
+		return "", nil
+	})
+	forms.RegisterFileNameGenerator("test_error", func(record *models.Record, field *schema.SchemaField, file *rest.UploadedFile) (string, error) {
+		return "", errors.New("test")
+	})
+
+	scenarios := []struct {
+		generator   string
+		expectError bool
+		expected    string
+	}{
+		{"missing", true, ""},
+		{"test_error", true, ""},
+		{"test_empty", false, ""},
+		{"test_record", false, "df55c8ff-45ef-4c82-8aed-6e2183fe1125_manyfiles_test.txt"},
+	}
+
+	for _, s := range scenarios {
+		record := fileNameTestRecord(t, app, schema.FileNameStrategyCustom, s.generator)
+
+		form := forms.NewRecordUpsert(app, record)
+		if err := form.LoadData(fileNameTestRequest(t, "manyfiles", "test.txt")); err != nil {
+			t.Fatalf("[%s] Failed to load the form data: %v", s.generator, err)
+		}
+
+		// the file naming errors are reported as field validation errors
+		err := form.Submit()
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Fatalf("[%s] Expected hasErr %v, got %v (%v)", s.generator, s.expectError, hasErr, err)
+		}
+		if hasErr {
+			if errs, _ := err.(validation.Errors); errs["manyfiles"] == nil {
+				t.Fatalf("[%s] Expected manyfiles validation error, got %v", s.generator, err)
+			}
+			continue
+		}
+
+		// the uploaded files are appended to the previous scenarios ones
+		names := record.GetStringSliceDataValue("manyfiles")
+		name := names[len(names)-1]
+
+		if s.expected == "" {
+			if name == "test.txt" {
+				t.Fatalf("[%s] Expected the random file name fallback, got %q", s.generator, name)
+			}
+		} else if name != s.expected {
+			t.Fatalf("[%s] Expected file name %q, got %q", s.generator, s.expected, name)
+		}
+	}
+}
+
+func TestRecordUpsertFileNameErrorWithOtherErrors(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	record := fileNameTestRecord(t, app, schema.FileNameStrategyCustom, "missing")
+
+	body := new(bytes.Buffer)
+	mp := multipart.NewWriter(body)
+	mp.WriteField("title", "a")
+	for _, item := range [][2]string{
+		{"manyfiles", "test.txt"},
+		{"onlyimages", "test1.txt"},
+		{"onlyimages", "test2.txt"},
+	} {
+		w, err := mp.CreateFormFile(item[0], item[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte("test"))
+	}
+	mp.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set(echo.HeaderContentType, mp.FormDataContentType())
+
+	form := forms.NewRecordUpsert(app, record)
+	if err := form.LoadData(req); err != nil {
+		t.Fatal(err)
+	}
+
+	errs, _ := form.Validate().(validation.Errors)
+
+	for _, k := range []string{"title", "manyfiles", "onlyimages"} {
+		if errs[k] == nil {
+			t.Fatalf("Missing expected error key %q in %v", k, errs)
+		}
+	}
+
+	// the file naming error should be nested like the other file errors
+	if nestedErrs, _ := errs["manyfiles"].(validation.Errors); nestedErrs["name"] == nil {
+		t.Fatalf("Expected manyfiles nested %q error, got %v", "name", errs["manyfiles"])
+	}
+
+	// both invalid uploaded files should be reported
+	if nestedErrs, _ := errs["onlyimages"].(validation.Errors); len(nestedErrs) != 2 {
+		t.Fatalf("Expected 2 onlyimages file errors, got %v", errs["onlyimages"])
+	}
+}
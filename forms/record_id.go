@@ -0,0 +1,249 @@
+package forms
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/store"
+)
+
+// recordIdSequenceCachePrefix is the app cache key prefix of the collections id sequences.
+const recordIdSequenceCachePrefix = "@recordIdSequence_"
+
+// recordIdMaxAttempts is the max number of sequence ids to try
+// before giving up due to collisions with existing records.
+const recordIdMaxAttempts = 100
+
+// RecordIdMaxLength is the max allowed length of a client-supplied record id.
+const RecordIdMaxLength = 100
+
+// ErrRecordIdConflict is returned when a record with
+// the client-supplied id already exists.
+var ErrRecordIdConflict = errors.New("A record with the provided id already exists.")
+
+// recordIdRegex matches a valid client-supplied record id
+// (it is also used in the record files path so it must be url and path safe).
+var recordIdRegex = regexp.MustCompile(`^[\w\-]+$`)
+
+// RecordIdGeneratorFunc defines a custom record id generator function.
+//
+// txDao is the Dao of the current record create transaction.
+type RecordIdGeneratorFunc func(txDao *daos.Dao, record *models.Record) (string, error)
+
+var recordIdGenerators = store.New(map[string]RecordIdGeneratorFunc{})
+
+// RegisterRecordIdGenerator registers a custom record id generator
+// with the specified name, so that it could be used by the collections
+// with "custom" id options type, eg:
+//
+//	forms.RegisterRecordIdGenerator("ticket", func(txDao *daos.Dao, record *models.Record) (string, error) {
+//		return "T-" + security.RandomStringWithAlphabet(8, "0123456789ABCDEF"), nil
+//	})
+//
+// Registering a generator with an already existing name replaces it.
+func RegisterRecordIdGenerator(name string, fn RecordIdGeneratorFunc) {
+	recordIdGenerators.Set(name, fn)
+}
+
+// HasRecordIdGenerator checks whether a custom record id generator
+// with the specified name is registered.
+func HasRecordIdGenerator(name string) bool {
+	return recordIdGenerators.Has(name)
+}
+
+// generateRecordId sets a new record id based on the record collection
+// id options (does nothing if the record already has an id or the
+// collection uses the default random ids).
+//
+// The record with the generated id is marked as new to ensure
+// that it will be created on save.
+func generateRecordId(app core.App, txDao *daos.Dao, record *models.Record) error {
+	if record.HasId() {
+		return nil
+	}
+
+	options := record.Collection().Options.Id
+
+	switch options.Type {
+	case models.RecordIdTypeSequence:
+		id, err := nextRecordSequenceId(app, txDao, record.Collection())
+		if err != nil {
+			return err
+		}
+		record.Id = id
+		record.MarkAsNew()
+	case models.RecordIdTypeCustom:
+		generator := recordIdGenerators.Get(options.Generator)
+		if generator == nil {
+			return fmt.Errorf("Missing record id generator %q.", options.Generator)
+		}
+
+		id, err := generator(txDao, record)
+		if err != nil {
+			return err
+		}
+		if id == "" {
+			return errors.New("The record id generator returned an empty id.")
+		}
+		record.Id = id
+		record.MarkAsNew()
+	}
+
+	return nil
+}
+
+// saveNewRecord persists the provided new record, generating its id
+// based on the record collection id options (if the record doesn't have one).
+//
+// The generated ids are regenerated on collision with
+// an existing record id (see [daos.Dao.CreateIdRetries]).
+func saveNewRecord(app core.App, txDao *daos.Dao, record *models.Record) error {
+	options := record.Collection().Options.Id
+
+	if record.HasId() {
+		// client-supplied id
+		// (could be already taken by a concurrent create after its validation)
+		err := txDao.SaveRecord(record)
+		if err != nil && daos.IsIdCollision(err, record.TableName()) {
+			return ErrRecordIdConflict
+		}
+		return err
+	}
+
+	if options.Type == "" || options.Type == models.RecordIdTypeRandom {
+		// random id (regenerated by the dao on collision)
+		return txDao.SaveRecord(record)
+	}
+
+	return txDao.CreateRecordWithIdGenerator(record, func() error {
+		record.Id = ""
+		return generateRecordId(app, txDao, record)
+	})
+}
+
+// recordIdSequence defines a single collection id sequence state.
+type recordIdSequence struct {
+	mux    sync.Mutex
+	loaded bool
+	last   int64
+}
+
+var recordIdSequencesMux sync.Mutex
+
+// nextRecordSequenceId returns the next available sequence id of the collection.
+//
+// The sequence counter is kept in the app cache and it is initialized
+// from the largest existing id on first use. Ids that are already taken
+// (eg. manually created records) are skipped.
+//
+// Note that the sequence numbers of rolled back creates are not reused.
+func nextRecordSequenceId(app core.App, txDao *daos.Dao, collection *models.Collection) (string, error) {
+	options := collection.Options.Id
+
+	key := recordIdSequenceCachePrefix + collection.Id + "_" + options.Prefix
+
+	recordIdSequencesMux.Lock()
+	seq, _ := app.Cache().Get(key).(*recordIdSequence)
+	if seq == nil {
+		seq = &recordIdSequence{}
+		app.Cache().Set(key, seq)
+	}
+	recordIdSequencesMux.Unlock()
+
+	seq.mux.Lock()
+	defer seq.mux.Unlock()
+
+	if !seq.loaded {
+		last, err := findLastRecordSequence(txDao, collection.Name, options.Prefix)
+		if err != nil {
+			return "", err
+		}
+		seq.last = last
+		seq.loaded = true
+	}
+
+	for i := 0; i < recordIdMaxAttempts; i++ {
+		seq.last++
+
+		id := fmt.Sprintf("%s%0*d", options.Prefix, options.Padding, seq.last)
+
+		var exists bool
+		err := txDao.DB().Select("(1)").
+			From(collection.Name).
+			Where(dbx.HashExp{"id": id}).
+			Limit(1).
+			Row(&exists)
+		if err == sql.ErrNoRows {
+			return id, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return "", errors.New("Failed to generate a unique sequence record id.")
+}
+
+// findLastRecordSequence returns the largest sequence number
+// of the existing collection records with the specified id prefix.
+func findLastRecordSequence(txDao *daos.Dao, tableName string, prefix string) (int64, error) {
+	var last sql.NullInt64
+
+	err := txDao.DB().NewQuery(fmt.Sprintf(
+		"SELECT MAX(CAST(SUBSTR([[id]], {:offset}) AS INTEGER)) FROM {{%s}} WHERE SUBSTR([[id]], 1, {:length}) = {:prefix} AND SUBSTR([[id]], {:offset}) GLOB '[0-9]*'",
+		tableName,
+	)).Bind(dbx.Params{
+		"prefix": prefix,
+		"length": len(prefix),
+		"offset": len(prefix) + 1,
+	}).Row(&last)
+	if err != nil {
+		return 0, err
+	}
+
+	return last.Int64, nil
+}
+
+// checkClientRecordId validates the format and the uniqueness
+// of a client-supplied new record id.
+//
+// Client ids are allowed only for collections with the default random ids.
+//
+// Note that the id could still be taken by a concurrent create before
+// the record insert, in which case [saveNewRecord] also returns [ErrRecordIdConflict].
+func checkClientRecordId(dao *daos.Dao, collection *models.Collection, id string) error {
+	if t := collection.Options.Id.Type; t != "" && t != models.RecordIdTypeRandom {
+		return validation.Errors{"id": validation.NewError(
+			"validation_id_not_allowed",
+			"The collection record ids are generated automatically.",
+		)}
+	}
+
+	err := validation.Validate(id, validation.Length(1, RecordIdMaxLength), validation.Match(recordIdRegex))
+	if err != nil {
+		return validation.Errors{"id": err}
+	}
+
+	var exists bool
+	err = dao.RecordQuery(collection).
+		Select("count(*)").
+		AndWhere(dbx.HashExp{"id": id}).
+		Limit(1).
+		Row(&exists)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrRecordIdConflict
+	}
+
+	return nil
+}
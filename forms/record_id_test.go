@@ -0,0 +1,160 @@
+package forms_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestRecordUpsertSequenceId(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, _ := app.Dao().FindCollectionByNameOrId("demo3")
+	collection.Options.Id = models.RecordIdOptions{
+		Type:    models.RecordIdTypeSequence,
+		Prefix:  "INV-",
+		Padding: 5,
+	}
+
+	// existing record with the collection prefix
+	existing := models.NewRecord(collection)
+	existing.Id = "INV-00007"
+	existing.MarkAsNew()
+	if err := app.Dao().SaveRecord(existing); err != nil {
+		t.Fatal(err)
+	}
+
+	create := func() string {
+		record := models.NewRecord(collection)
+		form := forms.NewRecordUpsert(app, record)
+		form.Data["title"] = "test"
+		if err := form.Submit(); err != nil {
+			t.Fatalf("Failed to submit the form: %v", err)
+		}
+		return record.Id
+	}
+
+	if id := create(); id != "INV-00008" {
+		t.Fatalf("Expected id INV-00008, got %q", id)
+	}
+
+	// collision with a manually created record
+	manual := models.NewRecord(collection)
+	manual.Id = "INV-00009"
+	manual.MarkAsNew()
+	if err := app.Dao().SaveRecord(manual); err != nil {
+		t.Fatal(err)
+	}
+
+	if id := create(); id != "INV-00010" {
+		t.Fatalf("Expected id INV-00010, got %q", id)
+	}
+
+	// reinitialize the sequence from the db
+	for key := range app.Cache().GetAll() {
+		app.Cache().Remove(key)
+	}
+
+	if id := create(); id != "INV-00011" {
+		t.Fatalf("Expected id INV-00011, got %q", id)
+	}
+
+	// explicitly set ids are preserved
+	record := models.NewRecord(collection)
+	record.Id = "custom-id"
+	record.MarkAsNew()
+	form := forms.NewRecordUpsert(app, record)
+	if err := form.Submit(); err != nil {
+		t.Fatal(err)
+	}
+	if record.Id != "custom-id" {
+		t.Fatalf("Expected the explicit id to be preserved, got %q", record.Id)
+	}
+}
+
+func TestRecordUpsertCustomId(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	forms.RegisterRecordIdGenerator("test_generator", func(txDao *daos.Dao, record *models.Record) (string, error) {
+		return "generated_" + record.GetStringDataValue("title"), nil
+	})
+
+	forms.RegisterRecordIdGenerator("test_generator_error", func(txDao *daos.Dao, record *models.Record) (string, error) {
+		return "", errors.New("test")
+	})
+
+	collection, _ := app.Dao().FindCollectionByNameOrId("demo3")
+
+	scenarios := []struct {
+		generator   string
+		expectedId  string
+		expectError bool
+	}{
+		{"missing", "", true},
+		{"test_generator_error", "", true},
+		{"test_generator", "generated_abc", false},
+	}
+
+	for i, s := range scenarios {
+		collection.Options.Id = models.RecordIdOptions{
+			Type:      models.RecordIdTypeCustom,
+			Generator: s.generator,
+		}
+
+		record := models.NewRecord(collection)
+		form := forms.NewRecordUpsert(app, record)
+		form.Data["title"] = "abc"
+
+		err := form.Submit()
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+			continue
+		}
+
+		if !s.expectError && record.Id != s.expectedId {
+			t.Errorf("(%d) Expected id %q, got %q", i, s.expectedId, record.Id)
+		}
+	}
+}
+
+func TestRecordUpsertCustomIdCollisionRetry(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// the first generated id collides with an existing record
+	ids := []string{"2c542824-9de1-42fe-8924-e57c86267760", "generated_new"}
+	forms.RegisterRecordIdGenerator("test_colliding_generator", func(txDao *daos.Dao, record *models.Record) (string, error) {
+		id := ids[0]
+		ids = ids[1:]
+		return id, nil
+	})
+
+	collection, _ := app.Dao().FindCollectionByNameOrId("demo3")
+	collection.Options.Id = models.RecordIdOptions{
+		Type:      models.RecordIdTypeCustom,
+		Generator: "test_colliding_generator",
+	}
+
+	record := models.NewRecord(collection)
+	form := forms.NewRecordUpsert(app, record)
+	form.Data["title"] = "abc"
+	if err := form.Submit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if record.Id != "generated_new" {
+		t.Fatalf("Expected id %q, got %q", "generated_new", record.Id)
+	}
+
+	if _, err := app.Dao().FindRecordById(collection, "generated_new", nil); err != nil {
+		t.Fatalf("Expected the record to be created, got %v", err)
+	}
+}
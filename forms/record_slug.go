@@ -0,0 +1,109 @@
+package forms
+
+import (
+	"fmt"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/inflector"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/spf13/cast"
+)
+
+// recordSlugMaxLength is the max length of the generated slugs (without the suffix).
+const recordSlugMaxLength = 100
+
+// recordSlugMaxAttempts is the max number of numeric suffixes to try
+// before fallbacking to a random one.
+const recordSlugMaxAttempts = 100
+
+// generateRecordSlugs populates the data with the auto generated
+// record slug fields values.
+//
+// It must be called before loading the data into the record,
+// so that the submitted source values could be compared with the old ones.
+//
+// A slug is generated if it is not manually changed and:
+//   - the slug is empty (eg. on create or explicitly cleared)
+//   - the source value has changed and the field has the "regenerate" option
+func generateRecordSlugs(dao *daos.Dao, record *models.Record, data map[string]any) error {
+	for _, field := range record.Collection().Schema.Fields() {
+		if field.Type != schema.FieldTypeSlug {
+			continue
+		}
+
+		field.InitOptions()
+		options, _ := field.Options.(*schema.SlugOptions)
+		if options == nil {
+			continue
+		}
+
+		current := cast.ToString(record.GetDataValue(field.Name))
+
+		submitted := current
+		if v, ok := data[field.Name]; ok {
+			submitted = cast.ToString(v)
+		}
+
+		oldSource := cast.ToString(record.GetDataValue(options.Source))
+		source := oldSource
+		if v, ok := data[options.Source]; ok {
+			source = cast.ToString(v)
+		}
+
+		if submitted != "" && (submitted != current || !options.Regenerate || source == oldSource) {
+			continue // manually set or unchanged
+		}
+
+		slug, err := uniqueRecordSlug(dao, record, field.Name, inflector.Slugify(source))
+		if err != nil {
+			return err
+		}
+
+		if slug == "" && field.Required {
+			return validation.Errors{field.Name: validation.NewError(
+				"validation_required",
+				fmt.Sprintf("Missing required value (failed to generate slug from %q).", options.Source),
+			)}
+		}
+
+		data[field.Name] = slug
+	}
+
+	return nil
+}
+
+// uniqueRecordSlug returns the first available slug for the record,
+// appending a numeric suffix to the base slug on collision (eg. "title-2").
+func uniqueRecordSlug(dao *daos.Dao, record *models.Record, fieldName string, base string) (string, error) {
+	if len(base) > recordSlugMaxLength {
+		base = strings.TrimRight(base[:recordSlugMaxLength], "-")
+	}
+
+	if base == "" {
+		return "", nil
+	}
+
+	collection := record.Collection()
+
+	for i := 1; i <= recordSlugMaxAttempts; i++ {
+		slug := base
+		if i > 1 {
+			slug = fmt.Sprintf("%s-%d", base, i)
+		}
+
+		if dao.IsRecordValueUnique(collection, fieldName, slug, record.GetId()) {
+			return slug, nil
+		}
+	}
+
+	slug := base + "-" + strings.ToLower(security.RandomString(8))
+	if !dao.IsRecordValueUnique(collection, fieldName, slug, record.GetId()) {
+		return "", fmt.Errorf("Failed to generate an unique %q slug.", fieldName)
+	}
+
+	return slug, nil
+}
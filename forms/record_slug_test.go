@@ -0,0 +1,77 @@
+package forms_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestRecordUpsertSlug(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, _ := app.Dao().FindCollectionByNameOrId("demo3")
+	collection.Schema.AddField(&schema.SchemaField{
+		Name:    "slug",
+		Type:    schema.FieldTypeSlug,
+		Options: &schema.SlugOptions{Source: "title"},
+	})
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	submit := func(record *models.Record, data map[string]any) string {
+		form := forms.NewRecordUpsert(app, record)
+		for k, v := range data {
+			form.Data[k] = v
+		}
+		if err := form.Submit(); err != nil {
+			t.Fatalf("Failed to submit the form: %v", err)
+		}
+		return record.GetStringDataValue("slug")
+	}
+
+	// generated on create
+	record1 := models.NewRecord(collection)
+	if slug := submit(record1, map[string]any{"title": "Hello Wörld!"}); slug != "hello-world" {
+		t.Fatalf("Expected slug hello-world, got %q", slug)
+	}
+
+	// unique suffix on collision
+	record2 := models.NewRecord(collection)
+	if slug := submit(record2, map[string]any{"title": "Hello world"}); slug != "hello-world-2" {
+		t.Fatalf("Expected slug hello-world-2, got %q", slug)
+	}
+
+	// manually set
+	record3 := models.NewRecord(collection)
+	if slug := submit(record3, map[string]any{"title": "Hello world", "slug": "custom"}); slug != "custom" {
+		t.Fatalf("Expected slug custom, got %q", slug)
+	}
+
+	// source change without the regenerate option
+	if slug := submit(record1, map[string]any{"title": "Changed"}); slug != "hello-world" {
+		t.Fatalf("Expected the slug to be preserved, got %q", slug)
+	}
+
+	// explicitly cleared slug
+	if slug := submit(record1, map[string]any{"slug": ""}); slug != "changed" {
+		t.Fatalf("Expected the slug to be regenerated, got %q", slug)
+	}
+
+	// source change with the regenerate option
+	collection.Schema.GetFieldByName("slug").Options = &schema.SlugOptions{Source: "title", Regenerate: true}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	if slug := submit(record2, map[string]any{"title": "Hello world"}); slug != "hello-world-2" {
+		t.Fatalf("Expected the slug to be preserved on unchanged source, got %q", slug)
+	}
+	if slug := submit(record2, map[string]any{"title": "Hello again"}); slug != "hello-again" {
+		t.Fatalf("Expected slug hello-again, got %q", slug)
+	}
+}
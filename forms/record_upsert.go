@@ -1,13 +1,17 @@
 package forms
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strconv"
+	"strings"
 
+	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/daos"
@@ -22,11 +26,25 @@ import (
 // RecordUpsert defines a Record upsert form.
 type RecordUpsert struct {
 	app    core.App
+	appDao *daos.Dao
 	record *models.Record
 
 	isCreate      bool
 	filesToDelete []string // names list
 	filesToUpload []*rest.UploadedFile
+	uploadErrors  validation.Errors // the nested file field errors collected on data load
+
+	depth            int
+	nestedData       []*nestedRecordData
+	nestedRecords    []*models.Record
+	nestedCreateFunc NestedRecordCreateFunc
+	nestedTotal      *int // shared by the whole nested records tree
+
+	updatedBy string
+
+	// Id is the optional client-supplied id of the new record
+	// (ignored on update).
+	Id string `form:"id" json:"id"`
 
 	Data map[string]any `json:"data"`
 }
@@ -37,7 +55,7 @@ func NewRecordUpsert(app core.App, record *models.Record) *RecordUpsert {
 	form := &RecordUpsert{
 		app:           app,
 		record:        record,
-		isCreate:      !record.HasId(),
+		isCreate:      record.IsNew(),
 		filesToDelete: []string{},
 		filesToUpload: []*rest.UploadedFile{},
 	}
@@ -50,6 +68,21 @@ func NewRecordUpsert(app core.App, record *models.Record) *RecordUpsert {
 	return form
 }
 
+// SetDao replaces the default form Dao (app.Dao()) used
+// to validate and persist the record (eg. to execute the form
+// queries with the request context).
+func (form *RecordUpsert) SetDao(dao *daos.Dao) {
+	form.appDao = dao
+}
+
+func (form *RecordUpsert) dao() *daos.Dao {
+	if form.appDao != nil {
+		return form.appDao
+	}
+
+	return form.app.Dao()
+}
+
 func (form *RecordUpsert) getContentType(r *http.Request) string {
 	t := r.Header.Get("Content-Type")
 	for i, c := range t {
@@ -66,6 +99,8 @@ func (form *RecordUpsert) extractRequestData(r *http.Request) (map[string]any, e
 		return form.extractJsonData(r)
 	case "multipart/form-data":
 		return form.extractMultipartFormData(r)
+	case "application/x-www-form-urlencoded":
+		return form.extractUrlEncodedFormData(r)
 	default:
 		return nil, errors.New("Unsupported request Content-Type.")
 	}
@@ -74,22 +109,54 @@ func (form *RecordUpsert) extractRequestData(r *http.Request) (map[string]any, e
 func (form *RecordUpsert) extractJsonData(r *http.Request) (map[string]any, error) {
 	result := map[string]any{}
 
-	err := rest.ReadJsonBodyCopy(r, &result)
+	raw := json.RawMessage{}
+	if err := rest.ReadJsonBodyCopy(r, &raw); err != nil {
+		return result, err
+	}
+
+	// decode the numbers as json.Number to preserve their precision
+	// (they are further normalized based on the schema field options)
+	err := decodeJsonWithNumbers(raw, &result)
 
 	return result, err
 }
 
-func (form *RecordUpsert) extractMultipartFormData(r *http.Request) (map[string]any, error) {
-	result := map[string]any{}
+func decodeJsonWithNumbers(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	return decoder.Decode(v)
+}
 
+func (form *RecordUpsert) extractMultipartFormData(r *http.Request) (map[string]any, error) {
 	// parse form data (if not already)
 	if err := r.ParseMultipartForm(rest.DefaultMaxMemory); err != nil {
-		return result, err
+		return map[string]any{}, err
+	}
+
+	return form.extractFormValues(r.PostForm), nil
+}
+
+func (form *RecordUpsert) extractUrlEncodedFormData(r *http.Request) (map[string]any, error) {
+	// parse form data (if not already)
+	if err := r.ParseForm(); err != nil {
+		return map[string]any{}, err
 	}
 
+	return form.extractFormValues(r.PostForm), nil
+}
+
+// extractFormValues maps the submitted form values to the record fields
+// (the repeated keys of the arrayable fields are loaded as slice).
+//
+// The values are submitted as plain strings and are further
+// coerced to the field type on load (see [schema.SchemaField.PrepareValue]).
+func (form *RecordUpsert) extractFormValues(formValues url.Values) map[string]any {
+	result := map[string]any{}
+
 	arrayValueSupportTypes := schema.ArraybleFieldTypes()
 
-	for key, values := range r.PostForm {
+	for key, values := range formValues {
 		if len(values) == 0 {
 			result[key] = nil
 			continue
@@ -103,7 +170,7 @@ func (form *RecordUpsert) extractMultipartFormData(r *http.Request) (map[string]
 		}
 	}
 
-	return result, nil
+	return result
 }
 
 func (form *RecordUpsert) normalizeData() error {
@@ -116,7 +183,8 @@ func (form *RecordUpsert) normalizeData() error {
 	return nil
 }
 
-// LoadData loads and normalizes json OR multipart/form-data request data.
+// LoadData loads and normalizes json, multipart/form-data OR
+// application/x-www-form-urlencoded request data.
 //
 // File upload is supported only via multipart/form-data.
 //
@@ -129,19 +197,47 @@ func (form *RecordUpsert) normalizeData() error {
 // with the file index (eg. `myfile.0`) and set it to null or empty string.
 // For single file upload fields, you can skip the index and directly
 // reset the field using its field name (eg. `myfile`).
+//
+// The collection field aliases (if any) are accepted as data keys
+// interchangeably with the field names.
+//
+// Relation fields could also be set to an object (or array of objects)
+// with the data of new relation records that are created and linked
+// together with the form record on submit.
 func (form *RecordUpsert) LoadData(r *http.Request) error {
 	requestData, err := form.extractRequestData(r)
 	if err != nil {
 		return err
 	}
 
+	requestData = form.record.Collection().Options.ResolveAliases(requestData)
+
+	form.loadId(requestData)
+
+	form.uploadErrors = validation.Errors{}
+
+	// resolve also the aliased file index keys (eg. "alias.0" -> "myfile.0")
+	for name, alias := range form.record.Collection().Options.Aliases {
+		for k, v := range requestData {
+			if strings.HasPrefix(k, alias+".") {
+				delete(requestData, k)
+				requestData[name+k[len(alias):]] = v
+			}
+		}
+	}
+
 	// extend base data with the extracted one
 	extendedData := form.record.Data()
 	rawData, err := json.Marshal(requestData)
 	if err != nil {
 		return err
 	}
-	if err := json.Unmarshal(rawData, &extendedData); err != nil {
+	if err := decodeJsonWithNumbers(rawData, &extendedData); err != nil {
+		return err
+	}
+
+	// extract the new nested relation records data (if any)
+	if err := form.extractNestedData(extendedData); err != nil {
 		return err
 	}
 
@@ -196,10 +292,27 @@ func (form *RecordUpsert) LoadData(r *http.Request) error {
 
 			// check if there are any new uploaded form files
 			files, err := rest.FindUploadedFiles(r, key)
+			if alias := form.record.Collection().Options.Aliases[key]; err != nil && alias != "" {
+				files, err = rest.FindUploadedFiles(r, alias)
+			}
 			if err != nil {
 				continue // skip invalid or missing file(s)
 			}
 
+			if options.MaxSelect == 1 {
+				files = files[:1]
+			}
+
+			// apply the field file naming strategy (if any)
+			// (the error is reported together with the other fields validation
+			// errors, nested under "name" similar to the other file errors)
+			if err := form.nameUploadedFiles(field, files); err != nil {
+				form.uploadErrors[key] = validation.Errors{
+					"name": validation.NewError("validation_invalid_file_name", err.Error()),
+				}
+				continue
+			}
+
 			// refresh oldNames list
 			oldNames = list.ToUniqueStringSlice(form.Data[key])
 
@@ -228,13 +341,53 @@ func (form *RecordUpsert) LoadData(r *http.Request) error {
 
 // Validate makes the form validatable by implementing [validation.Validatable] interface.
 func (form *RecordUpsert) Validate() error {
+	return form.validate(form.dao())
+}
+
+func (form *RecordUpsert) validate(dao *daos.Dao) error {
+	if form.isCreate && form.Id != "" {
+		if err := checkClientRecordId(dao, form.record.Collection(), form.Id); err != nil {
+			return err
+		}
+	}
+
 	dataValidator := validators.NewRecordDataValidator(
-		form.app.Dao(),
+		dao,
 		form.record,
 		form.filesToUpload,
 	)
 
-	return dataValidator.Validate(form.Data)
+	err := dataValidator.Validate(form.Data)
+	if len(form.uploadErrors) == 0 {
+		return err
+	}
+
+	// merge the upload errors with the data validation ones
+	// so that all invalid fields are reported together
+	errs := validation.Errors{}
+	if err != nil {
+		dataErrs, ok := err.(validation.Errors)
+		if !ok {
+			return err
+		}
+		for k, v := range dataErrs {
+			errs[k] = v
+		}
+	}
+	for k, v := range form.uploadErrors {
+		// merge with the field file errors (see [validators.RecordDataValidator])
+		fieldErrs, _ := errs[k].(validation.Errors)
+		if fieldErrs == nil {
+			errs[k] = v
+			continue
+		}
+		nestedErrs, _ := v.(validation.Errors)
+		for nestedKey, nestedErr := range nestedErrs {
+			fieldErrs[nestedKey] = nestedErr
+		}
+	}
+
+	return errs
 }
 
 // DrySubmit performs a form submit within a transaction and reverts it.
@@ -242,16 +395,25 @@ func (form *RecordUpsert) Validate() error {
 //
 // This method doesn't handle file uploads/deletes or trigger any app events!
 func (form *RecordUpsert) DrySubmit(callback func(txDao *daos.Dao) error) error {
-	if err := form.Validate(); err != nil {
-		return err
-	}
+	// the nested relation records (if any) are created
+	// and validated as part of the transaction
+	if !form.hasNestedData() {
+		if err := form.Validate(); err != nil {
+			return err
+		}
 
-	// bulk load form data
-	if err := form.record.Load(form.Data); err != nil {
-		return err
+		// generate the missing or outdated slugs (if any)
+		if err := generateRecordSlugs(form.dao(), form.record, form.Data); err != nil {
+			return err
+		}
+
+		// bulk load form data
+		if err := form.loadRecord(); err != nil {
+			return err
+		}
 	}
 
-	return form.app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+	return form.dao().RunInTransaction(func(txDao *daos.Dao) error {
 		tx, ok := txDao.DB().(*dbx.Tx)
 		if !ok {
 			return errors.New("failed to get transaction db")
@@ -262,6 +424,12 @@ func (form *RecordUpsert) DrySubmit(callback func(txDao *daos.Dao) error) error
 		txDao.BeforeUpdateFunc = nil
 		txDao.AfterUpdateFunc = nil
 
+		if form.hasNestedData() {
+			if err := form.loadInTransaction(txDao, false); err != nil {
+				return err
+			}
+		}
+
 		if err := txDao.SaveRecord(form.record); err != nil {
 			return err
 		}
@@ -270,36 +438,94 @@ func (form *RecordUpsert) DrySubmit(callback func(txDao *daos.Dao) error) error
 	})
 }
 
+// SetUpdatedBy sets the update client identifier (eg. "admin:ID")
+// stored in the new record version on update (see [daos.Dao.SaveRecordBy]).
+func (form *RecordUpsert) SetUpdatedBy(updatedBy string) {
+	form.updatedBy = updatedBy
+}
+
 // Submit validates the form and upserts the form Record model.
+//
+// The nested relation records (if any) are created
+// within the same transaction as the form Record model.
 func (form *RecordUpsert) Submit() error {
+	if form.hasNestedData() {
+		return form.dao().RunInTransaction(func(txDao *daos.Dao) error {
+			if err := form.loadInTransaction(txDao, true); err != nil {
+				return err
+			}
+
+			return form.persist(txDao)
+		})
+	}
+
 	if err := form.Validate(); err != nil {
 		return err
 	}
 
+	// generate the missing or outdated slugs (if any)
+	if err := generateRecordSlugs(form.dao(), form.record, form.Data); err != nil {
+		return err
+	}
+
 	// bulk load form data
-	if err := form.record.Load(form.Data); err != nil {
+	if err := form.loadRecord(); err != nil {
 		return err
 	}
 
-	return form.app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
-		// persist record model
-		if err := txDao.SaveRecord(form.record); err != nil {
-			return err
-		}
+	return form.dao().RunInTransaction(form.persist)
+}
 
-		// upload new files (if any)
-		if err := form.processFilesToUpload(); err != nil {
-			return err
-		}
+// loadId loads the client-supplied record id from the provided data (if any).
+func (form *RecordUpsert) loadId(data map[string]any) {
+	if id, ok := data[schema.ReservedFieldNameId]; ok && form.isCreate {
+		form.Id = cast.ToString(id)
+	}
+}
 
-		// delete old files (if any)
-		if err := form.processFilesToDelete(); err != nil { //nolint:staticcheck
-			// for now fail silently to avoid reupload when `form.Submit()`
-			// is called manually (aka. not from an api request)...
-		}
+// loadRecord loads the form data (and the client-supplied id)
+// into the form record.
+func (form *RecordUpsert) loadRecord() error {
+	if form.isCreate && form.Id != "" {
+		form.record.Id = form.Id
+		form.record.MarkAsNew()
+	}
 
-		return nil
-	})
+	return form.record.Load(form.Data)
+}
+
+// persist saves the already loaded form record and its files.
+func (form *RecordUpsert) persist(txDao *daos.Dao) error {
+	// persist record model
+	// (generating the new record id if the collection has custom id options)
+	var saveErr error
+	if form.isCreate {
+		saveErr = saveNewRecord(form.app, txDao, form.record)
+	} else {
+		saveErr = txDao.SaveRecordBy(form.record, form.updatedBy)
+	}
+	if saveErr != nil {
+		return saveErr
+	}
+
+	// the files changes are not reverted on transaction rollback
+	// (aka. the transaction shouldn't be retried)
+	if len(form.filesToUpload) > 0 || len(form.filesToDelete) > 0 {
+		txDao.MarkSideEffect()
+	}
+
+	// upload new files (if any)
+	if err := form.processFilesToUpload(); err != nil {
+		return err
+	}
+
+	// delete old files (if any)
+	if err := form.processFilesToDelete(); err != nil { //nolint:staticcheck
+		// for now fail silently to avoid reupload when `form.Submit()`
+		// is called manually (aka. not from an api request)...
+	}
+
+	return nil
 }
 
 func (form *RecordUpsert) processFilesToUpload() error {
@@ -322,7 +548,19 @@ func (form *RecordUpsert) processFilesToUpload() error {
 		file := form.filesToUpload[i]
 		path := form.record.BaseFilesPath() + "/" + file.Name()
 
-		if err := fs.Upload(file.Bytes(), path); err == nil {
+		event := &core.FileUploadEvent{
+			Collection: form.record.Collection(),
+			Record:     form.record,
+			FileField:  form.record.FindFileFieldByFile(file.Name()),
+			File:       file,
+			Reader:     bytes.NewReader(file.Bytes()),
+		}
+
+		err := form.app.OnFileBeforeUpload().Trigger(event, func(e *core.FileUploadEvent) error {
+			return fs.UploadReader(e.Reader, path, e.ContentType)
+		})
+
+		if err == nil {
 			// remove the uploaded file from the list
 			form.filesToUpload = append(form.filesToUpload[:i], form.filesToUpload[i+1:]...)
 		} else {
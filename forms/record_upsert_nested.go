@@ -0,0 +1,229 @@
+package forms
+
+import (
+	"errors"
+	"fmt"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/rest"
+	"github.com/spf13/cast"
+)
+
+// RecordUpsertMaxNestingDepth is the max allowed nesting depth of the
+// relation records that are created together with their parent record
+// (eg. 2 allows creating children and grandchildren records).
+const RecordUpsertMaxNestingDepth = 3
+
+// RecordUpsertMaxNestedRecords is the max allowed total number of nested
+// relation records (including the deeper nested ones) that a single
+// record submit could create.
+const RecordUpsertMaxNestedRecords = 50
+
+// NestedRecordCreateFunc defines a function that wraps the creation of
+// a single nested relation record.
+//
+// data is the submitted data of the nested record and
+// the create function must be called to actually persist the record.
+//
+// A returned [rest.ApiError] is returned by the form submit as it is,
+// while all other errors are reported as the relation field errors.
+type NestedRecordCreateFunc func(txDao *daos.Dao, record *models.Record, data map[string]any, create func() error) error
+
+// nestedRecordData defines the submitted data of a new relation record.
+type nestedRecordData struct {
+	field string
+	data  map[string]any
+}
+
+// SetNestedCreateFunc registers a function that wraps the creation
+// of each nested relation record (eg. to trigger the record create hooks).
+func (form *RecordUpsert) SetNestedCreateFunc(fn NestedRecordCreateFunc) {
+	form.nestedCreateFunc = fn
+}
+
+// NestedRecords returns all nested relation records (including
+// the deeper nested ones) created with the last form submit.
+func (form *RecordUpsert) NestedRecords() []*models.Record {
+	return form.nestedRecords
+}
+
+func (form *RecordUpsert) hasNestedData() bool {
+	return len(form.nestedData) > 0
+}
+
+// extractNestedData moves the relation field object values
+// (aka. the new relation records data) out of the provided data map,
+// leaving in their place only the existing relation record ids.
+func (form *RecordUpsert) extractNestedData(data map[string]any) error {
+	if form.nestedTotal == nil {
+		form.nestedTotal = new(int)
+	}
+
+	totalBefore := len(form.nestedData)
+
+	for _, field := range form.record.Collection().Schema.Fields() {
+		if field.Type != schema.FieldTypeRelation {
+			continue
+		}
+
+		var items []any
+		switch v := data[field.Name].(type) {
+		case map[string]any:
+			items = []any{v}
+		case []any:
+			items = v
+		default:
+			continue
+		}
+
+		ids := []string{}
+		hasNested := false
+		for _, item := range items {
+			if itemData, ok := item.(map[string]any); ok {
+				hasNested = true
+				form.nestedData = append(form.nestedData, &nestedRecordData{field: field.Name, data: itemData})
+			} else if id := cast.ToString(item); id != "" {
+				ids = append(ids, id)
+			}
+		}
+
+		if !hasNested {
+			continue
+		}
+
+		if form.depth >= RecordUpsertMaxNestingDepth {
+			return fmt.Errorf("%s: nested relation records are limited to depth %d", field.Name, RecordUpsertMaxNestingDepth)
+		}
+
+		data[field.Name] = ids
+	}
+
+	*form.nestedTotal += len(form.nestedData) - totalBefore
+
+	if *form.nestedTotal > RecordUpsertMaxNestedRecords {
+		return fmt.Errorf("a record could create no more than %d nested relation records", RecordUpsertMaxNestedRecords)
+	}
+
+	return nil
+}
+
+// loadNestedData loads and normalizes the submitted nested relation record data.
+func (form *RecordUpsert) loadNestedData(data map[string]any) error {
+	data = form.record.Collection().Options.ResolveAliases(data)
+
+	form.loadId(data)
+
+	if err := form.extractNestedData(data); err != nil {
+		return err
+	}
+
+	for k, v := range data {
+		form.Data[k] = v
+	}
+
+	return form.normalizeData()
+}
+
+// loadInTransaction creates the nested relation records (if any),
+// validates the form and loads its data into the form record.
+//
+// Set withHooks to false to skip the registered nested create func.
+func (form *RecordUpsert) loadInTransaction(txDao *daos.Dao, withHooks bool) error {
+	if err := form.createNestedRecords(txDao, withHooks); err != nil {
+		return err
+	}
+
+	if err := form.validate(txDao); err != nil {
+		return err
+	}
+
+	// generate the missing or outdated slugs (if any)
+	if err := generateRecordSlugs(txDao, form.record, form.Data); err != nil {
+		return err
+	}
+
+	return form.loadRecord()
+}
+
+// createNestedRecords creates the nested relation records and
+// appends their ids to the related form relation field values.
+func (form *RecordUpsert) createNestedRecords(txDao *daos.Dao, withHooks bool) error {
+	form.nestedRecords = []*models.Record{}
+
+	if !form.hasNestedData() {
+		return nil
+	}
+
+	expand := form.record.GetExpand()
+	fieldIndexes := map[string]int{}
+
+	for _, nested := range form.nestedData {
+		index := fieldIndexes[nested.field]
+		fieldIndexes[nested.field]++
+
+		field := form.record.Collection().Schema.GetFieldByName(nested.field)
+		options, _ := field.Options.(*schema.RelationOptions)
+
+		relCollection, err := txDao.FindCollectionByNameOrId(options.CollectionId)
+		if err != nil {
+			return validation.Errors{nested.field: validation.NewError(
+				"validation_missing_rel_collection",
+				"Relation connection is missing or cannot be accessed",
+			)}
+		}
+
+		child := NewRecordUpsert(form.app, models.NewRecord(relCollection))
+		child.appDao = form.appDao
+		child.depth = form.depth + 1
+		child.nestedCreateFunc = form.nestedCreateFunc
+		child.nestedTotal = form.nestedTotal
+		if err := child.loadNestedData(nested.data); err != nil {
+			return validation.Errors{nested.field: validation.NewError("validation_invalid_nested_record", err.Error())}
+		}
+
+		create := func() error {
+			if err := child.loadInTransaction(txDao, withHooks); err != nil {
+				return err
+			}
+			return child.persist(txDao)
+		}
+
+		if withHooks && form.nestedCreateFunc != nil {
+			err = form.nestedCreateFunc(txDao, child.record, nested.data, create)
+		} else {
+			err = create()
+		}
+		if err != nil {
+			// return the api errors of the nested create func (eg. forbidden) as they are
+			var apiErr *rest.ApiError
+			if errors.As(err, &apiErr) {
+				return err
+			}
+			return validation.Errors{nested.field: validation.Errors{cast.ToString(index): err}}
+		}
+
+		form.nestedRecords = append(form.nestedRecords, child.nestedRecords...)
+		form.nestedRecords = append(form.nestedRecords, child.record)
+
+		// link the new relation record
+		if options.MaxSelect == 1 {
+			form.Data[nested.field] = child.record.Id
+			expand[nested.field] = child.record
+		} else {
+			ids := append(list.ToUniqueStringSlice(form.Data[nested.field]), child.record.Id)
+			form.Data[nested.field] = field.PrepareValue(ids)
+
+			expanded, _ := expand[nested.field].([]*models.Record)
+			expand[nested.field] = append(expanded, child.record)
+		}
+	}
+
+	// return the created relation records inline
+	form.record.SetExpand(expand)
+
+	return nil
+}
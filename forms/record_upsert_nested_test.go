@@ -0,0 +1,214 @@
+package forms_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/spf13/cast"
+)
+
+func TestRecordUpsertNestedRecords(t *testing.T) {
+	scenarios := []struct {
+		name          string
+		body          string
+		expectLoadErr bool
+		expectError   bool
+		expectNested  int
+	}{
+		{
+			"invalid nested record data",
+			`{"onerel":{"title":"a"}}`,
+			false,
+			true,
+			0,
+		},
+		{
+			"unknown nested record field",
+			`{"onerel":{"title":"abc","missing":1}}`,
+			false,
+			true,
+			0,
+		},
+		{
+			"single nested record",
+			`{"onerel":{"title":"nested1"}}`,
+			false,
+			false,
+			1,
+		},
+		{
+			"existing and nested records",
+			`{"manyrels":["b84cd893-7119-43c9-8505-3c4e22da28a9",{"title":"nested1"},{"title":"nested2"}]}`,
+			false,
+			false,
+			2,
+		},
+		{
+			"too many nested records",
+			`{"manyrels":[` + strings.TrimSuffix(strings.Repeat(`{"title":"abc"},`, forms.RecordUpsertMaxNestedRecords+1), ",") + `]}`,
+			true,
+			false,
+			0,
+		},
+		{
+			"too many nested records in total",
+			`{"manyrels":[` + strings.TrimSuffix(strings.Repeat(`{"title":"abc","manyrels":[{"title":"abc"}]},`, forms.RecordUpsertMaxNestedRecords/2+1), ",") + `]}`,
+			false,
+			true,
+			0,
+		},
+	}
+
+	for _, s := range scenarios {
+		app, _ := tests.NewTestApp()
+
+		collection, _ := app.Dao().FindCollectionByNameOrId("demo4")
+		totalBefore := countRecords(t, app.Dao(), collection)
+
+		record := models.NewRecord(collection)
+		form := forms.NewRecordUpsert(app, record)
+
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(s.body))
+		req.Header.Set("Content-Type", "application/json")
+
+		loadErr := form.LoadData(req)
+		if hasErr := loadErr != nil; hasErr != s.expectLoadErr {
+			t.Errorf("[%s] Expected hasLoadErr %v, got %v (%v)", s.name, s.expectLoadErr, hasErr, loadErr)
+		}
+		if loadErr != nil {
+			app.Cleanup()
+			continue
+		}
+
+		err := form.Submit()
+		if hasErr := err != nil; hasErr != s.expectError {
+			t.Errorf("[%s] Expected hasErr %v, got %v (%v)", s.name, s.expectError, hasErr, err)
+		}
+
+		expectedNew := 0
+		if !s.expectError {
+			expectedNew = s.expectNested + 1 // +1 for the parent record
+		}
+		if totalAfter := countRecords(t, app.Dao(), collection); totalAfter-totalBefore != expectedNew {
+			t.Errorf("[%s] Expected %d new records, got %d", s.name, expectedNew, totalAfter-totalBefore)
+		}
+
+		if err == nil {
+			if len(form.NestedRecords()) != s.expectNested {
+				t.Errorf("[%s] Expected %d nested records, got %d", s.name, s.expectNested, len(form.NestedRecords()))
+			}
+
+			relIds := append(record.GetStringSliceDataValue("manyrels"), record.GetStringSliceDataValue("onerel")...)
+			for _, nested := range form.NestedRecords() {
+				found := false
+				for _, id := range relIds {
+					if id == nested.Id {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("[%s] Expected nested record %q to be linked, got %v", s.name, nested.Id, relIds)
+				}
+			}
+
+			if len(record.GetExpand()) == 0 {
+				t.Errorf("[%s] Expected the nested records to be expanded", s.name)
+			}
+		}
+
+		app.Cleanup()
+	}
+}
+
+func TestRecordUpsertNestedRecordsDepthLimit(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// demo4 relation fields reference the same collection
+	collection, _ := app.Dao().FindCollectionByNameOrId("demo4")
+
+	nestedBody := func(depth int) string {
+		return strings.Repeat(`{"onerel":`, depth) + `{"title":"abc"}` + strings.Repeat(`}`, depth)
+	}
+
+	submit := func(body string) error {
+		form := forms.NewRecordUpsert(app, models.NewRecord(collection))
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if err := form.LoadData(req); err != nil {
+			return err
+		}
+		return form.Submit()
+	}
+
+	if err := submit(nestedBody(forms.RecordUpsertMaxNestingDepth)); err != nil {
+		t.Fatalf("Expected the max nesting depth to be allowed, got %v", err)
+	}
+
+	if err := submit(nestedBody(forms.RecordUpsertMaxNestingDepth + 1)); err == nil {
+		t.Fatal("Expected the nesting depth error, got nil")
+	}
+}
+
+func TestRecordUpsertNestedRecordsCreateFunc(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, _ := app.Dao().FindCollectionByNameOrId("demo4")
+	totalBefore := countRecords(t, app.Dao(), collection)
+
+	form := forms.NewRecordUpsert(app, models.NewRecord(collection))
+
+	calls := 0
+	titles := []string{}
+	form.SetNestedCreateFunc(func(txDao *daos.Dao, record *models.Record, data map[string]any, create func() error) error {
+		calls++
+		titles = append(titles, cast.ToString(data["title"]))
+		if err := create(); err != nil {
+			return err
+		}
+		if calls == 2 {
+			return errors.New("test")
+		}
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"manyrels":[{"title":"nested1"},{"title":"nested2"}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	if err := form.LoadData(req); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := form.Submit(); err == nil {
+		t.Fatal("Expected the submit to fail")
+	}
+
+	if calls != 2 {
+		t.Fatalf("Expected the create func to be called 2 times, got %d", calls)
+	}
+
+	if strings.Join(titles, ",") != "nested1,nested2" {
+		t.Fatalf("Expected the nested records data to be passed to the create func, got %v", titles)
+	}
+
+	// the whole transaction should be reverted
+	if total := countRecords(t, app.Dao(), collection); total != totalBefore {
+		t.Fatalf("Expected %d records, got %d", totalBefore, total)
+	}
+}
+
+func countRecords(t *testing.T, dao *daos.Dao, collection *models.Collection) int {
+	var total int
+	if err := dao.RecordQuery(collection).Select("count(*)").Row(&total); err != nil {
+		t.Fatal(err)
+	}
+	return total
+}
@@ -3,18 +3,24 @@ package forms_test
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"path/filepath"
 	"strings"
 	"testing"
+	"testing/iotest"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/daos"
 	"github.com/pocketbase/pocketbase/forms"
 	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
 	"github.com/pocketbase/pocketbase/tests"
 	"github.com/pocketbase/pocketbase/tools/list"
 )
@@ -49,7 +55,7 @@ func TestRecordUpsertLoadDataUnsupported(t *testing.T) {
 
 	form := forms.NewRecordUpsert(app, record)
 	req := httptest.NewRequest(http.MethodGet, "/", strings.NewReader(testData))
-	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	req.Header.Set(echo.HeaderContentType, echo.MIMETextPlain)
 
 	if err := form.LoadData(req); err == nil {
 		t.Fatal("Expected LoadData to fail, got nil")
@@ -122,6 +128,175 @@ func TestRecordUpsertLoadDataJson(t *testing.T) {
 	}
 }
 
+func TestRecordUpsertLoadDataJsonWithAliases(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, _ := app.Dao().FindCollectionByNameOrId("demo4")
+	collection.Options.Aliases = map[string]string{"title": "name", "manyfiles": "files"}
+
+	record, err := app.Dao().FindFirstRecordByData(collection, "id", "054f9f24-0a0a-4e09-87b1-bc7ff2b336a2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testData := map[string]any{
+		"name":    "test123",
+		"files.0": "",
+	}
+
+	form := forms.NewRecordUpsert(app, record)
+	jsonBody, _ := json.Marshal(testData)
+	req := httptest.NewRequest(http.MethodGet, "/", bytes.NewReader(jsonBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if err := form.LoadData(req); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := form.Data["title"]; v != "test123" {
+		t.Fatalf("Expect title field to be %q, got %q", "test123", v)
+	}
+
+	if v, ok := form.Data["name"]; ok {
+		t.Fatalf("Didn't expect the alias key to be set, got %v", v)
+	}
+
+	if remains := len(list.ToUniqueStringSlice(form.Data["manyfiles"])); remains != 1 {
+		t.Fatalf("Expect only 1 manyfiles to remain, got %v", form.Data["manyfiles"])
+	}
+}
+
+func TestRecordUpsertLoadDataJsonPreciseNumbers(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := &models.Collection{
+		Name: "precise_test",
+		Schema: schema.NewSchema(
+			&schema.SchemaField{
+				Name: "float",
+				Type: schema.FieldTypeNumber,
+			},
+			&schema.SchemaField{
+				Name:    "precise",
+				Type:    schema.FieldTypeDecimal,
+				Options: &schema.DecimalOptions{Scale: 2},
+			},
+		),
+	}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	record := models.NewRecord(collection)
+
+	form := forms.NewRecordUpsert(app, record)
+	req := httptest.NewRequest(http.MethodGet, "/", strings.NewReader(
+		`{"float":12345678901234567.01,"precise":12345678901234567.01}`,
+	))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if err := form.LoadData(req); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, _ := form.Data["float"].(float64); v != 12345678901234567.01 {
+		t.Fatalf("Expected float field to be float64, got %v (%T)", form.Data["float"], form.Data["float"])
+	}
+
+	if v := form.Data["precise"]; v != "12345678901234567.01" {
+		t.Fatalf("Expected precise field to be %q, got %v (%T)", "12345678901234567.01", v, v)
+	}
+
+	if err := form.Submit(); err != nil {
+		t.Fatal(err)
+	}
+
+	// reload from the db
+	saved, err := app.Dao().FindRecordById(collection, record.Id, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, _ := saved.MarshalJSON()
+	if !strings.Contains(string(raw), `"precise":"12345678901234567.01"`) {
+		t.Fatalf("Expected the precise number to be preserved, got %s", raw)
+	}
+}
+
+func TestRecordUpsertLoadDataJsonNullableFields(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := &models.Collection{
+		Name: "nullable_test",
+		Schema: schema.NewSchema(
+			&schema.SchemaField{
+				Name:    "number",
+				Type:    schema.FieldTypeNumber,
+				Options: &schema.NumberOptions{Nullable: true},
+			},
+			&schema.SchemaField{
+				Name:    "bool",
+				Type:    schema.FieldTypeBool,
+				Options: &schema.BoolOptions{Nullable: true},
+			},
+			&schema.SchemaField{
+				Name:    "date",
+				Type:    schema.FieldTypeDate,
+				Options: &schema.DateOptions{Nullable: true},
+			},
+		),
+	}
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		id       string
+		data     string
+		expected string
+	}{
+		{"absent", `{}`, `"bool":null,"date":null,"number":null`},
+		{"null", `{"number":null,"bool":null,"date":null}`, `"bool":null,"date":null,"number":null`},
+		{"empty", `{"number":"","bool":"","date":""}`, `"bool":null,"date":null,"number":null`},
+		{"zero", `{"number":0,"bool":false,"date":"2022-01-01 10:00:00.000"}`, `"bool":false,"date":"2022-01-01 10:00:00.000","number":0`},
+	}
+
+	for _, s := range scenarios {
+		record := models.NewRecord(collection)
+
+		form := forms.NewRecordUpsert(app, record)
+		req := httptest.NewRequest(http.MethodGet, "/", strings.NewReader(s.data))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if err := form.LoadData(req); err != nil {
+			t.Fatalf("[%s] %v", s.id, err)
+		}
+
+		if err := form.Submit(); err != nil {
+			t.Fatalf("[%s] %v", s.id, err)
+		}
+
+		// reload from the db
+		saved, err := app.Dao().FindRecordById(collection, record.Id, nil)
+		if err != nil {
+			t.Fatalf("[%s] %v", s.id, err)
+		}
+
+		raw, _ := json.Marshal(saved.Data())
+		if !strings.Contains(string(raw), s.expected) {
+			t.Fatalf("[%s] Expected %s, got %s", s.id, s.expected, raw)
+		}
+	}
+
+	// the null values should be distinguishable from the zero ones
+	var nullCount, zeroCount int
+	app.Dao().RecordQuery(collection).Select("count(*)").AndWhere(dbx.NewExp("[[number]] IS NULL")).Row(&nullCount)
+	app.Dao().RecordQuery(collection).Select("count(*)").AndWhere(dbx.HashExp{"number": 0}).Row(&zeroCount)
+	if nullCount != 3 || zeroCount != 1 {
+		t.Fatalf("Expected 3 null and 1 zero numbers, got %d and %d", nullCount, zeroCount)
+	}
+}
+
 func TestRecordUpsertLoadDataMultipart(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -189,6 +364,53 @@ func TestRecordUpsertLoadDataMultipart(t *testing.T) {
 	}
 }
 
+func TestRecordUpsertLoadDataUrlEncoded(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, _ := app.Dao().FindCollectionByNameOrId("demo4")
+	record, err := app.Dao().FindFirstRecordByData(collection, "id", "054f9f24-0a0a-4e09-87b1-bc7ff2b336a2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testData := url.Values{}
+	testData.Set("title", "test123")
+	testData.Set("unknown", "test456")
+	testData.Set("onerel", "b84cd893-7119-43c9-8505-3c4e22da28a9")
+	testData.Add("manyrels", "df55c8ff-45ef-4c82-8aed-6e2183fe1125")
+	testData.Add("manyrels", "b84cd893-7119-43c9-8505-3c4e22da28a9")
+	// file fields unset
+	testData.Set("onefile", "")
+
+	form := forms.NewRecordUpsert(app, record)
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(testData.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	if err := form.LoadData(req); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, ok := form.Data["title"]; !ok || v != "test123" {
+		t.Fatalf("Expect title field to be %q, got %q", "test123", v)
+	}
+
+	if v, ok := form.Data["unknown"]; ok {
+		t.Fatalf("Didn't expect unknown field to be set, got %v", v)
+	}
+
+	if v := form.Data["onerel"]; v != "b84cd893-7119-43c9-8505-3c4e22da28a9" {
+		t.Fatalf("Expect onerel field to be %q, got %v", "b84cd893-7119-43c9-8505-3c4e22da28a9", v)
+	}
+
+	if v := list.ToUniqueStringSlice(form.Data["manyrels"]); len(v) != 2 {
+		t.Fatalf("Expect 2 manyrels, got %v", v)
+	}
+
+	if v, ok := form.Data["onefile"]; !ok || v != nil {
+		t.Fatalf("Expect onefile field to be nil, got %v", v)
+	}
+}
+
 func TestRecordUpsertValidateFailure(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -482,6 +704,121 @@ func TestRecordUpsertSubmitSuccess(t *testing.T) {
 	}
 }
 
+func TestRecordUpsertSubmitFileTransform(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// chained transforms
+	app.OnFileBeforeUpload().Add(func(e *core.FileUploadEvent) error {
+		content, err := io.ReadAll(e.Reader)
+		if err != nil {
+			return err
+		}
+		e.Reader = strings.NewReader(strings.ToUpper(string(content)))
+		return nil
+	})
+	app.OnFileBeforeUpload().Add(func(e *core.FileUploadEvent) error {
+		if e.FileField == nil || e.FileField.Name != "onefile" {
+			t.Fatalf("Expected the onefile field, got %v", e.FileField)
+		}
+		e.Reader = io.MultiReader(e.Reader, strings.NewReader("_transformed"))
+		e.ContentType = "text/plain"
+		return nil
+	})
+
+	collection, _ := app.Dao().FindCollectionByNameOrId("demo4")
+	record, err := app.Dao().FindFirstRecordByData(collection, "id", "054f9f24-0a0a-4e09-87b1-bc7ff2b336a2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	formData, mp, err := tests.MockMultipartData(nil, "onefile")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	form := forms.NewRecordUpsert(app, record)
+	req := httptest.NewRequest(http.MethodGet, "/", formData)
+	req.Header.Set(echo.HeaderContentType, mp.FormDataContentType())
+	form.LoadData(req)
+
+	if err := form.Submit(); err != nil {
+		t.Fatalf("Expected nil, got error %v", err)
+	}
+
+	if app.EventCalls["OnFileBeforeUpload"] != 1 {
+		t.Fatalf("Expected OnFileBeforeUpload to be called once, got %d", app.EventCalls["OnFileBeforeUpload"])
+	}
+
+	fs, _ := app.NewFilesystem()
+	defer fs.Close()
+
+	fileKey := record.BaseFilesPath() + "/" + record.GetStringDataValue("onefile")
+
+	attrs, err := fs.Attributes(fileKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attrs.ContentType != "text/plain" {
+		t.Fatalf("Expected text/plain content type, got %q", attrs.ContentType)
+	}
+
+	r := httptest.NewRecorder()
+	if err := fs.Serve(r, httptest.NewRequest(http.MethodGet, "/", nil), fileKey, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if body := r.Body.String(); body != "TEST_transformed" {
+		t.Fatalf("Expected the transformed file content, got %q", body)
+	}
+}
+
+func TestRecordUpsertSubmitFileTransformFailure(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.OnFileBeforeUpload().Add(func(e *core.FileUploadEvent) error {
+		// fail in the middle of the stream
+		e.Reader = io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(errors.New("transform failure")))
+		return nil
+	})
+
+	collection, _ := app.Dao().FindCollectionByNameOrId("demo4")
+	recordBefore, err := app.Dao().FindFirstRecordByData(collection, "id", "054f9f24-0a0a-4e09-87b1-bc7ff2b336a2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	formData, mp, err := tests.MockMultipartData(map[string]string{
+		"title": "test_save",
+	}, "onefile")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	form := forms.NewRecordUpsert(app, recordBefore)
+	req := httptest.NewRequest(http.MethodGet, "/", formData)
+	req.Header.Set(echo.HeaderContentType, mp.FormDataContentType())
+	form.LoadData(req)
+
+	result := form.Submit()
+	if result == nil || !strings.Contains(result.Error(), "transform failure") {
+		t.Fatalf("Expected transform failure error, got %v", result)
+	}
+
+	recordAfter, err := app.Dao().FindFirstRecordByData(collection, "id", recordBefore.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if recordAfter.GetStringDataValue("title") == "test_save" {
+		t.Fatal("Expected the record save to be aborted")
+	}
+
+	if hasRecordFile(app, recordAfter, form.Data["onefile"].(string)) {
+		t.Fatal("Expected the failed file to not be stored")
+	}
+}
+
 func hasRecordFile(app core.App, record *models.Record, filename string) bool {
 	fs, _ := app.NewFilesystem()
 	defer fs.Close()
@@ -496,3 +833,134 @@ func hasRecordFile(app core.App, record *models.Record, filename string) bool {
 
 	return exists
 }
+
+func TestRecordUpsertSubmitClientId(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		data           string
+		expectConflict bool
+		expectedErrors []string
+	}{
+		{"invalid id format", `{"id":"../invalid","title":"abc"}`, false, []string{"id"}},
+		{"too long id", `{"id":"` + strings.Repeat("a", forms.RecordIdMaxLength+1) + `","title":"abc"}`, false, []string{"id"}},
+		{"existing id", `{"id":"577bd676-aacb-4072-b7da-99d00ee210a4","title":"abc"}`, true, nil},
+		{"new id", `{"id":"client_id-1","title":"abc"}`, false, nil},
+	}
+
+	for _, s := range scenarios {
+		app, _ := tests.NewTestApp()
+
+		collection, _ := app.Dao().FindCollectionByNameOrId("demo")
+
+		record := models.NewRecord(collection)
+		form := forms.NewRecordUpsert(app, record)
+		req := httptest.NewRequest(http.MethodGet, "/", strings.NewReader(s.data))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if err := form.LoadData(req); err != nil {
+			t.Fatalf("[%s] Failed to load data: %v", s.name, err)
+		}
+
+		err := form.Submit()
+
+		if s.expectConflict != errors.Is(err, forms.ErrRecordIdConflict) {
+			t.Errorf("[%s] Expected conflict %v, got %v", s.name, s.expectConflict, err)
+		}
+
+		if s.expectConflict {
+			app.Cleanup()
+			continue
+		}
+
+		errs, _ := err.(validation.Errors)
+		if len(errs) != len(s.expectedErrors) {
+			t.Errorf("[%s] Expected error keys %v, got %v", s.name, s.expectedErrors, err)
+		}
+		for _, k := range s.expectedErrors {
+			if _, ok := errs[k]; !ok {
+				t.Errorf("[%s] Missing expected error key %q in %v", s.name, k, errs)
+			}
+		}
+
+		if err == nil {
+			if _, findErr := app.Dao().FindRecordById(collection, form.Id, nil); findErr != nil || record.Id != form.Id {
+				t.Errorf("[%s] Expected record with the client id %q to be created, got %q (%v)", s.name, form.Id, record.Id, findErr)
+			}
+		}
+
+		app.Cleanup()
+	}
+}
+
+func TestRecordUpsertSubmitClientIdConcurrentCreate(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, _ := app.Dao().FindCollectionByNameOrId("demo")
+
+	record := models.NewRecord(collection)
+	form := forms.NewRecordUpsert(app, record)
+	req := httptest.NewRequest(http.MethodGet, "/", strings.NewReader(`{"id":"client_id-1","title":"abc"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if err := form.LoadData(req); err != nil {
+		t.Fatal(err)
+	}
+
+	// simulate a concurrent create with the same id after the form validation
+	app.OnModelBeforeCreate().Add(func(e *core.ModelEvent) error {
+		_, err := app.DB().Insert(collection.Name, dbx.Params{"id": "client_id-1"}).Execute()
+		return err
+	})
+
+	if err := form.Submit(); !errors.Is(err, forms.ErrRecordIdConflict) {
+		t.Fatalf("Expected the id conflict error, got %v", err)
+	}
+}
+
+func TestRecordUpsertSubmitClientIdOnUpdate(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, _ := app.Dao().FindCollectionByNameOrId("demo")
+	record, err := app.Dao().FindRecordById(collection, "577bd676-aacb-4072-b7da-99d00ee210a4", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	form := forms.NewRecordUpsert(app, record)
+	req := httptest.NewRequest(http.MethodGet, "/", strings.NewReader(`{"id":"changed","title":"abc"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if err := form.LoadData(req); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := form.Submit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if record.Id != "577bd676-aacb-4072-b7da-99d00ee210a4" {
+		t.Fatalf("Expected the record id to remain unchanged, got %q", record.Id)
+	}
+}
+
+func TestRecordUpsertSubmitClientIdNotAllowed(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, _ := app.Dao().FindCollectionByNameOrId("demo")
+	collection.Options.Id.Type = models.RecordIdTypeSequence
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	form := forms.NewRecordUpsert(app, models.NewRecord(collection))
+	req := httptest.NewRequest(http.MethodGet, "/", strings.NewReader(`{"id":"client_id","title":"abc"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if err := form.LoadData(req); err != nil {
+		t.Fatal(err)
+	}
+
+	errs, _ := form.Submit().(validation.Errors)
+	if _, ok := errs["id"]; !ok {
+		t.Fatalf("Expected id validation error, got %v", errs)
+	}
+}
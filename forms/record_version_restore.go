@@ -0,0 +1,62 @@
+package forms
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/forms/validators"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// RecordVersionRestore defines a record version restore form.
+type RecordVersionRestore struct {
+	app       core.App
+	record    *models.Record
+	state     *models.Record
+	updatedBy string
+}
+
+// NewRecordVersionRestore creates a new restore form for the provided
+// record and its version state (see [daos.Dao.FindRecordVersionState]).
+func NewRecordVersionRestore(app core.App, record *models.Record, state *models.Record) *RecordVersionRestore {
+	return &RecordVersionRestore{
+		app:    app,
+		record: record,
+		state:  state,
+	}
+}
+
+// Record returns the record that will be restored.
+func (form *RecordVersionRestore) Record() *models.Record {
+	return form.record
+}
+
+// SetUpdatedBy sets the restore client identifier (eg. "admin:ID")
+// stored in the new record version.
+func (form *RecordVersionRestore) SetUpdatedBy(updatedBy string) {
+	form.updatedBy = updatedBy
+}
+
+// Submit validates the version state against the current collection
+// schema and updates the record with it.
+//
+// Like any other update, the restore stores the replaced record
+// state as a new version (aka. the restore could be undone).
+//
+// Note that only the record data is restored and the files
+// removed with a previous update are not recovered.
+func (form *RecordVersionRestore) Submit() error {
+	return form.app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+		data := map[string]any{}
+		for _, field := range form.record.Collection().Schema.Fields() {
+			data[field.Name] = form.state.GetDataValue(field.Name)
+			form.record.SetDataValue(field.Name, data[field.Name])
+		}
+
+		validator := validators.NewRecordDataValidator(txDao, form.record, nil)
+		if err := validator.Validate(data); err != nil {
+			return err
+		}
+
+		return txDao.SaveRecordBy(form.record, form.updatedBy)
+	})
+}
@@ -0,0 +1,61 @@
+package forms_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestRecordVersionRestoreSubmit(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, _ := app.Dao().FindCollectionByNameOrId("demo3")
+	collection.Options.Versions = true
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := app.Dao().FindRecordById(collection, "2c542824-9de1-42fe-8924-e57c86267760", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	originalTitle := record.GetStringDataValue("title")
+
+	record.SetDataValue("title", "changed")
+	if err := app.Dao().SaveRecord(record); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := app.Dao().FindRecordVersionState(record, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	form := forms.NewRecordVersionRestore(app, record, state)
+	form.SetUpdatedBy("admin:test")
+	if err := form.Submit(); err != nil {
+		t.Fatalf("Expected the restore to succeed, got %v", err)
+	}
+
+	restored, err := app.Dao().FindRecordById(collection, record.Id, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := restored.GetStringDataValue("title"); v != originalTitle {
+		t.Fatalf("Expected title %q, got %q", originalTitle, v)
+	}
+
+	// the restore creates a new version with the replaced state
+	version2, err := app.Dao().FindRecordVersion(record, 2)
+	if err != nil {
+		t.Fatalf("Expected a new version to be created, got %v", err)
+	}
+	if version2.Data["title"] != "changed" {
+		t.Fatalf("Expected the new version title %q, got %v", "changed", version2.Data["title"])
+	}
+	if version2.UpdatedBy != "admin:test" {
+		t.Fatalf("Expected updatedBy %q, got %q", "admin:test", version2.UpdatedBy)
+	}
+}
@@ -0,0 +1,301 @@
+package forms
+
+import (
+	"errors"
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/forms/validators"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/rest"
+	"github.com/spf13/cast"
+)
+
+const (
+	// RecordsBatchModeAtomic creates all records or none of them.
+	RecordsBatchModeAtomic = "atomic"
+
+	// RecordsBatchModePartial creates each record in its own transaction
+	// and preserves the successfully created ones.
+	RecordsBatchModePartial = "partial"
+
+	// RecordsBatchMaxRecords is the max number of records in a single batch.
+	RecordsBatchMaxRecords = 500
+)
+
+// errBatchAborted is used internally to rollback the atomic batch transaction.
+var errBatchAborted = errors.New("The batch was aborted due to failed records.")
+
+// RecordsBatchCreate defines a records batch create form.
+//
+// Note that the batch records are created without triggering the
+// record create request hooks (the model hooks are still triggered).
+//
+// The batch records could have client-supplied ids and they could
+// reference each other with them via the collection self relation fields
+// (the referenced records are created first, regardless of their batch order).
+type RecordsBatchCreate struct {
+	app        core.App
+	collection *models.Collection
+
+	Mode    string           `form:"mode" json:"mode"`
+	Records []map[string]any `form:"records" json:"records"`
+}
+
+// RecordsBatchCreateResult defines the records batch create report.
+type RecordsBatchCreateResult struct {
+	Mode    string                          `json:"mode"`
+	Created int                             `json:"created"`
+	Failed  int                             `json:"failed"`
+	Items   []*RecordsBatchCreateItemResult `json:"items"`
+}
+
+// RecordsBatchCreateItemResult defines a single batch record report.
+type RecordsBatchCreateItemResult struct {
+	// Index is the record index in the submitted batch (starting from 0).
+	Index   int            `json:"index"`
+	Success bool           `json:"success"`
+	Record  *models.Record `json:"record"`
+	Error   *rest.ApiError `json:"error"`
+}
+
+// NewRecordsBatchCreate creates a new records batch create form for the provided collection.
+func NewRecordsBatchCreate(app core.App, collection *models.Collection) *RecordsBatchCreate {
+	return &RecordsBatchCreate{
+		app:        app,
+		collection: collection,
+		Mode:       RecordsBatchModeAtomic,
+	}
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *RecordsBatchCreate) Validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(
+			&form.Mode,
+			validation.Required,
+			validation.In(RecordsBatchModeAtomic, RecordsBatchModePartial),
+		),
+		validation.Field(
+			&form.Records,
+			validation.Required,
+			validation.Length(1, RecordsBatchMaxRecords),
+		),
+	)
+}
+
+// Submit validates the form and creates the batch records.
+//
+// checkFunc is an optional callback that is invoked after each record
+// persistence within the record transaction (eg. to check the collection
+// create rule). Returning an error marks the record as failed.
+//
+// The record errors don't fail the submit - they are reported in the
+// returned result (for the atomic mode, a single record error rollbacks
+// the entire batch and all items are reported as failed).
+func (form *RecordsBatchCreate) Submit(checkFunc func(txDao *daos.Dao, record *models.Record) error) (*RecordsBatchCreateResult, error) {
+	if err := form.Validate(); err != nil {
+		return nil, err
+	}
+
+	result := &RecordsBatchCreateResult{
+		Mode:  form.Mode,
+		Items: make([]*RecordsBatchCreateItemResult, len(form.Records)),
+	}
+
+	if form.Mode == RecordsBatchModeAtomic {
+		err := form.app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+			for _, i := range form.createOrder() {
+				result.Items[i] = form.createRecord(txDao, i, form.Records[i], checkFunc)
+			}
+
+			if result.countFailed() > 0 {
+				return errBatchAborted
+			}
+
+			return nil
+		})
+
+		if errors.Is(err, errBatchAborted) {
+			for _, item := range result.Items {
+				if item.Success {
+					item.Success = false
+					item.Record = nil
+					item.Error = rest.NewBadRequestError("The record was rolled back due to other failed records.", nil)
+				}
+			}
+		} else if err != nil {
+			return nil, err
+		}
+	} else {
+		for _, i := range form.createOrder() {
+			var item *RecordsBatchCreateItemResult
+
+			err := form.app.Dao().RunInTransaction(func(txDao *daos.Dao) error {
+				item = form.createRecord(txDao, i, form.Records[i], checkFunc)
+				if !item.Success {
+					return errBatchAborted // rollback the failed record changes
+				}
+				return nil
+			})
+			if err != nil && !errors.Is(err, errBatchAborted) {
+				item = newRecordsBatchCreateItemError(i, err)
+			}
+
+			result.Items[i] = item
+		}
+	}
+
+	result.Failed = result.countFailed()
+	result.Created = len(result.Items) - result.Failed
+
+	return result, nil
+}
+
+func (form *RecordsBatchCreate) createRecord(
+	txDao *daos.Dao,
+	index int,
+	data map[string]any,
+	checkFunc func(txDao *daos.Dao, record *models.Record) error,
+) *RecordsBatchCreateItemResult {
+	record := models.NewRecord(form.collection)
+
+	normalizedData := map[string]any{}
+	for _, field := range form.collection.Schema.Fields() {
+		if field.Type == schema.FieldTypeFile {
+			continue // file uploads are not supported
+		}
+
+		normalizedData[field.Name] = field.PrepareValue(data[field.Name])
+	}
+
+	// client-supplied id
+	if id := cast.ToString(data[schema.ReservedFieldNameId]); id != "" {
+		if err := checkClientRecordId(txDao, form.collection, id); err != nil {
+			return newRecordsBatchCreateItemError(index, err)
+		}
+		record.Id = id
+		record.MarkAsNew()
+	}
+
+	validator := validators.NewRecordDataValidator(txDao, record, nil)
+	if err := validator.Validate(normalizedData); err != nil {
+		return newRecordsBatchCreateItemError(index, err)
+	}
+
+	if err := record.Load(normalizedData); err != nil {
+		return newRecordsBatchCreateItemError(index, err)
+	}
+
+	if err := saveNewRecord(form.app, txDao, record); err != nil {
+		return newRecordsBatchCreateItemError(index, err)
+	}
+
+	if checkFunc != nil {
+		if err := checkFunc(txDao, record); err != nil {
+			return newRecordsBatchCreateItemError(index, err)
+		}
+	}
+
+	return &RecordsBatchCreateItemResult{
+		Index:   index,
+		Success: true,
+		Record:  record,
+	}
+}
+
+// createOrder returns the batch records indexes sorted so that the records
+// referenced by their client-supplied id (via the collection self relation
+// fields) are created before the records that reference them.
+//
+// Records with circular references keep their submitted order.
+func (form *RecordsBatchCreate) createOrder() []int {
+	ids := map[string]int{}
+	for i, data := range form.Records {
+		if id := cast.ToString(data[schema.ReservedFieldNameId]); id != "" {
+			ids[id] = i
+		}
+	}
+
+	deps := make([][]int, len(form.Records))
+
+	if len(ids) > 0 {
+		for _, field := range form.collection.Schema.Fields() {
+			if field.Type != schema.FieldTypeRelation {
+				continue
+			}
+
+			field.InitOptions()
+			options, _ := field.Options.(*schema.RelationOptions)
+			if options == nil || options.CollectionId != form.collection.Id {
+				continue
+			}
+
+			for i, data := range form.Records {
+				for _, id := range list.ToUniqueStringSlice(data[field.Name]) {
+					if j, ok := ids[id]; ok && j != i {
+						deps[i] = append(deps[i], j)
+					}
+				}
+			}
+		}
+	}
+
+	// depth-first topological sort
+	// (0 - not visited, 1 - in progress, 2 - done)
+	order := make([]int, 0, len(form.Records))
+	states := make([]int, len(form.Records))
+
+	var visit func(i int)
+	visit = func(i int) {
+		if states[i] != 0 {
+			return // already added or circular reference
+		}
+
+		states[i] = 1
+		for _, j := range deps[i] {
+			visit(j)
+		}
+		states[i] = 2
+
+		order = append(order, i)
+	}
+
+	for i := range form.Records {
+		visit(i)
+	}
+
+	return order
+}
+
+func (r *RecordsBatchCreateResult) countFailed() int {
+	var total int
+
+	for _, item := range r.Items {
+		if item != nil && !item.Success {
+			total++
+		}
+	}
+
+	return total
+}
+
+func newRecordsBatchCreateItemError(index int, err error) *RecordsBatchCreateItemResult {
+	apiErr, ok := err.(*rest.ApiError)
+	if !ok {
+		if errors.Is(err, ErrRecordIdConflict) {
+			apiErr = rest.NewApiError(http.StatusConflict, err.Error(), nil)
+		} else {
+			apiErr = rest.NewBadRequestError("Failed to create record.", err)
+		}
+	}
+
+	return &RecordsBatchCreateItemResult{
+		Index: index,
+		Error: apiErr,
+	}
+}
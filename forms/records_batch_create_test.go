@@ -0,0 +1,226 @@
+package forms_test
+
+import (
+	"errors"
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestRecordsBatchCreateValidate(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, _ := app.Dao().FindCollectionByNameOrId("demo")
+
+	tooMany := make([]map[string]any, forms.RecordsBatchMaxRecords+1)
+
+	scenarios := []struct {
+		name           string
+		mode           string
+		records        []map[string]any
+		expectedErrors []string
+	}{
+		{"empty", "", nil, []string{"mode", "records"}},
+		{"invalid mode", "invalid", []map[string]any{{"title": "abc"}}, []string{"mode"}},
+		{"too many records", "atomic", tooMany, []string{"records"}},
+		{"valid atomic", "atomic", []map[string]any{{"title": "abc"}}, nil},
+		{"valid partial", "partial", []map[string]any{{"title": "abc"}}, nil},
+	}
+
+	for _, s := range scenarios {
+		form := forms.NewRecordsBatchCreate(app, collection)
+		form.Mode = s.mode
+		form.Records = s.records
+
+		err := form.Validate()
+
+		errs, ok := err.(validation.Errors)
+		if !ok && err != nil {
+			t.Errorf("[%s] Failed to parse errors %v", s.name, err)
+			continue
+		}
+
+		if len(errs) != len(s.expectedErrors) {
+			t.Errorf("[%s] Expected error keys %v, got %v", s.name, s.expectedErrors, errs)
+		}
+		for _, k := range s.expectedErrors {
+			if _, ok := errs[k]; !ok {
+				t.Errorf("[%s] Missing expected error key %q in %v", s.name, k, errs)
+			}
+		}
+	}
+}
+
+func TestRecordsBatchCreateSubmit(t *testing.T) {
+	records := []map[string]any{
+		{"title": "batch1"},
+		{"title": "a"}, // min 3 chars
+		{"title": "batch2"},
+	}
+
+	scenarios := []struct {
+		name            string
+		mode            string
+		records         []map[string]any
+		checkFunc       func(txDao *daos.Dao, record *models.Record) error
+		expectedCreated int
+		expectedFailed  []int
+		expectedTitles  []string
+	}{
+		{"atomic with failed record", "atomic", records, nil, 0, []int{0, 1, 2}, nil},
+		{"partial with failed record", "partial", records, nil, 2, []int{1}, []string{"batch1", "batch2"}},
+		{
+			"atomic without errors",
+			"atomic",
+			[]map[string]any{{"title": "batch1"}, {"title": "batch2"}},
+			nil,
+			2,
+			nil,
+			[]string{"batch1", "batch2"},
+		},
+		{
+			"partial with failed check func",
+			"partial",
+			[]map[string]any{{"title": "batch1"}, {"title": "batch2"}},
+			func(txDao *daos.Dao, record *models.Record) error {
+				if record.GetStringDataValue("title") == "batch1" {
+					return errors.New("test")
+				}
+				return nil
+			},
+			1,
+			[]int{0},
+			[]string{"batch2"},
+		},
+	}
+
+	for _, s := range scenarios {
+		app, _ := tests.NewTestApp()
+
+		collection, _ := app.Dao().FindCollectionByNameOrId("demo")
+
+		totalBefore, _ := app.Dao().FindRecordsByExpr(collection, dbx.NewExp("1=1"))
+
+		form := forms.NewRecordsBatchCreate(app, collection)
+		form.Mode = s.mode
+		form.Records = s.records
+
+		result, err := form.Submit(s.checkFunc)
+		if err != nil {
+			t.Errorf("[%s] Unexpected error %v", s.name, err)
+			app.Cleanup()
+			continue
+		}
+
+		if result.Created != s.expectedCreated {
+			t.Errorf("[%s] Expected %d created records, got %d", s.name, s.expectedCreated, result.Created)
+		}
+
+		if result.Failed != len(s.expectedFailed) {
+			t.Errorf("[%s] Expected %d failed records, got %d", s.name, len(s.expectedFailed), result.Failed)
+		}
+
+		if len(result.Items) != len(s.records) {
+			t.Errorf("[%s] Expected %d items, got %d", s.name, len(s.records), len(result.Items))
+		}
+
+		for i, item := range result.Items {
+			shouldFail := false
+			for _, index := range s.expectedFailed {
+				if index == i {
+					shouldFail = true
+					break
+				}
+			}
+
+			if item.Index != i {
+				t.Errorf("[%s] Expected item index %d, got %d", s.name, i, item.Index)
+			}
+
+			if item.Success == shouldFail || (item.Error != nil) != shouldFail || (item.Record != nil) == shouldFail {
+				t.Errorf("[%s] Expected item %d failure %v, got %v", s.name, i, shouldFail, item)
+			}
+		}
+
+		// check the persisted records
+		totalAfter, _ := app.Dao().FindRecordsByExpr(collection, dbx.NewExp("1=1"))
+		if len(totalAfter) != len(totalBefore)+len(s.expectedTitles) {
+			t.Errorf("[%s] Expected %d total records, got %d", s.name, len(totalBefore)+len(s.expectedTitles), len(totalAfter))
+		}
+		for _, title := range s.expectedTitles {
+			if _, err := app.Dao().FindFirstRecordByData(collection, "title", title); err != nil {
+				t.Errorf("[%s] Missing created record %q", s.name, title)
+			}
+		}
+
+		app.Cleanup()
+	}
+}
+
+func TestRecordsBatchCreateSubmitClientIds(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, _ := app.Dao().FindCollectionByNameOrId("demo4")
+
+	form := forms.NewRecordsBatchCreate(app, collection)
+	form.Records = []map[string]any{
+		// references not yet created batch records
+		{"id": "batch_a", "title": "a_title", "onerel": "batch_b", "manyrels": []any{"batch_b", "batch_c"}},
+		{"id": "batch_b", "title": "b_title", "onerel": "batch_c"},
+		{"id": "batch_c", "title": "c_title"},
+	}
+
+	result, err := form.Submit(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Created != 3 || result.Failed != 0 {
+		t.Fatalf("Expected 3 created records, got %d (failed %d): %v", result.Created, result.Failed, result.Items)
+	}
+
+	for i, item := range result.Items {
+		if item.Index != i || item.Record == nil || item.Record.Id != form.Records[i]["id"] {
+			t.Fatalf("Expected item %d to be the record with id %v, got %v", i, form.Records[i]["id"], item)
+		}
+	}
+
+	recordA, err := app.Dao().FindRecordById(collection, "batch_a", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := recordA.GetStringDataValue("onerel"); v != "batch_b" {
+		t.Fatalf("Expected onerel batch_b, got %q", v)
+	}
+	if v := recordA.GetStringSliceDataValue("manyrels"); len(v) != 2 {
+		t.Fatalf("Expected 2 manyrels, got %v", v)
+	}
+
+	// submit the same records again
+	form2 := forms.NewRecordsBatchCreate(app, collection)
+	form2.Mode = forms.RecordsBatchModePartial
+	form2.Records = []map[string]any{
+		{"id": "batch_a", "title": "a_title"},
+		{"id": "batch_d", "title": "d_title"},
+	}
+
+	result2, err := form2.Submit(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result2.Created != 1 || result2.Items[1].Error != nil {
+		t.Fatalf("Expected only the new record to be created, got %v", result2.Items)
+	}
+
+	if result2.Items[0].Error == nil || result2.Items[0].Error.Code != 409 {
+		t.Fatalf("Expected 409 conflict error, got %v", result2.Items[0].Error)
+	}
+}
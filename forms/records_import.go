@@ -0,0 +1,782 @@
+package forms
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/forms/validators"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/rest"
+	"github.com/spf13/cast"
+)
+
+const (
+	RecordsImportFormatCsv  = "csv"
+	RecordsImportFormatJson = "json"
+
+	// RecordsImportModeContinue commits the valid rows and skips the failed ones.
+	RecordsImportModeContinue = "continue"
+
+	// RecordsImportModeAtomic imports all rows or none of them.
+	RecordsImportModeAtomic = "atomic"
+
+	// RecordsImportDefaultBatchSize is the default number of rows
+	// persisted in a single transaction (for the continue mode).
+	RecordsImportDefaultBatchSize = 500
+
+	// RecordsImportMaxReportedErrors is the max number of row errors
+	// stored in the import result (the Failed counter is not limited).
+	RecordsImportMaxReportedErrors = 1000
+)
+
+// errImportAborted is used internally to rollback the atomic import transaction.
+var errImportAborted = errors.New("The import was aborted due to failed rows.")
+
+// RecordsImport defines a records bulk import form.
+type RecordsImport struct {
+	dao        *daos.Dao
+	collection *models.Collection
+
+	Format string `form:"format" json:"format"`
+	Mode   string `form:"mode" json:"mode"`
+
+	// BatchSize is the number of rows persisted in a single transaction
+	// (ignored for the atomic mode).
+	BatchSize int `form:"batchSize" json:"batchSize"`
+
+	// Mapping is an optional source column/key to collection field name map.
+	// Source columns without mapping are matched by their name.
+	Mapping map[string]string `form:"mapping" json:"mapping"`
+
+	// KeyField is an optional (unique) collection field with the
+	// rows external key.
+	//
+	// If set, the rows are upserted by their key - the existing records
+	// with changed data are updated and the rest are created.
+	KeyField string `form:"keyField" json:"keyField"`
+
+	// Sync deletes the collection records whose KeyField value is missing
+	// from the source (requires KeyField).
+	//
+	// The records are deleted only if the source was fully read without
+	// failed rows (a failed row may not have a readable key, so its record
+	// could be wrongly treated as absent). With enabled collection
+	// archive option the deleted records are also archived, so that they
+	// could be restored later.
+	Sync bool `form:"sync" json:"sync"`
+
+	// RelationKeys is an optional relation field name to related
+	// collection key field name map.
+	//
+	// The source values of the listed relation fields are resolved as
+	// keys of the related records (the records created earlier within
+	// the same import are also resolved).
+	RelationKeys map[string]string `form:"relationKeys" json:"relationKeys"`
+}
+
+// RecordsImportResult defines the records import report.
+type RecordsImportResult struct {
+	Processed int `json:"processed"`
+	Imported  int `json:"imported"`
+	Failed    int `json:"failed"`
+
+	// Created, Updated and Deleted are the number of the created,
+	// changed and (in sync mode) deleted records.
+	//
+	// The imported rows without data changes are counted only as Imported.
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Deleted int `json:"deleted"`
+
+	Errors []*RecordsImportRowError `json:"errors"`
+}
+
+// RecordsImportRowError defines a single failed import row report.
+type RecordsImportRowError struct {
+	// Row is the source row number (starting from 1, excluding the csv header).
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+	Data    any    `json:"data"`
+}
+
+// NewRecordsImport creates a new records import form for the provided collection.
+func NewRecordsImport(app core.App, collection *models.Collection) *RecordsImport {
+	return NewRecordsImportWithDao(app.Dao(), collection)
+}
+
+// NewRecordsImportWithDao creates a new records import form for the
+// provided collection that persists the records with the provided dao
+// (eg. in a migration with `daos.New(db)`).
+func NewRecordsImportWithDao(dao *daos.Dao, collection *models.Collection) *RecordsImport {
+	return &RecordsImport{
+		dao:          dao,
+		collection:   collection,
+		Mode:         RecordsImportModeContinue,
+		BatchSize:    RecordsImportDefaultBatchSize,
+		Mapping:      map[string]string{},
+		RelationKeys: map[string]string{},
+	}
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *RecordsImport) Validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(
+			&form.Format,
+			validation.Required,
+			validation.In(RecordsImportFormatCsv, RecordsImportFormatJson),
+		),
+		validation.Field(
+			&form.Mode,
+			validation.Required,
+			validation.In(RecordsImportModeContinue, RecordsImportModeAtomic),
+		),
+		validation.Field(&form.BatchSize, validation.Min(1), validation.Max(10000)),
+		validation.Field(&form.Mapping, validation.By(form.checkMapping)),
+		validation.Field(&form.KeyField, validation.By(form.checkKeyField)),
+		validation.Field(&form.Sync, validation.When(form.KeyField == "", validation.Empty.Error("Sync requires a key field."))),
+		validation.Field(&form.RelationKeys, validation.By(form.checkRelationKeys)),
+	)
+}
+
+func (form *RecordsImport) checkMapping(value any) error {
+	v, _ := value.(map[string]string)
+
+	for column, fieldName := range v {
+		field := form.collection.Schema.GetFieldByName(fieldName)
+		if field == nil {
+			return validation.NewError(
+				"validation_invalid_mapping_field",
+				fmt.Sprintf("Column %q is mapped to missing field %q.", column, fieldName),
+			)
+		}
+
+		if field.Type == schema.FieldTypeFile {
+			return validation.NewError(
+				"validation_invalid_mapping_field",
+				fmt.Sprintf("Column %q is mapped to file field %q (file fields are not importable).", column, fieldName),
+			)
+		}
+	}
+
+	return nil
+}
+
+func (form *RecordsImport) checkKeyField(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil
+	}
+
+	field := form.collection.Schema.GetFieldByName(v)
+	if field == nil {
+		return validation.NewError("validation_invalid_key_field", fmt.Sprintf("Missing key field %q.", v))
+	}
+
+	switch field.Type {
+	case schema.FieldTypeFile, schema.FieldTypeRelation, schema.FieldTypeUser, schema.FieldTypeJson:
+		return validation.NewError("validation_invalid_key_field", fmt.Sprintf("Field %q cannot be used as key.", v))
+	}
+
+	return nil
+}
+
+func (form *RecordsImport) checkRelationKeys(value any) error {
+	v, _ := value.(map[string]string)
+
+	for fieldName, keyField := range v {
+		field := form.collection.Schema.GetFieldByName(fieldName)
+		if field == nil || field.Type != schema.FieldTypeRelation {
+			return validation.NewError(
+				"validation_invalid_relation_key",
+				fmt.Sprintf("%q is not a relation field.", fieldName),
+			)
+		}
+
+		relCollection, err := form.relatedCollection(field)
+		if err != nil || relCollection.Schema.GetFieldByName(keyField) == nil {
+			return validation.NewError(
+				"validation_invalid_relation_key",
+				fmt.Sprintf("Missing %q related collection key field %q.", fieldName, keyField),
+			)
+		}
+	}
+
+	return nil
+}
+
+// Submit validates the form and imports the rows streamed from source.
+//
+// onProgress is an optional callback that is invoked with the current
+// import state after each processed batch.
+//
+// Row errors don't fail the submit - they are reported in the
+// returned result (for the atomic mode, a single row error rollbacks
+// the entire import and the result Imported counter is reset to 0).
+func (form *RecordsImport) Submit(source io.Reader, onProgress func(result *RecordsImportResult)) (*RecordsImportResult, error) {
+	if err := form.Validate(); err != nil {
+		return nil, err
+	}
+
+	var rows importRowsReader
+	if form.Format == RecordsImportFormatCsv {
+		rows = newCsvImportRows(source)
+	} else {
+		rows = newJsonImportRows(source)
+	}
+
+	result := &RecordsImportResult{Errors: []*RecordsImportRowError{}}
+
+	// the source keys (used to find the absent records in sync mode)
+	keys := map[string]struct{}{}
+
+	if form.Mode == RecordsImportModeAtomic {
+		err := form.dao.RunInTransaction(func(txDao *daos.Dao) error {
+			if _, err := form.importBatch(txDao, rows, -1, keys, result, onProgress); err != nil {
+				return err
+			}
+
+			if result.Failed > 0 {
+				return errImportAborted
+			}
+
+			if form.Sync {
+				return form.deleteAbsentRecords(txDao, keys, result)
+			}
+
+			return nil
+		})
+
+		if errors.Is(err, errImportAborted) {
+			result.Imported = 0
+			result.Created = 0
+			result.Updated = 0
+			return result, nil
+		}
+
+		return result, err
+	}
+
+	for {
+		var done bool
+		var sourceErr error
+
+		err := form.dao.RunInTransaction(func(txDao *daos.Dao) error {
+			// the already imported batch rows are committed even on source error
+			done, sourceErr = form.importBatch(txDao, rows, form.BatchSize, keys, result, nil)
+			return nil
+		})
+		if err != nil {
+			return result, err
+		}
+
+		if onProgress != nil {
+			onProgress(result)
+		}
+
+		if sourceErr != nil {
+			return result, sourceErr
+		}
+
+		if done {
+			break
+		}
+	}
+
+	// skip the deletion since the keys of the failed rows may be missing
+	if form.Sync && result.Failed == 0 {
+		var lastId string
+		for {
+			var done bool
+
+			err := form.dao.RunInTransaction(func(txDao *daos.Dao) error {
+				var deleteErr error
+				lastId, done, deleteErr = form.deleteAbsentRecordsBatch(txDao, keys, lastId, form.BatchSize, result)
+				return deleteErr
+			})
+			if err != nil {
+				return result, err
+			}
+
+			if done {
+				break
+			}
+		}
+
+		if onProgress != nil {
+			onProgress(result)
+		}
+	}
+
+	return result, nil
+}
+
+// deleteAbsentRecords deletes all collection records
+// whose key is not in the provided source keys.
+func (form *RecordsImport) deleteAbsentRecords(txDao *daos.Dao, keys map[string]struct{}, result *RecordsImportResult) error {
+	_, _, err := form.deleteAbsentRecordsBatch(txDao, keys, "", -1, result)
+
+	return err
+}
+
+// deleteAbsentRecordsBatch deletes up to limit (-1 for no limit) absent
+// records with id greater than lastId.
+//
+// It returns the id of the last checked record and reports whether
+// there are no more records to check.
+func (form *RecordsImport) deleteAbsentRecordsBatch(
+	txDao *daos.Dao,
+	keys map[string]struct{},
+	lastId string,
+	limit int,
+	result *RecordsImportResult,
+) (string, bool, error) {
+	var deleted int
+
+	for {
+		query := txDao.RecordQuery(form.collection).
+			AndWhere(dbx.NewExp("[["+form.collection.Name+".id]] > {:lastId}", dbx.Params{"lastId": lastId})).
+			OrderBy(form.collection.Name + ".id ASC").
+			Limit(RecordsImportDefaultBatchSize)
+
+		rows := []dbx.NullStringMap{}
+		if err := query.All(&rows); err != nil {
+			return lastId, false, err
+		}
+
+		for _, record := range models.NewRecordsFromNullStringMaps(form.collection, rows) {
+			lastId = record.Id
+
+			if _, ok := keys[cast.ToString(record.GetDataValue(form.KeyField))]; ok {
+				continue
+			}
+
+			if err := txDao.DeleteRecord(record); err != nil {
+				return lastId, false, fmt.Errorf("Failed to delete absent record %q: %w", record.Id, err)
+			}
+
+			result.Deleted++
+			deleted++
+
+			if limit > 0 && deleted >= limit {
+				return lastId, false, nil
+			}
+		}
+
+		if len(rows) < RecordsImportDefaultBatchSize {
+			return lastId, true, nil
+		}
+	}
+}
+
+// importBatch imports up to limit rows (-1 for no limit)
+// and reports whether the source rows were exhausted.
+//
+// The returned error is not nil only on malformed source.
+//
+// onProgress (if set) is invoked after every RecordsImportDefaultBatchSize rows.
+func (form *RecordsImport) importBatch(
+	txDao *daos.Dao,
+	rows importRowsReader,
+	limit int,
+	keys map[string]struct{},
+	result *RecordsImportResult,
+	onProgress func(result *RecordsImportResult),
+) (bool, error) {
+	for i := 0; limit < 0 || i < limit; i++ {
+		row, err := rows.Next()
+		if err == io.EOF {
+			return true, nil
+		}
+
+		// stop on malformed source
+		var sourceErr *importSourceError
+		if errors.As(err, &sourceErr) {
+			return true, sourceErr.err
+		}
+
+		result.Processed++
+
+		var status int
+		if err == nil {
+			status, err = form.importRow(txDao, row, keys)
+		}
+
+		if err != nil {
+			result.Failed++
+			if len(result.Errors) < RecordsImportMaxReportedErrors {
+				result.Errors = append(result.Errors, newRecordsImportRowError(result.Processed, err))
+			}
+		} else {
+			result.Imported++
+			switch status {
+			case importRowCreated:
+				result.Created++
+			case importRowUpdated:
+				result.Updated++
+			}
+		}
+
+		if onProgress != nil && result.Processed%RecordsImportDefaultBatchSize == 0 {
+			onProgress(result)
+		}
+	}
+
+	return false, nil
+}
+
+// the importRow result statuses
+const (
+	importRowUnchanged = iota
+	importRowCreated
+	importRowUpdated
+)
+
+// importRow creates or (if KeyField is set) updates the record of the
+// provided row and returns its import status.
+//
+// The row key (if any) is added to keys even if the import fails,
+// so that its existing record is not deleted in sync mode.
+func (form *RecordsImport) importRow(txDao *daos.Dao, row map[string]any, keys map[string]struct{}) (int, error) {
+	data := map[string]any{}
+	for column, value := range row {
+		fieldName := column
+		if mapped, ok := form.Mapping[column]; ok {
+			fieldName = mapped
+		}
+
+		field := form.collection.Schema.GetFieldByName(fieldName)
+		if field == nil || field.Type == schema.FieldTypeFile {
+			continue // skip unknown and not importable columns
+		}
+
+		data[field.Name] = field.PrepareValue(value)
+	}
+
+	if len(data) == 0 {
+		return 0, errors.New("The row doesn't have any importable columns.")
+	}
+
+	record := models.NewRecord(form.collection)
+
+	if form.KeyField != "" {
+		key := cast.ToString(data[form.KeyField])
+		if key == "" {
+			return 0, fmt.Errorf("Missing row %q key.", form.KeyField)
+		}
+
+		keys[key] = struct{}{}
+	}
+
+	// resolve the relation keys before merging the existing record ids
+	if err := form.resolveRelationKeys(txDao, data); err != nil {
+		return 0, err
+	}
+
+	if form.KeyField != "" {
+		existing, err := form.findRecordByKey(txDao, form.collection, form.KeyField, data[form.KeyField])
+		if err != nil {
+			return 0, err
+		}
+
+		if existing != nil {
+			record = existing
+
+			// keep the existing values of the fields missing from the row
+			for _, field := range form.collection.Schema.Fields() {
+				if _, ok := data[field.Name]; !ok {
+					data[field.Name] = record.GetDataValue(field.Name)
+				}
+			}
+		}
+	}
+
+	validator := validators.NewRecordDataValidator(txDao, record, nil)
+	if err := validator.Validate(data); err != nil {
+		return 0, err
+	}
+
+	status := importRowCreated
+	if !record.IsNew() {
+		status = importRowUnchanged
+		for key, value := range data {
+			if !importValuesEqual(record.GetDataValue(key), value) {
+				status = importRowUpdated
+				break
+			}
+		}
+
+		if status == importRowUnchanged {
+			return status, nil
+		}
+	}
+
+	if err := record.Load(data); err != nil {
+		return 0, err
+	}
+
+	return status, txDao.SaveRecord(record)
+}
+
+// findRecordByKey returns the collection record with the provided
+// key field value (or nil if there is no such record).
+func (form *RecordsImport) findRecordByKey(txDao *daos.Dao, collection *models.Collection, keyField string, key any) (*models.Record, error) {
+	rows := []dbx.NullStringMap{}
+
+	err := txDao.RecordQuery(collection).
+		AndWhere(dbx.HashExp{collection.Name + "." + keyField: key}).
+		Limit(2).
+		All(&rows)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(rows) {
+	case 0:
+		return nil, nil
+	case 1:
+		return models.NewRecordFromNullStringMap(collection, rows[0]), nil
+	default:
+		return nil, fmt.Errorf("Found more than one %q record with %s %v.", collection.Name, keyField, key)
+	}
+}
+
+// resolveRelationKeys replaces the RelationKeys fields data
+// values with the ids of the related records.
+func (form *RecordsImport) resolveRelationKeys(txDao *daos.Dao, data map[string]any) error {
+	for fieldName, keyField := range form.RelationKeys {
+		value, ok := data[fieldName]
+		if !ok {
+			continue
+		}
+
+		field := form.collection.Schema.GetFieldByName(fieldName)
+
+		relCollection, err := form.relatedCollection(field)
+		if err != nil {
+			return err
+		}
+
+		ids := []string{}
+		for _, key := range list.ToUniqueStringSlice(value) {
+			related, err := form.findRecordByKey(txDao, relCollection, keyField, relCollection.Schema.GetFieldByName(keyField).PrepareValue(key))
+			if err != nil {
+				return err
+			}
+			if related == nil {
+				return validation.Errors{fieldName: validation.NewError(
+					"validation_missing_related_key",
+					fmt.Sprintf("Missing related record with %s %q.", keyField, key),
+				)}
+			}
+			ids = append(ids, related.Id)
+		}
+
+		data[fieldName] = field.PrepareValue(ids)
+	}
+
+	return nil
+}
+
+// relatedCollection returns the collection of the provided relation field.
+func (form *RecordsImport) relatedCollection(field *schema.SchemaField) (*models.Collection, error) {
+	field.InitOptions()
+	options, _ := field.Options.(*schema.RelationOptions)
+	if options == nil {
+		return nil, fmt.Errorf("Missing %q relation options.", field.Name)
+	}
+
+	if options.CollectionId == form.collection.Id {
+		return form.collection, nil
+	}
+
+	return form.dao.FindCollectionByNameOrId(options.CollectionId)
+}
+
+// importValuesEqual compares the serialized values to normalize
+// the different representations of the same value (eg. dates).
+func importValuesEqual(a, b any) bool {
+	rawA, _ := json.Marshal(a)
+	rawB, _ := json.Marshal(b)
+
+	return string(rawA) == string(rawB)
+}
+
+func newRecordsImportRowError(row int, err error) *RecordsImportRowError {
+	rowErr := &RecordsImportRowError{
+		Row:     row,
+		Message: "Failed to import the row.",
+		Data:    map[string]any{},
+	}
+
+	if fieldsErr, ok := err.(validation.Errors); ok {
+		rowErr.Message = "Failed to validate the row data."
+		rowErr.Data = rest.NewBadRequestError("", fieldsErr).Data
+	} else {
+		rowErr.Message = err.Error()
+	}
+
+	return rowErr
+}
+
+// -------------------------------------------------------------------
+
+// importSourceError wraps the errors caused by a malformed import source.
+type importSourceError struct {
+	err error
+}
+
+func (e *importSourceError) Error() string {
+	return e.err.Error()
+}
+
+// importRowsReader defines a streamed import rows source.
+type importRowsReader interface {
+	// Next returns the next source row or io.EOF if there are no more rows.
+	Next() (map[string]any, error)
+}
+
+type csvImportRows struct {
+	reader  *csv.Reader
+	columns []string
+}
+
+func newCsvImportRows(source io.Reader) *csvImportRows {
+	reader := csv.NewReader(source)
+	reader.FieldsPerRecord = -1 // allow missing trailing columns
+	reader.ReuseRecord = true
+
+	return &csvImportRows{reader: reader}
+}
+
+func (r *csvImportRows) Next() (map[string]any, error) {
+	if r.columns == nil {
+		header, err := r.reader.Read()
+		if err == io.EOF {
+			return nil, err
+		}
+		if err != nil {
+			return nil, &importSourceError{fmt.Errorf("Failed to read the csv header: %w", err)}
+		}
+		r.columns = append([]string{}, header...)
+	}
+
+	values, err := r.reader.Read()
+	if err == io.EOF {
+		return nil, err
+	}
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			// malformed row - report it and continue with the next line
+			return nil, err
+		}
+		return nil, &importSourceError{err}
+	}
+
+	row := make(map[string]any, len(r.columns))
+	for i, column := range r.columns {
+		if i < len(values) {
+			row[column] = values[i]
+		}
+	}
+
+	return row, nil
+}
+
+// jsonImportRows reads a json array of objects
+// or a stream of json objects (aka. ndjson).
+type jsonImportRows struct {
+	decoder *json.Decoder
+	started bool
+	isArray bool
+}
+
+func newJsonImportRows(source io.Reader) *jsonImportRows {
+	decoder := json.NewDecoder(source)
+	decoder.UseNumber()
+
+	return &jsonImportRows{decoder: decoder}
+}
+
+func (r *jsonImportRows) Next() (map[string]any, error) {
+	if !r.started {
+		r.started = true
+
+		token, err := r.decoder.Token()
+		if err == io.EOF {
+			return nil, err
+		}
+		if err != nil {
+			return nil, &importSourceError{fmt.Errorf("Failed to read the json source: %w", err)}
+		}
+
+		switch token {
+		case json.Delim('['):
+			r.isArray = true
+		case json.Delim('{'):
+			// a stream of objects - decode the already started object
+			return r.decodeRemainingObject()
+		default:
+			return nil, &importSourceError{errors.New("The json source must be an array or a stream of objects.")}
+		}
+	}
+
+	if r.isArray && !r.decoder.More() {
+		return nil, io.EOF
+	}
+
+	row := map[string]any{}
+	if err := r.decoder.Decode(&row); err != nil {
+		if err == io.EOF && !r.isArray {
+			return nil, io.EOF
+		}
+
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, errors.New("The row must be a json object.")
+		}
+
+		return nil, &importSourceError{fmt.Errorf("Failed to read the json source: %w", err)}
+	}
+
+	return row, nil
+}
+
+// decodeRemainingObject decodes the key-value pairs of an object
+// whose opening delimiter was already consumed.
+func (r *jsonImportRows) decodeRemainingObject() (map[string]any, error) {
+	row := map[string]any{}
+
+	for r.decoder.More() {
+		keyToken, err := r.decoder.Token()
+		if err != nil {
+			return nil, &importSourceError{err}
+		}
+
+		key, _ := keyToken.(string)
+
+		var value any
+		if err := r.decoder.Decode(&value); err != nil {
+			return nil, &importSourceError{err}
+		}
+
+		row[key] = value
+	}
+
+	// consume the closing delimiter
+	if _, err := r.decoder.Token(); err != nil {
+		return nil, &importSourceError{err}
+	}
+
+	return row, nil
+}
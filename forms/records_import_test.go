@@ -0,0 +1,385 @@
+package forms_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestRecordsImportValidate(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, _ := app.Dao().FindCollectionByNameOrId("demo2")
+
+	scenarios := []struct {
+		name           string
+		format         string
+		mode           string
+		batchSize      int
+		mapping        map[string]string
+		expectedErrors []string
+	}{
+		{"empty", "", "", 0, nil, []string{"format", "mode"}},
+		{"invalid values", "xml", "all", -1, nil, []string{"format", "mode", "batchSize"}},
+		{"missing mapping field", "csv", "continue", 10, map[string]string{"a": "missing"}, []string{"mapping"}},
+		{"file mapping field", "csv", "continue", 10, map[string]string{"a": "file"}, []string{"mapping"}},
+		{"valid", "json", "atomic", 10, map[string]string{"a": "text"}, nil},
+	}
+
+	for _, s := range scenarios {
+		form := forms.NewRecordsImport(app, collection)
+		form.Format = s.format
+		form.Mode = s.mode
+		form.BatchSize = s.batchSize
+		form.Mapping = s.mapping
+
+		err := form.Validate()
+
+		errs, ok := err.(validation.Errors)
+		if !ok && err != nil {
+			t.Errorf("[%s] Failed to parse errors %v", s.name, err)
+			continue
+		}
+
+		if len(errs) != len(s.expectedErrors) {
+			t.Errorf("[%s] Expected error keys %v, got %v", s.name, s.expectedErrors, errs)
+		}
+		for _, k := range s.expectedErrors {
+			if _, ok := errs[k]; !ok {
+				t.Errorf("[%s] Missing expected error key %q in %v", s.name, k, errs)
+			}
+		}
+	}
+}
+
+func TestRecordsImportSubmit(t *testing.T) {
+	csvSource := strings.Join([]string{
+		`name,number,bool,select,onerel,rel_cascade,unknown`,
+		`imp1,5,true,"[""a"",""b""]",848a1dea-5ddd-42d6-a00d-030547bffcfe,848a1dea-5ddd-42d6-a00d-030547bffcfe,x`,
+		`x,2,false,c,848a1dea-5ddd-42d6-a00d-030547bffcfe,848a1dea-5ddd-42d6-a00d-030547bffcfe,x`,
+		`imp2,2,false,c,848a1dea-5ddd-42d6-a00d-030547bffcfe,848a1dea-5ddd-42d6-a00d-030547bffcfe`,
+	}, "\n")
+
+	jsonSource := `[
+		{"name":"imp1","number":5,"bool":true,"select":["a","b"],"onerel":"848a1dea-5ddd-42d6-a00d-030547bffcfe","rel_cascade":"848a1dea-5ddd-42d6-a00d-030547bffcfe"},
+		{"name":"x","onerel":"848a1dea-5ddd-42d6-a00d-030547bffcfe","rel_cascade":"848a1dea-5ddd-42d6-a00d-030547bffcfe"},
+		123,
+		{"name":"imp2","number":"2","select":"c","onerel":"848a1dea-5ddd-42d6-a00d-030547bffcfe","rel_cascade":"848a1dea-5ddd-42d6-a00d-030547bffcfe"}
+	]`
+
+	ndjsonSource := `{"name":"imp1","number":5,"bool":true,"select":["a","b"],"onerel":"848a1dea-5ddd-42d6-a00d-030547bffcfe","rel_cascade":"848a1dea-5ddd-42d6-a00d-030547bffcfe"}
+		{"name":"imp2","number":2,"select":"c","onerel":"848a1dea-5ddd-42d6-a00d-030547bffcfe","rel_cascade":"848a1dea-5ddd-42d6-a00d-030547bffcfe"}`
+
+	scenarios := []struct {
+		name               string
+		format             string
+		mode               string
+		batchSize          int
+		source             string
+		expectError        bool
+		expectedProcessed  int
+		expectedImported   int
+		expectedFailedRows []int
+		expectedProgress   int
+		expectedTexts      []string
+	}{
+		{"csv continue", "csv", "continue", 10, csvSource, false, 3, 2, []int{2}, 1, []string{"imp1", "imp2"}},
+		{"csv continue with batches", "csv", "continue", 1, csvSource, false, 3, 2, []int{2}, 4, []string{"imp1", "imp2"}},
+		{"csv atomic", "csv", "atomic", 10, csvSource, false, 3, 0, []int{2}, 0, nil},
+		{"csv empty", "csv", "continue", 10, "", false, 0, 0, nil, 1, nil},
+		{"json array continue", "json", "continue", 10, jsonSource, false, 4, 2, []int{2, 3}, 1, []string{"imp1", "imp2"}},
+		{"json stream continue", "json", "continue", 10, ndjsonSource, false, 2, 2, nil, 1, []string{"imp1", "imp2"}},
+		{"json atomic without errors", "json", "atomic", 10, ndjsonSource, false, 2, 2, nil, 0, []string{"imp1", "imp2"}},
+		{"json malformed source", "json", "continue", 10, `"invalid"`, true, 0, 0, nil, 0, nil},
+	}
+
+	for _, s := range scenarios {
+		app, _ := tests.NewTestApp()
+
+		collection, _ := app.Dao().FindCollectionByNameOrId("demo2")
+
+		form := forms.NewRecordsImport(app, collection)
+		form.Format = s.format
+		form.Mode = s.mode
+		form.BatchSize = s.batchSize
+		form.Mapping = map[string]string{"name": "text"}
+
+		progressCalls := 0
+		result, err := form.Submit(strings.NewReader(s.source), func(r *forms.RecordsImportResult) {
+			progressCalls++
+		})
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("[%s] Expected hasErr %v, got %v (%v)", s.name, s.expectError, hasErr, err)
+			app.Cleanup()
+			continue
+		}
+
+		if hasErr {
+			app.Cleanup()
+			continue
+		}
+
+		if result.Processed != s.expectedProcessed {
+			t.Errorf("[%s] Expected %d processed rows, got %d", s.name, s.expectedProcessed, result.Processed)
+		}
+
+		if result.Imported != s.expectedImported {
+			t.Errorf("[%s] Expected %d imported rows, got %d", s.name, s.expectedImported, result.Imported)
+		}
+
+		if result.Failed != len(s.expectedFailedRows) || len(result.Errors) != len(s.expectedFailedRows) {
+			raw, _ := json.Marshal(result.Errors)
+			t.Errorf("[%s] Expected failed rows %v, got %d (%s)", s.name, s.expectedFailedRows, result.Failed, raw)
+		} else {
+			for i, row := range s.expectedFailedRows {
+				if result.Errors[i].Row != row {
+					t.Errorf("[%s] Expected failed row %d, got %d", s.name, row, result.Errors[i].Row)
+				}
+			}
+		}
+
+		if progressCalls != s.expectedProgress {
+			t.Errorf("[%s] Expected %d progress calls, got %d", s.name, s.expectedProgress, progressCalls)
+		}
+
+		// check the persisted records
+		records, err := app.Dao().FindRecordsByExpr(collection, dbx.NewExp("1=1"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 2+len(s.expectedTexts) {
+			t.Errorf("[%s] Expected %d total records, got %d", s.name, 2+len(s.expectedTexts), len(records))
+		}
+		for _, text := range s.expectedTexts {
+			record, err := app.Dao().FindFirstRecordByData(collection, "text", text)
+			if err != nil {
+				t.Errorf("[%s] Missing imported record %q", s.name, text)
+				continue
+			}
+
+			if text == "imp1" {
+				if v := record.GetFloatDataValue("number"); v != 5 {
+					t.Errorf("[%s] Expected number 5, got %v", s.name, v)
+				}
+				if v := record.GetBoolDataValue("bool"); !v {
+					t.Errorf("[%s] Expected bool true, got %v", s.name, v)
+				}
+				if v := record.GetStringSliceDataValue("select"); len(v) != 2 {
+					t.Errorf("[%s] Expected 2 select values, got %v", s.name, v)
+				}
+			}
+		}
+
+		app.Cleanup()
+	}
+}
+
+func TestRecordsImportValidateKeys(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, _ := app.Dao().FindCollectionByNameOrId("demo2")
+
+	scenarios := []struct {
+		name           string
+		keyField       string
+		sync           bool
+		relationKeys   map[string]string
+		expectedErrors []string
+	}{
+		{"sync without key field", "", true, nil, []string{"sync"}},
+		{"missing key field", "missing", false, nil, []string{"keyField"}},
+		{"file key field", "file", false, nil, []string{"keyField"}},
+		{"relation key field", "onerel", false, nil, []string{"keyField"}},
+		{"non relation key field", "text", false, map[string]string{"text": "title"}, []string{"relationKeys"}},
+		{"missing related key field", "text", false, map[string]string{"onerel": "missing"}, []string{"relationKeys"}},
+		{"valid", "text", true, map[string]string{"onerel": "title"}, nil},
+	}
+
+	for _, s := range scenarios {
+		form := forms.NewRecordsImport(app, collection)
+		form.Format = forms.RecordsImportFormatJson
+		form.KeyField = s.keyField
+		form.Sync = s.sync
+		form.RelationKeys = s.relationKeys
+
+		err := form.Validate()
+
+		errs, ok := err.(validation.Errors)
+		if !ok && err != nil {
+			t.Errorf("[%s] Failed to parse errors %v", s.name, err)
+			continue
+		}
+
+		if len(errs) != len(s.expectedErrors) {
+			t.Errorf("[%s] Expected error keys %v, got %v", s.name, s.expectedErrors, errs)
+		}
+		for _, k := range s.expectedErrors {
+			if _, ok := errs[k]; !ok {
+				t.Errorf("[%s] Missing expected error key %q in %v", s.name, k, errs)
+			}
+		}
+	}
+}
+
+func TestRecordsImportSubmitWithKeys(t *testing.T) {
+	source := `[
+		{"extId":"a","title":"A"},
+		{"extId":"b","title":"B2"},
+		{"extId":"d","title":"D","parent":"a"},
+		{"extId":"e","title":"E","parent":"missing"},
+		{"extId":"c","title":"C2","parent":"missing"}
+	]`
+
+	syncSource := `{"extId":"a","title":"A"}
+		{"extId":"b","title":"B2","parent":"a"}`
+
+	scenarios := []struct {
+		name            string
+		mode            string
+		sync            bool
+		source          string
+		expectedResult  string
+		expectedRecords map[string]string
+	}{
+		{
+			"continue",
+			forms.RecordsImportModeContinue,
+			false,
+			source,
+			`"processed":5,"imported":3,"failed":2,"created":1,"updated":1,"deleted":0`,
+			map[string]string{"a": "A", "b": "B2", "c": "C", "d": "D"},
+		},
+		{
+			"continue with sync and failed rows",
+			forms.RecordsImportModeContinue,
+			true,
+			source,
+			`"processed":5,"imported":3,"failed":2,"created":1,"updated":1,"deleted":0`,
+			map[string]string{"a": "A", "b": "B2", "c": "C", "d": "D"},
+		},
+		{
+			"continue with sync and failed row without key",
+			forms.RecordsImportModeContinue,
+			true,
+			syncSource + `
+		{"unknown":"c"}`,
+			`"processed":3,"imported":2,"failed":1,"created":0,"updated":1,"deleted":0`,
+			map[string]string{"a": "A", "b": "B2", "c": "C"},
+		},
+		{
+			"continue with sync",
+			forms.RecordsImportModeContinue,
+			true,
+			syncSource,
+			`"processed":2,"imported":2,"failed":0,"created":0,"updated":1,"deleted":1`,
+			map[string]string{"a": "A", "b": "B2"},
+		},
+		{
+			"atomic with sync",
+			forms.RecordsImportModeAtomic,
+			true,
+			syncSource,
+			`"processed":2,"imported":2,"failed":0,"created":0,"updated":1,"deleted":1`,
+			map[string]string{"a": "A", "b": "B2"},
+		},
+		{
+			"atomic with sync and failed rows",
+			forms.RecordsImportModeAtomic,
+			true,
+			source,
+			`"processed":5,"imported":0,"failed":2,"created":0,"updated":0,"deleted":0`,
+			map[string]string{"a": "A", "b": "B", "c": "C"},
+		},
+	}
+
+	for _, s := range scenarios {
+		func() {
+			app, _ := tests.NewTestApp()
+			defer app.Cleanup()
+
+			collection := &models.Collection{
+				Name: "sync_items",
+				Schema: schema.NewSchema(
+					&schema.SchemaField{Name: "extId", Type: schema.FieldTypeText, Unique: true},
+					&schema.SchemaField{Name: "title", Type: schema.FieldTypeText},
+				),
+			}
+			if err := app.Dao().SaveCollection(collection); err != nil {
+				t.Fatal(err)
+			}
+
+			// self-reference
+			collection.Schema.AddField(&schema.SchemaField{
+				Name:    "parent",
+				Type:    schema.FieldTypeRelation,
+				Options: &schema.RelationOptions{MaxSelect: 1, CollectionId: collection.Id},
+			})
+			if err := app.Dao().SaveCollection(collection); err != nil {
+				t.Fatal(err)
+			}
+
+			for key, title := range map[string]string{"a": "A", "b": "B", "c": "C"} {
+				record := models.NewRecord(collection)
+				record.SetDataValue("extId", key)
+				record.SetDataValue("title", title)
+				if err := app.Dao().SaveRecord(record); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			form := forms.NewRecordsImportWithDao(app.Dao(), collection)
+			form.Format = forms.RecordsImportFormatJson
+			form.Mode = s.mode
+			form.KeyField = "extId"
+			form.Sync = s.sync
+			form.RelationKeys = map[string]string{"parent": "extId"}
+
+			result, err := form.Submit(strings.NewReader(s.source), nil)
+			if err != nil {
+				t.Fatalf("[%s] %v", s.name, err)
+			}
+
+			raw, _ := json.Marshal(result)
+			if !strings.Contains(string(raw), s.expectedResult) {
+				t.Fatalf("[%s] Expected result %s, got %s", s.name, s.expectedResult, raw)
+			}
+
+			records, err := app.Dao().FindRecordsByExpr(collection, dbx.NewExp("1=1"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			titles := map[string]string{}
+			for _, r := range records {
+				titles[r.GetStringDataValue("extId")] = r.GetStringDataValue("title")
+			}
+			if len(titles) != len(s.expectedRecords) {
+				t.Fatalf("[%s] Expected records %v, got %v", s.name, s.expectedRecords, titles)
+			}
+			for key, title := range s.expectedRecords {
+				if titles[key] != title {
+					t.Fatalf("[%s] Expected records %v, got %v", s.name, s.expectedRecords, titles)
+				}
+			}
+
+			// the relation key should be resolved to the related record id
+			if d, _ := app.Dao().FindFirstRecordByData(collection, "extId", "d"); d != nil {
+				a, _ := app.Dao().FindFirstRecordByData(collection, "extId", "a")
+				if d.GetStringDataValue("parent") != a.Id {
+					t.Fatalf("[%s] Expected parent %q, got %q", s.name, a.Id, d.GetStringDataValue("parent"))
+				}
+			}
+		}()
+	}
+}
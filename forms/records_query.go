@@ -0,0 +1,121 @@
+package forms
+
+import (
+	"regexp"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+var queryFieldRegex = regexp.MustCompile(`^\@?\w+$`)
+
+// RecordsQuerySelection defines the fields and the nested relations
+// that should be returned for the selected records.
+//
+// Empty Fields means that all record fields will be returned.
+type RecordsQuerySelection struct {
+	Fields []string                          `form:"fields" json:"fields"`
+	Expand map[string]*RecordsQuerySelection `form:"expand" json:"expand"`
+}
+
+// Validate makes RecordsQuerySelection validatable by implementing [validation.Validatable] interface.
+func (s *RecordsQuerySelection) Validate() error {
+	if s == nil {
+		return nil // all fields
+	}
+
+	return validation.ValidateStruct(s,
+		validation.Field(&s.Fields, validation.Each(validation.Match(queryFieldRegex))),
+		validation.Field(&s.Expand, validation.By(checkQueryExpand)),
+	)
+}
+
+// ExpandPaths returns the flattened relation expand paths
+// of the current selection (eg. ["a", "a.b", "c"]).
+func (s *RecordsQuerySelection) ExpandPaths() []string {
+	result := []string{}
+
+	for name, sub := range s.Expand {
+		result = append(result, name)
+
+		if sub != nil {
+			for _, path := range sub.ExpandPaths() {
+				result = append(result, name+"."+path)
+			}
+		}
+	}
+
+	return result
+}
+
+// depth returns the max nested expand depth of the current selection.
+func (s *RecordsQuerySelection) depth() int {
+	result := 0
+
+	for _, sub := range s.Expand {
+		subDepth := 1
+		if sub != nil {
+			subDepth += sub.depth()
+		}
+
+		if subDepth > result {
+			result = subDepth
+		}
+	}
+
+	return result
+}
+
+func checkQueryExpand(value any) error {
+	v, _ := value.(map[string]*RecordsQuerySelection)
+
+	for name := range v {
+		if !queryFieldRegex.MatchString(name) || name[0] == '@' {
+			return validation.NewError("validation_invalid_expand_field", "Invalid expand relation field name.")
+		}
+	}
+
+	return nil
+}
+
+// RecordsQuery defines a declarative records query form
+// (collection records search + fields and relations selection).
+type RecordsQuery struct {
+	RecordsQuerySelection
+
+	Collection string `form:"collection" json:"collection"`
+	Filter     string `form:"filter" json:"filter"`
+	Sort       string `form:"sort" json:"sort"`
+	Page       int    `form:"page" json:"page"`
+	PerPage    int    `form:"perPage" json:"perPage"`
+}
+
+// NewRecordsQuery creates new RecordsQuery request form.
+func NewRecordsQuery() *RecordsQuery {
+	return &RecordsQuery{}
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *RecordsQuery) Validate() error {
+	if err := validation.ValidateStruct(form,
+		validation.Field(&form.Collection, validation.Required, validation.Length(1, 255)),
+		validation.Field(&form.Page, validation.Min(0)),
+		validation.Field(&form.PerPage, validation.Min(0), validation.Max(search.MaxPerPage)),
+	); err != nil {
+		return err
+	}
+
+	if err := form.RecordsQuerySelection.Validate(); err != nil {
+		return err
+	}
+
+	if form.depth() > daos.MaxExpandDepth {
+		return validation.Errors{"expand": validation.NewError(
+			"validation_max_expand_depth",
+			"The nested expand depth limit is reached.",
+		)}
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package forms_test
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/tools/list"
+)
+
+func TestRecordsQueryValidate(t *testing.T) {
+	scenarios := []struct {
+		jsonData       string
+		expectedErrors []string
+	}{
+		// empty data
+		{
+			`{}`,
+			[]string{"collection"},
+		},
+		// invalid base fields
+		{
+			`{"collection":"demo","page":-1,"perPage":1000}`,
+			[]string{"page", "perPage"},
+		},
+		// invalid field names
+		{
+			`{"collection":"demo","fields":["id","invalid.name"]}`,
+			[]string{"fields"},
+		},
+		// invalid expand names
+		{
+			`{"collection":"demo","expand":{"@invalid":{}}}`,
+			[]string{"expand"},
+		},
+		// invalid nested selection
+		{
+			`{"collection":"demo","expand":{"a":{"fields":["a b"]}}}`,
+			[]string{"expand"},
+		},
+		// max depth limit
+		{
+			`{"collection":"demo","expand":{"a":{"expand":{"a":{"expand":{"a":{"expand":{"a":{"expand":{"a":{"expand":{"a":{"expand":{"a":null}}}}}}}}}}}}}}`,
+			[]string{"expand"},
+		},
+		// valid data
+		{
+			`{"collection":"demo","filter":"id!=''","sort":"-created","page":2,"perPage":10,"fields":["id","@collectionName"],"expand":{"a":{"fields":["title"],"expand":{"b":null}}}}`,
+			[]string{},
+		},
+	}
+
+	for i, s := range scenarios {
+		form := forms.NewRecordsQuery()
+
+		if err := json.Unmarshal([]byte(s.jsonData), form); err != nil {
+			t.Errorf("(%d) Failed to load form data: %v", i, err)
+			continue
+		}
+
+		result := form.Validate()
+
+		// parse errors
+		errs := map[string]any{}
+		if result != nil {
+			encoded, _ := json.Marshal(result)
+			json.Unmarshal(encoded, &errs)
+		}
+
+		// check errors
+		if len(errs) > len(s.expectedErrors) {
+			t.Errorf("(%d) Expected error keys %v, got %v", i, s.expectedErrors, errs)
+		}
+		for _, k := range s.expectedErrors {
+			if _, ok := errs[k]; !ok {
+				t.Errorf("(%d) Missing expected error key %q in %v", i, k, errs)
+			}
+		}
+	}
+}
+
+func TestRecordsQuerySelectionExpandPaths(t *testing.T) {
+	form := forms.NewRecordsQuery()
+
+	err := json.Unmarshal([]byte(`{"expand":{"a":{"expand":{"b":{"expand":{"c":{}}},"d":null}},"e":null}}`), form)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := form.ExpandPaths()
+	sort.Strings(result)
+
+	expected := []string{"a", "a.b", "a.b.c", "a.d", "e"}
+
+	if len(result) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, result)
+	}
+
+	for _, path := range expected {
+		if !list.ExistInSlice(path, result) {
+			t.Fatalf("Missing path %q in %v", path, strings.Join(result, ","))
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package forms
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// SettingsTokenKeyRotate defines a token signing key rotation form.
+type SettingsTokenKeyRotate struct {
+	app core.App
+
+	// Token is the name of the rotated token config (eg. "userAuthToken").
+	Token string `form:"token" json:"token"`
+}
+
+// NewSettingsTokenKeyRotate creates new token signing key rotation form
+// for the provided app.
+func NewSettingsTokenKeyRotate(app core.App) *SettingsTokenKeyRotate {
+	return &SettingsTokenKeyRotate{app: app}
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *SettingsTokenKeyRotate) Validate() error {
+	names := []any{}
+	for name := range form.app.Settings().TokenConfigs() {
+		names = append(names, name)
+	}
+
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Token, validation.Required, validation.In(names...)),
+	)
+}
+
+// Submit validates the form, generates a new signing key for the
+// selected token config and persists the updated app settings.
+//
+// The previous keys remain valid for verification until removed.
+// On success returns the id of the new signing key.
+func (form *SettingsTokenKeyRotate) Submit() (string, error) {
+	if err := form.Validate(); err != nil {
+		return "", err
+	}
+
+	upsert := NewSettingsUpsert(form.app)
+
+	keyId := upsert.Settings.TokenConfigs()[form.Token].RotateKey()
+
+	if err := upsert.Submit(); err != nil {
+		return "", err
+	}
+
+	return keyId, nil
+}
@@ -0,0 +1,74 @@
+package forms_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestSettingsTokenKeyRotateValidate(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		token       string
+		expectError bool
+	}{
+		{"", true},
+		{"missing", true},
+		{"Secret", true},
+		{"adminAuthToken", false},
+		{"userVerificationToken", false},
+	}
+
+	for i, s := range scenarios {
+		form := forms.NewSettingsTokenKeyRotate(app)
+		form.Token = s.token
+
+		err := form.Validate()
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr to be %v, got %v (%v)", i, s.expectError, hasErr, err)
+		}
+	}
+}
+
+func TestSettingsTokenKeyRotateSubmit(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	form := forms.NewSettingsTokenKeyRotate(app)
+	form.Token = "userAuthToken"
+
+	keyId, err := form.Submit()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := app.Settings().UserAuthToken
+	if keyId == "" || config.SigningKeyId != keyId {
+		t.Fatalf("Expected signing key id %q, got %q", keyId, config.SigningKeyId)
+	}
+
+	if signingId, _ := config.SigningKey(); signingId != keyId {
+		t.Fatalf("Expected the new key to be used for signing, got %q", signingId)
+	}
+
+	// the other token configs shouldn't be changed
+	if app.Settings().AdminAuthToken.SigningKeyId != "" {
+		t.Fatalf("Expected the admin auth token signing key to be unchanged, got %q", app.Settings().AdminAuthToken.SigningKeyId)
+	}
+
+	// check whether the settings were persisted
+	stored, err := app.Dao().FindParamByKey(models.ParamAppSettings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(stored.Value), keyId) {
+		t.Fatalf("Expected the new key to be persisted, got %s", stored.Value)
+	}
+}
@@ -80,7 +80,7 @@ func (form *UserEmailChangeConfirm) parseToken(token string) (*models.User, stri
 	// verify that the token is not expired and its signiture is valid
 	user, err := form.app.Dao().FindUserByToken(
 		token,
-		form.app.Settings().UserEmailChangeToken.Secret,
+		form.app.Settings().UserEmailChangeToken.VerificationSecret(token),
 	)
 	if err != nil || user == nil {
 		return nil, "", validation.NewError("validation_invalid_token", "Invalid or expired token.")
@@ -3,6 +3,7 @@ package forms
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
@@ -15,7 +16,8 @@ import (
 
 // UserOauth2Login defines a user Oauth2 login form.
 type UserOauth2Login struct {
-	app core.App
+	app        core.App
+	loggedUser *models.User
 
 	// The name of the OAuth2 client provider (eg. "google")
 	Provider string `form:"provider" json:"provider"`
@@ -31,8 +33,17 @@ type UserOauth2Login struct {
 }
 
 // NewUserOauth2Login creates a new user Oauth2 login form.
-func NewUserOauth2Login(app core.App) *UserOauth2Login {
-	return &UserOauth2Login{app: app}
+//
+// If `optLoggedUser` is provided, the authorized OAuth2 identity
+// will be linked to it (aka. account linking).
+func NewUserOauth2Login(app core.App, optLoggedUser ...*models.User) *UserOauth2Login {
+	form := &UserOauth2Login{app: app}
+
+	if len(optLoggedUser) > 0 {
+		form.loggedUser = optLoggedUser[0]
+	}
+
+	return form
 }
 
 // Validate makes the form validatable by implementing [validation.Validatable] interface.
@@ -88,11 +99,38 @@ func (form *UserOauth2Login) Submit() (*models.User, *auth.AuthUser, error) {
 		return nil, nil, err
 	}
 
+	// check for already linked identity
+	var extAuth *models.ExternalAuth
+	if authData.Id != "" {
+		extAuth, _ = form.app.Dao().FindExternalAuthByProvider(form.Provider, authData.Id)
+	}
+
+	var user *models.User
+
+	switch {
+	case form.loggedUser != nil:
+		// link to the current logged user
+		if extAuth != nil && extAuth.UserId != form.loggedUser.Id {
+			return nil, authData, errors.New("The OAuth2 identity is already linked to another user.")
+		}
+		user = form.loggedUser
+	case extAuth != nil:
+		user, err = form.app.Dao().FindUserById(extAuth.UserId)
+		if err != nil {
+			return nil, authData, err
+		}
+	default:
+		user, _ = form.app.Dao().FindUserByEmail(authData.Email)
+		if user != nil && config.RequireLinkConfirmation {
+			return nil, authData, errors.New("A user with the same email already exists. Authenticate with the existing account first to link the OAuth2 identity.")
+		}
+	}
+
 	// login/register the auth user
-	user, _ := form.app.Dao().FindUserByEmail(authData.Email)
 	if user != nil {
 		// update the existing user's verified state
-		if !user.Verified {
+		// (only if the provider's email is the same as the user's one)
+		if !user.Verified && strings.EqualFold(user.Email, authData.Email) {
 			user.Verified = true
 			if err := form.app.Dao().SaveUser(user); err != nil {
 				return nil, authData, err
@@ -129,5 +167,21 @@ func (form *UserOauth2Login) Submit() (*models.User, *auth.AuthUser, error) {
 		}
 	}
 
+	// link the identity to the user (if not already)
+	if extAuth == nil && authData.Id != "" {
+		if existing, _ := form.app.Dao().FindExternalAuthByUserIdAndProvider(user.Id, form.Provider); existing != nil {
+			return nil, authData, fmt.Errorf("The user is already linked to another %s account.", form.Provider)
+		}
+
+		extAuth = &models.ExternalAuth{
+			UserId:     user.Id,
+			Provider:   form.Provider,
+			ProviderId: authData.Id,
+		}
+		if err := form.app.Dao().SaveExternalAuth(extAuth); err != nil {
+			return nil, authData, err
+		}
+	}
+
 	return user, authData, nil
 }
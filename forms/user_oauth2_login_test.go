@@ -2,10 +2,14 @@ package forms_test
 
 import (
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"testing"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/pocketbase/pocketbase/forms"
+	"github.com/pocketbase/pocketbase/models"
 	"github.com/pocketbase/pocketbase/tests"
 )
 
@@ -72,4 +76,132 @@ func TestUserOauth2LoginValidate(t *testing.T) {
 	}
 }
 
-// @todo consider mocking a Oauth2 provider to test Submit
+func TestUserOauth2LoginSubmitAccountLinking(t *testing.T) {
+	// mock the gitlab provider token and user api endpoints
+	var authUserJson string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/token":
+			w.Write([]byte(`{"access_token":"test","token_type":"Bearer"}`))
+		case "/user":
+			w.Write([]byte(authUserJson))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	scenarios := []struct {
+		name                    string
+		authUser                string
+		loggedUserEmail         string
+		requireLinkConfirmation bool
+		expectError             bool
+		expectedUserEmail       string
+		expectedLinkedUserId    string
+	}{
+		{
+			name:                 "already linked identity",
+			authUser:             `{"id":456,"email":"other@example.com"}`,
+			expectedUserEmail:    "test@example.com",
+			expectedLinkedUserId: "4d0197cc-2b4a-3f83-a26b-d77bc8423d3c",
+		},
+		{
+			name:                 "new identity with existing user email (auto link)",
+			authUser:             `{"id":789,"email":"test3@example.com"}`,
+			expectedUserEmail:    "test3@example.com",
+			expectedLinkedUserId: "97cc3d3d-6ba2-383f-b42a-7bc84d27410c",
+		},
+		{
+			name:                    "new identity with existing user email (require link confirmation)",
+			authUser:                `{"id":789,"email":"test3@example.com"}`,
+			requireLinkConfirmation: true,
+			expectError:             true,
+		},
+		{
+			name:              "new identity with new email (register)",
+			authUser:          `{"id":789,"email":"new@example.com"}`,
+			expectedUserEmail: "new@example.com",
+		},
+		{
+			name:            "logged user + identity linked to another user",
+			authUser:        `{"id":456,"email":"test3@example.com"}`,
+			loggedUserEmail: "test3@example.com",
+			expectError:     true,
+		},
+		{
+			name:                    "logged user + new identity",
+			authUser:                `{"id":789,"email":"other@example.com"}`,
+			loggedUserEmail:         "test3@example.com",
+			requireLinkConfirmation: true,
+			expectedUserEmail:       "test3@example.com",
+			expectedLinkedUserId:    "97cc3d3d-6ba2-383f-b42a-7bc84d27410c",
+		},
+		{
+			name:            "logged user already linked to another identity of the same provider",
+			authUser:        `{"id":789,"email":"test@example.com"}`,
+			loggedUserEmail: "test@example.com",
+			expectError:     true,
+		},
+	}
+
+	for _, s := range scenarios {
+		func() {
+			app, _ := tests.NewTestApp()
+			defer app.Cleanup()
+
+			authUserJson = s.authUser
+
+			app.Settings().GitlabAuth.TokenUrl = server.URL + "/token"
+			app.Settings().GitlabAuth.UserApiUrl = server.URL + "/user"
+			app.Settings().GitlabAuth.RequireLinkConfirmation = s.requireLinkConfirmation
+
+			var loggedUser *models.User
+			if s.loggedUserEmail != "" {
+				loggedUser, _ = app.Dao().FindUserByEmail(s.loggedUserEmail)
+			}
+
+			form := forms.NewUserOauth2Login(app, loggedUser)
+			form.Provider = "gitlab"
+			form.Code = "test"
+			form.CodeVerifier = "test"
+			form.RedirectUrl = "https://example.com"
+
+			user, _, err := form.Submit()
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Errorf("[%s] Expected hasErr %v, got %v (%v)", s.name, s.expectError, hasErr, err)
+				return
+			}
+
+			if hasErr {
+				return
+			}
+
+			if user.Email != s.expectedUserEmail {
+				t.Errorf("[%s] Expected user %q, got %q", s.name, s.expectedUserEmail, user.Email)
+			}
+
+			var rawAuthUser struct {
+				Id int `json:"id"`
+			}
+			json.Unmarshal([]byte(s.authUser), &rawAuthUser)
+
+			extAuth, _ := app.Dao().FindExternalAuthByProvider("gitlab", strconv.Itoa(rawAuthUser.Id))
+			if extAuth == nil {
+				t.Errorf("[%s] Expected the identity to be linked", s.name)
+				return
+			}
+
+			expectedLinkedUserId := s.expectedLinkedUserId
+			if expectedLinkedUserId == "" {
+				expectedLinkedUserId = user.Id
+			}
+			if extAuth.UserId != expectedLinkedUserId {
+				t.Errorf("[%s] Expected the identity to be linked to %q, got %q", s.name, expectedLinkedUserId, extAuth.UserId)
+			}
+		}()
+	}
+}
@@ -42,7 +42,7 @@ func (form *UserPasswordResetConfirm) checkToken(value any) error {
 
 	user, err := form.app.Dao().FindUserByToken(
 		v,
-		form.app.Settings().UserPasswordResetToken.Secret,
+		form.app.Settings().UserPasswordResetToken.VerificationSecret(v),
 	)
 	if err != nil || user == nil {
 		return validation.NewError("validation_invalid_token", "Invalid or expired token.")
@@ -53,6 +53,9 @@ func (form *UserPasswordResetConfirm) checkToken(value any) error {
 
 // Submit validates and submits the form.
 // On success returns the updated user model associated to `form.Token`.
+//
+// Note that the password change also rotates the user token key,
+// aka. all previously issued user tokens are invalidated.
 func (form *UserPasswordResetConfirm) Submit() (*models.User, error) {
 	if err := form.Validate(); err != nil {
 		return nil, err
@@ -60,7 +63,7 @@ func (form *UserPasswordResetConfirm) Submit() (*models.User, error) {
 
 	user, err := form.app.Dao().FindUserByToken(
 		form.Token,
-		form.app.Settings().UserPasswordResetToken.Secret,
+		form.app.Settings().UserPasswordResetToken.VerificationSecret(form.Token),
 	)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,55 @@
+package forms
+
+import (
+	"errors"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// UserTokenVerify defines a user auth token verification form.
+type UserTokenVerify struct {
+	app core.App
+
+	// Token is the user auth token to verify
+	// (the "User " Authorization header prefix is optional).
+	Token string `form:"token" json:"token"`
+}
+
+// NewUserTokenVerify creates a new user auth token verification form.
+func NewUserTokenVerify(app core.App) *UserTokenVerify {
+	return &UserTokenVerify{
+		app: app,
+	}
+}
+
+// Validate makes the form validatable by implementing [validation.Validatable] interface.
+func (form *UserTokenVerify) Validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Token, validation.Required),
+	)
+}
+
+// Submit validates the form and verifies the auth token signature,
+// expiration and the existence of its user.
+//
+// On success returns the user model associated to `form.Token`.
+func (form *UserTokenVerify) Submit() (*models.User, error) {
+	if err := form.Validate(); err != nil {
+		return nil, err
+	}
+
+	token := strings.TrimPrefix(form.Token, "User ")
+
+	user, err := form.app.Dao().FindUserByToken(
+		token,
+		form.app.Settings().UserAuthToken.VerificationSecret(token),
+	)
+	if err != nil || user == nil {
+		return nil, errors.New("Invalid or expired auth token.")
+	}
+
+	return user, nil
+}
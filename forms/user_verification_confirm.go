@@ -35,7 +35,7 @@ func (form *UserVerificationConfirm) checkToken(value any) error {
 
 	user, err := form.app.Dao().FindUserByToken(
 		v,
-		form.app.Settings().UserVerificationToken.Secret,
+		form.app.Settings().UserVerificationToken.VerificationSecret(v),
 	)
 	if err != nil || user == nil {
 		return validation.NewError("validation_invalid_token", "Invalid or expired token.")
@@ -53,7 +53,7 @@ func (form *UserVerificationConfirm) Submit() (*models.User, error) {
 
 	user, err := form.app.Dao().FindUserByToken(
 		form.Token,
-		form.app.Settings().UserVerificationToken.Secret,
+		form.app.Settings().UserVerificationToken.VerificationSecret(form.Token),
 	)
 	if err != nil {
 		return nil, err
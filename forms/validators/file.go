@@ -3,6 +3,7 @@ package validators
 import (
 	"encoding/binary"
 	"fmt"
+	"mime"
 	"net/http"
 	"strings"
 
@@ -33,6 +34,11 @@ func UploadedFileSize(maxBytes int) validation.RuleFunc {
 // UploadedFileMimeType checks whether the validated `rest.UploadedFile`
 // mimetype is within the provided allowed mime types.
 //
+// The file mimetype is sniffed from the file content header bytes
+// (not from its extension). The allowed mime types could be either
+// exact types with or without parameters (eg. "text/plain" or
+// "text/plain; charset=utf-8") or wildcards (eg. "image/*").
+//
 // Example:
 // 	validMimeTypes := []string{"test/plain","image/jpeg"}
 //	validation.Field(&form.File, validation.By(validators.UploadedFileMimeType(validMimeTypes)))
@@ -47,10 +53,10 @@ func UploadedFileMimeType(validTypes []string) validation.RuleFunc {
 			return validation.NewError("validation_invalid_mime_type", "Unsupported file type.")
 		}
 
-		filetype := http.DetectContentType(v.Bytes())
+		filetype := sniffContentType(v.Bytes())
 
 		for _, t := range validTypes {
-			if t == filetype {
+			if matchMimeType(filetype, t) {
 				return nil // valid
 			}
 		}
@@ -61,3 +67,45 @@ func UploadedFileMimeType(validTypes []string) validation.RuleFunc {
 		))
 	}
 }
+
+// sniffLen is the max number of the file header bytes
+// used to detect the file content type.
+const sniffLen = 512
+
+// sniffContentType detects the content type of the provided
+// file data by inspecting only its header bytes.
+func sniffContentType(data []byte) string {
+	if len(data) > sniffLen {
+		data = data[:sniffLen]
+	}
+
+	return http.DetectContentType(data)
+}
+
+// matchMimeType checks whether the detected content type
+// matches the provided allowed mime type pattern.
+func matchMimeType(detected string, pattern string) bool {
+	pattern = strings.TrimSpace(pattern)
+
+	if detected == pattern {
+		return true
+	}
+
+	// the pattern has parameters so it must be an exact match
+	if strings.Contains(pattern, ";") {
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(detected)
+	if err != nil {
+		return false
+	}
+
+	pattern = strings.ToLower(pattern)
+
+	if strings.HasSuffix(pattern, "/*") {
+		return strings.HasPrefix(mediaType, strings.TrimSuffix(pattern, "*"))
+	}
+
+	return mediaType == pattern
+}
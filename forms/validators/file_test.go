@@ -79,6 +79,12 @@ func TestUploadedFileMimeType(t *testing.T) {
 		{[]string{"image/jpeg"}, files[0], true},
 		// test files are detected as "text/plain; charset=utf-8" content type
 		{[]string{"image/jpeg", "text/plain; charset=utf-8"}, files[0], false},
+		// media type without parameters
+		{[]string{"image/jpeg", "text/plain"}, files[0], false},
+		{[]string{"text/plain; charset=iso-8859-1"}, files[0], true},
+		// wildcards
+		{[]string{"text/*"}, files[0], false},
+		{[]string{"image/*"}, files[0], true},
 	}
 
 	for i, s := range scenarios {
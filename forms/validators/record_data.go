@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
@@ -15,6 +16,7 @@ import (
 	"github.com/pocketbase/pocketbase/tools/list"
 	"github.com/pocketbase/pocketbase/tools/rest"
 	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
 )
 
 var requiredErr = validation.NewError("validation_required", "Missing required value")
@@ -75,7 +77,9 @@ func (validator *RecordDataValidator) Validate(data map[string]any) error {
 		value := field.PrepareValue(data[key])
 
 		// check required constraint
-		if field.Required && validation.Required.Validate(value) != nil {
+		// (decimals are serialized as string, so "0.00" is also considered empty
+		// and the empty slugs are auto generated from their source field on submit)
+		if field.Required && field.Type != schema.FieldTypeSlug && (validation.Required.Validate(value) != nil || isZeroDecimal(field, value)) {
 			errs[key] = requiredErr
 			continue
 		}
@@ -129,6 +133,12 @@ func (validator *RecordDataValidator) checkFieldValue(field *schema.SchemaField,
 		return validator.checkRelationValue(field, value)
 	case schema.FieldTypeUser:
 		return validator.checkUserValue(field, value)
+	case schema.FieldTypeLocalized:
+		return validator.checkLocalizedValue(field, value)
+	case schema.FieldTypeSlug:
+		return validator.checkSlugValue(field, value)
+	case schema.FieldTypeDecimal:
+		return validator.checkDecimalFieldValue(field, value)
 	}
 
 	return nil
@@ -160,14 +170,54 @@ func (validator *RecordDataValidator) checkTextValue(field *schema.SchemaField,
 	return nil
 }
 
+func (validator *RecordDataValidator) checkLocalizedValue(field *schema.SchemaField, value any) error {
+	options, _ := field.Options.(*schema.LocalizedOptions)
+
+	val, _ := value.(types.JsonMap)
+
+	// the default locale value is required for the required fields
+	if field.Required && cast.ToString(val[options.DefaultLocale]) == "" {
+		return validation.Errors{options.DefaultLocale: requiredErr}
+	}
+
+	textField := &schema.SchemaField{
+		Type: schema.FieldTypeText,
+		Options: &schema.TextOptions{
+			Min:     options.Min,
+			Max:     options.Max,
+			Pattern: options.Pattern,
+		},
+	}
+
+	errs := validation.Errors{}
+
+	for locale, v := range val {
+		if !options.HasLocale(locale) {
+			errs[locale] = validation.NewError("validation_unknown_locale", "Unknown locale")
+			continue
+		}
+
+		if err := validator.checkTextValue(textField, v); err != nil {
+			errs[locale] = err
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
 func (validator *RecordDataValidator) checkNumberValue(field *schema.SchemaField, value any) error {
 	if value == nil {
 		return nil // nothing to check
 	}
 
-	val, _ := value.(float64)
 	options, _ := field.Options.(*schema.NumberOptions)
 
+	val, _ := value.(float64)
+
 	if options.Min != nil && val < *options.Min {
 		return validation.NewError("validation_min_number_constraint", fmt.Sprintf("Must be larger than %f", *options.Min))
 	}
@@ -179,6 +229,45 @@ func (validator *RecordDataValidator) checkNumberValue(field *schema.SchemaField
 	return nil
 }
 
+func isZeroDecimal(field *schema.SchemaField, value any) bool {
+	if field.Type != schema.FieldTypeDecimal {
+		return false
+	}
+
+	val, ok := schema.ParseDecimal(value)
+
+	return ok && val.Sign() == 0
+}
+
+// checkDecimalFieldValue validates the range and the min/max constraints of a decimal field value.
+func (validator *RecordDataValidator) checkDecimalFieldValue(field *schema.SchemaField, value any) error {
+	if value == nil {
+		return nil // nothing to check
+	}
+
+	options, _ := field.Options.(*schema.DecimalOptions)
+
+	if _, err := options.Scaled(value); err != nil {
+		return validation.NewError("validation_invalid_decimal", err.Error())
+	}
+
+	val, _ := options.Round(value)
+
+	if options.Min != nil {
+		if min, _ := schema.ParseDecimal(*options.Min); val.Cmp(min) < 0 {
+			return validation.NewError("validation_min_number_constraint", fmt.Sprintf("Must be larger than %s", min.FloatString(options.Scale)))
+		}
+	}
+
+	if options.Max != nil {
+		if max, _ := schema.ParseDecimal(*options.Max); val.Cmp(max) > 0 {
+			return validation.NewError("validation_max_number_constraint", fmt.Sprintf("Must be less than %s", max.FloatString(options.Scale)))
+		}
+	}
+
+	return nil
+}
+
 func (validator *RecordDataValidator) checkBoolValue(field *schema.SchemaField, value any) error {
 	return nil
 }
@@ -209,6 +298,29 @@ func (validator *RecordDataValidator) checkEmailValue(field *schema.SchemaField,
 	return nil
 }
 
+func (validator *RecordDataValidator) checkSlugValue(field *schema.SchemaField, value any) error {
+	val, _ := value.(string)
+	if val == "" {
+		return nil // nothing to check (will be auto generated)
+	}
+
+	if !schema.SlugRegex.MatchString(val) {
+		return validation.NewError("validation_invalid_slug", "Must contain only lowercase letters, digits and single dashes")
+	}
+
+	// slugs are always unique
+	if !validator.dao.IsRecordValueUnique(
+		validator.record.Collection(),
+		field.Name,
+		val,
+		validator.record.GetId(),
+	) {
+		return validation.NewError("validation_not_unique", "Value must be unique")
+	}
+
+	return nil
+}
+
 func (validator *RecordDataValidator) checkUrlValue(field *schema.SchemaField, value any) error {
 	val, _ := value.(string)
 	if val == "" {
@@ -301,6 +413,12 @@ func (validator *RecordDataValidator) checkJsonValue(field *schema.SchemaField,
 	return nil
 }
 
+// checkFileValue validates the file field value and its new uploaded files.
+//
+// All failing checks are collected so that the user could fix them at once.
+// The returned error is always either nil or [validation.Errors] (even for
+// a single problem) keyed by the file index in the field value (eg. "2")
+// and "maxSelect" for the files count limit.
 func (validator *RecordDataValidator) checkFileValue(field *schema.SchemaField, value any) error {
 	// normalize value access
 	var names []string
@@ -313,37 +431,65 @@ func (validator *RecordDataValidator) checkFileValue(field *schema.SchemaField,
 
 	options, _ := field.Options.(*schema.FileOptions)
 
+	errs := validation.Errors{}
+
 	if len(names) > options.MaxSelect {
-		return validation.NewError("validation_too_many_values", fmt.Sprintf("Select no more than %d", options.MaxSelect))
+		errs["maxSelect"] = validation.NewError("validation_too_many_values", fmt.Sprintf("Select no more than %d", options.MaxSelect))
 	}
 
-	// extract the uploaded files
-	files := []*rest.UploadedFile{}
+	// check the uploaded files
 	if len(validator.uploadedFiles) > 0 {
-		for _, file := range validator.uploadedFiles {
-			if list.ExistInSlice(file.Name(), names) {
-				files = append(files, file)
+		for i, name := range names {
+			for _, file := range validator.uploadedFiles {
+				if file.Name() != name {
+					continue
+				}
+
+				if err := validator.checkUploadedFile(options, file); err != nil {
+					errs[strconv.Itoa(i)] = err
+				}
+
+				break
 			}
 		}
 	}
 
-	for _, file := range files {
-		// check size
-		if err := UploadedFileSize(options.MaxSize)(file); err != nil {
-			return err
-		}
+	return errs.Filter()
+}
 
-		// check type
-		if len(options.MimeTypes) > 0 {
-			if err := UploadedFileMimeType(options.MimeTypes)(file); err != nil {
-				return err
-			}
+// checkUploadedFile checks the size and type of a single uploaded file.
+func (validator *RecordDataValidator) checkUploadedFile(options *schema.FileOptions, file *rest.UploadedFile) error {
+	// check size
+	if err := UploadedFileSize(options.MaxSize)(file); err != nil {
+		return uploadedFileError(file, err)
+	}
+
+	// check type
+	if len(options.MimeTypes) > 0 {
+		if err := UploadedFileMimeType(options.MimeTypes)(file); err != nil {
+			return uploadedFileError(file, err)
 		}
 	}
 
 	return nil
 }
 
+// uploadedFileError prefixes the provided validation error
+// message with the offending uploaded file original name.
+func uploadedFileError(file *rest.UploadedFile, err error) error {
+	v, ok := err.(validation.Error)
+	if !ok {
+		return err
+	}
+
+	name := file.Name()
+	if file.Header() != nil && file.Header().Filename != "" {
+		name = file.Header().Filename
+	}
+
+	return v.SetMessage(fmt.Sprintf("%s: %s", name, v.Message()))
+}
+
 func (validator *RecordDataValidator) checkRelationValue(field *schema.SchemaField, value any) error {
 	// normalize value access
 	var ids []string
@@ -277,6 +277,105 @@ func TestRecordDataValidatorValidateNumber(t *testing.T) {
 	checkValidatorErrors(t, app.Dao(), models.NewRecord(collection), scenarios)
 }
 
+func TestRecordDataValidatorValidateDecimal(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// create new test collection
+	collection := &models.Collection{}
+	collection.Name = "validate_test"
+	min := 0.01
+	max := 9.99
+	collection.Schema = schema.NewSchema(
+		&schema.SchemaField{
+			Name:     "field1",
+			Required: true,
+			Type:     schema.FieldTypeDecimal,
+			Options:  &schema.DecimalOptions{Scale: 2},
+		},
+		&schema.SchemaField{
+			Name:   "field2",
+			Unique: true,
+			Type:   schema.FieldTypeDecimal,
+			Options: &schema.DecimalOptions{
+				Scale: 2,
+				Min:   &min,
+				Max:   &max,
+			},
+		},
+	)
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	// create dummy record (used for the unique check)
+	dummy := models.NewRecord(collection)
+	dummy.SetDataValue("field1", "1")
+	dummy.SetDataValue("field2", "1.23")
+	if err := app.Dao().SaveRecord(dummy); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []testDataFieldScenario{
+		{
+			"check required constraint",
+			map[string]any{
+				"field1": "0.001",
+				"field2": nil,
+			},
+			nil,
+			[]string{"field1"},
+		},
+		{
+			"check out of range value",
+			map[string]any{
+				"field1": "99999999999999999999",
+				"field2": nil,
+			},
+			nil,
+			[]string{"field1"},
+		},
+		{
+			"check unique constraint (rounded value)",
+			map[string]any{
+				"field1": 1,
+				"field2": "1.2301",
+			},
+			nil,
+			[]string{"field2"},
+		},
+		{
+			"check min constraint",
+			map[string]any{
+				"field1": 1,
+				"field2": "0.004",
+			},
+			nil,
+			[]string{"field2"},
+		},
+		{
+			"check max constraint",
+			map[string]any{
+				"field1": 1,
+				"field2": "9.995",
+			},
+			nil,
+			[]string{"field2"},
+		},
+		{
+			"valid data",
+			map[string]any{
+				"field1": "-0.5",
+				"field2": "9.994",
+			},
+			nil,
+			[]string{},
+		},
+	}
+
+	checkValidatorErrors(t, app.Dao(), models.NewRecord(collection), scenarios)
+}
+
 func TestRecordDataValidatorValidateBool(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -1136,6 +1235,39 @@ func TestRecordDataValidatorValidateFile(t *testing.T) {
 	}
 
 	checkValidatorErrors(t, app.Dao(), models.NewRecord(collection), scenarios)
+
+	// the file errors should name the offending file
+	validator := validators.NewRecordDataValidator(app.Dao(), models.NewRecord(collection), testFiles)
+	err = validator.Validate(map[string]any{
+		"field2": []string{testFiles[0].Name()},
+		"field3": []string{testFiles[1].Name()},
+	})
+	errs, _ := err.(validation.Errors)
+	if errs["field3"] == nil || !strings.Contains(errs["field3"].Error(), testFiles[1].Header().Filename) {
+		t.Fatalf("Expected field3 error naming the %q file, got %v", testFiles[1].Header().Filename, err)
+	}
+
+	// a single file error should have the same nested shape as multiple ones
+	if nestedErrs, _ := errs["field3"].(validation.Errors); len(nestedErrs) != 1 || nestedErrs["0"] == nil {
+		t.Fatalf("Expected field3 nested error with key %q, got %v", "0", errs["field3"])
+	}
+
+	// all file errors of a field should be returned together
+	err = validator.Validate(map[string]any{
+		"field2": []string{testFiles[0].Name()},
+		"field3": []string{"test1", testFiles[1].Name(), testFiles[2].Name(), testFiles[3].Name()},
+	})
+	errs, _ = err.(validation.Errors)
+	nestedErrs, _ := errs["field3"].(validation.Errors)
+	expectedKeys := []string{"maxSelect", "1", "2", "3"}
+	if len(nestedErrs) != len(expectedKeys) {
+		t.Fatalf("Expected field3 nested errors %v, got %v", expectedKeys, errs["field3"])
+	}
+	for _, k := range expectedKeys {
+		if nestedErrs[k] == nil {
+			t.Fatalf("Missing field3 nested error %q in %v", k, nestedErrs)
+		}
+	}
 }
 
 func TestRecordDataValidatorValidateRelation(t *testing.T) {
@@ -1441,3 +1573,184 @@ func checkValidatorErrors(t *testing.T, dao *daos.Dao, record *models.Record, sc
 		}
 	}
 }
+
+func TestRecordDataValidatorValidateLocalized(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	min := 2
+	max := 5
+
+	// create new test collection
+	collection := &models.Collection{}
+	collection.Name = "validate_test"
+	collection.Schema = schema.NewSchema(
+		&schema.SchemaField{
+			Name: "field1",
+			Type: schema.FieldTypeLocalized,
+			Options: &schema.LocalizedOptions{
+				Locales:       []string{"en", "bg"},
+				DefaultLocale: "en",
+			},
+		},
+		&schema.SchemaField{
+			Name:     "field2",
+			Required: true,
+			Type:     schema.FieldTypeLocalized,
+			Options: &schema.LocalizedOptions{
+				Locales:       []string{"en", "bg"},
+				DefaultLocale: "bg",
+			},
+		},
+		&schema.SchemaField{
+			Name: "field3",
+			Type: schema.FieldTypeLocalized,
+			Options: &schema.LocalizedOptions{
+				Locales:       []string{"en", "bg"},
+				DefaultLocale: "en",
+				Min:           &min,
+				Max:           &max,
+				Pattern:       `^\w+$`,
+			},
+		},
+	)
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []testDataFieldScenario{
+		{
+			"check required constraint - nil",
+			map[string]any{
+				"field1": nil,
+				"field2": nil,
+				"field3": nil,
+			},
+			nil,
+			[]string{"field2"},
+		},
+		{
+			"check required constraint - missing default locale value",
+			map[string]any{
+				"field2": map[string]any{"en": "test"},
+			},
+			nil,
+			[]string{"field2"},
+		},
+		{
+			"check unknown locales",
+			map[string]any{
+				"field1": map[string]any{"de": "test"},
+				"field2": map[string]any{"bg": "test"},
+			},
+			nil,
+			[]string{"field1"},
+		},
+		{
+			"check min, max and pattern constraints",
+			map[string]any{
+				"field2": `{"bg":"test"}`,
+				"field3": map[string]any{"en": "a", "bg": "a b"},
+			},
+			nil,
+			[]string{"field3"},
+		},
+		{
+			"valid data - plain string as default locale value",
+			map[string]any{
+				"field2": "test",
+			},
+			nil,
+			[]string{},
+		},
+		{
+			"valid data - all fields",
+			map[string]any{
+				"field1": map[string]any{"en": "test", "bg": ""},
+				"field2": `{"en":"test","bg":"тест"}`,
+				"field3": map[string]any{"en": "test1", "bg": "test2"},
+			},
+			nil,
+			[]string{},
+		},
+	}
+
+	checkValidatorErrors(t, app.Dao(), models.NewRecord(collection), scenarios)
+}
+
+func TestRecordDataValidatorValidateSlug(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	// create new test collection
+	collection := &models.Collection{}
+	collection.Name = "validate_test"
+	collection.Schema = schema.NewSchema(
+		&schema.SchemaField{
+			Name: "title",
+			Type: schema.FieldTypeText,
+		},
+		&schema.SchemaField{
+			Name:     "field1",
+			Required: true,
+			Type:     schema.FieldTypeSlug,
+			Options:  &schema.SlugOptions{Source: "title"},
+		},
+	)
+	if err := app.Dao().SaveCollection(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	// create dummy record (used for the unique check)
+	dummy := models.NewRecord(collection)
+	dummy.SetDataValue("field1", "existing-slug")
+	if err := app.Dao().SaveRecord(dummy); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []testDataFieldScenario{
+		{
+			"empty slug (auto generated)",
+			map[string]any{
+				"title":  "test",
+				"field1": "",
+			},
+			nil,
+			[]string{},
+		},
+		{
+			"invalid slug format",
+			map[string]any{
+				"field1": "Invalid slug",
+			},
+			nil,
+			[]string{"field1"},
+		},
+		{
+			"invalid slug format - duplicated dashes",
+			map[string]any{
+				"field1": "invalid--slug",
+			},
+			nil,
+			[]string{"field1"},
+		},
+		{
+			"duplicated slug",
+			map[string]any{
+				"field1": "existing-slug",
+			},
+			nil,
+			[]string{"field1"},
+		},
+		{
+			"valid slug",
+			map[string]any{
+				"field1": "new-slug-123",
+			},
+			nil,
+			[]string{},
+		},
+	}
+
+	checkValidatorErrors(t, app.Dao(), models.NewRecord(collection), scenarios)
+}
@@ -74,3 +74,63 @@ func SendAdminPasswordReset(app core.App, admin *models.Admin) error {
 
 	return sendErr
 }
+
+// SendAdminMagicLink sends a magic link login email with the
+// provided token to the specified admin.
+func SendAdminMagicLink(app core.App, admin *models.Admin, token string) error {
+	actionUrl, urlErr := normalizeUrl(fmt.Sprintf(
+		"%s/#/confirm-magic-link/%s",
+		strings.TrimSuffix(app.Settings().Meta.AppUrl, "/"),
+		token,
+	))
+	if urlErr != nil {
+		return urlErr
+	}
+
+	params := struct {
+		AppName   string
+		AppUrl    string
+		Admin     *models.Admin
+		Token     string
+		ActionUrl string
+	}{
+		AppName:   app.Settings().Meta.AppName,
+		AppUrl:    app.Settings().Meta.AppUrl,
+		Admin:     admin,
+		Token:     token,
+		ActionUrl: actionUrl,
+	}
+
+	mailClient := app.NewMailClient()
+
+	event := &core.MailerAdminEvent{
+		MailClient: mailClient,
+		Admin:      admin,
+		Meta:       map[string]any{"token": token},
+	}
+
+	sendErr := app.OnMailerBeforeAdminMagicLinkSend().Trigger(event, func(e *core.MailerAdminEvent) error {
+		// resolve body template
+		body, renderErr := resolveTemplateContent(params, templates.Layout, templates.AdminMagicLinkBody)
+		if renderErr != nil {
+			return renderErr
+		}
+
+		return e.MailClient.Send(
+			mail.Address{
+				Name:    app.Settings().Meta.SenderName,
+				Address: app.Settings().Meta.SenderAddress,
+			},
+			mail.Address{Address: e.Admin.Email},
+			"Admin sign in link",
+			body,
+			nil,
+		)
+	})
+
+	if sendErr == nil {
+		app.OnMailerAfterAdminMagicLinkSend().Trigger(event)
+	}
+
+	return sendErr
+}
@@ -35,3 +35,35 @@ func TestSendAdminPasswordReset(t *testing.T) {
 		}
 	}
 }
+
+func TestSendAdminMagicLink(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	// ensure that action url normalization will be applied
+	testApp.Settings().Meta.AppUrl = "http://localhost:8090////"
+
+	admin, _ := testApp.Dao().FindAdminByEmail("test@example.com")
+
+	err := mails.SendAdminMagicLink(testApp, admin, "test_token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if testApp.TestMailer.TotalSend != 1 {
+		t.Fatalf("Expected one email to be sent, got %d", testApp.TestMailer.TotalSend)
+	}
+
+	expectedParts := []string{
+		"http://localhost:8090/#/confirm-magic-link/test_token",
+	}
+	for _, part := range expectedParts {
+		if !strings.Contains(testApp.TestMailer.LastHtmlBody, part) {
+			t.Fatalf("Couldn't find %s \nin\n %s", part, testApp.TestMailer.LastHtmlBody)
+		}
+	}
+
+	if testApp.EventCalls["OnMailerBeforeAdminMagicLinkSend"] != 1 || testApp.EventCalls["OnMailerAfterAdminMagicLinkSend"] != 1 {
+		t.Fatalf("Expected the magic link mailer hooks to be triggered, got %v", testApp.EventCalls)
+	}
+}
@@ -0,0 +1,25 @@
+package templates
+
+// Available variables:
+//
+// ```
+// Admin     *models.Admin
+// AppName   string
+// AppUrl    string
+// Token     string
+// ActionUrl string
+// ```
+const AdminMagicLinkBody = `
+{{define "content"}}
+	<p>Hello,</p>
+
+	<p>Follow this link to sign in to the {{.AppName}} admin dashboard.</p>
+
+	<p>
+		<a class="btn" href="{{.ActionUrl}}">Sign in</a>
+		<a class="fallback-link" href="{{.ActionUrl}}">{{.ActionUrl}}</a>
+	</p>
+
+	<p><i>The link could be used only once. If you did not request it, please ignore this email and the link will expire on its own.</i></p>
+{{end}}
+`
@@ -129,7 +129,35 @@ func init() {
 			),
 		}
 
-		return daos.New(db).SaveCollection(collection)
+		collection.RefreshId()
+		collection.RefreshCreated()
+		collection.RefreshUpdated()
+
+		rawSchema, schemaErr := collection.Schema.MarshalJSON()
+		if schemaErr != nil {
+			return schemaErr
+		}
+
+		// insert only the initial _collections table columns
+		// (the model columns added by the later migrations don't exist yet)
+		_, insertErr := db.Insert(collection.TableName(), dbx.Params{
+			"id":         collection.Id,
+			"system":     collection.System,
+			"name":       collection.Name,
+			"schema":     string(rawSchema),
+			"listRule":   collection.ListRule,
+			"viewRule":   collection.ViewRule,
+			"createRule": collection.CreateRule,
+			"updateRule": collection.UpdateRule,
+			"deleteRule": collection.DeleteRule,
+			"created":    collection.Created,
+			"updated":    collection.Updated,
+		}).Execute()
+		if insertErr != nil {
+			return insertErr
+		}
+
+		return daos.New(db).SyncRecordTableSchema(collection, nil)
 	}, func(db dbx.Builder) error {
 		tables := []string{
 			"_params",
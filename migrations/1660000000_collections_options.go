@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/tools/list"
+)
+
+// Adds the `_collections.options` column.
+func init() {
+	AppMigrations.Register(func(db dbx.Builder) error {
+		columns, err := daos.New(db).GetTableColumns("_collections")
+		if err != nil {
+			return err
+		}
+
+		if list.ExistInSlice("options", columns) {
+			return nil // already exists
+		}
+
+		_, err = db.NewQuery(`ALTER TABLE {{_collections}} ADD COLUMN [[options]] JSON DEFAULT "{}" NOT NULL`).Execute()
+
+		return err
+	}, func(db dbx.Builder) error {
+		_, err := db.NewQuery(`ALTER TABLE {{_collections}} DROP COLUMN [[options]]`).Execute()
+
+		return err
+	})
+}
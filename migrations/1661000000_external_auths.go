@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+)
+
+// Creates the `_externalAuths` table that stores the
+// OAuth2 identities linked to the users (aka. account linking).
+func init() {
+	AppMigrations.Register(func(db dbx.Builder) error {
+		_, err := db.NewQuery(`
+			CREATE TABLE {{_externalAuths}} (
+				[[id]]         TEXT PRIMARY KEY,
+				[[userId]]     TEXT NOT NULL,
+				[[provider]]   TEXT NOT NULL,
+				[[providerId]] TEXT NOT NULL,
+				[[created]]    TEXT DEFAULT "" NOT NULL,
+				[[updated]]    TEXT DEFAULT "" NOT NULL,
+				FOREIGN KEY ([[userId]]) REFERENCES {{_users}} ([[id]]) ON UPDATE CASCADE ON DELETE CASCADE
+			);
+
+			CREATE UNIQUE INDEX _externalAuths_userId_provider_idx on {{_externalAuths}} ([[userId]], [[provider]]);
+			CREATE UNIQUE INDEX _externalAuths_provider_providerId_idx on {{_externalAuths}} ([[provider]], [[providerId]]);
+		`).Execute()
+
+		return err
+	}, func(db dbx.Builder) error {
+		_, err := db.DropTable("_externalAuths").Execute()
+
+		return err
+	})
+}
@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	"github.com/pocketbase/pocketbase/tools/list"
+)
+
+// Adds the `_admins.permissions` column used for the collection scoped
+// admin access (the existing admins remain with full access).
+func init() {
+	AppMigrations.Register(func(db dbx.Builder) error {
+		columns, err := daos.New(db).GetTableColumns("_admins")
+		if err != nil {
+			return err
+		}
+
+		if list.ExistInSlice("permissions", columns) {
+			return nil // already exists
+		}
+
+		_, err = db.NewQuery(`ALTER TABLE {{_admins}} ADD COLUMN [[permissions]] JSON DEFAULT "{}" NOT NULL`).Execute()
+
+		return err
+	}, func(db dbx.Builder) error {
+		_, err := db.NewQuery(`ALTER TABLE {{_admins}} DROP COLUMN [[permissions]]`).Execute()
+
+		return err
+	})
+}
@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+)
+
+// Creates the `_archivedRecords` table that stores the deleted
+// records snapshots of the collections with enabled archive option.
+func init() {
+	AppMigrations.Register(func(db dbx.Builder) error {
+		_, err := db.NewQuery(`
+			CREATE TABLE {{_archivedRecords}} (
+				[[id]]           TEXT PRIMARY KEY,
+				[[collectionId]] TEXT NOT NULL,
+				[[recordId]]     TEXT NOT NULL,
+				[[data]]         JSON DEFAULT "{}" NOT NULL,
+				[[deletedBy]]    TEXT DEFAULT "" NOT NULL,
+				[[created]]      TEXT DEFAULT "" NOT NULL,
+				[[updated]]      TEXT DEFAULT "" NOT NULL
+			);
+
+			CREATE INDEX _archivedRecords_collectionId_created_idx on {{_archivedRecords}} ([[collectionId]], [[created]]);
+			CREATE INDEX _archivedRecords_collectionId_recordId_idx on {{_archivedRecords}} ([[collectionId]], [[recordId]]);
+		`).Execute()
+
+		return err
+	}, func(db dbx.Builder) error {
+		_, err := db.DropTable("_archivedRecords").Execute()
+
+		return err
+	})
+}
@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+)
+
+// Creates the `_recordVersions` table that stores the previous
+// states of the updated records of the collections with enabled
+// versions option.
+func init() {
+	AppMigrations.Register(func(db dbx.Builder) error {
+		_, err := db.NewQuery(`
+			CREATE TABLE {{_recordVersions}} (
+				[[id]]           TEXT PRIMARY KEY,
+				[[collectionId]] TEXT NOT NULL,
+				[[recordId]]     TEXT NOT NULL,
+				[[version]]      INTEGER NOT NULL,
+				[[data]]         JSON DEFAULT "{}" NOT NULL,
+				[[updatedBy]]    TEXT DEFAULT "" NOT NULL,
+				[[created]]      TEXT DEFAULT "" NOT NULL,
+				[[updated]]      TEXT DEFAULT "" NOT NULL
+			);
+
+			CREATE UNIQUE INDEX _recordVersions_collectionId_recordId_version_idx on {{_recordVersions}} ([[collectionId]], [[recordId]], [[version]]);
+		`).Execute()
+
+		return err
+	}, func(db dbx.Builder) error {
+		_, err := db.DropTable("_recordVersions").Execute()
+
+		return err
+	})
+}
@@ -0,0 +1,221 @@
+// Package diff implements a collections migration generator
+// based on the difference between two collections states
+// (eg. a previously saved snapshot and the current db state).
+package diff
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// Diff defines the changes between two collections states.
+type Diff struct {
+	Created []*models.Collection
+	Updated []*CollectionChange
+	Deleted []*models.Collection
+}
+
+// CollectionChange defines the changes of a single existing collection.
+type CollectionChange struct {
+	Old *models.Collection
+	New *models.Collection
+
+	// OptionsChanged indicates whether any of the non schema
+	// collection properties have changed (name, rules, options, etc.).
+	OptionsChanged bool
+
+	AddedFields   []*schema.SchemaField
+	RemovedFields []*schema.SchemaField
+	ChangedFields []*FieldChange
+}
+
+// FieldChange defines a single existing (and possibly renamed) schema field change.
+type FieldChange struct {
+	Old *schema.SchemaField
+	New *schema.SchemaField
+}
+
+// IsEmpty reports whether there are no collection changes.
+func (d *Diff) IsEmpty() bool {
+	return len(d.Created) == 0 && len(d.Updated) == 0 && len(d.Deleted) == 0
+}
+
+// Compare returns the changes needed to transform the oldState
+// collections into the newState ones.
+//
+// Collections and schema fields are matched by their ids.
+// Unmatched fields with the same name are considered the same field.
+//
+// renames is an optional map with field rename hints for the cases when
+// the field ids don't match (eg. the field was deleted and created again).
+// The map key is in the format "collectionName.oldFieldName"
+// (where collectionName is the new collection name) and its value is
+// the new field name, eg. {"posts.name": "title"}.
+func Compare(oldState []*models.Collection, newState []*models.Collection, renames map[string]string) *Diff {
+	result := &Diff{}
+
+	oldCollections := make(map[string]*models.Collection, len(oldState))
+	for _, c := range oldState {
+		oldCollections[c.Id] = c
+	}
+
+	newCollections := make(map[string]*models.Collection, len(newState))
+	for _, c := range newState {
+		newCollections[c.Id] = c
+	}
+
+	for _, newCollection := range newState {
+		oldCollection, ok := oldCollections[newCollection.Id]
+		if !ok {
+			result.Created = append(result.Created, newCollection)
+			continue
+		}
+
+		if change := compareCollections(oldCollection, newCollection, renames); change != nil {
+			result.Updated = append(result.Updated, change)
+		}
+	}
+
+	for _, oldCollection := range oldState {
+		if _, ok := newCollections[oldCollection.Id]; !ok {
+			result.Deleted = append(result.Deleted, oldCollection)
+		}
+	}
+
+	return result
+}
+
+// ReverseRenames returns the rename hints needed to compare the states
+// in reverse order (eg. {"posts.name": "title"} -> {"posts.title": "name"}).
+//
+// oldState and newState are used to resolve the collection name changes.
+func ReverseRenames(renames map[string]string, oldState []*models.Collection, newState []*models.Collection) map[string]string {
+	oldNames := make(map[string]string, len(oldState))
+	for _, c := range oldState {
+		oldNames[c.Id] = c.Name
+	}
+
+	result := make(map[string]string, len(renames))
+
+	for key, newField := range renames {
+		parts := strings.SplitN(key, ".", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		collectionName := parts[0]
+		for _, c := range newState {
+			if c.Name == parts[0] && oldNames[c.Id] != "" {
+				collectionName = oldNames[c.Id]
+				break
+			}
+		}
+
+		result[collectionName+"."+newField] = parts[1]
+	}
+
+	return result
+}
+
+func compareCollections(oldCollection, newCollection *models.Collection, renames map[string]string) *CollectionChange {
+	change := &CollectionChange{
+		Old:            oldCollection,
+		New:            newCollection,
+		OptionsChanged: exportCollectionProps(oldCollection) != exportCollectionProps(newCollection),
+	}
+
+	oldFields := oldCollection.Schema.Fields()
+	newFields := newCollection.Schema.Fields()
+
+	matchedOld := map[string]*schema.SchemaField{} // new field id -> old field
+	usedOld := map[string]bool{}
+
+	// match by id
+	for _, newField := range newFields {
+		if oldField := oldCollection.Schema.GetFieldById(newField.Id); oldField != nil {
+			matchedOld[newField.Id] = oldField
+			usedOld[oldField.Id] = true
+		}
+	}
+
+	// match by name or rename hint
+	for _, newField := range newFields {
+		if matchedOld[newField.Id] != nil {
+			continue
+		}
+
+		for _, oldField := range oldFields {
+			if usedOld[oldField.Id] {
+				continue
+			}
+
+			hint, hasHint := renames[newCollection.Name+"."+oldField.Name]
+			if oldField.Name == newField.Name || (hasHint && hint == newField.Name) {
+				matchedOld[newField.Id] = oldField
+				usedOld[oldField.Id] = true
+				break
+			}
+		}
+	}
+
+	for _, newField := range newFields {
+		oldField := matchedOld[newField.Id]
+		if oldField == nil {
+			change.AddedFields = append(change.AddedFields, newField)
+			continue
+		}
+
+		if oldField.Id != newField.Id || exportField(oldField) != exportField(newField) {
+			change.ChangedFields = append(change.ChangedFields, &FieldChange{Old: oldField, New: newField})
+		}
+	}
+
+	for _, oldField := range oldFields {
+		if !usedOld[oldField.Id] {
+			change.RemovedFields = append(change.RemovedFields, oldField)
+		}
+	}
+
+	if !change.OptionsChanged &&
+		len(change.AddedFields) == 0 &&
+		len(change.RemovedFields) == 0 &&
+		len(change.ChangedFields) == 0 {
+		return nil // no changes
+	}
+
+	return change
+}
+
+// collectionProps returns the non schema collection properties.
+func collectionProps(c *models.Collection) map[string]any {
+	return map[string]any{
+		"name":       c.Name,
+		"system":     c.System,
+		"listRule":   c.ListRule,
+		"viewRule":   c.ViewRule,
+		"createRule": c.CreateRule,
+		"updateRule": c.UpdateRule,
+		"deleteRule": c.DeleteRule,
+		"options":    c.Options,
+	}
+}
+
+// exportCollectionProps exports the non schema collection properties as json.
+func exportCollectionProps(c *models.Collection) string {
+	raw, _ := json.Marshal(collectionProps(c))
+
+	return string(raw)
+}
+
+// exportField exports the schema field as json (without its id).
+func exportField(f *schema.SchemaField) string {
+	clone := *f
+	clone.Id = ""
+
+	raw, _ := json.Marshal(clone)
+
+	return string(raw)
+}
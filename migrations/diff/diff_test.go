@@ -0,0 +1,176 @@
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/migrations/diff"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+func newCollection(id string, name string, fields ...*schema.SchemaField) *models.Collection {
+	c := &models.Collection{Name: name, Schema: schema.NewSchema(fields...)}
+	c.Id = id
+	return c
+}
+
+func newField(id string, name string, fieldType string) *schema.SchemaField {
+	return &schema.SchemaField{Id: id, Name: name, Type: fieldType}
+}
+
+func TestDiffIsEmpty(t *testing.T) {
+	d := &diff.Diff{}
+	if !d.IsEmpty() {
+		t.Fatal("Expected empty diff")
+	}
+
+	d.Deleted = append(d.Deleted, newCollection("c1", "test"))
+	if d.IsEmpty() {
+		t.Fatal("Expected non empty diff")
+	}
+}
+
+func TestCompareCollections(t *testing.T) {
+	oldState := []*models.Collection{
+		newCollection("c1", "unchanged", newField("f1", "title", schema.FieldTypeText)),
+		newCollection("c2", "deleted"),
+		newCollection("c3", "updated",
+			newField("f2", "removed", schema.FieldTypeText),
+			newField("f3", "changed", schema.FieldTypeText),
+			newField("f4", "same_name", schema.FieldTypeText),
+			newField("f5", "old_name", schema.FieldTypeText),
+		),
+	}
+
+	newState := []*models.Collection{
+		newCollection("c1", "unchanged", newField("f1", "title", schema.FieldTypeText)),
+		newCollection("c3", "updated_renamed",
+			newField("f3", "changed", schema.FieldTypeNumber),
+			newField("f4_new", "same_name", schema.FieldTypeText),
+			newField("f5_new", "new_name", schema.FieldTypeText),
+			newField("f6", "added", schema.FieldTypeBool),
+		),
+		newCollection("c4", "created"),
+	}
+
+	d := diff.Compare(oldState, newState, map[string]string{"updated_renamed.old_name": "new_name"})
+
+	if len(d.Created) != 1 || d.Created[0].Id != "c4" {
+		t.Fatalf("Expected only c4 to be created, got %v", d.Created)
+	}
+
+	if len(d.Deleted) != 1 || d.Deleted[0].Id != "c2" {
+		t.Fatalf("Expected only c2 to be deleted, got %v", d.Deleted)
+	}
+
+	if len(d.Updated) != 1 {
+		t.Fatalf("Expected 1 updated collection, got %d", len(d.Updated))
+	}
+
+	change := d.Updated[0]
+
+	if change.Old.Id != "c3" || change.New.Id != "c3" {
+		t.Fatalf("Expected c3 change, got %s -> %s", change.Old.Id, change.New.Id)
+	}
+
+	if !change.OptionsChanged {
+		t.Fatal("Expected OptionsChanged to be true")
+	}
+
+	if len(change.AddedFields) != 1 || change.AddedFields[0].Id != "f6" {
+		t.Fatalf("Expected only f6 to be added, got %v", change.AddedFields)
+	}
+
+	if len(change.RemovedFields) != 1 || change.RemovedFields[0].Id != "f2" {
+		t.Fatalf("Expected only f2 to be removed, got %v", change.RemovedFields)
+	}
+
+	expectedChanged := map[string]string{
+		"f3": "f3",
+		"f4": "f4_new",
+		"f5": "f5_new",
+	}
+	if len(change.ChangedFields) != len(expectedChanged) {
+		t.Fatalf("Expected %d changed fields, got %d", len(expectedChanged), len(change.ChangedFields))
+	}
+	for _, fc := range change.ChangedFields {
+		if expectedChanged[fc.Old.Id] != fc.New.Id {
+			t.Errorf("Unexpected field change %s -> %s", fc.Old.Id, fc.New.Id)
+		}
+	}
+}
+
+func TestCompareWithoutRenameHint(t *testing.T) {
+	oldState := []*models.Collection{
+		newCollection("c1", "test", newField("f1", "old_name", schema.FieldTypeText)),
+	}
+	newState := []*models.Collection{
+		newCollection("c1", "test", newField("f2", "new_name", schema.FieldTypeText)),
+	}
+
+	d := diff.Compare(oldState, newState, nil)
+
+	if len(d.Updated) != 1 {
+		t.Fatalf("Expected 1 updated collection, got %d", len(d.Updated))
+	}
+
+	change := d.Updated[0]
+
+	if change.OptionsChanged {
+		t.Fatal("Expected OptionsChanged to be false")
+	}
+
+	if len(change.ChangedFields) != 0 {
+		t.Fatalf("Expected no changed fields, got %v", change.ChangedFields)
+	}
+
+	if len(change.AddedFields) != 1 || change.AddedFields[0].Id != "f2" {
+		t.Fatalf("Expected f2 to be added, got %v", change.AddedFields)
+	}
+
+	if len(change.RemovedFields) != 1 || change.RemovedFields[0].Id != "f1" {
+		t.Fatalf("Expected f1 to be removed, got %v", change.RemovedFields)
+	}
+}
+
+func TestCompareNoChanges(t *testing.T) {
+	state := []*models.Collection{
+		newCollection("c1", "test", newField("f1", "title", schema.FieldTypeText)),
+	}
+
+	if d := diff.Compare(state, state, nil); !d.IsEmpty() {
+		t.Fatalf("Expected empty diff, got %v", d)
+	}
+}
+
+func TestReverseRenames(t *testing.T) {
+	oldState := []*models.Collection{
+		newCollection("c1", "old_posts"),
+		newCollection("c2", "users"),
+	}
+	newState := []*models.Collection{
+		newCollection("c1", "posts"),
+		newCollection("c2", "users"),
+	}
+
+	result := diff.ReverseRenames(map[string]string{
+		"posts.name":     "title",
+		"users.nickname": "username",
+		"invalid":        "test",
+	}, oldState, newState)
+
+	expected := map[string]string{
+		"old_posts.title": "name",
+		"users.username":  "nickname",
+	}
+
+	if len(result) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, result)
+	}
+
+	for k, v := range expected {
+		if result[k] != v {
+			t.Errorf("Expected %q to be %q, got %q", k, v, result[k])
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package diff
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+// ErrNoChanges is returned when there are no collection changes to generate a migration for.
+var ErrNoChanges = errors.New("No collection changes.")
+
+// GenerateMigration generates the Go source code of a migration file
+// that applies the changes between oldState and newState on "up"
+// and reverts them on "down".
+//
+// The generated file is expected to be placed in the app migrations
+// directory, aka. to be part of the same package as the other
+// `migrations.Register()` calls.
+//
+// See [Compare] for the renames hints format.
+//
+// Returns [ErrNoChanges] if there are no differences between the two states.
+func GenerateMigration(oldState []*models.Collection, newState []*models.Collection, renames map[string]string) ([]byte, error) {
+	upDiff := Compare(oldState, newState, renames)
+	if upDiff.IsEmpty() {
+		return nil, ErrNoChanges
+	}
+
+	up, err := generateDiffCode(upDiff)
+	if err != nil {
+		return nil, err
+	}
+
+	down, err := generateDiffCode(Compare(newState, oldState, ReverseRenames(renames, oldState, newState)))
+	if err != nil {
+		return nil, err
+	}
+
+	// include only the used optional imports
+	imports := []string{}
+	for pkg, path := range map[string]string{
+		"json.":   `"encoding/json"`,
+		"models.": `"github.com/pocketbase/pocketbase/models"`,
+		"schema.": `"github.com/pocketbase/pocketbase/models/schema"`,
+	} {
+		if strings.Contains(up+down, pkg) {
+			imports = append(imports, path)
+		}
+	}
+	sort.Strings(imports)
+
+	code := fmt.Sprintf(migrationTemplate, strings.Join(imports, "\n"), up, down)
+
+	return format.Source([]byte(code))
+}
+
+const migrationTemplate = `package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/daos"
+	m "github.com/pocketbase/pocketbase/migrations"
+	%s
+)
+
+func init() {
+	m.Register(func(db dbx.Builder) error {
+		dao := daos.New(db)
+		%s
+		return nil
+	}, func(db dbx.Builder) error {
+		dao := daos.New(db)
+		%s
+		return nil
+	})
+}
+`
+
+func generateDiffCode(d *Diff) (string, error) {
+	var b strings.Builder
+
+	// created first, so that the updated relation fields could reference them
+	for _, c := range d.Created {
+		raw, err := json.Marshal(c)
+		if err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(&b, "\n// create collection %q\n", c.Name)
+		b.WriteString("{\n")
+		b.WriteString("collection := &models.Collection{}\n")
+		fmt.Fprintf(&b, "if err := json.Unmarshal([]byte(%s), collection); err != nil {\nreturn err\n}\n", goString(string(raw)))
+		b.WriteString("if err := dao.SaveCollection(collection); err != nil {\nreturn err\n}\n")
+		b.WriteString("}\n")
+	}
+
+	for _, change := range d.Updated {
+		if err := writeCollectionChange(&b, change); err != nil {
+			return "", err
+		}
+	}
+
+	// deleted last, after their relation fields references were removed
+	for _, c := range d.Deleted {
+		fmt.Fprintf(&b, "\n// delete collection %q\n", c.Name)
+		b.WriteString("{\n")
+		fmt.Fprintf(&b, "collection, err := dao.FindCollectionByNameOrId(%q)\nif err != nil {\nreturn err\n}\n", c.Id)
+		b.WriteString("if err := dao.DeleteCollection(collection); err != nil {\nreturn err\n}\n")
+		b.WriteString("}\n")
+	}
+
+	return b.String(), nil
+}
+
+func writeCollectionChange(b *strings.Builder, change *CollectionChange) error {
+	fmt.Fprintf(b, "\n// update collection %q\n", change.Old.Name)
+	b.WriteString("{\n")
+	fmt.Fprintf(b, "collection, err := dao.FindCollectionByNameOrId(%q)\nif err != nil {\nreturn err\n}\n", change.Old.Id)
+
+	if change.OptionsChanged {
+		raw, err := json.Marshal(collectionProps(change.New))
+		if err != nil {
+			return err
+		}
+
+		b.WriteString("\n// update the collection properties\n")
+		b.WriteString("collection.Options = models.CollectionOptions{}\n")
+		fmt.Fprintf(b, "if err := json.Unmarshal([]byte(%s), collection); err != nil {\nreturn err\n}\n", goString(string(raw)))
+	}
+
+	for _, field := range change.RemovedFields {
+		fmt.Fprintf(b, "\n// remove field %q\n", field.Name)
+		fmt.Fprintf(b, "collection.Schema.RemoveField(%q)\n", field.Id)
+	}
+
+	// fields that were matched by name or rename hint and
+	// whose id needs to be synced after the columns update
+	idChanges := []*FieldChange{}
+
+	for _, fieldChange := range change.ChangedFields {
+		if fieldChange.Old.Name != fieldChange.New.Name {
+			fmt.Fprintf(b, "\n// update field %q (renamed to %q)\n", fieldChange.Old.Name, fieldChange.New.Name)
+		} else {
+			fmt.Fprintf(b, "\n// update field %q\n", fieldChange.Old.Name)
+		}
+
+		// keep the old id to preserve the column data
+		updated := *fieldChange.New
+		updated.Id = fieldChange.Old.Id
+		if err := writeAddField(b, &updated); err != nil {
+			return err
+		}
+
+		if fieldChange.Old.Id != fieldChange.New.Id {
+			idChanges = append(idChanges, fieldChange)
+		}
+	}
+
+	for _, field := range change.AddedFields {
+		fmt.Fprintf(b, "\n// add field %q\n", field.Name)
+		if err := writeAddField(b, field); err != nil {
+			return err
+		}
+	}
+
+	b.WriteString("\nif err := dao.SaveCollection(collection); err != nil {\nreturn err\n}\n")
+
+	if len(idChanges) > 0 {
+		b.WriteString("\n// sync the matched fields ids (only the collection model is updated, the columns remain the same)\n")
+		for _, fieldChange := range idChanges {
+			fmt.Fprintf(b, "collection.Schema.GetFieldById(%q).Id = %q\n", fieldChange.Old.Id, fieldChange.New.Id)
+		}
+		b.WriteString("if err := dao.Save(collection); err != nil {\nreturn err\n}\n")
+	}
+
+	b.WriteString("}\n")
+
+	return nil
+}
+
+func writeAddField(b *strings.Builder, field *schema.SchemaField) error {
+	raw, err := json.Marshal(field)
+	if err != nil {
+		return err
+	}
+
+	b.WriteString("{\n")
+	b.WriteString("field := &schema.SchemaField{}\n")
+	fmt.Fprintf(b, "if err := json.Unmarshal([]byte(%s), field); err != nil {\nreturn err\n}\n", goString(string(raw)))
+	b.WriteString("collection.Schema.AddField(field)\n")
+	b.WriteString("}\n")
+
+	return nil
+}
+
+// goString returns the provided string as Go string literal
+// (raw string literal if possible for better readability).
+func goString(s string) string {
+	if strings.Contains(s, "`") {
+		return strconv.Quote(s)
+	}
+
+	return "`" + s + "`"
+}
@@ -0,0 +1,105 @@
+package diff_test
+
+import (
+	"errors"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/migrations/diff"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+func TestGenerateMigrationNoChanges(t *testing.T) {
+	state := []*models.Collection{
+		newCollection("c1", "test", newField("f1", "title", schema.FieldTypeText)),
+	}
+
+	_, err := diff.GenerateMigration(state, state, nil)
+	if !errors.Is(err, diff.ErrNoChanges) {
+		t.Fatalf("Expected ErrNoChanges, got %v", err)
+	}
+}
+
+func TestGenerateMigration(t *testing.T) {
+	oldState := []*models.Collection{
+		newCollection("c1", "deleted"),
+		newCollection("c2", "updated",
+			newField("f1", "removed", schema.FieldTypeText),
+			newField("f2", "old_name", schema.FieldTypeText),
+		),
+	}
+
+	newState := []*models.Collection{
+		newCollection("c2", "updated",
+			newField("f2_new", "new_name", schema.FieldTypeText),
+			newField("f3", "with`backtick", schema.FieldTypeText),
+		),
+		newCollection("c3", "created"),
+	}
+
+	result, err := diff.GenerateMigration(oldState, newState, map[string]string{"updated.old_name": "new_name"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code := string(result)
+
+	if _, err := parser.ParseFile(token.NewFileSet(), "", result, parser.AllErrors); err != nil {
+		t.Fatalf("Expected valid Go source, got error %v:\n%s", err, code)
+	}
+
+	expectedParts := []string{
+		"package migrations",
+		`"encoding/json"`,
+		`"github.com/pocketbase/pocketbase/models"`,
+		`"github.com/pocketbase/pocketbase/models/schema"`,
+		"m.Register(",
+		// up
+		`// create collection "created"`,
+		`// delete collection "deleted"`,
+		`// remove field "removed"`,
+		`collection.Schema.RemoveField("f1")`,
+		`// update field "old_name" (renamed to "new_name")`,
+		`collection.Schema.GetFieldById("f2").Id = "f2_new"`,
+		`// add field "with` + "`" + `backtick"`,
+		`\"name\":\"with` + "`" + `backtick\"`,
+		// down
+		`// create collection "deleted"`,
+		`// delete collection "created"`,
+		`collection.Schema.RemoveField("f3")`,
+		`// update field "new_name" (renamed to "old_name")`,
+		`collection.Schema.GetFieldById("f2_new").Id = "f2"`,
+		`// add field "removed"`,
+	}
+
+	for _, part := range expectedParts {
+		if !strings.Contains(code, part) {
+			t.Errorf("Missing %q in:\n%s", part, code)
+		}
+	}
+
+	// the up migration should create before and delete after the other changes
+	upCode := code[:strings.Index(code, "}, func(db dbx.Builder) error {")]
+	createPos := strings.Index(upCode, `// create collection "created"`)
+	updatePos := strings.Index(upCode, `// update collection "updated"`)
+	deletePos := strings.Index(upCode, `// delete collection "deleted"`)
+	if createPos == -1 || updatePos == -1 || deletePos == -1 || !(createPos < updatePos && updatePos < deletePos) {
+		t.Fatalf("Expected create -> update -> delete order, got %d, %d, %d", createPos, updatePos, deletePos)
+	}
+}
+
+func TestGenerateMigrationOnlyDeletes(t *testing.T) {
+	oldState := []*models.Collection{newCollection("c1", "deleted")}
+
+	result, err := diff.GenerateMigration(oldState, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := parser.ParseFile(token.NewFileSet(), "", result, parser.AllErrors); err != nil {
+		t.Fatalf("Expected valid Go source, got error %v:\n%s", err, result)
+	}
+}
@@ -0,0 +1,49 @@
+package diff
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/pocketbase/pocketbase/models"
+)
+
+// LoadSnapshot loads the collections snapshot from the provided json file.
+//
+// Returns an empty list if the snapshot file doesn't exist yet.
+func LoadSnapshot(path string) ([]*models.Collection, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []*models.Collection{}, nil
+		}
+		return nil, err
+	}
+
+	result := []*models.Collection{}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// SaveSnapshot saves the provided collections state as json
+// in the specified file (creating its parent directory if missing).
+func SaveSnapshot(path string, collections []*models.Collection) error {
+	if collections == nil {
+		collections = []*models.Collection{}
+	}
+
+	raw, err := json.MarshalIndent(collections, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, raw, 0644)
+}
@@ -0,0 +1,47 @@
+package diff_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/migrations/diff"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+)
+
+func TestLoadSnapshotMissingFile(t *testing.T) {
+	result, err := diff.LoadSnapshot(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result == nil || len(result) != 0 {
+		t.Fatalf("Expected empty list, got %v", result)
+	}
+}
+
+func TestSaveAndLoadSnapshot(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "nested", "snapshot.json")
+
+	state := []*models.Collection{
+		newCollection("c1", "test1", newField("f1", "title", schema.FieldTypeText)),
+		newCollection("c2", "test2"),
+	}
+
+	if err := diff.SaveSnapshot(file, state); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := diff.LoadSnapshot(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(loaded) != len(state) {
+		t.Fatalf("Expected %d collections, got %d", len(state), len(loaded))
+	}
+
+	if d := diff.Compare(state, loaded, nil); !d.IsEmpty() {
+		t.Fatalf("Expected the loaded snapshot to match the saved state, got %v", d)
+	}
+}
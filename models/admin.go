@@ -1,13 +1,107 @@
 package models
 
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
 var _ Model = (*Admin)(nil)
 
+const (
+	AdminAccessRead  = "read"
+	AdminAccessWrite = "write"
+)
+
 type Admin struct {
 	BaseAccount
 
-	Avatar int `db:"avatar" json:"avatar"`
+	Avatar      int              `db:"avatar" json:"avatar"`
+	Permissions AdminPermissions `db:"permissions" json:"permissions"`
 }
 
 func (m *Admin) TableName() string {
 	return "_admins"
 }
+
+// IsSuper reports whether the admin has full (unrestricted) access.
+func (m *Admin) IsSuper() bool {
+	return !m.Permissions.Restricted
+}
+
+// -------------------------------------------------------------------
+
+// AdminPermissions defines the admin access permissions.
+//
+// The zero value defines a super admin with full access.
+type AdminPermissions struct {
+	// Restricted limits the admin access only to the granted Collections.
+	Restricted bool `form:"restricted" json:"restricted"`
+
+	// Collections holds the collection grants in the format
+	// {"collectionId": AdminAccessRead|AdminAccessWrite}.
+	Collections map[string]string `form:"collections" json:"collections"`
+}
+
+// CanRead reports whether the permissions allow reading
+// the specified collection and its records.
+func (p AdminPermissions) CanRead(collectionId string) bool {
+	if !p.Restricted {
+		return true
+	}
+
+	access := p.Collections[collectionId]
+
+	return access == AdminAccessRead || access == AdminAccessWrite
+}
+
+// CanWrite reports whether the permissions allow modifying
+// the specified collection and its records.
+func (p AdminPermissions) CanWrite(collectionId string) bool {
+	if !p.Restricted {
+		return true
+	}
+
+	return p.Collections[collectionId] == AdminAccessWrite
+}
+
+// MarshalJSON implements the [json.Marshaler] interface.
+func (p AdminPermissions) MarshalJSON() ([]byte, error) {
+	type alias AdminPermissions // prevent recursion
+
+	// inialize an empty map to ensure that `{}` is returned as json
+	if p.Collections == nil {
+		p.Collections = map[string]string{}
+	}
+
+	return json.Marshal(alias(p))
+}
+
+// Value implements the [driver.Valuer] interface.
+func (p AdminPermissions) Value() (driver.Value, error) {
+	data, err := json.Marshal(p)
+
+	return string(data), err
+}
+
+// Scan implements [sql.Scanner] interface to scan the provided value
+// into the current AdminPermissions instance.
+func (p *AdminPermissions) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		// no cast needed
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("Failed to unmarshal AdminPermissions value %q.", value)
+	}
+
+	if len(data) == 0 {
+		data = []byte("{}")
+	}
+
+	return json.Unmarshal(data, p)
+}
@@ -1,6 +1,7 @@
 package models_test
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/pocketbase/pocketbase/models"
@@ -12,3 +13,112 @@ func TestAdminTableName(t *testing.T) {
 		t.Fatalf("Unexpected table name, got %q", m.TableName())
 	}
 }
+
+func TestAdminIsSuper(t *testing.T) {
+	m := models.Admin{}
+	if !m.IsSuper() {
+		t.Fatal("Expected the zero value admin to be super admin")
+	}
+
+	m.Permissions.Restricted = true
+	if m.IsSuper() {
+		t.Fatal("Expected the restricted admin to not be super admin")
+	}
+}
+
+func TestAdminPermissionsAccess(t *testing.T) {
+	scenarios := []struct {
+		permissions   models.AdminPermissions
+		collectionId  string
+		expectedRead  bool
+		expectedWrite bool
+	}{
+		{models.AdminPermissions{}, "test", true, true},
+		{models.AdminPermissions{Restricted: true}, "test", false, false},
+		{models.AdminPermissions{Restricted: true, Collections: map[string]string{"test": "read"}}, "test", true, false},
+		{models.AdminPermissions{Restricted: true, Collections: map[string]string{"test": "write"}}, "test", true, true},
+		{models.AdminPermissions{Restricted: true, Collections: map[string]string{"test": "invalid"}}, "test", false, false},
+		{models.AdminPermissions{Restricted: true, Collections: map[string]string{"other": "write"}}, "test", false, false},
+	}
+
+	for i, s := range scenarios {
+		if v := s.permissions.CanRead(s.collectionId); v != s.expectedRead {
+			t.Errorf("(%d) Expected CanRead %v, got %v", i, s.expectedRead, v)
+		}
+
+		if v := s.permissions.CanWrite(s.collectionId); v != s.expectedWrite {
+			t.Errorf("(%d) Expected CanWrite %v, got %v", i, s.expectedWrite, v)
+		}
+	}
+}
+
+func TestAdminPermissionsMarshalJSON(t *testing.T) {
+	scenarios := []struct {
+		permissions models.AdminPermissions
+		expected    string
+	}{
+		{models.AdminPermissions{}, `{"restricted":false,"collections":{}}`},
+		{models.AdminPermissions{Restricted: true, Collections: map[string]string{"test": "read"}}, `{"restricted":true,"collections":{"test":"read"}}`},
+	}
+
+	for i, s := range scenarios {
+		result, err := json.Marshal(s.permissions)
+		if err != nil {
+			t.Errorf("(%d) %v", i, err)
+			continue
+		}
+
+		if string(result) != s.expected {
+			t.Errorf("(%d) Expected %s, got %s", i, s.expected, result)
+		}
+	}
+}
+
+func TestAdminPermissionsValue(t *testing.T) {
+	p := models.AdminPermissions{Restricted: true, Collections: map[string]string{"test": "write"}}
+
+	result, err := p.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"restricted":true,"collections":{"test":"write"}}`
+	if result != expected {
+		t.Fatalf("Expected %s, got %v", expected, result)
+	}
+}
+
+func TestAdminPermissionsScan(t *testing.T) {
+	scenarios := []struct {
+		value       any
+		expectError bool
+		expected    string
+	}{
+		{nil, false, `{"restricted":false,"collections":{}}`},
+		{"", false, `{"restricted":false,"collections":{}}`},
+		{[]byte(`{"restricted":true}`), false, `{"restricted":true,"collections":{}}`},
+		{`{"restricted":true,"collections":{"test":"read"}}`, false, `{"restricted":true,"collections":{"test":"read"}}`},
+		{`invalid`, true, ""},
+		{123, true, ""},
+	}
+
+	for i, s := range scenarios {
+		p := models.AdminPermissions{}
+		err := p.Scan(s.value)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+			continue
+		}
+
+		if hasErr {
+			continue
+		}
+
+		encoded, _ := json.Marshal(p)
+		if string(encoded) != s.expected {
+			t.Errorf("(%d) Expected %s, got %s", i, s.expected, encoded)
+		}
+	}
+}
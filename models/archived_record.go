@@ -0,0 +1,25 @@
+package models
+
+import "github.com/pocketbase/pocketbase/tools/types"
+
+var _ Model = (*ArchivedRecord)(nil)
+
+// ArchivedRecord defines a single deleted record snapshot
+// of a collection with enabled archive option.
+type ArchivedRecord struct {
+	BaseModel
+
+	CollectionId string `db:"collectionId" json:"collectionId"`
+	RecordId     string `db:"recordId" json:"recordId"`
+
+	// Data is the deleted record data (including its base created and updated fields).
+	Data types.JsonMap `db:"data" json:"data"`
+
+	// DeletedBy is the identifier of the delete request client
+	// (eg. "admin:ID", "user:ID", "ip:IP") or empty string if unknown.
+	DeletedBy string `db:"deletedBy" json:"deletedBy"`
+}
+
+func (m *ArchivedRecord) TableName() string {
+	return "_archivedRecords"
+}
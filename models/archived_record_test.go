@@ -0,0 +1,14 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestArchivedRecordTableName(t *testing.T) {
+	m := models.ArchivedRecord{}
+	if m.TableName() != "_archivedRecords" {
+		t.Fatalf("Unexpected table name, got %q", m.TableName())
+	}
+}
@@ -19,6 +19,9 @@ type ColumnValueMapper interface {
 type Model interface {
 	TableName() string
 	HasId() bool
+	IsNew() bool
+	MarkAsNew()
+	MarkAsNotNew()
 	GetId() string
 	GetCreated() types.DateTime
 	GetUpdated() types.DateTime
@@ -33,6 +36,8 @@ type Model interface {
 
 // BaseModel defines common fields and methods used by all other models.
 type BaseModel struct {
+	markedAsNew bool
+
 	Id      string         `db:"id" json:"id"`
 	Created types.DateTime `db:"created" json:"created"`
 	Updated types.DateTime `db:"updated" json:"updated"`
@@ -43,6 +48,23 @@ func (m *BaseModel) HasId() bool {
 	return m.GetId() != ""
 }
 
+// IsNew checks whether the model should be created on save
+// (aka. it doesn't have an id or it was explicitly marked as new).
+func (m *BaseModel) IsNew() bool {
+	return m.markedAsNew || !m.HasId()
+}
+
+// MarkAsNew marks the model to be created on save even if it has
+// an id already (eg. a custom generated one).
+func (m *BaseModel) MarkAsNew() {
+	m.markedAsNew = true
+}
+
+// MarkAsNotNew clears the [BaseModel.MarkAsNew] flag.
+func (m *BaseModel) MarkAsNotNew() {
+	m.markedAsNew = false
+}
+
 // GetId returns the model's id.
 func (m *BaseModel) GetId() string {
 	return m.Id
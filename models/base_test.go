@@ -34,6 +34,28 @@ func TestBaseModelHasId(t *testing.T) {
 	}
 }
 
+func TestBaseModelIsNew(t *testing.T) {
+	m0 := models.BaseModel{}
+	if !m0.IsNew() {
+		t.Fatal("Expected the model without id to be new")
+	}
+
+	m1 := models.BaseModel{Id: "abc"}
+	if m1.IsNew() {
+		t.Fatal("Expected the model with id to not be new")
+	}
+
+	m1.MarkAsNew()
+	if !m1.IsNew() {
+		t.Fatal("Expected the model marked as new to be new")
+	}
+
+	m1.MarkAsNotNew()
+	if m1.IsNew() {
+		t.Fatal("Expected the model marked as not new to not be new")
+	}
+}
+
 func TestBaseModelGetId(t *testing.T) {
 	m0 := models.BaseModel{}
 	if m0.GetId() != "" {
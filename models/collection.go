@@ -1,27 +1,820 @@
 package models
 
-import "github.com/pocketbase/pocketbase/models/schema"
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/template"
+)
 
 var _ Model = (*Collection)(nil)
 
 type Collection struct {
 	BaseModel
 
-	Name       string        `db:"name" json:"name"`
-	System     bool          `db:"system" json:"system"`
-	Schema     schema.Schema `db:"schema" json:"schema"`
-	ListRule   *string       `db:"listRule" json:"listRule"`
-	ViewRule   *string       `db:"viewRule" json:"viewRule"`
-	CreateRule *string       `db:"createRule" json:"createRule"`
-	UpdateRule *string       `db:"updateRule" json:"updateRule"`
-	DeleteRule *string       `db:"deleteRule" json:"deleteRule"`
+	Name       string            `db:"name" json:"name"`
+	System     bool              `db:"system" json:"system"`
+	Schema     schema.Schema     `db:"schema" json:"schema"`
+	ListRule   *string           `db:"listRule" json:"listRule"`
+	ViewRule   *string           `db:"viewRule" json:"viewRule"`
+	CreateRule *string           `db:"createRule" json:"createRule"`
+	UpdateRule *string           `db:"updateRule" json:"updateRule"`
+	DeleteRule *string           `db:"deleteRule" json:"deleteRule"`
+	Options    CollectionOptions `db:"options" json:"options"`
 }
 
 func (m *Collection) TableName() string {
 	return "_collections"
 }
 
+// RelationIndexes returns the automatically created relation field
+// indexes of the collection in the format {"indexName": "fieldName"}
+// (empty if the RelationIndexes option is disabled).
+//
+// Only the single relation fields are indexed. The multiple relation
+// fields are stored as serialized json arrays and SQLite cannot index
+// their individual elements (an expression or generated column index
+// could cover only a fixed array position), so they are skipped.
+//
+// The index names are derived from the field ids to keep them
+// stable between field renames.
+func (m *Collection) RelationIndexes() map[string]string {
+	result := map[string]string{}
+
+	if !m.Options.RelationIndexes {
+		return result
+	}
+
+	for _, field := range m.Schema.Fields() {
+		if field.Type != schema.FieldTypeRelation {
+			continue
+		}
+
+		field.InitOptions()
+		options, _ := field.Options.(*schema.RelationOptions)
+		if options == nil || options.MaxSelect > 1 {
+			continue
+		}
+
+		name := "_ridx_" + strings.ReplaceAll(m.Id, "-", "") + "_" + nonWordCharsRegex.ReplaceAllString(field.Id, "")
+		result[name] = field.Name
+	}
+
+	return result
+}
+
 // BaseFilesPath returns the storage dir path used by the collection.
 func (m *Collection) BaseFilesPath() string {
 	return m.Id
 }
+
+// SchemaHash returns a short hash derived from the collection schema
+// definition that changes whenever a schema field is added, removed
+// or modified.
+//
+// Clients could use it to decide whether to refetch their cached
+// collection metadata.
+func (m *Collection) SchemaHash() string {
+	raw, _ := json.Marshal(m.Schema)
+
+	return fmt.Sprintf("%x", sha256.Sum256(raw))[:16]
+}
+
+// MarshalJSON implements the [json.Marshaler] interface.
+//
+// In addition to the collection properties, the exported json
+// also contains the collection "schemaHash" (see [Collection.SchemaHash]).
+func (m Collection) MarshalJSON() ([]byte, error) {
+	type alias Collection // prevent recursion
+
+	return json.Marshal(struct {
+		alias
+		SchemaHash string `json:"schemaHash"`
+	}{
+		alias:      alias(m),
+		SchemaHash: m.SchemaHash(),
+	})
+}
+
+// -------------------------------------------------------------------
+
+// headerNameRegex matches a valid HTTP header field name (aka. RFC 7230 token).
+var headerNameRegex = regexp.MustCompile("^[!#$%&'*+\\-.^_`|~0-9a-zA-Z]+$")
+
+// countNameRegex matches a valid relation count name.
+var countNameRegex = regexp.MustCompile(`^\w+$`)
+
+// CollectionOptions defines the additional (non schema) collection settings.
+type CollectionOptions struct {
+	// Headers specifies static response headers that are applied
+	// to the collection records api responses (eg. "Cache-Control").
+	Headers map[string]string `form:"headers" json:"headers"`
+
+	// Cache specifies the optional collection records read cache settings.
+	Cache RecordCacheOptions `form:"cache" json:"cache"`
+
+	// Counts specifies the back-relations whose records count should be
+	// included in the collection records api responses (as "@counts").
+	Counts []RelationCountOptions `form:"counts" json:"counts"`
+
+	// Logs specifies the collection records api requests logging settings.
+	Logs RecordLogsOptions `form:"logs" json:"logs"`
+
+	// Indexes specifies the collection records table indexes.
+	Indexes []CollectionIndex `form:"indexes" json:"indexes"`
+
+	// Aliases specifies the records api output field names
+	// in the format {"fieldName": "alias"} (eg. {"username": "user_name"}).
+	//
+	// The record upsert forms accept both the alias and the field name.
+	// The filter, sort and expand parameters still use the field names.
+	Aliases map[string]string `form:"aliases" json:"aliases"`
+
+	// Id specifies the collection records id generation settings.
+	Id RecordIdOptions `form:"id" json:"id"`
+
+	// AutoExpand specifies the default relation expand paths
+	// (eg. "author", "author.profile") applied to the records list
+	// and view api responses when the client doesn't send its own
+	// "expand" query parameter (an empty "expand" disables it).
+	//
+	// The related collections view rules are still enforced.
+	AutoExpand []string `form:"autoExpand" json:"autoExpand"`
+
+	// Archive enables storing a snapshot of the deleted collection
+	// records (see [ArchivedRecord]), so that they could be restored later.
+	//
+	// The files of the archived records are also preserved.
+	Archive bool `form:"archive" json:"archive"`
+
+	// EstimatedCountThreshold enables returning an estimated (from the
+	// SQLite stat tables) instead of an exact records list totalItems
+	// when the estimated number of collection records is at least the
+	// threshold (0 for always exact count).
+	//
+	// The estimate is used only for the unfiltered list requests
+	// and requires the db statistics to be collected with ANALYZE.
+	EstimatedCountThreshold int `form:"estimatedCountThreshold" json:"estimatedCountThreshold"`
+
+	// Publish specifies the collection records draft/publish workflow settings.
+	Publish RecordPublishOptions `form:"publish" json:"publish"`
+
+	// ListExcludedFields specifies the schema fields that are omitted from
+	// the records list api responses unless they are explicitly requested
+	// with the "fields" query parameter (eg. large text fields).
+	//
+	// The excluded fields are still returned by the record view api
+	// and could still be used in the list filter and sort parameters.
+	ListExcludedFields []string `form:"listExcludedFields" json:"listExcludedFields"`
+
+	// CoalesceReads enables sharing a single db execution between the
+	// concurrent identical records list requests (the access rules are
+	// part of the requests signature, so they are still applied per request).
+	CoalesceReads bool `form:"coalesceReads" json:"coalesceReads"`
+
+	// Templates specifies the named server-side record templates in the
+	// format {"name": {"format": "html", "content": "..."}}, that could be
+	// rendered with the record render api (eg. email previews or printable views).
+	Templates map[string]RecordTemplate `form:"templates" json:"templates"`
+
+	// Mirrors specifies the denormalized collection fields that are kept in
+	// sync with a field of the related (aka. source) record, eg. the author
+	// name of a post (see [RecordMirrorOptions]).
+	Mirrors []RecordMirrorOptions `form:"mirrors" json:"mirrors"`
+
+	// RelationIndexes enables the automatic creation of a records table
+	// index for each single relation field of the collection, so that
+	// the back-relation lookups (eg. relation counts and cascade deletes)
+	// don't have to scan the whole table (see [Collection.RelationIndexes]).
+	RelationIndexes bool `form:"relationIndexes" json:"relationIndexes"`
+
+	// Versions enables storing the previous state of the updated
+	// collection records (see [RecordVersion]), so that they could be
+	// listed and restored later.
+	//
+	// The versions api access follows the collection view (list and view)
+	// and update (restore) rules. The stored versions values follow
+	// the renamed and deleted fields, but a value of a field with
+	// changed type or options could fail the restore validation.
+	Versions bool `form:"versions" json:"versions"`
+}
+
+// Validate makes CollectionOptions validatable by implementing [validation.Validatable] interface.
+func (o CollectionOptions) Validate() error {
+	return validation.ValidateStruct(&o,
+		validation.Field(&o.Headers, validation.By(checkHeaders)),
+		validation.Field(&o.Cache),
+		validation.Field(&o.Counts, validation.By(checkUniqueCountNames)),
+		validation.Field(&o.Logs),
+		validation.Field(&o.Indexes, validation.By(checkUniqueIndexNames)),
+		validation.Field(&o.Aliases, validation.By(checkAliases)),
+		validation.Field(&o.Id),
+		validation.Field(&o.AutoExpand, validation.Each(validation.Required, validation.Match(expandPathRegex))),
+		validation.Field(&o.EstimatedCountThreshold, validation.Min(0)),
+		validation.Field(&o.Publish),
+		validation.Field(&o.ListExcludedFields, validation.Each(validation.Required)),
+		validation.Field(&o.Templates, validation.By(checkTemplates)),
+		validation.Field(&o.Mirrors, validation.By(checkUniqueMirrorFields)),
+	)
+}
+
+func checkHeaders(value any) error {
+	v, _ := value.(map[string]string)
+
+	for name, val := range v {
+		if !headerNameRegex.MatchString(name) {
+			return validation.NewError("validation_invalid_header_name", fmt.Sprintf("Invalid header name %q.", name))
+		}
+
+		if strings.TrimSpace(val) == "" || strings.ContainsAny(val, "\r\n") {
+			return validation.NewError("validation_invalid_header_value", fmt.Sprintf("Invalid or empty %q header value.", name))
+		}
+	}
+
+	return nil
+}
+
+func checkUniqueCountNames(value any) error {
+	v, _ := value.([]RelationCountOptions)
+
+	names := make(map[string]struct{}, len(v))
+	for _, c := range v {
+		if _, ok := names[c.Name]; ok {
+			return validation.NewError("validation_duplicated_count_name", fmt.Sprintf("Duplicated count name %q.", c.Name))
+		}
+		names[c.Name] = struct{}{}
+	}
+
+	return nil
+}
+
+func checkUniqueMirrorFields(value any) error {
+	v, _ := value.([]RecordMirrorOptions)
+
+	fields := make(map[string]struct{}, len(v))
+	for _, m := range v {
+		if _, ok := fields[m.Field]; ok {
+			return validation.NewError("validation_duplicated_mirror_field", fmt.Sprintf("Duplicated mirror field %q.", m.Field))
+		}
+		fields[m.Field] = struct{}{}
+	}
+
+	return nil
+}
+
+func checkUniqueIndexNames(value any) error {
+	v, _ := value.([]CollectionIndex)
+
+	names := make(map[string]struct{}, len(v))
+	for _, idx := range v {
+		name := strings.ToLower(idx.Name)
+		if _, ok := names[name]; ok {
+			return validation.NewError("validation_duplicated_index_name", fmt.Sprintf("Duplicated index name %q.", idx.Name))
+		}
+		names[name] = struct{}{}
+	}
+
+	return nil
+}
+
+// aliasRegex matches a valid field alias.
+var aliasRegex = regexp.MustCompile(`^\w+$`)
+
+func checkAliases(value any) error {
+	v, _ := value.(map[string]string)
+
+	aliases := make(map[string]struct{}, len(v))
+	for name, alias := range v {
+		if !aliasRegex.MatchString(alias) {
+			return validation.NewError("validation_invalid_alias", fmt.Sprintf("Invalid %q field alias %q.", name, alias))
+		}
+
+		if list.ExistInSlice(alias, schema.ReservedFieldNames()) {
+			return validation.NewError("validation_reserved_alias", fmt.Sprintf("The %q field alias %q is reserved.", name, alias))
+		}
+
+		if _, ok := aliases[alias]; ok {
+			return validation.NewError("validation_duplicated_alias", fmt.Sprintf("Duplicated field alias %q.", alias))
+		}
+		aliases[alias] = struct{}{}
+	}
+
+	return nil
+}
+
+// templateNameRegex matches a valid record template name.
+var templateNameRegex = regexp.MustCompile(`^\w+$`)
+
+func checkTemplates(value any) error {
+	v, _ := value.(map[string]RecordTemplate)
+
+	for name, tmpl := range v {
+		if !templateNameRegex.MatchString(name) {
+			return validation.NewError("validation_invalid_template_name", fmt.Sprintf("Invalid template name %q.", name))
+		}
+
+		if err := tmpl.Validate(); err != nil {
+			return validation.Errors{name: err}
+		}
+	}
+
+	return nil
+}
+
+// expandPathRegex matches a valid relation expand path (eg. "author.profile").
+var expandPathRegex = regexp.MustCompile(`^\w+(\.\w+)*$`)
+
+// nonWordCharsRegex matches all non word characters.
+var nonWordCharsRegex = regexp.MustCompile(`\W`)
+
+// indexNameRegex matches a valid collection index name.
+var indexNameRegex = regexp.MustCompile(`^\w+$`)
+
+// CollectionIndex defines a single (optionally unique and/or partial)
+// collection records table index.
+type CollectionIndex struct {
+	// Name is the index identifier (unique within the collection).
+	Name string `form:"name" json:"name"`
+
+	// Fields is the list of the indexed schema field (or system column) names.
+	Fields []string `form:"fields" json:"fields"`
+
+	Unique bool `form:"unique" json:"unique"`
+
+	// Where is an optional SQLite expression that makes the index
+	// partial (eg. "status = 'active'").
+	Where string `form:"where" json:"where"`
+}
+
+// Validate makes CollectionIndex validatable by implementing [validation.Validatable] interface.
+//
+// Note that the fields existence is not checked here because it depends on the collection schema.
+func (idx CollectionIndex) Validate() error {
+	return validation.ValidateStruct(&idx,
+		validation.Field(&idx.Name, validation.Required, validation.Length(1, 100), validation.Match(indexNameRegex)),
+		validation.Field(&idx.Fields, validation.Required, validation.By(checkUniqueIndexFields)),
+		validation.Field(&idx.Where, validation.Length(0, 1000), validation.By(checkIndexWhere)),
+	)
+}
+
+// TableIndexName returns the name of the db index for the provided collection.
+//
+// The collection id (instead of its name) is used to keep the
+// index name stable between collection renames.
+func (idx CollectionIndex) TableIndexName(collection *Collection) string {
+	return "_idx_" + strings.ReplaceAll(collection.Id, "-", "") + "_" + idx.Name
+}
+
+// Equal checks whether the two indexes have the same definition.
+func (idx CollectionIndex) Equal(other CollectionIndex) bool {
+	return idx.Name == other.Name &&
+		idx.Unique == other.Unique &&
+		idx.Where == other.Where &&
+		strings.Join(idx.Fields, ",") == strings.Join(other.Fields, ",")
+}
+
+func checkUniqueIndexFields(value any) error {
+	v, _ := value.([]string)
+
+	names := make(map[string]struct{}, len(v))
+	for _, name := range v {
+		if _, ok := names[name]; ok {
+			return validation.NewError("validation_duplicated_index_field", fmt.Sprintf("Duplicated index field %q.", name))
+		}
+		names[name] = struct{}{}
+	}
+
+	return nil
+}
+
+func checkIndexWhere(value any) error {
+	v, _ := value.(string)
+
+	// the expression is embedded as it is in the index definition
+	// so disallow statements separators and comments
+	if strings.ContainsAny(v, ";") || strings.Contains(v, "--") || strings.Contains(v, "/*") {
+		return validation.NewError("validation_invalid_index_where", "The index where expression must not contain ';' or comments.")
+	}
+
+	return nil
+}
+
+// RelationCountOptions defines a single back-relation count,
+// aka. the number of records from CollectionId that reference
+// the current collection record through their Field relation.
+//
+// Note that the counted records are not filtered by the
+// related collection's list rule.
+type RelationCountOptions struct {
+	// Name is the key under which the count is exported (eg. "comments").
+	Name string `form:"name" json:"name"`
+
+	// CollectionId is the id of the collection with the relation field.
+	CollectionId string `form:"collectionId" json:"collectionId"`
+
+	// Field is the name of the relation field referencing the current collection.
+	Field string `form:"field" json:"field"`
+}
+
+// Validate makes RelationCountOptions validatable by implementing [validation.Validatable] interface.
+func (o RelationCountOptions) Validate() error {
+	return validation.ValidateStruct(&o,
+		validation.Field(&o.Name, validation.Required, validation.Length(1, 255), validation.Match(countNameRegex)),
+		validation.Field(&o.CollectionId, validation.Required),
+		validation.Field(&o.Field, validation.Required),
+	)
+}
+
+// RecordMirrorOptions defines a single denormalized (aka. mirror) field,
+// whose value is a copy of the SourceField value of the record
+// referenced by the RelationField single relation.
+//
+// The mirror field is filled on record create and on RelationField change
+// and it is updated automatically when the source record field changes.
+type RecordMirrorOptions struct {
+	// Field is the name of the field that stores the mirrored value.
+	Field string `form:"field" json:"field"`
+
+	// RelationField is the name of the single relation field
+	// referencing the source record.
+	RelationField string `form:"relationField" json:"relationField"`
+
+	// SourceField is the name of the mirrored source record field.
+	SourceField string `form:"sourceField" json:"sourceField"`
+}
+
+// Validate makes RecordMirrorOptions validatable by implementing [validation.Validatable] interface.
+//
+// Note that the fields existence is not checked here because it depends on the collections schema.
+func (o RecordMirrorOptions) Validate() error {
+	return validation.ValidateStruct(&o,
+		validation.Field(&o.Field, validation.Required, validation.By(checkMirrorFieldName(o.RelationField))),
+		validation.Field(&o.RelationField, validation.Required),
+		validation.Field(&o.SourceField, validation.Required),
+	)
+}
+
+func checkMirrorFieldName(relationField string) validation.RuleFunc {
+	return func(value any) error {
+		v, _ := value.(string)
+
+		if v != "" && v == relationField {
+			return validation.NewError("validation_invalid_mirror_field", "The mirror field must be different from the relation field.")
+		}
+
+		return nil
+	}
+}
+
+// Records api request actions.
+const (
+	RecordActionList   string = "list"
+	RecordActionView   string = "view"
+	RecordActionCreate string = "create"
+	RecordActionUpdate string = "update"
+	RecordActionDelete string = "delete"
+	RecordActionFile   string = "file"
+)
+
+// RecordLogsOptions defines the collection records api requests logging settings.
+type RecordLogsOptions struct {
+	// Disabled excludes all collection records api requests from the activity logs.
+	Disabled bool `form:"disabled" json:"disabled"`
+
+	// SkipActions excludes only the listed records api actions from the
+	// activity logs (eg. "list", "view", "create", "update", "delete", "file").
+	SkipActions []string `form:"skipActions" json:"skipActions"`
+}
+
+// Validate makes RecordLogsOptions validatable by implementing [validation.Validatable] interface.
+func (o RecordLogsOptions) Validate() error {
+	return validation.ValidateStruct(&o,
+		validation.Field(&o.SkipActions, validation.Each(validation.In(
+			RecordActionList,
+			RecordActionView,
+			RecordActionCreate,
+			RecordActionUpdate,
+			RecordActionDelete,
+			RecordActionFile,
+		))),
+	)
+}
+
+// IsSkipped checks whether the provided records api action
+// should be excluded from the activity logs.
+func (o RecordLogsOptions) IsSkipped(action string) bool {
+	if o.Disabled {
+		return true
+	}
+
+	for _, skipped := range o.SkipActions {
+		if skipped == action {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Record id generation types.
+const (
+	RecordIdTypeRandom   string = "random"
+	RecordIdTypeSequence string = "sequence"
+	RecordIdTypeCustom   string = "custom"
+)
+
+// recordIdPrefixRegex matches a valid sequence record id prefix.
+var recordIdPrefixRegex = regexp.MustCompile(`^[\w\-]+$`)
+
+// RecordIdOptions defines the collection records id generation settings.
+//
+// The ids are generated only on create and only if the record doesn't
+// have an id already (see forms.RecordUpsert).
+type RecordIdOptions struct {
+	// Type is the id generation strategy (defaults to "random").
+	//
+	// "sequence" generates Prefix + zero padded sequence number ids
+	// (eg. "INV-00001") and "custom" uses the registered Go
+	// generator with the specified Generator name.
+	Type string `form:"type" json:"type"`
+
+	// Prefix is the optional sequence id prefix (eg. "INV-").
+	Prefix string `form:"prefix" json:"prefix"`
+
+	// Padding is the min number of the sequence digits (eg. 5 for "00001").
+	Padding int `form:"padding" json:"padding"`
+
+	// Generator is the name of the custom id generator.
+	Generator string `form:"generator" json:"generator"`
+}
+
+// Validate makes RecordIdOptions validatable by implementing [validation.Validatable] interface.
+func (o RecordIdOptions) Validate() error {
+	return validation.ValidateStruct(&o,
+		validation.Field(
+			&o.Type,
+			validation.In(RecordIdTypeRandom, RecordIdTypeSequence, RecordIdTypeCustom),
+		),
+		validation.Field(
+			&o.Prefix,
+			validation.Length(1, 50),
+			validation.Match(recordIdPrefixRegex),
+		),
+		validation.Field(&o.Padding, validation.Min(0), validation.Max(20)),
+		validation.Field(
+			&o.Generator,
+			validation.When(o.Type == RecordIdTypeCustom, validation.Required),
+		),
+	)
+}
+
+// RecordCacheOptions defines the collection records read cache settings.
+//
+// When enabled, the raw records fetched by the view api are kept
+// in the app cache for up to Ttl seconds (or until they are updated/deleted).
+type RecordCacheOptions struct {
+	Enabled    bool `form:"enabled" json:"enabled"`
+	MaxEntries int  `form:"maxEntries" json:"maxEntries"`
+	Ttl        int  `form:"ttl" json:"ttl"`
+}
+
+// Validate makes RecordCacheOptions validatable by implementing [validation.Validatable] interface.
+func (o RecordCacheOptions) Validate() error {
+	return validation.ValidateStruct(&o,
+		validation.Field(&o.MaxEntries, validation.When(o.Enabled, validation.Required), validation.Min(0)),
+		validation.Field(&o.Ttl, validation.When(o.Enabled, validation.Required), validation.Min(0)),
+	)
+}
+
+// Record publish statuses.
+const (
+	RecordStatusDraft     string = "draft"
+	RecordStatusPublished string = "published"
+)
+
+// RecordPublishOptions defines the collection records draft/publish workflow settings.
+//
+// When enabled, the records list and view api requests of non-admins
+// return only the published records (aka. with "published" status and
+// empty or past publish date), allowing scheduled publishing by setting
+// the publish date in the future.
+type RecordPublishOptions struct {
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// StatusField is the name of the record status field
+	// (a text or select field with "draft" or "published" value).
+	StatusField string `form:"statusField" json:"statusField"`
+
+	// PublishedAtField is the optional name of the record publish date field.
+	PublishedAtField string `form:"publishedAtField" json:"publishedAtField"`
+}
+
+// Validate makes RecordPublishOptions validatable by implementing [validation.Validatable] interface.
+func (o RecordPublishOptions) Validate() error {
+	return validation.ValidateStruct(&o,
+		validation.Field(&o.StatusField, validation.When(o.Enabled, validation.Required)),
+		validation.Field(&o.PublishedAtField, validation.When(o.PublishedAtField == o.StatusField, validation.Empty)),
+	)
+}
+
+// RecordTemplate defines a single server-side record template
+// (see [template.Render] for the execution limits).
+type RecordTemplate struct {
+	// Format is the template format - "text" or "html"
+	// (the html templates escape contextually the record values).
+	Format string `form:"format" json:"format"`
+
+	// Content is the Go template content.
+	Content string `form:"content" json:"content"`
+}
+
+// Validate makes RecordTemplate validatable by implementing [validation.Validatable] interface.
+func (t RecordTemplate) Validate() error {
+	return validation.ValidateStruct(&t,
+		validation.Field(&t.Format, validation.Required, validation.In(template.FormatText, template.FormatHtml)),
+		validation.Field(&t.Content, validation.Required, validation.By(t.checkContent)),
+	)
+}
+
+func (t RecordTemplate) checkContent(value any) error {
+	v, _ := value.(string)
+
+	if err := template.Validate(t.Format, v); err != nil {
+		return validation.NewError("validation_invalid_template", err.Error())
+	}
+
+	return nil
+}
+
+// Render executes the template with the public export of the provided
+// records (including their expanded relations) as template data.
+//
+// The first record is available in the template as "record"
+// and all records as "records", eg.:
+//
+//	{{.record.title}}
+//	{{range .records}}{{.title}}{{end}}
+func (t RecordTemplate) Render(ctx context.Context, records ...*Record) (string, error) {
+	// normalize the records export to plain json values
+	raw, err := json.Marshal(records)
+	if err != nil {
+		return "", err
+	}
+
+	exported := []map[string]any{}
+	if err := json.Unmarshal(raw, &exported); err != nil {
+		return "", err
+	}
+
+	data := map[string]any{"records": exported}
+	if len(exported) > 0 {
+		data["record"] = exported[0]
+	}
+
+	return template.Render(ctx, t.Format, t.Content, data)
+}
+
+// ApplyHeaders sets the configured options headers to the provided
+// header map, skipping the ones that are already set (eg. by other middlewares).
+func (o CollectionOptions) ApplyHeaders(h http.Header) {
+	for name, val := range o.Headers {
+		if h.Get(name) != "" {
+			continue // don't overwrite already set headers
+		}
+		h.Set(name, val)
+	}
+}
+
+// ResolveAliases returns a shallow copy of data with the aliased keys
+// renamed to their field names (the field name keys have priority).
+func (o CollectionOptions) ResolveAliases(data map[string]any) map[string]any {
+	result := make(map[string]any, len(data))
+	for k, v := range data {
+		result[k] = v
+	}
+
+	for name, alias := range o.Aliases {
+		v, ok := result[alias]
+		if !ok {
+			continue
+		}
+
+		delete(result, alias)
+
+		if _, exists := result[name]; !exists {
+			result[name] = v
+		}
+	}
+
+	return result
+}
+
+// MarshalJSON implements the [json.Marshaler] interface.
+func (o CollectionOptions) MarshalJSON() ([]byte, error) {
+	type alias CollectionOptions // prevent recursion
+
+	// inialize an empty map to ensure that `{}` is returned as json
+	if o.Headers == nil {
+		o.Headers = map[string]string{}
+	}
+
+	// inialize an empty slice to ensure that `[]` is returned as json
+	if o.Counts == nil {
+		o.Counts = []RelationCountOptions{}
+	}
+
+	if o.Logs.SkipActions == nil {
+		o.Logs.SkipActions = []string{}
+	}
+
+	if o.Indexes == nil {
+		o.Indexes = []CollectionIndex{}
+	}
+
+	if o.Aliases == nil {
+		o.Aliases = map[string]string{}
+	}
+
+	if o.AutoExpand == nil {
+		o.AutoExpand = []string{}
+	}
+
+	if o.ListExcludedFields == nil {
+		o.ListExcludedFields = []string{}
+	}
+
+	if o.Templates == nil {
+		o.Templates = map[string]RecordTemplate{}
+	}
+
+	if o.Mirrors == nil {
+		o.Mirrors = []RecordMirrorOptions{}
+	}
+
+	return json.Marshal(alias(o))
+}
+
+// UnmarshalJSON implements the [json.Unmarshaler] interface.
+//
+// The submitted map options replace the existing ones (instead of
+// merging with them), so that their keys could be also removed.
+func (o *CollectionOptions) UnmarshalJSON(data []byte) error {
+	type alias CollectionOptions // prevent recursion
+
+	keys := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return err
+	}
+
+	if _, ok := keys["headers"]; ok {
+		o.Headers = nil
+	}
+
+	if _, ok := keys["aliases"]; ok {
+		o.Aliases = nil
+	}
+
+	if _, ok := keys["templates"]; ok {
+		o.Templates = nil
+	}
+
+	return json.Unmarshal(data, (*alias)(o))
+}
+
+// Value implements the [driver.Valuer] interface.
+func (o CollectionOptions) Value() (driver.Value, error) {
+	data, err := json.Marshal(o)
+
+	return string(data), err
+}
+
+// Scan implements [sql.Scanner] interface to scan the provided value
+// into the current CollectionOptions instance.
+func (o *CollectionOptions) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		// no cast needed
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("Failed to unmarshal CollectionOptions value %q.", value)
+	}
+
+	if len(data) == 0 {
+		data = []byte("{}")
+	}
+
+	return json.Unmarshal(data, o)
+}
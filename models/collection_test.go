@@ -1,9 +1,13 @@
 package models_test
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
 	"testing"
 
 	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
 )
 
 func TestCollectionTableName(t *testing.T) {
@@ -23,3 +27,392 @@ func TestCollectionBaseFilesPath(t *testing.T) {
 		t.Fatalf("Expected path %s, got %s", expected, m.BaseFilesPath())
 	}
 }
+
+func TestCollectionSchemaHash(t *testing.T) {
+	m1 := models.Collection{}
+	m1.Schema = schema.NewSchema(&schema.SchemaField{Id: "a1", Name: "title", Type: schema.FieldTypeText})
+
+	m2 := models.Collection{Name: "other"}
+	m2.Schema = schema.NewSchema(&schema.SchemaField{Id: "a1", Name: "title", Type: schema.FieldTypeText})
+
+	m3 := models.Collection{}
+	m3.Schema = schema.NewSchema(&schema.SchemaField{Id: "a1", Name: "title", Type: schema.FieldTypeText, Required: true})
+
+	hash := m1.SchemaHash()
+	if len(hash) != 16 {
+		t.Fatalf("Expected 16 chars hash, got %q", hash)
+	}
+
+	if hash != m1.SchemaHash() {
+		t.Fatalf("Expected the same hash on consecutive calls")
+	}
+
+	if hash != m2.SchemaHash() {
+		t.Fatalf("Expected the same hash for the same schema, got %q and %q", hash, m2.SchemaHash())
+	}
+
+	if hash == m3.SchemaHash() {
+		t.Fatalf("Expected different hash for different schema, got %q", hash)
+	}
+}
+
+func TestCollectionRelationIndexes(t *testing.T) {
+	m := models.Collection{}
+	m.Id = "a-b-c"
+	m.Schema = schema.NewSchema(
+		&schema.SchemaField{Id: "f1", Name: "title", Type: schema.FieldTypeText},
+		&schema.SchemaField{Id: "f2", Name: "one", Type: schema.FieldTypeRelation, Options: &schema.RelationOptions{MaxSelect: 1}},
+		&schema.SchemaField{Id: "f3", Name: "many", Type: schema.FieldTypeRelation, Options: &schema.RelationOptions{MaxSelect: 2}},
+		&schema.SchemaField{Id: "f-4", Name: "any", Type: schema.FieldTypeRelation, Options: &schema.RelationOptions{}},
+	)
+
+	if indexes := m.RelationIndexes(); len(indexes) != 0 {
+		t.Fatalf("Expected no indexes with disabled option, got %v", indexes)
+	}
+
+	m.Options.RelationIndexes = true
+
+	indexes := m.RelationIndexes()
+
+	expected := map[string]string{
+		"_ridx_abc_f2": "one",
+		"_ridx_abc_f4": "any",
+	}
+
+	if len(indexes) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, indexes)
+	}
+
+	for name, field := range expected {
+		if indexes[name] != field {
+			t.Fatalf("Expected index %q on field %q, got %v", name, field, indexes)
+		}
+	}
+}
+
+func TestCollectionMarshalJSON(t *testing.T) {
+	m := models.Collection{Name: "test"}
+	m.Id = "test_id"
+	m.Schema = schema.NewSchema(&schema.SchemaField{Id: "a1", Name: "title", Type: schema.FieldTypeText})
+
+	raw, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := map[string]any{}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		t.Fatal(err)
+	}
+
+	if result["id"] != "test_id" || result["name"] != "test" {
+		t.Fatalf("Expected the collection properties to be exported, got %s", raw)
+	}
+
+	if result["schemaHash"] != m.SchemaHash() {
+		t.Fatalf("Expected schemaHash %q, got %v", m.SchemaHash(), result["schemaHash"])
+	}
+
+	// the exported json should be loadable back
+	loaded := models.Collection{}
+	if err := json.Unmarshal(raw, &loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.SchemaHash() != m.SchemaHash() {
+		t.Fatalf("Expected the unmarshaled collection hash %q, got %q", m.SchemaHash(), loaded.SchemaHash())
+	}
+}
+
+func TestCollectionOptionsValidate(t *testing.T) {
+	scenarios := []struct {
+		options     models.CollectionOptions
+		expectError bool
+	}{
+		{models.CollectionOptions{}, false},
+		{models.CollectionOptions{Headers: map[string]string{"Cache-Control": "no-cache"}}, false},
+		{models.CollectionOptions{Headers: map[string]string{"Invalid Name": "no-cache"}}, true},
+		{models.CollectionOptions{Headers: map[string]string{"X-Test": ""}}, true},
+		{models.CollectionOptions{Headers: map[string]string{"X-Test": "a\r\nX-Injected: b"}}, true},
+		{models.CollectionOptions{Cache: models.RecordCacheOptions{Enabled: true}}, true},
+		{models.CollectionOptions{Cache: models.RecordCacheOptions{Enabled: true, MaxEntries: 10}}, true},
+		{models.CollectionOptions{Cache: models.RecordCacheOptions{Enabled: true, Ttl: 10}}, true},
+		{models.CollectionOptions{Cache: models.RecordCacheOptions{MaxEntries: -1, Ttl: -1}}, true},
+		{models.CollectionOptions{Cache: models.RecordCacheOptions{Enabled: true, MaxEntries: 10, Ttl: 10}}, false},
+		{models.CollectionOptions{Logs: models.RecordLogsOptions{SkipActions: []string{"invalid"}}}, true},
+		{models.CollectionOptions{Logs: models.RecordLogsOptions{Disabled: true, SkipActions: []string{"list", "view", "create", "update", "delete", "file"}}}, false},
+		{models.CollectionOptions{Counts: []models.RelationCountOptions{{}}}, true},
+		{models.CollectionOptions{Counts: []models.RelationCountOptions{{Name: "invalid name", CollectionId: "a", Field: "b"}}}, true},
+		{models.CollectionOptions{Counts: []models.RelationCountOptions{{Name: "test", Field: "b"}}}, true},
+		{models.CollectionOptions{Counts: []models.RelationCountOptions{{Name: "test", CollectionId: "a"}}}, true},
+		{models.CollectionOptions{Counts: []models.RelationCountOptions{
+			{Name: "test", CollectionId: "a", Field: "b"},
+			{Name: "test", CollectionId: "a", Field: "c"},
+		}}, true},
+		{models.CollectionOptions{Counts: []models.RelationCountOptions{
+			{Name: "test1", CollectionId: "a", Field: "b"},
+			{Name: "test2", CollectionId: "a", Field: "c"},
+		}}, false},
+		{models.CollectionOptions{Id: models.RecordIdOptions{Type: "invalid"}}, true},
+		{models.CollectionOptions{Id: models.RecordIdOptions{Type: models.RecordIdTypeSequence, Prefix: "INV 1"}}, true},
+		{models.CollectionOptions{Id: models.RecordIdOptions{Type: models.RecordIdTypeSequence, Padding: 21}}, true},
+		{models.CollectionOptions{Id: models.RecordIdOptions{Type: models.RecordIdTypeSequence, Prefix: "INV-", Padding: 5}}, false},
+		{models.CollectionOptions{Id: models.RecordIdOptions{Type: models.RecordIdTypeCustom}}, true},
+		{models.CollectionOptions{Id: models.RecordIdOptions{Type: models.RecordIdTypeCustom, Generator: "test"}}, false},
+		{models.CollectionOptions{Indexes: []models.CollectionIndex{{}}}, true},
+		{models.CollectionOptions{Indexes: []models.CollectionIndex{{Name: "invalid name", Fields: []string{"a"}}}}, true},
+		{models.CollectionOptions{Indexes: []models.CollectionIndex{{Name: "test", Fields: []string{}}}}, true},
+		{models.CollectionOptions{Indexes: []models.CollectionIndex{{Name: "test", Fields: []string{"a", "a"}}}}, true},
+		{models.CollectionOptions{Indexes: []models.CollectionIndex{{Name: "test", Fields: []string{"a"}, Where: "a = 1; DROP TABLE b"}}}, true},
+		{models.CollectionOptions{Indexes: []models.CollectionIndex{{Name: "test", Fields: []string{"a"}, Where: "a = 1 -- test"}}}, true},
+		{models.CollectionOptions{Indexes: []models.CollectionIndex{
+			{Name: "test", Fields: []string{"a"}},
+			{Name: "TEST", Fields: []string{"b"}},
+		}}, true},
+		{models.CollectionOptions{Indexes: []models.CollectionIndex{
+			{Name: "test1", Fields: []string{"a", "b"}, Unique: true},
+			{Name: "test2", Fields: []string{"b"}, Where: "b != ''"},
+		}}, false},
+		{models.CollectionOptions{Aliases: map[string]string{"a": "invalid alias"}}, true},
+		{models.CollectionOptions{Aliases: map[string]string{"a": "id"}}, true},
+		{models.CollectionOptions{Aliases: map[string]string{"a": "test", "b": "test"}}, true},
+		{models.CollectionOptions{Aliases: map[string]string{"a": "test1", "b": "test2"}}, false},
+		{models.CollectionOptions{AutoExpand: []string{""}}, true},
+		{models.CollectionOptions{AutoExpand: []string{"a..b"}}, true},
+		{models.CollectionOptions{AutoExpand: []string{"a", "invalid path"}}, true},
+		{models.CollectionOptions{AutoExpand: []string{"a", "a.b.c"}}, false},
+		{models.CollectionOptions{Publish: models.RecordPublishOptions{Enabled: true}}, true},
+		{models.CollectionOptions{Publish: models.RecordPublishOptions{Enabled: true, StatusField: "a", PublishedAtField: "a"}}, true},
+		{models.CollectionOptions{Publish: models.RecordPublishOptions{Enabled: true, StatusField: "a"}}, false},
+		{models.CollectionOptions{Publish: models.RecordPublishOptions{Enabled: true, StatusField: "a", PublishedAtField: "b"}}, false},
+		{models.CollectionOptions{Templates: map[string]models.RecordTemplate{"invalid name": {Format: "text", Content: "test"}}}, true},
+		{models.CollectionOptions{Templates: map[string]models.RecordTemplate{"test": {Format: "invalid", Content: "test"}}}, true},
+		{models.CollectionOptions{Templates: map[string]models.RecordTemplate{"test": {Format: "text"}}}, true},
+		{models.CollectionOptions{Templates: map[string]models.RecordTemplate{"test": {Format: "html", Content: "{{.record.title}"}}}, true},
+		{models.CollectionOptions{Templates: map[string]models.RecordTemplate{
+			"test1": {Format: "text", Content: "{{.record.title}}"},
+			"test2": {Format: "html", Content: "<p>{{.record.title}}</p>"},
+		}}, false},
+	}
+
+	for i, s := range scenarios {
+		err := s.options.Validate()
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr to be %v, got %v (%v)", i, s.expectError, hasErr, err)
+		}
+	}
+}
+
+func TestCollectionIndexTableIndexName(t *testing.T) {
+	collection := &models.Collection{}
+	collection.Id = "3f2888f8-075d-49fe-9d09-ea7e951000dc"
+
+	idx := models.CollectionIndex{Name: "test"}
+
+	expected := "_idx_3f2888f8075d49fe9d09ea7e951000dc_test"
+	if result := idx.TableIndexName(collection); result != expected {
+		t.Fatalf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestCollectionIndexEqual(t *testing.T) {
+	base := models.CollectionIndex{Name: "test", Fields: []string{"a", "b"}, Unique: true, Where: "a > 1"}
+
+	scenarios := []struct {
+		other    models.CollectionIndex
+		expected bool
+	}{
+		{models.CollectionIndex{Name: "test", Fields: []string{"a", "b"}, Unique: true, Where: "a > 1"}, true},
+		{models.CollectionIndex{Name: "test2", Fields: []string{"a", "b"}, Unique: true, Where: "a > 1"}, false},
+		{models.CollectionIndex{Name: "test", Fields: []string{"b", "a"}, Unique: true, Where: "a > 1"}, false},
+		{models.CollectionIndex{Name: "test", Fields: []string{"a", "b"}, Unique: false, Where: "a > 1"}, false},
+		{models.CollectionIndex{Name: "test", Fields: []string{"a", "b"}, Unique: true, Where: ""}, false},
+	}
+
+	for i, s := range scenarios {
+		if result := base.Equal(s.other); result != s.expected {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, result)
+		}
+	}
+}
+
+func TestCollectionOptionsApplyHeaders(t *testing.T) {
+	options := models.CollectionOptions{
+		Headers: map[string]string{
+			"Cache-Control":   "public, max-age=60",
+			"X-Frame-Options": "DENY",
+		},
+	}
+
+	h := http.Header{}
+	h.Set("X-Frame-Options", "SAMEORIGIN")
+
+	options.ApplyHeaders(h)
+
+	if v := h.Get("Cache-Control"); v != "public, max-age=60" {
+		t.Fatalf("Expected Cache-Control header to be set, got %q", v)
+	}
+
+	if v := h.Get("X-Frame-Options"); v != "SAMEORIGIN" {
+		t.Fatalf("Expected X-Frame-Options header to not be overwritten, got %q", v)
+	}
+}
+
+func TestRecordLogsOptionsIsSkipped(t *testing.T) {
+	scenarios := []struct {
+		options  models.RecordLogsOptions
+		action   string
+		expected bool
+	}{
+		{models.RecordLogsOptions{}, models.RecordActionList, false},
+		{models.RecordLogsOptions{Disabled: true}, models.RecordActionList, true},
+		{models.RecordLogsOptions{SkipActions: []string{"view", "list"}}, models.RecordActionList, true},
+		{models.RecordLogsOptions{SkipActions: []string{"view", "list"}}, models.RecordActionCreate, false},
+	}
+
+	for i, s := range scenarios {
+		if result := s.options.IsSkipped(s.action); result != s.expected {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, result)
+		}
+	}
+}
+
+func TestCollectionOptionsResolveAliases(t *testing.T) {
+	options := models.CollectionOptions{
+		Aliases: map[string]string{"username": "user_name", "title": "name"},
+	}
+
+	data := map[string]any{
+		"user_name": "test1",
+		"title":     "test2",
+		"name":      "test3", // the field name has priority
+		"other":     "test4",
+	}
+
+	result := options.ResolveAliases(data)
+
+	encoded, _ := json.Marshal(result)
+	expected := `{"other":"test4","title":"test2","username":"test1"}`
+	if string(encoded) != expected {
+		t.Fatalf("Expected %s, got %s", expected, encoded)
+	}
+
+	// the original data must remain unchanged
+	if len(data) != 4 {
+		t.Fatalf("Expected the original data to be unchanged, got %v", data)
+	}
+}
+
+func TestRecordTemplateRender(t *testing.T) {
+	collection := &models.Collection{
+		Name: "test",
+		Schema: schema.NewSchema(
+			&schema.SchemaField{Name: "title", Type: schema.FieldTypeText},
+		),
+	}
+
+	record1 := models.NewRecord(collection)
+	record1.Id = "a"
+	record1.SetDataValue("title", "<b>test1</b>")
+
+	record2 := models.NewRecord(collection)
+	record2.Id = "b"
+	record2.SetDataValue("title", "test2")
+
+	scenarios := []struct {
+		template       models.RecordTemplate
+		records        []*models.Record
+		expectError    bool
+		expectedOutput string
+	}{
+		{models.RecordTemplate{Format: "invalid", Content: "test"}, nil, true, ""},
+		{models.RecordTemplate{Format: "text", Content: "{{len .records}}"}, nil, false, "0"},
+		{models.RecordTemplate{Format: "text", Content: "{{.record.id}}:{{.record.title}}"}, []*models.Record{record1}, false, "a:<b>test1</b>"},
+		{models.RecordTemplate{Format: "html", Content: "<p>{{.record.title}}</p>"}, []*models.Record{record1}, false, "<p>&lt;b&gt;test1&lt;/b&gt;</p>"},
+		{models.RecordTemplate{Format: "text", Content: "{{range .records}}[{{.title}}]{{end}}"}, []*models.Record{record1, record2}, false, "[<b>test1</b>][test2]"},
+	}
+
+	for i, s := range scenarios {
+		output, err := s.template.Render(context.Background(), s.records...)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+			continue
+		}
+
+		if output != s.expectedOutput {
+			t.Errorf("(%d) Expected output %q, got %q", i, s.expectedOutput, output)
+		}
+	}
+}
+
+func TestCollectionOptionsMarshalJSON(t *testing.T) {
+	scenarios := []struct {
+		options  models.CollectionOptions
+		expected string
+	}{
+		{models.CollectionOptions{}, `{"headers":{},"cache":{"enabled":false,"maxEntries":0,"ttl":0},"counts":[],"logs":{"disabled":false,"skipActions":[]},"indexes":[],"aliases":{},"id":{"type":"","prefix":"","padding":0,"generator":""},"autoExpand":[],"archive":false,"estimatedCountThreshold":0,"publish":{"enabled":false,"statusField":"","publishedAtField":""},"listExcludedFields":[],"coalesceReads":false,"templates":{},"mirrors":[],"relationIndexes":false,"versions":false}`},
+		{models.CollectionOptions{Headers: map[string]string{"X-Test": "123"}}, `{"headers":{"X-Test":"123"},"cache":{"enabled":false,"maxEntries":0,"ttl":0},"counts":[],"logs":{"disabled":false,"skipActions":[]},"indexes":[],"aliases":{},"id":{"type":"","prefix":"","padding":0,"generator":""},"autoExpand":[],"archive":false,"estimatedCountThreshold":0,"publish":{"enabled":false,"statusField":"","publishedAtField":""},"listExcludedFields":[],"coalesceReads":false,"templates":{},"mirrors":[],"relationIndexes":false,"versions":false}`},
+	}
+
+	for i, s := range scenarios {
+		result, err := s.options.MarshalJSON()
+		if err != nil {
+			t.Errorf("(%d) %v", i, err)
+			continue
+		}
+
+		if string(result) != s.expected {
+			t.Errorf("(%d) Expected %s, got %s", i, s.expected, string(result))
+		}
+	}
+}
+
+func TestCollectionOptionsValue(t *testing.T) {
+	options := models.CollectionOptions{Headers: map[string]string{"X-Test": "123"}}
+
+	result, err := options.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"headers":{"X-Test":"123"},"cache":{"enabled":false,"maxEntries":0,"ttl":0},"counts":[],"logs":{"disabled":false,"skipActions":[]},"indexes":[],"aliases":{},"id":{"type":"","prefix":"","padding":0,"generator":""},"autoExpand":[],"archive":false,"estimatedCountThreshold":0,"publish":{"enabled":false,"statusField":"","publishedAtField":""},"listExcludedFields":[],"coalesceReads":false,"templates":{},"mirrors":[],"relationIndexes":false,"versions":false}`
+	if result != expected {
+		t.Fatalf("Expected %s, got %v", expected, result)
+	}
+}
+
+func TestCollectionOptionsScan(t *testing.T) {
+	scenarios := []struct {
+		value       any
+		expectError bool
+		expectJson  string
+	}{
+		{nil, false, `{"headers":{},"cache":{"enabled":false,"maxEntries":0,"ttl":0},"counts":[],"logs":{"disabled":false,"skipActions":[]},"indexes":[],"aliases":{},"id":{"type":"","prefix":"","padding":0,"generator":""},"autoExpand":[],"archive":false,"estimatedCountThreshold":0,"publish":{"enabled":false,"statusField":"","publishedAtField":""},"listExcludedFields":[],"coalesceReads":false,"templates":{},"mirrors":[],"relationIndexes":false,"versions":false}`},
+		{"", false, `{"headers":{},"cache":{"enabled":false,"maxEntries":0,"ttl":0},"counts":[],"logs":{"disabled":false,"skipActions":[]},"indexes":[],"aliases":{},"id":{"type":"","prefix":"","padding":0,"generator":""},"autoExpand":[],"archive":false,"estimatedCountThreshold":0,"publish":{"enabled":false,"statusField":"","publishedAtField":""},"listExcludedFields":[],"coalesceReads":false,"templates":{},"mirrors":[],"relationIndexes":false,"versions":false}`},
+		{[]byte(`{"headers":{"X-Test":"123"}}`), false, `{"headers":{"X-Test":"123"},"cache":{"enabled":false,"maxEntries":0,"ttl":0},"counts":[],"logs":{"disabled":false,"skipActions":[]},"indexes":[],"aliases":{},"id":{"type":"","prefix":"","padding":0,"generator":""},"autoExpand":[],"archive":false,"estimatedCountThreshold":0,"publish":{"enabled":false,"statusField":"","publishedAtField":""},"listExcludedFields":[],"coalesceReads":false,"templates":{},"mirrors":[],"relationIndexes":false,"versions":false}`},
+		{`{"headers":{"X-Test":"123"},"cache":{"enabled":true,"maxEntries":10,"ttl":5},"counts":[],"logs":{"disabled":false,"skipActions":[]},"indexes":[],"aliases":{},"id":{"type":"","prefix":"","padding":0,"generator":""},"autoExpand":[],"archive":false,"estimatedCountThreshold":0,"publish":{"enabled":false,"statusField":"","publishedAtField":""},"listExcludedFields":[],"coalesceReads":false,"templates":{},"mirrors":[],"relationIndexes":false,"versions":false}`, false, `{"headers":{"X-Test":"123"},"cache":{"enabled":true,"maxEntries":10,"ttl":5},"counts":[],"logs":{"disabled":false,"skipActions":[]},"indexes":[],"aliases":{},"id":{"type":"","prefix":"","padding":0,"generator":""},"autoExpand":[],"archive":false,"estimatedCountThreshold":0,"publish":{"enabled":false,"statusField":"","publishedAtField":""},"listExcludedFields":[],"coalesceReads":false,"templates":{},"mirrors":[],"relationIndexes":false,"versions":false}`},
+		{"invalid", true, `{"headers":{},"cache":{"enabled":false,"maxEntries":0,"ttl":0},"counts":[],"logs":{"disabled":false,"skipActions":[]},"indexes":[],"aliases":{},"id":{"type":"","prefix":"","padding":0,"generator":""},"autoExpand":[],"archive":false,"estimatedCountThreshold":0,"publish":{"enabled":false,"statusField":"","publishedAtField":""},"listExcludedFields":[],"coalesceReads":false,"templates":{},"mirrors":[],"relationIndexes":false,"versions":false}`},
+		{123, true, `{"headers":{},"cache":{"enabled":false,"maxEntries":0,"ttl":0},"counts":[],"logs":{"disabled":false,"skipActions":[]},"indexes":[],"aliases":{},"id":{"type":"","prefix":"","padding":0,"generator":""},"autoExpand":[],"archive":false,"estimatedCountThreshold":0,"publish":{"enabled":false,"statusField":"","publishedAtField":""},"listExcludedFields":[],"coalesceReads":false,"templates":{},"mirrors":[],"relationIndexes":false,"versions":false}`},
+	}
+
+	for i, s := range scenarios {
+		options := models.CollectionOptions{}
+
+		err := options.Scan(s.value)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+			continue
+		}
+
+		encoded, _ := options.MarshalJSON()
+		if string(encoded) != s.expectJson {
+			t.Errorf("(%d) Expected %s, got %s", i, s.expectJson, string(encoded))
+		}
+	}
+}
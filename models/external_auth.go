@@ -0,0 +1,16 @@
+package models
+
+var _ Model = (*ExternalAuth)(nil)
+
+// ExternalAuth defines a single OAuth2 identity linked to a User model.
+type ExternalAuth struct {
+	BaseModel
+
+	UserId     string `db:"userId" json:"userId"`
+	Provider   string `db:"provider" json:"provider"`
+	ProviderId string `db:"providerId" json:"providerId"`
+}
+
+func (m *ExternalAuth) TableName() string {
+	return "_externalAuths"
+}
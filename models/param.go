@@ -7,7 +7,9 @@ import (
 var _ Model = (*Param)(nil)
 
 const (
-	ParamAppSettings = "settings"
+	ParamAppSettings  = "settings"
+	ParamAppInstance  = "instance"
+	ParamStaleMirrors = "staleMirrors"
 )
 
 type Param struct {
@@ -17,12 +17,35 @@ import (
 var _ Model = (*Record)(nil)
 var _ ColumnValueMapper = (*Record)(nil)
 
+// LocaleAll is the special locale preference that exports
+// the localized fields with all their locale values.
+const LocaleAll = "*"
+
 type Record struct {
 	BaseModel
 
 	collection *Collection
 	data       map[string]any
+	original   map[string]any
 	expand     map[string]any
+	counts     map[string]int
+
+	// number of the not expanded relation records per relation field
+	// (aka. records that are inaccessible for the requester or missing)
+	expandFiltered map[string]int
+
+	// localized fields export preferences (see SetLocale)
+	localized bool
+	locales   []string
+
+	// exported schema fields preferences (see SetExportFields)
+	exportFields []string
+
+	// exported dates timezone (see SetExportLocation)
+	exportLocation *time.Location
+
+	// empty single relations export preference (see SetEmptyRelationAsString)
+	emptyRelationAsString bool
 }
 
 // NewRecord initializes a new empty Record model.
@@ -44,6 +67,11 @@ func NewRecordFromNullStringMap(collection *Collection, data dbx.NullStringMap)
 		nullString, ok := data[field.Name]
 		if !ok || !nullString.Valid {
 			rawValue = nil
+		} else if field.Type == schema.FieldTypeDecimal {
+			// decimals are stored as scaled integers
+			field.InitOptions()
+			options, _ := field.Options.(*schema.DecimalOptions)
+			rawValue = options.Unscaled(nullString.String)
 		} else {
 			rawValue = nullString.String
 		}
@@ -62,6 +90,8 @@ func NewRecordFromNullStringMap(collection *Collection, data dbx.NullStringMap)
 		log.Println("Failed to unmarshal record:", err)
 	}
 
+	record.RefreshOriginalData()
+
 	return record
 }
 
@@ -98,11 +128,122 @@ func (m *Record) SetExpand(data map[string]any) {
 	m.expand = shallowCopy(data)
 }
 
+// GetCounts returns a shallow copy of the optional back-relation `counts` data
+// attached to the current Record model.
+func (m *Record) GetCounts() map[string]int {
+	if m.counts == nil {
+		return nil
+	}
+
+	result := make(map[string]int, len(m.counts))
+	for k, v := range m.counts {
+		result[k] = v
+	}
+
+	return result
+}
+
+// SetCounts assigns the provided back-relation counts to `record.counts`.
+func (m *Record) SetCounts(counts map[string]int) {
+	m.counts = make(map[string]int, len(counts))
+	for k, v := range counts {
+		m.counts[k] = v
+	}
+}
+
+// GetExpandFiltered returns a shallow copy of the optional number of
+// the filtered out (not expanded) relation records per relation field.
+func (m *Record) GetExpandFiltered() map[string]int {
+	if m.expandFiltered == nil {
+		return nil
+	}
+
+	result := make(map[string]int, len(m.expandFiltered))
+	for k, v := range m.expandFiltered {
+		result[k] = v
+	}
+
+	return result
+}
+
+// SetExpandFiltered assigns the provided number of the filtered out
+// relation records per relation field to `record.expandFiltered`.
+func (m *Record) SetExpandFiltered(filtered map[string]int) {
+	m.expandFiltered = make(map[string]int, len(filtered))
+	for k, v := range filtered {
+		m.expandFiltered[k] = v
+	}
+}
+
+// SetLocale sets the preferred locales used to export the localized
+// fields with only a single locale value
+// (see [schema.LocalizedOptions.ResolveLocale]).
+//
+// Calling it without arguments exports the fields default locale value.
+// By default (or if one of the locales is [LocaleAll]) the localized
+// fields are exported with all their locale values.
+func (m *Record) SetLocale(locales ...string) {
+	m.localized = !list.ExistInSlice(LocaleAll, locales)
+	m.locales = locales
+}
+
+// SetExportFields limits the schema fields exported with PublicExport
+// to the provided field names (the system fields are always exported).
+//
+// Calling it without arguments exports only the system fields.
+// By default (or if called with nil) all schema fields are exported.
+func (m *Record) SetExportFields(fields []string) {
+	if fields == nil {
+		m.exportFields = nil
+		return
+	}
+
+	m.exportFields = append([]string{}, fields...)
+}
+
+// SetExportLocation sets the timezone location used to export the
+// record date fields (including created and updated) with
+// explicit UTC offset, eg. "2022-05-01 02:00:00.000-04:00".
+//
+// By default (or if called with nil) the dates are exported in UTC.
+func (m *Record) SetExportLocation(loc *time.Location) {
+	m.exportLocation = loc
+}
+
+// SetEmptyRelationAsString changes the PublicExport value of the empty
+// single relation fields to empty string (by default they are exported as null).
+//
+// The empty multiple relation fields are always exported as empty array.
+func (m *Record) SetEmptyRelationAsString(enable bool) {
+	m.emptyRelationAsString = enable
+}
+
 // Data returns a shallow copy of the currently loaded record's data.
 func (m *Record) Data() map[string]any {
 	return shallowCopy(m.data)
 }
 
+// OriginalData returns a shallow copy of the record's data as it was
+// when the record was loaded from or last persisted in the db.
+//
+// Returns nil if the record doesn't have a tracked original state
+// (eg. for a new record initialized with NewRecord).
+func (m *Record) OriginalData() map[string]any {
+	if m.original == nil {
+		return nil
+	}
+
+	return shallowCopy(m.original)
+}
+
+// RefreshOriginalData replaces the record's original data with
+// a snapshot of the currently loaded data.
+//
+// It is usually called after successfully persisting the record.
+func (m *Record) RefreshOriginalData() {
+	m.original = shallowCopy(m.data)
+}
+
 // SetDataValue sets the provided key-value data pair for the current Record model.
 //
 // This method does nothing if the record doesn't have a `key` field.
@@ -211,6 +352,16 @@ func (m *Record) ColumnValueMap() map[string]any {
 		result[key] = m.normalizeDataValueForDB(key)
 	}
 
+	// explicitly insert the unset nullable fields as NULL
+	// (their column default could be from before the field was nullable)
+	if m.IsNew() {
+		for _, field := range m.collection.Schema.Fields() {
+			if _, ok := result[field.Name]; !ok && field.IsNullable() {
+				result[field.Name] = nil
+			}
+		}
+	}
+
 	// set base model fields
 	result[schema.ReservedFieldNameId] = m.Id
 	result[schema.ReservedFieldNameCreated] = m.Created
@@ -225,11 +376,98 @@ func (m *Record) ColumnValueMap() map[string]any {
 func (m *Record) PublicExport() map[string]any {
 	result := skipHiddenFields(m.data)
 
+	// export only the preferred schema fields
+	if m.exportFields != nil {
+		for key := range result {
+			if !list.ExistInSlice(key, m.exportFields) {
+				delete(result, key)
+			}
+		}
+	}
+
+	// export only the preferred locale value of the localized fields
+	if m.localized {
+		for _, field := range m.collection.Schema.Fields() {
+			if field.Type != schema.FieldTypeLocalized {
+				continue
+			}
+
+			if _, ok := result[field.Name]; !ok {
+				continue // hidden field
+			}
+
+			field.InitOptions()
+			options, _ := field.Options.(*schema.LocalizedOptions)
+			values, _ := result[field.Name].(types.JsonMap)
+
+			value := cast.ToString(values[options.ResolveLocale(m.locales)])
+			if value == "" {
+				value = cast.ToString(values[options.DefaultLocale])
+			}
+			result[field.Name] = value
+		}
+	}
+
+	// normalize the empty relation fields
+	for _, field := range m.collection.Schema.Fields() {
+		if field.Type != schema.FieldTypeRelation || strings.HasPrefix(field.Name, "#") {
+			continue
+		}
+
+		if m.exportFields != nil && !list.ExistInSlice(field.Name, m.exportFields) {
+			continue
+		}
+
+		field.InitOptions()
+		options, _ := field.Options.(*schema.RelationOptions)
+
+		ids := list.ToUniqueStringSlice(result[field.Name])
+
+		if options.MaxSelect > 1 {
+			result[field.Name] = ids
+		} else if len(ids) > 0 {
+			result[field.Name] = ids[0]
+		} else if m.emptyRelationAsString {
+			result[field.Name] = ""
+		} else {
+			result[field.Name] = nil
+		}
+	}
+
+	// rename the aliased fields
+	for name, alias := range m.collection.Options.Aliases {
+		if v, ok := result[name]; ok {
+			delete(result, name)
+			result[alias] = v
+		}
+	}
+
 	// set base model fields
 	result[schema.ReservedFieldNameId] = m.Id
 	result[schema.ReservedFieldNameCreated] = m.Created
 	result[schema.ReservedFieldNameUpdated] = m.Updated
 
+	// export the dates in the preferred timezone
+	if m.exportLocation != nil {
+		dateFields := []string{schema.ReservedFieldNameCreated, schema.ReservedFieldNameUpdated}
+		for _, field := range m.collection.Schema.Fields() {
+			if field.Type != schema.FieldTypeDate {
+				continue
+			}
+			if alias := m.collection.Options.Aliases[field.Name]; alias != "" {
+				dateFields = append(dateFields, alias)
+			} else {
+				dateFields = append(dateFields, field.Name)
+			}
+		}
+
+		for _, name := range dateFields {
+			if date, ok := result[name].(types.DateTime); ok && !date.IsZero() {
+				result[name] = date.Time().In(m.exportLocation).Format(types.DefaultDateLayout + "Z07:00")
+			}
+		}
+	}
+
 	// add helper collection fields
 	result["@collectionId"] = m.collection.Id
 	result["@collectionName"] = m.collection.Name
@@ -239,6 +477,16 @@ func (m *Record) PublicExport() map[string]any {
 		result["@expand"] = m.expand
 	}
 
+	// add the filtered out expand relations indicator (if any)
+	if len(m.expandFiltered) > 0 {
+		result["@expandFiltered"] = m.expandFiltered
+	}
+
+	// add back-relation counts (if set)
+	if m.counts != nil {
+		result["@counts"] = m.counts
+	}
+
 	return result
 }
 
@@ -257,6 +505,10 @@ func (m *Record) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
+	if m.collection != nil {
+		result = m.collection.Options.ResolveAliases(result)
+	}
+
 	return m.Load(result)
 }
 
@@ -264,6 +516,14 @@ func (m *Record) UnmarshalJSON(data []byte) error {
 func (m *Record) normalizeDataValueForDB(key string) any {
 	val := m.GetDataValue(key)
 
+	if field := m.collection.Schema.GetFieldByName(key); field != nil && field.Type == schema.FieldTypeDecimal && val != nil {
+		// store the decimal as scaled integer
+		field.InitOptions()
+		options, _ := field.Options.(*schema.DecimalOptions)
+		scaled, _ := options.Scaled(val)
+		return scaled
+	}
+
 	switch ids := val.(type) {
 	case []string:
 		// encode strings slice
@@ -3,6 +3,7 @@ package models_test
 import (
 	"database/sql"
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
@@ -232,6 +233,27 @@ func TestRecordExpand(t *testing.T) {
 	}
 }
 
+func TestRecordCounts(t *testing.T) {
+	collection := &models.Collection{}
+	m := models.NewRecord(collection)
+
+	if m.GetCounts() != nil {
+		t.Fatalf("Expected nil counts, got %v", m.GetCounts())
+	}
+
+	data := map[string]int{"test": 123}
+
+	m.SetCounts(data)
+
+	// change the original data to check if it was copied
+	data["test"] = 456
+
+	counts := m.GetCounts()
+	if v, ok := counts["test"]; !ok || v != 123 {
+		t.Fatalf("Expected counts.test to be %v, got %v", 123, v)
+	}
+}
+
 func TestRecordLoadAndData(t *testing.T) {
 	collection := &models.Collection{
 		Schema: schema.NewSchema(
@@ -270,6 +292,44 @@ func TestRecordLoadAndData(t *testing.T) {
 	}
 }
 
+func TestRecordOriginalData(t *testing.T) {
+	collection := &models.Collection{
+		Schema: schema.NewSchema(
+			&schema.SchemaField{
+				Name: "field",
+				Type: schema.FieldTypeText,
+			},
+		),
+	}
+
+	// new record
+	m := models.NewRecord(collection)
+	if m.OriginalData() != nil {
+		t.Fatalf("Expected nil original data, got %v", m.OriginalData())
+	}
+
+	// loaded from db
+	m = models.NewRecordFromNullStringMap(collection, dbx.NullStringMap{
+		"id":    sql.NullString{String: "11111111-d07e-4fbe-86b3-b8ac31982e9a", Valid: true},
+		"field": sql.NullString{String: "test", Valid: true},
+	})
+	m.SetDataValue("field", "new_test")
+
+	encoded, _ := json.Marshal(m.OriginalData())
+	if expected := `{"field":"test"}`; string(encoded) != expected {
+		t.Fatalf("Expected original data %v, got \n%v", expected, string(encoded))
+	}
+
+	// refreshed
+	m.RefreshOriginalData()
+	m.SetDataValue("field", "another_test")
+
+	encoded, _ = json.Marshal(m.OriginalData())
+	if expected := `{"field":"new_test"}`; string(encoded) != expected {
+		t.Fatalf("Expected original data %v, got \n%v", expected, string(encoded))
+	}
+}
+
 func TestRecordSetDataValue(t *testing.T) {
 	collection := &models.Collection{
 		Schema: schema.NewSchema(
@@ -758,6 +818,7 @@ func TestRecordPublicExport(t *testing.T) {
 	m.SetDataValue("field2", "test.png")
 	m.SetDataValue("#field3", []string{"test1", "test2"})
 	m.SetExpand(map[string]any{"test": 123})
+	m.SetCounts(map[string]int{"test": 1})
 
 	result := m.PublicExport()
 
@@ -766,11 +827,317 @@ func TestRecordPublicExport(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	expected := `{"@collectionId":"","@collectionName":"test","@expand":{"test":123},"created":"2022-01-01 10:00:30.123","field1":"test","field2":"test.png","id":"210a896c-1e32-4c94-ae06-90c25fcf6791","updated":""}`
+	expected := `{"@collectionId":"","@collectionName":"test","@counts":{"test":1},"@expand":{"test":123},"created":"2022-01-01 10:00:30.123","field1":"test","field2":"test.png","id":"210a896c-1e32-4c94-ae06-90c25fcf6791","updated":""}`
+
+	if string(encoded) != expected {
+		t.Fatalf("Expected %v, got \n%v", expected, string(encoded))
+	}
+}
+
+func TestRecordPublicExportWithAliases(t *testing.T) {
+	collection := &models.Collection{
+		Name: "test",
+		Schema: schema.NewSchema(
+			&schema.SchemaField{
+				Name: "field1",
+				Type: schema.FieldTypeText,
+			},
+			&schema.SchemaField{
+				Name: "field2",
+				Type: schema.FieldTypeText,
+			},
+		),
+		Options: models.CollectionOptions{
+			Aliases: map[string]string{"field1": "alias1"},
+		},
+	}
+
+	m := models.NewRecord(collection)
+	m.Id = "210a896c-1e32-4c94-ae06-90c25fcf6791"
+	m.SetDataValue("field1", "test1")
+	m.SetDataValue("field2", "test2")
 
+	encoded, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"@collectionId":"","@collectionName":"test","alias1":"test1","created":"","field2":"test2","id":"210a896c-1e32-4c94-ae06-90c25fcf6791","updated":""}`
 	if string(encoded) != expected {
 		t.Fatalf("Expected %v, got \n%v", expected, string(encoded))
 	}
+
+	// unmarshal should resolve the alias back to the field name
+	m2 := models.NewRecord(collection)
+	if err := json.Unmarshal(encoded, m2); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := m2.GetStringDataValue("field1"); v != "test1" {
+		t.Fatalf("Expected field1 to be test1, got %q", v)
+	}
+}
+
+func TestRecordSetExportFields(t *testing.T) {
+	collection := &models.Collection{
+		Name: "test",
+		Schema: schema.NewSchema(
+			&schema.SchemaField{
+				Name: "field1",
+				Type: schema.FieldTypeText,
+			},
+			&schema.SchemaField{
+				Name: "field2",
+				Type: schema.FieldTypeText,
+			},
+		),
+		Options: models.CollectionOptions{
+			Aliases: map[string]string{"field1": "alias1"},
+		},
+	}
+
+	m := models.NewRecord(collection)
+	m.Id = "210a896c-1e32-4c94-ae06-90c25fcf6791"
+	m.SetDataValue("field1", "test1")
+	m.SetDataValue("field2", "test2")
+
+	scenarios := []struct {
+		fields   []string
+		expected string
+	}{
+		{
+			nil,
+			`{"@collectionId":"","@collectionName":"test","alias1":"test1","created":"","field2":"test2","id":"210a896c-1e32-4c94-ae06-90c25fcf6791","updated":""}`,
+		},
+		{
+			[]string{},
+			`{"@collectionId":"","@collectionName":"test","created":"","id":"210a896c-1e32-4c94-ae06-90c25fcf6791","updated":""}`,
+		},
+		{
+			[]string{"field1", "missing"},
+			`{"@collectionId":"","@collectionName":"test","alias1":"test1","created":"","id":"210a896c-1e32-4c94-ae06-90c25fcf6791","updated":""}`,
+		},
+		{
+			[]string{"field2"},
+			`{"@collectionId":"","@collectionName":"test","created":"","field2":"test2","id":"210a896c-1e32-4c94-ae06-90c25fcf6791","updated":""}`,
+		},
+	}
+
+	for i, s := range scenarios {
+		m.SetExportFields(s.fields)
+
+		encoded, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(encoded) != s.expected {
+			t.Errorf("(%d) Expected %v, got \n%v", i, s.expected, string(encoded))
+		}
+	}
+}
+
+func TestRecordSetExportLocation(t *testing.T) {
+	collection := &models.Collection{
+		Name: "test",
+		Schema: schema.NewSchema(
+			&schema.SchemaField{
+				Name: "field1",
+				Type: schema.FieldTypeDate,
+			},
+			&schema.SchemaField{
+				Name: "field2",
+				Type: schema.FieldTypeDate,
+			},
+			&schema.SchemaField{
+				Name: "field3",
+				Type: schema.FieldTypeText,
+			},
+		),
+		Options: models.CollectionOptions{
+			Aliases: map[string]string{"field1": "alias1"},
+		},
+	}
+
+	created, _ := types.ParseDateTime("2022-05-01 02:00:00.123")
+
+	m := models.NewRecord(collection)
+	m.Id = "210a896c-1e32-4c94-ae06-90c25fcf6791"
+	m.Created = created
+	m.SetDataValue("field1", "2022-11-06 06:30:00.000")
+	m.SetDataValue("field3", "2022-05-01 02:00:00.000")
+
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		loc      *time.Location
+		expected string
+	}{
+		{
+			nil,
+			`{"@collectionId":"","@collectionName":"test","alias1":"2022-11-06 06:30:00.000","created":"2022-05-01 02:00:00.123","field3":"2022-05-01 02:00:00.000","id":"210a896c-1e32-4c94-ae06-90c25fcf6791","updated":""}`,
+		},
+		{
+			newYork,
+			`{"@collectionId":"","@collectionName":"test","alias1":"2022-11-06 01:30:00.000-05:00","created":"2022-04-30 22:00:00.123-04:00","field3":"2022-05-01 02:00:00.000","id":"210a896c-1e32-4c94-ae06-90c25fcf6791","updated":""}`,
+		},
+	}
+
+	for i, s := range scenarios {
+		m.SetExportLocation(s.loc)
+
+		encoded, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(encoded) != s.expected {
+			t.Errorf("(%d) Expected %v, got \n%v", i, s.expected, string(encoded))
+		}
+	}
+}
+
+func TestRecordPublicExportEmptyRelations(t *testing.T) {
+	collection := &models.Collection{
+		Name: "test",
+		Schema: schema.NewSchema(
+			&schema.SchemaField{
+				Name:    "single",
+				Type:    schema.FieldTypeRelation,
+				Options: &schema.RelationOptions{MaxSelect: 1},
+			},
+			&schema.SchemaField{
+				Name:    "multiple",
+				Type:    schema.FieldTypeRelation,
+				Options: &schema.RelationOptions{MaxSelect: 2},
+			},
+			&schema.SchemaField{
+				Name:    "#hidden",
+				Type:    schema.FieldTypeRelation,
+				Options: &schema.RelationOptions{MaxSelect: 1},
+			},
+		),
+	}
+
+	scenarios := []struct {
+		data          map[string]any
+		asString      bool
+		expectedPairs []string
+	}{
+		// missing values
+		{
+			map[string]any{},
+			false,
+			[]string{`"single":null`, `"multiple":[]`},
+		},
+		{
+			map[string]any{},
+			true,
+			[]string{`"single":""`, `"multiple":[]`},
+		},
+		// empty values
+		{
+			map[string]any{"single": "", "multiple": ""},
+			false,
+			[]string{`"single":null`, `"multiple":[]`},
+		},
+		{
+			map[string]any{"single": nil, "multiple": nil},
+			true,
+			[]string{`"single":""`, `"multiple":[]`},
+		},
+		// non-empty values
+		{
+			map[string]any{"single": "a", "multiple": []string{"b", "c"}},
+			true,
+			[]string{`"single":"a"`, `"multiple":["b","c"]`},
+		},
+	}
+
+	for i, s := range scenarios {
+		m := models.NewRecord(collection)
+		m.Load(s.data)
+		m.SetEmptyRelationAsString(s.asString)
+
+		encoded, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encodedStr := string(encoded)
+
+		for _, pair := range s.expectedPairs {
+			if !strings.Contains(encodedStr, pair) {
+				t.Errorf("(%d) Expected %s in \n%v", i, pair, encodedStr)
+			}
+		}
+
+		if strings.Contains(encodedStr, "hidden") {
+			t.Errorf("(%d) Didn't expect the hidden field to be exported, got \n%v", i, encodedStr)
+		}
+
+		// the serialized value should round-trip
+		m2 := models.NewRecord(collection)
+		if err := json.Unmarshal(encoded, m2); err != nil {
+			t.Fatal(err)
+		}
+		encoded2, _ := json.Marshal(m2)
+		if !s.asString && string(encoded2) != encodedStr {
+			t.Errorf("(%d) Expected the unmarshaled record to serialize as \n%v, got \n%v", i, encodedStr, string(encoded2))
+		}
+		if m2.GetStringDataValue("single") != m.GetStringDataValue("single") {
+			t.Errorf("(%d) Expected single %q, got %q", i, m.GetStringDataValue("single"), m2.GetStringDataValue("single"))
+		}
+	}
+}
+
+func TestRecordPublicExportLocalized(t *testing.T) {
+	collection := &models.Collection{
+		Name: "test",
+		Schema: schema.NewSchema(
+			&schema.SchemaField{
+				Name: "field1",
+				Type: schema.FieldTypeLocalized,
+				Options: &schema.LocalizedOptions{
+					Locales:       []string{"en", "bg", "de"},
+					DefaultLocale: "en",
+				},
+			},
+		),
+	}
+
+	m := models.NewRecord(collection)
+	m.Id = "210a896c-1e32-4c94-ae06-90c25fcf6791"
+	m.SetDataValue("field1", `{"en":"test_en","bg":"test_bg"}`)
+
+	scenarios := []struct {
+		locales  []string
+		expected string
+	}{
+		{nil, `{"bg":"test_bg","en":"test_en"}`},
+		{[]string{}, `"test_en"`},
+		{[]string{"bg"}, `"test_bg"`},
+		{[]string{"fr", "bg-BG"}, `"test_bg"`},
+		{[]string{"de"}, `"test_en"`}, // fallback to the default locale value
+		{[]string{"bg", models.LocaleAll}, `{"bg":"test_bg","en":"test_en"}`},
+	}
+
+	for i, s := range scenarios {
+		if s.locales != nil {
+			m.SetLocale(s.locales...)
+		}
+
+		encoded, err := json.Marshal(m.PublicExport()["field1"])
+		if err != nil {
+			t.Fatalf("(%d) %v", i, err)
+		}
+
+		if string(encoded) != s.expected {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, string(encoded))
+		}
+	}
 }
 
 func TestRecordMarshalJSON(t *testing.T) {
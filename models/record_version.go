@@ -0,0 +1,34 @@
+package models
+
+import "github.com/pocketbase/pocketbase/tools/types"
+
+var _ Model = (*RecordVersion)(nil)
+
+// RecordVersion defines a single previous state of an updated record
+// of a collection with enabled versions option.
+//
+// To bound the storage size, only the values of the fields changed by
+// the update are stored (see [daos.Dao.FindRecordVersionState] for
+// reconstructing the full version state).
+type RecordVersion struct {
+	BaseModel
+
+	CollectionId string `db:"collectionId" json:"collectionId"`
+	RecordId     string `db:"recordId" json:"recordId"`
+
+	// Version is the record version number (starting from 1).
+	Version int `db:"version" json:"version"`
+
+	// Data is the version values of the fields changed by the update
+	// (including the base updated field).
+	Data types.JsonMap `db:"data" json:"data"`
+
+	// UpdatedBy is the identifier of the auth model whose update replaced
+	// the version state (eg. "admin:ID", "user:ID") or empty string
+	// for guests and unknown clients.
+	UpdatedBy string `db:"updatedBy" json:"updatedBy"`
+}
+
+func (m *RecordVersion) TableName() string {
+	return "_recordVersions"
+}
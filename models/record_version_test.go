@@ -0,0 +1,14 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/models"
+)
+
+func TestRecordVersionTableName(t *testing.T) {
+	m := models.RecordVersion{}
+	if m.TableName() != "_recordVersions" {
+		t.Fatalf("Unexpected table name, got %q", m.TableName())
+	}
+}
@@ -3,7 +3,10 @@ package schema
 import (
 	"encoding/json"
 	"errors"
+	"math/big"
 	"regexp"
+	"strconv"
+	"strings"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
@@ -43,6 +46,19 @@ const (
 	FieldTypeFile     string = "file"
 	FieldTypeRelation string = "relation"
 	FieldTypeUser     string = "user"
+
+	// FieldTypeLocalized is a text field that stores
+	// a separate value for each of its locales.
+	FieldTypeLocalized string = "localized"
+
+	// FieldTypeSlug is an unique URL-safe text field that is
+	// auto generated from another record field (if not manually set).
+	FieldTypeSlug string = "slug"
+
+	// FieldTypeDecimal is a fixed-point number field that is stored
+	// as scaled integer (eg. 9.99 with scale 2 is stored as 999)
+	// to allow exact comparisons and sorting.
+	FieldTypeDecimal string = "decimal"
 )
 
 // FieldTypes returns slice with all supported field types.
@@ -59,6 +75,9 @@ func FieldTypes() []string {
 		FieldTypeFile,
 		FieldTypeRelation,
 		FieldTypeUser,
+		FieldTypeLocalized,
+		FieldTypeSlug,
+		FieldTypeDecimal,
 	}
 }
 
@@ -87,16 +106,49 @@ type SchemaField struct {
 func (f *SchemaField) ColDefinition() string {
 	switch f.Type {
 	case FieldTypeNumber:
+		f.InitOptions()
+		options, _ := f.Options.(*NumberOptions)
+		if options != nil && options.Nullable {
+			return "REAL DEFAULT NULL"
+		}
 		return "REAL DEFAULT 0"
+	case FieldTypeDecimal:
+		return "INTEGER DEFAULT 0"
 	case FieldTypeBool:
+		if f.IsNullable() {
+			return "Boolean DEFAULT NULL"
+		}
 		return "Boolean DEFAULT FALSE"
-	case FieldTypeJson:
+	case FieldTypeDate:
+		if f.IsNullable() {
+			return "TEXT DEFAULT NULL"
+		}
+		return "TEXT DEFAULT ''"
+	case FieldTypeJson, FieldTypeLocalized:
 		return "JSON DEFAULT NULL"
 	default:
 		return "TEXT DEFAULT ''"
 	}
 }
 
+// IsNullable reports whether the field stores its unset value as NULL
+// instead of its type zero value (see the number, bool and date
+// fields Nullable option).
+func (f *SchemaField) IsNullable() bool {
+	f.InitOptions()
+
+	switch options := f.Options.(type) {
+	case *NumberOptions:
+		return options.Nullable
+	case *BoolOptions:
+		return options.Nullable
+	case *DateOptions:
+		return options.Nullable
+	default:
+		return false
+	}
+}
+
 // String serializes and returns the current field as string.
 func (f SchemaField) String() string {
 	data, _ := f.MarshalJSON()
@@ -149,7 +201,7 @@ func (f SchemaField) Validate() error {
 		validation.Field(&f.Type, validation.Required, validation.In(list.ToInterfaceSlice(FieldTypes())...)),
 		// currently file fields cannot be unique because a proper
 		// hash/content check could cause performance issues
-		validation.Field(&f.Unique, validation.When(f.Type == FieldTypeFile, validation.Empty)),
+		validation.Field(&f.Unique, validation.When(f.Type == FieldTypeFile || f.Type == FieldTypeLocalized, validation.Empty)),
 	)
 }
 
@@ -199,6 +251,12 @@ func (f *SchemaField) InitOptions() error {
 		options = &RelationOptions{}
 	case FieldTypeUser:
 		options = &UserOptions{}
+	case FieldTypeLocalized:
+		options = &LocalizedOptions{}
+	case FieldTypeSlug:
+		options = &SlugOptions{}
+	case FieldTypeDecimal:
+		options = &DecimalOptions{}
 	default:
 		return errors.New("Missing or unknown field field type.")
 	}
@@ -218,7 +276,7 @@ func (f *SchemaField) PrepareValue(value any) any {
 	f.InitOptions()
 
 	switch f.Type {
-	case FieldTypeText, FieldTypeEmail, FieldTypeUrl: // string
+	case FieldTypeText, FieldTypeEmail, FieldTypeUrl, FieldTypeSlug: // string
 		if value == nil {
 			return nil
 		}
@@ -230,17 +288,23 @@ func (f *SchemaField) PrepareValue(value any) any {
 		val, _ := types.ParseJsonRaw(value)
 		return val
 	case FieldTypeNumber: // nil, int or float
-		if value == nil {
+		if value == nil || (f.IsNullable() && value == "") {
 			return nil
 		}
 		return cast.ToFloat64(value)
-	case FieldTypeBool: // bool
+	case FieldTypeBool: // nil or bool
+		if f.IsNullable() && (value == nil || value == "") {
+			return nil
+		}
 		return cast.ToBool(value)
 	case FieldTypeDate: // string, DateTime or time.Time
 		if value == nil {
 			return nil
 		}
 		val, _ := types.ParseDateTime(value)
+		if f.IsNullable() && val.IsZero() {
+			return nil
+		}
 		return val
 	case FieldTypeSelect: // nil, string or slice of strings
 		val := list.ToUniqueStringSlice(value)
@@ -290,6 +354,20 @@ func (f *SchemaField) PrepareValue(value any) any {
 		}
 
 		return ids
+	case FieldTypeLocalized: // nil, map, json string or plain default locale string
+		options, _ := f.Options.(*LocalizedOptions)
+		return normalizeLocalized(options, value)
+	case FieldTypeDecimal: // nil, int, float or decimal string
+		if value == nil {
+			return nil
+		}
+
+		options, _ := f.Options.(*DecimalOptions)
+		if options == nil {
+			options = &DecimalOptions{}
+		}
+
+		return options.Format(value)
 	default:
 		return value // unmodified
 	}
@@ -339,6 +417,10 @@ func (o *TextOptions) checkRegex(value any) error {
 type NumberOptions struct {
 	Min *float64 `form:"min" json:"min"`
 	Max *float64 `form:"max" json:"max"`
+
+	// Nullable stores the unset (null or empty) field value as NULL
+	// instead of 0, so that it could be distinguished from a real 0.
+	Nullable bool `form:"nullable" json:"nullable"`
 }
 
 func (o NumberOptions) Validate() error {
@@ -352,9 +434,39 @@ func (o NumberOptions) Validate() error {
 	)
 }
 
+// decimalRegex matches the plain and the exponent decimal number literals
+// (eg. "-9.99", ".5", "1.25e-3"), but not other big.Rat formats like "1/3" or "0x10".
+var decimalRegex = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d{1,4})?$`)
+
+// ParseDecimal parses the provided value (number, json.Number or
+// decimal string) into an arbitrary precision rational number.
+//
+// Returns false if the value is not a valid decimal number.
+func ParseDecimal(value any) (*big.Rat, bool) {
+	var str string
+
+	switch v := value.(type) {
+	case float32:
+		str = strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		str = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		str = strings.TrimSpace(cast.ToString(v))
+	}
+
+	if !decimalRegex.MatchString(str) {
+		return nil, false
+	}
+
+	return new(big.Rat).SetString(str)
+}
+
 // -------------------------------------------------------------------
 
 type BoolOptions struct {
+	// Nullable stores the unset (null or empty) field value as NULL
+	// instead of false.
+	Nullable bool `form:"nullable" json:"nullable"`
 }
 
 func (o BoolOptions) Validate() error {
@@ -406,6 +518,10 @@ func (o UrlOptions) Validate() error {
 type DateOptions struct {
 	Min types.DateTime `form:"min" json:"min"`
 	Max types.DateTime `form:"max" json:"max"`
+
+	// Nullable stores the unset (null or empty) field value as NULL
+	// instead of an empty string.
+	Nullable bool `form:"nullable" json:"nullable"`
 }
 
 func (o DateOptions) Validate() error {
@@ -459,18 +575,63 @@ func (o JsonOptions) Validate() error {
 
 // -------------------------------------------------------------------
 
+// mimeTypePatternRegex matches a mime type with optional
+// parameters (eg. "text/plain; charset=utf-8") or a wildcard (eg. "image/*").
+var mimeTypePatternRegex = regexp.MustCompile(`^[\w.+-]+/(\*|[\w.+-]+(\s*;.+)?)$`)
+
+// All valid file naming strategies
+const (
+	FileNameStrategyRandom   string = "random"
+	FileNameStrategyOriginal string = "original"
+	FileNameStrategyCustom   string = "custom"
+)
+
+// cacheControlRegex matches a single line printable header value.
+var cacheControlRegex = regexp.MustCompile(`^[\x20-\x7e]+$`)
+
 type FileOptions struct {
-	MaxSelect int      `form:"maxSelect" json:"maxSelect"`
-	MaxSize   int      `form:"maxSize" json:"maxSize"` // in bytes
+	MaxSelect int `form:"maxSelect" json:"maxSelect"`
+	MaxSize   int `form:"maxSize" json:"maxSize"` // in bytes
+
+	// MimeTypes is the list of the allowed file mime types sniffed from
+	// the uploaded file content (wildcards like "image/*" are supported).
 	MimeTypes []string `form:"mimeTypes" json:"mimeTypes"`
 	Thumbs    []string `form:"thumbs" json:"thumbs"`
+
+	// NameStrategy is the uploaded files naming strategy (defaults to "random").
+	//
+	// "original" keeps the sanitized original file name and "custom"
+	// uses the registered Go generator with the specified NameGenerator name.
+	NameStrategy string `form:"nameStrategy" json:"nameStrategy"`
+
+	// NameGenerator is the name of the custom file name generator.
+	NameGenerator string `form:"nameGenerator" json:"nameGenerator"`
+
+	// CacheControl is the optional "Cache-Control" header value used
+	// when serving the field files and thumbs
+	// (eg. "public, max-age=31536000, immutable" or "no-store").
+	CacheControl string `form:"cacheControl" json:"cacheControl"`
 }
 
 func (o FileOptions) Validate() error {
 	return validation.ValidateStruct(&o,
 		validation.Field(&o.MaxSelect, validation.Required, validation.Min(1)),
 		validation.Field(&o.MaxSize, validation.Required, validation.Min(1)),
+		validation.Field(&o.MimeTypes, validation.Each(validation.Match(mimeTypePatternRegex))),
 		validation.Field(&o.Thumbs, validation.Each(validation.Match(regexp.MustCompile(`^[1-9]\d*x[1-9]\d*$`)))),
+		validation.Field(
+			&o.NameStrategy,
+			validation.In(FileNameStrategyRandom, FileNameStrategyOriginal, FileNameStrategyCustom),
+		),
+		validation.Field(
+			&o.NameGenerator,
+			validation.When(o.NameStrategy == FileNameStrategyCustom, validation.Required),
+		),
+		validation.Field(
+			&o.CacheControl,
+			validation.Length(1, 255),
+			validation.Match(cacheControlRegex),
+		),
 	)
 }
 
@@ -501,3 +662,329 @@ func (o UserOptions) Validate() error {
 		validation.Field(&o.MaxSelect, validation.Required, validation.Min(1)),
 	)
 }
+
+// -------------------------------------------------------------------
+
+// localeRegex matches a valid locale code (eg. "en", "pt-BR", "zh_Hant").
+var localeRegex = regexp.MustCompile(`^[a-zA-Z]{2,3}([_\-][a-zA-Z0-9]{2,8})*$`)
+
+// LocalizedOptions defines the localized field options.
+//
+// The Min, Max and Pattern constraints are applied to each locale value.
+type LocalizedOptions struct {
+	Locales       []string `form:"locales" json:"locales"`
+	DefaultLocale string   `form:"defaultLocale" json:"defaultLocale"`
+	Min           *int     `form:"min" json:"min"`
+	Max           *int     `form:"max" json:"max"`
+	Pattern       string   `form:"pattern" json:"pattern"`
+}
+
+func (o LocalizedOptions) Validate() error {
+	minVal := 0
+	if o.Min != nil {
+		minVal = *o.Min
+	}
+
+	return validation.ValidateStruct(&o,
+		validation.Field(
+			&o.Locales,
+			validation.Required,
+			validation.Each(validation.Match(localeRegex)),
+			validation.By(checkUniqueLocales),
+		),
+		validation.Field(
+			&o.DefaultLocale,
+			validation.Required,
+			validation.In(list.ToInterfaceSlice(o.Locales)...),
+		),
+		validation.Field(&o.Min, validation.Min(0)),
+		validation.Field(&o.Max, validation.Min(minVal)),
+		validation.Field(&o.Pattern, validation.By((&TextOptions{}).checkRegex)),
+	)
+}
+
+func checkUniqueLocales(value any) error {
+	v, _ := value.([]string)
+
+	existing := make(map[string]struct{}, len(v))
+	for _, locale := range v {
+		key := strings.ToLower(locale)
+		if _, ok := existing[key]; ok {
+			return validation.NewError("validation_duplicated_locales", "The locales must be unique.")
+		}
+		existing[key] = struct{}{}
+	}
+
+	return nil
+}
+
+// HasLocale checks whether locale is one of the field locales (case-insensitive).
+func (o *LocalizedOptions) HasLocale(locale string) bool {
+	return o.MatchLocale(locale) != ""
+}
+
+// ResolveLocale returns the first of the preferred locales that is
+// supported by the field, falling back to the default locale.
+//
+// A preferred locale with region (eg. "en-US") also matches
+// its base language locale (eg. "en").
+func (o *LocalizedOptions) ResolveLocale(preferred []string) string {
+	for _, locale := range preferred {
+		if match := o.MatchLocale(locale); match != "" {
+			return match
+		}
+
+		if i := strings.IndexAny(locale, "-_"); i > 0 {
+			if match := o.MatchLocale(locale[:i]); match != "" {
+				return match
+			}
+		}
+	}
+
+	return o.DefaultLocale
+}
+
+// MatchLocale returns the field locale matching the provided one
+// (case-insensitive) or empty string if there is no such locale.
+func (o *LocalizedOptions) MatchLocale(locale string) string {
+	for _, l := range o.Locales {
+		if strings.EqualFold(l, locale) {
+			return l
+		}
+	}
+
+	return ""
+}
+
+// normalizeLocalized returns the provided localized field value as JsonMap.
+//
+// A plain (non json object) string is stored as the default locale value.
+func normalizeLocalized(options *LocalizedOptions, value any) any {
+	var raw map[string]any
+
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case types.JsonMap:
+		raw = v
+	case map[string]any:
+		raw = v
+	case map[string]string:
+		raw = make(map[string]any, len(v))
+		for k, item := range v {
+			raw[k] = item
+		}
+	default:
+		str := cast.ToString(v)
+		if str == "" {
+			return nil
+		}
+
+		if err := json.Unmarshal([]byte(str), &raw); err != nil || raw == nil {
+			if options == nil || options.DefaultLocale == "" {
+				return nil
+			}
+			raw = map[string]any{options.DefaultLocale: str}
+		}
+	}
+
+	result := make(types.JsonMap, len(raw))
+	for locale, item := range raw {
+		if item == nil {
+			continue
+		}
+		result[locale] = cast.ToString(item)
+	}
+
+	return result
+}
+
+// -------------------------------------------------------------------
+
+// SlugRegex defines the valid slug field value format.
+var SlugRegex = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+type SlugOptions struct {
+	// Source is the name of the record field from which the slug is generated.
+	Source string `form:"source" json:"source"`
+
+	// Regenerate regenerates the slug on source field value change
+	// (by default the slug is generated only once to keep the urls stable).
+	Regenerate bool `form:"regenerate" json:"regenerate"`
+}
+
+func (o SlugOptions) Validate() error {
+	return validation.ValidateStruct(&o,
+		validation.Field(&o.Source, validation.Required, validation.Match(schemaFieldNameRegex)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+// DecimalMaxScale is the max allowed number of decimal field places.
+const DecimalMaxScale = 12
+
+type DecimalOptions struct {
+	// Scale is the number of the stored decimal places
+	// (the values are rounded half away from zero).
+	Scale int `form:"scale" json:"scale"`
+
+	Min *float64 `form:"min" json:"min"`
+	Max *float64 `form:"max" json:"max"`
+}
+
+func (o DecimalOptions) Validate() error {
+	var maxRules []validation.Rule
+	if o.Min != nil && o.Max != nil {
+		maxRules = append(maxRules, validation.Min(*o.Min))
+	}
+
+	return validation.ValidateStruct(&o,
+		validation.Field(&o.Scale, validation.Min(0), validation.Max(DecimalMaxScale)),
+		validation.Field(&o.Max, maxRules...),
+	)
+}
+
+// Round parses the provided value and rounds it to the options scale.
+//
+// Returns false if the value is not a valid number.
+func (o DecimalOptions) Round(value any) (*big.Rat, bool) {
+	r, ok := ParseDecimal(value)
+	if !ok {
+		return nil, false
+	}
+
+	scaled := scaleDecimal(r, o.Scale)
+
+	return new(big.Rat).SetFrac(scaled, decimalFactor(o.Scale)), true
+}
+
+// Format returns the provided value as decimal string with exactly
+// Scale decimal places (eg. "9.90"), fallbacking to zero for invalid numbers.
+func (o DecimalOptions) Format(value any) string {
+	r, ok := o.Round(value)
+	if !ok {
+		r = new(big.Rat)
+	}
+
+	return r.FloatString(o.Scale)
+}
+
+// Scaled returns the db stored scaled integer representation
+// of the provided value (eg. "9.99" with scale 2 is 999).
+//
+// Returns an error if the value is not a valid number or it is out of range.
+func (o DecimalOptions) Scaled(value any) (int64, error) {
+	r, ok := ParseDecimal(value)
+	if !ok {
+		return 0, errors.New("Must be a valid number.")
+	}
+
+	return scaledInt64(scaleDecimal(r, o.Scale))
+}
+
+// ScaledExact is similar to [DecimalOptions.Scaled] but returns an error
+// if the value has more decimal places than the options scale
+// (aka. it cannot be stored without rounding).
+func (o DecimalOptions) ScaledExact(value any) (int64, error) {
+	num, den, err := o.scaledFrac(value)
+	if err != nil {
+		return 0, err
+	}
+
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() != 0 {
+		return 0, errors.New("The number has more decimal places than the allowed scale.")
+	}
+
+	return scaledInt64(quo)
+}
+
+// ScaledFloor is similar to [DecimalOptions.Scaled] but rounds
+// the value down (toward negative infinity) to the options scale.
+func (o DecimalOptions) ScaledFloor(value any) (int64, error) {
+	num, den, err := o.scaledFrac(value)
+	if err != nil {
+		return 0, err
+	}
+
+	// Div is Euclidean and den is always positive
+	return scaledInt64(new(big.Int).Div(num, den))
+}
+
+// ScaledCeil is similar to [DecimalOptions.Scaled] but rounds
+// the value up (toward positive infinity) to the options scale.
+func (o DecimalOptions) ScaledCeil(value any) (int64, error) {
+	num, den, err := o.scaledFrac(value)
+	if err != nil {
+		return 0, err
+	}
+
+	quo, mod := new(big.Int).DivMod(num, den, new(big.Int))
+	if mod.Sign() != 0 {
+		quo.Add(quo, big.NewInt(1))
+	}
+
+	return scaledInt64(quo)
+}
+
+// scaledFrac returns the numerator and the (positive) denominator
+// of the provided value multiplied by 10^scale.
+func (o DecimalOptions) scaledFrac(value any) (*big.Int, *big.Int, error) {
+	r, ok := ParseDecimal(value)
+	if !ok {
+		return nil, nil, errors.New("Must be a valid number.")
+	}
+
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(decimalFactor(o.Scale)))
+
+	return scaled.Num(), scaled.Denom(), nil
+}
+
+// scaledInt64 returns the int64 value of the provided scaled integer
+// or an error if it is out of range.
+func scaledInt64(scaled *big.Int) (int64, error) {
+	if !scaled.IsInt64() {
+		return 0, errors.New("The number is out of the allowed range.")
+	}
+
+	return scaled.Int64(), nil
+}
+
+// Unscaled converts the provided db stored scaled integer
+// value into decimal string (eg. 999 with scale 2 is "9.99").
+func (o DecimalOptions) Unscaled(value any) string {
+	scaled, ok := new(big.Int).SetString(strings.TrimSpace(cast.ToString(value)), 10)
+	if !ok {
+		scaled = new(big.Int)
+	}
+
+	return new(big.Rat).SetFrac(scaled, decimalFactor(o.Scale)).FloatString(o.Scale)
+}
+
+// decimalFactor returns 10^scale.
+func decimalFactor(scale int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)
+}
+
+// scaleDecimal multiplies r by 10^scale and rounds
+// the result half away from zero to the nearest integer.
+func scaleDecimal(r *big.Rat, scale int) *big.Int {
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(decimalFactor(scale)))
+
+	num := scaled.Num()
+	den := scaled.Denom()
+
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+
+	// round half away from zero
+	if new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(den) >= 0 {
+		if num.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+
+	return quo
+}
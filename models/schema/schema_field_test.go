@@ -3,6 +3,7 @@ package schema_test
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"testing"
 	"time"
 
@@ -22,7 +23,7 @@ func TestReservedFieldNames(t *testing.T) {
 func TestFieldTypes(t *testing.T) {
 	result := schema.FieldTypes()
 
-	if len(result) != 11 {
+	if len(result) != 14 {
 		t.Fatalf("Expected %d types, got %d (%v)", 3, len(result), result)
 	}
 }
@@ -48,10 +49,18 @@ func TestSchemaFieldColDefinition(t *testing.T) {
 			schema.SchemaField{Type: schema.FieldTypeNumber, Name: "test"},
 			"REAL DEFAULT 0",
 		},
+		{
+			schema.SchemaField{Type: schema.FieldTypeNumber, Name: "test", Options: &schema.NumberOptions{Nullable: true}},
+			"REAL DEFAULT NULL",
+		},
 		{
 			schema.SchemaField{Type: schema.FieldTypeBool, Name: "test"},
 			"Boolean DEFAULT FALSE",
 		},
+		{
+			schema.SchemaField{Type: schema.FieldTypeBool, Name: "test", Options: &schema.BoolOptions{Nullable: true}},
+			"Boolean DEFAULT NULL",
+		},
 		{
 			schema.SchemaField{Type: schema.FieldTypeEmail, Name: "test"},
 			"TEXT DEFAULT ''",
@@ -64,6 +73,10 @@ func TestSchemaFieldColDefinition(t *testing.T) {
 			schema.SchemaField{Type: schema.FieldTypeDate, Name: "test"},
 			"TEXT DEFAULT ''",
 		},
+		{
+			schema.SchemaField{Type: schema.FieldTypeDate, Name: "test", Options: &schema.DateOptions{Nullable: true}},
+			"TEXT DEFAULT NULL",
+		},
 		{
 			schema.SchemaField{Type: schema.FieldTypeSelect, Name: "test"},
 			"TEXT DEFAULT ''",
@@ -84,6 +97,18 @@ func TestSchemaFieldColDefinition(t *testing.T) {
 			schema.SchemaField{Type: schema.FieldTypeUser, Name: "test"},
 			"TEXT DEFAULT ''",
 		},
+		{
+			schema.SchemaField{Type: schema.FieldTypeLocalized, Name: "test"},
+			"JSON DEFAULT NULL",
+		},
+		{
+			schema.SchemaField{Type: schema.FieldTypeSlug, Name: "test"},
+			"TEXT DEFAULT ''",
+		},
+		{
+			schema.SchemaField{Type: schema.FieldTypeDecimal, Name: "test"},
+			"INTEGER DEFAULT 0",
+		},
 	}
 
 	for i, s := range scenarios {
@@ -94,6 +119,27 @@ func TestSchemaFieldColDefinition(t *testing.T) {
 	}
 }
 
+func TestSchemaFieldIsNullable(t *testing.T) {
+	scenarios := []struct {
+		field    schema.SchemaField
+		expected bool
+	}{
+		{schema.SchemaField{Type: schema.FieldTypeText}, false},
+		{schema.SchemaField{Type: schema.FieldTypeNumber}, false},
+		{schema.SchemaField{Type: schema.FieldTypeNumber, Options: &schema.NumberOptions{Nullable: true}}, true},
+		{schema.SchemaField{Type: schema.FieldTypeBool}, false},
+		{schema.SchemaField{Type: schema.FieldTypeBool, Options: &schema.BoolOptions{Nullable: true}}, true},
+		{schema.SchemaField{Type: schema.FieldTypeDate}, false},
+		{schema.SchemaField{Type: schema.FieldTypeDate, Options: &schema.DateOptions{Nullable: true}}, true},
+	}
+
+	for i, s := range scenarios {
+		if result := s.field.IsNullable(); result != s.expected {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, result)
+		}
+	}
+}
+
 func TestSchemaFieldString(t *testing.T) {
 	f := schema.SchemaField{
 		Id:       "abc",
@@ -416,12 +462,12 @@ func TestSchemaFieldInitOptions(t *testing.T) {
 		{
 			schema.SchemaField{Type: schema.FieldTypeNumber},
 			false,
-			`{"system":false,"id":"","name":"","type":"number","required":false,"unique":false,"options":{"min":null,"max":null}}`,
+			`{"system":false,"id":"","name":"","type":"number","required":false,"unique":false,"options":{"min":null,"max":null,"nullable":false}}`,
 		},
 		{
 			schema.SchemaField{Type: schema.FieldTypeBool},
 			false,
-			`{"system":false,"id":"","name":"","type":"bool","required":false,"unique":false,"options":{}}`,
+			`{"system":false,"id":"","name":"","type":"bool","required":false,"unique":false,"options":{"nullable":false}}`,
 		},
 		{
 			schema.SchemaField{Type: schema.FieldTypeEmail},
@@ -436,7 +482,7 @@ func TestSchemaFieldInitOptions(t *testing.T) {
 		{
 			schema.SchemaField{Type: schema.FieldTypeDate},
 			false,
-			`{"system":false,"id":"","name":"","type":"date","required":false,"unique":false,"options":{"min":"","max":""}}`,
+			`{"system":false,"id":"","name":"","type":"date","required":false,"unique":false,"options":{"min":"","max":"","nullable":false}}`,
 		},
 		{
 			schema.SchemaField{Type: schema.FieldTypeSelect},
@@ -451,7 +497,7 @@ func TestSchemaFieldInitOptions(t *testing.T) {
 		{
 			schema.SchemaField{Type: schema.FieldTypeFile},
 			false,
-			`{"system":false,"id":"","name":"","type":"file","required":false,"unique":false,"options":{"maxSelect":0,"maxSize":0,"mimeTypes":null,"thumbs":null}}`,
+			`{"system":false,"id":"","name":"","type":"file","required":false,"unique":false,"options":{"maxSelect":0,"maxSize":0,"mimeTypes":null,"thumbs":null,"nameStrategy":"","nameGenerator":"","cacheControl":""}}`,
 		},
 		{
 			schema.SchemaField{Type: schema.FieldTypeRelation},
@@ -463,6 +509,21 @@ func TestSchemaFieldInitOptions(t *testing.T) {
 			false,
 			`{"system":false,"id":"","name":"","type":"user","required":false,"unique":false,"options":{"maxSelect":0,"cascadeDelete":false}}`,
 		},
+		{
+			schema.SchemaField{Type: schema.FieldTypeLocalized},
+			false,
+			`{"system":false,"id":"","name":"","type":"localized","required":false,"unique":false,"options":{"locales":null,"defaultLocale":"","min":null,"max":null,"pattern":""}}`,
+		},
+		{
+			schema.SchemaField{Type: schema.FieldTypeSlug},
+			false,
+			`{"system":false,"id":"","name":"","type":"slug","required":false,"unique":false,"options":{"source":"","regenerate":false}}`,
+		},
+		{
+			schema.SchemaField{Type: schema.FieldTypeDecimal},
+			false,
+			`{"system":false,"id":"","name":"","type":"decimal","required":false,"unique":false,"options":{"scale":0,"min":null,"max":null}}`,
+		},
 		{
 			schema.SchemaField{
 				Type:    schema.FieldTypeText,
@@ -527,6 +588,27 @@ func TestSchemaFieldPrepareValue(t *testing.T) {
 		{schema.SchemaField{Type: schema.FieldTypeNumber}, 1, "1"},
 		{schema.SchemaField{Type: schema.FieldTypeNumber}, 1.5, "1.5"},
 		{schema.SchemaField{Type: schema.FieldTypeNumber}, "1.5", "1.5"},
+		{schema.SchemaField{Type: schema.FieldTypeNumber}, json.Number("1.5"), "1.5"},
+		{schema.SchemaField{Type: schema.FieldTypeNumber}, "", "0"},
+
+		// number (nullable)
+		{schema.SchemaField{Type: schema.FieldTypeNumber, Options: &schema.NumberOptions{Nullable: true}}, nil, "null"},
+		{schema.SchemaField{Type: schema.FieldTypeNumber, Options: &schema.NumberOptions{Nullable: true}}, "", "null"},
+		{schema.SchemaField{Type: schema.FieldTypeNumber, Options: &schema.NumberOptions{Nullable: true}}, 0, "0"},
+		{schema.SchemaField{Type: schema.FieldTypeNumber, Options: &schema.NumberOptions{Nullable: true}}, "0", "0"},
+
+		// decimal
+		{schema.SchemaField{Type: schema.FieldTypeDecimal, Options: &schema.DecimalOptions{Scale: 2}}, nil, "null"},
+		{schema.SchemaField{Type: schema.FieldTypeDecimal, Options: &schema.DecimalOptions{Scale: 2}}, "invalid", `"0.00"`},
+		{schema.SchemaField{Type: schema.FieldTypeDecimal, Options: &schema.DecimalOptions{Scale: 2}}, "1/3", `"0.00"`},
+		{schema.SchemaField{Type: schema.FieldTypeDecimal, Options: &schema.DecimalOptions{Scale: 2}}, " -0.120 ", `"-0.12"`},
+		{schema.SchemaField{Type: schema.FieldTypeDecimal, Options: &schema.DecimalOptions{Scale: 5}}, "1.25e-3", `"0.00125"`},
+		{schema.SchemaField{Type: schema.FieldTypeDecimal, Options: &schema.DecimalOptions{Scale: 2}}, json.Number("12345678901234567.01"), `"12345678901234567.01"`},
+		{schema.SchemaField{Type: schema.FieldTypeDecimal, Options: &schema.DecimalOptions{Scale: 2}}, 1, `"1.00"`},
+		{schema.SchemaField{Type: schema.FieldTypeDecimal, Options: &schema.DecimalOptions{Scale: 2}}, 9.99, `"9.99"`},
+		{schema.SchemaField{Type: schema.FieldTypeDecimal, Options: &schema.DecimalOptions{Scale: 2}}, "0.105", `"0.11"`},
+		{schema.SchemaField{Type: schema.FieldTypeDecimal, Options: &schema.DecimalOptions{Scale: 2}}, "-0.105", `"-0.11"`},
+		{schema.SchemaField{Type: schema.FieldTypeDecimal, Options: &schema.DecimalOptions{Scale: 0}}, "2.5", `"3"`},
 
 		// bool
 		{schema.SchemaField{Type: schema.FieldTypeBool}, nil, "false"},
@@ -538,6 +620,13 @@ func TestSchemaFieldPrepareValue(t *testing.T) {
 		{schema.SchemaField{Type: schema.FieldTypeBool}, false, "false"},
 		{schema.SchemaField{Type: schema.FieldTypeBool}, true, "true"},
 
+		// bool (nullable)
+		{schema.SchemaField{Type: schema.FieldTypeBool, Options: &schema.BoolOptions{Nullable: true}}, nil, "null"},
+		{schema.SchemaField{Type: schema.FieldTypeBool, Options: &schema.BoolOptions{Nullable: true}}, "", "null"},
+		{schema.SchemaField{Type: schema.FieldTypeBool, Options: &schema.BoolOptions{Nullable: true}}, 0, "false"},
+		{schema.SchemaField{Type: schema.FieldTypeBool, Options: &schema.BoolOptions{Nullable: true}}, false, "false"},
+		{schema.SchemaField{Type: schema.FieldTypeBool, Options: &schema.BoolOptions{Nullable: true}}, "true", "true"},
+
 		// date
 		{schema.SchemaField{Type: schema.FieldTypeDate}, nil, "null"},
 		{schema.SchemaField{Type: schema.FieldTypeDate}, "", `""`},
@@ -546,6 +635,12 @@ func TestSchemaFieldPrepareValue(t *testing.T) {
 		{schema.SchemaField{Type: schema.FieldTypeDate}, types.DateTime{}, `""`},
 		{schema.SchemaField{Type: schema.FieldTypeDate}, time.Time{}, `""`},
 
+		// date (nullable)
+		{schema.SchemaField{Type: schema.FieldTypeDate, Options: &schema.DateOptions{Nullable: true}}, nil, "null"},
+		{schema.SchemaField{Type: schema.FieldTypeDate, Options: &schema.DateOptions{Nullable: true}}, "", "null"},
+		{schema.SchemaField{Type: schema.FieldTypeDate, Options: &schema.DateOptions{Nullable: true}}, types.DateTime{}, "null"},
+		{schema.SchemaField{Type: schema.FieldTypeDate, Options: &schema.DateOptions{Nullable: true}}, "2022-01-01 11:27:10.123", `"2022-01-01 11:27:10.123"`},
+
 		// select (single)
 		{schema.SchemaField{Type: schema.FieldTypeSelect}, nil, `null`},
 		{schema.SchemaField{Type: schema.FieldTypeSelect}, "", `null`},
@@ -829,6 +924,48 @@ func TestSchemaFieldPrepareValue(t *testing.T) {
 			[]string{"1ba88b4f-e9da-42f0-9764-9a55c953e724", "2ba88b4f-e9da-42f0-9764-9a55c953e724", "1ba88b4f-e9da-42f0-9764-9a55c953e724"},
 			`["1ba88b4f-e9da-42f0-9764-9a55c953e724","2ba88b4f-e9da-42f0-9764-9a55c953e724"]`,
 		},
+
+		// slug
+		{schema.SchemaField{Type: schema.FieldTypeSlug}, nil, `null`},
+		{schema.SchemaField{Type: schema.FieldTypeSlug}, "", `""`},
+		{schema.SchemaField{Type: schema.FieldTypeSlug}, "test-slug", `"test-slug"`},
+
+		// localized
+		{schema.SchemaField{Type: schema.FieldTypeLocalized}, nil, `null`},
+		{schema.SchemaField{Type: schema.FieldTypeLocalized}, "", `null`},
+		{schema.SchemaField{Type: schema.FieldTypeLocalized}, "test", `null`},
+		{
+			schema.SchemaField{
+				Type:    schema.FieldTypeLocalized,
+				Options: &schema.LocalizedOptions{Locales: []string{"en", "bg"}, DefaultLocale: "en"},
+			},
+			"test",
+			`{"en":"test"}`,
+		},
+		{
+			schema.SchemaField{
+				Type:    schema.FieldTypeLocalized,
+				Options: &schema.LocalizedOptions{Locales: []string{"en", "bg"}, DefaultLocale: "en"},
+			},
+			`{"en":"test","bg":123,"de":null}`,
+			`{"bg":"123","en":"test"}`,
+		},
+		{
+			schema.SchemaField{
+				Type:    schema.FieldTypeLocalized,
+				Options: &schema.LocalizedOptions{Locales: []string{"en", "bg"}, DefaultLocale: "en"},
+			},
+			map[string]any{"en": "test", "bg": "тест"},
+			`{"bg":"тест","en":"test"}`,
+		},
+		{
+			schema.SchemaField{
+				Type:    schema.FieldTypeLocalized,
+				Options: &schema.LocalizedOptions{Locales: []string{"en", "bg"}, DefaultLocale: "en"},
+			},
+			map[string]string{"bg": "тест"},
+			`{"bg":"тест"}`,
+		},
 	}
 
 	for i, s := range scenarios {
@@ -945,6 +1082,45 @@ func TestTextOptionsValidate(t *testing.T) {
 	checkFieldOptionsScenarios(t, scenarios)
 }
 
+func TestParseDecimal(t *testing.T) {
+	scenarios := []struct {
+		value       any
+		expectedOk  bool
+		expectedStr string
+	}{
+		{nil, false, ""},
+		{"", false, ""},
+		{"abc", false, ""},
+		{"1.2.3", false, ""},
+		{"1/3", false, ""},
+		{"0x10", false, ""},
+		{"1_000", false, ""},
+		{"1e100000", false, ""},
+		{math.NaN(), false, ""},
+		{0, true, "0/1"},
+		{-12, true, "-12/1"},
+		{1.5, true, "3/2"},
+		{float32(0.25), true, "1/4"},
+		{" 12345678901234567891 ", true, "12345678901234567891/1"},
+		{json.Number("0.001"), true, "1/1000"},
+		{"+.5", true, "1/2"},
+		{"1.25e-3", true, "1/800"},
+	}
+
+	for i, s := range scenarios {
+		result, ok := schema.ParseDecimal(s.value)
+
+		if ok != s.expectedOk {
+			t.Errorf("(%d) Expected ok %v, got %v", i, s.expectedOk, ok)
+			continue
+		}
+
+		if ok && result.String() != s.expectedStr {
+			t.Errorf("(%d) Expected %q, got %q", i, s.expectedStr, result.String())
+		}
+	}
+}
+
 func TestNumberOptionsValidate(t *testing.T) {
 	number1 := 10.0
 	number2 := 20.0
@@ -1266,6 +1442,61 @@ func TestFileOptionsValidate(t *testing.T) {
 			},
 			[]string{"thumbs"},
 		},
+		{
+			"invalid name strategy",
+			schema.FileOptions{
+				MaxSize:      1,
+				MaxSelect:    2,
+				NameStrategy: "invalid",
+			},
+			[]string{"nameStrategy"},
+		},
+		{
+			"custom name strategy without generator",
+			schema.FileOptions{
+				MaxSize:      1,
+				MaxSelect:    2,
+				NameStrategy: schema.FileNameStrategyCustom,
+			},
+			[]string{"nameGenerator"},
+		},
+		{
+			"original name strategy",
+			schema.FileOptions{
+				MaxSize:      1,
+				MaxSelect:    2,
+				NameStrategy: schema.FileNameStrategyOriginal,
+			},
+			[]string{},
+		},
+		{
+			"custom name strategy with generator",
+			schema.FileOptions{
+				MaxSize:       1,
+				MaxSelect:     2,
+				NameStrategy:  schema.FileNameStrategyCustom,
+				NameGenerator: "test",
+			},
+			[]string{},
+		},
+		{
+			"invalid cache control value",
+			schema.FileOptions{
+				MaxSize:      1,
+				MaxSelect:    2,
+				CacheControl: "no-store\r\nX-Test: 1",
+			},
+			[]string{"cacheControl"},
+		},
+		{
+			"valid cache control value",
+			schema.FileOptions{
+				MaxSize:      1,
+				MaxSelect:    2,
+				CacheControl: "public, max-age=31536000, immutable",
+			},
+			[]string{},
+		},
 		{
 			"valid thumbs format",
 			schema.FileOptions{
@@ -1275,6 +1506,24 @@ func TestFileOptionsValidate(t *testing.T) {
 			},
 			[]string{},
 		},
+		{
+			"invalid mime types format",
+			schema.FileOptions{
+				MaxSize:   1,
+				MaxSelect: 2,
+				MimeTypes: []string{"image/png", "image", "*/png"},
+			},
+			[]string{"mimeTypes"},
+		},
+		{
+			"valid mime types format",
+			schema.FileOptions{
+				MaxSize:   1,
+				MaxSelect: 2,
+				MimeTypes: []string{"image/png", "image/svg+xml", "image/*", "text/plain; charset=utf-8"},
+			},
+			[]string{},
+		},
 	}
 
 	checkFieldOptionsScenarios(t, scenarios)
@@ -1341,3 +1590,273 @@ func TestUserOptionsValidate(t *testing.T) {
 
 	checkFieldOptionsScenarios(t, scenarios)
 }
+
+func TestLocalizedOptionsValidate(t *testing.T) {
+	number1 := 1
+	number5 := 5
+	number10 := 10
+
+	scenarios := []fieldOptionsScenario{
+		{
+			"empty",
+			schema.LocalizedOptions{},
+			[]string{"locales", "defaultLocale"},
+		},
+		{
+			"invalid locales",
+			schema.LocalizedOptions{
+				Locales:       []string{"en", "invalid locale"},
+				DefaultLocale: "en",
+			},
+			[]string{"locales"},
+		},
+		{
+			"duplicated locales",
+			schema.LocalizedOptions{
+				Locales:       []string{"en", "bg", "EN"},
+				DefaultLocale: "en",
+			},
+			[]string{"locales"},
+		},
+		{
+			"missing default locale",
+			schema.LocalizedOptions{
+				Locales:       []string{"en", "bg"},
+				DefaultLocale: "de",
+			},
+			[]string{"defaultLocale"},
+		},
+		{
+			"invalid min, max and pattern",
+			schema.LocalizedOptions{
+				Locales:       []string{"en"},
+				DefaultLocale: "en",
+				Min:           &number10,
+				Max:           &number5,
+				Pattern:       "(test",
+			},
+			[]string{"max", "pattern"},
+		},
+		{
+			"valid options",
+			schema.LocalizedOptions{
+				Locales:       []string{"en", "pt-BR", "zh_Hant"},
+				DefaultLocale: "pt-BR",
+				Min:           &number1,
+				Max:           &number5,
+				Pattern:       `\w+`,
+			},
+			[]string{},
+		},
+	}
+
+	checkFieldOptionsScenarios(t, scenarios)
+}
+
+func TestLocalizedOptionsResolveLocale(t *testing.T) {
+	options := schema.LocalizedOptions{
+		Locales:       []string{"en", "bg", "pt-BR"},
+		DefaultLocale: "en",
+	}
+
+	scenarios := []struct {
+		preferred []string
+		expected  string
+	}{
+		{nil, "en"},
+		{[]string{"de"}, "en"},
+		{[]string{"bg"}, "bg"},
+		{[]string{"BG"}, "bg"},
+		{[]string{"de", "bg", "en"}, "bg"},
+		{[]string{"bg-BG"}, "bg"},
+		{[]string{"pt-br"}, "pt-BR"},
+		{[]string{"pt"}, "en"},
+	}
+
+	for i, s := range scenarios {
+		result := options.ResolveLocale(s.preferred)
+		if result != s.expected {
+			t.Errorf("(%d) Expected %q, got %q", i, s.expected, result)
+		}
+	}
+
+	if !options.HasLocale("PT-br") {
+		t.Fatal("Expected HasLocale to be true for PT-br")
+	}
+
+	if options.HasLocale("pt") {
+		t.Fatal("Expected HasLocale to be false for pt")
+	}
+}
+
+func TestSlugOptionsValidate(t *testing.T) {
+	scenarios := []fieldOptionsScenario{
+		{
+			"empty",
+			schema.SlugOptions{},
+			[]string{"source"},
+		},
+		{
+			"invalid source",
+			schema.SlugOptions{Source: "invalid source"},
+			[]string{"source"},
+		},
+		{
+			"valid options",
+			schema.SlugOptions{Source: "title", Regenerate: true},
+			[]string{},
+		},
+	}
+
+	checkFieldOptionsScenarios(t, scenarios)
+}
+
+func TestDecimalOptionsValidate(t *testing.T) {
+	number1 := 10.0
+	number2 := 20.0
+	scenarios := []fieldOptionsScenario{
+		{
+			"empty",
+			schema.DecimalOptions{},
+			[]string{},
+		},
+		{
+			"negative scale",
+			schema.DecimalOptions{Scale: -1},
+			[]string{"scale"},
+		},
+		{
+			"too large scale",
+			schema.DecimalOptions{Scale: schema.DecimalMaxScale + 1},
+			[]string{"scale"},
+		},
+		{
+			"max - failure with min",
+			schema.DecimalOptions{
+				Scale: 2,
+				Min:   &number2,
+				Max:   &number1,
+			},
+			[]string{"max"},
+		},
+		{
+			"max - success with min",
+			schema.DecimalOptions{
+				Scale: 2,
+				Min:   &number1,
+				Max:   &number2,
+			},
+			[]string{},
+		},
+	}
+
+	checkFieldOptionsScenarios(t, scenarios)
+}
+
+func TestDecimalOptionsScaled(t *testing.T) {
+	options := schema.DecimalOptions{Scale: 2}
+
+	scenarios := []struct {
+		value       any
+		expected    int64
+		expectError bool
+	}{
+		{"", 0, true},
+		{"invalid", 0, true},
+		{"99999999999999999999", 0, true}, // out of range
+		{0, 0, false},
+		{9.99, 999, false},
+		{"9.99", 999, false},
+		{"-9.99", -999, false},
+		{"9.995", 1000, false},
+		{"0.1", 10, false},
+	}
+
+	for i, s := range scenarios {
+		result, err := options.Scaled(s.value)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+			continue
+		}
+
+		if result != s.expected {
+			t.Errorf("(%d) Expected %d, got %d", i, s.expected, result)
+		}
+	}
+}
+
+func TestDecimalOptionsScaledRounding(t *testing.T) {
+	options := schema.DecimalOptions{Scale: 2}
+
+	scenarios := []struct {
+		value         any
+		expectedExact int64
+		expectedFloor int64
+		expectedCeil  int64
+		expectError   bool // for the exact value only
+	}{
+		{"9.99", 999, 999, 999, false},
+		{"9.990", 999, 999, 999, false},
+		{"9.991", 0, 999, 1000, true},
+		{"9.999", 0, 999, 1000, true},
+		{"-9.991", 0, -1000, -999, true},
+		{"-9.99", -999, -999, -999, false},
+		{10, 1000, 1000, 1000, false},
+		{"0.001", 0, 0, 1, true},
+	}
+
+	for i, s := range scenarios {
+		exact, err := options.ScaledExact(s.value)
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+		}
+		if exact != s.expectedExact {
+			t.Errorf("(%d) Expected exact %d, got %d", i, s.expectedExact, exact)
+		}
+
+		floor, err := options.ScaledFloor(s.value)
+		if err != nil || floor != s.expectedFloor {
+			t.Errorf("(%d) Expected floor %d, got %d (%v)", i, s.expectedFloor, floor, err)
+		}
+
+		ceil, err := options.ScaledCeil(s.value)
+		if err != nil || ceil != s.expectedCeil {
+			t.Errorf("(%d) Expected ceil %d, got %d (%v)", i, s.expectedCeil, ceil, err)
+		}
+	}
+
+	for _, fn := range []func(any) (int64, error){options.ScaledExact, options.ScaledFloor, options.ScaledCeil} {
+		if _, err := fn("invalid"); err == nil {
+			t.Error("Expected invalid number error")
+		}
+		if _, err := fn("99999999999999999999"); err == nil {
+			t.Error("Expected out of range error")
+		}
+	}
+}
+
+func TestDecimalOptionsUnscaled(t *testing.T) {
+	options := schema.DecimalOptions{Scale: 2}
+
+	scenarios := []struct {
+		value    any
+		expected string
+	}{
+		{"", "0.00"},
+		{"invalid", "0.00"},
+		{0, "0.00"},
+		{999, "9.99"},
+		{"-999", "-9.99"},
+		{"1000", "10.00"},
+	}
+
+	for i, s := range scenarios {
+		result := options.Unscaled(s.value)
+		if result != s.expected {
+			t.Errorf("(%d) Expected %q, got %q", i, s.expected, result)
+		}
+	}
+}
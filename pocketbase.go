@@ -6,12 +6,12 @@ import (
 	"os/signal"
 	"path/filepath"
 	"strings"
-	"sync"
 	"syscall"
 
 	"github.com/pocketbase/pocketbase/cmd"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var _ core.App = (*PocketBase)(nil)
@@ -31,7 +31,11 @@ type appWrapper struct {
 type PocketBase struct {
 	*appWrapper
 
-	// RootCmd is the main cli command
+	// RootCmd is the main cli command.
+	//
+	// Custom commands could be registered with RootCmd.AddCommand()
+	// before calling Start() or Execute(). The app is already bootstrapped
+	// when the commands are executed (aka. the app Dao, settings, etc. are ready for use).
 	RootCmd *cobra.Command
 
 	// console flags
@@ -48,6 +52,54 @@ type PocketBase struct {
 	showStartBanner bool
 }
 
+// Config defines the PocketBase app configuration options.
+//
+// The unset (zero value) fields fallback to their defaults.
+type Config struct {
+	// DefaultDebug is the default --debug flag value.
+	DefaultDebug bool
+
+	// DefaultDataDir is the default --dir flag value
+	// (default to "pb_data" in the executable directory).
+	DefaultDataDir string
+
+	// DefaultEncryptionEnv is the default --encryptionEnv flag value.
+	DefaultEncryptionEnv string
+
+	// HideStartBanner hides the web server start banner.
+	HideStartBanner bool
+
+	// DB is the app databases config (default to core.DefaultDBConfig()).
+	DB *core.DBConfig
+
+	// DefaultSettings is an optional func to modify the default app settings
+	// before loading the stored ones (see [core.BaseAppConfig]).
+	DefaultSettings func(s *core.Settings)
+
+	// Logger is the app logger (default to log.Default()).
+	Logger *log.Logger
+
+	// TracerProvider is an optional OpenTelemetry tracer provider
+	// (the tracing is disabled if not set).
+	TracerProvider trace.TracerProvider
+
+	// DisabledApis is an optional list of built-in api groups that
+	// shouldn't be registered (eg. core.ApiGroupAdminUI, core.ApiGroupLogs).
+	DisabledApis []string
+
+	// SPA is an optional single page application that is served from
+	// the app root path (the api and admin UI routes take precedence).
+	SPA *core.SPAConfig
+
+	// InstanceId is an optional app instance identifier used as the issued
+	// tokens audience (default to a hash of the data dir path).
+	InstanceId string
+
+	// BasePath is an optional path prefix (eg. "/pb") under which
+	// all app routes, including the admin UI, are served.
+	BasePath string
+}
+
 // New creates a new PocketBase instance.
 //
 // Note that the application will not be initialized/bootstrapped yet,
@@ -56,20 +108,29 @@ type PocketBase struct {
 // If you want to initialize the application before calling Start(),
 // then you'll have to manually call Bootstrap().
 func New() *PocketBase {
-	// try to find the base executable directory and how it was run
-	var withGoRun bool
-	var baseDir string
-	if strings.HasPrefix(os.Args[0], os.TempDir()) {
-		// probably ran with go run...
-		withGoRun = true
-		baseDir, _ = os.Getwd()
-	} else {
-		// probably ran with go build...
-		withGoRun = false
-		baseDir = filepath.Dir(os.Args[0])
-	}
+	_, withGoRun := baseExecDir()
 
-	defaultDir := filepath.Join(baseDir, "pb_data")
+	return NewWithConfig(Config{DefaultDebug: withGoRun})
+}
+
+// NewWithConfig creates a new PocketBase instance with the provided config.
+//
+// The same as with New(), the application will not be initialized/bootstrapped yet.
+//
+// Example:
+//
+//	app := pocketbase.NewWithConfig(pocketbase.Config{
+//		DefaultDataDir: "./test_pb_data",
+//		DefaultSettings: func(s *core.Settings) {
+//			s.Meta.AppName = "Test"
+//		},
+//	})
+func NewWithConfig(config Config) *PocketBase {
+	defaultDir := config.DefaultDataDir
+	if defaultDir == "" {
+		baseDir, _ := baseExecDir()
+		defaultDir = filepath.Join(baseDir, "pb_data")
+	}
 
 	pb := &PocketBase{
 		RootCmd: &cobra.Command{
@@ -84,25 +145,46 @@ func New() *PocketBase {
 				DisableDefaultCmd: true,
 			},
 		},
-		defaultDebug:         withGoRun,
+		defaultDebug:         config.DefaultDebug,
 		defaultDataDir:       defaultDir,
-		defaultEncryptionEnv: "",
-		showStartBanner:      true,
+		defaultEncryptionEnv: config.DefaultEncryptionEnv,
+		showStartBanner:      !config.HideStartBanner,
 	}
 
 	// parse base flags
 	// (errors are ignored, since the full flags parsing happens on Execute())
 	pb.eagerParseFlags()
 
-	pb.appWrapper = &appWrapper{core.NewBaseApp(
-		pb.dataDirFlag,
-		pb.encryptionEnv,
-		pb.debugFlag,
-	)}
+	pb.appWrapper = &appWrapper{core.NewBaseAppWithConfig(core.BaseAppConfig{
+		DataDir:         pb.dataDirFlag,
+		EncryptionEnv:   pb.encryptionEnv,
+		IsDebug:         pb.debugFlag,
+		DB:              config.DB,
+		DefaultSettings: config.DefaultSettings,
+		Logger:          config.Logger,
+		TracerProvider:  config.TracerProvider,
+		DisabledApis:    config.DisabledApis,
+		SPA:             config.SPA,
+		InstanceId:      config.InstanceId,
+		BasePath:        config.BasePath,
+	})}
 
 	return pb
 }
 
+// baseExecDir tries to find the base executable directory
+// and whether it was run with "go run".
+func baseExecDir() (dir string, withGoRun bool) {
+	if strings.HasPrefix(os.Args[0], os.TempDir()) {
+		// probably ran with go run...
+		dir, _ = os.Getwd()
+		return dir, true
+	}
+
+	// probably ran with go build...
+	return filepath.Dir(os.Args[0]), false
+}
+
 // DefaultDebug sets the default --debug flag value.
 func (pb *PocketBase) DefaultDebug(val bool) *PocketBase {
 	pb.defaultDebug = val
@@ -128,11 +210,12 @@ func (pb *PocketBase) ShowStartBanner(val bool) *PocketBase {
 }
 
 // Start starts the application, aka. registers the default system
-// commands (serve, migrate, version) and executes pb.RootCmd.
+// commands (serve, migrate, mirrors, version) and executes pb.RootCmd.
 func (pb *PocketBase) Start() error {
 	// register system commands
 	pb.RootCmd.AddCommand(cmd.NewServeCommand(pb, pb.showStartBanner))
 	pb.RootCmd.AddCommand(cmd.NewMigrateCommand(pb))
+	pb.RootCmd.AddCommand(cmd.NewMirrorsCommand(pb))
 
 	return pb.Execute()
 }
@@ -142,35 +225,41 @@ func (pb *PocketBase) Start() error {
 //
 // This method differs from pb.Start() by not registering the default
 // system commands!
+//
+// The error returned by the executed command (if any) is returned
+// after the app resources cleanup.
 func (pb *PocketBase) Execute() error {
 	if err := pb.Bootstrap(); err != nil {
 		return err
 	}
 
-	var wg sync.WaitGroup
-
-	wg.Add(1)
-
 	// wait for interrupt signal to gracefully shutdown the application
-	go func() {
-		defer wg.Done()
-		quit := make(chan os.Signal, 1) // we need to reserve to buffer size 1, so the notifier are not blocked
-		signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
-		<-quit
-	}()
+	quit := make(chan os.Signal, 1) // we need to reserve to buffer size 1, so the notifier are not blocked
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(quit)
 
 	// execute the root command
+	done := make(chan error, 1)
 	go func() {
-		defer wg.Done()
-		if err := pb.RootCmd.Execute(); err != nil {
-			log.Println(err)
-		}
+		done <- pb.RootCmd.Execute()
 	}()
 
-	wg.Wait()
+	var cmdErr error
+	select {
+	case <-quit:
+	case cmdErr = <-done:
+	}
 
 	// cleanup
-	return pb.onTerminate()
+	if err := pb.onTerminate(); err != nil {
+		if cmdErr != nil {
+			log.Println(err)
+		} else {
+			return err
+		}
+	}
+
+	return cmdErr
 }
 
 // onTerminate tries to release the app resources on app termination.
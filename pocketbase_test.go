@@ -1,8 +1,14 @@
 package pocketbase
 
 import (
+	"errors"
+	"io"
+	"log"
 	"os"
 	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/spf13/cobra"
 )
 
 func TestNew(t *testing.T) {
@@ -45,6 +51,58 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestNewWithConfig(t *testing.T) {
+	testDir := "./pb_test_data_dir"
+	defer os.RemoveAll(testDir)
+
+	// reset os.Args
+	os.Args = os.Args[0:1]
+
+	logger := log.New(io.Discard, "", 0)
+	dbConfig := core.DefaultDBConfig()
+	dbConfig.MaxOpenConns = 10
+
+	app := NewWithConfig(Config{
+		DefaultDebug:         true,
+		DefaultDataDir:       testDir,
+		DefaultEncryptionEnv: "test_encryption_env",
+		HideStartBanner:      true,
+		DB:                   &dbConfig,
+		DefaultSettings: func(s *core.Settings) {
+			s.Meta.AppName = "test_app"
+		},
+		Logger: logger,
+	})
+
+	if app.DataDir() != testDir {
+		t.Fatalf("Expected app.DataDir() %q, got %q", testDir, app.DataDir())
+	}
+
+	if app.EncryptionEnv() != "test_encryption_env" {
+		t.Fatalf("Expected app.EncryptionEnv() test_encryption_env, got %q", app.EncryptionEnv())
+	}
+
+	if !app.IsDebug() {
+		t.Fatal("Expected app.IsDebug() true, got false")
+	}
+
+	if app.showStartBanner {
+		t.Fatal("Expected showStartBanner false, got true")
+	}
+
+	if app.DBConfig().MaxOpenConns != 10 {
+		t.Fatalf("Expected MaxOpenConns 10, got %d", app.DBConfig().MaxOpenConns)
+	}
+
+	if app.Settings().Meta.AppName != "test_app" {
+		t.Fatalf("Expected the default settings app name to be test_app, got %q", app.Settings().Meta.AppName)
+	}
+
+	if app.Logger() != logger {
+		t.Fatal("Expected the config logger to be set")
+	}
+}
+
 func TestDefaultDebug(t *testing.T) {
 	app := New()
 
@@ -94,3 +152,53 @@ func TestShowStartBanner(t *testing.T) {
 		t.Fatalf("Expected showStartBanner %v, got %v", false, app.showStartBanner)
 	}
 }
+
+func TestExecuteCustomCommand(t *testing.T) {
+	testDir := "./pb_test_data_dir"
+	defer os.RemoveAll(testDir)
+
+	// reset os.Args
+	os.Args = os.Args[0:1]
+	os.Args = append(os.Args, "--dir="+testDir)
+
+	app := New()
+
+	var executed, bootstrapped bool
+	app.RootCmd.AddCommand(&cobra.Command{
+		Use: "custom",
+		Run: func(command *cobra.Command, args []string) {
+			executed = true
+			bootstrapped = app.Dao() != nil && app.Settings() != nil
+		},
+	})
+	app.RootCmd.AddCommand(&cobra.Command{
+		Use:           "failing",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(command *cobra.Command, args []string) error {
+			return errors.New("test")
+		},
+	})
+
+	app.RootCmd.SetArgs([]string{"custom"})
+	if err := app.Execute(); err != nil {
+		t.Fatalf("Expected nil error, got %v", err)
+	}
+
+	if !executed {
+		t.Fatal("Expected the custom command to be executed")
+	}
+
+	if !bootstrapped {
+		t.Fatal("Expected the app to be bootstrapped on command execution")
+	}
+
+	if app.Dao() != nil {
+		t.Fatal("Expected the app resources to be released after the command execution")
+	}
+
+	app.RootCmd.SetArgs([]string{"failing"})
+	if err := app.Execute(); err == nil || err.Error() != "test" {
+		t.Fatalf("Expected the command error to be returned, got %v", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/ganigeorgiev/fexpr"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/daos"
 	"github.com/pocketbase/pocketbase/models"
@@ -19,6 +20,16 @@ import (
 // ensure that `search.FieldResolver` interface is implemented
 var _ search.FieldResolver = (*RecordFieldResolver)(nil)
 
+// ensure that `search.InFieldResolver` interface is implemented
+var _ search.InFieldResolver = (*RecordFieldResolver)(nil)
+
+// ensure that `search.SortFieldResolver` interface is implemented
+var _ search.SortFieldResolver = (*RecordFieldResolver)(nil)
+
+// MaxSortRelationDepth specifies the max allowed number of
+// relation fields in a single sort field path (eg. "author.group.name" = 2).
+const MaxSortRelationDepth = 3
+
 type join struct {
 	table string
 	on    dbx.Expression
@@ -145,6 +156,16 @@ func (r *RecordFieldResolver) Resolve(fieldName string) (resultName string, plac
 			return "", nil, fmt.Errorf("Unrecognized field %q.", prop)
 		}
 
+		// localized field (optionally followed by an explicit locale, eg. "title.en")
+		if field.Type == schema.FieldTypeLocalized && i >= totalProps-2 {
+			locale := ""
+			if i == totalProps-2 {
+				locale = props[i+1]
+			}
+
+			return r.resolveLocalizedField(currentTableAlias, field, locale)
+		}
+
 		// last prop
 		if i == totalProps-1 {
 			return fmt.Sprintf("[[%s.%s]]", inflector.Columnify(currentTableAlias), inflector.Columnify(prop)), nil, nil
@@ -185,6 +206,229 @@ func (r *RecordFieldResolver) Resolve(fieldName string) (resultName string, plac
 	return "", nil, fmt.Errorf("Failed to resolve field %q.", fieldName)
 }
 
+// resolveLocalizedField resolves the single locale value of a localized field.
+//
+// If locale is not set, the locale is resolved from the request locales
+// preference (see [models.Record.SetLocale]).
+func (r *RecordFieldResolver) resolveLocalizedField(tableAlias string, field *schema.SchemaField, locale string) (resultName string, placeholderParams dbx.Params, err error) {
+	field.InitOptions()
+	options, ok := field.Options.(*schema.LocalizedOptions)
+	if !ok {
+		return "", nil, fmt.Errorf("Failed to initialize field %q options.", field.Name)
+	}
+
+	if locale == "" {
+		preferred, _ := r.requestData["locales"].([]string)
+		locale = options.ResolveLocale(preferred)
+	} else if match := options.MatchLocale(locale); match != "" {
+		locale = match
+	} else {
+		return "", nil, fmt.Errorf("Unknown field %q locale %q.", field.Name, locale)
+	}
+
+	// the locale is one of the validated field locales so it is safe
+	// to be inlined (this also allows the expression to be used for sorting)
+	return fmt.Sprintf(
+		"JSON_EXTRACT([[%s.%s]], '$.\"%s\"')",
+		inflector.Columnify(tableAlias),
+		inflector.Columnify(field.Name),
+		locale,
+	), nil, nil
+}
+
+// IsMultiValueField implements `search.InFieldResolver` interface.
+//
+// Returns true for the select, file, relation and user schema fields
+// that could store more than one value.
+func (r *RecordFieldResolver) IsMultiValueField(fieldName string) bool {
+	field := r.findSchemaField(fieldName)
+	if field == nil {
+		return false
+	}
+
+	field.InitOptions()
+
+	switch options := field.Options.(type) {
+	case *schema.SelectOptions:
+		return options.MaxSelect != 1
+	case *schema.FileOptions:
+		return options.MaxSelect != 1
+	case *schema.RelationOptions:
+		return options.MaxSelect != 1
+	case *schema.UserOptions:
+		return options.MaxSelect != 1
+	}
+
+	return false
+}
+
+// NormalizeInValue implements `search.InFieldResolver` interface.
+//
+// Validates and casts the provided `in` list value based on the resolved schema field type.
+func (r *RecordFieldResolver) NormalizeInValue(fieldName string, value string) (any, error) {
+	field := r.findSchemaField(fieldName)
+	if field == nil {
+		return value, nil // base model field or unresolvable
+	}
+
+	switch field.Type {
+	case schema.FieldTypeNumber:
+		v, err := cast.ToFloat64E(value)
+		if err != nil {
+			return nil, fmt.Errorf("Expected number value, got %q.", value)
+		}
+		return v, nil
+	case schema.FieldTypeDecimal:
+		return r.NormalizeLiteral(fieldName, fexpr.SignEq, value)
+	case schema.FieldTypeBool:
+		v, err := cast.ToBoolE(value)
+		if err != nil {
+			return nil, fmt.Errorf("Expected bool value, got %q.", value)
+		}
+		return v, nil
+	case schema.FieldTypeSelect:
+		field.InitOptions()
+		options, _ := field.Options.(*schema.SelectOptions)
+		if options != nil && len(options.Values) > 0 && !list.ExistInSlice(value, options.Values) {
+			return nil, fmt.Errorf("Value %q is not an allowed select option.", value)
+		}
+	}
+
+	return value, nil
+}
+
+// NormalizeLiteral implements `search.LiteralFieldResolver` interface.
+//
+// Converts the literals compared with a decimal field into the
+// field scaled integer representation so that the comparison is exact.
+// The other literals are returned unmodified.
+//
+// Equality literals with more decimal places than the field scale
+// are rejected (they can never match a stored value) and the ordering
+// ones are rounded toward the side that keeps the comparison result
+// (eg. with scale 2 "price < 9.991" becomes "price < 10.00"
+// and "price <= 9.991" becomes "price <= 9.99").
+func (r *RecordFieldResolver) NormalizeLiteral(fieldName string, op fexpr.SignOp, value string) (any, error) {
+	field := r.findSchemaField(fieldName)
+	if field == nil || field.Type != schema.FieldTypeDecimal {
+		return value, nil
+	}
+
+	field.InitOptions()
+	options, _ := field.Options.(*schema.DecimalOptions)
+	if options == nil {
+		return value, nil
+	}
+
+	switch op {
+	case fexpr.SignLt, fexpr.SignGte:
+		return options.ScaledCeil(value)
+	case fexpr.SignLte, fexpr.SignGt:
+		return options.ScaledFloor(value)
+	default:
+		return options.ScaledExact(value)
+	}
+}
+
+// CheckSortField implements `search.SortFieldResolver` interface.
+//
+// Allows sorting by relation field paths (eg. "author.name") only if
+// all path relations are single-value (to prevent ambiguous joins)
+// and the path doesn't exceed [MaxSortRelationDepth].
+func (r *RecordFieldResolver) CheckSortField(fieldName string) error {
+	props := strings.Split(fieldName, ".")
+
+	currentCollectionName := r.baseCollection.Name
+
+	if props[0] == "@collection" {
+		if len(props) < 3 {
+			return nil // will fail on resolve
+		}
+		currentCollectionName = props[1]
+		props = props[2:]
+	} else if strings.HasPrefix(props[0], "@") {
+		return nil
+	}
+
+	if len(props)-1 > MaxSortRelationDepth {
+		return fmt.Errorf("Max %d nested relations are allowed.", MaxSortRelationDepth)
+	}
+
+	// check the intermediate path relations
+	for _, prop := range props[:len(props)-1] {
+		collection, err := r.loadCollection(currentCollectionName)
+		if err != nil {
+			return nil // will fail on resolve
+		}
+
+		field := collection.Schema.GetFieldByName(prop)
+		if field == nil {
+			return nil // will fail on resolve
+		}
+
+		field.InitOptions()
+		options, ok := field.Options.(*schema.RelationOptions)
+		if !ok {
+			return nil // will fail on resolve
+		}
+
+		if options.MaxSelect != 1 {
+			return fmt.Errorf("Sorting by multiple relation field %q is not supported.", prop)
+		}
+
+		currentCollectionName = options.CollectionId
+	}
+
+	return nil
+}
+
+// findSchemaField returns the schema field of the last fieldName prop
+// (eg. "title", "project.screen.title", "@collection.product.name").
+//
+// Returns nil for base model fields or unresolvable field paths.
+func (r *RecordFieldResolver) findSchemaField(fieldName string) *schema.SchemaField {
+	props := strings.Split(fieldName, ".")
+
+	currentCollectionName := r.baseCollection.Name
+
+	if props[0] == "@collection" {
+		if len(props) < 3 {
+			return nil
+		}
+		currentCollectionName = props[1]
+		props = props[2:]
+	} else if strings.HasPrefix(props[0], "@") {
+		return nil
+	}
+
+	for i, prop := range props {
+		collection, err := r.loadCollection(currentCollectionName)
+		if err != nil {
+			return nil
+		}
+
+		field := collection.Schema.GetFieldByName(prop)
+		if field == nil {
+			return nil
+		}
+
+		// last prop
+		if i == len(props)-1 {
+			return field
+		}
+
+		field.InitOptions()
+		options, ok := field.Options.(*schema.RelationOptions)
+		if !ok {
+			return nil
+		}
+
+		currentCollectionName = options.CollectionId
+	}
+
+	return nil
+}
+
 func (r *RecordFieldResolver) resolveRequestField(path ...string) (resultName string, placeholderParams dbx.Params, err error) {
 	// ignore error because requestData is dynamic and some of the
 	// lookup keys may not be defined for the request
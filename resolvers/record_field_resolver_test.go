@@ -2,11 +2,16 @@ package resolvers_test
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
 	"testing"
 
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
 	"github.com/pocketbase/pocketbase/resolvers"
 	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/search"
 )
 
 func TestRecordFieldResolverUpdateQuery(t *testing.T) {
@@ -153,6 +158,65 @@ func TestRecordFieldResolverResolveSchemaFields(t *testing.T) {
 	}
 }
 
+func TestRecordFieldResolverResolveLocalizedFields(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := &models.Collection{
+		Name: "test",
+		Schema: schema.NewSchema(
+			&schema.SchemaField{
+				Name: "title",
+				Type: schema.FieldTypeLocalized,
+				Options: &schema.LocalizedOptions{
+					Locales:       []string{"en", "bg", "pt-BR"},
+					DefaultLocale: "en",
+				},
+			},
+		),
+	}
+
+	scenarios := []struct {
+		fieldName    string
+		locales      []string
+		expectError  bool
+		expectLocale string
+	}{
+		{"title", nil, false, "en"},
+		{"title", []string{"de", "bg"}, false, "bg"},
+		{"title", []string{"pt-br"}, false, "pt-BR"},
+		{"title.bg", []string{"pt-BR"}, false, "bg"},
+		{"title.BG", nil, false, "bg"},
+		{"title.de", nil, true, ""},
+		{"title.en.test", nil, true, ""},
+	}
+
+	for i, s := range scenarios {
+		r := resolvers.NewRecordFieldResolver(app.Dao(), collection, map[string]any{"locales": s.locales})
+
+		name, params, err := r.Resolve(s.fieldName)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+			continue
+		}
+
+		if hasErr {
+			continue
+		}
+
+		if len(params) != 0 {
+			t.Errorf("(%d) Expected 0 params, got %v", i, params)
+		}
+
+		expectName := fmt.Sprintf(`JSON_EXTRACT([[test.title]], '$."%s"')`, s.expectLocale)
+		if name != expectName {
+			t.Errorf("(%d) Expected name %q, got %q", i, expectName, name)
+		}
+	}
+}
+
 func TestRecordFieldResolverResolveRequestDataFields(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -243,3 +307,167 @@ func TestRecordFieldResolverResolveRequestDataFields(t *testing.T) {
 		}
 	}
 }
+
+func TestRecordFieldResolverIsMultiValueField(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, err := app.Dao().FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := resolvers.NewRecordFieldResolver(app.Dao(), collection, nil)
+
+	scenarios := []struct {
+		fieldName string
+		expected  bool
+	}{
+		{"id", false},
+		{"missing", false},
+		{"@request.data.select", false},
+		{"text", false},
+		{"onerel", false},
+		{"user", false},
+		{"file", false},
+		{"select", true},
+		{"manyrels", true},
+		{"onerel.title", false},
+		{"@collection.demo4.manyrels", true},
+		{"@collection.demo4.onerel", false},
+	}
+
+	for i, s := range scenarios {
+		if result := r.IsMultiValueField(s.fieldName); result != s.expected {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, result)
+		}
+	}
+}
+
+func TestRecordFieldResolverNormalizeInValue(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, err := app.Dao().FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := resolvers.NewRecordFieldResolver(app.Dao(), collection, nil)
+
+	scenarios := []struct {
+		fieldName   string
+		value       string
+		expectError bool
+		expected    any
+	}{
+		{"id", "abc", false, "abc"},
+		{"text", "123", false, "123"},
+		{"number", "abc", true, nil},
+		{"number", "1.5", false, 1.5},
+		{"bool", "abc", true, nil},
+		{"bool", "true", false, true},
+		{"select", "invalid", true, nil},
+		{"select", "a", false, "a"},
+		{"onerel.title", "abc", false, "abc"},
+	}
+
+	for i, s := range scenarios {
+		result, err := r.NormalizeInValue(s.fieldName, s.value)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+			continue
+		}
+
+		if result != s.expected {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, result)
+		}
+	}
+}
+
+func TestRecordFieldResolverInFilter(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, err := app.Dao().FindCollectionByNameOrId("demo4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		filter        string
+		expectedCount int
+	}{
+		{"id in ('054f9f24-0a0a-4e09-87b1-bc7ff2b336a2', 'b84cd893-7119-43c9-8505-3c4e22da28a9', 'missing')", 2},
+		{"id not in ('054f9f24-0a0a-4e09-87b1-bc7ff2b336a2')", 3},
+		{"onerel in ('b8ba58f9-e2d7-42a0-b0e7-a11efd98236b', '054f9f24-0a0a-4e09-87b1-bc7ff2b336a2')", 2},
+		{"manyrels in ('b84cd893-7119-43c9-8505-3c4e22da28a9')", 2},
+		{"manyrels in ('df55c8ff-45ef-4c82-8aed-6e2183fe1125', 'missing')", 1},
+		{"manyrels not in ('b84cd893-7119-43c9-8505-3c4e22da28a9')", 2},
+	}
+
+	for i, s := range scenarios {
+		r := resolvers.NewRecordFieldResolver(app.Dao(), collection, nil)
+
+		expr, err := search.FilterData(s.filter).BuildExpr(r)
+		if err != nil {
+			t.Errorf("(%d) Failed to build expression: %v", i, err)
+			continue
+		}
+
+		query := app.Dao().RecordQuery(collection).AndWhere(expr)
+		r.UpdateQuery(query)
+
+		rows := []dbx.NullStringMap{}
+		if err := query.All(&rows); err != nil {
+			t.Errorf("(%d) Query failed: %v", i, err)
+			continue
+		}
+
+		if len(rows) != s.expectedCount {
+			t.Errorf("(%d) Expected %d records, got %d", i, s.expectedCount, len(rows))
+		}
+	}
+}
+
+func TestRecordFieldResolverCheckSortField(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, err := app.Dao().FindCollectionByNameOrId("demo4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := resolvers.NewRecordFieldResolver(app.Dao(), collection, nil)
+
+	scenarios := []struct {
+		fieldName   string
+		expectError bool
+	}{
+		{"id", false},
+		{"title", false},
+		{"manyrels", false},
+		{"missing.title", false}, // fails on resolve
+		{"@request.user.id", false},
+		{"onerel.title", false},
+		{"onerel.onerel.onerel.title", false},
+		{"onerel.onerel.onerel.onerel.title", true},
+		{"manyrels.title", true},
+		{"onerel.manyrels.title", true},
+		{"onerel.manyrels", false},
+		{"@collection.demo4.onerel.title", false},
+		{"@collection.demo4.manyrels.title", true},
+	}
+
+	for i, s := range scenarios {
+		err := r.CheckSortField(s.fieldName)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+		}
+	}
+}
@@ -23,9 +23,11 @@ type ApiScenario struct {
 	Body           io.Reader
 	RequestHeaders map[string]string
 	// expectations
-	ExpectedStatus  int
-	ExpectedContent []string
-	ExpectedEvents  map[string]int
+	ExpectedStatus     int
+	ExpectedContent    []string
+	NotExpectedContent []string
+	ExpectedEvents     map[string]int
+	ExpectedHeaders    map[string]string
 	// test events
 	BeforeFunc func(t *testing.T, app *TestApp, e *echo.Echo)
 	AfterFunc  func(t *testing.T, app *TestApp, e *echo.Echo)
@@ -80,6 +82,12 @@ func (scenario *ApiScenario) Test(t *testing.T) {
 		t.Errorf("[%s] Expected status code %d, got %d", prefix, scenario.ExpectedStatus, res.StatusCode)
 	}
 
+	for k, v := range scenario.ExpectedHeaders {
+		if actual := res.Header.Get(k); actual != v {
+			t.Errorf("[%s] Expected header %s to be %q, got %q", prefix, k, v, actual)
+		}
+	}
+
 	if len(scenario.ExpectedContent) == 0 {
 		if len(recorder.Body.Bytes()) != 0 {
 			t.Errorf("[%s] Expected empty body, got %v", prefix, recorder.Body.String())
@@ -102,6 +110,13 @@ func (scenario *ApiScenario) Test(t *testing.T) {
 				break
 			}
 		}
+
+		for _, item := range scenario.NotExpectedContent {
+			if strings.Contains(normalizedBody, item) {
+				t.Errorf("[%s] Didn't expect %v in response body %v", prefix, item, normalizedBody)
+				break
+			}
+		}
 	}
 
 	if len(testApp.EventCalls) > len(scenario.ExpectedEvents) {
@@ -1,4 +1,4 @@
-// Package tests provides common helpers and mocks used in PocketBase application tests.
+// Package tests provides common helpers and mocks used in PocketBase application (and extensions) tests.
 package tests
 
 import (
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"runtime"
 
+	"github.com/labstack/echo/v5"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tools/mailer"
 )
@@ -20,6 +21,9 @@ type TestApp struct {
 	// (and how many times) were triggered.
 	EventCalls map[string]int
 	TestMailer *TestMailer
+
+	// router is the lazily initialized app api router (see [TestApp.Router]).
+	router *echo.Echo
 }
 
 // Cleanup resets the test application state and removes the test
@@ -50,15 +54,17 @@ func (t *TestApp) ResetEventCalls() {
 // It is the caller's responsibility to call `app.Cleanup()`
 // when the app is no longer needed.
 func NewTestApp() (*TestApp, error) {
-	tempDir, err := NewTempDataDir()
-	if err != nil {
-		return nil, err
-	}
-
-	app := core.NewBaseApp(tempDir, "pb_test_env", false)
+	return NewTestAppWithConfig(TestAppConfig{})
+}
 
-	// load data dir and db connections
-	if err := app.Bootstrap(); err != nil {
+// NewTestAppWithConfig creates and initializes a full application instance
+// for testing with the provided collections and seed records.
+//
+// It is the caller's responsibility to call `app.Cleanup()`
+// when the app is no longer needed.
+func NewTestAppWithConfig(config TestAppConfig) (*TestApp, error) {
+	app, err := newTestBaseApp(config)
+	if err != nil {
 		return nil, err
 	}
 
@@ -148,6 +154,16 @@ func NewTestApp() (*TestApp, error) {
 		return nil
 	})
 
+	t.OnRecordBeforePublishRequest().Add(func(e *core.RecordPublishEvent) error {
+		t.EventCalls["OnRecordBeforePublishRequest"]++
+		return nil
+	})
+
+	t.OnRecordAfterPublishRequest().Add(func(e *core.RecordPublishEvent) error {
+		t.EventCalls["OnRecordAfterPublishRequest"]++
+		return nil
+	})
+
 	t.OnUsersListRequest().Add(func(e *core.UsersListEvent) error {
 		t.EventCalls["OnUsersListRequest"]++
 		return nil
@@ -213,6 +229,16 @@ func NewTestApp() (*TestApp, error) {
 		return nil
 	})
 
+	t.OnMailerBeforeAdminMagicLinkSend().Add(func(e *core.MailerAdminEvent) error {
+		t.EventCalls["OnMailerBeforeAdminMagicLinkSend"]++
+		return nil
+	})
+
+	t.OnMailerAfterAdminMagicLinkSend().Add(func(e *core.MailerAdminEvent) error {
+		t.EventCalls["OnMailerAfterAdminMagicLinkSend"]++
+		return nil
+	})
+
 	t.OnMailerBeforeUserResetPasswordSend().Add(func(e *core.MailerUserEvent) error {
 		t.EventCalls["OnMailerBeforeUserResetPasswordSend"]++
 		return nil
@@ -258,6 +284,11 @@ func NewTestApp() (*TestApp, error) {
 		return nil
 	})
 
+	t.OnRealtimeBeforeRecordBroadcast().Add(func(e *core.RealtimeRecordBroadcastEvent) error {
+		t.EventCalls["OnRealtimeBeforeRecordBroadcast"]++
+		return nil
+	})
+
 	t.OnSettingsListRequest().Add(func(e *core.SettingsListEvent) error {
 		t.EventCalls["OnSettingsListRequest"]++
 		return nil
@@ -358,6 +389,26 @@ func NewTestApp() (*TestApp, error) {
 		return nil
 	})
 
+	t.OnAuthSuccess().Add(func(e *core.AuthAttemptEvent) error {
+		t.EventCalls["OnAuthSuccess"]++
+		return nil
+	})
+
+	t.OnAuthFailure().Add(func(e *core.AuthAttemptEvent) error {
+		t.EventCalls["OnAuthFailure"]++
+		return nil
+	})
+
+	t.OnAuthMeRequest().Add(func(e *core.AuthMeEvent) error {
+		t.EventCalls["OnAuthMeRequest"]++
+		return nil
+	})
+
+	t.OnFileBeforeUpload().Add(func(e *core.FileUploadEvent) error {
+		t.EventCalls["OnFileBeforeUpload"]++
+		return nil
+	})
+
 	t.OnFileDownloadRequest().Add(func(e *core.FileDownloadEvent) error {
 		t.EventCalls["OnFileDownloadRequest"]++
 		return nil
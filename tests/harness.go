@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"os"
+
+	"github.com/labstack/echo/v5"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/migrations/logs"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/tools/migrate"
+)
+
+// TestAppConfig defines the [NewTestAppWithConfig] test app options.
+type TestAppConfig struct {
+	// Blank starts the test app from an empty data dir with only the
+	// system migrations applied (instead of the default test data).
+	Blank bool
+
+	// IsDebug enables the test app debug mode.
+	IsDebug bool
+
+	// Collections specifies the collections to create (in the listed order).
+	Collections []*models.Collection
+
+	// Records specifies the records to seed (in the listed order)
+	// after the collections are created.
+	Records []SeedRecord
+}
+
+// SeedRecord defines a single [TestAppConfig] seed record.
+type SeedRecord struct {
+	// Collection is the name or id of the record collection.
+	Collection string
+
+	// Data is the record data (including the optional "id").
+	Data map[string]any
+}
+
+// newTestBaseApp creates and bootstraps a new base app in a temp data dir
+// and seeds it with the config collections and records.
+//
+// The temp data dir is removed if the app initialization fails.
+func newTestBaseApp(config TestAppConfig) (app *core.BaseApp, err error) {
+	var tempDir string
+	if config.Blank {
+		tempDir, err = os.MkdirTemp("", "pb_test_*")
+	} else {
+		tempDir, err = NewTempDataDir()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err != nil {
+			if app != nil {
+				app.ResetBootstrapState()
+			}
+			os.RemoveAll(tempDir)
+		}
+	}()
+
+	app = core.NewBaseApp(tempDir, "pb_test_env", config.IsDebug)
+
+	// load data dir and db connections
+	if err := app.Bootstrap(); err != nil {
+		return app, err
+	}
+
+	if config.Blank {
+		if err := runTestAppMigrations(app); err != nil {
+			return app, err
+		}
+	}
+
+	for _, collection := range config.Collections {
+		if err := app.Dao().SaveCollection(collection); err != nil {
+			return app, fmt.Errorf("Failed to create collection %q: %w", collection.Name, err)
+		}
+	}
+
+	for i, seed := range config.Records {
+		collection, err := app.Dao().FindCollectionByNameOrId(seed.Collection)
+		if err != nil {
+			return app, fmt.Errorf("Failed to find seed record %d collection %q: %w", i, seed.Collection, err)
+		}
+
+		record := models.NewRecord(collection)
+		if err := record.Load(seed.Data); err != nil {
+			return app, fmt.Errorf("Failed to load seed record %d: %w", i, err)
+		}
+		record.MarkAsNew() // the seed record could have a predefined id
+
+		if err := app.Dao().SaveRecord(record); err != nil {
+			return app, fmt.Errorf("Failed to save seed record %d: %w", i, err)
+		}
+	}
+
+	return app, nil
+}
+
+// runTestAppMigrations applies the system migrations of the
+// app data and logs databases and reloads the app settings.
+func runTestAppMigrations(app *core.BaseApp) error {
+	dataRunner, err := migrate.NewRunner(app.DB(), migrations.AppMigrations)
+	if err != nil {
+		return err
+	}
+	if _, err := dataRunner.Up(); err != nil {
+		return err
+	}
+
+	logsRunner, err := migrate.NewRunner(app.LogsDB(), logs.LogsMigrations)
+	if err != nil {
+		return err
+	}
+	if _, err := logsRunner.Up(); err != nil {
+		return err
+	}
+
+	return app.RefreshSettings()
+}
+
+// Router returns the test app api router.
+//
+// The router is initialized on the first call (triggering the app
+// OnBeforeServe hook) and reused by the subsequent calls, so the
+// app hooks should be registered before that.
+func (t *TestApp) Router() (*echo.Echo, error) {
+	if t.router != nil {
+		return t.router, nil
+	}
+
+	router, err := apis.InitApi(t)
+	if err != nil {
+		return nil, err
+	}
+
+	t.router = router
+
+	return router, nil
+}
+
+// Request simulates a single http request against the test app
+// router and returns the recorded response.
+//
+// The request Content-Type header defaults to "application/json".
+func (t *TestApp) Request(method string, url string, body io.Reader, headers map[string]string) (*httptest.ResponseRecorder, error) {
+	router, err := t.Router()
+	if err != nil {
+		return nil, err
+	}
+
+	req := httptest.NewRequest(method, url, body)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	return recorder, nil
+}
@@ -0,0 +1,101 @@
+package tests_test
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/models"
+	"github.com/pocketbase/pocketbase/models/schema"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestNewTestAppWithConfig(t *testing.T) {
+	app, err := tests.NewTestAppWithConfig(tests.TestAppConfig{
+		Blank: true,
+		Collections: []*models.Collection{
+			{
+				Name:       "posts",
+				ListRule:   strPointer(""),
+				CreateRule: strPointer(""),
+				Schema: schema.NewSchema(
+					&schema.SchemaField{Name: "title", Type: schema.FieldTypeText},
+				),
+			},
+		},
+		Records: []tests.SeedRecord{
+			{Collection: "posts", Data: map[string]any{"id": "seed_post_1", "title": "seed1"}},
+			{Collection: "posts", Data: map[string]any{"title": "seed2"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dataDir := app.DataDir()
+
+	// the default test data shouldn't be loaded
+	if _, err := app.Dao().FindCollectionByNameOrId("demo"); err == nil {
+		t.Fatal("Expected the blank app to not have the default test collections")
+	}
+
+	collection, err := app.Dao().FindCollectionByNameOrId("posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seeded, err := app.Dao().FindRecordById(collection, "seed_post_1", nil)
+	if err != nil || seeded.GetStringDataValue("title") != "seed1" {
+		t.Fatalf("Expected the seed record to be created, got %v (%v)", seeded, err)
+	}
+
+	app.OnModelBeforeCreate().Add(func(e *core.ModelEvent) error {
+		if record, ok := e.Model.(*models.Record); ok && record.Collection().Name == "posts" {
+			record.SetDataValue("title", strings.ToUpper(record.GetStringDataValue("title")))
+		}
+		return nil
+	})
+
+	res, err := app.Request(http.MethodPost, "/api/collections/posts/records", strings.NewReader(`{"title":"new"}`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Code != http.StatusOK || !strings.Contains(res.Body.String(), `"title":"NEW"`) {
+		t.Fatalf("Expected the hook modified record, got %d %s", res.Code, res.Body.String())
+	}
+
+	res, err = app.Request(http.MethodGet, "/api/collections/posts/records", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Code != http.StatusOK || !strings.Contains(res.Body.String(), `"totalItems":3`) {
+		t.Fatalf("Expected 3 records, got %d %s", res.Code, res.Body.String())
+	}
+
+	if app.EventCalls["OnRecordBeforeCreateRequest"] != 1 || app.EventCalls["OnRecordsListRequest"] != 1 {
+		t.Fatalf("Unexpected event calls %v", app.EventCalls)
+	}
+
+	app.Cleanup()
+
+	if _, err := os.Stat(dataDir); !os.IsNotExist(err) {
+		t.Fatalf("Expected the data dir %q to be removed, got %v", dataDir, err)
+	}
+}
+
+func TestNewTestAppWithConfigInvalidSeed(t *testing.T) {
+	_, err := tests.NewTestAppWithConfig(tests.TestAppConfig{
+		Records: []tests.SeedRecord{
+			{Collection: "missing", Data: map[string]any{}},
+		},
+	})
+	if err == nil {
+		t.Fatal("Expected the missing seed record collection to fail")
+	}
+}
+
+func strPointer(s string) *string {
+	return &s
+}
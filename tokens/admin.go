@@ -9,18 +9,33 @@ import (
 
 // NewAdminAuthToken generates and returns a new admin authentication token.
 func NewAdminAuthToken(app core.App, admin *models.Admin) (string, error) {
-	return security.NewToken(
+	return newToken(
+		app,
 		jwt.MapClaims{"id": admin.Id, "type": "admin"},
-		(admin.TokenKey + app.Settings().AdminAuthToken.Secret),
-		app.Settings().AdminAuthToken.Duration,
+		admin.TokenKey,
+		app.Settings().AdminAuthToken,
 	)
 }
 
 // NewAdminResetPasswordToken generates and returns a new admin password reset request token.
 func NewAdminResetPasswordToken(app core.App, admin *models.Admin) (string, error) {
-	return security.NewToken(
+	return newToken(
+		app,
 		jwt.MapClaims{"id": admin.Id, "type": "admin", "email": admin.Email},
-		(admin.TokenKey + app.Settings().AdminPasswordResetToken.Secret),
-		app.Settings().AdminPasswordResetToken.Duration,
+		admin.TokenKey,
+		app.Settings().AdminPasswordResetToken,
+	)
+}
+
+// NewAdminMagicLinkToken generates and returns a new admin magic link login token.
+//
+// Each token has a random nonce claim so that it could be tracked
+// (and invalidated) individually.
+func NewAdminMagicLinkToken(app core.App, admin *models.Admin) (string, error) {
+	return newToken(
+		app,
+		jwt.MapClaims{"id": admin.Id, "type": "admin", "email": admin.Email, "nonce": security.RandomString(20)},
+		admin.TokenKey,
+		app.Settings().AdminMagicLinkToken,
 	)
 }
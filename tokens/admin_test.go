@@ -5,6 +5,7 @@ import (
 
 	"github.com/pocketbase/pocketbase/tests"
 	"github.com/pocketbase/pocketbase/tokens"
+	"github.com/pocketbase/pocketbase/tools/security"
 )
 
 func TestNewAdminAuthToken(t *testing.T) {
@@ -28,6 +29,17 @@ func TestNewAdminAuthToken(t *testing.T) {
 	if tokenAdmin == nil || tokenAdmin.Id != admin.Id {
 		t.Fatalf("Expected admin %v, got %v", admin, tokenAdmin)
 	}
+
+	claims, _ := security.ParseUnverifiedJWT(token)
+	if claims["aud"] != app.TokensAudience() {
+		t.Fatalf("Expected aud claim %q, got %v", app.TokensAudience(), claims["aud"])
+	}
+
+	// token of another instance
+	app.Settings().Meta.TokensAudience = "other"
+	if tokenAdmin, _ := app.Dao().FindAdminByToken(token, app.Settings().AdminAuthToken.Secret); tokenAdmin != nil {
+		t.Fatalf("Expected the token with different audience to be rejected, got %v", tokenAdmin)
+	}
 }
 
 func TestNewAdminResetPasswordToken(t *testing.T) {
@@ -52,3 +64,31 @@ func TestNewAdminResetPasswordToken(t *testing.T) {
 		t.Fatalf("Expected admin %v, got %v", admin, tokenAdmin)
 	}
 }
+
+func TestNewAdminMagicLinkToken(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	admin, err := app.Dao().FindAdminByEmail("test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := tokens.NewAdminMagicLinkToken(app, admin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokenAdmin, _ := app.Dao().FindAdminByToken(
+		token,
+		app.Settings().AdminMagicLinkToken.Secret,
+	)
+	if tokenAdmin == nil || tokenAdmin.Id != admin.Id {
+		t.Fatalf("Expected admin %v, got %v", admin, tokenAdmin)
+	}
+
+	// the token shouldn't be valid for the other admin token types
+	if tokenAdmin, _ := app.Dao().FindAdminByToken(token, app.Settings().AdminAuthToken.Secret); tokenAdmin != nil {
+		t.Fatalf("Expected the magic link token to be rejected as auth token, got %v", tokenAdmin)
+	}
+}
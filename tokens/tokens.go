@@ -1,2 +1,22 @@
 // Package tokens implements various user and admin tokens generation methods.
 package tokens
+
+import (
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// newToken generates and returns a new token signed with the config's
+// current signing key mixed with the auth model's own tokenKey.
+//
+// The token "aud" claim is set to the app tokens audience.
+func newToken(app core.App, claims jwt.MapClaims, tokenKey string, config core.TokenConfig) (string, error) {
+	keyId, secret := config.SigningKey()
+
+	if aud := app.TokensAudience(); aud != "" {
+		claims["aud"] = aud
+	}
+
+	return security.NewTokenWithKeyId(claims, keyId, (tokenKey + secret), config.Duration)
+}
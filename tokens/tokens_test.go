@@ -0,0 +1,83 @@
+package tokens_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tokens"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+func TestTokensKeyRotation(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.Dao().FindUserByEmail("test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// token signed with the legacy secret
+	legacyToken, err := tokens.NewUserAuthToken(app, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid := security.ParseUnverifiedJWTKeyId(legacyToken); kid != "" {
+		t.Fatalf("Expected legacy token without kid, got %q", kid)
+	}
+
+	// rotate the signing key
+	oldKeyId := app.Settings().UserAuthToken.RotateKey()
+
+	oldKeyToken, err := tokens.NewUserAuthToken(app, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid := security.ParseUnverifiedJWTKeyId(oldKeyToken); kid != oldKeyId {
+		t.Fatalf("Expected token kid %q, got %q", oldKeyId, kid)
+	}
+
+	// rotate again
+	newKeyId := app.Settings().UserAuthToken.RotateKey()
+
+	newKeyToken, err := tokens.NewUserAuthToken(app, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid := security.ParseUnverifiedJWTKeyId(newKeyToken); kid != newKeyId {
+		t.Fatalf("Expected token kid %q, got %q", newKeyId, kid)
+	}
+
+	// all tokens should be still valid
+	for i, token := range []string{legacyToken, oldKeyToken, newKeyToken} {
+		tokenUser, _ := app.Dao().FindUserByToken(
+			token,
+			app.Settings().UserAuthToken.VerificationSecret(token),
+		)
+		if tokenUser == nil || tokenUser.Id != user.Id {
+			t.Fatalf("(%d) Expected user %v, got %v", i, user, tokenUser)
+		}
+	}
+
+	// remove the old key
+	app.Settings().UserAuthToken.Keys = app.Settings().UserAuthToken.Keys[1:]
+
+	tokenUser, _ := app.Dao().FindUserByToken(
+		oldKeyToken,
+		app.Settings().UserAuthToken.VerificationSecret(oldKeyToken),
+	)
+	if tokenUser != nil {
+		t.Fatalf("Expected the token signed with the removed key to be invalid, got %v", tokenUser)
+	}
+
+	// the other tokens should be still valid
+	for i, token := range []string{legacyToken, newKeyToken} {
+		tokenUser, _ := app.Dao().FindUserByToken(
+			token,
+			app.Settings().UserAuthToken.VerificationSecret(token),
+		)
+		if tokenUser == nil || tokenUser.Id != user.Id {
+			t.Fatalf("(%d) Expected user %v, got %v", i, user, tokenUser)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+// Package archive implements helpers for creating and extracting zip archives.
+package archive
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/tools/list"
+)
+
+// Create creates a new zip archive at dest with the content of the provided src directories.
+//
+// The files of each src directory are stored relative to that directory.
+// If a file with the same relative path exists in more than one src directory,
+// only the first occurrence is archived (aka. earlier src directories take precedence).
+//
+// skipPaths could be used to exclude specific relative files or
+// directories from the archive (eg. "backups", "data.db-wal").
+func Create(dest string, srcDirs []string, skipPaths ...string) (err error) {
+	if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
+		return err
+	}
+
+	zf, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(zf)
+
+	defer func() {
+		if closeErr := zw.Close(); err == nil {
+			err = closeErr
+		}
+
+		if closeErr := zf.Close(); err == nil {
+			err = closeErr
+		}
+
+		// cleanup the partially written archive
+		if err != nil {
+			os.Remove(dest)
+		}
+	}()
+
+	added := map[string]struct{}{}
+
+	for _, src := range srcDirs {
+		err = filepath.WalkDir(src, func(path string, d fs.DirEntry, walkErr error) error {
+			if walkErr != nil {
+				return walkErr
+			}
+
+			rel, err := filepath.Rel(src, path)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+
+			if rel == "." {
+				return nil
+			}
+
+			if list.ExistInSlice(rel, skipPaths) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if d.IsDir() || !d.Type().IsRegular() {
+				return nil
+			}
+
+			if _, ok := added[rel]; ok {
+				return nil // already archived from a previous src dir
+			}
+			added[rel] = struct{}{}
+
+			return addFile(zw, path, rel)
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func addFile(zw *zip.Writer, path string, name string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	header.Method = zip.Deflate
+
+	w, err := zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(w, f)
+
+	return err
+}
+
+// Extract extracts the zip archive at src into the dest directory.
+//
+// Archive entries that point outside of dest (eg. "../test.txt") are rejected.
+func Extract(src string, dest string) error {
+	zr, err := zip.OpenReader(src)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	if err := os.MkdirAll(dest, os.ModePerm); err != nil {
+		return err
+	}
+
+	destPrefix := filepath.Clean(dest) + string(os.PathSeparator)
+
+	for _, file := range zr.File {
+		path := filepath.Join(dest, file.Name)
+
+		if !strings.HasPrefix(path, destPrefix) {
+			return fmt.Errorf("Invalid archive file path %q.", file.Name)
+		}
+
+		if file.FileInfo().IsDir() {
+			if err := os.MkdirAll(path, os.ModePerm); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := extractFile(file, path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func extractFile(file *zip.File, path string) error {
+	if !file.Mode().IsRegular() {
+		return errors.New("Only regular archive files are supported.")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	r, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, file.Mode())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, r)
+
+	return err
+}
@@ -0,0 +1,142 @@
+package archive_test
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/archive"
+)
+
+func TestCreateAndExtract(t *testing.T) {
+	dir1 := createTestDir(t, map[string]string{
+		"a.txt":     "a1",
+		"sub/b.txt": "b1",
+	})
+	defer os.RemoveAll(dir1)
+
+	dir2 := createTestDir(t, map[string]string{
+		"a.txt":          "a2", // duplicated
+		"c.txt":          "c2",
+		"skip.txt":       "skip",
+		"skipdir/d.txt":  "d2",
+		"sub/skip.txt":   "sub_skip",
+		"sub/nested.txt": "nested",
+	})
+	defer os.RemoveAll(dir2)
+
+	tempDir, err := os.MkdirTemp(os.TempDir(), "pb_test_archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	zipPath := filepath.Join(tempDir, "test.zip")
+
+	if err := archive.Create(zipPath, []string{dir1, dir2}, "skip.txt", "skipdir", "sub/skip.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	extractDir := filepath.Join(tempDir, "extract")
+
+	if err := archive.Extract(zipPath, extractDir); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedFiles := map[string]string{
+		"a.txt":          "a1",
+		"sub/b.txt":      "b1",
+		"c.txt":          "c2",
+		"sub/nested.txt": "nested",
+	}
+
+	for name, content := range expectedFiles {
+		data, err := os.ReadFile(filepath.Join(extractDir, name))
+		if err != nil {
+			t.Errorf("Failed to read %q: %v", name, err)
+			continue
+		}
+
+		if string(data) != content {
+			t.Errorf("Expected %q content %q, got %q", name, content, string(data))
+		}
+	}
+
+	missingFiles := []string{"skip.txt", "skipdir/d.txt", "sub/skip.txt"}
+	for _, name := range missingFiles {
+		if _, err := os.Stat(filepath.Join(extractDir, name)); err == nil {
+			t.Errorf("Expected %q to not be archived", name)
+		}
+	}
+}
+
+func TestCreateMissingSrc(t *testing.T) {
+	tempDir, err := os.MkdirTemp(os.TempDir(), "pb_test_archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	zipPath := filepath.Join(tempDir, "test.zip")
+
+	if err := archive.Create(zipPath, []string{filepath.Join(tempDir, "missing")}); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	if _, err := os.Stat(zipPath); err == nil {
+		t.Fatal("Expected the partial archive to be deleted")
+	}
+}
+
+func TestExtractInvalidPath(t *testing.T) {
+	tempDir, err := os.MkdirTemp(os.TempDir(), "pb_test_archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	zipPath := filepath.Join(tempDir, "test.zip")
+
+	zf, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(zf)
+	w, err := zw.Create("../outside.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("test"))
+	zw.Close()
+	zf.Close()
+
+	if err := archive.Extract(zipPath, filepath.Join(tempDir, "extract")); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	if _, err := os.Stat(filepath.Join(tempDir, "outside.txt")); err == nil {
+		t.Fatal("Expected the file to not be extracted outside of the dest dir")
+	}
+}
+
+func createTestDir(t *testing.T, files map[string]string) string {
+	dir, err := os.MkdirTemp(os.TempDir(), "pb_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
@@ -1,8 +1,10 @@
 package filesystem
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
@@ -93,12 +95,29 @@ func (s *System) Attributes(fileKey string) (*blob.Attributes, error) {
 
 // Upload writes content into the fileKey location.
 func (s *System) Upload(content []byte, fileKey string) error {
-	w, writerErr := s.bucket.NewWriter(s.ctx, fileKey, nil)
+	return s.UploadReader(bytes.NewReader(content), fileKey, "")
+}
+
+// UploadReader streams the reader content into the fileKey location.
+//
+// If contentType is empty, it is auto detected from the content.
+// On read failure the write is aborted and no file is stored.
+func (s *System) UploadReader(reader io.Reader, fileKey string, contentType string) error {
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+
+	var opts *blob.WriterOptions
+	if contentType != "" {
+		opts = &blob.WriterOptions{ContentType: contentType}
+	}
+
+	w, writerErr := s.bucket.NewWriter(ctx, fileKey, opts)
 	if writerErr != nil {
 		return writerErr
 	}
 
-	if _, err := w.Write(content); err != nil {
+	if _, err := io.Copy(w, reader); err != nil {
+		cancel() // abort the write
 		w.Close()
 		return err
 	}
@@ -175,32 +194,177 @@ func (s *System) DeletePrefix(prefix string) []error {
 }
 
 // Serve serves the file at fileKey location to an HTTP response.
-func (s *System) Serve(response http.ResponseWriter, fileKey string, name string) error {
-	r, readErr := s.bucket.NewReader(s.ctx, fileKey, nil)
-	if readErr != nil {
-		return readErr
+//
+// If the request has a valid "Range" header, only the requested bytes
+// range is read from the storage and served with 206 Partial Content.
+// Multi-range requests are served with only their first range.
+func (s *System) Serve(response http.ResponseWriter, request *http.Request, fileKey string, name string) error {
+	attrs, attrsErr := s.bucket.Attributes(s.ctx, fileKey)
+	if attrsErr != nil {
+		return attrsErr
 	}
-	defer r.Close()
 
 	response.Header().Set("Content-Disposition", "attachment; filename="+name)
-	response.Header().Set("Content-Type", r.ContentType())
-	response.Header().Set("Content-Length", strconv.FormatInt(r.Size(), 10))
+	response.Header().Set("Content-Type", attrs.ContentType)
+	response.Header().Set("Accept-Ranges", "bytes")
 
 	// All HTTP date/time stamps MUST be represented in Greenwich Mean Time (GMT)
 	// (see https://www.w3.org/Protocols/rfc2616/rfc2616-sec3.html#sec3.3.1)
 	//
 	// NB! time.LoadLocation may fail on non-Unix systems (see https://github.com/pocketbase/pocketbase/issues/45)
+	var lastModified string
 	location, locationErr := time.LoadLocation("GMT")
 	if locationErr == nil {
-		response.Header().Set("Last-Modified", r.ModTime().In(location).Format("Mon, 02 Jan 06 15:04:05 MST"))
+		lastModified = attrs.ModTime.In(location).Format("Mon, 02 Jan 06 15:04:05 MST")
+		response.Header().Set("Last-Modified", lastModified)
+	}
+
+	// not all storage drivers provide an ETag so fallback to
+	// a weak validator generated from the file modification time and size
+	etag := attrs.ETag
+	if etag == "" {
+		etag = fmt.Sprintf(`W/"%x-%x"`, attrs.ModTime.UnixNano(), attrs.Size)
+	}
+	response.Header().Set("ETag", etag)
+
+	if request != nil && matchETag(request.Header.Get("If-None-Match"), etag) {
+		response.WriteHeader(http.StatusNotModified)
+		return nil
 	}
 
+	var offset int64
+	var length int64 = -1
+	status := http.StatusOK
+
+	rangeHeader := ""
+	if request != nil {
+		rangeHeader = request.Header.Get("Range")
+
+		// serve the full content if the file has changed since the client cached validator
+		if ifRange := request.Header.Get("If-Range"); ifRange != "" && ifRange != lastModified && ifRange != etag {
+			rangeHeader = ""
+		}
+	}
+
+	if rangeHeader != "" {
+		start, end, rangeErr := parseRange(rangeHeader, attrs.Size)
+		if errors.Is(rangeErr, errRangeNotSatisfiable) {
+			response.Header().Set("Content-Range", "bytes */"+strconv.FormatInt(attrs.Size, 10))
+			response.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return nil
+		}
+
+		// malformed range headers are ignored and the full content is served
+		if rangeErr == nil {
+			offset = start
+			length = end - start + 1
+			status = http.StatusPartialContent
+			response.Header().Set("Content-Range", "bytes "+
+				strconv.FormatInt(start, 10)+"-"+strconv.FormatInt(end, 10)+"/"+strconv.FormatInt(attrs.Size, 10))
+		}
+	}
+
+	r, readErr := s.bucket.NewRangeReader(s.ctx, fileKey, offset, length, nil)
+	if readErr != nil {
+		return readErr
+	}
+	defer r.Close()
+
+	if length < 0 {
+		length = r.Size()
+	}
+	response.Header().Set("Content-Length", strconv.FormatInt(length, 10))
+	response.WriteHeader(status)
+
 	// copy from the read range to response.
 	_, err := io.Copy(response, r)
 
 	return err
 }
 
+// matchETag reports whether the If-None-Match header value matches
+// the provided etag (using the weak comparison).
+func matchETag(header string, etag string) bool {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return false
+	}
+
+	if header == "*" {
+		return true
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+var errRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// parseRange parses the first range of a "bytes=..." Range header
+// and returns its inclusive start and end positions.
+//
+// Returns errRangeNotSatisfiable if the range is out of the size bounds.
+func parseRange(header string, size int64) (int64, int64, error) {
+	const prefix = "bytes="
+	if !strings.HasPrefix(header, prefix) {
+		return 0, 0, errors.New("unsupported range unit")
+	}
+
+	// use only the first range (if multiple)
+	spec := strings.TrimSpace(strings.SplitN(header[len(prefix):], ",", 2)[0])
+
+	parts := strings.SplitN(spec, "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, errors.New("invalid range format")
+	}
+
+	startStr := strings.TrimSpace(parts[0])
+	endStr := strings.TrimSpace(parts[1])
+
+	// suffix range (eg. "-500" - the last 500 bytes)
+	if startStr == "" {
+		suffix, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil || suffix < 0 {
+			return 0, 0, errors.New("invalid suffix range")
+		}
+		if suffix == 0 || size == 0 {
+			return 0, 0, errRangeNotSatisfiable
+		}
+		if suffix > size {
+			suffix = size
+		}
+		return size - suffix, size - 1, nil
+	}
+
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, errors.New("invalid range start")
+	}
+
+	if start >= size {
+		return 0, 0, errRangeNotSatisfiable
+	}
+
+	end := size - 1
+	if endStr != "" {
+		end, err = strconv.ParseInt(endStr, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, errors.New("invalid range end")
+		}
+		if end >= size {
+			end = size - 1
+		}
+	}
+
+	return start, end, nil
+}
+
 // CreateThumb creates a new thumb image for the file at originalKey location.
 // The new thumb file is stored at thumbKey location.
 //
@@ -1,12 +1,17 @@
 package filesystem_test
 
 import (
+	"errors"
 	"image"
 	"image/png"
+	"io"
+	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/pocketbase/pocketbase/tools/filesystem"
 )
@@ -147,6 +152,38 @@ func TestFileSystemUpload(t *testing.T) {
 	}
 }
 
+func TestFileSystemUploadReader(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	fs, err := filesystem.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+
+	// failed read
+	failedReader := io.MultiReader(strings.NewReader("demo"), iotest.ErrReader(errors.New("test")))
+	if err := fs.UploadReader(failedReader, "failed.txt", ""); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+	if exists, _ := fs.Exists("failed.txt"); exists {
+		t.Fatal("Expected failed.txt to not exist")
+	}
+
+	// successful read with custom content type
+	if err := fs.UploadReader(strings.NewReader("demo"), "newdir/newkey.txt", "text/csv"); err != nil {
+		t.Fatal(err)
+	}
+	attrs, err := fs.Attributes("newdir/newkey.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attrs.ContentType != "text/csv" {
+		t.Fatalf("Expected text/csv content type, got %q", attrs.ContentType)
+	}
+}
+
 func TestFileSystemServe(t *testing.T) {
 	dir := createTestDir(t)
 	defer os.RemoveAll(dir)
@@ -158,14 +195,15 @@ func TestFileSystemServe(t *testing.T) {
 	defer fs.Close()
 
 	r := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
 
 	// serve missing file
-	if err := fs.Serve(r, "missing.txt", "download.txt"); err == nil {
+	if err := fs.Serve(r, req, "missing.txt", "download.txt"); err == nil {
 		t.Fatal("Expected error, got nil")
 	}
 
 	// serve existing file
-	if err := fs.Serve(r, "test/sub1.txt", "download.txt"); err != nil {
+	if err := fs.Serve(r, req, "test/sub1.txt", "download.txt"); err != nil {
 		t.Fatal("Expected nil, got error")
 	}
 
@@ -188,6 +226,152 @@ func TestFileSystemServe(t *testing.T) {
 	}
 }
 
+func TestFileSystemServeRange(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	fs, err := filesystem.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+
+	if err := fs.Upload([]byte("0123456789"), "range.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		rangeHeader     string
+		ifRangeHeader   string
+		expectedStatus  int
+		expectedContent string
+		expectedRange   string
+	}{
+		{"", "", 200, "0123456789", ""},
+		{"invalid", "", 200, "0123456789", ""},
+		{"bytes=5-1", "", 200, "0123456789", ""},
+		{"bytes=0-0", "", 206, "0", "bytes 0-0/10"},
+		{"bytes=2-5", "", 206, "2345", "bytes 2-5/10"},
+		{"bytes=7-", "", 206, "789", "bytes 7-9/10"},
+		{"bytes=7-100", "", 206, "789", "bytes 7-9/10"},
+		{"bytes=-2", "", 206, "89", "bytes 8-9/10"},
+		{"bytes=-100", "", 206, "0123456789", "bytes 0-9/10"},
+		{"bytes=1-2, 5-6", "", 206, "12", "bytes 1-2/10"},
+		{"bytes=10-", "", 416, "", "bytes */10"},
+		{"bytes=-0", "", 416, "", "bytes */10"},
+		{"bytes=1-2", "Mon, 02 Jan 06 15:04:05 GMT", 200, "0123456789", ""},
+	}
+
+	for i, s := range scenarios {
+		r := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if s.rangeHeader != "" {
+			req.Header.Set("Range", s.rangeHeader)
+		}
+		if s.ifRangeHeader != "" {
+			req.Header.Set("If-Range", s.ifRangeHeader)
+		}
+
+		if err := fs.Serve(r, req, "range.txt", "download.txt"); err != nil {
+			t.Errorf("(%d) Expected nil, got error %v", i, err)
+			continue
+		}
+
+		result := r.Result()
+
+		if result.StatusCode != s.expectedStatus {
+			t.Errorf("(%d) Expected status %d, got %d", i, s.expectedStatus, result.StatusCode)
+		}
+
+		if body := r.Body.String(); body != s.expectedContent {
+			t.Errorf("(%d) Expected content %q, got %q", i, s.expectedContent, body)
+		}
+
+		if v := result.Header.Get("Content-Range"); v != s.expectedRange {
+			t.Errorf("(%d) Expected Content-Range %q, got %q", i, s.expectedRange, v)
+		}
+
+		if v := result.Header.Get("Accept-Ranges"); v != "bytes" {
+			t.Errorf("(%d) Expected Accept-Ranges bytes, got %q", i, v)
+		}
+	}
+}
+
+func TestFileSystemServeETag(t *testing.T) {
+	dir := createTestDir(t)
+	defer os.RemoveAll(dir)
+
+	fs, err := filesystem.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+
+	if err := fs.Upload([]byte("0123456789"), "etag.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRecorder()
+	if err := fs.Serve(r, httptest.NewRequest(http.MethodGet, "/", nil), "etag.txt", "download.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	etag := r.Result().Header.Get("ETag")
+	if etag == "" {
+		t.Fatal("Expected ETag header to be set")
+	}
+
+	scenarios := []struct {
+		ifNoneMatch     string
+		ifRange         string
+		rangeHeader     string
+		expectedStatus  int
+		expectedContent string
+	}{
+		{"", "", "", 200, "0123456789"},
+		{`"invalid"`, "", "", 200, "0123456789"},
+		{etag, "", "", 304, ""},
+		{"W/" + etag, "", "", 304, ""},
+		{`"invalid", ` + etag, "", "", 304, ""},
+		{"*", "", "", 304, ""},
+		{"", etag, "bytes=1-2", 206, "12"},
+		{"", `"invalid"`, "bytes=1-2", 200, "0123456789"},
+	}
+
+	for i, s := range scenarios {
+		r := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if s.ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", s.ifNoneMatch)
+		}
+		if s.ifRange != "" {
+			req.Header.Set("If-Range", s.ifRange)
+		}
+		if s.rangeHeader != "" {
+			req.Header.Set("Range", s.rangeHeader)
+		}
+
+		if err := fs.Serve(r, req, "etag.txt", "download.txt"); err != nil {
+			t.Errorf("(%d) Expected nil, got error %v", i, err)
+			continue
+		}
+
+		result := r.Result()
+
+		if result.StatusCode != s.expectedStatus {
+			t.Errorf("(%d) Expected status %d, got %d", i, s.expectedStatus, result.StatusCode)
+		}
+
+		if body := r.Body.String(); body != s.expectedContent {
+			t.Errorf("(%d) Expected content %q, got %q", i, s.expectedContent, body)
+		}
+
+		if v := result.Header.Get("ETag"); v != etag {
+			t.Errorf("(%d) Expected ETag %q, got %q", i, etag, v)
+		}
+	}
+}
+
 func TestFileSystemCreateThumb(t *testing.T) {
 	dir := createTestDir(t)
 	defer os.RemoveAll(dir)
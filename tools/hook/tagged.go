@@ -0,0 +1,61 @@
+package hook
+
+// TagWildcard is the special tag that matches all event tags.
+const TagWildcard = "*"
+
+// Tagger defines an interface for event data structs that
+// support tags (eg. the collection name and id of a record event).
+type Tagger interface {
+	Tags() []string
+}
+
+// TaggedHook defines a proxy hook which registers handlers that are
+// triggered only if the TaggedHook tags are empty or include at least
+// one of the event data tags.
+//
+// Trigger and Reset are executed on the proxied main hook.
+type TaggedHook[T Tagger] struct {
+	*Hook[T]
+
+	tags []string
+}
+
+// NewTaggedHook creates a new TaggedHook proxy for the provided
+// main hook and the optional list of filter tags.
+func NewTaggedHook[T Tagger](hook *Hook[T], tags ...string) *TaggedHook[T] {
+	return &TaggedHook[T]{hook, tags}
+}
+
+// CanTriggerOn checks if the current TaggedHook
+// could be triggered with the provided event data tags.
+func (h *TaggedHook[T]) CanTriggerOn(tags []string) bool {
+	if len(h.tags) == 0 {
+		return true // no filter
+	}
+
+	for _, t := range h.tags {
+		if t == TagWildcard {
+			return true
+		}
+
+		for _, tag := range tags {
+			if t == tag {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Add registers a new handler to the main hook, wrapping it
+// with a filter that skips the events with not matching tags.
+func (h *TaggedHook[T]) Add(fn Handler[T]) {
+	h.Hook.Add(func(e T) error {
+		if h.CanTriggerOn(e.Tags()) {
+			return fn(e)
+		}
+
+		return nil
+	})
+}
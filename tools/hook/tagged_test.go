@@ -0,0 +1,78 @@
+package hook
+
+import (
+	"strings"
+	"testing"
+)
+
+type mockTagsData struct {
+	tags []string
+}
+
+func (m mockTagsData) Tags() []string {
+	return m.tags
+}
+
+func TestTaggedHookCanTriggerOn(t *testing.T) {
+	main := &Hook[mockTagsData]{}
+
+	scenarios := []struct {
+		hookTags  []string
+		eventTags []string
+		expected  bool
+	}{
+		{nil, nil, true},
+		{nil, []string{"a"}, true},
+		{[]string{"a"}, nil, false},
+		{[]string{"a"}, []string{"b"}, false},
+		{[]string{"a", "b"}, []string{"b", "c"}, true},
+		{[]string{"*"}, nil, true},
+		{[]string{"a", "*"}, []string{"c"}, true},
+	}
+
+	for i, s := range scenarios {
+		h := NewTaggedHook(main, s.hookTags...)
+
+		if result := h.CanTriggerOn(s.eventTags); result != s.expected {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, result)
+		}
+	}
+}
+
+func TestTaggedHookAdd(t *testing.T) {
+	main := &Hook[mockTagsData]{}
+
+	calls := ""
+
+	NewTaggedHook(main).Add(func(e mockTagsData) error { calls += "1"; return nil })
+	NewTaggedHook(main, "a").Add(func(e mockTagsData) error { calls += "2"; return nil })
+	NewTaggedHook(main, "b", "c").Add(func(e mockTagsData) error { calls += "3"; return nil })
+	NewTaggedHook(main, "*").Add(func(e mockTagsData) error { calls += "4"; return nil })
+
+	scenarios := []struct {
+		tags     []string
+		expected string
+	}{
+		{nil, "14"},
+		{[]string{"a"}, "124"},
+		{[]string{"c"}, "134"},
+		{[]string{"a", "b"}, "1234"},
+		{[]string{"d"}, "14"},
+	}
+
+	for _, s := range scenarios {
+		calls = ""
+
+		// trigger through both the main and a tagged hook
+		if err := main.Trigger(mockTagsData{s.tags}); err != nil {
+			t.Fatal(err)
+		}
+		if err := NewTaggedHook(main, "x").Trigger(mockTagsData{s.tags}); err != nil {
+			t.Fatal(err)
+		}
+
+		if expected := strings.Repeat(s.expected, 2); calls != expected {
+			t.Errorf("[%v] Expected calls %q, got %q", s.tags, expected, calls)
+		}
+	}
+}
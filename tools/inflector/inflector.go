@@ -4,6 +4,8 @@ import (
 	"regexp"
 	"strings"
 	"unicode"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 var columnifyRemoveRegex = regexp.MustCompile(`[^\w\.\*\-\_\@\#]+`)
@@ -84,3 +86,31 @@ func Snakecase(str string) string {
 
 	return strings.ToLower(result.String())
 }
+
+// Slugify converts `str` into a lowercase URL-safe slug, eg.
+// "Hello Wörld!" will become "hello-world".
+//
+// The diacritics are stripped and all other non alphanumeric
+// ASCII characters are replaced with a single dash.
+func Slugify(str string) string {
+	var result strings.Builder
+
+	dash := false
+
+	for _, c := range norm.NFD.String(str) {
+		switch {
+		case unicode.Is(unicode.Mn, c):
+			// skip the diacritic marks
+		case c < unicode.MaxASCII && (unicode.IsLetter(c) || unicode.IsDigit(c)):
+			if dash && result.Len() > 0 {
+				result.WriteByte('-')
+			}
+			dash = false
+			result.WriteRune(unicode.ToLower(c))
+		default:
+			dash = true
+		}
+	}
+
+	return result.String()
+}
@@ -128,3 +128,27 @@ func TestSnakecase(t *testing.T) {
 		}
 	}
 }
+
+func TestSlugify(t *testing.T) {
+	scenarios := []struct {
+		val      string
+		expected string
+	}{
+		{"", ""},
+		{"  ", ""},
+		{"!@#$%^", ""},
+		{"test", "test"},
+		{"Hello World", "hello-world"},
+		{"  Hello   World!  ", "hello-world"},
+		{"Hello_World-123", "hello-world-123"},
+		{"Crème brûlée à la mode", "creme-brulee-a-la-mode"},
+		{"Ünïcödé 日本 test", "unicode-test"},
+		{"--a--b--", "a-b"},
+	}
+
+	for i, scenario := range scenarios {
+		if result := inflector.Slugify(scenario.val); result != scenario.expected {
+			t.Errorf("(%d) Expected %q, got %q", i, scenario.expected, result)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package mailer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ Mailer = (*FailoverClient)(nil)
+
+const (
+	// failoverBreakerThreshold is the number of consecutive send
+	// failures after which a provider is temporary skipped.
+	failoverBreakerThreshold = 3
+
+	// failoverBreakerCooldown is the duration for which a failing provider is skipped.
+	failoverBreakerCooldown = time.Minute
+)
+
+// ErrNoAvailableProvider is returned by [FailoverClient.Send] when all
+// of its providers are rate limited or temporary skipped due to failures.
+var ErrNoAvailableProvider = errors.New("No available mail provider.")
+
+// NewFailoverProvider creates a new [FailoverClient] provider.
+//
+// Set maxPerMinute to 0 for no rate limit.
+func NewFailoverProvider(name string, client Mailer, maxPerMinute int) *FailoverProvider {
+	return &FailoverProvider{
+		name:         name,
+		client:       client,
+		maxPerMinute: maxPerMinute,
+	}
+}
+
+// FailoverProvider defines a single [FailoverClient] mail provider
+// together with its rate limit and circuit breaker state.
+//
+// The same provider instance could be shared between
+// multiple clients in order to preserve its state.
+type FailoverProvider struct {
+	name         string
+	client       Mailer
+	maxPerMinute int
+
+	mux       sync.Mutex
+	sent      []time.Time // the send attempts in the last minute
+	failures  int         // the number of consecutive send failures
+	openUntil time.Time   // the provider is skipped until this time
+}
+
+// Name returns the provider name identifier.
+func (p *FailoverProvider) Name() string {
+	return p.name
+}
+
+// acquire reports whether the provider could be used for a new send
+// attempt (aka. it is not rate limited or skipped due to failures).
+func (p *FailoverProvider) acquire() bool {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	now := time.Now()
+
+	if now.Before(p.openUntil) {
+		return false
+	}
+
+	if p.maxPerMinute > 0 {
+		minuteAgo := now.Add(-time.Minute)
+		for len(p.sent) > 0 && !p.sent[0].After(minuteAgo) {
+			p.sent = p.sent[1:]
+		}
+
+		if len(p.sent) >= p.maxPerMinute {
+			return false
+		}
+
+		p.sent = append(p.sent, now)
+	}
+
+	return true
+}
+
+// report updates the provider circuit breaker state with the send attempt result.
+//
+// Once opened, a single failure after the cooldown reopens the breaker.
+func (p *FailoverProvider) report(err error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	if err == nil {
+		p.failures = 0
+		return
+	}
+
+	p.failures++
+	if p.failures >= failoverBreakerThreshold {
+		p.openUntil = time.Now().Add(failoverBreakerCooldown)
+	}
+}
+
+// NewFailoverClient creates a new [FailoverClient] with the provided
+// providers (ordered by priority).
+func NewFailoverClient(providers ...*FailoverProvider) *FailoverClient {
+	return &FailoverClient{providers: providers}
+}
+
+// FailoverClient defines a mail client that tries its providers in order
+// until the message is sent successfully.
+//
+// Providers that are rate limited or have failed multiple consecutive
+// times in a row (the circuit breaker is open) are skipped.
+type FailoverClient struct {
+	providers []*FailoverProvider
+
+	// OnSend is an optional callback that is invoked after each
+	// provider send attempt (attempt starts from 1).
+	OnSend func(provider string, attempt int, err error)
+}
+
+// Send implements `mailer.Mailer` interface.
+func (c *FailoverClient) Send(
+	fromEmail mail.Address,
+	toEmail mail.Address,
+	subject string,
+	htmlBody string,
+	attachments map[string]io.Reader,
+) error {
+	// the attachment readers could be consumed only once
+	// so we read them in advance for the eventual retries
+	buffered := make(map[string][]byte, len(attachments))
+	for name, r := range attachments {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		buffered[name] = data
+	}
+
+	var errs []string
+	attempt := 0
+
+	for _, p := range c.providers {
+		if !p.acquire() {
+			continue
+		}
+
+		attempt++
+
+		readers := make(map[string]io.Reader, len(buffered))
+		for name, data := range buffered {
+			readers[name] = bytes.NewReader(data)
+		}
+
+		err := p.client.Send(fromEmail, toEmail, subject, htmlBody, readers)
+
+		p.report(err)
+
+		if c.OnSend != nil {
+			c.OnSend(p.name, attempt, err)
+		}
+
+		if err == nil {
+			return nil
+		}
+
+		errs = append(errs, fmt.Sprintf("%s: %v", p.name, err))
+	}
+
+	if len(errs) == 0 {
+		return ErrNoAvailableProvider
+	}
+
+	return fmt.Errorf("All mail providers failed (%s).", strings.Join(errs, "; "))
+}
@@ -0,0 +1,144 @@
+package mailer_test
+
+import (
+	"errors"
+	"io"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/mailer"
+)
+
+type mockMailer struct {
+	err         error
+	calls       int
+	attachments []string
+}
+
+func (m *mockMailer) Send(fromEmail mail.Address, toEmail mail.Address, subject string, htmlBody string, attachments map[string]io.Reader) error {
+	m.calls++
+	for _, r := range attachments {
+		data, _ := io.ReadAll(r)
+		m.attachments = append(m.attachments, string(data))
+	}
+	return m.err
+}
+
+func send(client mailer.Mailer) error {
+	return client.Send(
+		mail.Address{Address: "from@example.com"},
+		mail.Address{Address: "to@example.com"},
+		"test",
+		"test",
+		map[string]io.Reader{"a.txt": strings.NewReader("abc")},
+	)
+}
+
+func TestFailoverClientSend(t *testing.T) {
+	failing := &mockMailer{err: errors.New("test")}
+	working := &mockMailer{}
+
+	client := mailer.NewFailoverClient(
+		mailer.NewFailoverProvider("failing", failing, 0),
+		mailer.NewFailoverProvider("working", working, 0),
+	)
+
+	attempts := []string{}
+	client.OnSend = func(provider string, attempt int, err error) {
+		attempts = append(attempts, provider)
+	}
+
+	if err := send(client); err != nil {
+		t.Fatalf("Expected nil, got %v", err)
+	}
+
+	if strings.Join(attempts, ",") != "failing,working" {
+		t.Fatalf("Unexpected attempts %v", attempts)
+	}
+
+	// the attachments should be resent on failover
+	if len(working.attachments) != 1 || working.attachments[0] != "abc" {
+		t.Fatalf("Expected the failover attachments to be [abc], got %v", working.attachments)
+	}
+}
+
+func TestFailoverClientSendAllFailed(t *testing.T) {
+	client := mailer.NewFailoverClient(
+		mailer.NewFailoverProvider("a", &mockMailer{err: errors.New("test")}, 0),
+		mailer.NewFailoverProvider("b", &mockMailer{err: errors.New("test")}, 0),
+	)
+
+	err := send(client)
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	if !strings.Contains(err.Error(), "a: test") || !strings.Contains(err.Error(), "b: test") {
+		t.Fatalf("Expected the error to contain the providers errors, got %v", err)
+	}
+}
+
+func TestFailoverClientSendRateLimit(t *testing.T) {
+	limited := &mockMailer{}
+	other := &mockMailer{}
+
+	client := mailer.NewFailoverClient(
+		mailer.NewFailoverProvider("limited", limited, 2),
+		mailer.NewFailoverProvider("other", other, 0),
+	)
+
+	for i := 0; i < 3; i++ {
+		if err := send(client); err != nil {
+			t.Fatalf("(%d) Expected nil, got %v", i, err)
+		}
+	}
+
+	if limited.calls != 2 {
+		t.Fatalf("Expected the rate limited provider to be called 2 times, got %d", limited.calls)
+	}
+
+	if other.calls != 1 {
+		t.Fatalf("Expected the other provider to be called 1 time, got %d", other.calls)
+	}
+
+	// all providers rate limited
+	onlyLimited := mailer.NewFailoverClient(mailer.NewFailoverProvider("limited", &mockMailer{}, 1))
+	send(onlyLimited)
+	if err := send(onlyLimited); !errors.Is(err, mailer.ErrNoAvailableProvider) {
+		t.Fatalf("Expected ErrNoAvailableProvider, got %v", err)
+	}
+}
+
+func TestFailoverClientSendCircuitBreaker(t *testing.T) {
+	failing := &mockMailer{err: errors.New("test")}
+	working := &mockMailer{}
+
+	provider := mailer.NewFailoverProvider("failing", failing, 0)
+
+	client := mailer.NewFailoverClient(
+		provider,
+		mailer.NewFailoverProvider("working", working, 0),
+	)
+
+	for i := 0; i < 5; i++ {
+		if err := send(client); err != nil {
+			t.Fatalf("(%d) Expected nil, got %v", i, err)
+		}
+	}
+
+	// the failing provider should be skipped after 3 consecutive failures
+	if failing.calls != 3 {
+		t.Fatalf("Expected the failing provider to be called 3 times, got %d", failing.calls)
+	}
+
+	if working.calls != 5 {
+		t.Fatalf("Expected the working provider to be called 5 times, got %d", working.calls)
+	}
+
+	// the breaker state is shared between the clients of the same provider
+	another := mailer.NewFailoverClient(provider)
+	if err := send(another); !errors.Is(err, mailer.ErrNoAvailableProvider) {
+		t.Fatalf("Expected ErrNoAvailableProvider, got %v", err)
+	}
+}
@@ -16,3 +16,36 @@ type Mailer interface {
 		attachments map[string]io.Reader,
 	) error
 }
+
+// Message defines a single composed email message.
+type Message struct {
+	From        mail.Address
+	To          mail.Address
+	Subject     string
+	HTML        string
+	Attachments map[string]io.Reader
+}
+
+var _ Mailer = (SenderFunc)(nil)
+
+// SenderFunc is an adapter that allows the use of an ordinary function
+// as [Mailer] (eg. to deliver the composed messages via a transactional
+// email provider HTTP API instead of SMTP).
+type SenderFunc func(message *Message) error
+
+// Send implements `mailer.Mailer` interface by calling f with the composed message.
+func (f SenderFunc) Send(
+	fromEmail mail.Address,
+	toEmail mail.Address,
+	subject string,
+	htmlBody string,
+	attachments map[string]io.Reader,
+) error {
+	return f(&Message{
+		From:        fromEmail,
+		To:          toEmail,
+		Subject:     subject,
+		HTML:        htmlBody,
+		Attachments: attachments,
+	})
+}
@@ -0,0 +1,57 @@
+package mailer_test
+
+import (
+	"errors"
+	"io"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/mailer"
+)
+
+func TestSenderFunc(t *testing.T) {
+	var message *mailer.Message
+
+	sender := mailer.SenderFunc(func(m *mailer.Message) error {
+		message = m
+		return errors.New("test")
+	})
+
+	attachments := map[string]io.Reader{"test.txt": strings.NewReader("test")}
+
+	err := sender.Send(
+		mail.Address{Name: "From", Address: "from@example.com"},
+		mail.Address{Address: "to@example.com"},
+		"Test subject",
+		"<p>Test</p>",
+		attachments,
+	)
+	if err == nil || err.Error() != "test" {
+		t.Fatalf("Expected the sender func error, got %v", err)
+	}
+
+	if message == nil {
+		t.Fatal("Expected the sender func to be called")
+	}
+
+	if message.From.Address != "from@example.com" || message.From.Name != "From" {
+		t.Fatalf("Unexpected from address %v", message.From)
+	}
+
+	if message.To.Address != "to@example.com" {
+		t.Fatalf("Unexpected to address %v", message.To)
+	}
+
+	if message.Subject != "Test subject" {
+		t.Fatalf("Unexpected subject %q", message.Subject)
+	}
+
+	if message.HTML != "<p>Test</p>" {
+		t.Fatalf("Unexpected html body %q", message.HTML)
+	}
+
+	if len(message.Attachments) != 1 || message.Attachments["test.txt"] == nil {
+		t.Fatalf("Unexpected attachments %v", message.Attachments)
+	}
+}
@@ -0,0 +1,94 @@
+package rest
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ProblemContentType is the RFC 7807 problem details media type.
+const ProblemContentType = "application/problem+json"
+
+// ProblemDetails defines an RFC 7807 problem details error response.
+//
+// Field validation errors are listed under the "errors" extension member.
+type ProblemDetails struct {
+	Type   string              `json:"type"`
+	Title  string              `json:"title"`
+	Status int                 `json:"status"`
+	Detail string              `json:"detail"`
+	Errors []ProblemFieldError `json:"errors,omitempty"`
+}
+
+// ProblemFieldError defines a single field validation problem entry.
+type ProblemFieldError struct {
+	// Field is the dot separated path of the invalid field (eg. "options.headers").
+	Field  string `json:"field"`
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+// ProblemDetails converts the current ApiError into RFC 7807 problem details.
+func (e *ApiError) ProblemDetails() *ProblemDetails {
+	return &ProblemDetails{
+		Type:   "about:blank",
+		Title:  http.StatusText(e.Code),
+		Status: e.Code,
+		Detail: e.Message,
+		Errors: flattenProblemFieldErrors("", e.Data),
+	}
+}
+
+// AcceptsProblemDetails reports whether the request Accept header
+// explicitly lists the RFC 7807 problem details media type.
+func AcceptsProblemDetails(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || mediaType != ProblemContentType {
+			continue
+		}
+
+		// explicitly rejected (eg. "application/problem+json;q=0")
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q <= 0 {
+			continue
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// flattenProblemFieldErrors converts the nested ApiError data
+// into a sorted list of field problem entries.
+func flattenProblemFieldErrors(prefix string, data map[string]any) []ProblemFieldError {
+	result := []ProblemFieldError{}
+
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		field := k
+		if prefix != "" {
+			field = prefix + "." + k
+		}
+
+		switch v := data[k].(type) {
+		case map[string]string:
+			result = append(result, ProblemFieldError{
+				Field:  field,
+				Code:   v["code"],
+				Detail: v["message"],
+			})
+		case map[string]any:
+			result = append(result, flattenProblemFieldErrors(field, v)...)
+		}
+	}
+
+	return result
+}
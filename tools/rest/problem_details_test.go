@@ -0,0 +1,67 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/tools/rest"
+)
+
+func TestApiErrorProblemDetails(t *testing.T) {
+	scenarios := []struct {
+		err      *rest.ApiError
+		expected string
+	}{
+		{
+			rest.NewNotFoundError("", errors.New("test")),
+			`{"type":"about:blank","title":"Not Found","status":404,"detail":"The requested resource wasn't found."}`,
+		},
+		{
+			rest.NewBadRequestError("Invalid data.", validation.Errors{
+				"b": validation.NewError("test_code", "test message"),
+				"a": validation.Errors{
+					"nested": validation.NewError("nested_code", "nested message"),
+				},
+			}),
+			`{"type":"about:blank","title":"Bad Request","status":400,"detail":"Invalid data.","errors":[{"field":"a.nested","code":"nested_code","detail":"Nested message."},{"field":"b","code":"test_code","detail":"Test message."}]}`,
+		},
+	}
+
+	for i, s := range scenarios {
+		encoded, err := json.Marshal(s.err.ProblemDetails())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(encoded) != s.expected {
+			t.Errorf("(%d) Expected \n%s, \ngot \n%s", i, s.expected, encoded)
+		}
+	}
+}
+
+func TestAcceptsProblemDetails(t *testing.T) {
+	scenarios := []struct {
+		accept   string
+		expected bool
+	}{
+		{"", false},
+		{"*/*", false},
+		{"application/json", false},
+		{"application/problem+json", true},
+		{"application/json, application/problem+json;q=0.5", true},
+		{"application/problem+json;q=0", false},
+		{"application/problem+xml", false},
+	}
+
+	for i, s := range scenarios {
+		r, _ := http.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", s.accept)
+
+		if result := rest.AcceptsProblemDetails(r); result != s.expected {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, result)
+		}
+	}
+}
@@ -27,6 +27,12 @@ func (f *UploadedFile) Name() string {
 	return f.name
 }
 
+// SetName replaces the assigned name of the uploaded file
+// (eg. to use a custom file naming scheme).
+func (f *UploadedFile) SetName(name string) {
+	f.name = name
+}
+
 // Header returns the file header that comes with the multipart request.
 func (f *UploadedFile) Header() *multipart.FileHeader {
 	return f.header
@@ -14,38 +14,175 @@ import (
 
 // FilterData is a filter expession string following the `fexpr` package grammar.
 //
+// In addition to the `fexpr` operators, the `in` and `not in` list operators
+// and calls of the allowed custom functions (see [AllowFilterFunction]) are also supported.
+//
 // Example:
-//	var filter FilterData = "id = null || (name = 'test' && status = true)"
+//	var filter FilterData = "id = null || (name = 'test' && status = true) || id in ('a', 'b')"
 //	resolver := search.NewSimpleFieldResolver("id", "name", "status")
 //	expr, err := filter.BuildExpr(resolver)
 type FilterData string
 
+// parsedFilter defines a single parsed filter data expression.
+type parsedFilter struct {
+	groups []fexpr.ExprGroup
+
+	// the extracted `in` operator lists, indexed by their placeholder identifier
+	inLists map[string][]fexpr.Token
+
+	// the extracted function calls, indexed by their placeholder identifier
+	funcCalls map[string]*filterFuncCall
+}
+
+// filterFuncCall defines a single parsed filter function call.
+type filterFuncCall struct {
+	name string
+	args []fexpr.Token
+}
+
+// allowedFilterFunctions holds the names of the custom functions
+// that are allowed to be called in the filter expressions.
+var allowedFilterFunctions = store.New(map[string]bool{})
+
+// AllowFilterFunction allows calling the db function with the provided
+// name in the filter expressions (eg. `myFunc(title, 'abc') = true`).
+//
+// The function arguments could be only fields, text or number literals.
+// Calls of not allowed functions result in a filter build error.
+func AllowFilterFunction(name string) {
+	allowedFilterFunctions.Set(strings.ToLower(name), true)
+}
+
 // parsedFilterData holds a cache with previously parsed filter data expressions
 // (initialized with some prealocated empty data map)
-var parsedFilterData = store.New(make(map[string][]fexpr.ExprGroup, 50))
+var parsedFilterData = store.New(make(map[string]*parsedFilter, 50))
 
 // BuildExpr parses the current filter data and returns a new db WHERE expression.
 func (f FilterData) BuildExpr(fieldResolver FieldResolver) (dbx.Expression, error) {
+	data, err := f.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data.funcCalls) > 0 {
+		fieldResolver = &funcCallsResolver{
+			FieldResolver: fieldResolver,
+			filter:        f,
+			calls:         data.funcCalls,
+		}
+	}
+
+	return f.build(data.groups, data.inLists, fieldResolver)
+}
+
+// parse parses the current filter data (or returns the cached parsed result).
+func (f FilterData) parse() (*parsedFilter, error) {
 	raw := string(f)
-	var data []fexpr.ExprGroup
 
 	if parsedFilterData.Has(raw) {
-		data = parsedFilterData.Get(raw)
-	} else {
-		var err error
-		data, err = fexpr.Parse(raw)
-		if err != nil {
-			return nil, err
+		return parsedFilterData.Get(raw), nil
+	}
+
+	data := &parsedFilter{
+		inLists:   map[string][]fexpr.Token{},
+		funcCalls: map[string]*filterFuncCall{},
+	}
+
+	// replace the `in` expressions and the function calls with fexpr compatible placeholders
+	normalized, err := extractPlaceholders(raw, data)
+	if err != nil {
+		return nil, err
+	}
+
+	data.groups, err = fexpr.Parse(normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	// store in cache
+	// (the limit size is arbitrary and it is there to prevent the cache growing too big)
+	parsedFilterData.SetIfLessThanLimit(raw, data, 500)
+
+	return data, nil
+}
+
+// FilterLimits defines the max complexity of a single filter expression,
+// eg. to prevent pathological client provided filters (0 for no limit).
+type FilterLimits struct {
+	// MaxConditions is the max number of comparison expressions.
+	MaxConditions int
+
+	// MaxDepth is the max parenthesized groups nesting depth.
+	MaxDepth int
+
+	// MaxLikeWildcards is the max total number of wildcard characters
+	// ("%" and "_") of the like operands, including the implicit
+	// "%" wrapping of the operands without wildcards.
+	MaxLikeWildcards int
+}
+
+// CheckLimits parses the current filter data and returns an error
+// if it exceeds any of the provided limits.
+func (f FilterData) CheckLimits(limits FilterLimits) error {
+	data, err := f.parse()
+	if err != nil {
+		return err
+	}
+
+	var conditions, wildcards int
+
+	if err := checkFilterGroupsLimits(data.groups, 0, limits, &conditions, &wildcards); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func checkFilterGroupsLimits(groups []fexpr.ExprGroup, depth int, limits FilterLimits, conditions *int, wildcards *int) error {
+	if limits.MaxDepth > 0 && depth > limits.MaxDepth {
+		return fmt.Errorf("The filter nesting is too deep (max %d).", limits.MaxDepth)
+	}
+
+	for _, group := range groups {
+		switch item := group.Item.(type) {
+		case fexpr.Expr:
+			*conditions++
+			if limits.MaxConditions > 0 && *conditions > limits.MaxConditions {
+				return fmt.Errorf("The filter has too many conditions (max %d).", limits.MaxConditions)
+			}
+
+			if item.Op != fexpr.SignLike && item.Op != fexpr.SignNlike {
+				continue
+			}
+
+			for _, operand := range []fexpr.Token{item.Left, item.Right} {
+				if operand.Type != fexpr.TokenText && operand.Type != fexpr.TokenNumber {
+					continue
+				}
+				*wildcards += strings.Count(operand.Literal, "%") + strings.Count(operand.Literal, "_")
+				if !strings.Contains(operand.Literal, "%") {
+					*wildcards += 2 // implicit %value% (see normalizeLikeParams)
+				}
+			}
+
+			if limits.MaxLikeWildcards > 0 && *wildcards > limits.MaxLikeWildcards {
+				return fmt.Errorf("The filter has too many like wildcards (max %d).", limits.MaxLikeWildcards)
+			}
+		case fexpr.ExprGroup:
+			if err := checkFilterGroupsLimits([]fexpr.ExprGroup{item}, depth, limits, conditions, wildcards); err != nil {
+				return err
+			}
+		case []fexpr.ExprGroup:
+			if err := checkFilterGroupsLimits(item, depth+1, limits, conditions, wildcards); err != nil {
+				return err
+			}
 		}
-		// store in cache
-		// (the limit size is arbitrary and it is there to prevent the cache growing too big)
-		parsedFilterData.SetIfLessThanLimit(raw, data, 500)
 	}
 
-	return f.build(data, fieldResolver)
+	return nil
 }
 
-func (f FilterData) build(data []fexpr.ExprGroup, fieldResolver FieldResolver) (dbx.Expression, error) {
+func (f FilterData) build(data []fexpr.ExprGroup, inLists map[string][]fexpr.Token, fieldResolver FieldResolver) (dbx.Expression, error) {
 	if len(data) == 0 {
 		return nil, errors.New("Empty filter expression.")
 	}
@@ -58,11 +195,15 @@ func (f FilterData) build(data []fexpr.ExprGroup, fieldResolver FieldResolver) (
 
 		switch item := group.Item.(type) {
 		case fexpr.Expr:
-			expr, exprErr = f.resolveTokenizedExpr(item, fieldResolver)
+			if list, ok := inLists[item.Right.Literal]; ok && item.Right.Type == fexpr.TokenIdentifier {
+				expr, exprErr = f.resolveInExpr(item, list, fieldResolver)
+			} else {
+				expr, exprErr = f.resolveTokenizedExpr(item, fieldResolver)
+			}
 		case fexpr.ExprGroup:
-			expr, exprErr = f.build([]fexpr.ExprGroup{item}, fieldResolver)
+			expr, exprErr = f.build([]fexpr.ExprGroup{item}, inLists, fieldResolver)
 		case []fexpr.ExprGroup:
-			expr, exprErr = f.build(item, fieldResolver)
+			expr, exprErr = f.build(item, inLists, fieldResolver)
 		default:
 			exprErr = errors.New("Unsupported expression item.")
 		}
@@ -92,6 +233,16 @@ func (f FilterData) resolveTokenizedExpr(expr fexpr.Expr, fieldResolver FieldRes
 		return nil, fmt.Errorf("Invalid right operand %q - %v.", expr.Right.Literal, rErr)
 	}
 
+	// cast the literal operand based on the compared field (if supported)
+	if literalResolver, ok := fieldResolver.(LiteralFieldResolver); ok && expr.Op != fexpr.SignLike && expr.Op != fexpr.SignNlike {
+		if err := normalizeLiteralParams(literalResolver, expr.Left, lParams, expr.Op, expr.Right, rParams); err != nil {
+			return nil, err
+		}
+		if err := normalizeLiteralParams(literalResolver, expr.Right, rParams, flipSignOp(expr.Op), expr.Left, lParams); err != nil {
+			return nil, err
+		}
+	}
+
 	// merge both operands parameters (if any)
 	params := dbx.Params{}
 	if len(lParams) > 0 {
@@ -179,6 +330,54 @@ func (f FilterData) resolveToken(token fexpr.Token, fieldResolver FieldResolver)
 	return "", nil, errors.New("Unresolvable token type.")
 }
 
+// normalizeLiteralParams replaces the literal token params with their
+// normalized value if the other operand is a resolver field.
+//
+// op is the comparison operator with the field as left operand.
+func normalizeLiteralParams(
+	resolver LiteralFieldResolver,
+	fieldToken fexpr.Token,
+	fieldParams dbx.Params,
+	op fexpr.SignOp,
+	literalToken fexpr.Token,
+	literalParams dbx.Params,
+) error {
+	if fieldToken.Type != fexpr.TokenIdentifier || len(fieldParams) > 0 {
+		return nil // not a field
+	}
+
+	if literalToken.Type != fexpr.TokenNumber && literalToken.Type != fexpr.TokenText {
+		return nil // not a literal
+	}
+
+	for k := range literalParams {
+		v, err := resolver.NormalizeLiteral(fieldToken.Literal, op, literalToken.Literal)
+		if err != nil {
+			return fmt.Errorf("Invalid %q operand %q - %v", fieldToken.Literal, literalToken.Literal, err)
+		}
+		literalParams[k] = v
+	}
+
+	return nil
+}
+
+// flipSignOp returns the equivalent comparison operator
+// with swapped operands (eg. "<" for ">").
+func flipSignOp(op fexpr.SignOp) fexpr.SignOp {
+	switch op {
+	case fexpr.SignLt:
+		return fexpr.SignGt
+	case fexpr.SignLte:
+		return fexpr.SignGte
+	case fexpr.SignGt:
+		return fexpr.SignLt
+	case fexpr.SignGte:
+		return fexpr.SignLte
+	}
+
+	return op
+}
+
 func (f FilterData) normalizeLikeParams(params dbx.Params) dbx.Params {
 	result := dbx.Params{}
 
@@ -196,3 +395,346 @@ func (f FilterData) normalizeLikeParams(params dbx.Params) dbx.Params {
 
 	return result
 }
+
+// resolveInExpr builds a db IN expression from the provided `in`/`not in`
+// placeholder expression and its extracted list values.
+func (f FilterData) resolveInExpr(expr fexpr.Expr, values []fexpr.Token, fieldResolver FieldResolver) (dbx.Expression, error) {
+	lName, lParams, lErr := f.resolveToken(expr.Left, fieldResolver)
+	if lName == "" || lErr != nil {
+		return nil, fmt.Errorf("Invalid left operand %q - %v.", expr.Left.Literal, lErr)
+	}
+
+	params := dbx.Params{}
+	for k, v := range lParams {
+		params[k] = v
+	}
+
+	// the type specific handling is applied only for the resolver fields
+	inResolver, _ := fieldResolver.(InFieldResolver)
+	isField := inResolver != nil && expr.Left.Type == fexpr.TokenIdentifier && len(lParams) == 0
+
+	placeholders := make([]string, len(values))
+	for i, token := range values {
+		var value any = token.Literal
+
+		if isField {
+			var err error
+			value, err = inResolver.NormalizeInValue(expr.Left.Literal, token.Literal)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid %q list value %q - %v", expr.Left.Literal, token.Literal, err)
+			}
+		}
+
+		placeholder := "t" + security.RandomString(7)
+		params[placeholder] = value
+		placeholders[i] = fmt.Sprintf("{:%s}", placeholder)
+	}
+
+	var not string
+	switch expr.Op {
+	case fexpr.SignEq:
+		// IN
+	case fexpr.SignNeq:
+		not = "NOT "
+	default:
+		return nil, fmt.Errorf("Unknown list expression operator %q", expr.Op)
+	}
+
+	list := strings.Join(placeholders, ", ")
+
+	// membership check for the json array columns
+	if isField && inResolver.IsMultiValueField(expr.Left.Literal) {
+		return dbx.NewExp(fmt.Sprintf(
+			"%sEXISTS (SELECT 1 FROM json_each(CASE WHEN json_valid(%s) THEN %s ELSE json_array(%s) END) WHERE json_each.value IN (%s))",
+			not, lName, lName, lName, list,
+		), params), nil
+	}
+
+	return dbx.NewExp(fmt.Sprintf("%s %sIN (%s)", lName, not, list), params), nil
+}
+
+// inPlaceholderPrefix is the identifier prefix of the placeholders
+// that replace the `in` operator lists.
+const inPlaceholderPrefix = "@__in"
+
+// funcPlaceholderPrefix is the identifier prefix of the placeholders
+// that replace the function calls.
+const funcPlaceholderPrefix = "@__fn"
+
+// extractPlaceholders replaces all `X in (...)` and `X not in (...)` expressions
+// in the raw filter string with the `fexpr` compatible `X = @__inN` and
+// `X != @__inN` placeholder expressions, and all `fn(...)` function calls
+// with `@__fnN` placeholder identifiers.
+//
+// The extracted list values and function calls are stored in the provided parsed filter.
+func extractPlaceholders(raw string, data *parsedFilter) (string, error) {
+	if !strings.Contains(raw, "(") {
+		return raw, nil // fast path
+	}
+
+	scanner := fexpr.NewScanner(strings.NewReader(raw))
+	tokens := []fexpr.Token{}
+	for {
+		t, err := scanner.Scan()
+		if err != nil {
+			return "", err
+		}
+		if t.Type == fexpr.TokenEOF {
+			break
+		}
+		tokens = append(tokens, t)
+	}
+
+	// returns the index of the next non whitespace token (or -1)
+	nextIndex := func(i int) int {
+		for j := i + 1; j < len(tokens); j++ {
+			if tokens[j].Type != fexpr.TokenWS {
+				return j
+			}
+		}
+		return -1
+	}
+
+	var result strings.Builder
+
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+
+		switch t.Type {
+		case fexpr.TokenIdentifier:
+			op := ""
+			groupIndex := -1
+
+			if strings.EqualFold(t.Literal, "in") {
+				op = "="
+				groupIndex = nextIndex(i)
+			} else if strings.EqualFold(t.Literal, "not") {
+				if inIndex := nextIndex(i); inIndex != -1 && strings.EqualFold(tokens[inIndex].Literal, "in") && tokens[inIndex].Type == fexpr.TokenIdentifier {
+					op = "!="
+					groupIndex = nextIndex(inIndex)
+				}
+			}
+
+			// function call (the arguments group must follow the name without whitespaces)
+			if op == "" && i+1 < len(tokens) && tokens[i+1].Type == fexpr.TokenGroup {
+				call, err := parseFuncCall(t.Literal, tokens[i+1].Literal)
+				if err != nil {
+					return "", err
+				}
+
+				placeholder := fmt.Sprintf("%s%d", funcPlaceholderPrefix, len(data.funcCalls))
+				data.funcCalls[placeholder] = call
+
+				result.WriteString(placeholder)
+
+				i++
+				continue
+			}
+
+			if groupIndex == -1 || tokens[groupIndex].Type != fexpr.TokenGroup {
+				result.WriteString(t.Literal)
+				continue
+			}
+
+			values, err := parseInList(tokens[groupIndex].Literal)
+			if err != nil {
+				return "", err
+			}
+
+			placeholder := fmt.Sprintf("%s%d", inPlaceholderPrefix, len(data.inLists))
+			data.inLists[placeholder] = values
+
+			result.WriteString(op + " " + placeholder)
+
+			i = groupIndex
+		case fexpr.TokenText:
+			result.WriteString(`"` + strings.ReplaceAll(t.Literal, `"`, `\"`) + `"`)
+		case fexpr.TokenGroup:
+			inner, err := extractPlaceholders(t.Literal, data)
+			if err != nil {
+				return "", err
+			}
+			result.WriteString("(" + inner + ")")
+		default:
+			result.WriteString(t.Literal)
+		}
+	}
+
+	return result.String(), nil
+}
+
+// parseInList parses a comma separated list of quoted text and number
+// literals (eg. `'a', "b", 123`).
+func parseInList(raw string) ([]fexpr.Token, error) {
+	result := []fexpr.Token{}
+	runes := []rune(raw)
+	expectItem := true
+
+	for i := 0; i < len(runes); i++ {
+		ch := runes[i]
+
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n':
+			continue
+		case ch == ',' && !expectItem:
+			expectItem = true
+		case (ch == '\'' || ch == '"') && expectItem:
+			var buf strings.Builder
+			closed := false
+			for i = i + 1; i < len(runes); i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && runes[i+1] == ch {
+					buf.WriteRune(ch)
+					i++
+					continue
+				}
+				if runes[i] == ch {
+					closed = true
+					break
+				}
+				buf.WriteRune(runes[i])
+			}
+			if !closed {
+				return nil, fmt.Errorf("Invalid quoted list value in %q.", raw)
+			}
+			result = append(result, fexpr.Token{Type: fexpr.TokenText, Literal: buf.String()})
+			expectItem = false
+		case (ch == '-' || (ch >= '0' && ch <= '9')) && expectItem:
+			start := i
+			for i+1 < len(runes) && (runes[i+1] == '.' || (runes[i+1] >= '0' && runes[i+1] <= '9')) {
+				i++
+			}
+			literal := string(runes[start : i+1])
+			if _, err := cast.ToFloat64E(literal); err != nil {
+				return nil, fmt.Errorf("Invalid number list value %q.", literal)
+			}
+			result = append(result, fexpr.Token{Type: fexpr.TokenNumber, Literal: literal})
+			expectItem = false
+		default:
+			return nil, fmt.Errorf("Unexpected character %q in list %q.", ch, raw)
+		}
+	}
+
+	if len(result) == 0 || expectItem {
+		return nil, fmt.Errorf("Invalid or empty list %q.", raw)
+	}
+
+	return result, nil
+}
+
+// parseFuncCall parses a single filter function call with the provided
+// name and raw comma separated arguments (eg. `title, 'abc', 123`).
+func parseFuncCall(name string, rawArgs string) (*filterFuncCall, error) {
+	if !allowedFilterFunctions.Has(strings.ToLower(name)) {
+		return nil, fmt.Errorf("Unknown or not allowed filter function %q.", name)
+	}
+
+	call := &filterFuncCall{name: name, args: []fexpr.Token{}}
+
+	if strings.TrimSpace(rawArgs) == "" {
+		return call, nil // no arguments
+	}
+
+	// split the arguments by the not quoted commas
+	parts := []string{}
+	var quote rune
+	var start int
+	runes := []rune(rawArgs)
+	for i, ch := range runes {
+		switch {
+		case quote != 0:
+			// closing not escaped quote
+			if ch == quote && (i == 0 || runes[i-1] != '\\') {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == ',':
+			parts = append(parts, string(runes[start:i]))
+			start = i + 1
+		}
+	}
+	parts = append(parts, string(runes[start:]))
+
+	for _, part := range parts {
+		scanner := fexpr.NewScanner(strings.NewReader(strings.TrimSpace(part)))
+
+		arg, err := scanner.Scan()
+		if err != nil {
+			return nil, err
+		}
+
+		if arg.Type != fexpr.TokenIdentifier && arg.Type != fexpr.TokenText && arg.Type != fexpr.TokenNumber {
+			return nil, fmt.Errorf("Invalid %q function argument %q.", name, part)
+		}
+
+		if next, _ := scanner.Scan(); next.Type != fexpr.TokenEOF {
+			return nil, fmt.Errorf("Invalid %q function argument %q.", name, part)
+		}
+
+		call.args = append(call.args, arg)
+	}
+
+	return call, nil
+}
+
+// funcCallsResolver is a FieldResolver wrapper that resolves
+// the function call placeholders of a single parsed filter.
+type funcCallsResolver struct {
+	FieldResolver
+
+	filter FilterData
+	calls  map[string]*filterFuncCall
+}
+
+// Resolve implements the [FieldResolver] interface.
+func (r *funcCallsResolver) Resolve(field string) (string, dbx.Params, error) {
+	call, ok := r.calls[field]
+	if !ok {
+		return r.FieldResolver.Resolve(field)
+	}
+
+	params := dbx.Params{}
+	args := make([]string, len(call.args))
+
+	for i, arg := range call.args {
+		name, argParams, err := r.filter.resolveToken(arg, r.FieldResolver)
+		if name == "" || err != nil {
+			return "", nil, fmt.Errorf("Invalid %q function argument %q - %v.", call.name, arg.Literal, err)
+		}
+
+		for k, v := range argParams {
+			params[k] = v
+		}
+
+		args[i] = name
+	}
+
+	return fmt.Sprintf("%s(%s)", call.name, strings.Join(args, ", ")), params, nil
+}
+
+// IsMultiValueField implements the [InFieldResolver] interface.
+func (r *funcCallsResolver) IsMultiValueField(field string) bool {
+	if inResolver, ok := r.FieldResolver.(InFieldResolver); ok && r.calls[field] == nil {
+		return inResolver.IsMultiValueField(field)
+	}
+
+	return false
+}
+
+// NormalizeLiteral implements the [LiteralFieldResolver] interface.
+func (r *funcCallsResolver) NormalizeLiteral(field string, op fexpr.SignOp, value string) (any, error) {
+	if literalResolver, ok := r.FieldResolver.(LiteralFieldResolver); ok && r.calls[field] == nil {
+		return literalResolver.NormalizeLiteral(field, op, value)
+	}
+
+	return value, nil
+}
+
+// NormalizeInValue implements the [InFieldResolver] interface.
+func (r *funcCallsResolver) NormalizeInValue(field string, value string) (any, error) {
+	if inResolver, ok := r.FieldResolver.(InFieldResolver); ok && r.calls[field] == nil {
+		return inResolver.NormalizeInValue(field, value)
+	}
+
+	return value, nil
+}
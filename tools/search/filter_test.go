@@ -1,11 +1,14 @@
 package search_test
 
 import (
+	"math"
 	"regexp"
 	"testing"
 
+	"github.com/ganigeorgiev/fexpr"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/spf13/cast"
 )
 
 func TestFilterDataBuildExpr(t *testing.T) {
@@ -78,6 +81,261 @@ func TestFilterDataBuildExpr(t *testing.T) {
 				regexp.QuoteMeta("})") +
 				"$",
 		},
+		// in operator
+		{
+			"test1 in ('a', \"b\", 1.5) && test2 not in (1) || (test3 IN ('c') && test4.sub > 1)",
+			false,
+			"^" +
+				regexp.QuoteMeta("(([[test1]] IN ({:") +
+				".+" +
+				regexp.QuoteMeta("}, {:") +
+				".+" +
+				regexp.QuoteMeta("}, {:") +
+				".+" +
+				regexp.QuoteMeta("})) AND ([[test2]] NOT IN ({:") +
+				".+" +
+				regexp.QuoteMeta("}))) OR (([[test3]] IN ({:") +
+				".+" +
+				regexp.QuoteMeta("})) AND ([[test4.sub]] > {:") +
+				".+" +
+				regexp.QuoteMeta("}))") +
+				"$",
+		},
+		// in operator with invalid list
+		{"test1 in ()", true, ""},
+		{"test1 in ('a',)", true, ""},
+		{"test1 in (test2)", true, ""},
+		{"test1 in ('a' 'b')", true, ""},
+		// in operator with unknown field
+		{"unknown in ('a')", true, ""},
+	}
+
+	for i, s := range scenarios {
+		expr, err := s.filterData.BuildExpr(resolver)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+			continue
+		}
+
+		if hasErr {
+			continue
+		}
+
+		dummyDB := &dbx.DB{}
+		rawSql := expr.Build(dummyDB, map[string]any{})
+
+		pattern := regexp.MustCompile(s.expectPattern)
+		if !pattern.MatchString(rawSql) {
+			t.Errorf("(%d) Pattern %v don't match with expression: \n%v", i, s.expectPattern, rawSql)
+		}
+	}
+}
+
+// testInFieldResolver is a SimpleFieldResolver that treats
+// the "multi" field as json array and "num" as number.
+type testInFieldResolver struct {
+	*search.SimpleFieldResolver
+}
+
+func (r *testInFieldResolver) IsMultiValueField(field string) bool {
+	return field == "multi"
+}
+
+func (r *testInFieldResolver) NormalizeInValue(field string, value string) (any, error) {
+	if field == "num" {
+		return cast.ToFloat64E(value)
+	}
+	return value, nil
+}
+
+func TestFilterDataBuildExprWithInFieldResolver(t *testing.T) {
+	resolver := &testInFieldResolver{search.NewSimpleFieldResolver("multi", "num", "text")}
+
+	scenarios := []struct {
+		filterData    search.FilterData
+		expectError   bool
+		expectPattern string
+	}{
+		// invalid value type
+		{"num in (1, 'abc')", true, ""},
+		// non multi-value field
+		{"num in (1, '2') && text not in ('a')", false,
+			"^" +
+				regexp.QuoteMeta("([[num]] IN ({:") +
+				".+" +
+				regexp.QuoteMeta("}, {:") +
+				".+" +
+				regexp.QuoteMeta("})) AND ([[text]] NOT IN ({:") +
+				".+" +
+				regexp.QuoteMeta("}))") +
+				"$",
+		},
+		// multi-value field
+		{"multi in ('a', 'b') || multi not in ('c')", false,
+			"^" +
+				regexp.QuoteMeta("(EXISTS (SELECT 1 FROM json_each(CASE WHEN json_valid([[multi]]) THEN [[multi]] ELSE json_array([[multi]]) END) WHERE json_each.value IN ({:") +
+				".+" +
+				regexp.QuoteMeta("}, {:") +
+				".+" +
+				regexp.QuoteMeta("}))) OR (NOT EXISTS (SELECT 1 FROM json_each(CASE WHEN json_valid([[multi]]) THEN [[multi]] ELSE json_array([[multi]]) END) WHERE json_each.value IN ({:") +
+				".+" +
+				regexp.QuoteMeta("})))") +
+				"$",
+		},
+	}
+
+	for i, s := range scenarios {
+		expr, err := s.filterData.BuildExpr(resolver)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+			continue
+		}
+
+		if hasErr {
+			continue
+		}
+
+		dummyDB := &dbx.DB{}
+		rawSql := expr.Build(dummyDB, map[string]any{})
+
+		pattern := regexp.MustCompile(s.expectPattern)
+		if !pattern.MatchString(rawSql) {
+			t.Errorf("(%d) Pattern %v don't match with expression: \n%v", i, s.expectPattern, rawSql)
+		}
+	}
+}
+
+// testLiteralFieldResolver is a SimpleFieldResolver that casts
+// the literals compared with the "price" field to cents
+// (rounding them down for the "<=" and ">" operators).
+type testLiteralFieldResolver struct {
+	*search.SimpleFieldResolver
+}
+
+func (r *testLiteralFieldResolver) NormalizeLiteral(field string, op fexpr.SignOp, value string) (any, error) {
+	if field == "price" {
+		v, err := cast.ToFloat64E(value)
+		if err != nil {
+			return nil, err
+		}
+		if op == fexpr.SignLte || op == fexpr.SignGt {
+			return int64(math.Floor(v * 100)), nil
+		}
+		return int64(math.Round(v * 100)), nil
+	}
+	return value, nil
+}
+
+func TestFilterDataBuildExprWithLiteralFieldResolver(t *testing.T) {
+	resolver := &testLiteralFieldResolver{search.NewSimpleFieldResolver("price", "text")}
+
+	scenarios := []struct {
+		filterData     search.FilterData
+		expectError    bool
+		expectedParams []any
+	}{
+		{"price <= 'abc'", true, nil},
+		{"price <= 9.99", false, []any{int64(999)}},
+		{"9.99 > price", false, []any{int64(999)}},
+		// the operator is flipped for the right field operand
+		{"price <= 9.996", false, []any{int64(999)}},
+		{"9.996 >= price", false, []any{int64(999)}},
+		{"9.996 <= price", false, []any{int64(1000)}},
+		{"price = '1.5' && text = '1.5'", false, []any{int64(150), "1.5"}},
+		// like operators are not normalized
+		{"price ~ '9'", false, []any{"%9%"}},
+	}
+
+	for i, s := range scenarios {
+		expr, err := s.filterData.BuildExpr(resolver)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+			continue
+		}
+
+		if hasErr {
+			continue
+		}
+
+		params := dbx.Params{}
+		expr.Build(&dbx.DB{}, params)
+
+		if len(params) != len(s.expectedParams) {
+			t.Errorf("(%d) Expected %d params, got %v", i, len(s.expectedParams), params)
+			continue
+		}
+
+		for _, expected := range s.expectedParams {
+			found := false
+			for _, v := range params {
+				if v == expected {
+					found = true
+					break
+				}
+			}
+			if !found {
+				t.Errorf("(%d) Missing expected param %v (%T) in %v", i, expected, expected, params)
+			}
+		}
+	}
+}
+
+func TestFilterDataBuildExprWithFunctions(t *testing.T) {
+	search.AllowFilterFunction("test_fn")
+
+	resolver := &testInFieldResolver{search.NewSimpleFieldResolver("multi", "num", "text")}
+
+	scenarios := []struct {
+		filterData    search.FilterData
+		expectError   bool
+		expectPattern string
+	}{
+		// not allowed function
+		{"unknown_fn(text) = 1", true, ""},
+		// unknown field argument
+		{"test_fn(unknown) = 1", true, ""},
+		// invalid argument
+		{"test_fn(text = 1) = 1", true, ""},
+		{"test_fn(text,) = 1", true, ""},
+		{"test_fn(other_fn(text)) = 1", true, ""},
+		// without arguments
+		{"test_fn() > 1", false,
+			"^" +
+				regexp.QuoteMeta("test_fn() > {:") +
+				".+" +
+				regexp.QuoteMeta("}") +
+				"$",
+		},
+		// mixed arguments (with quoted comma)
+		{"TEST_FN(text, 'a,b', 123) = true", false,
+			"^" +
+				regexp.QuoteMeta("TEST_FN([[text]], {:") +
+				".+" +
+				regexp.QuoteMeta("}, {:") +
+				".+" +
+				regexp.QuoteMeta("}) = 1") +
+				"$",
+		},
+		// nested groups and in operator
+		{"(text = 'a' && test_fn(num) in (1, 2)) || multi in ('b')", false,
+			"^" +
+				regexp.QuoteMeta("(([[text]] = {:") +
+				".+" +
+				regexp.QuoteMeta("}) AND (test_fn([[num]]) IN ({:") +
+				".+" +
+				regexp.QuoteMeta("}, {:") +
+				".+" +
+				regexp.QuoteMeta("}))) OR (EXISTS (SELECT 1 FROM json_each(CASE WHEN json_valid([[multi]]) THEN [[multi]] ELSE json_array([[multi]]) END) WHERE json_each.value IN ({:") +
+				".+" +
+				regexp.QuoteMeta("})))") +
+				"$",
+		},
 	}
 
 	for i, s := range scenarios {
@@ -102,3 +360,37 @@ func TestFilterDataBuildExpr(t *testing.T) {
 		}
 	}
 }
+
+func TestFilterDataCheckLimits(t *testing.T) {
+	scenarios := []struct {
+		filterData  search.FilterData
+		limits      search.FilterLimits
+		expectError bool
+	}{
+		// invalid filter
+		{"a >", search.FilterLimits{}, true},
+		// no limits
+		{"a = 1 && (b = 2 || (c ~ '%_%' && d ~ 'x'))", search.FilterLimits{}, false},
+		// conditions
+		{"a = 1 && b = 2 && c in (1,2,3)", search.FilterLimits{MaxConditions: 3}, false},
+		{"a = 1 && (b = 2 || c = 3) && d = 4", search.FilterLimits{MaxConditions: 3}, true},
+		// depth
+		{"(a = 1 && (b = 2))", search.FilterLimits{MaxDepth: 2}, false},
+		{"(a = 1 && (b = 2 || (c = 3)))", search.FilterLimits{MaxDepth: 2}, true},
+		// like wildcards (incl. the implicit %value% wrapping)
+		{"a ~ 'x' && b !~ 'y'", search.FilterLimits{MaxLikeWildcards: 4}, false},
+		{"a ~ 'x' && b !~ 'y' && c ~ 'z'", search.FilterLimits{MaxLikeWildcards: 4}, true},
+		{"a ~ '%x_y%'", search.FilterLimits{MaxLikeWildcards: 3}, false},
+		{"a ~ '%x_y%'", search.FilterLimits{MaxLikeWildcards: 2}, true},
+		{"a ~ '%x%' && b = '%%%%'", search.FilterLimits{MaxLikeWildcards: 2}, false},
+	}
+
+	for i, s := range scenarios {
+		err := s.filterData.CheckLimits(s.limits)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"math"
 	"net/url"
+	"reflect"
 	"strconv"
 
 	"github.com/pocketbase/dbx"
@@ -29,6 +30,10 @@ type Result struct {
 	PerPage    int `json:"perPage"`
 	TotalItems int `json:"totalItems"`
 	Items      any `json:"items"`
+
+	// TotalEstimated indicates that TotalItems is an approximate value
+	// (see [Provider.EstimatedCount]).
+	TotalEstimated bool `json:"totalEstimated,omitempty"`
 }
 
 // Provider represents a single configured search provider instance.
@@ -39,6 +44,12 @@ type Provider struct {
 	perPage       int
 	sort          []SortField
 	filter        []FilterData
+	filterLimits  FilterLimits
+
+	estimateFunc      func() (int64, error)
+	estimateThreshold int64
+
+	retryFunc func(fn func() error) error
 }
 
 // NewProvider creates and returns a new search provider.
@@ -67,6 +78,26 @@ func (s *Provider) Query(query *dbx.SelectQuery) *Provider {
 	return s
 }
 
+// EstimatedCount sets a func that returns an estimated total number
+// of the search items, which is used instead of the exact count query
+// when the estimate is at least the provided threshold.
+//
+// Note that the estimate func is expected to ignore the provider
+// filters, so it should be set only for unfiltered searches.
+// On estimate error the exact count is used.
+func (s *Provider) EstimatedCount(threshold int64, estimateFunc func() (int64, error)) *Provider {
+	s.estimateThreshold = threshold
+	s.estimateFunc = estimateFunc
+	return s
+}
+
+// Retry sets a func that executes the provider db queries and
+// retries them on transient error (eg. [daos.Dao.WithRetry]).
+func (s *Provider) Retry(retryFunc func(fn func() error) error) *Provider {
+	s.retryFunc = retryFunc
+	return s
+}
+
 // Page sets the `page` field of the current search provider.
 //
 // Normalization on the `page` value is done during `Exec()`.
@@ -109,6 +140,13 @@ func (s *Provider) AddFilter(filter FilterData) *Provider {
 	return s
 }
 
+// FilterLimits sets the complexity limits of the parsed url query filter
+// (the filters added with [Provider.Filter] and [Provider.AddFilter] are not checked).
+func (s *Provider) FilterLimits(limits FilterLimits) *Provider {
+	s.filterLimits = limits
+	return s
+}
+
 // Parse parses the search query parameter from the provided query string
 // and assigns the found fields to the current search provider.
 //
@@ -148,6 +186,11 @@ func (s *Provider) Parse(urlQuery string) error {
 
 	rawFilter := params.Get(FilterQueryParam)
 	if rawFilter != "" {
+		if s.filterLimits != (FilterLimits{}) {
+			if err := FilterData(rawFilter).CheckLimits(s.filterLimits); err != nil {
+				return err
+			}
+		}
 		s.AddFilter(FilterData(rawFilter))
 	}
 
@@ -198,9 +241,21 @@ func (s *Provider) Exec(items any) (*Result, error) {
 
 	// count
 	var totalCount int64
-	countQuery := modelsQuery
-	if err := countQuery.Select("count(*)").Row(&totalCount); err != nil {
-		return nil, err
+	var estimated bool
+	if s.estimateFunc != nil {
+		if estimate, err := s.estimateFunc(); err == nil && estimate >= s.estimateThreshold {
+			totalCount = estimate
+			estimated = true
+		}
+	}
+	if !estimated {
+		countQuery := modelsQuery
+		err := s.retry(func() error {
+			return countQuery.Select("count(*)").Row(&totalCount)
+		})
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// normalize perPage
@@ -211,9 +266,10 @@ func (s *Provider) Exec(items any) (*Result, error) {
 	}
 
 	// normalize page according to the total count
-	if s.page <= 0 || totalCount == 0 {
+	// (the estimated count could be lower than the actual one so the page is not capped)
+	if s.page <= 0 || (totalCount == 0 && !estimated) {
 		s.page = 1
-	} else if totalPages := int(math.Ceil(float64(totalCount) / float64(s.perPage))); s.page > totalPages {
+	} else if totalPages := int(math.Ceil(float64(totalCount) / float64(s.perPage))); s.page > totalPages && !estimated {
 		s.page = totalPages
 	}
 
@@ -222,18 +278,40 @@ func (s *Provider) Exec(items any) (*Result, error) {
 	modelsQuery.Offset(int64(s.perPage * (s.page - 1)))
 
 	// fetch models
-	if err := modelsQuery.All(items); err != nil {
+	// (truncating the items on retry since All appends to the slice)
+	itemsValue := reflect.Indirect(reflect.ValueOf(items))
+	itemsLen := -1
+	if itemsValue.Kind() == reflect.Slice && itemsValue.CanSet() {
+		itemsLen = itemsValue.Len()
+	}
+	err := s.retry(func() error {
+		if itemsLen >= 0 {
+			itemsValue.SetLen(itemsLen)
+		}
+		return modelsQuery.All(items)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	return &Result{
-		Page:       s.page,
-		PerPage:    s.perPage,
-		TotalItems: int(totalCount),
-		Items:      items,
+		Page:           s.page,
+		PerPage:        s.perPage,
+		TotalItems:     int(totalCount),
+		Items:          items,
+		TotalEstimated: estimated,
 	}, nil
 }
 
+// retry executes fn with the provider retry func (if set).
+func (s *Provider) retry(fn func() error) error {
+	if s.retryFunc == nil {
+		return fn()
+	}
+
+	return s.retryFunc(fn)
+}
+
 // ParseAndExec is a short conventient method to trigger both
 // `Parse()` and `Exec()` in a single call.
 func (s *Provider) ParseAndExec(urlQuery string, modelsSlice any) (*Result, error) {
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -449,6 +450,117 @@ func TestProviderParseAndExec(t *testing.T) {
 	}
 }
 
+func TestProviderEstimatedCount(t *testing.T) {
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	query := testDB.Select("*").From("test")
+
+	scenarios := []struct {
+		threshold        int64
+		estimate         int64
+		estimateErr      error
+		expectTotal      int
+		expectEstimated  bool
+		expectCountQuery bool
+	}{
+		{10, 100, nil, 100, true, false},
+		{10, 10, nil, 10, true, false},
+		{10, 5, nil, 2, false, true},
+		{10, 100, errors.New("test"), 2, false, true},
+	}
+
+	for i, s := range scenarios {
+		testDB.CalledQueries = []string{} // reset
+
+		result, err := NewProvider(&testFieldResolver{}).
+			Query(query).
+			Page(3).
+			PerPage(1).
+			EstimatedCount(s.threshold, func() (int64, error) {
+				return s.estimate, s.estimateErr
+			}).
+			Exec(&[]testTableStruct{})
+		if err != nil {
+			t.Errorf("(%d) Unexpected error %v", i, err)
+			continue
+		}
+
+		if result.TotalItems != s.expectTotal {
+			t.Errorf("(%d) Expected total %d, got %d", i, s.expectTotal, result.TotalItems)
+		}
+
+		if result.TotalEstimated != s.expectEstimated {
+			t.Errorf("(%d) Expected TotalEstimated %v, got %v", i, s.expectEstimated, result.TotalEstimated)
+		}
+
+		// the page is capped only by the exact count
+		expectPage := 3
+		if !s.expectEstimated {
+			expectPage = 2
+		}
+		if result.Page != expectPage {
+			t.Errorf("(%d) Expected page %d, got %d", i, expectPage, result.Page)
+		}
+
+		hasCountQuery := false
+		for _, q := range testDB.CalledQueries {
+			if strings.Contains(q, "count(*)") {
+				hasCountQuery = true
+			}
+		}
+		if hasCountQuery != s.expectCountQuery {
+			t.Errorf("(%d) Expected count query %v, got %v", i, s.expectCountQuery, testDB.CalledQueries)
+		}
+	}
+}
+
+func TestProviderRetry(t *testing.T) {
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	calls := 0
+
+	// executes every query twice to simulate a retry
+	retryFunc := func(fn func() error) error {
+		calls++
+		if err := fn(); err != nil {
+			return err
+		}
+		return fn()
+	}
+
+	items := []testTableStruct{}
+
+	result, err := NewProvider(&testFieldResolver{}).
+		Query(testDB.Select("*").From("test")).
+		Retry(retryFunc).
+		Exec(&items)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// count and items queries
+	if calls != 2 {
+		t.Fatalf("Expected the retry func to be called 2 times, got %d", calls)
+	}
+
+	if result.TotalItems != 2 {
+		t.Fatalf("Expected total 2, got %d", result.TotalItems)
+	}
+
+	// the items of the previous attempt shouldn't be duplicated
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 items, got %v", items)
+	}
+}
+
 // -------------------------------------------------------------------
 // Helpers
 // -------------------------------------------------------------------
@@ -3,6 +3,7 @@ package search
 import (
 	"fmt"
 
+	"github.com/ganigeorgiev/fexpr"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/tools/inflector"
 	"github.com/pocketbase/pocketbase/tools/list"
@@ -21,6 +22,39 @@ type FieldResolver interface {
 	Resolve(field string) (name string, placeholderParams dbx.Params, err error)
 }
 
+// InFieldResolver is an optional interface that could be implemented
+// by a FieldResolver to customize the `in` filter operator handling.
+type InFieldResolver interface {
+	// IsMultiValueField checks whether the specified field stores
+	// multiple values (aka. json array) and should be checked for membership.
+	IsMultiValueField(field string) bool
+
+	// NormalizeInValue validates and casts a single `in` list value
+	// according to the specified field type.
+	NormalizeInValue(field string, value string) (any, error)
+}
+
+// LiteralFieldResolver is an optional interface that could be implemented
+// by a FieldResolver to cast the literal operands compared with a field
+// (eg. to convert a decimal literal into the field stored representation).
+type LiteralFieldResolver interface {
+	// NormalizeLiteral validates and casts a number or text literal
+	// that is compared with the specified field.
+	//
+	// op is the comparison operator as if the field is the left
+	// operand (eg. "5 < price" is normalized as "price > 5").
+	NormalizeLiteral(field string, op fexpr.SignOp, value string) (any, error)
+}
+
+// SortFieldResolver is an optional interface that could be implemented
+// by a FieldResolver to restrict the allowed sort fields
+// (eg. to disallow sorting by ambiguous multi-value relations).
+type SortFieldResolver interface {
+	// CheckSortField returns an error if the specified field
+	// cannot be used as sort field.
+	CheckSortField(field string) error
+}
+
 // NewSimpleFieldResolver creates a new `SimpleFieldResolver` with the
 // provided `allowedFields`.
 //
@@ -19,6 +19,12 @@ type SortField struct {
 
 // BuildExpr resolves the sort field into a valid db sort expression.
 func (s *SortField) BuildExpr(fieldResolver FieldResolver) (string, error) {
+	if r, ok := fieldResolver.(SortFieldResolver); ok {
+		if err := r.CheckSortField(s.Name); err != nil {
+			return "", fmt.Errorf("Invalid sort field %q: %w", s.Name, err)
+		}
+	}
+
 	name, params, err := fieldResolver.Resolve(s.Name)
 
 	// invalidate empty fields and non-column identifiers
@@ -2,6 +2,7 @@ package search_test
 
 import (
 	"encoding/json"
+	"errors"
 	"testing"
 
 	"github.com/pocketbase/pocketbase/tools/search"
@@ -44,6 +45,47 @@ func TestSortFieldBuildExpr(t *testing.T) {
 	}
 }
 
+// testSortFieldResolver is a SimpleFieldResolver
+// that doesn't allow sorting by the "test2" field.
+type testSortFieldResolver struct {
+	*search.SimpleFieldResolver
+}
+
+func (r *testSortFieldResolver) CheckSortField(field string) error {
+	if field == "test2" {
+		return errors.New("test2 sort is not allowed")
+	}
+	return nil
+}
+
+func TestSortFieldBuildExprWithSortFieldResolver(t *testing.T) {
+	resolver := &testSortFieldResolver{search.NewSimpleFieldResolver("test1", "test2")}
+
+	scenarios := []struct {
+		sortField        search.SortField
+		expectError      bool
+		expectExpression string
+	}{
+		{search.SortField{"test1", search.SortAsc}, false, "[[test1]] ASC"},
+		{search.SortField{"test2", search.SortAsc}, true, ""},
+		{search.SortField{"unknown", search.SortAsc}, true, ""},
+	}
+
+	for i, s := range scenarios {
+		result, err := s.sortField.BuildExpr(resolver)
+
+		hasErr := err != nil
+		if hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+			continue
+		}
+
+		if result != s.expectExpression {
+			t.Errorf("(%d) Expected expression %v, got %v", i, s.expectExpression, result)
+		}
+	}
+}
+
 func TestParseSortFromString(t *testing.T) {
 	scenarios := []struct {
 		value        string
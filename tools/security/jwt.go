@@ -22,6 +22,22 @@ func ParseUnverifiedJWT(token string) (jwt.MapClaims, error) {
 	return claims, err
 }
 
+// ParseUnverifiedJWTKeyId returns the "kid" header value of the
+// provided JWT token but DOES NOT verify the signature.
+//
+// Returns an empty string if the token doesn't have a "kid" header.
+func ParseUnverifiedJWTKeyId(token string) string {
+	parser := &jwt.Parser{}
+	parsedToken, _, err := parser.ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		return ""
+	}
+
+	kid, _ := parsedToken.Header["kid"].(string)
+
+	return kid
+}
+
 // ParseJWT verifies and parses JWT token and returns its claims.
 func ParseJWT(token string, verificationKey string) (jwt.MapClaims, error) {
 	parser := &jwt.Parser{
@@ -44,6 +60,14 @@ func ParseJWT(token string, verificationKey string) (jwt.MapClaims, error) {
 
 // NewToken generates and returns new HS256 signed JWT token.
 func NewToken(payload jwt.MapClaims, signingKey string, secondsDuration int64) (string, error) {
+	return NewTokenWithKeyId(payload, "", signingKey, secondsDuration)
+}
+
+// NewTokenWithKeyId generates and returns new HS256 signed JWT token
+// with a "kid" header identifying the used signing key.
+//
+// The "kid" header is omitted if keyId is empty.
+func NewTokenWithKeyId(payload jwt.MapClaims, keyId string, signingKey string, secondsDuration int64) (string, error) {
 	seconds := time.Duration(secondsDuration) * time.Second
 
 	claims := jwt.MapClaims{
@@ -56,5 +80,10 @@ func NewToken(payload jwt.MapClaims, signingKey string, secondsDuration int64) (
 		}
 	}
 
-	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(signingKey))
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	if keyId != "" {
+		token.Header["kid"] = keyId
+	}
+
+	return token.SignedString([]byte(signingKey))
 }
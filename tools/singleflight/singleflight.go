@@ -0,0 +1,59 @@
+// Package singleflight implements a duplicate function calls suppression
+// mechanism (similar to golang.org/x/sync/singleflight but generic).
+package singleflight
+
+import "sync"
+
+type call[T any] struct {
+	wg  sync.WaitGroup
+	val T
+	err error
+}
+
+// Group defines a namespace of calls with duplicate suppression.
+//
+// The zero value is ready to use.
+type Group[T any] struct {
+	mux   sync.Mutex
+	calls map[string]*call[T]
+}
+
+// Do executes and returns the result of the provided function,
+// making sure that only one execution is in-flight for a given key at a time.
+//
+// If a duplicate call comes in while the first one is still executing,
+// the duplicate caller waits for the original to complete and receives
+// the same result (shared reports whether the result was received
+// from another caller in-flight execution).
+func (g *Group[T]) Do(key string, fn func() (T, error)) (val T, err error, shared bool) {
+	g.mux.Lock()
+
+	if g.calls == nil {
+		g.calls = map[string]*call[T]{}
+	}
+
+	if c, ok := g.calls[key]; ok {
+		g.mux.Unlock()
+		c.wg.Wait()
+		return c.val, c.err, true
+	}
+
+	c := &call[T]{}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mux.Unlock()
+
+	func() {
+		// always release the waiting callers (even on panic)
+		defer func() {
+			g.mux.Lock()
+			delete(g.calls, key)
+			g.mux.Unlock()
+			c.wg.Done()
+		}()
+
+		c.val, c.err = fn()
+	}()
+
+	return c.val, c.err, false
+}
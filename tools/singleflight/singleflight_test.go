@@ -0,0 +1,82 @@
+package singleflight_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/singleflight"
+)
+
+func TestGroupDo(t *testing.T) {
+	g := singleflight.Group[string]{}
+
+	val, err, shared := g.Do("test", func() (string, error) {
+		return "abc", nil
+	})
+	if val != "abc" || err != nil || shared {
+		t.Fatalf("Expected (abc, nil, false), got (%v, %v, %v)", val, err, shared)
+	}
+
+	_, err, _ = g.Do("test", func() (string, error) {
+		return "", errors.New("test")
+	})
+	if err == nil || err.Error() != "test" {
+		t.Fatalf("Expected the call error, got %v", err)
+	}
+}
+
+func TestGroupDoDuplicates(t *testing.T) {
+	g := singleflight.Group[int]{}
+
+	var calls int32
+	release := make(chan struct{})
+
+	fn := func() (int, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return 123, nil
+	}
+
+	const total = 10
+
+	var wg sync.WaitGroup
+	var sharedTotal int32
+	wg.Add(total)
+	for i := 0; i < total; i++ {
+		go func() {
+			defer wg.Done()
+			val, err, shared := g.Do("test", fn)
+			if val != 123 || err != nil {
+				t.Errorf("Expected (123, nil), got (%v, %v)", val, err)
+			}
+			if shared {
+				atomic.AddInt32(&sharedTotal, 1)
+			}
+		}()
+	}
+
+	// give the goroutines time to join the in-flight call
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Fatalf("Expected 1 call, got %d", calls)
+	}
+
+	if sharedTotal != total-1 {
+		t.Fatalf("Expected %d shared results, got %d", total-1, sharedTotal)
+	}
+
+	// the key should be released after the call completion
+	g.Do("test", func() (int, error) {
+		atomic.AddInt32(&calls, 1)
+		return 0, nil
+	})
+	if calls != 2 {
+		t.Fatalf("Expected a new call after the completion, got %d calls", calls)
+	}
+}
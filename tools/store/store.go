@@ -43,6 +43,20 @@ func (s *Store[T]) Get(key string) T {
 	return s.data[key]
 }
 
+// GetAll returns a shallow copy of the current store data.
+func (s *Store[T]) GetAll() map[string]T {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	result := make(map[string]T, len(s.data))
+
+	for k, v := range s.data {
+		result[k] = v
+	}
+
+	return result
+}
+
 // Set sets (or overwrite if already exist) a new value for key.
 func (s *Store[T]) Set(key string, value T) {
 	s.mux.Lock()
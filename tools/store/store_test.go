@@ -124,3 +124,27 @@ func TestSetIfLessThanLimit(t *testing.T) {
 		}
 	}
 }
+
+func TestGetAll(t *testing.T) {
+	data := map[string]int{"a": 1, "b": 2}
+
+	s := store.New(data)
+
+	result := s.GetAll()
+
+	if len(result) != len(data) {
+		t.Fatalf("Expected %d items, got %d", len(data), len(result))
+	}
+
+	for k, v := range data {
+		if result[k] != v {
+			t.Errorf("Expected %q to be %d, got %d", k, v, result[k])
+		}
+	}
+
+	// modifying the result shouldn't affect the store
+	result["c"] = 3
+	if s.Has("c") {
+		t.Fatal("Expected the returned map to be a copy")
+	}
+}
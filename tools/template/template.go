@@ -0,0 +1,214 @@
+// Package template implements a minimal time and size bounded
+// renderer of user defined text and html templates.
+package template
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	texttemplate "text/template"
+	textparse "text/template/parse"
+	"time"
+)
+
+// The supported template formats.
+const (
+	// FormatText renders the template as plain text (without escaping).
+	FormatText = "text"
+
+	// FormatHtml renders the template as html with contextual escaping
+	// of the data values (see [html/template]).
+	FormatHtml = "html"
+)
+
+const (
+	// DefaultTimeout is the default max template execution duration
+	// (applied if the render context doesn't have a deadline).
+	DefaultTimeout = 2 * time.Second
+
+	// MaxOutputSize is the max size in bytes of a rendered template.
+	MaxOutputSize = 1 << 20
+)
+
+var (
+	ErrTimeout        = errors.New("the template execution timed out")
+	ErrOutputTooLarge = fmt.Errorf("the template output exceeds the %d bytes limit", MaxOutputSize)
+)
+
+type executor interface {
+	Execute(w io.Writer, data any) error
+}
+
+// checkFuncName is the name of the internal template function
+// that is called on every range iteration and template invocation.
+const checkFuncName = "_pb_check"
+
+// parse parses the provided template content according to its format.
+//
+// check is called on every range iteration and template invocation
+// and its error aborts the template execution (see [injectCheck]).
+func parse(format string, content string, check func() error) (executor, error) {
+	funcs := map[string]any{
+		checkFuncName: func() (bool, error) {
+			return false, check()
+		},
+	}
+
+	checkTmpl, err := texttemplate.New("").Funcs(funcs).Parse("{{if " + checkFuncName + "}}{{end}}")
+	if err != nil {
+		return nil, err
+	}
+	checkNode := checkTmpl.Tree.Root.Nodes[0]
+
+	switch format {
+	case FormatText:
+		tmpl, err := texttemplate.New("").Funcs(funcs).Option("missingkey=zero").Parse(content)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tmpl.Templates() {
+			injectCheck(t.Tree, checkNode)
+		}
+		return tmpl, nil
+	case FormatHtml:
+		tmpl, err := htmltemplate.New("").Funcs(funcs).Option("missingkey=zero").Parse(content)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tmpl.Templates() {
+			injectCheck(t.Tree, checkNode)
+		}
+		return tmpl, nil
+	default:
+		return nil, fmt.Errorf("unsupported template format %q", format)
+	}
+}
+
+// injectCheck prepends a copy of the check node to the template root
+// and to every range body of the provided parsed template tree.
+//
+// This way the long running templates (eg. ranging over a large number
+// or recursive template calls) could be aborted even if they don't write anything.
+func injectCheck(tree *textparse.Tree, check textparse.Node) {
+	if tree == nil || tree.Root == nil {
+		return
+	}
+
+	var walk func(list *textparse.ListNode)
+	walk = func(list *textparse.ListNode) {
+		if list == nil {
+			return
+		}
+
+		for _, node := range list.Nodes {
+			switch n := node.(type) {
+			case *textparse.IfNode:
+				walk(n.List)
+				walk(n.ElseList)
+			case *textparse.WithNode:
+				walk(n.List)
+				walk(n.ElseList)
+			case *textparse.RangeNode:
+				walk(n.List)
+				walk(n.ElseList)
+				if n.List != nil {
+					n.List.Nodes = append([]textparse.Node{check.Copy()}, n.List.Nodes...)
+				}
+			}
+		}
+	}
+
+	walk(tree.Root)
+
+	tree.Root.Nodes = append([]textparse.Node{check.Copy()}, tree.Root.Nodes...)
+}
+
+// Validate checks whether the provided template content
+// could be parsed in the specified format.
+func Validate(format string, content string) error {
+	_, err := parse(format, content, func() error { return nil })
+	return err
+}
+
+// Render parses and executes the provided template with the specified data.
+//
+// The execution is aborted if the rendered output exceeds MaxOutputSize
+// or if it doesn't complete before the context is done
+// (or before DefaultTimeout if the context doesn't have a deadline).
+//
+// On timeout the execution is also stopped on the next output write,
+// range iteration or template invocation (whichever comes first).
+func Render(ctx context.Context, format string, content string, data any) (string, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultTimeout)
+		defer cancel()
+	}
+
+	tmpl, err := parse(format, content, func() error {
+		if ctx.Err() != nil {
+			return ErrTimeout
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	w := &limitedWriter{ctx: ctx}
+
+	// buffered to not block the execution goroutine in case of timeout
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("the template execution panicked: %v", r)
+			}
+		}()
+		done <- tmpl.Execute(w, data)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			if w.err != nil {
+				return "", w.err
+			}
+			return "", err
+		}
+		return w.buf.String(), nil
+	case <-ctx.Done():
+		return "", ErrTimeout
+	}
+}
+
+// limitedWriter is a buffer writer that fails once the
+// MaxOutputSize is exceeded or the context is done
+// (stopping the template execution on its next write).
+type limitedWriter struct {
+	ctx context.Context
+	buf bytes.Buffer
+	err error
+}
+
+// Write implements [io.Writer].
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	if w.ctx.Err() != nil {
+		w.err = ErrTimeout
+		return 0, w.err
+	}
+
+	if w.buf.Len()+len(p) > MaxOutputSize {
+		w.err = ErrOutputTooLarge
+		return 0, w.err
+	}
+
+	return w.buf.Write(p)
+}
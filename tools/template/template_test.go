@@ -0,0 +1,143 @@
+package template_test
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/template"
+)
+
+func TestValidate(t *testing.T) {
+	scenarios := []struct {
+		format      string
+		content     string
+		expectError bool
+	}{
+		{"", "test", true},
+		{"invalid", "test", true},
+		{template.FormatText, "{{.title}", true},
+		{template.FormatHtml, "{{if .title}}", true},
+		{template.FormatText, "", false},
+		{template.FormatText, "Hello {{.title}}", false},
+		{template.FormatHtml, "<p>{{.title}}</p>", false},
+	}
+
+	for i, s := range scenarios {
+		err := template.Validate(s.format, s.content)
+
+		if hasErr := err != nil; hasErr != s.expectError {
+			t.Errorf("(%d) Expected hasErr %v, got %v (%v)", i, s.expectError, hasErr, err)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	data := map[string]any{
+		"title": "<b>test</b>",
+		"items": []int{1, 2, 3},
+	}
+
+	scenarios := []struct {
+		name           string
+		format         string
+		content        string
+		expectError    bool
+		expectedOutput string
+	}{
+		{"invalid template", template.FormatText, "{{.title}", true, ""},
+		{"text", template.FormatText, "{{.title}}:{{range .items}}{{.}}{{end}}", false, "<b>test</b>:123"},
+		{"html escaping", template.FormatHtml, "<p>{{.title}}</p>", false, "<p>&lt;b&gt;test&lt;/b&gt;</p>"},
+		{"missing key", template.FormatText, "[{{.missing}}]", false, "[<no value>]"},
+		{"execution error", template.FormatText, "{{index .items 10}}", true, ""},
+		{"too large output", template.FormatText, strings.Repeat("x", template.MaxOutputSize+1), true, ""},
+		{"nested range", template.FormatText, `{{range .items}}{{range $.items}}{{.}}{{end}}{{end}}`, false, "123123123"},
+		{"html range", template.FormatHtml, `<a href="/{{range .items}}{{.}}{{end}}">{{range .items}}{{.}}{{else}}-{{end}}</a>`, false, `<a href="/123">123</a>`},
+		{"defined template", template.FormatText, `{{define "item"}}[{{.}}]{{end}}{{range .items}}{{template "item" .}}{{end}}`, false, "[1][2][3]"},
+	}
+
+	for _, s := range scenarios {
+		output, err := template.Render(context.Background(), s.format, s.content, data)
+
+		if hasErr := err != nil; hasErr != s.expectError {
+			t.Errorf("[%s] Expected hasErr %v, got %v (%v)", s.name, s.expectError, hasErr, err)
+			continue
+		}
+
+		if output != s.expectedOutput {
+			t.Errorf("[%s] Expected output %q, got %q", s.name, s.expectedOutput, output)
+		}
+	}
+}
+
+func TestRenderTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// a slow custom data function
+	data := map[string]any{
+		"slow": func() string {
+			time.Sleep(200 * time.Millisecond)
+			return "test"
+		},
+	}
+
+	start := time.Now()
+
+	_, err := template.Render(ctx, template.FormatText, "{{call .slow}}", data)
+	if err != template.ErrTimeout {
+		t.Fatalf("Expected ErrTimeout, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Fatalf("Expected the render to be aborted on timeout, took %v", elapsed)
+	}
+}
+
+func TestRenderTimeoutWithoutOutput(t *testing.T) {
+	scenarios := []struct {
+		name    string
+		format  string
+		content string
+	}{
+		{"text large range", template.FormatText, `{{range 1000000000000}}{{if call $.count}}{{end}}{{end}}`},
+		{"html large range", template.FormatHtml, `{{range 1000000000000}}{{if call $.count}}{{end}}{{end}}`},
+		{"nested ranges", template.FormatText, `{{range 1000000}}{{range 1000000}}{{if call $.count}}{{end}}{{end}}{{end}}`},
+		{"recursive template", template.FormatText, `{{define "a"}}{{if call .count}}{{end}}{{template "a" .}}{{template "a" .}}{{end}}{{template "a" .}}`},
+	}
+
+	for _, s := range scenarios {
+		var calls int64
+		data := map[string]any{
+			"count": func() bool {
+				atomic.AddInt64(&calls, 1)
+				return false
+			},
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+
+		start := time.Now()
+
+		_, err := template.Render(ctx, s.format, s.content, data)
+		cancel()
+		if err != template.ErrTimeout {
+			t.Errorf("[%s] Expected ErrTimeout, got %v", s.name, err)
+			continue
+		}
+
+		if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+			t.Errorf("[%s] Expected the render to be aborted on timeout, took %v", s.name, elapsed)
+		}
+
+		// the execution goroutine should stop shortly after the timeout
+		time.Sleep(20 * time.Millisecond)
+		before := atomic.LoadInt64(&calls)
+		time.Sleep(50 * time.Millisecond)
+		if after := atomic.LoadInt64(&calls); after != before {
+			t.Errorf("[%s] Expected the template execution to be stopped, got %d more calls", s.name, after-before)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Package webhook implements a minimal signed outbound webhooks sender.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// HeaderTimestamp is the request header with the unix timestamp
+	// used for the payload signature.
+	HeaderTimestamp = "X-Webhook-Timestamp"
+
+	// HeaderSignature is the request header with the hex encoded
+	// HMAC-SHA256 payload signature (see [Sign]).
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// DefaultRetryDelays defines the default delays between the failed delivery attempts.
+var DefaultRetryDelays = []time.Duration{
+	5 * time.Second,
+	30 * time.Second,
+	2 * time.Minute,
+}
+
+// Sign returns the hex encoded HMAC-SHA256 signature of the
+// "{timestamp}.{body}" string generated with the provided secret.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is a valid [Sign] signature of the timestamp and body.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// Sender delivers signed JSON payloads to webhook endpoints.
+type Sender struct {
+	// Client is the http client used for the delivery
+	// (fallbacks to a client with 10s timeout if not set).
+	Client *http.Client
+
+	// RetryDelays is the list of delays between the failed delivery
+	// attempts (fallbacks to [DefaultRetryDelays] if nil).
+	//
+	// The total number of attempts is len(RetryDelays) + 1.
+	RetryDelays []time.Duration
+}
+
+// Send POSTs the JSON body to the url, retrying on network
+// errors and non 2xx responses.
+//
+// Send blocks until the delivery succeeds or all attempts fail
+// and returns the last delivery error.
+func (s *Sender) Send(url string, secret string, body []byte) error {
+	delays := s.RetryDelays
+	if delays == nil {
+		delays = DefaultRetryDelays
+	}
+
+	var err error
+
+	for attempt := 0; ; attempt++ {
+		if err = s.send(url, secret, body); err == nil {
+			return nil
+		}
+
+		if attempt >= len(delays) {
+			return err
+		}
+
+		time.Sleep(delays[attempt])
+	}
+}
+
+func (s *Sender) send(url string, secret string, body []byte) error {
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	timestamp := time.Now().Unix()
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(secret, timestamp, body))
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("Webhook %s responded with status %d.", url, res.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package webhook_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/webhook"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"a":1}`)
+
+	sig := webhook.Sign("secret", 123, body)
+	if sig == "" || len(sig) != 64 {
+		t.Fatalf("Expected 64 chars hex signature, got %q", sig)
+	}
+
+	scenarios := []struct {
+		secret    string
+		timestamp int64
+		body      []byte
+		expected  bool
+	}{
+		{"secret", 123, body, true},
+		{"other", 123, body, false},
+		{"secret", 124, body, false},
+		{"secret", 123, []byte(`{"a":2}`), false},
+	}
+
+	for i, s := range scenarios {
+		result := webhook.Verify(s.secret, s.timestamp, s.body, sig)
+		if result != s.expected {
+			t.Errorf("(%d) Expected %v, got %v", i, s.expected, result)
+		}
+	}
+}
+
+func TestSenderSend(t *testing.T) {
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+		if !webhook.Verify("secret", timestamp, body, r.Header.Get(webhook.HeaderSignature)) {
+			t.Errorf("Invalid request signature")
+		}
+
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected json content type, got %q", r.Header.Get("Content-Type"))
+		}
+
+		// fail the first 2 attempts
+		if n <= 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}))
+	defer server.Close()
+
+	sender := &webhook.Sender{RetryDelays: []time.Duration{0, 0, 0}}
+
+	if err := sender.Send(server.URL, "secret", []byte(`{"a":1}`)); err != nil {
+		t.Fatalf("Expected nil error, got %v", err)
+	}
+
+	if calls != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", calls)
+	}
+}
+
+func TestSenderSendAllAttemptsFailed(t *testing.T) {
+	var calls int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	sender := &webhook.Sender{RetryDelays: []time.Duration{0}}
+
+	if err := sender.Send(server.URL, "secret", []byte(`{}`)); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	if calls != 2 {
+		t.Fatalf("Expected 2 attempts, got %d", calls)
+	}
+}